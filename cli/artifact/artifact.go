@@ -0,0 +1,267 @@
+// Author: lipixun
+// Created Time : 日 10/18 11:56:42 2026
+//
+// File Name: artifact.go
+// Description:
+//	Push, pull and promote the artifacts
+package artifact
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/registry"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Push the artifacts
+func Push(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	target, version, err := getReference(c, logger)
+	if err != nil {
+		return err
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	manifestPath := c.String("manifest")
+	if manifestPath == "" {
+		if manifestPath, err = builder.GetLatestManifestPath(ws); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get the artifact manifest of the latest build, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	manifest, err := artifact.ReadManifest(manifestPath)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to read artifact manifest, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	reg, err := getRegistry(c, ws, logger)
+	if err != nil {
+		return err
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("push", fmt.Sprintf("%s@%s", target, version), fmt.Sprintf("to %s from %s", reg.Url, manifestPath))
+		return plan.Report(c)
+	}
+	pushed, err := reg.Push(manifest, target, version)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(pushed); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the manifest, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Pushed %d artifacts of %s@%s to %s\n", len(pushed.Artifacts), target, version, reg.Url)
+	// Done
+	return nil
+}
+
+// Pull the artifacts
+func Pull(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	target, version, err := getReference(c, logger)
+	if err != nil {
+		return err
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	dest, err := filepath.Abs(c.String("dest"))
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Invalid dest [%s], error: %s\n", c.String("dest"), err)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	reg, err := getRegistry(c, ws, logger)
+	if err != nil {
+		return err
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("pull", target+"@"+version, dest)
+		if c.Bool("extract") {
+			plan.Add("extract", "the archive artifacts", dest)
+		}
+		return plan.Report(c)
+	}
+	manifest, err := reg.Pull(target, version, dest)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	for _, art := range manifest.Artifacts {
+		logger.Printf("\tArtifact pulled: %s --> %s\n", art.GetName(), art.String())
+		archive, ok := art.Artifact.(*artifact.ArchiveArtifact)
+		if !ok || !c.Bool("extract") {
+			continue
+		}
+		// The name is from the remote manifest, which is not signed without the public key
+		if archive.Name == "" || archive.Name == "." || archive.Name == ".." || archive.Name != filepath.Base(archive.Name) {
+			logger.LeveledPrintf(log.LevelError, "Invalid name [%s] of archive artifact [%s]\n", archive.Name, art.GetName())
+			return cli.NewExitError("", 1)
+		}
+		// Replace the previously extracted files
+		path := filepath.Join(dest, archive.Name)
+		if err := os.RemoveAll(path); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to remove [%s], error: %s\n", path, err)
+			return cli.NewExitError("", 1)
+		}
+		directory, err := archive.Extract(path)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		logger.Printf("\tArtifact extracted: %s --> %s\n", art.GetName(), directory.String())
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(manifest); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the manifest, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Pulled %d artifacts of %s@%s to %s\n", len(manifest.Artifacts), target, version, dest)
+	// Done
+	return nil
+}
+
+// Promote the version to the channel
+func Promote(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	target, version, err := getReference(c, logger)
+	if err != nil {
+		return err
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	reg, err := getRegistry(c, ws, logger)
+	if err != nil {
+		return err
+	}
+	name := c.String("to")
+	if name == "" {
+		logger.LeveledPrintf(log.LevelError, "Require --to, one of %s\n", strings.Join(reg.Channels, ", "))
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if !reg.IsChannel(name) {
+		logger.LeveledPrintf(log.LevelError, "Unknown channel [%s], should be one of %s\n", name, strings.Join(reg.Channels, ", "))
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if reg.IsChannel(version) {
+		logger.LeveledPrintf(log.LevelError, "Require a version instead of channel [%s], resolve it by op artifact resolve %s@%s\n", version, target, version)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("promote", fmt.Sprintf("%s@%s", target, version), fmt.Sprintf("to %s in %s", name, reg.Url))
+		return plan.Report(c)
+	}
+	channel, err := reg.Promote(target, version, name, c.Bool("force"))
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(channel); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the channel, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	if channel.Previous != "" && channel.Previous != version {
+		logger.LeveledPrintf(log.LevelSuccess, "Promoted %s@%s to %s (was %s)\n", target, version, name, channel.Previous)
+	} else {
+		logger.LeveledPrintf(log.LevelSuccess, "Promoted %s@%s to %s\n", target, version, name)
+	}
+	// Done
+	return nil
+}
+
+// Print the current version of the channel
+func Resolve(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	target, name, err := getReference(c, logger)
+	if err != nil {
+		return err
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	reg, err := getRegistry(c, ws, logger)
+	if err != nil {
+		return err
+	}
+	channel, err := reg.GetChannel(target, name)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if channel == nil {
+		logger.LeveledPrintf(log.LevelError, "No version of [%s] in channel [%s]\n", target, name)
+		return cli.NewExitError("", 1)
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(channel); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the channel, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	fmt.Println(channel.Version)
+	// Done
+	return nil
+}
+
+// Get the target and the version of the argument
+func getReference(c *cli.Context, logger log.Logger) (string, string, error) {
+	if len(c.Args()) != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one <target>@<version>\n")
+		return "", "", cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	target, version, err := registry.ParseReference(c.Args()[0])
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return "", "", cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	return target, version, nil
+}
+
+// Get the registry of the flag or the config
+func getRegistry(c *cli.Context, ws *workspace.Workspace, logger log.Logger) (*registry.Registry, error) {
+	url := c.String("registry")
+	if url == "" {
+		url = ws.GetUserConfigString(workspace.ConfigKeyArtifactRegistry)
+	}
+	reg, err := registry.New(ws, url)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return nil, cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	return reg, nil
+}
@@ -0,0 +1,282 @@
+// Author: lipixun
+// Created Time : 日 10/18 14:05:27 2026
+//
+// File Name: list.go
+// Description:
+//	List and inspect the locally built artifacts (see builder/index.go)
+package artifact
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ListFormat = "%-48s%-16s%-20s%-10s%-14s%s\n"
+
+	ShortFingerprintLength = 12
+)
+
+// The inspected artifact
+type InspectResult struct {
+	Artifact   artifact.ManifestArtifact `json:"artifact"`
+	Provenance Provenance                `json:"provenance"`
+}
+
+// How the artifact was built
+type Provenance struct {
+	Target      string                         `json:"target"`
+	Version     string                         `json:"version"`
+	Tag         string                         `json:"tag"`
+	Time        time.Time                      `json:"time"`
+	Repository  string                         `json:"repository"`
+	Branch      string                         `json:"branch"`
+	Commit      string                         `json:"commit"`
+	Manifest    string                         `json:"manifest"`
+	Status      string                         `json:"status,omitempty"`
+	Duration    float64                        `json:"duration,omitempty"`
+	Environment *workspace.EnvironmentSnapshot `json:"environment,omitempty"`
+}
+
+// List the locally built artifacts
+func List(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	index, err := builder.LoadArtifactIndex(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load artifact index, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	targets := []string(c.Args())
+	if len(targets) == 0 {
+		targets = []string{""}
+	}
+	entries := []*builder.ArtifactIndexEntry{}
+	found := make(map[*builder.ArtifactIndexEntry]bool)
+	latest := make(map[string]bool)
+	for _, target := range targets {
+		for _, entry := range index.Find(target) {
+			key := entry.Target + "\x00" + entry.Name
+			if found[entry] || (c.Bool("latest") && latest[key]) {
+				continue
+			}
+			found[entry] = true
+			latest[key] = true
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Target < entries[j].Target
+	})
+	if renderer.Structured() {
+		if err := renderer.Render(entries); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render artifacts, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		logger.LeveledPrintln(log.LevelInfo, "No artifact found, build the targets first")
+		return nil
+	}
+	fmt.Printf(ListFormat, "Target", "Artifact", "Version", "Size", "Fingerprint", "Age")
+	for _, entry := range entries {
+		fmt.Printf(ListFormat, entry.Target, entry.Name, entry.Version(), util.FormatSize(entry.Size), getShortFingerprint(entry.Fingerprint), util.FormatAge(time.Now().Sub(entry.Time)))
+	}
+	// Done
+	return nil
+}
+
+// Inspect the manifest and the provenance of a locally built artifact
+func Inspect(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if len(c.Args()) != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one <target>[@<version>]\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	index, err := builder.LoadArtifactIndex(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load artifact index, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	entry, err := findEntry(index, c.Args()[0], c.String("name"))
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	_, art, err := entry.Load()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	result := InspectResult{
+		Artifact: artifact.ManifestArtifact{Artifact: art},
+		Provenance: Provenance{
+			Target:     entry.Target,
+			Version:    entry.Version(),
+			Tag:        entry.Tag,
+			Time:       entry.Time,
+			Repository: entry.Repository,
+			Branch:     entry.Branch,
+			Commit:     entry.Commit,
+			Manifest:   entry.Manifest,
+		},
+	}
+	if summaries, err := builder.LoadBuildSummaries(ws); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to load build summaries, error: %s\n", err)
+	} else {
+		for _, summary := range summaries {
+			if summary.Tag != entry.Tag {
+				continue
+			}
+			result.Provenance.Environment = summary.Environment
+			for _, target := range summary.Targets {
+				if target.Target == entry.Target {
+					result.Provenance.Status, result.Provenance.Duration = target.Status, target.Duration
+				}
+			}
+		}
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(result); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render artifact, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	info := art.GetInfo()
+	fmt.Println("Artifact:")
+	fmt.Printf("\tName: %s\n", art.GetName())
+	fmt.Printf("\tType: %s\n", art.GetType())
+	fmt.Printf("\tLocation: %s\n", art.String())
+	fmt.Printf("\tSize: %s\n", util.FormatSize(info.Size))
+	fmt.Printf("\tFingerprint: %s\n", info.Fingerprint)
+	fmt.Printf("\tCreated: %s\n", info.CreatedTime.Format(log.DefaultTimeLayout))
+	printMap("Outputs", info.Outputs)
+	printMap("Metadata", info.Metadata)
+	provenance := result.Provenance
+	fmt.Println("Provenance:")
+	fmt.Printf("\tTarget: %s\n", provenance.Target)
+	fmt.Printf("\tVersion: %s (tag %s)\n", provenance.Version, provenance.Tag)
+	fmt.Printf("\tBuilt: %s\n", provenance.Time.Format(log.DefaultTimeLayout))
+	if provenance.Status != "" {
+		fmt.Printf("\tStatus: %s in %.1fs\n", provenance.Status, provenance.Duration)
+	}
+	fmt.Printf("\tRepository: %s\n", provenance.Repository)
+	if provenance.Commit != "" {
+		fmt.Printf("\tCommit: %s@%s\n", provenance.Commit, provenance.Branch)
+	}
+	if env := provenance.Environment; env != nil {
+		fmt.Printf("\tHost: %s (%s/%s)\n", env.Hostname, env.OS, env.Arch)
+		var names []string
+		for name := range env.Toolchains {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("\tToolchain: %s %s\n", name, env.Toolchains[name])
+		}
+	}
+	if recorded := info.Provenance; recorded != nil {
+		fmt.Printf("\tSpec hash: %s\n", recorded.Source.SpecHash)
+		if recorded.Signature != nil {
+			fmt.Printf("\tSigned by: %s (%s)\n", recorded.Signature.KeyID, recorded.Signature.Algorithm)
+		} else {
+			fmt.Println("\tSigned by: none")
+		}
+	}
+	fmt.Printf("\tManifest: %s\n", provenance.Manifest)
+	// Done
+	return nil
+}
+
+// Find the entry of the reference <target>[@<version>] and the artifact name (optional)
+func findEntry(index *builder.ArtifactIndex, ref, name string) (*builder.ArtifactIndexEntry, error) {
+	target, version := ref, ""
+	if idx := strings.LastIndex(ref, "@"); idx > 0 {
+		target, version = ref[:idx], ref[idx+1:]
+	}
+	var entries []*builder.ArtifactIndexEntry
+	targets := make(map[string]bool)
+	for _, entry := range index.Find(target) {
+		if (version == "" || entry.MatchVersion(version)) && (name == "" || entry.Name == name) {
+			entries = append(entries, entry)
+			targets[entry.Target] = true
+		}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New(fmt.Sprintf("No artifact of [%s] found, list the artifacts by op artifact list", ref))
+	}
+	if len(targets) > 1 {
+		var keys []string
+		for key := range targets {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, errors.New(fmt.Sprintf("Ambiguous target [%s], could be %s", target, strings.Join(keys, ", ")))
+	}
+	// The entries are sorted from the newest, the artifacts of the newest build
+	var names []string
+	for _, entry := range entries {
+		if entry.Tag == entries[0].Tag {
+			names = append(names, entry.Name)
+		}
+	}
+	if len(names) > 1 {
+		return nil, errors.New(fmt.Sprintf("Target [%s] has artifacts %s, specify one by --name", entries[0].Target, strings.Join(names, ", ")))
+	}
+	// Done
+	return entries[0], nil
+}
+
+// Get the fingerprint without the algorithm prefix, shortened
+func getShortFingerprint(fingerprint string) string {
+	if idx := strings.Index(fingerprint, ":"); idx != -1 {
+		fingerprint = fingerprint[idx+1:]
+	}
+	if len(fingerprint) > ShortFingerprintLength {
+		fingerprint = fingerprint[:ShortFingerprintLength]
+	}
+	return fingerprint
+}
+
+// Print the map sorted by keys
+func printMap(title string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Printf("\t%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("\t\t%s: %s\n", key, values[key])
+	}
+}
@@ -0,0 +1,139 @@
+// Author: lipixun
+// Created Time : 日 10/18 11:48:20 2026
+//
+// File Name: main.go
+// Description:
+//	The artifact command pushes the build artifacts to and pulls them from the artifact registry (see
+//	registry/registry.go), e.g.
+//		op build server && op artifact push server@1.2.0 	On CI
+//		op artifact pull server@1.2.0 -d /opt/server 		On the staging box
+//	The pushed versions are promoted through the channels (the config artifact.channels, dev, staging, prod by default),
+//	a channel could be used as the version to get its current version, e.g.
+//		op artifact promote server@1.2.0 --to staging 		After the tests passed
+//		op artifact pull server@staging -d /opt/server 		Deploy the latest staging build
+//		op artifact resolve server@prod 					Print the version in prod
+//	The locally built artifacts are listed and inspected from the artifact index (see builder/index.go), e.g.
+//		op artifact list --latest
+//		op artifact inspect server@3 		The artifact of the build #3 of server
+//	The artifacts carry their provenances (optionally signed, see artifact/provenance.go), verified by
+//	op artifact verify.
+package artifact
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Artifact"
+)
+
+func GetCommand() []cli.Command {
+	registryFlag := cli.StringFlag{
+		Name:   "registry",
+		Usage:  "The url of the artifact registry, the config artifact.registry if not specified",
+		EnvVar: "OP_ARTIFACT_REGISTRY",
+	}
+	return []cli.Command{
+		{
+			Category: "Builder",
+			Name:     "artifact",
+			Usage:    "List the build artifacts, push them to or pull them from the artifact registry",
+			Subcommands: []cli.Command{
+				{
+					Name:      "push",
+					Usage:     "Push the archive and single file artifacts of the target as the version",
+					ArgsUsage: "<target>@<version>",
+					Action:    Push,
+					Flags: []cli.Flag{
+						registryFlag,
+						cli.StringFlag{
+							Name:  "manifest",
+							Usage: "The artifact manifest of the build, the manifest of the latest build if not specified",
+						},
+					},
+				},
+				{
+					Name:      "pull",
+					Usage:     "Pull the artifacts of the target of the version (or the current version of the channel)",
+					ArgsUsage: "<target>@<version|channel>",
+					Action:    Pull,
+					Flags: []cli.Flag{
+						registryFlag,
+						cli.StringFlag{
+							Name:  "dest, d",
+							Value: ".",
+							Usage: "The directory to write the artifacts and the manifest (artifacts.json) to",
+						},
+						cli.BoolFlag{
+							Name:  "extract",
+							Usage: "Extract the archive artifacts into <dest>/<artifact name>",
+						},
+					},
+				},
+				{
+					Name:      "list",
+					Aliases:   []string{"ls"},
+					Usage:     "List the locally built artifacts of the targets (all targets if not specified)",
+					ArgsUsage: "[target...]",
+					Action:    List,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "latest",
+							Usage: "Only list the latest build of each artifact",
+						},
+					},
+				},
+				{
+					Name:      "inspect",
+					Usage:     "Show the manifest and the provenance of a locally built artifact, the latest build if the version is not specified",
+					ArgsUsage: "<target>[@<build number|build tag>]",
+					Action:    Inspect,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "The artifact name, required if the target has more than one artifact",
+						},
+					},
+				},
+				{
+					Name:      "verify",
+					Usage:     "Verify the content and the provenances of the artifacts of the manifest (./artifacts.json if not specified)",
+					ArgsUsage: "[manifest]",
+					Action:    Verify,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "public-key",
+							Usage: "The base64 ed25519 public key to verify the provenance signatures, the config artifact.publickey if not specified",
+						},
+					},
+				},
+				{
+					Name:      "promote",
+					Usage:     "Promote the version of the target to the channel",
+					ArgsUsage: "<target>@<version>",
+					Action:    Promote,
+					Flags: []cli.Flag{
+						registryFlag,
+						cli.StringFlag{
+							Name:  "to",
+							Usage: "The channel to promote to, the version should be in the previous channel",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "Promote even if the version is not in the previous channel",
+						},
+					},
+				},
+				{
+					Name:      "resolve",
+					Usage:     "Print the current version of the channel of the target",
+					ArgsUsage: "<target>@<channel>",
+					Action:    Resolve,
+					Flags: []cli.Flag{
+						registryFlag,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,98 @@
+// Author: lipixun
+// Created Time : 日 10/18 16:20:44 2026
+//
+// File Name: verify.go
+// Description:
+//	Verify the artifacts of a manifest by their content and provenances (see artifact/provenance.go), e.g. the
+//	artifacts pulled from the registry:
+//		op artifact pull server@prod -d /opt/server && op artifact verify /opt/server/artifacts.json
+package artifact
+
+import (
+	"crypto/ed25519"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// The verification of an artifact
+type VerifyResult struct {
+	Target   string `json:"target"`
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+	KeyID    string `json:"keyId,omitempty"` // The key signed the provenance, empty if not signed
+	Error    string `json:"error,omitempty"`
+}
+
+// Verify the artifacts of the manifest
+func Verify(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if len(c.Args()) > 1 {
+		logger.LeveledPrintf(log.LevelError, "Require at most one manifest\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	manifestPath := artifact.ManifestFileName
+	if len(c.Args()) == 1 {
+		manifestPath = c.Args()[0]
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	var key ed25519.PublicKey
+	publicKey := c.String("public-key")
+	if publicKey == "" {
+		publicKey = ws.GetUserConfigString(workspace.ConfigKeyArtifactPublicKey)
+	}
+	if publicKey != "" {
+		if key, err = artifact.ParsePublicKey(publicKey); err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+	} else {
+		logger.LeveledPrintf(log.LevelWarn, "The provenance signatures are not verified since no public key, specify --public-key or the config %s\n", workspace.ConfigKeyArtifactPublicKey)
+	}
+	manifest, err := artifact.ReadManifest(manifestPath)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to read artifact manifest, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	results := []VerifyResult{}
+	failed := 0
+	for _, art := range manifest.Artifacts {
+		info := art.GetInfo()
+		result := VerifyResult{Target: info.Target, Name: art.GetName(), Verified: true}
+		if info.Provenance != nil && info.Provenance.Signature != nil {
+			result.KeyID = info.Provenance.Signature.KeyID
+		}
+		if err := artifact.VerifyArtifact(art.Artifact, key); err != nil {
+			result.Verified, result.Error = false, err.Error()
+			failed++
+			logger.LeveledPrintf(log.LevelFail, "\tArtifact [%s] of [%s] failed: %s\n", result.Name, result.Target, result.Error)
+		} else if result.KeyID != "" {
+			logger.Printf("\tArtifact [%s] of [%s] verified, signed by key [%s]\n", result.Name, result.Target, result.KeyID)
+		} else {
+			logger.Printf("\tArtifact [%s] of [%s] verified, not signed\n", result.Name, result.Target)
+		}
+		results = append(results, result)
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(results); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the verification, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	if failed > 0 {
+		logger.LeveledPrintf(log.LevelError, "%d of %d artifacts failed to verify\n", failed, len(results))
+		return cli.NewExitError("", 1)
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Verified %d artifacts of %s\n", len(results), manifestPath)
+	// Done
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"gopkg.in/urfave/cli.v1"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -41,7 +42,7 @@ func LocalBuild(c *cli.Context) error {
 		targetUri := uri.ParseTargetUri(targetUriArg)
 		if targetUri == nil {
 			logger.LeveledPrintf(log.LevelError, "Failed to parse target uri from arg: %s\n", targetUriArg)
-			return cli.NewExitError("", 1)
+			return cli.NewExitError("", opcli.ExitCodeUsage)
 		}
 		targetUris = append(targetUris, targetUri)
 	}
@@ -87,8 +88,17 @@ func LocalBuild(c *cli.Context) error {
 			// No target uri defined, add current repository
 			target, err := getDefaultTargetUri(currentProjectRootPath)
 			if err != nil {
-				logger.LeveledPrintf(log.LevelError, "Failed to current target uri, error: %s\n", err)
-				return cli.NewExitError("", 1)
+				// Pick one of the targets on the terminal
+				names := opcli.CompleteTargets(c)
+				if len(names) == 0 || !opcli.CanPick() {
+					logger.LeveledPrintf(log.LevelError, "Failed to current target uri, error: %s\n", err)
+					return cli.NewExitError("", 1)
+				}
+				name, err := opcli.Pick("target", names)
+				if err != nil {
+					return err
+				}
+				target = &uri.TargetUri{Name: name, Repository: &uri.RepositoryUri{Uri: currentProjectRootPath}}
 			}
 			targetUris = append(targetUris, target)
 		} else {
@@ -99,15 +109,32 @@ func LocalBuild(c *cli.Context) error {
 			}
 		}
 	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
 	// Start build
 	options := BuildOptions{
+		Renderer:         renderer,
 		AllowLocal:       true,
 		OnlyLocal:        true,
 		Output:           output,
 		DisableFinder:    disableFinder,
+		Trace:            c.Bool("trace"),
 		RemoteOverwrites: remoteOverwrites,
+		Plan:             opcli.GetPlan(c),
 	}
-	return build(targetUris, ws, options, logger)
+	if currentProjectRootPath != "" && !c.Bool("ignore-lock") {
+		options.LockFile = filepath.Join(currentProjectRootPath, graph.LockFileName)
+	}
+	if err := BuildTargets(targetUris, ws, options, logger); err != nil {
+		return err
+	}
+	if options.Plan.DryRun {
+		return options.Plan.Report(c)
+	}
+	// Done
+	return nil
 }
 
 func Build(c *cli.Context) error {
@@ -148,7 +175,7 @@ func getRemoteOverwrites(flags []string, logger log.Logger) (map[string]string,
 		}
 		path, err := util.GetRealPath(flag[idx+1:])
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Malformed repository remote overwrites argument [%s], error: %s", err))
+			return nil, errors.New(fmt.Sprintf("Malformed repository remote overwrites argument [%s], error: %s", flag, err))
 		}
 		remoteOverwrites[uri] = path
 	}
@@ -180,13 +207,29 @@ type BuildOptions struct {
 	OnlyLocal        bool
 	Output           string
 	DisableFinder    bool
+	Trace            bool
 	RemoteOverwrites map[string]string
+	LockFile         string // Load the remote repositories at the commits locked in the file if not empty
+	Renderer         *opcli.Renderer
+	Plan             *opcli.Plan // Only add the targets to the plan in the dry run
+}
+
+// The build result of the targets in the structured output
+type BuildResult struct {
+	Tag      string              `json:"tag"`
+	Manifest string              `json:"manifest"` // The path of the artifact manifest
+	Targets  []BuildTargetResult `json:"targets"`
+}
+
+type BuildTargetResult struct {
+	Target    string            `json:"target"`
+	Artifacts map[string]string `json:"artifacts"` // The artifact name to the artifact
 }
 
-// Start the build process
-func build(targetUris []*uri.TargetUri, ws *workspace.Workspace, options BuildOptions, logger log.Logger) error {
+// Build the targets, the returned error is an exit error and the failure is logged already
+func BuildTargets(targetUris []*uri.TargetUri, ws *workspace.Workspace, options BuildOptions, logger log.Logger) error {
 	// Load the source code graph
-	g, err := graph.New(ws, graph.GraphOptions{UseLocalDependency: options.AllowLocal, DisableFinder: options.DisableFinder})
+	g, err := graph.New(ws, graph.GraphOptions{UseLocalDependency: options.AllowLocal, DisableFinder: options.DisableFinder, Trace: options.Trace})
 	if err != nil {
 		logger.LeveledPrintf(log.LevelError, "Failed to create sourcecode graph, error: %s\n", err)
 		return cli.NewExitError("", 1)
@@ -196,21 +239,36 @@ func build(targetUris []*uri.TargetUri, ws *workspace.Workspace, options BuildOp
 			g.RemoteOverwrites[uri] = remote
 		}
 	}
+	if options.LockFile != "" {
+		if g.Lock, err = graph.LoadLock(options.LockFile); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to load lock file, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
 	// Load the repository with the targets
 	var targets []*spec.Target
 	for _, targetUri := range targetUris {
 		r, err := g.Load(targetUri.Repository.Uri, graph.LoadOptions{Branch: targetUri.Repository.Branch, Commit: targetUri.Repository.Commit, Targets: []string{targetUri.Name}})
 		if err != nil {
 			logger.LeveledPrintf(log.LevelError, "Failed to load target [%s] remote [%s], err: %s\n", targetUri.Name, targetUri.Repository.Uri, err)
+			if graph.IsTargetNotFoundError(err) {
+				return cli.NewExitError("", opcli.ExitCodeTargetNotFound)
+			}
 			return cli.NewExitError("", 1)
 		}
 		target := g.Targets[spec.GetTargetKey(targetUri.Name, r)]
 		if target == nil {
 			logger.LeveledPrintf(log.LevelError, "Target [%s] not loaded after repository loaded\n", targetUri.Name)
-			return cli.NewExitError("", 1)
+			return cli.NewExitError("", opcli.ExitCodeTargetNotFound)
 		}
 		targets = append(targets, target)
 	}
+	if options.Plan != nil && options.Plan.DryRun {
+		for _, target := range targets {
+			options.Plan.Add("build", target.Key(), fmt.Sprintf("output %s", options.Output))
+		}
+		return nil
+	}
 	// Create the builder
 	buildTag, err := builder.NewTag()
 	if err != nil {
@@ -224,18 +282,42 @@ func build(targetUris []*uri.TargetUri, ws *workspace.Workspace, options BuildOp
 		return cli.NewExitError("", 1)
 	}
 	// Build the targets
+	result := BuildResult{Tag: buildTag, Targets: []BuildTargetResult{}}
 	for _, target := range targets {
 		logger.Printf("Start build target %s\n", target.Key())
 		buildResult, err := b.Build(target)
 		if err != nil {
 			logger.LeveledPrintf(log.LevelError, "Failed to build target [%s] error: %s\n", target.Key(), err)
-			return cli.NewExitError("", 1)
+			opcli.Annotate(opcli.Annotation{
+				Level:   opcli.AnnotationError,
+				File:    target.Repository.SpecFile,
+				Line:    spec.GetKeyPathLine(target.Repository.SpecFile, "targets."+target.Name),
+				Title:   fmt.Sprintf("Build failed: %s", target.Key()),
+				Message: err.Error(),
+			})
+			return cli.NewExitError("", opcli.ExitCodeBuildFailure)
+		}
+		var names []string
+		for name := range buildResult.Artifacts {
+			names = append(names, name)
 		}
-		for name, art := range buildResult.Artifacts {
-			logger.Printf("\tArtifact generated: %s --> %s\n", name, art.String())
+		sort.Strings(names)
+		targetResult := BuildTargetResult{Target: target.Key(), Artifacts: make(map[string]string)}
+		for _, name := range names {
+			logger.Printf("\tArtifact generated: %s --> %s\n", name, buildResult.Artifacts[name].String())
+			targetResult.Artifacts[name] = buildResult.Artifacts[name].String()
 		}
+		result.Targets = append(result.Targets, targetResult)
 	}
+	result.Manifest = b.ManifestPath()
 	logger.Println("Build completed")
+	logger.Printf("Artifact manifest: %s\n", result.Manifest)
+	if options.Renderer != nil && options.Renderer.Structured() {
+		if err := options.Renderer.Render(result); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render build result, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
 	// Done
 	return nil
 }
@@ -7,9 +7,12 @@
 package build
 
 import (
+	"fmt"
 	opcli "github.com/ops-openlight/openlight/cli"
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -19,6 +22,32 @@ func Clean(c *cli.Context) error {
 		return err
 	}
 	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Confirm with the usage of the build data
+	path, err := builder.GetBuildDataPath(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get build data path, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	items, err := workspace.ListUsageItems(path)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get the usage of build data, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if len(items) == 0 {
+		logger.LeveledPrintf(log.LevelInfo, "No build data to clean\n")
+		return nil
+	}
+	var size int64
+	for _, item := range items {
+		size += item.Size
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("remove", path, fmt.Sprintf("%d builds, %s", len(items), util.FormatSize(size)))
+		return plan.Report(c)
+	}
+	if err := opcli.Confirm(c, fmt.Sprintf("Remove all build data, %d builds (%s)", len(items), util.FormatSize(size))); err != nil {
+		return err
+	}
 	// Run clean
 	if err := builder.CleanBuildData(ws); err != nil {
 		logger.LeveledPrintf(log.LevelError, "Failed to clean build data, error: %s\n", err)
@@ -0,0 +1,77 @@
+// Author: lipixun
+// Created Time : 五 10/16 10:48:33 2026
+//
+// File Name: doc.go
+// Description:
+//	Generate the spec documentation from the spec structures and registered builders / finders
+package build
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repofinder"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"sort"
+)
+
+func SpecDoc(c *cli.Context) error {
+	opcli.StartPager(c)
+	writeSpecDoc(os.Stdout)
+	// Done
+	return nil
+}
+
+func writeSpecDoc(w io.Writer) {
+	fmt.Fprintln(w, "# Openlight spec reference")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "This document is generated by `op spec-doc`, do not edit it manually.")
+	// The spec files
+	writeSpecKeysDoc(w, fmt.Sprintf("Sourcecode spec (`%s`)", spec.SpecFileName), spec.DescribeKeys(spec.RepositorySpec{}))
+	writeSpecKeysDoc(w, fmt.Sprintf("Runner spec (`%s`)", runner.SpecFileName), spec.DescribeKeys(runner.RunnerSpec{}))
+	// The build types
+	var buildTypes []string
+	for t := range builder.SourceCodeBuilders {
+		buildTypes = append(buildTypes, t)
+	}
+	sort.Strings(buildTypes)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Build types (`targets.<key>.build.type`)")
+	fmt.Fprintln(w)
+	for _, t := range buildTypes {
+		fmt.Fprintf(w, "- `%s`\n", t)
+	}
+	// The finder types
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Repository finders (`references.<key>.finder.type`)")
+	fmt.Fprintln(w)
+	for _, t := range repofinder.GetFinderTypes() {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "### `%s`\n", t)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Param | Type | Required | Default | Usage |")
+		fmt.Fprintln(w, "| ----- | ---- | -------- | ------- | ----- |")
+		for _, param := range repofinder.GetFinder(t).Params() {
+			var def string
+			if !param.Required && param.Default != nil {
+				def = fmt.Sprintf("`%v`", param.Default)
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %v | %s | %s |\n", param.Name, param.Type, param.Required, def, param.Usage)
+		}
+	}
+}
+
+func writeSpecKeysDoc(w io.Writer, title string, docs []spec.KeyDoc) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "## %s\n", title)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Key | Type |")
+	fmt.Fprintln(w, "| --- | ---- |")
+	for _, doc := range docs {
+		fmt.Fprintf(w, "| `%s` | %s |\n", doc.Path, doc.Type)
+	}
+}
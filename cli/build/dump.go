@@ -0,0 +1,122 @@
+// Author: lipixun
+// Created Time : 五 10/16 16:02:37 2026
+//
+// File Name: dump.go
+// Description:
+//	Dump the loaded repository spec
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repoloader"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DumpOutputYaml = "yaml"
+	DumpOutputJson = "json"
+)
+
+func SpecDump(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Get the repository path
+	var path string
+	if len(c.Args()) > 1 {
+		logger.LeveledPrintln(log.LevelError, "Only one repository path is allowed")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	} else if len(c.Args()) == 1 {
+		path = c.Args()[0]
+	} else {
+		path, err = opcli.GetGitRootFromCurrentDirectory()
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get current git root directory, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	realPath, err := util.GetRealPath(path)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get real path of [%s], error: %s\n", path, err)
+		return cli.NewExitError("", 1)
+	}
+	// Load the spec
+	filename := filepath.Join(realPath, spec.SpecFileName)
+	repoSpec, err := repoloader.LoadRepositorySpecFromFile(filename)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load repository spec file [%s], error: %s\n", filename, err)
+		return cli.NewExitError("", 1)
+	}
+	// Dump
+	output := c.String("output")
+	if output == "" {
+		output = DumpOutputYaml
+		if c.GlobalString("output") == opcli.OutputJson {
+			output = DumpOutputJson
+		}
+	}
+	data, err := dumpRepositorySpec(repoSpec, output)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to dump repository spec file [%s], error: %s\n", filename, err)
+		return cli.NewExitError("", 1)
+	}
+	opcli.StartPager(c)
+	os.Stdout.Write(data)
+	// Done
+	return nil
+}
+
+// Dump the repository spec in the output format. Map keys are sorted in both formats so the output could be diffed.
+func dumpRepositorySpec(repoSpec *spec.RepositorySpec, output string) ([]byte, error) {
+	data, err := yaml.Marshal(repoSpec)
+	if err != nil {
+		return nil, err
+	}
+	switch output {
+	case DumpOutputYaml:
+		return data, nil
+	case DumpOutputJson:
+		// Convert through the yaml form to keep the key names of the spec file
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(yamlToJsonValue(value), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown output format [%s]", output))
+	}
+}
+
+// Convert the value unmarshaled by yaml to a value could be marshaled by json
+func yamlToJsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for key, item := range v {
+			m[fmt.Sprint(key)] = yamlToJsonValue(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlToJsonValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,179 @@
+// Author: lipixun
+// Created Time : 六 10/17 12:40:52 2026
+//
+// File Name: fetch.go
+// Description:
+//	Fetch and upload the files of the remote urls (http, s3, gs, oci)
+//
+//	The build artifacts could be uploaded by the artifact manifest, e.g.
+//		op upload --manifest output/artifacts.json --artifact package s3://bucket/releases/
+package build
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"gopkg.in/urfave/cli.v1"
+	"strings"
+)
+
+// Fetch the url into the workspace cache and print the local path
+func Fetch(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if len(c.Args()) != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one url\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	f, err := fetcher.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create fetcher, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	path, err := f.Fetch(c.Args()[0], c.String("sha256"))
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	logger.Printf("%s\n", path)
+	// Done
+	return nil
+}
+
+// Resolve the oci url to the digest
+func Resolve(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if len(c.Args()) != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one url\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	f, err := fetcher.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create fetcher, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	digest, err := f.Resolve(c.Args()[0])
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	logger.Printf("%s\n", digest)
+	// Done
+	return nil
+}
+
+// Upload the files to the url
+func Upload(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	var paths []string
+	var target string
+	if manifestPath := c.String("manifest"); manifestPath != "" {
+		if len(c.Args()) != 1 {
+			logger.LeveledPrintf(log.LevelError, "Require exactly one url\n")
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+		manifest, err := artifact.ReadManifest(manifestPath)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to read artifact manifest, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		if paths, err = getManifestUploadPaths(manifest, c.StringSlice("artifact")); err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+		target = c.Args()[0]
+	} else if len(c.Args()) < 2 {
+		logger.LeveledPrintf(log.LevelError, "Require the files and the url\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	} else {
+		paths, target = c.Args()[:len(c.Args())-1], c.Args()[len(c.Args())-1]
+	}
+	if len(paths) > 1 && !(uri.IsObjectURI(target) && strings.HasSuffix(target, "/")) {
+		logger.LeveledPrintf(log.LevelError, "Require a s3:// or gs:// prefix ends with / to upload multiple files\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		for _, path := range paths {
+			plan.Add("upload", path, fmt.Sprintf("to %s", target))
+		}
+		return plan.Report(c)
+	}
+	f, err := fetcher.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create fetcher, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	for _, path := range paths {
+		if err := f.Upload(path, target); err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Uploaded %d files to %s\n", len(paths), target)
+	// Done
+	return nil
+}
+
+// Get the files to upload of the artifacts in the manifest, the archive files and the single (or compressed) files
+// Parameters:
+//
+//	selectors 	The artifact names or the target keys, all uploadable artifacts if empty
+func getManifestUploadPaths(manifest *artifact.Manifest, selectors []string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, selector := range selectors {
+		selected[selector] = false
+	}
+	var paths []string
+	for _, art := range manifest.Artifacts {
+		name, targetKey := art.GetName(), art.GetInfo().Target
+		_, byName := selected[name]
+		_, byTarget := selected[targetKey]
+		if len(selected) > 0 && !byName && !byTarget {
+			continue
+		}
+		if byName {
+			selected[name] = true
+		}
+		if byTarget {
+			selected[targetKey] = true
+		}
+		switch t := art.Artifact.(type) {
+		case *artifact.ArchiveArtifact:
+			paths = append(paths, t.Path)
+		case *artifact.FileArtifact:
+			if len(t.Files) == 0 || t.Compressed {
+				paths = append(paths, t.Path)
+			} else if byName {
+				return nil, errors.New(fmt.Sprintf("Cannot upload artifact [%s] of %d files, collect it as an archive instead", name, len(t.Files)))
+			}
+		default:
+			if byName {
+				return nil, errors.New(fmt.Sprintf("Cannot upload %s artifact [%s]", art.GetType(), name))
+			}
+		}
+	}
+	for _, selector := range selectors {
+		if !selected[selector] {
+			return nil, errors.New(fmt.Sprintf("Artifact [%s] not found in the manifest", selector))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("No artifact to upload in the manifest")
+	}
+	return paths, nil
+}
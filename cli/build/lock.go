@@ -0,0 +1,105 @@
+// Author: lipixun
+// Created Time : 六 10/17 09:58:14 2026
+//
+// File Name: lock.go
+// Description:
+//	Lock the remote repositories and the oci artifacts
+package build
+
+import (
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+)
+
+// Lock the remote repositories and the oci artifacts, the locked ones are kept
+func Lock(c *cli.Context) error {
+	return lock(c, false)
+}
+
+// Resolve the remote repositories and the oci artifacts (all by default) again and update the lock file
+func Update(c *cli.Context) error {
+	return lock(c, true)
+}
+
+func lock(c *cli.Context, update bool) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	path, err := opcli.GetGitRootFromCurrentDirectory()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get current git root directory, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	lockFile := filepath.Join(path, graph.LockFileName)
+	lock, err := graph.LoadLock(lockFile)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load lock file, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if update {
+		if len(c.Args()) == 0 {
+			lock.Repositories, lock.Artifacts = nil, nil
+		}
+		for _, arg := range c.Args() {
+			if lock.Get(arg) == nil && lock.GetArtifact(arg) == nil {
+				logger.LeveledPrintf(log.LevelWarn, "Repository or artifact [%s] is not locked\n", arg)
+			}
+			lock.Remove(arg)
+			lock.RemoveArtifact(arg)
+		}
+	}
+	// Load all targets of current repository
+	g, err := graph.New(ws, graph.GraphOptions{DisableFinder: true})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create sourcecode graph, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	g.Lock = lock
+	if _, err := g.Load(path, graph.LoadOptions{}); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load repository [%s], error: %s\n", path, err)
+		return cli.NewExitError("", 1)
+	}
+	// Resolve the oci artifacts referenced by the docker build files
+	var f *fetcher.Fetcher
+	for _, target := range g.Targets {
+		if target.Spec.Build.Docker == nil {
+			continue
+		}
+		for _, file := range target.Spec.Build.Docker.Files {
+			if file.Source.Http == nil || !uri.IsOCIURI(file.Source.Http.Url) {
+				continue
+			}
+			if f == nil {
+				if f, err = fetcher.New(ws); err != nil {
+					logger.LeveledPrintf(log.LevelError, "Failed to create fetcher, error: %s\n", err)
+					return cli.NewExitError("", 1)
+				}
+			}
+			if _, err := g.PinArtifact(file.Source.Http.Url, f.Resolve); err != nil {
+				logger.LeveledPrintf(log.LevelError, "Failed to resolve artifact of target [%s], error: %s\n", target.Key(), err)
+				return cli.NewExitError("", 1)
+			}
+		}
+	}
+	// Write the resolved repositories and artifacts
+	if err := g.Resolved.Save(lockFile); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to write lock file, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	for _, repo := range g.Resolved.Repositories {
+		logger.Printf("%s\t%s\n", repo.Uri, repo.Commit)
+	}
+	for _, artifact := range g.Resolved.Artifacts {
+		logger.Printf("%s\t%s\n", artifact.Uri, artifact.Digest)
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Locked %d repositories and %d artifacts in %s\n", len(g.Resolved.Repositories), len(g.Resolved.Artifacts), lockFile)
+	// Done
+	return nil
+}
@@ -7,6 +7,7 @@
 package build
 
 import (
+	opcli "github.com/ops-openlight/openlight/cli"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -17,11 +18,12 @@ const (
 func GetCommand() []cli.Command {
 	return []cli.Command{
 		{
-			Category: "Builder",
-			Name:     "local-build",
-			Aliases:  []string{"lb"},
-			Usage:    "Force build with local dependencies. The same as 'op build --only-local' ",
-			Action:   LocalBuild,
+			Category:     "Builder",
+			Name:         "local-build",
+			Aliases:      []string{"lb"},
+			Usage:        "Force build with local dependencies. The same as 'op build --only-local' ",
+			Action:       LocalBuild,
+			BashComplete: opcli.BashComplete(nil, opcli.CompleteTargets),
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "output, o",
@@ -36,6 +38,106 @@ func GetCommand() []cli.Command {
 					Name:  "repository-remote-overwrite, w",
 					Usage: "Overwrite the repository remote (or local path). Format: uri:path",
 				},
+				cli.BoolFlag{
+					Name:  "trace",
+					Usage: "Log the time of loading each repository and target",
+				},
+				cli.BoolFlag{
+					Name:  "ignore-lock",
+					Usage: "Resolve the remote repositories without the lock file (op.lock)",
+				},
+			},
+		},
+		{
+			Category: "Builder",
+			Name:     "lock",
+			Usage:    "Lock the commits of the remote repositories and the digests of the oci artifacts of current repository in op.lock, the locked ones are kept",
+			Action:   Lock,
+		},
+		{
+			Category:  "Builder",
+			Name:      "update",
+			Usage:     "Resolve the remote repositories and oci artifacts (all by default) again and update op.lock",
+			ArgsUsage: "[repository uri or artifact url...]",
+			Action:    Update,
+		},
+		{
+			Category:  "Builder",
+			Name:      "fetch",
+			Usage:     "Fetch the url (http, https, s3, gs, oci) into the workspace cache and print the local path",
+			ArgsUsage: "<url>",
+			Action:    Fetch,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "sha256",
+					Usage: "The expected sha256 digest of the content",
+				},
+			},
+		},
+		{
+			Category:  "Builder",
+			Name:      "resolve",
+			Usage:     "Resolve the oci url (oci://registry/repository:tag) to the manifest digest",
+			ArgsUsage: "<url>",
+			Action:    Resolve,
+		},
+		{
+			Category:  "Builder",
+			Name:      "upload",
+			Usage:     "Upload the files (e.g. the build artifacts) to the url (http, https, s3, gs, oci), a s3 or gs url ends with / is a prefix",
+			ArgsUsage: "<file...> <url>",
+			Action:    Upload,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "manifest",
+					Usage: "Upload the archive and single file artifacts in the artifact manifest (e.g. artifacts.json of op build) instead of the files, the arguments are only the url",
+				},
+				cli.StringSliceFlag{
+					Name:  "artifact",
+					Usage: "The artifact name or the target key of the artifacts to upload from the manifest, all artifacts by default",
+				},
+			},
+		},
+		{
+			Category: "Builder",
+			Name:     "validate",
+			Usage:    "Validate the sourcecode spec of the repositories (current repository by default)",
+			Action:   Validate,
+		},
+		{
+			Category:  "Builder",
+			Name:      "query",
+			Usage:     "Query the targets of current repository, e.g. deps(target), rdeps(target), kind(golang, ...)",
+			ArgsUsage: "<expression>",
+			Action:    Query,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "The output format: text, json or yaml. The global --output if not set",
+				},
+				cli.BoolFlag{
+					Name:  "disable-finder",
+					Usage: "Disable the repository local finder",
+				},
+			},
+		},
+		{
+			Category: "Builder",
+			Name:     "spec-doc",
+			Usage:    "Generate the markdown documentation of the spec files",
+			Action:   SpecDoc,
+		},
+		{
+			Category:  "Builder",
+			Name:      "spec-dump",
+			Usage:     "Print the repository spec as loaded (current repository by default), e.g. with short form dependencies expanded",
+			ArgsUsage: "[repository path]",
+			Action:    SpecDump,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "The output format, either yaml or json. Json if the global --output is json, otherwise yaml",
+				},
 			},
 		},
 		{
@@ -43,6 +145,9 @@ func GetCommand() []cli.Command {
 			Name:     "clean-build",
 			Usage:    "Clean the build workspace. This will clean user ALL build data",
 			Action:   Clean,
+			Flags: []cli.Flag{
+				opcli.YesFlag,
+			},
 		},
 	}
 }
@@ -0,0 +1,89 @@
+// Author: lipixun
+// Created Time : 五 10/16 13:41:09 2026
+//
+// File Name: query.go
+// Description:
+//	Query the targets of the sourcecode graph
+package build
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"strings"
+)
+
+type QueryResult struct {
+	Key        string `json:"key"`
+	Repository string `json:"repository"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Path       string `json:"path"`
+}
+
+func Query(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Check parameters
+	expr := strings.Join(c.Args(), " ")
+	if expr == "" {
+		logger.LeveledPrintln(log.LevelError, "Require query expression")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	renderer, err := opcli.GetRenderer(c, c.String("output"))
+	if err != nil {
+		return err
+	}
+	// Load the current repository with all targets
+	rootPath, err := opcli.GetGitRootFromCurrentDirectory()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get current git root directory, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	g, err := graph.New(ws, graph.GraphOptions{UseLocalDependency: true, DisableFinder: c.Bool("disable-finder")})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create sourcecode graph, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	root, err := g.Load(rootPath, graph.LoadOptions{})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load repository [%s], error: %s\n", rootPath, err)
+		return cli.NewExitError("", 1)
+	}
+	// Query
+	targets, err := g.Query(expr, root)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to query, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	// Output
+	opcli.StartPager(c)
+	if renderer.Structured() {
+		results := []QueryResult{}
+		for _, target := range targets {
+			results = append(results, QueryResult{
+				Key:        target.Key(),
+				Repository: target.Repository.Uri,
+				Name:       target.Name,
+				Type:       target.Spec.Build.Type,
+				Path:       target.Path(),
+			})
+		}
+		if err := renderer.Render(results); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render query results, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	} else {
+		for _, target := range targets {
+			fmt.Fprintln(os.Stdout, target.Key())
+		}
+	}
+	// Done
+	return nil
+}
@@ -0,0 +1,133 @@
+// Author: lipixun
+// Created Time : 五 10/16 09:40:18 2026
+//
+// File Name: validate.go
+// Description:
+//	Validate the repository spec
+package build
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repofinder"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repoloader"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+var (
+	yamlErrorLineRegularExp = regexp.MustCompile(`line (\d+)`) // The line of the yaml parsing error
+)
+
+func Validate(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Get the repository paths
+	paths := c.Args()
+	if len(paths) == 0 {
+		path, err := opcli.GetGitRootFromCurrentDirectory()
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get current git root directory, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		paths = append(paths, path)
+	}
+	// Validate each repository
+	var failed bool
+	for _, path := range paths {
+		realPath, err := util.GetRealPath(path)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get real path of [%s], error: %s\n", path, err)
+			failed = true
+			continue
+		}
+		filename := filepath.Join(realPath, spec.SpecFileName)
+		repoSpec, err := repoloader.LoadRepositorySpecFromFileStrict(filename)
+		if err != nil {
+			annotation := opcli.Annotation{Level: opcli.AnnotationError, File: filename, Title: "Invalid spec", Message: err.Error()}
+			location := filename
+			if match := yamlErrorLineRegularExp.FindStringSubmatch(err.Error()); match != nil {
+				annotation.Line, _ = strconv.Atoi(match[1])
+				location = fmt.Sprintf("%s:%d", filename, annotation.Line)
+			}
+			logger.LeveledPrintf(log.LevelError, "%s: %s\n", location, err)
+			opcli.Annotate(annotation)
+			failed = true
+			continue
+		}
+		finderErrs, warnings := validateFinderParams(repoSpec)
+		errs := append(repoSpec.Validate(), finderErrs...)
+		spec.LocateValidationErrors(filename, errs)
+		spec.LocateValidationErrors(filename, warnings)
+		for _, err := range errs {
+			reportValidationError(logger, filename, err, opcli.AnnotationError)
+		}
+		for _, warning := range warnings {
+			reportValidationError(logger, filename, warning, opcli.AnnotationWarning)
+		}
+		if len(errs) > 0 {
+			failed = true
+		} else {
+			logger.LeveledPrintf(log.LevelSuccess, "%s: OK\n", filename)
+		}
+	}
+	if failed {
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+// Print and annotate the validation error (or warning) at its line
+func reportValidationError(logger log.Logger, filename string, err spec.ValidationError, level string) {
+	location := filename
+	if err.Line > 0 {
+		location = fmt.Sprintf("%s:%d", filename, err.Line)
+	}
+	title, logLevel := "Invalid spec", log.LevelError
+	if level == opcli.AnnotationWarning {
+		title, logLevel = "Spec warning", log.LevelWarn
+	}
+	logger.LeveledPrintf(logLevel, "%s: %s\n", location, err.Error())
+	opcli.Annotate(opcli.Annotation{
+		Level:   level,
+		File:    filename,
+		Line:    err.Line,
+		Title:   title,
+		Message: err.Error(),
+	})
+}
+
+// Validate the finder parameters of the references by the finder schemas
+// Returns:
+// 	The errors and the warnings (the unknown parameters which are ignored when finding)
+func validateFinderParams(repoSpec *spec.RepositorySpec) ([]spec.ValidationError, []spec.ValidationError) {
+	var errs, warnings []spec.ValidationError
+	for uri, refer := range repoSpec.References {
+		if refer == nil || refer.Finder.Type == "" {
+			continue
+		}
+		path := fmt.Sprintf("references.%s.finder", uri)
+		finder := repofinder.GetFinder(refer.Finder.Type)
+		if finder == nil {
+			errs = append(errs, spec.ValidationError{Path: path + ".type", Message: fmt.Sprintf("Unknown finder type [%s]", refer.Finder.Type)})
+		} else {
+			if _, err := finder.Params().Parse(refer.Finder.Params); err != nil {
+				errs = append(errs, spec.ValidationError{Path: path + ".params", Message: err.Error()})
+			}
+			for _, name := range finder.Params().Unknown(refer.Finder.Params) {
+				warnings = append(warnings, spec.ValidationError{Path: path + ".params." + name, Message: fmt.Sprintf("Unknown parameter [%s] is ignored", name)})
+			}
+		}
+	}
+	// Done
+	return errs, warnings
+}
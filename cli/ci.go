@@ -0,0 +1,170 @@
+// Author: lipixun
+// Created Time : 日 10/18 08:26:51 2026
+//
+// File Name: ci.go
+// Description:
+//	The annotations of the failures for the CI systems, enabled by --ci (or OP_CI):
+//		github 	The workflow commands printed to stderr (the runner reads both stdout and stderr, the results on
+//				stdout are kept clean), e.g. ::error file=op.yaml,line=12,title=...::message, shown inline on the
+//				pull request
+//		gitlab 	The code quality report written to gl-code-quality-report.json in the work dir on exit, declare it
+//				in artifacts:reports:codequality of the job to show it on the merge request (gitlab has no inline
+//				annotation syntax in the job log)
+//	The build failures (op build), the test failures (op test) and the spec findings (op validate) are annotated at
+//	the spec files, the file paths are relative to the git root of the work dir. The logs are not changed.
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	CIGithub = "github"
+	CIGitlab = "gitlab"
+
+	CIGitlabReportFileName = "gl-code-quality-report.json"
+
+	AnnotationError   = "error"
+	AnnotationWarning = "warning"
+)
+
+// An annotation of a failure
+type Annotation struct {
+	Level   string // One of Annotation*
+	File    string // The path of the file, not annotated at a file if empty or not in the git root
+	Line    int    // The line number from 1, the whole file if not positive
+	Title   string
+	Message string
+}
+
+// The code quality issue of gitlab
+type gitlabIssue struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+		} `json:"lines"`
+	} `json:"location"`
+}
+
+var ciAnnotations struct {
+	lock   sync.Mutex
+	format string
+	root   string // The git root of the work dir
+	issues []gitlabIssue
+}
+
+// Set the ci format, should be called before running the command (e.g. in app.Before, after SetWorkDir)
+func SetCIFormat(format string) error {
+	switch format {
+	case "":
+		return nil
+	case CIGithub, CIGitlab:
+	default:
+		return cli.NewExitError(fmt.Sprintf("Unknown ci [%s], should be one of %s, %s", format, CIGithub, CIGitlab), ExitCodeUsage)
+	}
+	ciAnnotations.format = format
+	ciAnnotations.root, _ = GetGitRootFromCurrentDirectory()
+	// Done
+	return nil
+}
+
+// Annotate the failure, nothing if --ci is not specified
+func Annotate(annotation Annotation) {
+	ciAnnotations.lock.Lock()
+	defer ciAnnotations.lock.Unlock()
+	if ciAnnotations.format == "" {
+		return
+	}
+	file := getAnnotationPath(annotation.File)
+	switch ciAnnotations.format {
+	case CIGithub:
+		var properties []string
+		if file != "" {
+			properties = append(properties, "file="+escapeGithubProperty(file))
+			if annotation.Line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", annotation.Line))
+			}
+		}
+		if annotation.Title != "" {
+			properties = append(properties, "title="+escapeGithubProperty(annotation.Title))
+		}
+		command := annotation.Level
+		if len(properties) > 0 {
+			command += " " + strings.Join(properties, ",")
+		}
+		fmt.Fprintf(os.Stderr, "::%s::%s\n", command, escapeGithubData(annotation.Message))
+	case CIGitlab:
+		issue := gitlabIssue{Description: annotation.Message, CheckName: annotation.Title, Severity: "major"}
+		if annotation.Title != "" {
+			issue.Description = fmt.Sprintf("%s: %s", annotation.Title, annotation.Message)
+		}
+		if annotation.Level == AnnotationWarning {
+			issue.Severity = "minor"
+		}
+		issue.Location.Path, issue.Location.Lines.Begin = file, annotation.Line
+		if issue.Location.Lines.Begin <= 0 {
+			issue.Location.Lines.Begin = 1
+		}
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%d\n%s", issue.CheckName, file, annotation.Line, annotation.Message)))
+		issue.Fingerprint = hex.EncodeToString(hash[:16])
+		ciAnnotations.issues = append(ciAnnotations.issues, issue)
+	}
+}
+
+// Write the gitlab code quality report if any failure annotated, should be called on exit
+func FlushAnnotations() {
+	ciAnnotations.lock.Lock()
+	defer ciAnnotations.lock.Unlock()
+	if ciAnnotations.format != CIGitlab || len(ciAnnotations.issues) == 0 {
+		return
+	}
+	workDir, err := GetWorkDir()
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(ciAnnotations.issues, "", "  ")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(workDir, CIGitlabReportFileName)
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the code quality report [%s], error: %s\n", path, err)
+	}
+	ciAnnotations.issues = nil
+}
+
+// Get the path of the file relative to the git root, empty if not in the git root
+func getAnnotationPath(path string) string {
+	if path == "" || ciAnnotations.root == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(ciAnnotations.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Escape the message of the github workflow command
+func escapeGithubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// Escape the property value of the github workflow command
+func escapeGithubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
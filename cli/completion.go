@@ -0,0 +1,90 @@
+// Author: lipixun
+// Created Time : 六 10/17 19:24:36 2026
+//
+// File Name: completion.go
+// Description:
+//	The shell completion of the commands
+//
+//	The completion scripts (see op completion) call op with the words before the cursor, the current word (may be
+//	empty) and the --generate-bash-completion flag, e.g.
+//		op stop --id "" --generate-bash-completion
+//	The command prints the candidates one per line, the shell filters them by the current word. The logs are written
+//	to stderr and dropped by the scripts.
+package cli
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repoloader"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CompletionFlag = "--generate-bash-completion"
+)
+
+// A completer returns the candidates of a flag value or an argument
+type Completer func(c *cli.Context) []string
+
+// Create the completion function of a command
+// Parameters:
+//
+//	flags 	The completers of the flag values, keyed by the flag names (each name of the flag, e.g. id and i)
+//	args 	The completer of the arguments, nil to complete nothing
+func BashComplete(flags map[string]Completer, args Completer) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		current, previous := getCompletionWords()
+		var candidates []string
+		if strings.HasPrefix(current, "-") {
+			// The flag names
+			for _, flag := range c.Command.Flags {
+				for _, name := range strings.Split(flag.GetName(), ",") {
+					if name = strings.TrimSpace(name); len(name) == 1 {
+						candidates = append(candidates, "-"+name)
+					} else if name != "" {
+						candidates = append(candidates, "--"+name)
+					}
+				}
+			}
+		} else if completer, ok := flags[strings.TrimLeft(previous, "-")]; ok && strings.HasPrefix(previous, "-") {
+			candidates = completer(c)
+		} else if args != nil {
+			candidates = args(c)
+		}
+		for _, candidate := range candidates {
+			fmt.Println(candidate)
+		}
+	}
+}
+
+// Complete the target names of current repository
+func CompleteTargets(c *cli.Context) []string {
+	path, err := GetGitRootFromCurrentDirectory()
+	if err != nil {
+		return nil
+	}
+	repoSpec, err := repoloader.LoadRepositorySpecFromFile(filepath.Join(path, spec.SpecFileName))
+	if err != nil {
+		return nil
+	}
+	return repoSpec.GetTargetNames()
+}
+
+// Get the current word and the previous word of the completion
+func getCompletionWords() (string, string) {
+	args := os.Args
+	if len(args) > 0 && args[len(args)-1] == CompletionFlag {
+		args = args[:len(args)-1]
+	}
+	var current, previous string
+	if len(args) > 1 {
+		current = args[len(args)-1]
+	}
+	if len(args) > 2 {
+		previous = args[len(args)-2]
+	}
+	return current, previous
+}
@@ -0,0 +1,89 @@
+// Author: lipixun
+// Created Time : 六 10/17 19:31:02 2026
+//
+// File Name: main.go
+// Description:
+//	The completion command prints the completion script of the shell, e.g.
+//		bash 	echo 'source <(op completion bash)' >> ~/.bashrc
+//		zsh 	echo 'source <(op completion zsh)' >> ~/.zshrc
+//		fish 	op completion fish > ~/.config/fish/completions/op.fish
+package completion
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"gopkg.in/urfave/cli.v1"
+	"sort"
+	"strings"
+)
+
+const (
+	BashScript = `# The bash completion of op
+_op_complete() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local IFS=$'\n'
+	COMPREPLY=( $(compgen -W "$("${COMP_WORDS[@]:0:$COMP_CWORD}" "$cur" --generate-bash-completion 2>/dev/null)" -- "$cur") )
+	return 0
+}
+complete -o default -F _op_complete op
+`
+	ZshScript = `#compdef op
+# The zsh completion of op
+_op_complete() {
+	local -a candidates
+	candidates=("${(@f)$(${words[@]:0:$((CURRENT-1))} "${words[CURRENT]}" --generate-bash-completion 2>/dev/null)}")
+	compadd -a candidates
+}
+compdef _op_complete op
+`
+	FishScript = `# The fish completion of op
+function __op_complete
+	set -l tokens (commandline -opc)
+	$tokens (commandline -ct) --generate-bash-completion 2>/dev/null
+end
+complete -c op -f -a '(__op_complete)'
+`
+)
+
+var (
+	scripts = map[string]string{"bash": BashScript, "zsh": ZshScript, "fish": FishScript}
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Name:      "completion",
+			Usage:     "Print the shell completion script, e.g. source <(op completion bash)",
+			ArgsUsage: "<" + strings.Join(getShells(), "|") + ">",
+			Action:    completion,
+			BashComplete: func(c *cli.Context) {
+				for _, shell := range getShells() {
+					fmt.Println(shell)
+				}
+			},
+		},
+	}
+}
+
+func completion(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return cli.NewExitError(fmt.Sprintf("Require the shell, one of %s", strings.Join(getShells(), ", ")), opcli.ExitCodeUsage)
+	}
+	script, ok := scripts[c.Args().First()]
+	if !ok {
+		return cli.NewExitError(fmt.Sprintf("Unknown shell [%s], should be one of %s", c.Args().First(), strings.Join(getShells(), ", ")), opcli.ExitCodeUsage)
+	}
+	fmt.Print(script)
+	// Done
+	return nil
+}
+
+// Get the supported shells in sorted order
+func getShells() []string {
+	var shells []string
+	for shell := range scripts {
+		shells = append(shells, shell)
+	}
+	sort.Strings(shells)
+	return shells
+}
@@ -0,0 +1,249 @@
+// Author: lipixun
+// Created Time : 五 10/16 23:55:37 2026
+//
+// File Name: config.go
+// Description:
+//	The config commands
+package config
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+)
+
+// A config value in the structured output
+type ValueResult struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Layer       string `json:"layer"` // The layer defines the value, default if not defined
+	Description string `json:"description,omitempty"`
+}
+
+// A rewrite rule or a rewritten url in the structured output
+type RewriteResult struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Layer string `json:"layer,omitempty"` // The layer defines the rule
+}
+
+func Get(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one key\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	key := c.Args().First()
+	if workspace.GetConfigKey(key) == nil {
+		logger.LeveledPrintf(log.LevelError, "Unknown config key [%s]\n", key)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	value, layer, _ := ws.Config.Lookup(key)
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	if renderer.Structured() {
+		if layer == "" {
+			layer = "default"
+		}
+		return render(renderer, ValueResult{Key: key, Value: value, Layer: layer}, logger)
+	}
+	fmt.Println(value)
+	// Done
+	return nil
+}
+
+func Set(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 2 {
+		logger.LeveledPrintf(log.LevelError, "Require key and value\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	layer, err := getLayer(c, ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if err := layer.Set(c.Args().Get(0), c.Args().Get(1)); err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("set", c.Args().Get(0), fmt.Sprintf("%s in %s", c.Args().Get(1), layer.Path))
+		return plan.Report(c)
+	}
+	if err := layer.Save(); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to write config file [%s], error: %s\n", layer.Path, err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+func Unset(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one key\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	layer, err := getLayer(c, ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("unset", c.Args().First(), layer.Path)
+		return plan.Report(c)
+	}
+	layer.Unset(c.Args().First())
+	if err := layer.Save(); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to write config file [%s], error: %s\n", layer.Path, err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+func List(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("explain") {
+		ws.Config.Layered().Explain(os.Stdout)
+		return nil
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	results := []ValueResult{}
+	for _, key := range workspace.ConfigKeys {
+		names := []string{key.Name}
+		if prefix, ok := key.Prefix(); ok {
+			// List the keys set of the prefix
+			names = ws.Config.KeysWithPrefix(prefix)
+		}
+		for _, name := range names {
+			value, layer, _ := ws.Config.Lookup(name)
+			if layer == "" {
+				layer = "default"
+			}
+			results = append(results, ValueResult{Key: name, Value: value, Layer: layer, Description: key.Description})
+		}
+	}
+	if renderer.Structured() {
+		return render(renderer, results, ws.Logger.GetLoggerWithHeader(LogHeader))
+	}
+	for _, result := range results {
+		fmt.Printf("%s=%s\t(%s) %s\n", result.Key, result.Value, result.Layer, result.Description)
+	}
+	// Done
+	return nil
+}
+
+// List the rewrite rules, or rewrite the urls in the arguments
+func Rewrites(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() > 0 {
+		rules := ws.RewriteRules()
+		results := []RewriteResult{}
+		for _, arg := range c.Args() {
+			rewritten, _ := rules.Rewrite(arg)
+			results = append(results, RewriteResult{From: arg, To: rewritten})
+		}
+		if renderer.Structured() {
+			return render(renderer, results, logger)
+		}
+		for _, result := range results {
+			fmt.Printf("%s\t%s\n", result.From, result.To)
+		}
+		return nil
+	}
+	layered := ws.LoadRewrites()
+	if c.Bool("explain") {
+		layered.Explain(os.Stdout)
+		return nil
+	}
+	results := []RewriteResult{}
+	for _, key := range layered.Keys() {
+		results = append(results, RewriteResult{From: key, To: fmt.Sprint(layered[key].Value), Layer: layered[key].Source.Layer})
+	}
+	if renderer.Structured() {
+		return render(renderer, results, logger)
+	}
+	for _, result := range results {
+		fmt.Printf("%s -> %s\t(%s)\n", result.From, result.To, result.Layer)
+	}
+	// Done
+	return nil
+}
+
+// Render the results in the structured output
+func render(renderer *opcli.Renderer, value interface{}, logger log.Logger) error {
+	if err := renderer.Render(value); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to render the results, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+// Get the config layer selected by the flags, the user layer by default
+func getLayer(c *cli.Context, ws *workspace.Workspace) (*workspace.ConfigLayer, error) {
+	name := workspace.ConfigLayerUser
+	if c.Bool("global") && c.Bool("project") {
+		return nil, errors.New("Cannot specify both --global and --project")
+	} else if c.Bool("global") {
+		name = workspace.ConfigLayerGlobal
+	} else if c.Bool("project") {
+		name = workspace.ConfigLayerProject
+	}
+	layer := ws.Config.Layer(name)
+	if layer == nil {
+		return nil, errors.New(fmt.Sprintf("Config layer [%s] not loaded", name))
+	}
+	return layer, nil
+}
+
+// Complete the config key, then the value of the bool key
+func completeKeys(c *cli.Context) []string {
+	// The last argument is the word being completed
+	switch c.NArg() {
+	case 0, 1:
+		var keys []string
+		for _, key := range workspace.ConfigKeys {
+			keys = append(keys, key.Name)
+		}
+		return keys
+	case 2:
+		if key := workspace.GetConfigKey(c.Args().First()); key != nil && key.Type == workspace.ConfigTypeBool {
+			return []string{"true", "false"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Author: lipixun
+// Created Time : 五 10/16 23:48:10 2026
+//
+// File Name: main.go
+// Description:
+//
+package config
+
+import (
+	opcli "github.com/ops-openlight/openlight/cli"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Config"
+)
+
+func GetCommand() []cli.Command {
+	layerFlags := []cli.Flag{
+		cli.BoolFlag{
+			Name:  "global",
+			Usage: "Use the global config file instead of the user one",
+		},
+		cli.BoolFlag{
+			Name:  "project",
+			Usage: "Use the project config file (.op.config.yaml) instead of the user one",
+		},
+	}
+	return []cli.Command{
+		{
+			Category: "Workspace",
+			Name:     "config",
+			Usage:    "Get or set the workspace config (global, user and project layers)",
+			Subcommands: []cli.Command{
+				{
+					Name:         "get",
+					Usage:        "Print the effective value of the key",
+					ArgsUsage:    "<key>",
+					Action:       Get,
+					BashComplete: opcli.BashComplete(nil, completeKeys),
+				},
+				{
+					Name:         "set",
+					Usage:        "Set the value of the key in the user config file",
+					ArgsUsage:    "<key> <value>",
+					Action:       Set,
+					BashComplete: opcli.BashComplete(nil, completeKeys),
+					Flags:        layerFlags,
+				},
+				{
+					Name:         "unset",
+					Usage:        "Remove the key from the user config file",
+					ArgsUsage:    "<key>",
+					Action:       Unset,
+					BashComplete: opcli.BashComplete(nil, completeKeys),
+					Flags:        layerFlags,
+				},
+				{
+					Name:      "rewrites",
+					Usage:     "List the url rewrite (mirror) rules, or print the rewritten urls",
+					ArgsUsage: "[url...]",
+					Action:    Rewrites,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "explain",
+							Usage: "Show the file supplying each rule and the files it overrides",
+						},
+					},
+				},
+				{
+					Name:   "list",
+					Usage:  "List all keys with the effective values and where they are defined",
+					Action: List,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "explain",
+							Usage: "Show the file supplying each value and the files it overrides",
+						},
+					},
+				},
+			},
+		},
+	}
+}
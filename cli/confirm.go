@@ -0,0 +1,47 @@
+// Author: lipixun
+// Created Time : 日 10/18 00:41:25 2026
+//
+// File Name: confirm.go
+// Description:
+//	The confirmation of the destructive commands
+//
+//	The command prints the summary of what will be deleted and asks for the confirmation on the terminal, unless
+//	--yes (-y) is specified. When stdin is not a terminal (e.g. in scripts or CI) the command fails without --yes
+//	instead of prompting.
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"strings"
+)
+
+// The flag to skip the confirmation
+var YesFlag = cli.BoolFlag{
+	Name:  "yes, y",
+	Usage: "Do not ask for the confirmation, required when stdin is not a terminal",
+}
+
+// Confirm the operation with the summary, returns nil if confirmed or an exit error otherwise
+func Confirm(c *cli.Context, summary string) error {
+	if c.Bool("yes") {
+		return nil
+	}
+	if !log.IsTerminal(os.Stdin) {
+		return cli.NewExitError(fmt.Sprintf("%s. Stdin is not a terminal, specify --yes to confirm", summary), ExitCodeUsage)
+	}
+	fmt.Fprintf(os.Stderr, "%s. Continue? [y/N] ", summary)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(os.Stderr)
+		return cli.NewExitError(fmt.Sprintf("Failed to read the confirmation, error: %s", err), 1)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return nil
+	}
+	return cli.NewExitError("Aborted", 1)
+}
@@ -0,0 +1,294 @@
+// Author: lipixun
+// Created Time : 日 10/18 02:45:03 2026
+//
+// File Name: docs.go
+// Description:
+//	Generate the reference docs from the command and flag definitions of the app
+//
+//	Each visible command has its own page named by the command path, e.g. op-workspace-clean.1 and
+//	op-workspace-clean.md, the page of op lists the global flags and the commands. The hidden commands and flags
+//	are not documented.
+//
+//	The date of the man pages is SOURCE_DATE_EPOCH if set, so the packages built from the same source are identical.
+package docs
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"gopkg.in/urfave/cli.v1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatMan      = "man"
+	FormatMarkdown = "markdown"
+	FormatAll      = "all"
+
+	ManSection = "1"
+
+	SourceDateEpochEnvName = "SOURCE_DATE_EPOCH"
+
+	dateLayout = "2006-01-02"
+)
+
+// The page of a command, or the app
+type page struct {
+	Name        string   // The file name without extension, e.g. op-workspace-clean
+	Path        []string // The command path, e.g. op workspace clean
+	Usage       string
+	ArgsUsage   string
+	Description string
+	Aliases     []string
+	Flags       []flagDoc
+	Commands    []*page
+	Parent      *page
+}
+
+// The documented flag
+type flagDoc struct {
+	Names      []string // The long name first, e.g. output, o
+	Value      string   // The placeholder of the value, empty for the bool flags
+	Default    string
+	Usage      string
+	EnvVars    []string
+	Repeatable bool
+}
+
+// A page renderer
+type renderer struct {
+	Dir       string
+	Extension string
+	Render    func(p *page, date string) string
+}
+
+var renderers = map[string]renderer{
+	FormatMan:      {Dir: "man", Extension: "." + ManSection, Render: renderMan},
+	FormatMarkdown: {Dir: "markdown", Extension: ".md", Render: renderMarkdown},
+}
+
+func Generate(c *cli.Context) error {
+	var formats []string
+	switch format := c.String("format"); format {
+	case "", FormatAll:
+		formats = []string{FormatMan, FormatMarkdown}
+	case FormatMan, FormatMarkdown:
+		formats = []string{format}
+	default:
+		return cli.NewExitError(fmt.Sprintf("Unknown format [%s], should be one of man, markdown, all", format), opcli.ExitCodeUsage)
+	}
+	date, err := getDate(c.String("date"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), opcli.ExitCodeUsage)
+	}
+	output, err := filepath.Abs(c.String("output"))
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Failed to get output abs path, error: %s", err), 1)
+	}
+	root := newAppPage(c.App)
+	for _, format := range formats {
+		r := renderers[format]
+		dir := filepath.Join(output, r.Dir)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Failed to create directory [%s], error: %s", dir, err), 1)
+		}
+		count := 0
+		var err error
+		walk(root, func(p *page) {
+			if err != nil {
+				return
+			}
+			err = ioutil.WriteFile(filepath.Join(dir, p.Name+r.Extension), []byte(r.Render(p, date)), 0666)
+			count++
+		})
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Failed to write %s pages, error: %s", format, err), 1)
+		}
+		fmt.Printf("Generated %d %s pages in %s\n", count, format, dir)
+	}
+	// Done
+	return nil
+}
+
+// Get the date of the pages, SOURCE_DATE_EPOCH or today if not specified
+func getDate(date string) (string, error) {
+	if date != "" {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return "", errors.New(fmt.Sprintf("Malformed date [%s], should be YYYY-MM-DD", date))
+		}
+		return date, nil
+	}
+	if epoch := os.Getenv(SourceDateEpochEnvName); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("Malformed %s [%s], should be the unix timestamp", SourceDateEpochEnvName, epoch))
+		}
+		return time.Unix(seconds, 0).UTC().Format(dateLayout), nil
+	}
+	return time.Now().Format(dateLayout), nil
+}
+
+// Create the page of the app with the pages of the commands
+func newAppPage(app *cli.App) *page {
+	p := &page{
+		Name:        app.Name,
+		Path:        []string{app.Name},
+		Usage:       app.Usage,
+		ArgsUsage:   app.ArgsUsage,
+		Description: app.Description,
+		Flags:       getFlagDocs(app.Flags),
+	}
+	p.Commands = newCommandPages(app.Commands, p)
+	return p
+}
+
+// Create the pages of the visible commands
+func newCommandPages(commands []cli.Command, parent *page) []*page {
+	var pages []*page
+	for _, command := range commands {
+		// The help command is added by the cli to each command
+		if command.Hidden || command.Name == "help" {
+			continue
+		}
+		path := append(append([]string{}, parent.Path...), command.Name)
+		p := &page{
+			Name:        strings.Join(path, "-"),
+			Path:        path,
+			Usage:       command.Usage,
+			ArgsUsage:   command.ArgsUsage,
+			Description: command.Description,
+			Aliases:     command.Aliases,
+			Flags:       getFlagDocs(command.Flags),
+			Parent:      parent,
+		}
+		p.Commands = newCommandPages(command.Subcommands, p)
+		pages = append(pages, p)
+	}
+	return pages
+}
+
+// Get the docs of the visible flags
+func getFlagDocs(flags []cli.Flag) []flagDoc {
+	var docs []flagDoc
+	for _, flag := range flags {
+		var doc flagDoc
+		var envVar string
+		switch f := flag.(type) {
+		case cli.BoolFlag:
+			if f.Hidden {
+				continue
+			}
+			doc = flagDoc{Usage: f.Usage}
+			envVar = f.EnvVar
+		case cli.BoolTFlag:
+			if f.Hidden {
+				continue
+			}
+			doc = flagDoc{Usage: f.Usage, Default: "true"}
+			envVar = f.EnvVar
+		case cli.StringFlag:
+			if f.Hidden {
+				continue
+			}
+			doc = flagDoc{Value: "value", Default: f.Value, Usage: f.Usage}
+			envVar = f.EnvVar
+		case cli.IntFlag:
+			if f.Hidden {
+				continue
+			}
+			doc = flagDoc{Value: "number", Usage: f.Usage}
+			if f.Value != 0 {
+				doc.Default = strconv.Itoa(f.Value)
+			}
+			envVar = f.EnvVar
+		case cli.StringSliceFlag:
+			if f.Hidden {
+				continue
+			}
+			doc = flagDoc{Value: "value", Usage: f.Usage, Repeatable: true}
+			if f.Value != nil {
+				doc.Default = strings.Join([]string(*f.Value), ", ")
+			}
+			envVar = f.EnvVar
+		default:
+			doc = flagDoc{Usage: flag.String()}
+		}
+		for _, name := range strings.Split(flag.GetName(), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				doc.Names = append(doc.Names, name)
+			}
+		}
+		for _, name := range strings.Split(envVar, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				doc.EnvVars = append(doc.EnvVars, name)
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// Get the flag names in the command line form, e.g. --output, -o
+func (this flagDoc) Flags() []string {
+	var flags []string
+	for _, name := range this.Names {
+		if len(name) == 1 {
+			flags = append(flags, "-"+name)
+		} else {
+			flags = append(flags, "--"+name)
+		}
+	}
+	return flags
+}
+
+// Get the notes of the flag, e.g. the default value and the environment variables
+func (this flagDoc) Notes() []string {
+	var notes []string
+	if this.Default != "" {
+		notes = append(notes, fmt.Sprintf("Default: %s", this.Default))
+	}
+	if this.Repeatable {
+		notes = append(notes, "Could be specified multiple times")
+	}
+	if len(this.EnvVars) > 0 {
+		notes = append(notes, fmt.Sprintf("Environment: %s", strings.Join(this.EnvVars, ", ")))
+	}
+	return notes
+}
+
+// Get the synopsis of the command, e.g. op workspace clean [options] <categories>
+func (this *page) Synopsis() string {
+	synopsis := strings.Join(this.Path, " ")
+	if len(this.Flags) > 0 {
+		synopsis += " [options]"
+	}
+	if len(this.Commands) > 0 {
+		synopsis += " <command>"
+	}
+	if this.ArgsUsage != "" {
+		synopsis += " " + this.ArgsUsage
+	}
+	return synopsis
+}
+
+// Get the page of the app
+func (this *page) Root() *page {
+	root := this
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// Walk the page and its descendants
+func walk(p *page, fn func(p *page)) {
+	fn(p)
+	for _, child := range p.Commands {
+		walk(child, fn)
+	}
+}
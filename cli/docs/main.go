@@ -0,0 +1,45 @@
+// Author: lipixun
+// Created Time : 日 10/18 02:41:17 2026
+//
+// File Name: main.go
+// Description:
+//	The docs command generates the reference docs of op from the command and flag definitions, e.g.
+//		op docs generate --output dist/docs
+//	writes the man pages to dist/docs/man and the markdown pages to dist/docs/markdown
+package docs
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "docs",
+			Usage: "The reference docs of op",
+			Subcommands: []cli.Command{
+				{
+					Name:   "generate",
+					Usage:  "Generate the man pages and the markdown reference docs of all commands",
+					Action: Generate,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "output, o",
+							Value: "docs",
+							Usage: "The output path, the pages are written to the man and markdown directories in it",
+						},
+						cli.StringFlag{
+							Name:  "format",
+							Value: FormatAll,
+							Usage: "The format of the pages: man, markdown or all",
+						},
+						cli.StringFlag{
+							Name:  "date",
+							Usage: "The date of the man pages (YYYY-MM-DD), SOURCE_DATE_EPOCH or today if not specified",
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,102 @@
+// Author: lipixun
+// Created Time : 日 10/18 03:02:46 2026
+//
+// File Name: man.go
+// Description:
+//	Render the page in the man (roff) format
+package docs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render the man page
+func renderMan(p *page, date string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH \"%s\" \"%s\" \"%s\" \"%s\" \"%s\"\n", strings.ToUpper(p.Name), ManSection, date, p.Path[0], manManual(p))
+	// Name
+	b.WriteString(".SH NAME\n")
+	if p.Usage != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", manEscape(p.Name), manEscape(p.Usage))
+	} else {
+		fmt.Fprintf(&b, "%s\n", manEscape(p.Name))
+	}
+	// Synopsis
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B %s\n", manEscape(p.Synopsis()))
+	// Description
+	if p.Description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		for _, paragraph := range strings.Split(strings.TrimSpace(p.Description), "\n\n") {
+			fmt.Fprintf(&b, ".PP\n%s\n", manEscape(paragraph))
+		}
+	}
+	if len(p.Aliases) > 0 {
+		fmt.Fprintf(&b, ".SH ALIASES\n%s\n", manEscape(strings.Join(p.Aliases, ", ")))
+	}
+	// Options, the flags of op are the global options of all commands
+	if len(p.Flags) > 0 {
+		if p.Parent == nil {
+			b.WriteString(".SH GLOBAL OPTIONS\n")
+		} else {
+			b.WriteString(".SH OPTIONS\n")
+		}
+		for _, flag := range p.Flags {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR", manEscape(strings.Join(flag.Flags(), ", ")))
+			if flag.Value != "" {
+				fmt.Fprintf(&b, " \\fI%s\\fR", flag.Value)
+			}
+			b.WriteString("\n")
+			if flag.Usage != "" {
+				fmt.Fprintf(&b, "%s\n", manEscape(flag.Usage))
+			}
+			for _, note := range flag.Notes() {
+				fmt.Fprintf(&b, ".br\n%s\n", manEscape(note))
+			}
+		}
+	}
+	// Commands
+	if len(p.Commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, child := range p.Commands {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR(%s)\n", manEscape(child.Name), ManSection)
+			if child.Usage != "" {
+				fmt.Fprintf(&b, "%s\n", manEscape(child.Usage))
+			}
+		}
+	}
+	// See also
+	var refs []string
+	if p.Parent != nil {
+		// The global options are documented in the page of op
+		if root := p.Root(); root != p.Parent {
+			refs = append(refs, fmt.Sprintf("\\fB%s\\fR(%s)", manEscape(root.Name), ManSection))
+		}
+		refs = append(refs, fmt.Sprintf("\\fB%s\\fR(%s)", manEscape(p.Parent.Name), ManSection))
+	}
+	if len(refs) > 0 {
+		fmt.Fprintf(&b, ".SH SEE ALSO\n%s\n", strings.Join(refs, ", "))
+	}
+	// Done
+	return b.String()
+}
+
+// Get the manual name of the page, the usage of op
+func manManual(p *page) string {
+	return strings.Replace(p.Root().Usage, "\"", "'", -1)
+}
+
+// Escape the text for roff: the backslashes, the hyphens and the control characters at the line start
+func manEscape(text string) string {
+	text = strings.Replace(text, "\\", "\\e", -1)
+	text = strings.Replace(text, "-", "\\-", -1)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = "\\&" + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+// Author: lipixun
+// Created Time : 日 10/18 03:15:38 2026
+//
+// File Name: markdown.go
+// Description:
+//	Render the page in the markdown format, the pages are linked by the relative paths
+package docs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render the markdown page
+func renderMarkdown(p *page, date string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", strings.Join(p.Path, " "))
+	if p.Usage != "" {
+		fmt.Fprintf(&b, "%s\n\n", markdownEscape(p.Usage))
+	}
+	fmt.Fprintf(&b, "## Synopsis\n\n```\n%s\n```\n\n", p.Synopsis())
+	if p.Description != "" {
+		fmt.Fprintf(&b, "## Description\n\n%s\n\n", strings.TrimSpace(p.Description))
+	}
+	if len(p.Aliases) > 0 {
+		fmt.Fprintf(&b, "## Aliases\n\n`%s`\n\n", strings.Join(p.Aliases, "`, `"))
+	}
+	// Options, the flags of op are the global options of all commands
+	if len(p.Flags) > 0 {
+		if p.Parent == nil {
+			b.WriteString("## Global Options\n\n")
+		} else {
+			b.WriteString("## Options\n\n")
+		}
+		b.WriteString("| Flag | Description |\n| --- | --- |\n")
+		for _, flag := range p.Flags {
+			name := "`" + strings.Join(flag.Flags(), "`, `") + "`"
+			if flag.Value != "" {
+				name += " *" + flag.Value + "*"
+			}
+			var lines []string
+			if flag.Usage != "" {
+				lines = append(lines, markdownEscape(flag.Usage))
+			}
+			for _, note := range flag.Notes() {
+				lines = append(lines, markdownEscape(note))
+			}
+			fmt.Fprintf(&b, "| %s | %s |\n", name, strings.Join(lines, "<br>"))
+		}
+		b.WriteString("\n")
+	}
+	// Commands
+	if len(p.Commands) > 0 {
+		b.WriteString("## Commands\n\n| Command | Description |\n| --- | --- |\n")
+		for _, child := range p.Commands {
+			fmt.Fprintf(&b, "| [%s](%s.md) | %s |\n", strings.Join(child.Path, " "), child.Name, markdownEscape(child.Usage))
+		}
+		b.WriteString("\n")
+	}
+	// See also
+	if p.Parent != nil {
+		b.WriteString("## See Also\n\n")
+		root := p.Root()
+		fmt.Fprintf(&b, "* [%s](%s.md) for the global options\n", root.Name, root.Name)
+		if root != p.Parent {
+			fmt.Fprintf(&b, "* [%s](%s.md)\n", strings.Join(p.Parent.Path, " "), p.Parent.Name)
+		}
+	}
+	// Done
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// Escape the text in the markdown tables and paragraphs
+func markdownEscape(text string) string {
+	return strings.NewReplacer("\\", "\\\\", "|", "\\|", "*", "\\*", "_", "\\_", "<", "&lt;", ">", "&gt;", "\n", " ").Replace(text)
+}
@@ -0,0 +1,79 @@
+// Author: lipixun
+// Created Time : 日 10/18 01:12:48 2026
+//
+// File Name: env.go
+// Description:
+//	The environment variables of the flags
+//
+//	Each flag could be set by the environment variable, so the CI configuration doesn't need the long command lines:
+//		The global flags 		OP_<FLAG>, e.g. OP_VERBOSE=true, OP_WORKDIR_USER_PATH=/data/op, OP_OUTPUT=json
+//		The command flags 		OP_<COMMAND>_<FLAG>, e.g. OP_LOCAL_BUILD_OUTPUT=dist, OP_TEST_NO_CACHE=1
+//		The subcommand flags 	OP_<COMMAND>_<SUBCOMMAND>_<FLAG>, e.g. OP_WORKSPACE_CLEAN_OLDER_THAN=7d
+//	The names are upper cased and the - is replaced by _. The flag in the command line overwrites the environment
+//	variable, the environment variable overwrites the default value. The environment variables declared by the flag
+//	itself (e.g. OP_WORKSPACE) are kept and take precedence.
+package cli
+
+import (
+	"gopkg.in/urfave/cli.v1"
+	"strings"
+)
+
+const (
+	FlagEnvPrefix = "OP_"
+)
+
+// Set the environment variables of the flags of the app and its commands, should be called after the commands added
+func SetFlagEnvVars(app *cli.App) {
+	app.Flags = setFlagEnvVars(app.Flags, FlagEnvPrefix)
+	setCommandEnvVars(app.Commands, FlagEnvPrefix)
+}
+
+// Set the environment variables of the flags of the commands and their subcommands
+func setCommandEnvVars(commands []cli.Command, prefix string) {
+	for i := range commands {
+		commandPrefix := prefix + GetFlagEnvName(commands[i].Name) + "_"
+		commands[i].Flags = setFlagEnvVars(commands[i].Flags, commandPrefix)
+		setCommandEnvVars(commands[i].Subcommands, commandPrefix)
+	}
+}
+
+// Set the environment variables of the flags, the flags of unknown types are not changed
+func setFlagEnvVars(flags []cli.Flag, prefix string) []cli.Flag {
+	for i, flag := range flags {
+		switch f := flag.(type) {
+		case cli.BoolFlag:
+			f.EnvVar = appendEnvVar(f.EnvVar, prefix+GetFlagEnvName(f.Name))
+			flags[i] = f
+		case cli.StringFlag:
+			f.EnvVar = appendEnvVar(f.EnvVar, prefix+GetFlagEnvName(f.Name))
+			flags[i] = f
+		case cli.IntFlag:
+			f.EnvVar = appendEnvVar(f.EnvVar, prefix+GetFlagEnvName(f.Name))
+			flags[i] = f
+		case cli.StringSliceFlag:
+			f.EnvVar = appendEnvVar(f.EnvVar, prefix+GetFlagEnvName(f.Name))
+			flags[i] = f
+		}
+	}
+	return flags
+}
+
+// Get the environment variable name part of the flag or command name, e.g. older-than, o -> OLDER_THAN
+func GetFlagEnvName(name string) string {
+	name = strings.TrimSpace(strings.Split(name, ",")[0])
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// Append the environment variable to the comma separated ones if not declared
+func appendEnvVar(envVars, envVar string) string {
+	if envVars == "" {
+		return envVar
+	}
+	for _, name := range strings.Split(envVars, ",") {
+		if strings.TrimSpace(name) == envVar {
+			return envVars
+		}
+	}
+	return envVars + "," + envVar
+}
@@ -0,0 +1,161 @@
+// Author: lipixun
+// Created Time : 六 10/17 22:52:06 2026
+//
+// File Name: exitcode.go
+// Description:
+//	The exit codes and the error output of the commands
+//
+//	The exit code tells the failure type, so the scripts (e.g. CI pipelines) could branch on it:
+//		0 	Success
+//		1 	Generic error
+//		2 	Usage error, e.g. unknown flags or commands, missing or invalid arguments
+//		3 	Build failure
+//		4 	Target not found
+//		5 	Instance not running (or not found)
+//		6 	Test failure
+//		130 	Interrupted (by SIGINT or SIGTERM)
+//	The external commands (op-<command>) and the commands run by op exec exit with their own codes.
+//
+//	With the global --error-format json, a single line of the error object is written to stderr on failure, e.g.
+//		{"code":3,"kind":"build_failure","message":"Failed to build target [//app]","errors":["..."]}
+//	The logs are still written to stderr in the log format, the error object is the last line.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	ExitCodeError          = 1
+	ExitCodeUsage          = 2
+	ExitCodeBuildFailure   = 3
+	ExitCodeTargetNotFound = 4
+	ExitCodeNotRunning     = 5
+	ExitCodeTestFailure    = 6
+	ExitCodeInterrupted    = 130 // The exit code of the shells for SIGINT
+
+	ErrorFormatText = "text"
+	ErrorFormatJson = "json"
+)
+
+// The kinds of the exit codes in the error object
+var exitCodeKinds = map[int]string{
+	ExitCodeError:          "error",
+	ExitCodeUsage:          "usage",
+	ExitCodeBuildFailure:   "build_failure",
+	ExitCodeTargetNotFound: "target_not_found",
+	ExitCodeNotRunning:     "not_running",
+	ExitCodeTestFailure:    "test_failure",
+	ExitCodeInterrupted:    "interrupted",
+}
+
+var (
+	errorFormat   = ErrorFormatText
+	errorMessages []string      // The error messages of the command, only recorded in json format
+	errorOutput   *bytes.Buffer // The captured messages of the exit errors in json format
+	errorLock     sync.Mutex
+)
+
+// The error object written in json format
+type ErrorResult struct {
+	Code    int      `json:"code"`
+	Kind    string   `json:"kind"`
+	Message string   `json:"message"`
+	Errors  []string `json:"errors,omitempty"` // All error messages in order, the message is the last one
+}
+
+// Set the error format, should be called before running the command (e.g. in app.Before)
+func SetErrorFormat(format string) error {
+	switch format {
+	case "", ErrorFormatText:
+		format = ErrorFormatText
+	case ErrorFormatJson:
+		// The messages of the exit errors are written to cli.ErrWriter, capture them into the error object
+		errorOutput = new(bytes.Buffer)
+		cli.ErrWriter = errorOutput
+	default:
+		return cli.NewExitError(fmt.Sprintf("Unknown error format [%s], should be one of %s, %s", format, ErrorFormatText, ErrorFormatJson), ExitCodeUsage)
+	}
+	errorFormat = format
+	// Done
+	return nil
+}
+
+// Record an error message, e.g. the error returned by app.Run which is not an exit error
+func RecordError(message string) {
+	if message = strings.TrimSpace(message); message == "" {
+		return
+	}
+	errorLock.Lock()
+	defer errorLock.Unlock()
+	errorMessages = append(errorMessages, message)
+}
+
+// Record the error logs of the logger in json format
+func recordErrorLogs(logger log.Logger) {
+	if errorFormat != ErrorFormatJson {
+		return
+	}
+	logger.AddSink(log.NewHookSink(log.LevelError, func(entry *log.Entry) {
+		RecordError(entry.Message)
+	}))
+}
+
+// Exit with the code, the error object is written in json format. Should be used as cli.OsExiter
+func Exit(code int) {
+	FlushLogs()
+	if code != 0 && canceled() {
+		code = ExitCodeInterrupted
+	}
+	if code != 0 && errorFormat == ErrorFormatJson {
+		writeErrorResult(code)
+	}
+	StopPager()
+	FlushAnnotations()
+	RecordTelemetry(code)
+	CloseWorkspaces()
+	os.Exit(code)
+}
+
+// Whether any created workspace is canceled
+func canceled() bool {
+	workspacesLock.Lock()
+	defer workspacesLock.Unlock()
+	for _, ws := range workspaces {
+		if ws.Canceled() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Write the error object to stderr
+func writeErrorResult(code int) {
+	if errorOutput != nil {
+		RecordError(errorOutput.String())
+		errorOutput.Reset()
+	}
+	errorLock.Lock()
+	defer errorLock.Unlock()
+	result := ErrorResult{Code: code, Kind: exitCodeKinds[code], Errors: errorMessages}
+	if result.Kind == "" {
+		result.Kind = exitCodeKinds[ExitCodeError]
+	}
+	if len(errorMessages) > 0 {
+		result.Message = errorMessages[len(errorMessages)-1]
+	} else if code == ExitCodeInterrupted {
+		result.Message = "Interrupted"
+	}
+	data, err := json.Marshal(&result)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
@@ -0,0 +1,230 @@
+// Author: lipixun
+// Created Time : 日 10/18 01:36:20 2026
+//
+// File Name: explain.go
+// Description:
+//	Explain the effective config of a build target or a runner application
+//
+//	The argument is a runner application if it's defined in the runner spec files (see op apps), a target uri
+//	otherwise (e.g. :server or github.com/org/repo:server, the current git repository if no repository).
+//
+//	Each field of the effective spec is printed with its source:
+//		application 	The runner spec file supplying the application (a higher layer replaces the whole
+//						application), the overridden files are listed
+//		target 			The repository spec file of the loaded repository (the remote repositories are resolved
+//						as op build does, with the lock file and the rewrite rules)
+//	The fields not set in the file are printed with the default source, they're resolved by the runner or the
+//	builders when used.
+package explain
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	KindApp    = "app"
+	KindTarget = "target"
+
+	SourceDefault = "default"
+)
+
+// The explained config in the structured output
+type Result struct {
+	Kind      string   `json:"kind"` // app or target
+	Name      string   `json:"name"`
+	Source    string   `json:"source"`              // The file supplying the config
+	Overrides []string `json:"overrides,omitempty"` // The lower files also defining the config, from high to low
+	Fields    []Field  `json:"fields"`
+}
+
+// A field of the explained config
+type Field struct {
+	Key    string      `json:"key"` // The dotted key of the field in the spec file
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"` // The file supplying the value, default if not set
+}
+
+func Explain(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintln(log.LevelError, "Require exactly one target or application")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	name := c.Args().First()
+	var result *Result
+	if r, err := runner.New(ws); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to load runner spec, error: %s\n", err)
+	} else if appSpec := r.Apps[name]; appSpec != nil {
+		result = explainApp(name, appSpec, r.AppSources[name])
+	}
+	if result == nil {
+		if result, err = explainTarget(ws, name, c.Bool("disable-finder"), c.Bool("ignore-lock")); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to explain [%s], error: %s\n", name, err)
+			if graph.IsTargetNotFoundError(err) {
+				return cli.NewExitError("", opcli.ExitCodeTargetNotFound)
+			}
+			return cli.NewExitError("", 1)
+		}
+	}
+	opcli.StartPager(c)
+	if renderer.Structured() {
+		if err := renderer.Render(result); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the result, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	printResult(result)
+	// Done
+	return nil
+}
+
+// Explain the runner application
+func explainApp(name string, appSpec *runner.RunnerAppSpec, value *workspace.LayeredValue) *Result {
+	result := &Result{Kind: KindApp, Name: name, Source: value.Source.String()}
+	for _, source := range value.Overridden {
+		result.Overrides = append(result.Overrides, source.String())
+	}
+	flatten("", reflect.ValueOf(appSpec), result.Source, &result.Fields)
+	return result
+}
+
+// Explain the build target
+func explainTarget(ws *workspace.Workspace, name string, disableFinder, ignoreLock bool) (*Result, error) {
+	targetUri := uri.ParseTargetUri(name)
+	if targetUri == nil {
+		return nil, errors.New("Neither an application nor a target uri")
+	}
+	var rootPath string
+	if targetUri.Repository == nil {
+		path, err := opcli.GetGitRootFromCurrentDirectory()
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to get current git root directory, error: %s", err))
+		}
+		rootPath = path
+		targetUri.Repository = &uri.RepositoryUri{Uri: path}
+	}
+	g, err := graph.New(ws, graph.GraphOptions{UseLocalDependency: true, DisableFinder: disableFinder})
+	if err != nil {
+		return nil, err
+	}
+	if rootPath != "" && !ignoreLock {
+		if g.Lock, err = graph.LoadLock(filepath.Join(rootPath, graph.LockFileName)); err != nil {
+			return nil, err
+		}
+	}
+	r, err := g.Load(targetUri.Repository.Uri, graph.LoadOptions{Branch: targetUri.Repository.Branch, Commit: targetUri.Repository.Commit, Targets: []string{targetUri.Name}})
+	if err != nil {
+		return nil, err
+	}
+	target := g.Targets[spec.GetTargetKey(targetUri.Name, r)]
+	if target == nil {
+		return nil, &graph.TargetNotFoundError{Target: targetUri.Name, Repository: r.Uri}
+	}
+	result := &Result{Kind: KindTarget, Name: target.Key(), Source: target.Repository.SpecFile}
+	flatten("", reflect.ValueOf(target.Spec), result.Source, &result.Fields)
+	// Done
+	return result, nil
+}
+
+// Flatten the value into the fields, the keys are the yaml keys joined by dots
+func flatten(key string, value reflect.Value, source string, fields *[]Field) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			flatten(key, value.Elem(), source, fields)
+		}
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if field.PkgPath != "" {
+				// Not exported
+				continue
+			}
+			tags := strings.Split(field.Tag.Get("yaml"), ",")
+			name := tags[0]
+			if name == "-" {
+				continue
+			} else if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if len(tags) > 1 && tags[1] == "inline" {
+				flatten(key, value.Field(i), source, fields)
+			} else {
+				flatten(joinKey(key, name), value.Field(i), source, fields)
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, mapKey := range keys {
+			flatten(joinKey(key, fmt.Sprint(mapKey.Interface())), value.MapIndex(mapKey), source, fields)
+		}
+	case reflect.Slice:
+		if kind := value.Type().Elem().Kind(); kind == reflect.Struct || kind == reflect.Ptr {
+			for i := 0; i < value.Len(); i++ {
+				flatten(fmt.Sprintf("%s[%d]", key, i), value.Index(i), source, fields)
+			}
+			return
+		}
+		fallthrough
+	default:
+		fieldSource := source
+		if value.IsZero() {
+			fieldSource = SourceDefault
+		}
+		*fields = append(*fields, Field{Key: key, Value: value.Interface(), Source: fieldSource})
+	}
+}
+
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
+
+// Print the result in text
+func printResult(result *Result) {
+	fmt.Printf("%s %s\tfrom %s\n", result.Kind, result.Name, result.Source)
+	for _, source := range result.Overrides {
+		fmt.Printf("\toverrides %s\n", source)
+	}
+	width := 0
+	for _, field := range result.Fields {
+		if len(field.Key) > width {
+			width = len(field.Key)
+		}
+	}
+	for _, field := range result.Fields {
+		source := ""
+		if field.Source == SourceDefault {
+			source = "\t(default)"
+		}
+		fmt.Printf("  %-*s = %v%s\n", width, field.Key, field.Value, source)
+	}
+}
@@ -0,0 +1,39 @@
+// Author: lipixun
+// Created Time : 日 10/18 01:36:20 2026
+//
+// File Name: main.go
+// Description:
+//	The explain command prints the effective config of a build target or a runner application
+package explain
+
+import (
+	opcli "github.com/ops-openlight/openlight/cli"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Explain"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Category:     "Workspace",
+			Name:         "explain",
+			Usage:        "Print the effective config of a build target or a runner application, annotated with the file supplying each field",
+			ArgsUsage:    "<target|app>",
+			Action:       Explain,
+			BashComplete: opcli.BashComplete(nil, opcli.CompleteTargets),
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "disable-finder",
+					Usage: "Disable the repository local finder",
+				},
+				cli.BoolFlag{
+					Name:  "ignore-lock",
+					Usage: "Resolve the remote repositories without the lock file (op.lock)",
+				},
+			},
+		},
+	}
+}
@@ -8,7 +8,6 @@ package cli
 
 import (
 	"github.com/libgit2/git2go"
-	"os"
 	"path/filepath"
 )
 
@@ -22,8 +21,9 @@ func GetGitRootPath(p string) (string, error) {
 	return filepath.Dir(filepath.Dir(r.Path())), nil
 }
 
+// Get the git root of the work dir (see GetWorkDir)
 func GetGitRootFromCurrentDirectory() (string, error) {
-	rootRepoPath, err := os.Getwd()
+	rootRepoPath, err := GetWorkDir()
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,46 @@
+// Author: lipixun
+// Created Time : 日 10/18 06:38:50 2026
+//
+// File Name: dot.go
+// Description:
+//	Render the graph in the graphviz dot language, the applications are ellipses and the missing targets are dashed
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func renderDot(g *Result, writer io.Writer) error {
+	lines := []string{
+		"digraph op {",
+		"\trankdir=LR;",
+		"\tnode [shape=box, fontname=\"Helvetica\"];",
+	}
+	for _, node := range g.Nodes {
+		attrs := []string{fmt.Sprintf("label=%s", dotQuote(node.String()))}
+		if node.Kind == KindApp {
+			attrs = append(attrs, "shape=ellipse")
+		}
+		if node.Missing {
+			attrs = append(attrs, "style=dashed")
+		}
+		lines = append(lines, fmt.Sprintf("\t%s [%s];", dotQuote(node.Id), strings.Join(attrs, ", ")))
+	}
+	for _, edge := range g.Edges {
+		if edge.Name != "" {
+			lines = append(lines, fmt.Sprintf("\t%s -> %s [label=%s];", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Name)))
+		} else {
+			lines = append(lines, fmt.Sprintf("\t%s -> %s;", dotQuote(edge.From), dotQuote(edge.To)))
+		}
+	}
+	lines = append(lines, "}")
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
+
+// Quote the id or the label
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s) + "\""
+}
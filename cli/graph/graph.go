@@ -0,0 +1,356 @@
+// Author: lipixun
+// Created Time : 日 10/18 06:13:05 2026
+//
+// File Name: graph.go
+// Description:
+//	Render the combined dependency graph of the build targets and the runner applications
+//
+//	The nodes are all targets of the current repository (with the targets of the remote repositories they depend
+//	on) and the runner applications (see op apps). The edges are:
+//		target -> target 	The dependency of the target
+//		app -> target 		The target built by op up before starting the application
+//	An application target not loaded (e.g. in another local repository) is a missing node.
+//
+//	The filters (--path, --type, --label) select the nodes matching all of them, the dependencies of the selected
+//	nodes are added unless --no-deps.
+package graph
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	opgraph "github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatTree = "tree"
+	FormatDot  = "dot"
+	FormatSvg  = "svg"
+
+	KindApp    = "app"
+	KindTarget = "target"
+)
+
+// The renderers of the formats
+var renderers = map[string]func(g *Result, writer io.Writer) error{
+	FormatTree: renderTree,
+	FormatDot:  renderDot,
+	FormatSvg:  renderSvg,
+}
+
+// The graph in the structured output
+type Result struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// A node of the graph
+type Node struct {
+	Id         string `json:"id"`
+	Kind       string `json:"kind"`  // app or target
+	Label      string `json:"label"` // The target uri (without the repository for current repository) or app name
+	Name       string `json:"name"`
+	Type       string `json:"type"`                 // The build type of the target, app for the application
+	Path       string `json:"path,omitempty"`       // The relative path of the target in the repository
+	Repository string `json:"repository,omitempty"` // The repository uri of the target
+	Missing    bool   `json:"missing,omitempty"`    // The application target not loaded
+}
+
+// An edge of the graph, from the dependent to the dependency
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Name string `json:"name,omitempty"` // The dependency name of the target
+}
+
+// The filters of the nodes
+type filter struct {
+	Path  string
+	Type  string
+	Label string
+}
+
+func Graph(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Check parameters
+	render, ok := renderers[c.String("format")]
+	if !ok {
+		logger.LeveledPrintf(log.LevelError, "Unknown format [%s], should be one of %s, %s, %s\n", c.String("format"), FormatTree, FormatDot, FormatSvg)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if _, err := filepath.Match(c.String("label"), ""); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Malformed label pattern [%s], error: %s\n", c.String("label"), err)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	// Load the current repository with all targets
+	rootPath, err := opcli.GetGitRootFromCurrentDirectory()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get current git root directory, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	g, err := opgraph.New(ws, opgraph.GraphOptions{UseLocalDependency: true, DisableFinder: c.Bool("disable-finder")})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create sourcecode graph, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	root, err := g.Load(rootPath, opgraph.LoadOptions{})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load repository [%s], error: %s\n", rootPath, err)
+		return cli.NewExitError("", 1)
+	}
+	result := newTargetGraph(g, root)
+	// Add the applications
+	if r, err := runner.New(ws); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to load runner spec, error: %s\n", err)
+	} else {
+		for name, appSpec := range r.Apps {
+			if err := result.addApp(name, appSpec, r.AppSources[name].Source.Path, g, root, rootPath); err != nil {
+				logger.LeveledPrintf(log.LevelWarn, "Invalid target of application [%s], error: %s\n", name, err)
+			}
+		}
+	}
+	result.sort()
+	result = result.Filter(filter{Path: c.String("path"), Type: c.String("type"), Label: c.String("label")}, !c.Bool("no-deps"))
+	// Output
+	if renderer.Structured() {
+		opcli.StartPager(c)
+		if err := renderer.Render(result); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the graph, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	if c.String("file") != "" {
+		file, err := os.Create(c.String("file"))
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to create file [%s], error: %s\n", c.String("file"), err)
+			return cli.NewExitError("", 1)
+		}
+		defer file.Close()
+		if err := render(result, file); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to write file [%s], error: %s\n", c.String("file"), err)
+			return cli.NewExitError("", 1)
+		}
+		logger.LeveledPrintf(log.LevelSuccess, "Graph of %d nodes written to %s\n", len(result.Nodes), c.String("file"))
+		return nil
+	}
+	if c.String("format") == FormatTree {
+		opcli.StartPager(c)
+	}
+	if err := render(result, os.Stdout); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to render the graph, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+// Create the graph of the loaded targets
+func newTargetGraph(g *opgraph.Graph, root *spec.Repository) *Result {
+	result := new(Result)
+	for _, target := range g.Targets {
+		node := &Node{
+			Id:         target.Key(),
+			Kind:       KindTarget,
+			Label:      target.Key(),
+			Name:       target.Name,
+			Type:       target.Spec.Build.Type,
+			Path:       target.Spec.Path,
+			Repository: target.Repository.Uri,
+		}
+		if target.Repository == root {
+			node.Label = ":" + target.Name
+		}
+		result.Nodes = append(result.Nodes, node)
+		for _, name := range target.Spec.Deps.Names() {
+			dep := target.Spec.Deps[name]
+			if _, ok := g.Targets[dep.Key()]; !ok {
+				// Not available offline
+				continue
+			}
+			result.Edges = append(result.Edges, &Edge{From: target.Key(), To: dep.Key(), Name: name})
+		}
+	}
+	return result
+}
+
+// Add the application and the edge to its target
+func (this *Result) addApp(name string, appSpec *runner.RunnerAppSpec, specPath string, g *opgraph.Graph, root *spec.Repository, rootPath string) error {
+	node := &Node{Id: KindApp + ":" + name, Kind: KindApp, Label: name, Name: name, Type: KindApp}
+	this.Nodes = append(this.Nodes, node)
+	if appSpec.Target == "" {
+		return nil
+	}
+	targetUri := uri.ParseTargetUri(appSpec.Target)
+	if targetUri == nil {
+		return errors.New(fmt.Sprintf("Malformed target uri [%s]", appSpec.Target))
+	}
+	// Find the loaded repository of the target, the repository of the spec file if not specified
+	repository := root
+	if targetUri.Repository != nil {
+		repository = nil
+		for _, r := range g.Repositories {
+			if r.Uri == targetUri.Repository.Uri || r.Local.Path == targetUri.Repository.Uri {
+				repository = r
+				break
+			}
+		}
+	} else if path, err := opcli.GetGitRootPath(filepath.Dir(specPath)); err == nil && path != rootPath {
+		repository = nil
+	}
+	var key string
+	if repository != nil {
+		key = spec.GetTargetKey(targetUri.Name, repository)
+	}
+	if _, ok := g.Targets[key]; !ok {
+		// Not loaded
+		key = KindApp + ":" + name + ":" + appSpec.Target
+		this.Nodes = append(this.Nodes, &Node{Id: key, Kind: KindTarget, Label: appSpec.Target, Name: targetUri.Name, Missing: true})
+	}
+	this.Edges = append(this.Edges, &Edge{From: node.Id, To: key})
+	// Done
+	return nil
+}
+
+// Get the graph of the nodes matching the filter (and their dependencies if deps)
+func (this *Result) Filter(f filter, deps bool) *Result {
+	if f.Path == "" && f.Type == "" && f.Label == "" {
+		return this
+	}
+	edges := this.edgesFrom()
+	selected := make(map[string]bool)
+	var queue []string
+	for _, node := range this.Nodes {
+		if f.match(node) {
+			selected[node.Id] = true
+			queue = append(queue, node.Id)
+		}
+	}
+	for deps && len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range edges[id] {
+			if !selected[edge.To] {
+				selected[edge.To] = true
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	result := new(Result)
+	for _, node := range this.Nodes {
+		if selected[node.Id] {
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+	for _, edge := range this.Edges {
+		if selected[edge.From] && selected[edge.To] {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+	// Done
+	return result
+}
+
+// Whether the node matches the filter
+func (this filter) match(node *Node) bool {
+	if this.Path != "" {
+		if node.Kind != KindTarget || node.Missing {
+			return false
+		}
+		prefix, path := filepath.Clean(this.Path), filepath.Clean(node.Path)
+		if prefix != "." && path != prefix && !strings.HasPrefix(path, prefix+string(filepath.Separator)) {
+			return false
+		}
+	}
+	if this.Type != "" && node.Type != this.Type {
+		return false
+	}
+	if this.Label != "" {
+		if matched, _ := filepath.Match(this.Label, node.Name); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the nodes by id
+func (this *Result) nodes() map[string]*Node {
+	nodes := make(map[string]*Node)
+	for _, node := range this.Nodes {
+		nodes[node.Id] = node
+	}
+	return nodes
+}
+
+// Get the edges by the from node id
+func (this *Result) edgesFrom() map[string][]*Edge {
+	edges := make(map[string][]*Edge)
+	for _, edge := range this.Edges {
+		edges[edge.From] = append(edges[edge.From], edge)
+	}
+	return edges
+}
+
+// Get the nodes without the edges to them, the applications first
+func (this *Result) roots() []*Node {
+	dependent := make(map[string]bool)
+	for _, edge := range this.Edges {
+		dependent[edge.To] = true
+	}
+	var roots []*Node
+	for _, node := range this.Nodes {
+		if !dependent[node.Id] {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// Sort the nodes (the applications first, then by label) and the edges (by the dependent, then the dependency label)
+func (this *Result) sort() {
+	sort.Slice(this.Nodes, func(i, j int) bool {
+		if (this.Nodes[i].Kind == KindApp) != (this.Nodes[j].Kind == KindApp) {
+			return this.Nodes[i].Kind == KindApp
+		}
+		return this.Nodes[i].Label < this.Nodes[j].Label
+	})
+	nodes := this.nodes()
+	sort.Slice(this.Edges, func(i, j int) bool {
+		if this.Edges[i].From != this.Edges[j].From {
+			return this.Edges[i].From < this.Edges[j].From
+		}
+		return nodes[this.Edges[i].To].Label < nodes[this.Edges[j].To].Label
+	})
+}
+
+// Get the text of the node with its type
+func (this *Node) String() string {
+	switch {
+	case this.Missing:
+		return fmt.Sprintf("%s (not loaded)", this.Label)
+	case this.Kind == KindApp:
+		return fmt.Sprintf("%s (app)", this.Label)
+	case this.Type != "":
+		return fmt.Sprintf("%s (%s)", this.Label, this.Type)
+	default:
+		return this.Label
+	}
+}
@@ -0,0 +1,60 @@
+// Author: lipixun
+// Created Time : 日 10/18 06:12:40 2026
+//
+// File Name: main.go
+// Description:
+//	The graph command renders the dependency graph of the build targets and the runner applications, e.g.
+//		op graph --type golang
+//		op graph --format svg --file graph.svg
+//		op graph --format dot | dot -Tpng -o graph.png
+package graph
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Graph"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Category: "Builder",
+			Name:     "graph",
+			Usage:    "Render the dependency graph of the targets of current repository and the runner applications",
+			Action:   Graph,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: FormatTree,
+					Usage: "The format of the graph: tree, dot or svg",
+				},
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "Write the graph to the file instead of stdout",
+				},
+				cli.StringFlag{
+					Name:  "path",
+					Usage: "Only the targets under the path (relative to the repository root)",
+				},
+				cli.StringFlag{
+					Name:  "type",
+					Usage: "Only the targets of the build type (e.g. golang), app for the runner applications",
+				},
+				cli.StringFlag{
+					Name:  "label",
+					Usage: "Only the nodes with the label (the target or application name) matching the glob pattern, e.g. *-server",
+				},
+				cli.BoolFlag{
+					Name:  "no-deps",
+					Usage: "Do not add the dependencies of the matched nodes",
+				},
+				cli.BoolFlag{
+					Name:  "disable-finder",
+					Usage: "Disable the repository local finder",
+				},
+			},
+		},
+	}
+}
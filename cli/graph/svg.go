@@ -0,0 +1,176 @@
+// Author: lipixun
+// Created Time : 日 10/18 06:44:17 2026
+//
+// File Name: svg.go
+// Description:
+//	Render the graph as a standalone svg image, no graphviz required
+//
+//	The nodes are laid out in layers from top to bottom: the nodes without dependents are in the first layer, a node
+//	is one layer below its lowest dependent. The nodes of a layer are ordered by the average position of their
+//	dependents to reduce the crossing edges.
+package graph
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	svgCharWidth   = 7 // The width of a character of the monospace font
+	svgNodeHeight  = 24
+	svgNodePadding = 8
+	svgNodeGap     = 20
+	svgLayerGap    = 56
+	svgMargin      = 20
+)
+
+// The position of a node
+type svgBox struct {
+	X, Y, Width float64
+}
+
+func renderSvg(g *Result, writer io.Writer) error {
+	layers := getLayers(g)
+	// Get the positions of the nodes, the layers are centered
+	boxes := make(map[string]*svgBox)
+	var width float64
+	for _, layer := range layers {
+		if w := layerWidth(layer); w > width {
+			width = w
+		}
+	}
+	for i, layer := range layers {
+		x := svgMargin + (width-layerWidth(layer))/2
+		for _, node := range layer {
+			box := &svgBox{X: x, Y: float64(svgMargin + i*(svgNodeHeight+svgLayerGap)), Width: nodeWidth(node)}
+			boxes[node.Id] = box
+			x += box.Width + svgNodeGap
+		}
+	}
+	height := float64(2*svgMargin + len(layers)*(svgNodeHeight+svgLayerGap) - svgLayerGap)
+	if len(layers) == 0 {
+		height = 2 * svgMargin
+	}
+	// Write
+	lines := []string{
+		fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="monospace" font-size="12">`, width+2*svgMargin, height, width+2*svgMargin, height),
+		`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M 0 0 L 10 5 L 0 10 z" fill="#555"/></marker></defs>`,
+		`<rect width="100%" height="100%" fill="white"/>`,
+	}
+	for _, edge := range g.Edges {
+		from, to := boxes[edge.From], boxes[edge.To]
+		line := fmt.Sprintf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#555" marker-end="url(#arrow)">`, from.X+from.Width/2, from.Y+svgNodeHeight, to.X+to.Width/2, to.Y)
+		if edge.Name != "" {
+			line += fmt.Sprintf("<title>%s</title>", html.EscapeString(edge.Name))
+		}
+		lines = append(lines, line+"</line>")
+	}
+	for _, layer := range layers {
+		for _, node := range layer {
+			box := boxes[node.Id]
+			rect := fmt.Sprintf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="#eef3fb" stroke="#3b6ea8"`, box.X, box.Y, box.Width, svgNodeHeight)
+			switch {
+			case node.Missing:
+				rect = fmt.Sprintf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="white" stroke="#999" stroke-dasharray="4 2"`, box.X, box.Y, box.Width, svgNodeHeight)
+			case node.Kind == KindApp:
+				rect += fmt.Sprintf(` rx="%d" fill-opacity="0.5"`, svgNodeHeight/2)
+			}
+			lines = append(lines,
+				fmt.Sprintf("<g><title>%s</title>", html.EscapeString(node.Id)),
+				rect+"/>",
+				fmt.Sprintf(`<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central">%s</text>`, box.X+box.Width/2, box.Y+svgNodeHeight/2, html.EscapeString(node.String())),
+				"</g>",
+			)
+		}
+	}
+	lines = append(lines, "</svg>")
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
+
+// Get the layers of the nodes
+func getLayers(g *Result) [][]*Node {
+	dependents := make(map[string][]string)
+	for _, edge := range g.Edges {
+		dependents[edge.To] = append(dependents[edge.To], edge.From)
+	}
+	levels := make(map[string]int)
+	var getLevel func(id string, visiting map[string]bool) int
+	getLevel = func(id string, visiting map[string]bool) int {
+		if level, ok := levels[id]; ok {
+			return level
+		}
+		visiting[id] = true
+		level := 0
+		for _, from := range dependents[id] {
+			if visiting[from] {
+				continue
+			}
+			if l := getLevel(from, visiting) + 1; l > level {
+				level = l
+			}
+		}
+		delete(visiting, id)
+		levels[id] = level
+		return level
+	}
+	var layers [][]*Node
+	for _, node := range g.Nodes {
+		level := getLevel(node.Id, make(map[string]bool))
+		for len(layers) <= level {
+			layers = append(layers, nil)
+		}
+		layers[level] = append(layers[level], node)
+	}
+	// Order the nodes by the average index of their dependents in the upper layer
+	index := make(map[string]int)
+	for i, layer := range layers {
+		if i > 0 {
+			weights := make(map[string]float64)
+			for j, node := range layer {
+				var sum float64
+				var count int
+				for _, from := range dependents[node.Id] {
+					if k, ok := index[from]; ok {
+						sum += float64(k)
+						count++
+					}
+				}
+				if count > 0 {
+					weights[node.Id] = sum / float64(count)
+				} else {
+					weights[node.Id] = float64(j)
+				}
+			}
+			sort.SliceStable(layer, func(a, b int) bool {
+				return weights[layer[a].Id] < weights[layer[b].Id]
+			})
+		}
+		for j, node := range layer {
+			index[node.Id] = j
+		}
+	}
+	// Done
+	return layers
+}
+
+// Get the width of the node box
+func nodeWidth(node *Node) float64 {
+	return float64(utf8.RuneCountInString(node.String())*svgCharWidth + 2*svgNodePadding)
+}
+
+// Get the width of the layer
+func layerWidth(layer []*Node) float64 {
+	var width float64
+	for _, node := range layer {
+		width += nodeWidth(node)
+	}
+	if len(layer) > 1 {
+		width += float64((len(layer) - 1) * svgNodeGap)
+	}
+	return width
+}
@@ -0,0 +1,54 @@
+// Author: lipixun
+// Created Time : 日 10/18 06:31:22 2026
+//
+// File Name: tree.go
+// Description:
+//	Render the graph as an ascii tree from the nodes without dependents, e.g.
+//		web (app)
+//		└── :server (golang)
+//		    ├── :proto (shell)
+//		    └── github.com/org/lib:lib (golang)
+//	A node already printed with its dependencies is marked with (*) and not expanded again. On a terminal the tree is
+//	shown in the pager, use its search (e.g. /:server in less) to navigate.
+package graph
+
+import (
+	"fmt"
+	"io"
+)
+
+func renderTree(g *Result, writer io.Writer) error {
+	nodes, edges := g.nodes(), g.edgesFrom()
+	printed := make(map[string]bool)
+	var walk func(node *Node, prefix, childPrefix string) error
+	walk = func(node *Node, prefix, childPrefix string) error {
+		deps := edges[node.Id]
+		if printed[node.Id] && len(deps) > 0 {
+			_, err := fmt.Fprintf(writer, "%s%s (*)\n", prefix, node)
+			return err
+		}
+		printed[node.Id] = true
+		if _, err := fmt.Fprintf(writer, "%s%s\n", prefix, node); err != nil {
+			return err
+		}
+		for i, edge := range deps {
+			if i == len(deps)-1 {
+				if err := walk(nodes[edge.To], childPrefix+"└── ", childPrefix+"    "); err != nil {
+					return err
+				}
+			} else {
+				if err := walk(nodes[edge.To], childPrefix+"├── ", childPrefix+"│   "); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, node := range g.roots() {
+		if err := walk(node, "", ""); err != nil {
+			return err
+		}
+	}
+	// Done
+	return nil
+}
@@ -8,10 +8,39 @@ package cli
 
 import (
 	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"gopkg.in/urfave/cli.v1"
+	"sync"
 )
 
+var (
+	workspaces     []*workspace.Workspace // The created workspaces, closed by CloseWorkspaces
+	workspacesLock sync.Mutex
+)
+
+// Write the held uncompleted lines of the workspace loggers (see log.Options.LineBuffered), should be called before
+// exit so that the last line is not lost
+func FlushLogs() {
+	workspacesLock.Lock()
+	defer workspacesLock.Unlock()
+	for _, ws := range workspaces {
+		ws.Logger.Flush()
+	}
+}
+
+// Close the created workspaces, should be called before exit
+func CloseWorkspaces() {
+	workspacesLock.Lock()
+	defer workspacesLock.Unlock()
+	for _, ws := range workspaces {
+		if err := ws.Close(); err != nil {
+			ws.Logger.LeveledPrintf(log.LevelWarn, "Failed to close workspace, error: %s\n", err)
+		}
+	}
+	workspaces = nil
+}
+
 // Get the workspace
 func GetWorkspace(c *cli.Context) (*workspace.Workspace, error) {
 	verbose := c.GlobalBool("verbose")
@@ -19,24 +48,46 @@ func GetWorkspace(c *cli.Context) (*workspace.Workspace, error) {
 	workDirUserPath := c.GlobalString("workdir-user-path")
 	workDirGlobalPath := c.GlobalString("workdir-global-path")
 	dockerUri := c.GlobalString("docker-uri")
+	logFormat := c.GlobalString("log-format")
+	if logFormat != log.FormatText && logFormat != log.FormatJson {
+		return nil, cli.NewExitError(fmt.Sprintf("Unknown log format [%s]", logFormat), ExitCodeUsage)
+	}
 	// Create workspace options
 	options := workspace.NewWorkspaceOptions()
+	options.Name = c.GlobalString("workspace")
 	options.Verbose = verbose
-	options.EnableColor = true
+	options.EnableColor = options.EnableColor && !c.GlobalBool("no-color")
+	options.LogFormat = logFormat
+	options.LogTimestamp = c.GlobalBool("log-timestamp")
+	options.LogFile = !c.GlobalBool("no-log-file")
+	options.LogSystem = c.GlobalString("log-system")
+	options.LogCaller = c.GlobalBool("log-caller")
+	options.LogRateLimit = c.GlobalInt("log-rate-limit")
+	options.Offline = c.GlobalBool("offline")
+	options.Refresh = c.GlobalBool("refresh")
+	options.LogLevel = c.GlobalString("log-level")
+	if options.LogLevel != "" {
+		if _, err := log.ParseLevel(options.LogLevel); err != nil {
+			return nil, cli.NewExitError(err.Error(), ExitCodeUsage)
+		}
+	}
 	options.Dir.GlobalPath = workDirGlobalPath
 	options.Dir.UserPath = workDirUserPath
-	if workDirProjectPath == "" {
-		options.Dir.CurrentPathAsProjectPath = true
-	} else {
-		options.Dir.CurrentPathAsProjectPath = false
-		options.Dir.ProjectPath = workDirProjectPath
-	}
+	// Detect the project path from current path if not specified
+	options.Dir.CurrentPathAsProjectPath = false
+	options.Dir.ProjectPath = workDirProjectPath
+	options.Dir.CurrentPath = workDir
 	options.ThirdService.Docker.Uri = dockerUri
 	// Create workspace
 	ws, err := workspace.New(options, nil)
 	if err != nil {
 		return nil, cli.NewExitError(fmt.Sprintf("Failed to initialize workspace, error: %s", err), 1)
 	} else {
+		workspacesLock.Lock()
+		workspaces = append(workspaces, ws)
+		workspacesLock.Unlock()
+		recordErrorLogs(ws.Logger)
+		showTelemetryNotice(ws)
 		// Done
 		return ws, nil
 	}
@@ -8,8 +8,23 @@ package main
 
 import (
 	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/cli/artifact"
 	"github.com/ops-openlight/openlight/cli/build"
+	"github.com/ops-openlight/openlight/cli/completion"
+	"github.com/ops-openlight/openlight/cli/config"
+	"github.com/ops-openlight/openlight/cli/docs"
+	"github.com/ops-openlight/openlight/cli/explain"
+	"github.com/ops-openlight/openlight/cli/graph"
 	"github.com/ops-openlight/openlight/cli/runner"
+	"github.com/ops-openlight/openlight/cli/telemetry"
+	"github.com/ops-openlight/openlight/cli/test"
+	"github.com/ops-openlight/openlight/cli/ui"
+	"github.com/ops-openlight/openlight/cli/update"
+	"github.com/ops-openlight/openlight/cli/version"
+	opworkspace "github.com/ops-openlight/openlight/cli/workspace"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"gopkg.in/urfave/cli.v1"
 	"os"
@@ -31,12 +46,86 @@ func main() {
 	app.Name = "op"
 	app.Usage = "Openlight CLI"
 	app.Version = Version
+	app.EnableBashCompletion = true
 	// Global flags
 	app.Flags = []cli.Flag{
 		cli.BoolFlag{
 			Name:  "verbose",
 			Usage: "Show verbose log (debug log)",
 		},
+		cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable the color of the log (also disabled when not a terminal or NO_COLOR is set)",
+		},
+		cli.StringFlag{
+			Name:  "log-level",
+			Usage: "The log level: debug, info, warn, success, fail, error. Overwrites OP_LOG_LEVEL and --verbose",
+		},
+		cli.StringFlag{
+			Name:  "log-format",
+			Value: log.FormatText,
+			Usage: "The log format, either text or json (one json object per line)",
+		},
+		cli.BoolFlag{
+			Name:  "log-timestamp",
+			Usage: "Prefix the time and level of each log line",
+		},
+		cli.BoolFlag{
+			Name:  "log-caller",
+			Usage: "Show the caller (file:line) of the debug log",
+		},
+		cli.IntFlag{
+			Name:  "log-rate-limit",
+			Usage: "The max number of identical log messages per second, unlimited by default",
+		},
+		cli.BoolFlag{
+			Name:  "no-log-file",
+			Usage: "Do not mirror the log to the user workdir logs/op.log",
+		},
+		cli.StringFlag{
+			Name:  "log-system",
+			Usage: "Send the log to the system log, either syslog or journal (linux only)",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Value: opcli.OutputText,
+			Usage: "The output format of the command results: text, json or yaml (the logs are always written to stderr)",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the actions the mutating commands (e.g. build, start, stop, clean, upload) would take without running them",
+		},
+		cli.BoolFlag{
+			Name:  "no-pager",
+			Usage: "Do not pipe the long outputs (e.g. op logs, op query) through the pager (OP_PAGER, PAGER or less)",
+		},
+		cli.StringFlag{
+			Name:  "ci",
+			Usage: "Annotate the build, test and spec failures for the ci: github (workflow commands) or gitlab (code quality report)",
+		},
+		cli.StringFlag{
+			Name:   "workspace",
+			EnvVar: workspace.WorkspaceEnvName,
+			Usage:  "The workspace name, each workspace has its own runner state, caches and config",
+		},
+		cli.BoolFlag{
+			Name:   "offline",
+			EnvVar: workspace.OfflineEnvName,
+			Usage:  "Never access the network, serve the remote repositories and files from the caches and op.lock only",
+		},
+		cli.BoolFlag{
+			Name:  "refresh",
+			Usage: "Fetch the remote repositories even if they were fetched recently (see config git.ttl)",
+		},
+		cli.StringFlag{
+			Name:  "error-format",
+			Value: opcli.ErrorFormatText,
+			Usage: "The error output format on failure: text, json (a single line of the error object on stderr, see cli/exitcode.go)",
+		},
+		cli.StringFlag{
+			Name:  "workdir, C",
+			Usage: "Run as if op was started in the directory, the project and the git repository are detected from it",
+		},
 		cli.StringFlag{
 			Name:  "workdir-project-path",
 			Usage: "The openlight project workdir path",
@@ -64,6 +153,80 @@ func main() {
 	for _, cmd := range runner.GetCommand() {
 		app.Commands = append(app.Commands, cmd)
 	}
-	// Run it
-	app.Run(os.Args)
+	for _, cmd := range test.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range config.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range explain.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range graph.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range artifact.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range opworkspace.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range telemetry.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range ui.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range update.GetCommand(buildTag) {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range version.GetCommand(version.BuildInfo{Branch: buildBranch, Commit: buildCommit, Time: buildTime, Tag: buildTag}) {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range docs.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	for _, cmd := range completion.GetCommand() {
+		app.Commands = append(app.Commands, cmd)
+	}
+	// The version of op recorded in the artifact provenances, the commit for the development builds
+	builder.BuilderVersion = buildTag
+	if builder.BuilderVersion == "" {
+		builder.BuilderVersion = buildCommit
+	}
+	// Set the flags by the environment variables OP_[<COMMAND>_]<FLAG>
+	opcli.SetFlagEnvVars(app)
+	// Fail the mutating commands can't plan with --dry-run
+	opcli.SetNoDryRunCommands(app)
+	// Record the command runs if the telemetry is enabled
+	opcli.SetTelemetryHooks(app, buildTag)
+	// Dispatch the unknown subcommands to the external commands op-<command>
+	app.CommandNotFound = opworkspace.CommandNotFound
+	app.Before = func(c *cli.Context) error {
+		if err := opcli.SetErrorFormat(c.GlobalString("error-format")); err != nil {
+			return err
+		}
+		if err := opcli.SetWorkDir(c.GlobalString("workdir")); err != nil {
+			return err
+		}
+		return opcli.SetCIFormat(c.GlobalString("ci"))
+	}
+	// Wait for the pager and close the workspaces on exit, either normally or by an exit error
+	app.After = func(c *cli.Context) error {
+		opcli.FlushLogs()
+		opcli.StopPager()
+		opcli.FlushAnnotations()
+		opcli.RecordTelemetry(0)
+		opcli.CloseWorkspaces()
+		return nil
+	}
+	cli.OsExiter = opcli.Exit
+	// Cancel the workspaces on interrupt
+	opcli.HandleSignals()
+	// Run it. The exit errors are handled by the cli (exit with cli.OsExiter), the other errors are the usage errors,
+	// e.g. unknown flags, which are printed by the cli already
+	if err := app.Run(os.Args); err != nil {
+		opcli.RecordError(err.Error())
+		opcli.Exit(opcli.ExitCodeUsage)
+	}
 }
@@ -0,0 +1,113 @@
+// Author: lipixun
+// Created Time : 六 10/17 19:52:18 2026
+//
+// File Name: output.go
+// Description:
+//	The structured output of the commands
+//
+//	The global --output flag selects the output format of the command results: text (the default, for human), json
+//	or yaml (for scripts). The results are rendered to stdout while the logs are always written to stderr, e.g.
+//		op --output json status | jq '.[].id'
+//	The json tags of the results are the keys in both json and yaml.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+)
+
+const (
+	OutputText = "text"
+	OutputJson = "json"
+	OutputYaml = "yaml"
+)
+
+// The renderer of the command results
+type Renderer struct {
+	Format string    // The output format, one of Output*
+	Writer io.Writer // The writer, stdout (piped through the pager if started) if nil
+}
+
+// Get the renderer of the output format
+// Parameters:
+//
+//	format 	The output format of the command flag (e.g. op query -o json), the global --output flag if empty
+func GetRenderer(c *cli.Context, format string) (*Renderer, error) {
+	if format == "" {
+		format = c.GlobalString("output")
+	}
+	switch format {
+	case "":
+		format = OutputText
+	case OutputText, OutputJson, OutputYaml:
+	default:
+		return nil, cli.NewExitError(fmt.Sprintf("Unknown output format [%s], should be one of %s, %s, %s", format, OutputText, OutputJson, OutputYaml), ExitCodeUsage)
+	}
+	return &Renderer{Format: format}, nil
+}
+
+// Whether the output is structured (json or yaml), the command prints the text itself otherwise
+func (this *Renderer) Structured() bool {
+	return this.Format != OutputText
+}
+
+// Render the value in the structured format
+func (this *Renderer) Render(value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch this.Format {
+	case OutputJson:
+		data = append(data, '\n')
+	case OutputYaml:
+		// Convert through the json form to use the json tags as the keys
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var v interface{}
+		if err := decoder.Decode(&v); err != nil {
+			return err
+		}
+		if data, err = yaml.Marshal(jsonToYamlValue(v)); err != nil {
+			return err
+		}
+	default:
+		return errors.New(fmt.Sprintf("Cannot render the value in output format [%s]", this.Format))
+	}
+	writer := this.Writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// Convert the value decoded by json to a value marshaled by yaml in the natural form (the numbers are not quoted)
+func jsonToYamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonToYamlValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonToYamlValue(item)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
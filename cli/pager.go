@@ -0,0 +1,93 @@
+// Author: lipixun
+// Created Time : 日 10/18 03:38:52 2026
+//
+// File Name: pager.go
+// Description:
+//	The pager of the long outputs
+//
+//	Like git, the long outputs (e.g. op logs, op query, op explain, op spec-dump) are piped through the pager when
+//	stdout is a terminal, so the huge logs don't flood the terminal. The pager command is OP_PAGER, PAGER or less,
+//	run by sh. LESS defaults to FRX (quit if the output fits one screen, keep the colors and the screen) and LV
+//	defaults to -c as git does.
+//	The pager is disabled by the global --no-pager (or OP_NO_PAGER=1), an empty pager command or cat.
+package cli
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+const (
+	PagerEnvName = "OP_PAGER"
+	DefaultPager = "less"
+)
+
+var (
+	pager       *exec.Cmd
+	pagerStdout *os.File // The stdout replaced by the pipe to the pager
+	pagerLock   sync.Mutex
+)
+
+// Start the pager, the output written to stdout after it is piped through the pager until StopPager.
+// Does nothing if the pager is disabled or stdout is not a terminal, the output is written to stdout directly.
+func StartPager(c *cli.Context) {
+	pagerLock.Lock()
+	defer pagerLock.Unlock()
+	if pager != nil || c.GlobalBool("no-pager") || !log.IsTerminal(os.Stdout) {
+		return
+	}
+	command := getPagerCommand()
+	if command == "" || command == "cat" {
+		return
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = reader
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+	if err := cmd.Start(); err != nil {
+		// Write to stdout directly
+		reader.Close()
+		writer.Close()
+		return
+	}
+	reader.Close()
+	pager, pagerStdout = cmd, os.Stdout
+	os.Stdout = writer
+}
+
+// Stop the pager: close the pipe and wait for the user to quit the pager, should be called before exiting
+func StopPager() {
+	pagerLock.Lock()
+	defer pagerLock.Unlock()
+	if pager == nil {
+		return
+	}
+	os.Stdout.Close()
+	os.Stdout = pagerStdout
+	pager.Wait()
+	pager, pagerStdout = nil, nil
+}
+
+// Get the pager command, the empty command if disabled
+func getPagerCommand() string {
+	for _, name := range []string{PagerEnvName, "PAGER"} {
+		if command, ok := os.LookupEnv(name); ok {
+			return command
+		}
+	}
+	return DefaultPager
+}
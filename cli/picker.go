@@ -0,0 +1,185 @@
+// Author: lipixun
+// Created Time : 日 10/18 07:02:36 2026
+//
+// File Name: picker.go
+// Description:
+//	The interactive picker of the name omitted in the command (e.g. the target of op build, the application of
+//	op start), shown only when both stdin and stdout are terminals. The command fails as before otherwise (e.g. in
+//	scripts or CI).
+//
+//	The typed query filters the items by the fuzzy match: the characters of the query appear in the item in order
+//	(case insensitive), not necessarily adjacent. The items with the shorter span of the matched characters first.
+//	The keys:
+//		up/ctrl-p down/ctrl-n 	Select the item
+//		enter 					Pick the selected item
+//		esc ctrl-c 				Cancel
+package cli
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A matched item of the picker
+type pickerMatch struct {
+	Item      string
+	Positions []int // The rune positions of the matched characters
+}
+
+// Whether the picker could be shown
+func CanPick() bool {
+	return log.IsTerminal(os.Stdin) && log.IsTerminal(os.Stdout)
+}
+
+// Pick one of the items, returns an exit error if canceled
+// Parameters:
+//
+//	name 	The name of the items in the prompt, e.g. target
+func Pick(name string, items []string) (string, error) {
+	term, err := OpenTerminal()
+	if err != nil {
+		return "", cli.NewExitError(err.Error(), 1)
+	}
+	defer term.Close()
+	// Read ctrl-c as a key instead of the interrupt, so the terminal is always restored
+	stty("-isig")
+	var query string
+	var selected, offset int
+	for {
+		matches := fuzzyFilter(query, items)
+		if selected >= len(matches) {
+			selected = len(matches) - 1
+		}
+		if selected < 0 {
+			selected = 0
+		}
+		rows, cols := term.Size()
+		// Scroll to the selected item
+		height := rows - 1
+		if selected < offset {
+			offset = selected
+		} else if height > 0 && selected >= offset+height {
+			offset = selected - height + 1
+		}
+		lines := []string{Fit(fmt.Sprintf("Pick %s (%d/%d) > %s", name, len(matches), len(items), query), cols)}
+		for i := offset; i < len(matches) && i < offset+height; i++ {
+			line := "  " + highlight(matches[i], cols-2)
+			if i == selected {
+				line = EscReverse + "> " + highlight(matches[i], cols-2) + EscReset
+			}
+			lines = append(lines, line)
+		}
+		term.Draw(lines)
+		// Handle the keys
+		keys, err := term.ReadKey()
+		if err != nil {
+			return "", cli.NewExitError(fmt.Sprintf("Failed to read the keys, error: %s", err), 1)
+		}
+		for _, key := range keys {
+			switch key {
+			case KeyEscape, KeyCtrlC:
+				return "", cli.NewExitError("Aborted", 1)
+			case KeyEnter:
+				if len(matches) > 0 {
+					return matches[selected].Item, nil
+				}
+			case KeyUp:
+				selected--
+			case KeyDown:
+				selected++
+			case KeyPageUp:
+				selected -= height
+			case KeyPageDown:
+				selected += height
+			case KeyBackspace:
+				if query != "" {
+					query = query[:len(query)-1]
+					selected, offset = 0, 0
+				}
+			default:
+				if len(key) == 1 {
+					query += key
+					selected, offset = 0, 0
+				}
+			}
+		}
+	}
+}
+
+// Filter the items by the query, sorted by the span, then the position of the matched characters
+func fuzzyFilter(query string, items []string) []pickerMatch {
+	var matches []pickerMatch
+	for _, item := range items {
+		if positions, ok := fuzzyMatch(query, item); ok {
+			matches = append(matches, pickerMatch{Item: item, Positions: positions})
+		}
+	}
+	span := func(match pickerMatch) (int, int) {
+		if len(match.Positions) == 0 {
+			return 0, 0
+		}
+		return match.Positions[len(match.Positions)-1] - match.Positions[0], match.Positions[0]
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		spanI, startI := span(matches[i])
+		spanJ, startJ := span(matches[j])
+		if spanI != spanJ {
+			return spanI < spanJ
+		}
+		return startI < startJ
+	})
+	return matches
+}
+
+// Match the query to the text, returns the rune positions of the shortest match ending at the first possible end
+func fuzzyMatch(query, text string) ([]int, bool) {
+	pattern, runes := []rune(strings.ToLower(query)), []rune(strings.ToLower(text))
+	if len(pattern) == 0 {
+		return nil, true
+	}
+	// Find the end of the first match forward
+	end, n := -1, 0
+	for i, r := range runes {
+		if r == pattern[n] {
+			if n++; n == len(pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return nil, false
+	}
+	// Find the latest start backward
+	positions := make([]int, len(pattern))
+	n = len(pattern) - 1
+	for i := end; i >= 0 && n >= 0; i-- {
+		if runes[i] == pattern[n] {
+			positions[n] = i
+			n--
+		}
+	}
+	return positions, true
+}
+
+// Fit the item to the width with the matched characters in bold
+func highlight(match pickerMatch, width int) string {
+	matched := make(map[int]bool)
+	for _, position := range match.Positions {
+		matched[position] = true
+	}
+	var builder strings.Builder
+	for i, r := range []rune(Fit(match.Item, width)) {
+		if matched[i] && !unicode.IsSpace(r) {
+			builder.WriteString(EscBold + string(r) + EscNormal)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
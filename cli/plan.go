@@ -0,0 +1,117 @@
+// Author: lipixun
+// Created Time : 日 10/18 04:31:14 2026
+//
+// File Name: plan.go
+// Description:
+//	The planned actions of the dry run
+//
+//	With the global --dry-run, the mutating commands (op local-build, op up, op start, op stop, op restart,
+//	op clean-runner, op clean-build, op upload, op artifact push, op artifact pull, op artifact promote, op clean,
+//	op workspace clean, op config set, op config unset, op login, op logout, op plugin install, op plugin remove,
+//	op telemetry enable, op telemetry disable and op self-update) change nothing but add the actions they would take
+//	to the plan, then report the plan instead of running, e.g.
+//		Would stop 3f2a9c (application api)
+//		Would remove /home/user/.openlight/sourcecode/builder/20261018 (1.2G)
+//	The actions are rendered as a list in the structured output (--output json or yaml).
+//	The confirmations are skipped in the dry run since nothing is changed.
+//
+//	The mutating commands can't plan (NoDryRunCommands and the external commands) fail with --dry-run instead of
+//	running, a new mutating command should either plan or be added to NoDryRunCommands.
+package cli
+
+import (
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"strings"
+)
+
+var (
+	// The mutating commands (the command path) not supporting the dry run
+	NoDryRunCommands = []string{"lock", "update", "test", "ui", "docs generate", "plugin run"}
+)
+
+// A planned action
+type PlannedAction struct {
+	Action string `json:"action"`           // The verb, e.g. build, start, stop, remove, upload
+	Target string `json:"target"`           // The object of the action, e.g. the target key, instance id or path
+	Detail string `json:"detail,omitempty"` // The detail, e.g. the size to free
+}
+
+// The plan of the command
+type Plan struct {
+	DryRun  bool
+	Actions []PlannedAction
+}
+
+// Get the plan of the command, dry run if the global --dry-run is set
+func GetPlan(c *cli.Context) *Plan {
+	return &Plan{DryRun: c.GlobalBool("dry-run")}
+}
+
+// Check the dry run is not requested, for the commands not supporting it
+func CheckNoDryRun(c *cli.Context, command string) error {
+	if c.GlobalBool("dry-run") {
+		return cli.NewExitError(fmt.Sprintf("Command [%s] doesn't support --dry-run", command), ExitCodeUsage)
+	}
+	return nil
+}
+
+// Reject the dry run of NoDryRunCommands of the app, should be called after the commands added
+func SetNoDryRunCommands(app *cli.App) {
+	setNoDryRunCommands(app.Commands, "")
+}
+
+func setNoDryRunCommands(commands []cli.Command, prefix string) {
+	for i := range commands {
+		path := strings.TrimSpace(prefix + " " + commands[i].Name)
+		setNoDryRunCommands(commands[i].Subcommands, path)
+		for _, name := range NoDryRunCommands {
+			if name != path || commands[i].Action == nil {
+				continue
+			}
+			action := commands[i].Action
+			commands[i].Action = func(c *cli.Context) error {
+				if err := CheckNoDryRun(c, path); err != nil {
+					return err
+				}
+				return cli.HandleAction(action, c)
+			}
+		}
+	}
+}
+
+// Add the planned action
+func (this *Plan) Add(action, target, detail string) {
+	this.Actions = append(this.Actions, PlannedAction{Action: action, Target: target, Detail: detail})
+}
+
+// Report the planned actions to stdout
+func (this *Plan) Report(c *cli.Context) error {
+	renderer, err := GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	if renderer.Structured() {
+		actions := this.Actions
+		if actions == nil {
+			actions = []PlannedAction{}
+		}
+		if err := renderer.Render(actions); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Failed to render the planned actions, error: %s", err), 1)
+		}
+		return nil
+	}
+	if len(this.Actions) == 0 {
+		fmt.Fprintln(os.Stdout, "Nothing to do")
+	}
+	for _, action := range this.Actions {
+		if action.Detail != "" {
+			fmt.Fprintf(os.Stdout, "Would %s %s (%s)\n", action.Action, action.Target, action.Detail)
+		} else {
+			fmt.Fprintf(os.Stdout, "Would %s %s\n", action.Action, action.Target)
+		}
+	}
+	// Done
+	return nil
+}
@@ -11,9 +11,13 @@ import (
 	opcli "github.com/ops-openlight/openlight/cli"
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
 	"gopkg.in/urfave/cli.v1"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 )
 
 const (
@@ -22,13 +26,33 @@ const (
 	StatusFormat = "%-24s%-32s%-48s%-10s%s\n"
 )
 
+// The status of an application instance in the structured output
+type InstanceStatus struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Sequence int64     `json:"sequence"`
+	Time     time.Time `json:"time"`
+	Pid      int       `json:"pid"`
+	Status   string    `json:"status"` // Running, Exited or Error
+	Error    string    `json:"error,omitempty"`
+}
+
+// An application in the structured output
+type AppResult struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Source  string   `json:"source"` // The spec file defines the application
+}
+
 func GetCommand() []cli.Command {
 	return []cli.Command{
 		{
-			Category: "Runner",
-			Name:     "start",
-			Usage:    "Start the application",
-			Action:   start,
+			Category:     "Runner",
+			Name:         "start",
+			Usage:        "Start the application",
+			Action:       start,
+			BashComplete: opcli.BashComplete(map[string]opcli.Completer{"app": completeApps, "p": completeApps}, nil),
 			Flags: []cli.Flag{
 				cli.BoolFlag{
 					Name:  "background,b",
@@ -36,7 +60,7 @@ func GetCommand() []cli.Command {
 				},
 				cli.StringFlag{
 					Name:  "app,p",
-					Usage: "The application to start",
+					Usage: "The application to start, picked interactively on a terminal if not specified",
 				},
 				cli.StringFlag{
 					Name:  "command,c",
@@ -57,10 +81,11 @@ func GetCommand() []cli.Command {
 			},
 		},
 		{
-			Category: "Runner",
-			Name:     "logs",
-			Usage:    "Show the log of an application instance",
-			Action:   showlogs,
+			Category:     "Runner",
+			Name:         "logs",
+			Usage:        "Show the log of an application instance",
+			Action:       showlogs,
+			BashComplete: opcli.BashComplete(map[string]opcli.Completer{"id": completeInstanceIDs, "i": completeInstanceIDs}, completeInstanceNames),
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "id,i",
@@ -81,10 +106,11 @@ func GetCommand() []cli.Command {
 			},
 		},
 		{
-			Category: "Runner",
-			Name:     "stop",
-			Usage:    "Stop the application instance",
-			Action:   stop,
+			Category:     "Runner",
+			Name:         "stop",
+			Usage:        "Stop the application instance",
+			Action:       stop,
+			BashComplete: opcli.BashComplete(map[string]opcli.Completer{"id": completeInstanceIDs, "i": completeInstanceIDs}, completeInstanceNames),
 			Flags: []cli.Flag{
 				cli.StringSliceFlag{
 					Name:  "id,i",
@@ -97,10 +123,11 @@ func GetCommand() []cli.Command {
 			},
 		},
 		{
-			Category: "Runner",
-			Name:     "restart",
-			Usage:    "Restart the application instance ",
-			Action:   restart,
+			Category:     "Runner",
+			Name:         "restart",
+			Usage:        "Restart the application instance ",
+			Action:       restart,
+			BashComplete: opcli.BashComplete(map[string]opcli.Completer{"id": completeInstanceIDs, "i": completeInstanceIDs}, completeInstanceNames),
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "id,i",
@@ -124,11 +151,62 @@ func GetCommand() []cli.Command {
 				},
 			},
 		},
+		{
+			Category: "Runner",
+			Name:     "apps",
+			Usage:    "List the applications defined in the runner spec files (global, user and project)",
+			Action:   apps,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "explain",
+					Usage: "Show the spec file supplying each application and the files it overrides",
+				},
+			},
+		},
+		{
+			Category:     "Runner",
+			Name:         "up",
+			Usage:        "Build the target of the application (the target field in the runner spec) then start it, the running instances are stopped first",
+			ArgsUsage:    "<app> [args...]",
+			Action:       up,
+			BashComplete: opcli.BashComplete(nil, completeApps),
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "background,b",
+					Usage: "Start the application in background",
+				},
+				cli.BoolFlag{
+					Name:  "watch,w",
+					Usage: "Build and restart the application (in background, see op logs) on the changes of the repositories until interrupted",
+				},
+				cli.IntFlag{
+					Name:  "interval,i",
+					Value: 1,
+					Usage: "The interval in seconds to check the changes with --watch",
+				},
+				cli.StringFlag{
+					Name:  "output,o",
+					Value: "build",
+					Usage: "The build output path",
+				},
+				cli.BoolFlag{
+					Name:  "disable-finder",
+					Usage: "Disable the repository local finder",
+				},
+				cli.BoolFlag{
+					Name:  "ignore-lock",
+					Usage: "Resolve the remote repositories without the lock file (op.lock)",
+				},
+			},
+		},
 		{
 			Category: "Runner",
 			Name:     "clean-runner",
 			Usage:    "Clean the application runners, this is remove all runner data of stopped application instances",
 			Action:   clean,
+			Flags: []cli.Flag{
+				opcli.YesFlag,
+			},
 		},
 	}
 }
@@ -147,9 +225,9 @@ func start(c *cli.Context) error {
 	singleton := c.Bool("singleton")
 	ignoreConfigArgs := c.Bool("ignore-config-args")
 	args := c.Args()
-	if appName == "" {
+	if appName == "" && !opcli.CanPick() {
 		logger.LeveledPrintln(log.LevelError, "Require application name")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	}
 	// Create runner
 	r, err := runner.New(ws)
@@ -157,6 +235,21 @@ func start(c *cli.Context) error {
 		logger.LeveledPrintf(log.LevelError, "Failed to create runner, error: %s", err)
 		return cli.NewExitError("", 1)
 	}
+	if appName == "" {
+		// Pick one of the applications on the terminal
+		names := r.AppSources.Keys()
+		if len(names) == 0 {
+			logger.LeveledPrintln(log.LevelError, "Require application name, no application is defined in the runner spec files")
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+		if appName, err = opcli.Pick("application", names); err != nil {
+			return err
+		}
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("start", appName, strings.Join(args, " "))
+		return plan.Report(c)
+	}
 	// Start
 	instance, err := r.Start(appName, command, runner.AppStartOptions{
 		Args:           args,
@@ -170,7 +263,7 @@ func start(c *cli.Context) error {
 		return cli.NewExitError("", 1)
 	}
 	if !background {
-		instance.Wait()
+		instance.WaitContext(ws.Context())
 	}
 	// Done
 	return nil
@@ -190,14 +283,14 @@ func showlogs(c *cli.Context) error {
 	lines := c.Int("lines")
 	if id == "" && len(apps) == 0 {
 		logger.LeveledPrintln(log.LevelError, "Require either id or app")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	} else if id != "" && len(apps) > 0 {
 		logger.LeveledPrintln(log.LevelError, "Require either id or app but not both")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	}
 	if len(apps) > 1 {
 		logger.LeveledPrintln(log.LevelError, "Cannot specify more than 1 apps")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	}
 	// Create runner
 	r, err := runner.New(ws)
@@ -215,7 +308,7 @@ func showlogs(c *cli.Context) error {
 		}
 		if len(instances) == 0 {
 			logger.LeveledPrintln(log.LevelError, "No instance found for this application")
-			return cli.NewExitError("", 1)
+			return cli.NewExitError("", opcli.ExitCodeNotRunning)
 		} else if len(instances) > 1 {
 			// Filter out the running one
 			var runningInstances []*runner.AppInstance
@@ -229,7 +322,7 @@ func showlogs(c *cli.Context) error {
 				id = runningInstances[0].ID
 			} else {
 				logger.LeveledPrintln(log.LevelError, "More than 1 instance found, cannot show log by application name")
-				return cli.NewExitError("", 1)
+				return cli.NewExitError("", opcli.ExitCodeUsage)
 			}
 		} else {
 			id = instances[0].ID
@@ -243,6 +336,9 @@ func showlogs(c *cli.Context) error {
 	}
 	if follow {
 		args = append(args, "-f")
+	} else {
+		// Follow the log in the terminal directly
+		opcli.StartPager(c)
 	}
 	args = append(args, filename)
 	// Run the tail command
@@ -272,10 +368,30 @@ func stop(c *cli.Context) error {
 		return cli.NewExitError("", 1)
 	}
 	// Stop the application
+	plan := opcli.GetPlan(c)
+	action := "stop"
+	if clean {
+		action = "stop and clean"
+	}
+	code := 0
+	failed := func(err error) {
+		if runner.IsInstanceNotFoundError(err) {
+			if code == 0 {
+				code = opcli.ExitCodeNotRunning
+			}
+		} else {
+			code = opcli.ExitCodeError
+		}
+	}
 	for _, id := range ids {
+		if plan.DryRun {
+			plan.Add(action, id, "")
+			continue
+		}
 		logger.Printf("Stopping [%s] ...... ", id)
 		if err := r.Stop(id, clean); err != nil {
 			logger.LeveledHeadedPrint("", log.LevelError, "Error: %s\n", err)
+			failed(err)
 		} else {
 			logger.LeveledHeadedPrint("", log.LevelSuccess, "Done\n")
 		}
@@ -284,19 +400,39 @@ func stop(c *cli.Context) error {
 		instances, err := r.GetInstancesByName(name)
 		if err != nil {
 			logger.LeveledPrintf(log.LevelError, "Failed to get instances by name [%s], error: %s\n", name, err)
+			failed(err)
 			continue
 		}
+		stopped := 0
 		for _, instance := range instances {
 			s, _ := instance.GetStatus()
 			if s != runner.StatusExited {
+				stopped++
+				if plan.DryRun {
+					plan.Add(action, instance.ID, fmt.Sprintf("application %s", name))
+					continue
+				}
 				logger.Printf("Stopping [%s] ...... ", instance.ID)
 				if err := r.Stop(instance.ID, clean); err != nil {
 					logger.LeveledHeadedPrint("", log.LevelError, "Error: %s\n", err)
+					failed(err)
 				} else {
 					logger.LeveledHeadedPrint("", log.LevelSuccess, "Done\n")
 				}
 			}
 		}
+		if stopped == 0 {
+			logger.LeveledPrintf(log.LevelError, "No running instance of application [%s]\n", name)
+			failed(&runner.InstanceNotFoundError{ID: name})
+		}
+	}
+	if plan.DryRun {
+		if err := plan.Report(c); err != nil {
+			return err
+		}
+	}
+	if code != 0 {
+		return cli.NewExitError("", code)
 	}
 	// Done
 	return nil
@@ -310,6 +446,10 @@ func status(c *cli.Context) error {
 	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
 	// Get parameters
 	statusAll := c.Bool("all")
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
 	// Create runner
 	r, err := runner.New(ws)
 	if err != nil {
@@ -323,7 +463,7 @@ func status(c *cli.Context) error {
 		return cli.NewExitError("", 1)
 	}
 	// List it
-	fmt.Printf(StatusFormat, "ID", "Name", "Start Time", "Status", "Error")
+	results := []InstanceStatus{}
 	for _, instance := range instances {
 		var status string
 		s, err := instance.GetStatus()
@@ -338,7 +478,71 @@ func status(c *cli.Context) error {
 		if err != nil {
 			errmsg = err.Error()
 		}
-		fmt.Printf(StatusFormat, instance.ID, instance.Name, instance.Time, status, errmsg)
+		results = append(results, InstanceStatus{
+			ID:       instance.ID,
+			Name:     instance.Name,
+			Sequence: instance.Sequence,
+			Time:     instance.Time,
+			Pid:      instance.Pid,
+			Status:   status,
+			Error:    errmsg,
+		})
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(results); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render instances, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	fmt.Printf(StatusFormat, "ID", "Name", "Start Time", "Status", "Error")
+	for _, result := range results {
+		name := result.Name
+		if result.Sequence > 0 {
+			name = fmt.Sprintf("%s#%d", name, result.Sequence)
+		}
+		fmt.Printf(StatusFormat, result.ID, name, result.Time, result.Status, result.Error)
+	}
+	// Done
+	return nil
+}
+
+func apps(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Create runner
+	r, err := runner.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create runner, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	// List it
+	if c.Bool("explain") {
+		r.AppSources.Explain(os.Stdout)
+		return nil
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	if renderer.Structured() {
+		results := []AppResult{}
+		for _, name := range r.AppSources.Keys() {
+			appSpec := r.Apps[name]
+			results = append(results, AppResult{Name: name, Command: appSpec.Command, Args: appSpec.Args, Source: r.AppSources[name].Source.Path})
+		}
+		if err := renderer.Render(results); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render applications, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	for _, name := range r.AppSources.Keys() {
+		appSpec := r.Apps[name]
+		fmt.Printf("%s\t%s %s\n", name, appSpec.Command, strings.Join(appSpec.Args, " "))
 	}
 	// Done
 	return nil
@@ -356,14 +560,14 @@ func restart(c *cli.Context) error {
 	clean := c.Bool("clean")
 	if id == "" && len(apps) == 0 {
 		logger.LeveledPrintln(log.LevelError, "Require either id or app")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	} else if id != "" && len(apps) > 0 {
 		logger.LeveledPrintln(log.LevelError, "Require either id or app but not both")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	}
 	if len(apps) > 1 {
 		logger.LeveledPrintln(log.LevelError, "Cannot specify more than 1 apps")
-		return cli.NewExitError("", 1)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
 	}
 	// Create runner
 	r, err := runner.New(ws)
@@ -380,22 +584,30 @@ func restart(c *cli.Context) error {
 		}
 		if len(instances) == 0 {
 			logger.LeveledPrintln(log.LevelError, "No instance found for this application")
-			return cli.NewExitError("", 1)
+			return cli.NewExitError("", opcli.ExitCodeNotRunning)
 		} else if len(instances) > 1 {
 			logger.LeveledPrintln(log.LevelError, "More than 1 instance found, cannot restart by application name")
-			return cli.NewExitError("", 1)
+			return cli.NewExitError("", opcli.ExitCodeUsage)
 		} else {
 			id = instances[0].ID
 		}
 	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("restart", id, "")
+		return plan.Report(c)
+	}
 	// Restart the application
 	logger.Printf("Restarting [%s] ...... ", id)
 	if instance, err := r.Restart(id, clean); err != nil {
 		logger.LeveledHeadedPrintf("", log.LevelError, "Error: %s\n", err)
+		if runner.IsInstanceNotFoundError(err) {
+			return cli.NewExitError("", opcli.ExitCodeNotRunning)
+		}
+		return cli.NewExitError("", 1)
 	} else {
 		logger.LeveledHeadedPrintf("", log.LevelSuccess, "Done. New Instance ID [%s]\n", instance.ID)
 		if !instance.Options.Background {
-			instance.Wait()
+			instance.WaitContext(ws.Context())
 		}
 	}
 	// Done
@@ -414,6 +626,36 @@ func clean(c *cli.Context) error {
 		logger.LeveledPrintf(log.LevelError, "Failed to create runner, error: %s", err)
 		return cli.NewExitError("", 1)
 	}
+	// Confirm with the usage of the stopped instances
+	instances, err := r.List(false)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to list instances, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	plan := opcli.GetPlan(c)
+	var count int
+	var size int64
+	for _, instance := range instances {
+		if status, _ := instance.GetStatus(); status == runner.StatusExited {
+			count++
+			var instanceSize int64
+			if item, err := workspace.GetUsageItem(r.GetInstancePath(instance.ID)); err == nil {
+				instanceSize = item.Size
+			}
+			size += instanceSize
+			plan.Add("remove", instance.ID, fmt.Sprintf("application %s, %s", instance.Name, util.FormatSize(instanceSize)))
+		}
+	}
+	if count == 0 {
+		logger.LeveledPrintf(log.LevelInfo, "No stopped instance to clean\n")
+		return nil
+	}
+	if plan.DryRun {
+		return plan.Report(c)
+	}
+	if err := opcli.Confirm(c, fmt.Sprintf("Remove the data of %d stopped instances (%s)", count, util.FormatSize(size))); err != nil {
+		return err
+	}
 	// Clean
 	if err := r.CleanAll(); err != nil {
 		logger.LeveledPrintf(log.LevelError, "Failed to clean, error: %s\n", err)
@@ -422,3 +664,53 @@ func clean(c *cli.Context) error {
 	// Done
 	return nil
 }
+
+// Complete the application names defined in the runner spec files
+func completeApps(c *cli.Context) []string {
+	r := getCompletionRunner(c)
+	if r == nil {
+		return nil
+	}
+	return r.AppSources.Keys()
+}
+
+// Complete the ids of the application instances
+func completeInstanceIDs(c *cli.Context) []string {
+	var ids []string
+	if r := getCompletionRunner(c); r != nil {
+		instances, _ := r.List(false)
+		for _, instance := range instances {
+			ids = append(ids, instance.ID)
+		}
+	}
+	return ids
+}
+
+// Complete the application names of the instances
+func completeInstanceNames(c *cli.Context) []string {
+	var names []string
+	if r := getCompletionRunner(c); r != nil {
+		instances, _ := r.List(false)
+		found := make(map[string]bool)
+		for _, instance := range instances {
+			if !found[instance.Name] {
+				found[instance.Name] = true
+				names = append(names, instance.Name)
+			}
+		}
+	}
+	return names
+}
+
+// Create the runner to complete, nil if failed
+func getCompletionRunner(c *cli.Context) *runner.AppRunner {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return nil
+	}
+	r, err := runner.New(ws)
+	if err != nil {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,280 @@
+// Author: lipixun
+// Created Time : 日 10/18 00:04:37 2026
+//
+// File Name: up.go
+// Description:
+//	The up command runs the pipeline of the application: build the target then start the application
+//
+//	The target is defined by the target field of the application in the runner spec, a target without repository
+//	(e.g. :server) is in the git repository of the spec file. The application is started without building if no
+//	target is defined.
+//
+//	With --watch, the application is started in background and the pipeline runs again on the changes of the local
+//	repositories (polled every interval, the hidden directories and the output are ignored). A failed build keeps the
+//	running instance. The started instance is stopped when interrupted.
+package runner
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/cli/build"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The pipeline of the application
+type pipeline struct {
+	ws         *workspace.Workspace
+	logger     log.Logger
+	runner     *runner.AppRunner
+	app        string
+	args       []string
+	background bool
+	targetUris []*uri.TargetUri
+	options    build.BuildOptions
+	plan       *opcli.Plan
+}
+
+func up(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() == 0 {
+		logger.LeveledPrintln(log.LevelError, "Require application name")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	watch := c.Bool("watch")
+	// Create runner
+	r, err := runner.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create runner, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	name := c.Args().First()
+	appSpec := r.Apps[name]
+	if appSpec == nil {
+		logger.LeveledPrintf(log.LevelError, "Application [%s] is not defined in the runner spec files (see op apps)\n", name)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	// Get the build output path
+	output, err := filepath.Abs(c.String("output"))
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get output abs path, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	plan := opcli.GetPlan(c)
+	p := &pipeline{
+		ws:         ws,
+		logger:     logger,
+		runner:     r,
+		app:        name,
+		args:       c.Args().Tail(),
+		background: c.Bool("background") || watch,
+		plan:       plan,
+		options: build.BuildOptions{
+			AllowLocal:    true,
+			OnlyLocal:     true,
+			Output:        output,
+			DisableFinder: c.Bool("disable-finder"),
+			Plan:          plan,
+		},
+	}
+	if appSpec.Target != "" {
+		targetUri, err := getAppTargetUri(appSpec.Target, r.AppSources[name].Source.Path)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Invalid target of application [%s], error: %s\n", name, err)
+			return cli.NewExitError("", 1)
+		}
+		p.targetUris = append(p.targetUris, targetUri)
+		if !c.Bool("ignore-lock") {
+			p.options.LockFile = filepath.Join(targetUri.Repository.Uri, graph.LockFileName)
+		}
+	}
+	if plan.DryRun {
+		// A single run of the pipeline
+		if _, err := p.run(); err != nil {
+			return err
+		}
+		return plan.Report(c)
+	}
+	if !watch {
+		instance, err := p.run()
+		if err != nil {
+			return err
+		}
+		if !p.background {
+			instance.WaitContext(ws.Context())
+		}
+		// Done
+		return nil
+	}
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return p.watch(interval)
+}
+
+// Build the target then start the application, the running instances are stopped before starting. Only add the
+// actions to the plan in the dry run, no instance is returned
+func (this *pipeline) run() (*runner.AppInstance, error) {
+	if len(this.targetUris) > 0 {
+		if err := build.BuildTargets(this.targetUris, this.ws, this.options, this.logger); err != nil {
+			return nil, err
+		}
+	}
+	if this.ws.Canceled() != nil {
+		return nil, cli.NewExitError("", opcli.ExitCodeInterrupted)
+	}
+	instances, err := this.runner.GetRunningInstancesByName(this.app)
+	if err != nil {
+		this.logger.LeveledPrintf(log.LevelError, "Failed to get instances by name, error: %s\n", err)
+		return nil, cli.NewExitError("", 1)
+	}
+	if this.plan.DryRun {
+		for _, instance := range instances {
+			this.plan.Add("stop", instance.ID, fmt.Sprintf("application %s", this.app))
+		}
+		this.plan.Add("start", this.app, strings.Join(this.args, " "))
+		return nil, nil
+	}
+	for _, instance := range instances {
+		this.logger.Printf("Stopping [%s] ...... ", instance.ID)
+		if err := this.runner.Stop(instance.ID, false); err != nil {
+			this.logger.LeveledHeadedPrintf("", log.LevelError, "Error: %s\n", err)
+			return nil, cli.NewExitError("", 1)
+		}
+		this.logger.LeveledHeadedPrint("", log.LevelSuccess, "Done\n")
+	}
+	instance, err := this.runner.Start(this.app, "", runner.AppStartOptions{Args: this.args, Background: this.background})
+	if err != nil {
+		this.logger.LeveledPrintf(log.LevelError, "Failed to start application, error: %s\n", err)
+		return nil, cli.NewExitError("", 1)
+	}
+	this.logger.LeveledPrintf(log.LevelSuccess, "Application [%s] started, instance [%s]\n", this.app, instance.ID)
+	// Done
+	return instance, nil
+}
+
+// Run the pipeline on the changes until interrupted
+func (this *pipeline) watch(interval time.Duration) error {
+	roots := this.watchRoots()
+	if len(roots) == 0 {
+		this.logger.LeveledPrintln(log.LevelError, "No local repository to watch")
+		return cli.NewExitError("", 1)
+	}
+	var started *runner.AppInstance
+	for this.ws.Canceled() == nil {
+		// Get the digest before running, the changes while building trigger the next run
+		digest, err := this.digest(roots)
+		if err != nil {
+			this.logger.LeveledPrintf(log.LevelError, "Failed to check the changes, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		if instance, err := this.run(); err == nil {
+			started = instance
+		} else if started != nil {
+			this.logger.LeveledPrintf(log.LevelWarn, "Keep the running instance [%s]\n", started.ID)
+		}
+		this.logger.Printf("Watching %s for changes\n", strings.Join(roots, ", "))
+		if !this.waitChanges(roots, digest, interval) {
+			break
+		}
+	}
+	// Stop the instance started by the pipeline
+	if started != nil {
+		if status, _ := started.GetStatus(); status == runner.StatusRunning {
+			this.logger.Printf("Stopping [%s] ...... ", started.ID)
+			if err := this.runner.Stop(started.ID, false); err != nil {
+				this.logger.LeveledHeadedPrintf("", log.LevelError, "Error: %s\n", err)
+			} else {
+				this.logger.LeveledHeadedPrint("", log.LevelSuccess, "Done\n")
+			}
+		}
+	}
+	return cli.NewExitError("", opcli.ExitCodeInterrupted)
+}
+
+// Wait for the changes, returns false if interrupted
+func (this *pipeline) waitChanges(roots []string, digest string, interval time.Duration) bool {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-this.ws.Context().Done():
+			return false
+		case <-ticker.C:
+		}
+		current, err := this.digest(roots)
+		if err != nil {
+			this.logger.LeveledPrintf(log.LevelWarn, "Failed to check the changes, error: %s\n", err)
+			continue
+		}
+		if current != digest {
+			this.logger.Println("Changes detected")
+			return true
+		}
+	}
+}
+
+// Get the local repositories to watch, the current git repository if no target
+func (this *pipeline) watchRoots() []string {
+	var roots []string
+	for _, targetUri := range this.targetUris {
+		if info, err := os.Stat(targetUri.Repository.Uri); err == nil && info.IsDir() {
+			roots = append(roots, targetUri.Repository.Uri)
+		}
+	}
+	if len(roots) == 0 {
+		if root, err := opcli.GetGitRootFromCurrentDirectory(); err == nil {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// Get the digest of the stats of the files in the roots
+func (this *pipeline) digest(roots []string) (string, error) {
+	var digests []string
+	for _, root := range roots {
+		digest, err := util.StatPathDigest(root, func(path string, info os.FileInfo) bool {
+			return strings.HasPrefix(info.Name(), ".") || path == this.options.Output
+		})
+		if err != nil {
+			return "", err
+		}
+		digests = append(digests, digest)
+	}
+	return strings.Join(digests, ","), nil
+}
+
+// Get the target uri of the application, the target without repository is in the git repository of the spec file
+func getAppTargetUri(target, specPath string) (*uri.TargetUri, error) {
+	targetUri := uri.ParseTargetUri(target)
+	if targetUri == nil {
+		return nil, errors.New(fmt.Sprintf("Malformed target uri [%s]", target))
+	}
+	if targetUri.Repository == nil {
+		root, err := opcli.GetGitRootPath(filepath.Dir(specPath))
+		if err != nil {
+			if root, err = opcli.GetGitRootFromCurrentDirectory(); err != nil {
+				return nil, errors.New(fmt.Sprintf("Failed to get the git repository of target [%s], error: %s", target, err))
+			}
+		}
+		targetUri.Repository = &uri.RepositoryUri{Uri: root}
+	}
+	// Done
+	return targetUri, nil
+}
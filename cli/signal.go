@@ -0,0 +1,41 @@
+// Author: lipixun
+// Created Time : 六 10/17 22:21:48 2026
+//
+// File Name: signal.go
+// Description:
+//	The interrupt handling of the commands
+//
+//	The first SIGINT or SIGTERM cancels the created workspaces (see workspace/cancel.go), the command stops the
+//	running builds, tests and external commands then returns through its cleanups. The second one exits
+//	immediately, the temp directories are still removed.
+package cli
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Handle the interrupt signals, should be called once before running the command
+func HandleSignals() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		workspacesLock.Lock()
+		created := append([]*workspace.Workspace(nil), workspaces...)
+		workspacesLock.Unlock()
+		if len(created) == 0 {
+			// Nothing to clean
+			Exit(ExitCodeInterrupted)
+		}
+		for _, ws := range created {
+			ws.Logger.LeveledPrintf(log.LevelWarn, "Interrupted, canceling. Interrupt again to exit immediately\n")
+			ws.Cancel()
+		}
+		<-signals
+		Exit(ExitCodeInterrupted)
+	}()
+}
@@ -0,0 +1,121 @@
+// Author: lipixun
+// Created Time : 日 10/18 05:24:10 2026
+//
+// File Name: telemetry.go
+// Description:
+//	Record the command runs as the telemetry events (see workspace/telemetry.go), only if enabled by
+//	op telemetry enable. The commands not using the workspace (e.g. op version, op completion) are not recorded.
+//
+//	A notice of the telemetry (disabled by default) is shown once on the first run in a terminal.
+package cli
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The command run to record
+var telemetry struct {
+	lock     sync.Mutex
+	version  string
+	command  string
+	flags    []string
+	start    time.Time
+	recorded bool
+}
+
+// Set the hooks of the commands to record the runs, should be called after the commands added
+// Parameters:
+//
+//	version 	The version of op in the events, the build tag
+func SetTelemetryHooks(app *cli.App, version string) {
+	telemetry.version = version
+	setCommandTelemetryHooks(app.Commands, nil)
+}
+
+// Set the before hooks of the leaf commands to get the command path and the flags set
+func setCommandTelemetryHooks(commands []cli.Command, path []string) {
+	for i := range commands {
+		commandPath := append(append([]string{}, path...), commands[i].Name)
+		if len(commands[i].Subcommands) > 0 {
+			setCommandTelemetryHooks(commands[i].Subcommands, commandPath)
+			continue
+		}
+		flags, before := commands[i].Flags, commands[i].Before
+		commands[i].Before = func(c *cli.Context) error {
+			startTelemetry(strings.Join(commandPath, " "), flags, c)
+			if before != nil {
+				return before(c)
+			}
+			return nil
+		}
+	}
+}
+
+// Start the command run
+func startTelemetry(command string, flags []cli.Flag, c *cli.Context) {
+	telemetry.lock.Lock()
+	defer telemetry.lock.Unlock()
+	telemetry.command, telemetry.start = command, time.Now()
+	telemetry.flags = nil
+	for _, flag := range flags {
+		name := strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+		if c.IsSet(name) {
+			telemetry.flags = append(telemetry.flags, name)
+		}
+	}
+}
+
+// Record the command run with the exit code and flush the events, should be called before closing the workspaces
+func RecordTelemetry(code int) {
+	telemetry.lock.Lock()
+	defer telemetry.lock.Unlock()
+	if telemetry.command == "" || telemetry.recorded {
+		return
+	}
+	telemetry.recorded = true
+	workspacesLock.Lock()
+	var ws *workspace.Workspace
+	if len(workspaces) > 0 {
+		ws = workspaces[0]
+	}
+	workspacesLock.Unlock()
+	if ws == nil || !ws.TelemetryEnabled() {
+		return
+	}
+	event := workspace.TelemetryEvent{
+		Command:    telemetry.command,
+		Flags:      telemetry.flags,
+		DurationMs: int64(time.Since(telemetry.start) / time.Millisecond),
+		ExitCode:   code,
+		Version:    telemetry.version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Date:       time.Now().UTC().Format("2006-01-02"),
+	}
+	if code != 0 {
+		if event.ErrorKind = exitCodeKinds[code]; event.ErrorKind == "" {
+			event.ErrorKind = exitCodeKinds[ExitCodeError]
+		}
+	}
+	if err := ws.RecordTelemetry(event); err != nil {
+		ws.Logger.LeveledPrintf(log.LevelDebug, "Failed to record telemetry, error: %s\n", err)
+	}
+	if err := ws.FlushTelemetry(false); err != nil {
+		ws.Logger.LeveledPrintf(log.LevelDebug, "Failed to flush telemetry, error: %s\n", err)
+	}
+}
+
+// Show the notice of the telemetry on the first run in a terminal
+func showTelemetryNotice(ws *workspace.Workspace) {
+	if !log.IsTerminal(os.Stderr) || !ws.CheckTelemetryNotice() {
+		return
+	}
+	ws.Logger.LeveledPrintf(log.LevelInfo, "Op could send the anonymous usage metrics (commands, durations and error kinds, no paths or identifiers) to help the maintainers, it's disabled unless you run: op telemetry enable\n")
+}
@@ -0,0 +1,54 @@
+// Author: lipixun
+// Created Time : 日 10/18 05:41:36 2026
+//
+// File Name: main.go
+// Description:
+//	The telemetry command shows and switches the anonymous usage metrics (see workspace/telemetry.go)
+package telemetry
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Telemetry"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Category: "Workspace",
+			Name:     "telemetry",
+			Usage:    "Show, enable or disable the anonymous usage metrics (disabled by default)",
+			Subcommands: []cli.Command{
+				{
+					Name:   "status",
+					Usage:  "Show whether the metrics are enabled, the endpoint and the queued events",
+					Action: Status,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "events",
+							Usage: "Print the queued events",
+						},
+					},
+				},
+				{
+					Name:   "enable",
+					Usage:  "Enable the metrics in the user config",
+					Action: Enable,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "endpoint",
+							Usage: "The url to post the metrics to, the config telemetry.endpoint is not changed if not specified",
+						},
+					},
+				},
+				{
+					Name:   "disable",
+					Usage:  "Disable the metrics in the user config and remove the queued events",
+					Action: Disable,
+				},
+			},
+		},
+	}
+}
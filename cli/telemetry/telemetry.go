@@ -0,0 +1,146 @@
+// Author: lipixun
+// Created Time : 日 10/18 05:46:02 2026
+//
+// File Name: telemetry.go
+// Description:
+//	Show and switch the telemetry
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"strconv"
+	"time"
+)
+
+// The telemetry status in the structured output
+type StatusResult struct {
+	Enabled   bool                       `json:"enabled"`
+	Layer     string                     `json:"layer"` // The config layer defines telemetry.enabled, default if not defined
+	Endpoint  string                     `json:"endpoint"`
+	Queued    int                        `json:"queued"`
+	LastFlush string                     `json:"lastFlush,omitempty"`
+	Events    []workspace.TelemetryEvent `json:"events,omitempty"`
+}
+
+func Status(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	events, err := ws.GetTelemetryEvents()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to read the queued events, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	_, layer, _ := ws.LookupUserConfig(workspace.ConfigKeyTelemetryEnabled)
+	if layer == "" {
+		layer = "default"
+	}
+	result := StatusResult{
+		Enabled:  ws.TelemetryEnabled(),
+		Layer:    layer,
+		Endpoint: ws.GetUserConfigString(workspace.ConfigKeyTelemetryEndpoint),
+		Queued:   len(events),
+	}
+	if flushTime := ws.GetTelemetryFlushTime(); !flushTime.IsZero() {
+		result.LastFlush = flushTime.Format(time.RFC3339)
+	}
+	if c.Bool("events") {
+		result.Events = events
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(result); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render the status, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	if result.Enabled {
+		fmt.Printf("Telemetry:\tenabled (%s)\n", result.Layer)
+	} else {
+		fmt.Printf("Telemetry:\tdisabled (%s)\n", result.Layer)
+	}
+	if result.Endpoint != "" {
+		fmt.Printf("Endpoint:\t%s\n", result.Endpoint)
+	} else {
+		fmt.Printf("Endpoint:\tnot set, the events are kept locally\n")
+	}
+	fmt.Printf("Queued events:\t%d\n", result.Queued)
+	if result.LastFlush != "" {
+		fmt.Printf("Last flush:\t%s\n", result.LastFlush)
+	}
+	for _, event := range result.Events {
+		data, _ := json.Marshal(event)
+		fmt.Println(string(data))
+	}
+	// Done
+	return nil
+}
+
+func Enable(c *cli.Context) error {
+	return setEnabled(c, true, c.String("endpoint"))
+}
+
+func Disable(c *cli.Context) error {
+	return setEnabled(c, false, "")
+}
+
+// Set telemetry.enabled (and the endpoint if not empty) in the user config layer, the queued events are removed if
+// disabled
+func setEnabled(c *cli.Context, enabled bool, endpoint string) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	layer := ws.Config.Layer(workspace.ConfigLayerUser)
+	if layer == nil {
+		logger.LeveledPrintf(log.LevelError, "Config layer [%s] not loaded\n", workspace.ConfigLayerUser)
+		return cli.NewExitError("", 1)
+	}
+	if err := layer.Set(workspace.ConfigKeyTelemetryEnabled, strconv.FormatBool(enabled)); err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if endpoint != "" {
+		if err := layer.Set(workspace.ConfigKeyTelemetryEndpoint, endpoint); err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("set", workspace.ConfigKeyTelemetryEnabled, fmt.Sprintf("%t in %s", enabled, layer.Path))
+		if endpoint != "" {
+			plan.Add("set", workspace.ConfigKeyTelemetryEndpoint, fmt.Sprintf("%s in %s", endpoint, layer.Path))
+		}
+		if !enabled {
+			plan.Add("remove", "the queued events", "")
+		}
+		return plan.Report(c)
+	}
+	if err := layer.Save(); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to write config file [%s], error: %s\n", layer.Path, err)
+		return cli.NewExitError("", 1)
+	}
+	if !enabled {
+		if err := ws.ClearTelemetry(); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to remove the queued events, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		logger.LeveledPrintln(log.LevelSuccess, "Telemetry disabled, the queued events are removed")
+	} else {
+		logger.LeveledPrintln(log.LevelSuccess, "Telemetry enabled, see op telemetry status --events for the recorded events")
+	}
+	// Done
+	return nil
+}
@@ -0,0 +1,190 @@
+// Author: lipixun
+// Created Time : 六 10/17 20:21:43 2026
+//
+// File Name: terminal.go
+// Description:
+//	The terminal of the interactive commands (e.g. op ui, the picker), controlled by stty and the ansi escape sequences
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	KeyUp        = "up"
+	KeyDown      = "down"
+	KeyPageUp    = "pgup"
+	KeyPageDown  = "pgdn"
+	KeyEnter     = "enter"
+	KeyBackspace = "backspace"
+	KeyEscape    = "esc"
+	KeyCtrlC     = "ctrl-c"
+
+	escAltScreenEnter = "\x1b[?1049h"
+	escAltScreenLeave = "\x1b[?1049l"
+	escCursorHide     = "\x1b[?25l"
+	escCursorShow     = "\x1b[?25h"
+	escHome           = "\x1b[H"
+	escClearLine      = "\x1b[K"
+	escClearBelow     = "\x1b[J"
+
+	EscReverse = "\x1b[7m"
+	EscBold    = "\x1b[1m"
+	EscNormal  = "\x1b[22m" // Neither bold nor faint
+	EscReset   = "\x1b[0m"
+)
+
+// The terminal in the cbreak mode (keys are read without enter and not echoed)
+type Terminal struct {
+	state string // The saved stty state
+}
+
+// Open the terminal, enter the alternate screen
+func OpenTerminal() (*Terminal, error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, errors.New("Require a terminal")
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	fmt.Print(escAltScreenEnter + escCursorHide)
+	return &Terminal{state: state}, nil
+}
+
+// Restore the terminal
+func (this *Terminal) Close() {
+	fmt.Print(escCursorShow + escAltScreenLeave)
+	stty(this.state)
+}
+
+// Get the rows and columns of the terminal, 24x80 if unknown
+func (this *Terminal) Size() (int, int) {
+	rows, cols := 24, 80
+	if output, err := stty("size"); err == nil {
+		var r, c int
+		if fmt.Sscanf(output, "%d %d", &r, &c); r > 0 && c > 0 {
+			rows, cols = r, c
+		}
+	}
+	return rows, cols
+}
+
+// Draw the lines from the top of the screen, the lines should fit the width
+func (this *Terminal) Draw(lines []string) {
+	var builder strings.Builder
+	builder.WriteString(escHome)
+	for i, line := range lines {
+		builder.WriteString(line)
+		builder.WriteString(escClearLine)
+		if i < len(lines)-1 {
+			builder.WriteString("\r\n")
+		}
+	}
+	builder.WriteString(escClearBelow)
+	os.Stdout.WriteString(builder.String())
+}
+
+// Read the keys until stdin is closed
+func (this *Terminal) ReadKeys(keys chan<- string) {
+	for {
+		read, err := this.ReadKey()
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, key := range read {
+			keys <- key
+		}
+	}
+}
+
+// Read the keys of a single input (e.g. a key press or a paste)
+func (this *Terminal) ReadKey() ([]string, error) {
+	buffer := make([]byte, 64)
+	n, err := os.Stdin.Read(buffer)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeys(buffer[:n]), nil
+}
+
+// Parse the keys, the escape sequences of the arrows and pages are named, ctrl-p and ctrl-n are up and down
+func parseKeys(data []byte) []string {
+	var keys []string
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] == 0x1b && i+2 < len(data) && data[i+1] == '[':
+			switch {
+			case data[i+2] == 'A':
+				keys = append(keys, KeyUp)
+			case data[i+2] == 'B':
+				keys = append(keys, KeyDown)
+			case data[i+2] == '5' && i+3 < len(data) && data[i+3] == '~':
+				keys = append(keys, KeyPageUp)
+				i++
+			case data[i+2] == '6' && i+3 < len(data) && data[i+3] == '~':
+				keys = append(keys, KeyPageDown)
+				i++
+			}
+			i += 2
+		case data[i] == 0x1b && i == len(data)-1:
+			keys = append(keys, KeyEscape)
+		case data[i] == 0x7f || data[i] == 0x08:
+			keys = append(keys, KeyBackspace)
+		case data[i] == 0x10:
+			keys = append(keys, KeyUp)
+		case data[i] == 0x0e:
+			keys = append(keys, KeyDown)
+		case data[i] == '\r' || data[i] == '\n':
+			keys = append(keys, KeyEnter)
+		case data[i] == 0x03:
+			keys = append(keys, KeyCtrlC)
+		case data[i] >= 0x20 && data[i] < 0x7f:
+			keys = append(keys, string(data[i]))
+		}
+	}
+	return keys
+}
+
+// Run stty on the terminal
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// Fit the text to the width, the escape sequences (e.g. the colors of the logs) and control characters are removed
+// and the tabs are expanded
+func Fit(text string, width int) string {
+	var builder strings.Builder
+	var n int
+	var escaping bool
+	for _, r := range text {
+		if n >= width {
+			break
+		}
+		switch {
+		case escaping:
+			// The csi sequence ends with a letter
+			escaping = !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+		case r == 0x1b:
+			escaping = true
+		case r == '\t':
+			builder.WriteRune(' ')
+			for n++; n < width && n%4 != 0; n++ {
+				builder.WriteRune(' ')
+			}
+		case r < 0x20 || r == 0x7f:
+		default:
+			builder.WriteRune(r)
+			n++
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+// Author: lipixun
+// Created Time : 五 10/16 15:02:51 2026
+//
+// File Name: main.go
+// Description:
+//	The test command runs the test targets (the targets with the test spec) of current repository, e.g.
+//		op test --jobs 4 --junit build/junit.xml
+//		op test 'deps(:server)'
+package test
+
+import (
+	opcli "github.com/ops-openlight/openlight/cli"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Tester"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Category:     "Tester",
+			Name:         "test",
+			Usage:        "Run the test targets of current repository, all test targets (...) by default",
+			ArgsUsage:    "[query expression]",
+			Action:       Test,
+			BashComplete: opcli.BashComplete(nil, opcli.CompleteTargets),
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "Only run the test targets whose key matches this regular expression",
+				},
+				cli.IntFlag{
+					Name:  "jobs, j",
+					Value: 1,
+					Usage: "The number of tests to run in parallel",
+				},
+				cli.StringFlag{
+					Name:  "junit",
+					Usage: "Write the junit xml report of the test results to the file, e.g. for the CI",
+				},
+				cli.BoolFlag{
+					Name:  "no-cache",
+					Usage: "Run the tests even if the inputs are not changed since last pass",
+				},
+				cli.BoolFlag{
+					Name:  "disable-finder",
+					Usage: "Disable the repository local finder",
+				},
+			},
+		},
+	}
+}
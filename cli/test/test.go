@@ -0,0 +1,142 @@
+// Author: lipixun
+// Created Time : 五 10/16 15:10:24 2026
+//
+// File Name: test.go
+// Description:
+//	The test command
+package test
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/tester"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func Test(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Get options
+	expr := strings.Join(c.Args(), " ")
+	if expr == "" {
+		expr = graph.QueryAllTargets
+	}
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	options := tester.TesterOptions{Jobs: c.Int("jobs"), NoCache: c.Bool("no-cache") || !ws.Config.GetBool(workspace.ConfigKeyTestCache)}
+	if filter := c.String("filter"); filter != "" {
+		exp, err := regexp.Compile(filter)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Invalid filter [%s], error: %s\n", filter, err)
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+		options.Filter = exp
+	}
+	// Load the current repository with all targets
+	rootPath, err := opcli.GetGitRootFromCurrentDirectory()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get current git root directory, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	g, err := graph.New(ws, graph.GraphOptions{UseLocalDependency: true, DisableFinder: c.Bool("disable-finder")})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create sourcecode graph, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	root, err := g.Load(rootPath, graph.LoadOptions{})
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to load repository [%s], error: %s\n", rootPath, err)
+		return cli.NewExitError("", 1)
+	}
+	targets, err := g.Query(expr, root)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to query test targets, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	// Run the tests
+	t, err := tester.New(g, options)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create tester, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	testTargets := t.GetTestTargets(targets)
+	if len(testTargets) == 0 {
+		logger.LeveledPrintln(log.LevelWarn, "No test target found")
+		if path := c.String("junit"); path != "" {
+			// An empty report, so the CI step reading it doesn't fail
+			if err := tester.WriteJUnitReport(path, nil); err != nil {
+				logger.LeveledPrintf(log.LevelError, "Failed to write junit report [%s], error: %s\n", path, err)
+				return cli.NewExitError("", 1)
+			}
+		}
+		if renderer.Structured() {
+			return render(renderer, []*tester.TestResult{}, logger)
+		}
+		return nil
+	}
+	var cached int
+	var failed []*tester.TestResult
+	start := time.Now()
+	results := t.Run(testTargets)
+	for _, result := range results {
+		switch result.Status {
+		case tester.StatusPassed:
+			logger.LeveledPrintf(log.LevelSuccess, "PASS   %s (%.2fs)\n", result.Target, result.Duration)
+		case tester.StatusCached:
+			cached++
+			logger.LeveledPrintf(log.LevelSuccess, "PASS   %s (cached)\n", result.Target)
+		default:
+			failed = append(failed, result)
+			logger.LeveledPrintf(log.LevelFail, "FAIL   %s (%.2fs) %s, log: %s\n", result.Target, result.Duration, result.Error, result.LogFile)
+			annotation := opcli.Annotation{Level: opcli.AnnotationError, Title: fmt.Sprintf("Test failed: %s", result.Target), Message: result.Error}
+			if target := g.Targets[result.Target]; target != nil {
+				annotation.File = target.Repository.SpecFile
+				annotation.Line = spec.GetKeyPathLine(target.Repository.SpecFile, fmt.Sprintf("targets.%s.test", target.Name))
+			}
+			opcli.Annotate(annotation)
+		}
+	}
+	// Summary
+	for _, result := range failed {
+		logger.LeveledPrintf(log.LevelFail, "Failed: %s\n", result.Target)
+	}
+	logger.Printf("%d passed (%d cached), %d failed in %.2fs\n", len(results)-len(failed), cached, len(failed), time.Since(start).Seconds())
+	if path := c.String("junit"); path != "" {
+		if err := tester.WriteJUnitReport(path, results); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to write junit report [%s], error: %s\n", path, err)
+			return cli.NewExitError("", 1)
+		}
+		logger.LeveledPrintf(log.LevelInfo, "Junit report written to %s\n", path)
+	}
+	if renderer.Structured() {
+		if err := render(renderer, results, logger); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return cli.NewExitError("", opcli.ExitCodeTestFailure)
+	}
+	// Done
+	return nil
+}
+
+// Render the test results in the structured output
+func render(renderer *opcli.Renderer, results interface{}, logger log.Logger) error {
+	if err := renderer.Render(results); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to render test results, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
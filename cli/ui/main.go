@@ -0,0 +1,37 @@
+// Author: lipixun
+// Created Time : 六 10/17 20:18:27 2026
+//
+// File Name: main.go
+// Description:
+//	The ui command shows the interactive dashboard of the runner instances, builds and logs
+package ui
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.UI"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Category: "Runner",
+			Name:     "ui",
+			Usage:    "Show the interactive dashboard of the running instances, recent builds and logs",
+			Action:   UI,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "interval, i",
+					Value: 2,
+					Usage: "The refresh interval in seconds",
+				},
+				cli.BoolFlag{
+					Name:  "all, a",
+					Usage: "Show all instances instead of the running ones",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,364 @@
+// Author: lipixun
+// Created Time : 六 10/17 20:34:09 2026
+//
+// File Name: ui.go
+// Description:
+//	The terminal dashboard of the runner instances and the builds
+//
+//	The dashboard is refreshed every interval (or on each key):
+//		The instances 	The instances with the status and resource usage (by ps), the running ones by default
+//		The builds 		The recent build summaries
+//		The logs 		The tail of the log of the selected instance, scrollable
+//
+//	The keys:
+//		up/k down/j 	Select the instance
+//		pgup/u pgdn/d 	Scroll the logs
+//		o 				Switch the log between stderr and stdout
+//		a 				Show all instances or the running ones
+//		s 				Stop the selected instance
+//		r 				Restart the selected instance (in background)
+//		c 				Clean the selected instance (stop it first if running)
+//		q 				Quit
+package ui
+
+import (
+	"errors"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	RecentBuildCount = 5
+	LogTailSize      = 256 << 10 // The max bytes to read from the end of the log file
+
+	instanceFormat = "%-24s %-24s %-8s %-8s %6s %10s  %s"
+)
+
+// The dashboard state
+type dashboard struct {
+	ws       *workspace.Workspace
+	runner   *runner.AppRunner
+	all      bool   // Show all instances
+	stdout   bool   // Show the stdout log instead of stderr
+	selected string // The selected instance id
+	scroll   int    // The log lines scrolled up from the bottom
+	message  string // The result of the last action
+
+	instances []*runner.AppInstance
+}
+
+// The resource usage of a process
+type usage struct {
+	CPU float64 // The cpu percentage
+	RSS int64   // The resident memory in bytes
+}
+
+func UI(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// The logs of the runner actions would break the screen
+	ws.Logger = log.New(io.Discard, log.LevelError, log.LevelInfo, workspace.WorkspaceLogHeader)
+	r, err := runner.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to create runner, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	term, err := opcli.OpenTerminal()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	defer term.Close()
+	d := &dashboard{ws: ws, runner: r, all: c.Bool("all")}
+	keys := make(chan string)
+	go term.ReadKeys(keys)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		rows, cols := term.Size()
+		term.Draw(d.render(rows, cols))
+		select {
+		case key, ok := <-keys:
+			if !ok || !d.handle(key) {
+				return nil
+			}
+		case <-signals:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Handle the key, returns false to quit
+func (this *dashboard) handle(key string) bool {
+	switch key {
+	case "q", opcli.KeyCtrlC:
+		return false
+	case opcli.KeyUp, "k":
+		this.move(-1)
+	case opcli.KeyDown, "j":
+		this.move(1)
+	case opcli.KeyPageUp, "u":
+		this.scroll += 10
+	case opcli.KeyPageDown, "d":
+		if this.scroll -= 10; this.scroll < 0 {
+			this.scroll = 0
+		}
+	case "o":
+		this.stdout, this.scroll = !this.stdout, 0
+	case "a":
+		this.all = !this.all
+	case "s":
+		this.act("Stopped", func(id string) (string, error) {
+			return id, this.runner.Stop(id, false)
+		})
+	case "c":
+		this.act("Cleaned", func(id string) (string, error) {
+			if instance := this.find(id); instance != nil {
+				if status, _ := instance.GetStatus(); status == runner.StatusRunning {
+					if err := instance.Stop(); err != nil {
+						return "", err
+					}
+				}
+			}
+			return "", this.runner.Clean(id)
+		})
+	case "r":
+		this.act("Restarted", func(id string) (string, error) {
+			instance, err := this.runner.GetInstance(id)
+			if err != nil || instance == nil {
+				return "", errors.New(fmt.Sprintf("Instance [%s] not found", id))
+			}
+			if err := instance.Stop(); err != nil {
+				return "", err
+			}
+			options := instance.Options
+			options.Background = true
+			started, err := this.runner.Start(instance.Name, instance.Command, options)
+			if err != nil {
+				return "", err
+			}
+			return started.ID, nil
+		})
+	}
+	return true
+}
+
+// Run the action on the selected instance, the returned id is selected
+func (this *dashboard) act(name string, action func(id string) (string, error)) {
+	if this.selected == "" {
+		this.message = "No instance selected"
+		return
+	}
+	id, err := action(this.selected)
+	if err != nil {
+		this.message = fmt.Sprintf("Failed on [%s], error: %s", this.selected, err)
+		return
+	}
+	this.message = fmt.Sprintf("%s [%s]", name, this.selected)
+	this.selected, this.scroll = id, 0
+}
+
+// Move the selection
+func (this *dashboard) move(delta int) {
+	if len(this.instances) == 0 {
+		return
+	}
+	idx := 0
+	for i, instance := range this.instances {
+		if instance.ID == this.selected {
+			idx = i + delta
+		}
+	}
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(this.instances) {
+		idx = len(this.instances) - 1
+	}
+	this.selected, this.scroll = this.instances[idx].ID, 0
+}
+
+// Render the screen
+func (this *dashboard) render(rows, cols int) []string {
+	var lines []string
+	add := func(line string) {
+		lines = append(lines, opcli.Fit(line, cols))
+	}
+	bold := func(line string) {
+		lines = append(lines, opcli.EscBold+opcli.Fit(line, cols)+opcli.EscReset)
+	}
+	bold(fmt.Sprintf("op ui  workspace [%s]  %s", this.ws.Name, time.Now().Format(log.DefaultTimeLayout)))
+	add("[q]uit [s]top [r]estart [c]lean [a]ll [o]stdout/stderr  up/down select  pgup/pgdn scroll")
+	// The instances
+	instances, err := this.runner.List(!this.all)
+	if err != nil {
+		this.message = fmt.Sprintf("Failed to list instances, error: %s", err)
+	}
+	this.instances = instances
+	if len(instances) > 0 && this.find(this.selected) == nil {
+		this.selected = instances[0].ID
+	}
+	add("")
+	bold(fmt.Sprintf(instanceFormat, "ID", "NAME", "PID", "STATUS", "CPU%", "MEM", "STARTED"))
+	usages := getUsages(instances)
+	for _, instance := range instances {
+		status := "Error"
+		if s, _ := instance.GetStatus(); s == runner.StatusRunning {
+			status = "Running"
+		} else if s == runner.StatusExited {
+			status = "Exited"
+		}
+		name := instance.Name
+		if instance.Sequence > 0 {
+			name = fmt.Sprintf("%s#%d", name, instance.Sequence)
+		}
+		var cpu, mem string
+		if u, ok := usages[instance.Pid]; ok && status == "Running" {
+			cpu, mem = fmt.Sprintf("%.1f", u.CPU), util.FormatSize(u.RSS)
+		}
+		line := opcli.Fit(fmt.Sprintf(instanceFormat, instance.ID, name, strconv.Itoa(instance.Pid), status, cpu, mem, instance.Time.Format(log.DefaultTimeLayout)), cols)
+		if instance.ID == this.selected {
+			line = opcli.EscReverse + line + opcli.EscReset
+		}
+		lines = append(lines, line)
+	}
+	if len(instances) == 0 {
+		add("  No instance")
+	}
+	// The builds
+	add("")
+	bold("Recent builds")
+	if summaries, err := builder.LoadBuildSummaries(this.ws); err != nil {
+		add(fmt.Sprintf("  Failed to load build summaries, error: %s", err))
+	} else {
+		for i, summary := range summaries {
+			if i >= RecentBuildCount {
+				break
+			}
+			var targets []string
+			for _, target := range summary.Targets {
+				targets = append(targets, fmt.Sprintf("%s#%d", target.Target, target.Number))
+			}
+			add(fmt.Sprintf("  %s %s %-9s %s", summary.Time.Format(log.DefaultTimeLayout), summary.Tag, summary.Status(), strings.Join(targets, " ")))
+		}
+		if len(summaries) == 0 {
+			add("  No build")
+		}
+	}
+	// The logs fill the rest of the screen except the message line
+	add("")
+	logName := "stderr"
+	if this.stdout {
+		logName = "stdout"
+	}
+	bold(fmt.Sprintf("Logs (%s) %s", logName, this.selected))
+	height := rows - len(lines) - 1
+	if this.selected != "" && height > 0 {
+		for _, line := range this.tailLog(height) {
+			add("  " + line)
+		}
+	}
+	for len(lines) < rows-1 {
+		lines = append(lines, "")
+	}
+	add(this.message)
+	return lines
+}
+
+// Get the instance by id, nil if not listed
+func (this *dashboard) find(id string) *runner.AppInstance {
+	for _, instance := range this.instances {
+		if instance.ID == id {
+			return instance
+		}
+	}
+	return nil
+}
+
+// Get the log lines of the selected instance fit the height, scrolled up from the bottom
+func (this *dashboard) tailLog(height int) []string {
+	file, err := os.Open(this.runner.GetLogFile(this.selected, this.stdout))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	offset := info.Size() - LogTailSize
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && err != io.EOF {
+		return []string{err.Error()}
+	}
+	logLines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(logLines) > 1 {
+		// The first line is partial
+		logLines = logLines[1:]
+	}
+	if max := len(logLines) - height; this.scroll > max {
+		if this.scroll = max; this.scroll < 0 {
+			this.scroll = 0
+		}
+	}
+	end := len(logLines) - this.scroll
+	start := end - height
+	if start < 0 {
+		start = 0
+	}
+	return logLines[start:end]
+}
+
+// Get the resource usages of the instance processes by ps
+func getUsages(instances []*runner.AppInstance) map[int]usage {
+	usages := make(map[int]usage)
+	var pids []string
+	for _, instance := range instances {
+		pids = append(pids, strconv.Itoa(instance.Pid))
+	}
+	if len(pids) == 0 {
+		return usages
+	}
+	output, err := exec.Command("ps", "-o", "pid=,%cpu=,rss=", "-p", strings.Join(pids, ",")).Output()
+	if err != nil && len(output) == 0 {
+		return usages
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		pid, _ := strconv.Atoi(fields[0])
+		cpu, _ := strconv.ParseFloat(fields[1], 64)
+		rss, _ := strconv.ParseInt(fields[2], 10, 64)
+		usages[pid] = usage{CPU: cpu, RSS: rss << 10}
+	}
+	return usages
+}
@@ -0,0 +1,120 @@
+// Author: lipixun
+// Created Time : 六 10/17 21:40:15 2026
+//
+// File Name: main.go
+// Description:
+//	The self-update command replaces the op executable by the latest release of the channel, see update/update.go
+package update
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/update"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Update"
+)
+
+// Get the commands
+// Parameters:
+//
+//	version 	The version (build tag) of the running executable, empty for the development builds
+func GetCommand(version string) []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "self-update",
+			Usage: "Update op to the latest release of the channel (see config update.endpoint)",
+			Action: func(c *cli.Context) error {
+				return SelfUpdate(c, version)
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "channel",
+					Usage: "The release channel, stable or edge, config update.channel if not specified",
+				},
+				cli.BoolFlag{
+					Name:  "check",
+					Usage: "Only check the latest release, do not update",
+				},
+				cli.BoolFlag{
+					Name:  "insecure",
+					Usage: "Update without verifying the signature if there is no public key (config update.publickey), the sha256 is still verified",
+				},
+				cli.BoolFlag{
+					Name:  "force, f",
+					Usage: "Update even if the release is not newer (e.g. switch from edge to stable) or op is a development build",
+				},
+			},
+		},
+	}
+}
+
+func SelfUpdate(c *cli.Context, version string) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	channel := c.String("channel")
+	if channel == "" {
+		channel = ws.Config.GetString(workspace.ConfigKeyUpdateChannel)
+	}
+	updater, err := update.New(ws)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	updater.Insecure = c.Bool("insecure")
+	release, err := updater.GetLatestRelease(channel)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get the latest release, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	current := version
+	if current == "" {
+		current = "development build"
+	}
+	newer := version != "" && update.CompareVersions(release.Version, version) > 0
+	if c.Bool("check") {
+		if newer {
+			fmt.Printf("A new %s release [%s] is available, current [%s]. Run op self-update to update\n", channel, release.Version, current)
+		} else {
+			fmt.Printf("The latest %s release is [%s], current [%s]\n", channel, release.Version, current)
+		}
+		return nil
+	}
+	if !newer && !c.Bool("force") {
+		if version == "" {
+			logger.LeveledPrintf(log.LevelWarn, "Op is a development build, use --force to replace it by the %s release [%s]\n", channel, release.Version)
+		} else {
+			logger.LeveledPrintf(log.LevelSuccess, "Op [%s] is up to date of the %s channel (latest [%s])\n", version, channel, release.Version)
+		}
+		return nil
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		executable, err := update.GetExecutable("")
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get the executable, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		plan.Add("replace", executable, fmt.Sprintf("%s release [%s] from %s", channel, release.Version, release.URL))
+		return plan.Report(c)
+	}
+	logger.LeveledPrintf(log.LevelInfo, "Download %s release [%s] from [%s]\n", channel, release.Version, release.URL)
+	binary, err := updater.Download(release)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to download release [%s], error: %s\n", release.Version, err)
+		return cli.NewExitError("", 1)
+	}
+	if err := update.Replace("", binary); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to replace the executable, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Updated op from [%s] to [%s]\n", current, release.Version)
+	// Done
+	return nil
+}
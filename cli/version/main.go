@@ -0,0 +1,49 @@
+// Author: lipixun
+// Created Time : 日 10/18 02:03:51 2026
+//
+// File Name: main.go
+// Description:
+//	The version command prints the build information of op
+package version
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Version"
+)
+
+// The build information set at build time by -ldflags, empty for the development builds
+type BuildInfo struct {
+	Branch string
+	Commit string
+	Time   string
+	Tag    string
+}
+
+func GetCommand(info BuildInfo) []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "version",
+			Usage: "Print the build information of op: version, go runtime, platform and the dependencies",
+			Action: func(c *cli.Context) error {
+				return Version(c, info)
+			},
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "check",
+					Usage: "Check the latest release of the channel (see op self-update)",
+				},
+				cli.StringFlag{
+					Name:  "channel",
+					Usage: "The release channel to check, config update.channel if not specified",
+				},
+				cli.BoolFlag{
+					Name:  "deps",
+					Usage: "Print the modules op is built with (always included in the structured output)",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,169 @@
+// Author: lipixun
+// Created Time : 日 10/18 02:03:51 2026
+//
+// File Name: version.go
+// Description:
+//	Print the build information
+//
+//	The branch, commit, time and tag are set at build time by -ldflags (see cli/op/main.go), the go runtime and the
+//	modules are read from the build info embedded by the go toolchain.
+package version
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/update"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"runtime"
+	"runtime/debug"
+	"sort"
+)
+
+// The version in the structured output
+type Result struct {
+	Branch       string            `json:"branch"`
+	Commit       string            `json:"commit"`
+	BuildTime    string            `json:"buildTime"`
+	Tag          string            `json:"tag"` // The release version, empty for the development builds
+	GoVersion    string            `json:"goVersion"`
+	Platform     string            `json:"platform"`
+	Module       string            `json:"module,omitempty"`       // The main module path
+	Settings     map[string]string `json:"settings,omitempty"`     // The build settings, e.g. vcs.revision, CGO_ENABLED
+	Dependencies []Dependency      `json:"dependencies,omitempty"` // The modules op is built with
+	Latest       *Latest           `json:"latest,omitempty"`       // The latest release, only with --check
+}
+
+// A module op is built with
+type Dependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	Replace string `json:"replace,omitempty"` // The replacement module path@version
+}
+
+// The latest release of the channel
+type Latest struct {
+	Channel string `json:"channel"`
+	Version string `json:"version"`
+	Newer   bool   `json:"newer"` // Whether the release is newer than op
+}
+
+func Version(c *cli.Context, info BuildInfo) error {
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	result := getResult(info)
+	if c.Bool("check") {
+		ws, err := opcli.GetWorkspace(c)
+		if err != nil {
+			return err
+		}
+		logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+		channel := c.String("channel")
+		if channel == "" {
+			channel = ws.Config.GetString(workspace.ConfigKeyUpdateChannel)
+		}
+		updater, err := update.New(ws)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		release, err := updater.GetLatestRelease(channel)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get the latest release, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		result.Latest = &Latest{
+			Channel: channel,
+			Version: release.Version,
+			Newer:   info.Tag != "" && update.CompareVersions(release.Version, info.Tag) > 0,
+		}
+	}
+	if renderer.Structured() {
+		if err := renderer.Render(result); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Failed to render the version, error: %s", err), 1)
+		}
+		return nil
+	}
+	printResult(result, c.Bool("deps"))
+	// Done
+	return nil
+}
+
+// Get the version of the build information and the go build info
+func getResult(info BuildInfo) *Result {
+	result := &Result{
+		Branch:    info.Branch,
+		Commit:    info.Commit,
+		BuildTime: info.Time,
+		Tag:       info.Tag,
+		GoVersion: runtime.Version(),
+		Platform:  update.Platform(),
+	}
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return result
+	}
+	result.Module = buildInfo.Main.Path
+	if len(buildInfo.Settings) > 0 {
+		result.Settings = make(map[string]string)
+		for _, setting := range buildInfo.Settings {
+			result.Settings[setting.Key] = setting.Value
+		}
+	}
+	for _, dep := range buildInfo.Deps {
+		dependency := Dependency{Path: dep.Path, Version: dep.Version, Sum: dep.Sum}
+		if dep.Replace != nil {
+			dependency.Replace = fmt.Sprintf("%s@%s", dep.Replace.Path, dep.Replace.Version)
+		}
+		result.Dependencies = append(result.Dependencies, dependency)
+	}
+	// Done
+	return result
+}
+
+// Print the version in text
+func printResult(result *Result, deps bool) {
+	tag := result.Tag
+	if tag == "" {
+		tag = "development build"
+	}
+	fmt.Printf("op %s\n", tag)
+	fmt.Printf("  Branch:     %s\n", result.Branch)
+	fmt.Printf("  Commit:     %s\n", result.Commit)
+	fmt.Printf("  Build time: %s\n", result.BuildTime)
+	fmt.Printf("  Go:         %s\n", result.GoVersion)
+	fmt.Printf("  Platform:   %s\n", result.Platform)
+	if result.Latest != nil {
+		if result.Latest.Newer {
+			fmt.Printf("  Latest:     %s (%s), a newer release is available, run op self-update to update\n", result.Latest.Version, result.Latest.Channel)
+		} else {
+			fmt.Printf("  Latest:     %s (%s)\n", result.Latest.Version, result.Latest.Channel)
+		}
+	}
+	if !deps {
+		return
+	}
+	if len(result.Settings) > 0 {
+		fmt.Println("Build settings:")
+		var keys []string
+		for key := range result.Settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s=%s\n", key, result.Settings[key])
+		}
+	}
+	fmt.Printf("Modules (%s):\n", result.Module)
+	for _, dep := range result.Dependencies {
+		if dep.Replace != "" {
+			fmt.Printf("  %s %s => %s\n", dep.Path, dep.Version, dep.Replace)
+		} else {
+			fmt.Printf("  %s %s\n", dep.Path, dep.Version)
+		}
+	}
+}
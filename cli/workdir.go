@@ -0,0 +1,54 @@
+// Author: lipixun
+// Created Time : 日 10/18 07:31:18 2026
+//
+// File Name: workdir.go
+// Description:
+//	The work dir of the commands, the current directory unless --workdir (-C, or OP_WORKDIR) is specified, e.g.
+//		op -C ~/src/server build
+//	The project workdir (see workspace.WorkDirOptions) and the git repository of the commands (e.g. the current
+//	repository of op build, op query, op up) are detected from it. The relative paths in the arguments and flags
+//	(e.g. --output) are still relative to the current directory.
+package cli
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+)
+
+// The work dir specified by --workdir, empty for the current directory
+var workDir string
+
+// Set the work dir, should be called before running the command (e.g. in app.Before)
+func SetWorkDir(path string) error {
+	if path == "" {
+		return nil
+	}
+	realPath, err := util.GetRealPath(path)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Invalid workdir [%s], error: %s", path, err), ExitCodeUsage)
+	}
+	info, err := os.Stat(realPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cli.NewExitError(fmt.Sprintf("Invalid workdir [%s], directory not found", path), ExitCodeUsage)
+		}
+		return cli.NewExitError(fmt.Sprintf("Invalid workdir [%s], error: %s", path, err), ExitCodeUsage)
+	}
+	if !info.IsDir() {
+		return cli.NewExitError(fmt.Sprintf("Invalid workdir [%s], not a directory", path), ExitCodeUsage)
+	}
+	workDir = filepath.Clean(realPath)
+	// Done
+	return nil
+}
+
+// Get the work dir, the current directory if --workdir is not specified
+func GetWorkDir() (string, error) {
+	if workDir != "" {
+		return workDir, nil
+	}
+	return os.Getwd()
+}
@@ -0,0 +1,49 @@
+// Author: lipixun
+// Created Time : 六 10/17 23:31:12 2026
+//
+// File Name: alias.go
+// Description:
+//	The command aliases, see workspace/alias.go
+package workspace
+
+import (
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	opworkspace "github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"strings"
+)
+
+var (
+	runArgs   = os.Args              // The arguments of the running app, os.Args or the ones expanded from an alias
+	expanding opworkspace.AliasStack // The aliases being expanded, to detect the recursive aliases
+)
+
+// Run the alias, the commands are run by the app in order and the first failure exits with its exit code
+func runAlias(c *cli.Context, ws *opworkspace.Workspace, name, value string) error {
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	commands, err := opworkspace.ExpandAlias(value, c.Args().Tail())
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Invalid alias [%s], error: %s\n", name, err)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if err := expanding.Push(name); err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	defer expanding.Pop()
+	// The global flags before the alias are passed to each command
+	globals := append([]string{runArgs[0]}, runArgs[1:len(runArgs)-c.NArg()]...)
+	for _, command := range commands {
+		logger.LeveledPrintf(log.LevelDebug, "Run alias [%s] command: %s\n", name, strings.Join(command, " "))
+		runArgs = append(append([]string{}, globals...), command...)
+		if err := c.App.Run(runArgs); err != nil {
+			// The exit errors have exited already, the others are the usage errors printed by the cli
+			opcli.RecordError(err.Error())
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+	}
+	// Done
+	return nil
+}
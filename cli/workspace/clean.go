@@ -0,0 +1,252 @@
+// Author: lipixun
+// Created Time : 六 10/17 00:41:09 2026
+//
+// File Name: clean.go
+// Description:
+//	Report the disk usage and prune the workspace data
+//
+//	The categories:
+//		runner 		The data of stopped runner instances (running instances are never pruned)
+//		builds 		The build data of each build tag
+//		caches 		The workspace caches (each namespace is an item), the downloads and the test logs
+//		repos 		The fetched remote repositories and their worktrees (a single item)
+//		logs 		The op log files
+//
+//	The prune is confirmed with the summary of the items unless --yes is specified (see cli/confirm.go), the items are
+//	reported as the planned actions with the global --dry-run (see cli/plan.go).
+package workspace
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repofetcher"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/tester"
+	"github.com/ops-openlight/openlight/pkg/util"
+	opworkspace "github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	CleanCategoryAll = "all"
+)
+
+type cleanCategory struct {
+	Name  string
+	Items func(ws *opworkspace.Workspace) ([]*opworkspace.UsageItem, error)
+	Clean func(ws *opworkspace.Workspace) error // Remove all data of the category by its subsystem, nil to remove the items
+}
+
+var cleanCategories = []cleanCategory{
+	{"runner", getRunnerItems, cleanRunner},
+	{"builds", getBuildItems, builder.CleanBuildData},
+	{"caches", getCacheItems, nil},
+	{"repos", getRepoItems, repofetcher.CleanFetcherData},
+	{"logs", getLogItems, nil},
+}
+
+func Clean(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Get options
+	var olderThan time.Duration
+	if value := c.String("older-than"); value != "" {
+		if olderThan, err = util.ParseAge(value); err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	maxSize := int64(-1)
+	if value := c.String("max-size"); value != "" {
+		if maxSize, err = util.ParseSize(value); err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	plan := opcli.GetPlan(c)
+	dryRun := plan.DryRun
+	selected := make(map[string]bool)
+	for _, name := range c.Args() {
+		if name == CleanCategoryAll {
+			for _, category := range cleanCategories {
+				selected[category.Name] = true
+			}
+			continue
+		}
+		if !isCleanCategory(name) {
+			logger.LeveledPrintf(log.LevelError, "Unknown category [%s]\n", name)
+			return cli.NewExitError("", opcli.ExitCodeUsage)
+		}
+		selected[name] = true
+	}
+	// Report each category and select the items to prune
+	var failed bool
+	var total, pruneSize int64
+	var pruneItems []*opworkspace.UsageItem
+	var pruneCategories []string
+	for _, category := range cleanCategories {
+		items, err := category.Items(ws)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get the usage of [%s], error: %s\n", category.Name, err)
+			failed = true
+			continue
+		}
+		var size int64
+		for _, item := range items {
+			size += item.Size
+		}
+		total += size
+		fmt.Printf("%-8s %10s %d items\n", category.Name, util.FormatSize(size), len(items))
+		if !selected[category.Name] {
+			continue
+		}
+		selectedItems := opworkspace.SelectUsageItems(items, olderThan, maxSize)
+		for _, item := range selectedItems {
+			plan.Add("remove", item.Path, util.FormatSize(item.Size))
+			pruneSize += item.Size
+		}
+		if len(selectedItems) > 0 {
+			pruneItems = append(pruneItems, selectedItems...)
+			pruneCategories = append(pruneCategories, category.Name)
+		}
+	}
+	// Prune the items
+	var freed int64
+	if dryRun {
+		freed = pruneSize
+	} else if len(pruneItems) > 0 {
+		if err := opcli.Confirm(c, fmt.Sprintf("Remove %d items (%s) of %s", len(pruneItems), util.FormatSize(pruneSize), strings.Join(pruneCategories, ", "))); err != nil {
+			return err
+		}
+		for _, item := range pruneItems {
+			logger.LeveledPrintf(log.LevelDebug, "Remove %s (%s)\n", item.Path, util.FormatSize(item.Size))
+			if err := os.RemoveAll(item.Path); err != nil {
+				logger.LeveledPrintf(log.LevelError, "Failed to remove [%s], error: %s\n", item.Path, err)
+				failed = true
+				continue
+			}
+			freed += item.Size
+		}
+	}
+	fmt.Printf("%-8s %10s\n", "total", util.FormatSize(total))
+	if len(selected) > 0 {
+		if dryRun {
+			if err := plan.Report(c); err != nil {
+				return err
+			}
+			logger.LeveledPrintf(log.LevelInfo, "Would free %s\n", util.FormatSize(freed))
+		} else {
+			logger.LeveledPrintf(log.LevelSuccess, "Freed %s\n", util.FormatSize(freed))
+		}
+	}
+	if failed {
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+func isCleanCategory(name string) bool {
+	for _, category := range cleanCategories {
+		if category.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getRunnerItems(ws *opworkspace.Workspace) ([]*opworkspace.UsageItem, error) {
+	r, err := runner.New(ws)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := r.List(false)
+	if err != nil {
+		return nil, err
+	}
+	var items []*opworkspace.UsageItem
+	for _, instance := range instances {
+		if status, _ := instance.GetStatus(); status != runner.StatusExited {
+			continue
+		}
+		item, err := opworkspace.GetUsageItem(r.GetInstancePath(instance.ID))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return opworkspace.SortUsageItems(items), nil
+}
+
+func cleanRunner(ws *opworkspace.Workspace) error {
+	r, err := runner.New(ws)
+	if err != nil {
+		return err
+	}
+	return r.CleanAll()
+}
+
+func getBuildItems(ws *opworkspace.Workspace) ([]*opworkspace.UsageItem, error) {
+	path, err := builder.GetBuildDataPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	return opworkspace.ListUsageItems(path)
+}
+
+func getCacheItems(ws *opworkspace.Workspace) ([]*opworkspace.UsageItem, error) {
+	path, err := tester.GetTesterPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	fetcherPath, err := repofetcher.GetFetcherPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	var items []*opworkspace.UsageItem
+	for _, dir := range []string{filepath.Join(ws.Dir.User.RootPath(), opworkspace.CacheDirName), filepath.Join(path, tester.TesterLogsDirName)} {
+		dirItems, err := opworkspace.ListUsageItems(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range dirItems {
+			// The repositories are in the repos category
+			if item.Path != fetcherPath {
+				items = append(items, item)
+			}
+		}
+	}
+	return opworkspace.SortUsageItems(items), nil
+}
+
+// The repositories and the worktrees are a single item, a repository is referenced by its worktrees
+func getRepoItems(ws *opworkspace.Workspace) ([]*opworkspace.UsageItem, error) {
+	path, err := repofetcher.GetFetcherPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	item, err := opworkspace.GetUsageItem(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if item.Size == 0 {
+		return nil, nil
+	}
+	return []*opworkspace.UsageItem{item}, nil
+}
+
+func getLogItems(ws *opworkspace.Workspace) ([]*opworkspace.UsageItem, error) {
+	return opworkspace.ListUsageItems(filepath.Join(ws.Dir.User.RootPath(), opworkspace.WorkspaceLogDirName))
+}
@@ -0,0 +1,144 @@
+// Author: lipixun
+// Created Time : 日 10/18 04:06:25 2026
+//
+// File Name: cleanup.go
+// Description:
+//	The unified cleanup entry point, e.g.
+//		op clean --builds --cache
+//		op clean --all --yes
+//	Each selected category is cleaned by its subsystem (e.g. the runner removes the stopped instances, the builder
+//	removes the build data), then the freed space of each category is printed. The categories are the ones of
+//	op workspace clean (see clean.go), use it to prune by age or size.
+package workspace
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/util"
+	opworkspace "github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"strings"
+)
+
+// The flags of the categories
+var cleanupFlags = map[string]string{
+	"runner": "runner",
+	"builds": "builds",
+	"cache":  "caches",
+	"repos":  "repos",
+}
+
+// The cleanup of a category
+type cleanup struct {
+	category cleanCategory
+	items    []*opworkspace.UsageItem
+	size     int64
+}
+
+func Cleanup(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	// Select the categories
+	all := c.Bool("all")
+	selected := make(map[string]bool)
+	for flag, name := range cleanupFlags {
+		if all || c.Bool(flag) {
+			selected[name] = true
+		}
+	}
+	if !all && len(selected) == 0 {
+		logger.LeveledPrintln(log.LevelError, "Require at least one of --runner, --builds, --cache, --repos or --all")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	// Get the usage of the categories
+	plan := opcli.GetPlan(c)
+	var failed bool
+	var cleanups []*cleanup
+	var summaries []string
+	var total int64
+	for _, category := range cleanCategories {
+		if !all && !selected[category.Name] {
+			continue
+		}
+		items, err := category.Items(ws)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to get the usage of [%s], error: %s\n", category.Name, err)
+			failed = true
+			continue
+		}
+		if len(items) == 0 {
+			continue
+		}
+		item := &cleanup{category: category, items: items}
+		for _, usage := range items {
+			item.size += usage.Size
+		}
+		total += item.size
+		cleanups = append(cleanups, item)
+		summaries = append(summaries, fmt.Sprintf("%s (%d items, %s)", category.Name, len(items), util.FormatSize(item.size)))
+		plan.Add("clean", category.Name, fmt.Sprintf("%d items, %s", len(items), util.FormatSize(item.size)))
+	}
+	if len(cleanups) == 0 {
+		logger.LeveledPrintln(log.LevelInfo, "Nothing to clean")
+		if failed {
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	if plan.DryRun {
+		if err := plan.Report(c); err != nil {
+			return err
+		}
+		logger.LeveledPrintf(log.LevelInfo, "Would free %s\n", util.FormatSize(total))
+		return nil
+	}
+	if err := opcli.Confirm(c, fmt.Sprintf("Remove %s", strings.Join(summaries, ", "))); err != nil {
+		return err
+	}
+	// Clean each category, the freed space is the difference of the usage
+	var freed int64
+	for _, item := range cleanups {
+		if err := item.clean(ws, logger); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to clean [%s], error: %s\n", item.category.Name, err)
+			failed = true
+		}
+		var rest int64
+		if items, err := item.category.Items(ws); err == nil {
+			for _, usage := range items {
+				rest += usage.Size
+			}
+		}
+		size := item.size - rest
+		if size < 0 {
+			size = 0
+		}
+		freed += size
+		fmt.Printf("%-8s %10s freed\n", item.category.Name, util.FormatSize(size))
+	}
+	fmt.Printf("%-8s %10s freed\n", "total", util.FormatSize(freed))
+	if failed {
+		return cli.NewExitError("", 1)
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Freed %s\n", util.FormatSize(freed))
+	// Done
+	return nil
+}
+
+// Clean the category by its subsystem, or remove the items
+func (this *cleanup) clean(ws *opworkspace.Workspace, logger log.Logger) error {
+	if this.category.Clean != nil {
+		return this.category.Clean(ws)
+	}
+	for _, item := range this.items {
+		logger.LeveledPrintf(log.LevelDebug, "Remove %s (%s)\n", item.Path, util.FormatSize(item.Size))
+		if err := os.RemoveAll(item.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
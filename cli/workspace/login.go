@@ -0,0 +1,134 @@
+// Author: lipixun
+// Created Time : 六 10/17 02:31:20 2026
+//
+// File Name: login.go
+// Description:
+//	Store or remove the credential of a host
+package workspace
+
+import (
+	"bufio"
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	opworkspace "github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func Login(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one host\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("store credential of", c.Args().First(), ws.Credentials().Name())
+		return plan.Report(c)
+	}
+	credential := &opworkspace.Credential{Host: c.Args().First(), Username: c.String("username")}
+	// Read the username and secret
+	reader := bufio.NewReader(os.Stdin)
+	terminal := log.IsTerminal(os.Stdin)
+	if credential.Username == "" && terminal {
+		fmt.Fprint(os.Stderr, "Username: ")
+		if credential.Username, err = readLine(reader); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to read username, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+	}
+	if terminal {
+		fmt.Fprint(os.Stderr, "Password or token: ")
+		setTerminalEcho(false)
+	}
+	credential.Secret, err = readLine(reader)
+	if terminal {
+		setTerminalEcho(true)
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to read secret, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if credential.Secret == "" {
+		logger.LeveledPrintf(log.LevelError, "Require password or token\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	// Store
+	store := ws.Credentials()
+	if err := store.Set(credential); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to store credential in [%s], error: %s\n", store.Name(), err)
+		return cli.NewExitError("", 1)
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Credential of [%s] stored in [%s]\n", credential.Host, store.Name())
+	// Done
+	return nil
+}
+
+func Logout(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one host\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	store := ws.Credentials()
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plan.Add("remove credential of", c.Args().First(), store.Name())
+		return plan.Report(c)
+	}
+	if err := store.Delete(c.Args().First()); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to remove credential from [%s], error: %s\n", store.Name(), err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+// Serve the stored credentials as the git credential helper, see workspace/credentialhelper.go
+func CredentialHelper(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one action\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if err := ws.ServeCredentialHelper(c.Args().First(), os.Stdin, os.Stdout); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to get credential, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+// Read a line without the tailing new line
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Enable or disable the echo of the terminal of stdin
+func setTerminalEcho(enable bool) {
+	arg := "-echo"
+	if enable {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	cmd.Run()
+}
@@ -0,0 +1,138 @@
+// Author: lipixun
+// Created Time : 六 10/17 00:34:52 2026
+//
+// File Name: main.go
+// Description:
+//
+package workspace
+
+import (
+	opcli "github.com/ops-openlight/openlight/cli"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	LogHeader = "CLI.Workspace"
+)
+
+func GetCommand() []cli.Command {
+	return []cli.Command{
+		{
+			Category:  "Workspace",
+			Name:      "login",
+			Usage:     "Store the credential of the host (e.g. a docker registry) in the OS keychain or the encrypted credential file. The secret is read from stdin",
+			ArgsUsage: "<host>",
+			Action:    Login,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "username, u",
+					Usage: "The username, prompted if not specified and stdin is a terminal",
+				},
+			},
+		},
+		{
+			Category:  "Workspace",
+			Name:      "logout",
+			Usage:     "Remove the credential of the host",
+			ArgsUsage: "<host>",
+			Action:    Logout,
+		},
+		{
+			Category:  "Workspace",
+			Name:      "credential",
+			Usage:     "The git credential helper of the stored credentials, used by the repository fetcher",
+			ArgsUsage: "get|store|erase",
+			Hidden:    true,
+			Action:    CredentialHelper,
+		},
+		{
+			Category: "Workspace",
+			Name:     "plugin",
+			Usage:    "Manage the plugins (CLI extensions) in the workspace",
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List the installed plugins",
+					Action: PluginList,
+				},
+				{
+					Name:      "install",
+					Usage:     "Install or upgrade the plugin from the local directory which contains plugin.yaml",
+					ArgsUsage: "<path>",
+					Action:    PluginInstall,
+				},
+				{
+					Name:      "remove",
+					Usage:     "Remove the plugin",
+					ArgsUsage: "<name>",
+					Action:    PluginRemove,
+				},
+				{
+					Name:            "run",
+					Usage:           "Run the command plugin with the arguments",
+					ArgsUsage:       "<name> [args...]",
+					SkipFlagParsing: true,
+					Action:          PluginRun,
+				},
+			},
+		},
+		{
+			Category: "Workspace",
+			Name:     "clean",
+			Usage:    "Clean the workspace data of the categories and print the freed space of each",
+			Action:   Cleanup,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "runner",
+					Usage: "Remove the data of the stopped runner instances",
+				},
+				cli.BoolFlag{
+					Name:  "builds",
+					Usage: "Remove the build data",
+				},
+				cli.BoolFlag{
+					Name:  "cache",
+					Usage: "Remove the workspace caches, the downloads and the test logs",
+				},
+				cli.BoolFlag{
+					Name:  "repos",
+					Usage: "Remove the fetched remote repositories and their worktrees",
+				},
+				cli.BoolFlag{
+					Name:  "all",
+					Usage: "Clean all categories, including the op log files",
+				},
+				opcli.YesFlag,
+			},
+		},
+		{
+			Category: "Workspace",
+			Name:     "workspace",
+			Usage:    "Manage the workspace data",
+			Subcommands: []cli.Command{
+				{
+					Name:   "status",
+					Usage:  "Show an overview of the workspace: running instances, recent builds, disk usage, repositories and toolchains",
+					Action: Status,
+				},
+				{
+					Name:      "clean",
+					Usage:     "Report the disk usage by category (runner, builds, caches, repos, logs) and prune the categories in arguments",
+					ArgsUsage: "[category...|all]",
+					Action:    Clean,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "older-than",
+							Usage: "Only prune the items not modified in this duration, e.g. 36h, 7d",
+						},
+						cli.StringFlag{
+							Name:  "max-size",
+							Usage: "Prune the oldest items until the category is not larger than this size, e.g. 500M, 2G",
+						},
+						opcli.YesFlag,
+					},
+				},
+			},
+		},
+	}
+}
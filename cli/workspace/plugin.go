@@ -0,0 +1,173 @@
+// Author: lipixun
+// Created Time : 六 10/17 03:41:58 2026
+//
+// File Name: plugin.go
+// Description:
+//	The plugin commands
+package workspace
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	opworkspace "github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func PluginList(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	plugins, err := ws.Plugins("")
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to list plugins, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	for _, plugin := range plugins {
+		var installed string
+		if plugin.Install != nil {
+			installed = fmt.Sprintf(" (installed %s from %s)", plugin.Install.Time.Format(log.DefaultTimeLayout), plugin.Install.Source)
+		}
+		fmt.Printf("%s\t%s\t%s\t%s%s\n", plugin.Name, plugin.Version, plugin.Type, plugin.Description, installed)
+	}
+	// Done
+	return nil
+}
+
+func PluginInstall(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one plugin path\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plugin, err := opworkspace.LoadPlugin(c.Args().First())
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to load plugin, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		plan.Add("install plugin", plugin.Name, fmt.Sprintf("version %s from %s", plugin.Version, c.Args().First()))
+		return plan.Report(c)
+	}
+	plugin, err := ws.InstallPlugin(c.Args().First())
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to install plugin, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	logger.LeveledPrintf(log.LevelSuccess, "Plugin [%s] version [%s] installed\n", plugin.Name, plugin.Version)
+	// Done
+	return nil
+}
+
+func PluginRemove(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() != 1 {
+		logger.LeveledPrintf(log.LevelError, "Require exactly one plugin name\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	if plan := opcli.GetPlan(c); plan.DryRun {
+		plugin, err := ws.GetPlugin(c.Args().First())
+		if err != nil {
+			logger.LeveledPrintf(log.LevelError, "%s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		plan.Add("remove", plugin.Path(), "plugin "+plugin.Name)
+		return plan.Report(c)
+	}
+	if err := ws.RemovePlugin(c.Args().First()); err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to remove plugin, error: %s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
+
+func PluginRun(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if c.NArg() == 0 {
+		logger.LeveledPrintf(log.LevelError, "Require plugin name\n")
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	plugin, err := ws.GetPlugin(c.Args().First())
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "%s\n", err)
+		return cli.NewExitError("", 1)
+	}
+	if plugin.Type != opworkspace.PluginTypeCommand {
+		logger.LeveledPrintf(log.LevelError, "Plugin [%s] is not a command plugin\n", plugin.Name)
+		return cli.NewExitError("", opcli.ExitCodeUsage)
+	}
+	return runCommand(ws, plugin.Name, plugin.CommandPath(), plugin, c.Args().Tail())
+}
+
+// Dispatch the unknown subcommand to the alias (see alias.go) or the external command op-<command> (see
+// workspace.FindExternalCommand), e.g. op deploy --env prod runs op-deploy --env prod
+func CommandNotFound(c *cli.Context, command string) {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		cli.HandleExitCoder(err)
+		cli.OsExiter(1)
+		return
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	if value, ok := ws.GetAlias(command); ok {
+		cli.HandleExitCoder(runAlias(c, ws, command, value))
+		return
+	}
+	path, plugin, err := ws.FindExternalCommand(command)
+	if err != nil {
+		logger.LeveledPrintf(log.LevelError, "Failed to find command [%s], error: %s\n", command, err)
+		cli.OsExiter(1)
+		return
+	}
+	if path == "" {
+		logger.LeveledPrintf(log.LevelError, "Unknown command [%s], neither a builtin command, an alias nor an external command %s%s\n", command, opworkspace.PluginCommandPrefix, command)
+		cli.OsExiter(opcli.ExitCodeUsage)
+		return
+	}
+	if err := opcli.CheckNoDryRun(c, command); err != nil {
+		cli.HandleExitCoder(err)
+		return
+	}
+	logger.LeveledPrintf(log.LevelDebug, "Run external command [%s] of [%s]\n", path, command)
+	// The exit code of the command is returned as the exit code of op
+	cli.HandleExitCoder(runCommand(ws, command, path, plugin, c.Args().Tail()))
+}
+
+// Run the plugin command with the arguments, the workspace is passed by environment variables
+func runCommand(ws *opworkspace.Workspace, name, path string, plugin *opworkspace.Plugin, args []string) error {
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = ws.PluginEnviron(plugin)
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+				return cli.NewExitError("", status.ExitStatus())
+			}
+		}
+		logger.LeveledPrintf(log.LevelError, "Failed to run command [%s], error: %s\n", name, err)
+		return cli.NewExitError("", 1)
+	}
+	// Done
+	return nil
+}
@@ -0,0 +1,208 @@
+// Author: lipixun
+// Created Time : 六 10/17 01:44:52 2026
+//
+// File Name: status.go
+// Description:
+//	The workspace overview
+package workspace
+
+import (
+	"fmt"
+	opcli "github.com/ops-openlight/openlight/cli"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/runner"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/builder"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repoloader"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/util"
+	opworkspace "github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	StatusRecentBuildCount = 5
+)
+
+// The workspace overview in the structured output
+type StatusResult struct {
+	Name           string            `json:"name"`
+	UserWorkdir    string            `json:"userWorkdir"`
+	ProjectWorkdir string            `json:"projectWorkdir"`
+	Instances      []StatusInstance  `json:"instances"`  // The running instances
+	Builds         []StatusBuild     `json:"builds"`     // The recent builds
+	DiskUsage      []StatusDiskUsage `json:"diskUsage"`  // The size of each clean category
+	Repository     *StatusRepository `json:"repository"` // The repository of the project, nil if no sourcecode spec
+	Toolchains     []StatusToolchain `json:"toolchains"`
+}
+
+type StatusInstance struct {
+	ID   string    `json:"id"`
+	Name string    `json:"name"`
+	Pid  int       `json:"pid"`
+	Time time.Time `json:"time"`
+}
+
+type StatusBuild struct {
+	Tag     string    `json:"tag"`
+	Time    time.Time `json:"time"`
+	Status  string    `json:"status"`
+	Targets []string  `json:"targets"` // The target keys with the build numbers, e.g. target#1
+}
+
+type StatusDiskUsage struct {
+	Category string `json:"category"`
+	Size     int64  `json:"size"`
+}
+
+type StatusRepository struct {
+	Uri        string            `json:"uri"`
+	References map[string]string `json:"references"` // The uri to the remote (empty if found by the finder)
+}
+
+type StatusToolchain struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func Status(c *cli.Context) error {
+	ws, err := opcli.GetWorkspace(c)
+	if err != nil {
+		return err
+	}
+	logger := ws.Logger.GetLoggerWithHeader(LogHeader)
+	renderer, err := opcli.GetRenderer(c, "")
+	if err != nil {
+		return err
+	}
+	result := StatusResult{
+		Name:           ws.Name,
+		UserWorkdir:    ws.Dir.User.RootPath(),
+		ProjectWorkdir: ws.Dir.Project.RootPath(),
+		Instances:      []StatusInstance{},
+		Builds:         []StatusBuild{},
+		DiskUsage:      []StatusDiskUsage{},
+		Toolchains:     []StatusToolchain{},
+	}
+	// Running instances
+	if r, err := runner.New(ws); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to create runner, error: %s\n", err)
+	} else if instances, err := r.List(true); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to list runner instances, error: %s\n", err)
+	} else {
+		for _, instance := range instances {
+			result.Instances = append(result.Instances, StatusInstance{ID: instance.ID, Name: instance.Name, Pid: instance.Pid, Time: instance.Time})
+		}
+	}
+	// Recent builds
+	if summaries, err := builder.LoadBuildSummaries(ws); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to load build summaries, error: %s\n", err)
+	} else {
+		for i, summary := range summaries {
+			if i >= StatusRecentBuildCount {
+				break
+			}
+			build := StatusBuild{Tag: summary.Tag, Time: summary.Time, Status: summary.Status(), Targets: []string{}}
+			for _, target := range summary.Targets {
+				build.Targets = append(build.Targets, fmt.Sprintf("%s#%d", target.Target, target.Number))
+			}
+			result.Builds = append(result.Builds, build)
+		}
+	}
+	// Disk usage
+	for _, category := range cleanCategories {
+		items, err := category.Items(ws)
+		if err != nil {
+			logger.LeveledPrintf(log.LevelWarn, "Failed to get the usage of [%s], error: %s\n", category.Name, err)
+			continue
+		}
+		var size int64
+		for _, item := range items {
+			size += item.Size
+		}
+		result.DiskUsage = append(result.DiskUsage, StatusDiskUsage{Category: category.Name, Size: size})
+	}
+	// Repositories
+	filename := filepath.Join(ws.Dir.Project.RootPath(), spec.SpecFileName)
+	if _, err := os.Stat(filename); err == nil {
+		if repoSpec, err := repoloader.LoadRepositorySpecFromFile(filename); err != nil {
+			logger.LeveledPrintf(log.LevelWarn, "Failed to load [%s], error: %s\n", filename, err)
+		} else {
+			result.Repository = &StatusRepository{Uri: repoSpec.Uri, References: make(map[string]string)}
+			for uri, refer := range repoSpec.References {
+				if refer != nil {
+					result.Repository.References[uri] = refer.Remote
+				} else {
+					result.Repository.References[uri] = ""
+				}
+			}
+		}
+	}
+	// Toolchains
+	for _, toolchain := range opworkspace.Toolchains {
+		result.Toolchains = append(result.Toolchains, StatusToolchain{Name: toolchain.Name, Version: opworkspace.GetToolchainVersion(toolchain.Command, toolchain.Args...)})
+	}
+	// Output
+	if renderer.Structured() {
+		if err := renderer.Render(result); err != nil {
+			logger.LeveledPrintf(log.LevelError, "Failed to render workspace status, error: %s\n", err)
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	}
+	printStatus(&result)
+	// Done
+	return nil
+}
+
+// Print the workspace overview in text
+func printStatus(result *StatusResult) {
+	fmt.Println("Workspace:")
+	fmt.Printf("\tName: %s\n", result.Name)
+	fmt.Printf("\tUser workdir: %s\n", result.UserWorkdir)
+	fmt.Printf("\tProject workdir: %s\n", result.ProjectWorkdir)
+	fmt.Println("Running instances:")
+	for _, instance := range result.Instances {
+		fmt.Printf("\t%s %s (pid %d, started %s)\n", instance.ID, instance.Name, instance.Pid, instance.Time.Format(log.DefaultTimeLayout))
+	}
+	if len(result.Instances) == 0 {
+		fmt.Println("\tNone")
+	}
+	fmt.Println("Recent builds:")
+	for _, build := range result.Builds {
+		fmt.Printf("\t%s %s %-9s %s\n", build.Time.Format(log.DefaultTimeLayout), build.Tag, build.Status, strings.Join(build.Targets, " "))
+	}
+	if len(result.Builds) == 0 {
+		fmt.Println("\tNone")
+	}
+	fmt.Println("Disk usage:")
+	for _, usage := range result.DiskUsage {
+		fmt.Printf("\t%-8s %10s\n", usage.Category, util.FormatSize(usage.Size))
+	}
+	fmt.Println("Repositories:")
+	if result.Repository == nil {
+		fmt.Println("\tNo sourcecode spec in project")
+	} else {
+		fmt.Printf("\t%s (current)\n", result.Repository.Uri)
+		var uris []string
+		for uri := range result.Repository.References {
+			uris = append(uris, uri)
+		}
+		sort.Strings(uris)
+		for _, uri := range uris {
+			if remote := result.Repository.References[uri]; remote != "" {
+				fmt.Printf("\t%s --> %s\n", uri, remote)
+			} else {
+				fmt.Printf("\t%s\n", uri)
+			}
+		}
+	}
+	fmt.Println("Toolchains:")
+	for _, toolchain := range result.Toolchains {
+		fmt.Printf("\t%-8s %s\n", toolchain.Name, toolchain.Version)
+	}
+}
@@ -0,0 +1,287 @@
+// Author: lipixun
+// Created Time : 日 10/18 10:36:47 2026
+//
+// File Name: archive.go
+// Description:
+//	The archive artifact, a directory packed into a single file of the formats:
+//		tar.gz 		The gzip compressed tar
+//		tar.zst 	The zstd compressed tar, requires the zstd command (see util/zstd.go)
+//		zip 		The zip of the deflate method
+//	The archive is reproducible, the same content results in the same archive (and the same fingerprint):
+//		- The entries are in the order of the paths (the files are scanned as the directory artifact, the excludes are
+//		  the same glob patterns, see directory.go)
+//		- The modification times are ArchiveModTime, the owners are root and only the permission bits are kept
+//		- No name or time in the gzip header
+//	The archive could be extracted back into a directory artifact (see Extract).
+package artifact
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ArtifactTypeArchive = "archive"
+
+	ArchiveFormatTarGz   = "tar.gz"
+	ArchiveFormatTarZstd = "tar.zst"
+	ArchiveFormatZip     = "zip"
+
+	ArchiveArtifactAttrPath   = "path"
+	ArchiveArtifactAttrFormat = "format"
+	ArchiveArtifactAttrFiles  = "files"
+)
+
+var (
+	ArchiveFormats = []string{ArchiveFormatTarGz, ArchiveFormatTarZstd, ArchiveFormatZip}
+
+	// The modification time of all entries, the earliest time the zip format could represent
+	ArchiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type ArchiveArtifact struct {
+	Info   `json:",inline" yaml:",inline"`
+	Name   string          `json:"name" yaml:"name"`     // The name of this artifact
+	Path   string          `json:"path" yaml:"path"`     // The path of the archive file
+	Format string          `json:"format" yaml:"format"` // The format, one of ArchiveFormat*
+	Files  []DirectoryFile `json:"files" yaml:"files"`   // The files in the archive sorted by the path
+}
+
+// The options to create the archive
+type ArchiveOptions struct {
+	Format   string   // The format, one of ArchiveFormat*, tar.gz by default
+	Level    int      // The compression level, 0 for the default level of the format
+	Excludes []string // The glob patterns of the files to exclude, see directory.go
+}
+
+// Whether the format is supported
+func IsArchiveFormat(format string) bool {
+	for _, f := range ArchiveFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Create an archive artifact by packing the directory
+// Parameters:
+//
+//	name 		The artifact name
+//	root 		The directory to pack
+//	path 		The path of the archive file to create
+//	options 	The archive options
+func CreateArchiveArtifact(name, root, path string, options ArchiveOptions) (*ArchiveArtifact, error) {
+	if options.Format == "" {
+		options.Format = ArchiveFormatTarGz
+	}
+	if !IsArchiveFormat(options.Format) {
+		return nil, errors.New(fmt.Sprintf("Unknown archive format [%s]", options.Format))
+	}
+	directory, err := NewDirectoryArtifact(name, root, options.Excludes)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if options.Format == ArchiveFormatZip {
+		err = writeZip(file, root, directory.Files, options.Level)
+	} else {
+		err = writeTar(file, root, directory.Files, options.Format, options.Level)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, errors.New(fmt.Sprintf("Failed to create archive [%s], error: %s", path, err))
+	}
+	art := &ArchiveArtifact{
+		Info:   Info{CreatedTime: time.Now()},
+		Name:   name,
+		Path:   path,
+		Format: options.Format,
+		Files:  directory.Files,
+	}
+	size, sum, err := hashFile(path)
+	if err != nil {
+		return nil, err
+	}
+	art.Size, art.Fingerprint = size, "sha256:"+sum
+	art.SetOutput(filepath.Base(path), path)
+	// Done
+	return art, nil
+}
+
+func (this *ArchiveArtifact) GetName() string {
+	return this.Name
+}
+
+func (this *ArchiveArtifact) GetType() string {
+	return ArtifactTypeArchive
+}
+
+func (this *ArchiveArtifact) GetAttr(name string) interface{} {
+	switch name {
+	case ArchiveArtifactAttrPath:
+		return this.Path
+	case ArchiveArtifactAttrFormat:
+		return this.Format
+	case ArchiveArtifactAttrFiles:
+		return this.Files
+	default:
+		return nil
+	}
+}
+
+func (this *ArchiveArtifact) GetInfo() *Info {
+	return &this.Info
+}
+
+func (this *ArchiveArtifact) String() string {
+	return fmt.Sprintf("%s: %s --> %s of %d files (%d bytes)", ArtifactTypeArchive, this.Path, this.Format, len(this.Files), this.Size)
+}
+
+// Extract the archive into the directory, returns the directory artifact of the extracted files
+// The content of the extracted files is verified against the files recorded in the archive artifact (the permissions
+// are not, which are subject to the umask)
+func (this *ArchiveArtifact) Extract(dest string) (*DirectoryArtifact, error) {
+	if err := util.ExtractArchive(this.Path, dest); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to extract archive [%s], error: %s", this.Path, err))
+	}
+	directory, err := NewDirectoryArtifact(this.Name, dest, nil)
+	if err != nil {
+		return nil, err
+	}
+	directory.Target = this.Target
+	for name, value := range this.Metadata {
+		directory.SetMetadata(name, value)
+	}
+	content := func(files []DirectoryFile) *DirectoryArtifact {
+		var result DirectoryArtifact
+		for _, file := range files {
+			file.Mode &= os.ModeSymlink
+			result.Files = append(result.Files, file)
+		}
+		return &result
+	}
+	if diff := content(this.Files).Diff(content(directory.Files)); !diff.Empty() {
+		return nil, errors.New(fmt.Sprintf("The extracted files of archive [%s] mismatch:\n%s", this.Path, diff))
+	}
+	return directory, nil
+}
+
+// Write the files as a tar (compressed by the format)
+func writeTar(writer io.Writer, root string, files []DirectoryFile, format string, level int) error {
+	var compressor io.WriteCloser
+	var err error
+	switch format {
+	case ArchiveFormatTarGz:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		compressor, err = gzip.NewWriterLevel(writer, level)
+	case ArchiveFormatTarZstd:
+		compressor, err = util.NewZstdWriter(writer, level)
+	}
+	if err != nil {
+		return err
+	}
+	tarWriter := tar.NewWriter(compressor)
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file.Path))
+		hdr := &tar.Header{
+			Name:    file.Path,
+			Mode:    int64(file.Mode.Perm()),
+			ModTime: ArchiveModTime,
+			Format:  tar.FormatPAX,
+		}
+		if file.Mode&os.ModeSymlink != 0 {
+			if hdr.Linkname, err = os.Readlink(path); err != nil {
+				break
+			}
+			hdr.Typeflag = tar.TypeSymlink
+			err = tarWriter.WriteHeader(hdr)
+		} else {
+			hdr.Typeflag, hdr.Size = tar.TypeReg, file.Size
+			if err = tarWriter.WriteHeader(hdr); err == nil {
+				err = copyFile(tarWriter, path)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if closeErr := tarWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := compressor.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Write the files as a zip
+func writeZip(writer io.Writer, root string, files []DirectoryFile, level int) error {
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	zipWriter := zip.NewWriter(writer)
+	zipWriter.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	})
+	var err error
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file.Path))
+		hdr := &zip.FileHeader{Name: file.Path, Method: zip.Deflate, Modified: ArchiveModTime}
+		var entry io.Writer
+		if file.Mode&os.ModeSymlink != 0 {
+			// The content of a link is the link target
+			var link string
+			if link, err = os.Readlink(path); err != nil {
+				break
+			}
+			hdr.SetMode(os.ModeSymlink | file.Mode.Perm())
+			if entry, err = zipWriter.CreateHeader(hdr); err == nil {
+				_, err = io.WriteString(entry, link)
+			}
+		} else {
+			hdr.SetMode(file.Mode.Perm())
+			if entry, err = zipWriter.CreateHeader(hdr); err == nil {
+				err = copyFile(entry, path)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if closeErr := zipWriter.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Copy the content of the file to the writer
+func copyFile(writer io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(writer, file)
+	return err
+}
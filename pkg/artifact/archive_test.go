@@ -0,0 +1,119 @@
+// Author: lipixun
+// Created Time : 五 10/16 17:48:19 2026
+//
+// File Name: archive_test.go
+// Description:
+//
+package artifact
+
+import (
+	"github.com/ops-openlight/openlight/pkg/util"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	archiveArtifactCases = []struct {
+		Format   string
+		Level    int
+		Excludes []string
+		Files    []string // The expected files
+	}{
+		{Format: "", Files: []string{".git/HEAD", "README.md", "bin/current", "bin/run.sh", "build/main.o", "src/main.go", "src/main.o", "src/vendor/build/lib.go"}},
+		{Format: ArchiveFormatTarGz, Level: 9, Excludes: []string{".git", "*.o"}, Files: []string{"README.md", "bin/current", "bin/run.sh", "src/main.go", "src/vendor/build/lib.go"}},
+		{Format: ArchiveFormatTarZstd, Excludes: []string{".git", "build/"}, Files: []string{"README.md", "bin/current", "bin/run.sh", "src/main.go", "src/main.o", "src/vendor/build/lib.go"}},
+		{Format: ArchiveFormatZip, Level: 1, Excludes: []string{".git"}, Files: []string{"README.md", "bin/current", "bin/run.sh", "build/main.o", "src/main.go", "src/main.o", "src/vendor/build/lib.go"}},
+	}
+)
+
+func TestArchiveArtifact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	writeTestFiles(t, root, directoryFiles)
+	for _, tCase := range archiveArtifactCases {
+		if tCase.Format == ArchiveFormatTarZstd {
+			if _, err := exec.LookPath(util.ZstdCommand); err != nil {
+				t.Logf("Skip format [%s], %s is not installed", tCase.Format, util.ZstdCommand)
+				continue
+			}
+		}
+		name := tCase.Format
+		if name == "" {
+			name = "default"
+		}
+		options := ArchiveOptions{Format: tCase.Format, Level: tCase.Level, Excludes: tCase.Excludes}
+		path := filepath.Join(dir, "archives", name)
+		art, err := CreateArchiveArtifact("test", root, path, options)
+		if err != nil {
+			t.Errorf("Failed to create the archive of format [%s], error: %s", tCase.Format, err)
+			continue
+		}
+		if tCase.Format == "" && art.Format != ArchiveFormatTarGz {
+			t.Errorf("Incorrect default format. Expect [%s] Actual [%s]", ArchiveFormatTarGz, art.Format)
+		}
+		if paths := getDirectoryPaths(art.Files); !reflect.DeepEqual(paths, tCase.Files) {
+			t.Errorf("Incorrect files of format [%s]. Expect %v Actual %v", tCase.Format, tCase.Files, paths)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, sum, _ := hashFile(path); art.Fingerprint != "sha256:"+sum || art.Size != info.Size() || art.Outputs[filepath.Base(path)] != path {
+			t.Errorf("Incorrect fingerprint of format [%s]. Expect [sha256:%s] Actual [%s]", tCase.Format, sum, art.Fingerprint)
+		}
+		// Reproducible: the same content of other modification times results in the same archive
+		later := time.Now().Add(time.Hour)
+		for _, file := range directoryFiles {
+			if !file.Link {
+				if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(file.Path)), later, later); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		again, err := CreateArchiveArtifact("test", root, path+".again", options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.Fingerprint != art.Fingerprint {
+			t.Errorf("The archive of format [%s] is not reproducible. Expect [%s] Actual [%s]", tCase.Format, art.Fingerprint, again.Fingerprint)
+		}
+		// Extracted back to the same files
+		art.Target = "//test:target"
+		art.SetMetadata("version", "1.0")
+		directory, err := art.Extract(filepath.Join(dir, "extracted", name))
+		if err != nil {
+			t.Errorf("Failed to extract the archive of format [%s], error: %s", tCase.Format, err)
+			continue
+		}
+		if paths := getDirectoryPaths(directory.Files); !reflect.DeepEqual(paths, tCase.Files) {
+			t.Errorf("Incorrect extracted files of format [%s]. Expect %v Actual %v", tCase.Format, tCase.Files, paths)
+		}
+		if directory.Target != art.Target || directory.Metadata["version"] != "1.0" {
+			t.Errorf("Incorrect extracted info of format [%s]. Actual target [%s] metadata %v", tCase.Format, directory.Target, directory.Metadata)
+		}
+		for j, file := range directory.Files {
+			if expect := art.Files[j]; file.Sha256 != expect.Sha256 || file.Mode&os.ModeSymlink != expect.Mode&os.ModeSymlink {
+				t.Errorf("Incorrect extracted file [%s] of format [%s]. Expect %+v Actual %+v", file.Path, tCase.Format, expect, file)
+			}
+		}
+		// The recorded files mismatch the archive
+		art.Files = append(art.Files, DirectoryFile{Path: "missing", Size: 1, Sha256: "0"})
+		if _, err := art.Extract(filepath.Join(dir, "mismatched", name)); err == nil || !strings.Contains(err.Error(), "- missing") {
+			t.Errorf("Incorrect error of the mismatched archive of format [%s]. Actual [%v]", tCase.Format, err)
+		}
+	}
+	// Unknown format
+	if _, err := CreateArchiveArtifact("test", root, filepath.Join(dir, "unknown"), ArchiveOptions{Format: "rar"}); err == nil || !strings.Contains(err.Error(), "Unknown archive format") {
+		t.Errorf("Incorrect error of the unknown format. Actual [%v]", err)
+	}
+}
@@ -6,9 +6,41 @@
 //	The artifact
 package artifact
 
+import (
+	"time"
+)
+
 type Artifact interface {
 	GetName() string                 // Get the name
 	GetType() string                 // The the type
 	GetAttr(name string) interface{} // Get the attribute
+	GetInfo() *Info                  // Get the info
 	String() string                  // Get the string representation
 }
+
+// The info of an artifact, shared by all types of artifacts and serialized into the manifest (see Manifest)
+type Info struct {
+	Target      string            `json:"target,omitempty" yaml:"target,omitempty"`           // The key of the target produced this artifact
+	CreatedTime time.Time         `json:"createdTime" yaml:"createdTime"`                     // The time when the artifact was created
+	Size        int64             `json:"size" yaml:"size"`                                   // The size in bytes, 0 if unknown
+	Fingerprint string            `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"` // The fingerprint of the content, in the form of algorithm:hex, e.g. sha256:...
+	Outputs     map[string]string `json:"outputs,omitempty" yaml:"outputs,omitempty"`         // The named outputs, e.g. the files to their paths, the image to its uri
+	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`       // The arbitrary metadata
+	Provenance  *Provenance       `json:"provenance,omitempty" yaml:"provenance,omitempty"`   // How the artifact was produced, see provenance.go
+}
+
+// Set a metadata
+func (this *Info) SetMetadata(name, value string) {
+	if this.Metadata == nil {
+		this.Metadata = make(map[string]string)
+	}
+	this.Metadata[name] = value
+}
+
+// Set a named output
+func (this *Info) SetOutput(name, value string) {
+	if this.Outputs == nil {
+		this.Outputs = make(map[string]string)
+	}
+	this.Outputs[name] = value
+}
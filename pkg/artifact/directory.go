@@ -0,0 +1,238 @@
+// Author: lipixun
+// Created Time : 日 10/18 09:48:21 2026
+//
+// File Name: directory.go
+// Description:
+//	The directory artifact, records every file in the directory with its size, mode and sha256, so the content of
+//	the artifact could be compared (see Diff) or verified against the directory later (see Verify)
+//
+//	The excludes are the glob patterns (see path.Match) of the relative paths (separated by /) to skip:
+//		- A pattern without / matches the name of the file or directory at any depth, e.g. *.pyc, __pycache__
+//		- A pattern with / matches the relative path from the root, e.g. build/tmp, logs/*.log
+//	An excluded directory is skipped with all its content. Directories are not recorded, so empty directories are
+//	ignored. Symbol links are recorded as links (not followed), the hash of a link is the hash of its target.
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ArtifactTypeDirectory = "directory"
+
+	DirectoryArtifactAttrPath     = "path"
+	DirectoryArtifactAttrFiles    = "files"
+	DirectoryArtifactAttrExcludes = "excludes"
+)
+
+// A file in the directory artifact
+type DirectoryFile struct {
+	Path   string      `json:"path" yaml:"path"`     // The relative path separated by /
+	Size   int64       `json:"size" yaml:"size"`     // The size in bytes
+	Mode   os.FileMode `json:"mode" yaml:"mode"`     // The file mode
+	Sha256 string      `json:"sha256" yaml:"sha256"` // The sha256 in hex of the content (or the link target)
+}
+
+type DirectoryArtifact struct {
+	Info     `json:",inline" yaml:",inline"`
+	Name     string          `json:"name" yaml:"name"`         // The name of this artifact
+	Path     string          `json:"path" yaml:"path"`         // The root path of the directory
+	Excludes []string        `json:"excludes" yaml:"excludes"` // The exclusion glob patterns
+	Files    []DirectoryFile `json:"files" yaml:"files"`       // The files sorted by the path
+}
+
+// The difference between two directory artifacts, the relative paths of the files
+type DirectoryDiff struct {
+	Added   []string `json:"added" yaml:"added"`
+	Removed []string `json:"removed" yaml:"removed"`
+	Changed []string `json:"changed" yaml:"changed"` // The size, mode or content changed
+}
+
+// Create a directory artifact by scanning the directory
+func NewDirectoryArtifact(name, root string, excludes []string) (*DirectoryArtifact, error) {
+	for _, pattern := range excludes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid exclude pattern [%s], error: %s", pattern, err))
+		}
+	}
+	files, err := scanDirectory(root, excludes)
+	if err != nil {
+		return nil, err
+	}
+	art := &DirectoryArtifact{
+		Info:     Info{CreatedTime: time.Now()},
+		Name:     name,
+		Path:     root,
+		Excludes: excludes,
+		Files:    files,
+	}
+	// The size, fingerprint and outputs
+	hash := sha256.New()
+	for _, file := range files {
+		art.Size += file.Size
+		fmt.Fprintf(hash, "%s\x00%o\x00%s\n", file.Path, uint32(file.Mode), file.Sha256)
+		art.SetOutput(file.Path, filepath.Join(root, filepath.FromSlash(file.Path)))
+	}
+	art.Fingerprint = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	// Done
+	return art, nil
+}
+
+func (this *DirectoryArtifact) GetName() string {
+	return this.Name
+}
+
+func (this *DirectoryArtifact) GetType() string {
+	return ArtifactTypeDirectory
+}
+
+func (this *DirectoryArtifact) GetAttr(name string) interface{} {
+	switch name {
+	case DirectoryArtifactAttrPath:
+		return this.Path
+	case DirectoryArtifactAttrFiles:
+		return this.Files
+	case DirectoryArtifactAttrExcludes:
+		return this.Excludes
+	default:
+		return nil
+	}
+}
+
+func (this *DirectoryArtifact) GetInfo() *Info {
+	return &this.Info
+}
+
+func (this *DirectoryArtifact) String() string {
+	return fmt.Sprintf("%s: %s --> %d files (%d bytes)", ArtifactTypeDirectory, this.Path, len(this.Files), this.Size)
+}
+
+// Diff the files from this artifact to the other
+func (this *DirectoryArtifact) Diff(other *DirectoryArtifact) DirectoryDiff {
+	var diff DirectoryDiff
+	files := make(map[string]DirectoryFile)
+	for _, file := range this.Files {
+		files[file.Path] = file
+	}
+	for _, file := range other.Files {
+		if original, ok := files[file.Path]; !ok {
+			diff.Added = append(diff.Added, file.Path)
+		} else if original != file {
+			diff.Changed = append(diff.Changed, file.Path)
+		}
+		delete(files, file.Path)
+	}
+	for path := range files {
+		diff.Removed = append(diff.Removed, path)
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// Verify the directory against the recorded files, returns the changes of the directory since the artifact created
+func (this *DirectoryArtifact) Verify() (DirectoryDiff, error) {
+	current, err := NewDirectoryArtifact(this.Name, this.Path, this.Excludes)
+	if err != nil {
+		return DirectoryDiff{}, err
+	}
+	return this.Diff(current), nil
+}
+
+// Whether nothing changed
+func (this DirectoryDiff) Empty() bool {
+	return len(this.Added) == 0 && len(this.Removed) == 0 && len(this.Changed) == 0
+}
+
+func (this DirectoryDiff) String() string {
+	var lines []string
+	for _, path := range this.Added {
+		lines = append(lines, "+ "+path)
+	}
+	for _, path := range this.Removed {
+		lines = append(lines, "- "+path)
+	}
+	for _, path := range this.Changed {
+		lines = append(lines, "~ "+path)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Scan the files in the directory, sorted by the path
+func scanDirectory(root string, excludes []string) ([]DirectoryFile, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New(fmt.Sprintf("Path [%s] is not a directory", root))
+	}
+	var files []DirectoryFile
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if isExcluded(rel, excludes) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file := DirectoryFile{Path: rel, Size: info.Size(), Mode: info.Mode()}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256([]byte(target))
+			file.Sha256 = hex.EncodeToString(sum[:])
+		} else if info.Mode().IsRegular() {
+			if _, file.Sha256, err = hashFile(p); err != nil {
+				return err
+			}
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	// Done
+	return files, nil
+}
+
+// Whether the relative path (separated by /) is excluded by the patterns
+func isExcluded(rel string, excludes []string) bool {
+	for _, pattern := range excludes {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if matched, _ := path.Match(strings.TrimSuffix(pattern, "/"), name); matched {
+			return true
+		}
+	}
+	return false
+}
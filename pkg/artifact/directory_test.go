@@ -0,0 +1,258 @@
+// Author: lipixun
+// Created Time : 五 10/16 17:12:36 2026
+//
+// File Name: directory_test.go
+// Description:
+//
+package artifact
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A file to create for the tests
+type testFile struct {
+	Path    string // The relative path separated by /
+	Content string // The content, or the link target if Link is true
+	Mode    os.FileMode
+	Link    bool
+}
+
+var (
+	directoryFiles = []testFile{
+		{Path: "README.md", Content: "readme\n", Mode: 0644},
+		{Path: "bin/run.sh", Content: "#!/bin/sh\n", Mode: 0755},
+		{Path: "bin/current", Content: "run.sh", Link: true},
+		{Path: "build/main.o", Content: "object", Mode: 0644},
+		{Path: "src/main.go", Content: "package main\n", Mode: 0644},
+		{Path: "src/main.o", Content: "object", Mode: 0644},
+		{Path: "src/vendor/build/lib.go", Content: "package lib\n", Mode: 0644},
+		{Path: ".git/HEAD", Content: "ref: refs/heads/master\n", Mode: 0644},
+	}
+
+	isExcludedCases = []struct {
+		Path     string
+		Excludes []string
+		Excluded bool
+	}{
+		{Path: "src/main.go"},
+		{Path: "src/main.go", Excludes: []string{"*.o"}},
+		{Path: "src/main.o", Excludes: []string{"*.o"}, Excluded: true},
+		{Path: "main.o", Excludes: []string{"*.o"}, Excluded: true},
+		{Path: ".git", Excludes: []string{".git"}, Excluded: true},
+		{Path: "src/.git", Excludes: []string{".git"}, Excluded: true},
+		{Path: "build", Excludes: []string{"build/"}, Excluded: true},
+		{Path: "src/vendor/build", Excludes: []string{"build/"}},
+		{Path: "src/vendor/build", Excludes: []string{"src/*/build"}, Excluded: true},
+		{Path: "src/vendor", Excludes: []string{"src/*/build"}},
+		{Path: "src/main.go", Excludes: []string{"*.o", "src/main.*"}, Excluded: true},
+	}
+
+	directoryArtifactCases = []struct {
+		Name     string
+		Excludes []string
+		Files    []string // The expected files
+		Error    string
+	}{
+		{
+			Name:  "all",
+			Files: []string{".git/HEAD", "README.md", "bin/current", "bin/run.sh", "build/main.o", "src/main.go", "src/main.o", "src/vendor/build/lib.go"},
+		},
+		{
+			Name:     "excludes",
+			Excludes: []string{".git", "*.o", "build/"},
+			Files:    []string{"README.md", "bin/current", "bin/run.sh", "src/main.go", "src/vendor/build/lib.go"},
+		},
+		{
+			Name:     "excludes by path",
+			Excludes: []string{"src/*/build", "bin"},
+			Files:    []string{".git/HEAD", "README.md", "build/main.o", "src/main.go", "src/main.o"},
+		},
+		{
+			Name:     "invalid pattern",
+			Excludes: []string{"[a-"},
+			Error:    "Invalid exclude pattern",
+		},
+	}
+)
+
+// Create the files under the root
+func writeTestFiles(t *testing.T, root string, files []testFile) {
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if file.Link {
+			if err := os.Symlink(file.Content, path); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(path, []byte(file.Content), file.Mode); err != nil {
+			t.Fatal(err)
+		}
+		// Not subject to the umask
+		if err := os.Chmod(path, file.Mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Get the paths of the directory files
+func getDirectoryPaths(files []DirectoryFile) []string {
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func TestIsExcluded(t *testing.T) {
+	for _, tCase := range isExcludedCases {
+		if excluded := isExcluded(tCase.Path, tCase.Excludes); excluded != tCase.Excluded {
+			t.Errorf("Incorrect exclusion of [%s] by %v. Expect %v Actual %v", tCase.Path, tCase.Excludes, tCase.Excluded, excluded)
+		}
+	}
+}
+
+func TestNewDirectoryArtifact(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeTestFiles(t, root, directoryFiles)
+	for _, tCase := range directoryArtifactCases {
+		art, err := NewDirectoryArtifact("test", root, tCase.Excludes)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to create the directory artifact of case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		if paths := getDirectoryPaths(art.Files); !reflect.DeepEqual(paths, tCase.Files) {
+			t.Errorf("Incorrect files of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Files, paths)
+		}
+		var size int64
+		for _, file := range art.Files {
+			size += file.Size
+			if art.Outputs[file.Path] != filepath.Join(root, filepath.FromSlash(file.Path)) {
+				t.Errorf("Incorrect output of [%s] of case [%s]. Actual [%s]", file.Path, tCase.Name, art.Outputs[file.Path])
+			}
+		}
+		if art.Size != size || len(art.Outputs) != len(art.Files) {
+			t.Errorf("Incorrect size of case [%s]. Expect %d Actual %d", tCase.Name, size, art.Size)
+		}
+		// The same content results in the same fingerprint
+		again, err := NewDirectoryArtifact("test", root, tCase.Excludes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(art.Fingerprint, "sha256:") || again.Fingerprint != art.Fingerprint {
+			t.Errorf("Incorrect fingerprint of case [%s]. Expect [%s] Actual [%s]", tCase.Name, art.Fingerprint, again.Fingerprint)
+		}
+	}
+	// Not a directory
+	if _, err := NewDirectoryArtifact("test", filepath.Join(root, "README.md"), nil); err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Errorf("Incorrect error of the file path. Actual [%v]", err)
+	}
+}
+
+func TestDirectoryArtifactVerify(t *testing.T) {
+	for _, tCase := range []struct {
+		Name   string
+		Change func(root string) error
+		Diff   DirectoryDiff
+	}{
+		{Name: "unchanged", Change: func(root string) error { return nil }},
+		{
+			Name:   "added",
+			Change: func(root string) error { return ioutil.WriteFile(filepath.Join(root, "src", "util.go"), nil, 0644) },
+			Diff:   DirectoryDiff{Added: []string{"src/util.go"}},
+		},
+		{
+			Name:   "removed",
+			Change: func(root string) error { return os.Remove(filepath.Join(root, "README.md")) },
+			Diff:   DirectoryDiff{Removed: []string{"README.md"}},
+		},
+		{
+			Name: "content",
+			Change: func(root string) error {
+				return ioutil.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package lib\n"), 0644)
+			},
+			Diff: DirectoryDiff{Changed: []string{"src/main.go"}},
+		},
+		{
+			Name:   "mode",
+			Change: func(root string) error { return os.Chmod(filepath.Join(root, "bin", "run.sh"), 0644) },
+			Diff:   DirectoryDiff{Changed: []string{"bin/run.sh"}},
+		},
+		{
+			Name: "link",
+			Change: func(root string) error {
+				path := filepath.Join(root, "bin", "current")
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+				return os.Symlink("../README.md", path)
+			},
+			Diff: DirectoryDiff{Changed: []string{"bin/current"}},
+		},
+		{
+			Name: "excluded",
+			Change: func(root string) error {
+				return ioutil.WriteFile(filepath.Join(root, "src", "main.o"), []byte("changed"), 0644)
+			},
+		},
+	} {
+		func() {
+			root, err := ioutil.TempDir("", "artifact-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			writeTestFiles(t, root, directoryFiles)
+			art, err := NewDirectoryArtifact("test", root, []string{"*.o"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tCase.Change(root); err != nil {
+				t.Fatal(err)
+			}
+			diff, err := art.Verify()
+			if err != nil {
+				t.Fatalf("Failed to verify case [%s], error: %s", tCase.Name, err)
+			}
+			if !reflect.DeepEqual(diff, tCase.Diff) {
+				t.Errorf("Incorrect diff of case [%s]. Expect %+v Actual %+v", tCase.Name, tCase.Diff, diff)
+			}
+			current, err := NewDirectoryArtifact("test", root, []string{"*.o"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := current.Fingerprint != art.Fingerprint; changed != !tCase.Diff.Empty() {
+				t.Errorf("Incorrect fingerprint of case [%s]. Expect changed %v Actual %v", tCase.Name, !tCase.Diff.Empty(), changed)
+			}
+		}()
+	}
+}
+
+func TestDirectoryDiffString(t *testing.T) {
+	diff := DirectoryDiff{Added: []string{"a", "b"}, Removed: []string{"c"}, Changed: []string{"d"}}
+	if expect := "+ a\n+ b\n- c\n~ d"; diff.String() != expect {
+		t.Errorf("Incorrect diff string. Expect [%s] Actual [%s]", expect, diff.String())
+	}
+	if diff.Empty() || !(DirectoryDiff{}).Empty() {
+		t.Errorf("Incorrect empty diff")
+	}
+}
@@ -8,6 +8,7 @@ package artifact
 
 import (
 	"fmt"
+	"time"
 )
 
 const (
@@ -17,9 +18,12 @@ const (
 	DockerArtifactAttrRepository = "repository"
 	DockerArtifactAttrImage      = "image"
 	DockerArtifactAttrTag        = "tag"
+
+	DockerArtifactOutputImage = "image" // The output of the image uri
 )
 
 type DockerArtifact struct {
+	Info       `json:",inline" yaml:",inline"`
 	Name       string `json:"name" yaml:"name"`         // The name of this artifact
 	Fullname   string `json:"fullname" yaml:"fullname"` // The fullname of the image
 	Repository string `json:"repository" yaml:"repository"`
@@ -29,6 +33,7 @@ type DockerArtifact struct {
 
 func NewDockerArtifact(name, fullname, repository, image, tag string) *DockerArtifact {
 	return &DockerArtifact{
+		Info:       Info{CreatedTime: time.Now(), Outputs: map[string]string{DockerArtifactOutputImage: fullname}},
 		Name:       name,
 		Fullname:   fullname,
 		Repository: repository,
@@ -60,6 +65,10 @@ func (this *DockerArtifact) GetAttr(name string) interface{} {
 	}
 }
 
+func (this *DockerArtifact) GetInfo() *Info {
+	return &this.Info
+}
+
 func (this *DockerArtifact) String() string {
 	return fmt.Sprintf("%s: %s", ArtifactTypeDocker, this.Fullname)
 }
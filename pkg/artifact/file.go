@@ -9,13 +9,18 @@ package artifact
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/ops-openlight/openlight/pkg/util"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"time"
 )
 
 const (
@@ -27,6 +32,7 @@ const (
 )
 
 type FileArtifact struct {
+	Info       `json:",inline" yaml:",inline"`
 	Name       string   `json:"name" yaml:"name"`             // The name of this artifact
 	Path       string   `json:"path" yaml:"path"`             // The root path this file artifact. This path is the root directory path or the file path itself if the artifact is not compressed otherwise this path is the compressed file path
 	Files      []string `json:"files" yaml:"files"`           // The files in the artifact, the relative file path. If not empty the path field will be the root directory of the artifact otherwise (this field is nil or has 0 length) means this artifact only contains a single file and the path field is the path of the file
@@ -35,6 +41,7 @@ type FileArtifact struct {
 
 func NewFileArtifact(name, path string, files []string, compressed bool) *FileArtifact {
 	return &FileArtifact{
+		Info:       Info{CreatedTime: time.Now()},
 		Name:       name,
 		Path:       path,
 		Files:      files,
@@ -43,7 +50,7 @@ func NewFileArtifact(name, path string, files []string, compressed bool) *FileAr
 }
 
 func NewSingleFileArtifact(name, path string) *FileArtifact {
-	return &FileArtifact{Name: name, Path: path}
+	return &FileArtifact{Info: Info{CreatedTime: time.Now()}, Name: name, Path: path}
 }
 
 func (this *FileArtifact) GetName() string {
@@ -67,6 +74,42 @@ func (this *FileArtifact) GetAttr(name string) interface{} {
 	}
 }
 
+func (this *FileArtifact) GetInfo() *Info {
+	return &this.Info
+}
+
+// Stat the files of the artifact, set the size, the fingerprint and the outputs (the files to their paths)
+// The fingerprint is the sha256 of the file if the artifact is a single file or compressed, otherwise the sha256 of
+// the relative paths and the sha256 of the files in the order of the paths
+func (this *FileArtifact) Stat() error {
+	if len(this.Files) == 0 || this.Compressed {
+		size, sum, err := hashFile(this.Path)
+		if err != nil {
+			return err
+		}
+		this.Size, this.Fingerprint = size, "sha256:"+sum
+		this.SetOutput(filepath.Base(this.Path), this.Path)
+		return nil
+	}
+	files := append([]string(nil), this.Files...)
+	sort.Strings(files)
+	var total int64
+	hash := sha256.New()
+	for _, file := range files {
+		path := filepath.Join(this.Path, file)
+		size, sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		total += size
+		fmt.Fprintf(hash, "%s\x00%s\n", filepath.ToSlash(file), sum)
+		this.SetOutput(filepath.ToSlash(file), path)
+	}
+	this.Size, this.Fingerprint = total, "sha256:"+hex.EncodeToString(hash.Sum(nil))
+	// Done
+	return nil
+}
+
 func (this *FileArtifact) String() string {
 	if len(this.Files) == 0 {
 		return fmt.Sprintf("%s: %s --> Single file itself", ArtifactTypeFile, this.Path)
@@ -81,8 +124,8 @@ func (this *FileArtifact) String() string {
 type CollectFileArtifactOptions struct {
 	Recursive     bool           // Recursive collect or not
 	FollowLink    bool           // Follow the symbol link or not. It's dangerous to enable this feature and thus not encouraged
-	Includes      *regexp.Regexp // The regexp to test the files to include
-	Excludes      *regexp.Regexp // The regexp to test the files to exclude
+	Includes      *regexp.Regexp // The regexp to test the relative paths (separated by /) of the files to include
+	Excludes      *regexp.Regexp // The regexp to test the relative paths (separated by /) of the files to exclude
 	CompressLevel int            // The compress level when doing compress collect
 }
 
@@ -103,7 +146,9 @@ func CollectFileArtifact(name, path string, options CollectFileArtifactOptions)
 	files, err := listPath(path, &options)
 	if err != nil && err != pathIsAFileError {
 		return nil, err
-	} else if len(files) == 0 {
+	}
+	files = filterFiles(files, &options)
+	if len(files) == 0 {
 		// No file collected
 		return nil, nil
 	}
@@ -146,6 +191,7 @@ func CompressCollectFileArtifact(name, path, pkg string, options CollectFileArti
 		files = append(files, filepath.Base(path))
 	} else {
 		// Add files to tar
+		files = filterFiles(files, &options)
 		if len(files) == 0 {
 			// No files to compress
 			return nil, nil
@@ -155,13 +201,46 @@ func CompressCollectFileArtifact(name, path, pkg string, options CollectFileArti
 			if err := util.TarWriteFile(filepath.Join(path, file), file, tarWriter); err != nil {
 				return nil, err
 			}
-			files = append(files, file)
 		}
 	}
 	// Done
 	return NewFileArtifact(name, pkg, files, true), nil
 }
 
+// Get the size and the sha256 (in hex) of the file
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Filter the listed files by the includes or excludes of the options
+func filterFiles(files []string, options *CollectFileArtifactOptions) []string {
+	if options.Includes == nil && options.Excludes == nil {
+		return files
+	}
+	var filtered []string
+	for _, file := range files {
+		name := filepath.ToSlash(file)
+		if options.Includes != nil && !options.Includes.MatchString(name) {
+			continue
+		}
+		if options.Excludes != nil && options.Excludes.MatchString(name) {
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
 var (
 	pathIsAFileError = errors.New("Path is a file")
 )
@@ -0,0 +1,261 @@
+// Author: lipixun
+// Created Time : 五 10/16 17:25:03 2026
+//
+// File Name: file_test.go
+// Description:
+//
+package artifact
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var (
+	collectFiles = []testFile{
+		{Path: "app", Content: "binary", Mode: 0755},
+		{Path: "app.debug", Content: "symbols", Mode: 0644},
+		{Path: "lib/a.so", Content: "a", Mode: 0644},
+		{Path: "lib/b.so", Content: "b", Mode: 0644},
+		{Path: "lib/b.txt", Content: "readme of b", Mode: 0644},
+	}
+
+	collectFileArtifactCases = []struct {
+		Name    string
+		Options CollectFileArtifactOptions
+		Files   []string // The expected files, nil if no artifact
+		Error   string
+	}{
+		{Name: "top level", Files: []string{"app", "app.debug"}},
+		{Name: "recursive", Options: CollectFileArtifactOptions{Recursive: true}, Files: []string{"app", "app.debug", "lib/a.so", "lib/b.so", "lib/b.txt"}},
+		{Name: "includes", Options: CollectFileArtifactOptions{Recursive: true, Includes: regexp.MustCompile(`\.so$`)}, Files: []string{"lib/a.so", "lib/b.so"}},
+		{Name: "includes by path", Options: CollectFileArtifactOptions{Recursive: true, Includes: regexp.MustCompile(`^lib/b\.`)}, Files: []string{"lib/b.so", "lib/b.txt"}},
+		{Name: "excludes", Options: CollectFileArtifactOptions{Recursive: true, Excludes: regexp.MustCompile(`\.(debug|txt)$`)}, Files: []string{"app", "lib/a.so", "lib/b.so"}},
+		{Name: "nothing included", Options: CollectFileArtifactOptions{Recursive: true, Includes: regexp.MustCompile(`\.dll$`)}},
+		{
+			Name:    "includes and excludes",
+			Options: CollectFileArtifactOptions{Includes: regexp.MustCompile(`\.so$`), Excludes: regexp.MustCompile(`\.txt$`)},
+			Error:   "Cannot both specify includes and excludes",
+		},
+	}
+
+	fileFingerprintCases = []struct {
+		Name   string
+		Change func(root string) error
+		Files  []string // The files of the changed artifact, the same files if nil
+		Equal  bool
+	}{
+		{Name: "unchanged", Change: func(root string) error { return nil }, Equal: true},
+		{Name: "order", Change: func(root string) error { return nil }, Files: []string{"lib/b.so", "app", "lib/a.so"}, Equal: true},
+		{Name: "mode", Change: func(root string) error { return os.Chmod(filepath.Join(root, "app"), 0644) }, Equal: true},
+		{Name: "content", Change: func(root string) error {
+			return ioutil.WriteFile(filepath.Join(root, "lib", "a.so"), []byte("A"), 0644)
+		}},
+		{Name: "swapped", Change: func(root string) error {
+			if err := ioutil.WriteFile(filepath.Join(root, "lib", "a.so"), []byte("b"), 0644); err != nil {
+				return err
+			}
+			return ioutil.WriteFile(filepath.Join(root, "lib", "b.so"), []byte("a"), 0644)
+		}},
+		{Name: "removed", Change: func(root string) error { return nil }, Files: []string{"app", "lib/a.so"}},
+	}
+)
+
+// Get the prefixed sha256 of the content
+func getFingerprint(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Read the names and contents of the entries in the tar.gz file
+func readTarGz(t *testing.T, path string) ([]string, map[string]string) {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	var names []string
+	contents := make(map[string]string)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		contents[hdr.Name] = string(data)
+	}
+	return names, contents
+}
+
+func TestCollectFileArtifact(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeTestFiles(t, root, collectFiles)
+	for _, tCase := range collectFileArtifactCases {
+		art, err := CollectFileArtifact("test", root, tCase.Options)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to collect case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		if tCase.Files == nil {
+			if art != nil {
+				t.Errorf("Incorrect artifact of case [%s]. Expect nil Actual %v", tCase.Name, art)
+			}
+			continue
+		}
+		files := append([]string(nil), art.Files...)
+		for i := range files {
+			files[i] = filepath.ToSlash(files[i])
+		}
+		sort.Strings(files)
+		if !reflect.DeepEqual(files, tCase.Files) || art.Compressed || art.Path != root {
+			t.Errorf("Incorrect artifact of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Files, art)
+		}
+	}
+	// Nothing to collect
+	empty := filepath.Join(root, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if art, err := CollectFileArtifact("test", empty, NewDefaultCollectFileArtifactOptions()); art != nil || err != nil {
+		t.Errorf("Incorrect artifact of the empty directory. Expect nil Actual %v error [%v]", art, err)
+	}
+}
+
+func TestFileArtifactStat(t *testing.T) {
+	// A single file
+	root, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeTestFiles(t, root, collectFiles)
+	single := NewSingleFileArtifact("test", filepath.Join(root, "app"))
+	if err := single.Stat(); err != nil {
+		t.Fatal(err)
+	}
+	if single.Size != 6 || single.Fingerprint != getFingerprint("binary") || single.Outputs["app"] != single.Path {
+		t.Errorf("Incorrect stat of the single file. Expect [%s] Actual [%s] size %d outputs %v", getFingerprint("binary"), single.Fingerprint, single.Size, single.Outputs)
+	}
+	// The collected files
+	for _, tCase := range fileFingerprintCases {
+		func() {
+			root, err := ioutil.TempDir("", "artifact-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			writeTestFiles(t, root, collectFiles)
+			files := []string{"app", "lib/a.so", "lib/b.so"}
+			art := NewFileArtifact("test", root, files, false)
+			if err := art.Stat(); err != nil {
+				t.Fatal(err)
+			}
+			if art.Size != 8 || len(art.Outputs) != 3 || art.Outputs["lib/a.so"] != filepath.Join(root, "lib", "a.so") {
+				t.Errorf("Incorrect stat of case [%s]. Actual size %d outputs %v", tCase.Name, art.Size, art.Outputs)
+			}
+			if err := tCase.Change(root); err != nil {
+				t.Fatal(err)
+			}
+			if tCase.Files != nil {
+				files = tCase.Files
+			}
+			changed := NewFileArtifact("test", root, files, false)
+			if err := changed.Stat(); err != nil {
+				t.Fatal(err)
+			}
+			if equal := changed.Fingerprint == art.Fingerprint; equal != tCase.Equal {
+				t.Errorf("Incorrect fingerprint of case [%s]. Expect equal %v Actual [%s] and [%s]", tCase.Name, tCase.Equal, art.Fingerprint, changed.Fingerprint)
+			}
+		}()
+	}
+	// The missing file
+	if err := NewFileArtifact("test", root, []string{"missing"}, false).Stat(); err == nil {
+		t.Errorf("Should fail to stat the missing file")
+	}
+}
+
+func TestCompressCollectFileArtifact(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	writeTestFiles(t, source, collectFiles)
+	for _, tCase := range []struct {
+		Name     string
+		Path     string
+		Excludes string
+		Files    []string
+	}{
+		{Name: "directory", Path: source, Files: []string{"app", "app.debug", "lib/a.so", "lib/b.so", "lib/b.txt"}},
+		{Name: "excludes", Path: source, Excludes: `\.(debug|txt)$`, Files: []string{"app", "lib/a.so", "lib/b.so"}},
+		{Name: "file", Path: filepath.Join(source, "app.debug"), Files: []string{"app.debug"}},
+	} {
+		pkg := filepath.Join(root, tCase.Name+".tar.gz")
+		options := NewDefaultCollectFileArtifactOptions()
+		options.Recursive = true
+		if tCase.Excludes != "" {
+			options.Excludes = regexp.MustCompile(tCase.Excludes)
+		}
+		art, err := CompressCollectFileArtifact("test", tCase.Path, pkg, options)
+		if err != nil {
+			t.Fatalf("Failed to compress case [%s], error: %s", tCase.Name, err)
+		}
+		if !reflect.DeepEqual(art.Files, tCase.Files) || !art.Compressed || art.Path != pkg {
+			t.Errorf("Incorrect artifact of case [%s]. Expect %v Actual %v %v", tCase.Name, tCase.Files, art, art.Files)
+		}
+		names, contents := readTarGz(t, pkg)
+		if !reflect.DeepEqual(names, tCase.Files) {
+			t.Errorf("Incorrect entries of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Files, names)
+		}
+		for _, file := range collectFiles {
+			if content, ok := contents[file.Path]; ok && content != file.Content {
+				t.Errorf("Incorrect content of [%s] of case [%s]. Expect [%s] Actual [%s]", file.Path, tCase.Name, file.Content, content)
+			}
+		}
+		// The fingerprint is of the package
+		data, err := ioutil.ReadFile(pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := art.Stat(); err != nil {
+			t.Fatal(err)
+		}
+		if art.Fingerprint != getFingerprint(string(data)) || art.Size != int64(len(data)) {
+			t.Errorf("Incorrect fingerprint of case [%s]. Expect [%s] Actual [%s]", tCase.Name, getFingerprint(string(data)), art.Fingerprint)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Author: lipixun
+// Created Time : 日 10/18 09:12:40 2026
+//
+// File Name: manifest.go
+// Description:
+//	The artifact manifest, the artifacts of a build serialized in json for the downstream steps (e.g. packaging,
+//	uploading, the runner). Each artifact is an object of its fields, its info (see Info) and its type, e.g.
+//		{
+//			"tag": "...",
+//			"time": "...",
+//			"artifacts": [
+//				{"type": "file", "name": "default", "path": "...", "target": "...", "fingerprint": "sha256:...", ...},
+//				{"type": "docker", "name": "docker", "fullname": "...", "outputs": {"image": "..."}, ...}
+//			]
+//		}
+//	The artifacts are in the order of the targets then the names.
+package artifact
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ManifestFileName = "artifacts.json"
+)
+
+// The manifest of the artifacts
+type Manifest struct {
+	Tag       string             `json:"tag"`       // The build tag
+	Time      time.Time          `json:"time"`      // The time when the manifest was written
+	Artifacts []ManifestArtifact `json:"artifacts"` // The artifacts
+}
+
+// An artifact in the manifest
+type ManifestArtifact struct {
+	Artifact
+}
+
+// Add the artifact
+func (this *Manifest) Add(art Artifact) {
+	this.Artifacts = append(this.Artifacts, ManifestArtifact{art})
+}
+
+// Find the artifacts of the target, all artifacts if target is empty
+func (this *Manifest) Find(target string) []Artifact {
+	var arts []Artifact
+	for _, art := range this.Artifacts {
+		if target == "" || art.GetInfo().Target == target {
+			arts = append(arts, art.Artifact)
+		}
+	}
+	return arts
+}
+
+// Write the manifest to the file
+func (this *Manifest) Write(path string) error {
+	data, err := json.MarshalIndent(this, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0666)
+}
+
+// Read the manifest from the file
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse artifact manifest [%s], error: %s", path, err))
+	}
+	return &manifest, nil
+}
+
+func (this ManifestArtifact) MarshalJSON() ([]byte, error) {
+	if this.Artifact == nil {
+		return []byte("null"), nil
+	}
+	data, err := json.Marshal(this.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"] = this.GetType()
+	return json.Marshal(fields)
+}
+
+func (this *ManifestArtifact) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+	switch header.Type {
+	case ArtifactTypeFile:
+		this.Artifact = new(FileArtifact)
+	case ArtifactTypeDirectory:
+		this.Artifact = new(DirectoryArtifact)
+	case ArtifactTypeArchive:
+		this.Artifact = new(ArchiveArtifact)
+	case ArtifactTypeDocker:
+		this.Artifact = new(DockerArtifact)
+	default:
+		return errors.New(fmt.Sprintf("Unknown artifact type [%s]", header.Type))
+	}
+	return json.Unmarshal(data, this.Artifact)
+}
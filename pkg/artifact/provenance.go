@@ -0,0 +1,253 @@
+// Author: lipixun
+// Created Time : 日 10/18 15:12:36 2026
+//
+// File Name: provenance.go
+// Description:
+//	The provenance of an artifact, records how the artifact was produced (in the spirit of the SLSA provenance):
+//	the builder, the source (repository, commit and the sha256 of the target spec), the fingerprints of the
+//	dependency artifacts, the build parameters and the timestamps. The subject is the artifact name and fingerprint.
+//
+//	The provenance is attached to the artifact info (so it is in the manifest and pushed to the registry with the
+//	artifact), and optionally signed by an ed25519 private key. The signature is over the canonical json (the keys
+//	sorted, no spaces) of the provenance without the signature, so it survives the manifest serialization.
+//	A consumer verifies an artifact by (see VerifyArtifact):
+//		1. The content matches the fingerprint
+//		2. The provenance subject matches the artifact name and fingerprint
+//		3. The signature is made by the trusted public key (when the key is given)
+package artifact
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+const (
+	ProvenanceType = "https://github.com/ops-openlight/openlight/provenance/v1"
+
+	SignatureAlgorithmEd25519 = "ed25519"
+
+	KeyIDLength = 16 // The length of the key id, the sha256 (hex) prefix of the public key
+)
+
+// The provenance of an artifact
+type Provenance struct {
+	Type         string                 `json:"type" yaml:"type"` // ProvenanceType
+	Subject      ProvenanceSubject      `json:"subject" yaml:"subject"`
+	Builder      ProvenanceBuilder      `json:"builder" yaml:"builder"`
+	Source       ProvenanceSource       `json:"source" yaml:"source"`
+	Dependencies []ProvenanceDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"` // Sorted by the target and the name
+	Parameters   map[string]string      `json:"parameters,omitempty" yaml:"parameters,omitempty"`     // The build parameters
+	StartedTime  time.Time              `json:"startedTime" yaml:"startedTime"`
+	FinishedTime time.Time              `json:"finishedTime" yaml:"finishedTime"`
+	Signature    *ProvenanceSignature   `json:"signature,omitempty" yaml:"signature,omitempty"` // Nil if not signed
+}
+
+// The artifact the provenance is about
+type ProvenanceSubject struct {
+	Name        string `json:"name" yaml:"name"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// The builder produced the artifact
+type ProvenanceBuilder struct {
+	ID      string `json:"id" yaml:"id"`           // op/<build type>, e.g. op/golang
+	Version string `json:"version" yaml:"version"` // The version of op
+	Host    string `json:"host" yaml:"host"`
+	OS      string `json:"os" yaml:"os"`
+	Arch    string `json:"arch" yaml:"arch"`
+}
+
+// The source of the artifact
+type ProvenanceSource struct {
+	Repository string `json:"repository" yaml:"repository"`
+	Branch     string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Commit     string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Target     string `json:"target" yaml:"target"`                         // The target key
+	SpecHash   string `json:"specHash,omitempty" yaml:"specHash,omitempty"` // sha256:<hex> of the target spec
+}
+
+// An artifact of the dependencies
+type ProvenanceDependency struct {
+	Target      string `json:"target" yaml:"target"`
+	Name        string `json:"name" yaml:"name"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// The signature of the provenance
+type ProvenanceSignature struct {
+	Algorithm string `json:"algorithm" yaml:"algorithm"` // SignatureAlgorithmEd25519
+	KeyID     string `json:"keyId" yaml:"keyId"`         // See GetKeyID
+	Value     string `json:"value" yaml:"value"`         // The base64 signature
+}
+
+// Create the provenance of the artifact
+func NewProvenance(art Artifact) *Provenance {
+	return &Provenance{
+		Type:    ProvenanceType,
+		Subject: ProvenanceSubject{Name: art.GetName(), Fingerprint: art.GetInfo().Fingerprint},
+	}
+}
+
+// Sign the provenance
+func (this *Provenance) Sign(key ed25519.PrivateKey) error {
+	payload, err := this.payload()
+	if err != nil {
+		return err
+	}
+	this.Signature = &ProvenanceSignature{
+		Algorithm: SignatureAlgorithmEd25519,
+		KeyID:     GetKeyID(key.Public().(ed25519.PublicKey)),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}
+	return nil
+}
+
+// Verify the signature by the public key
+func (this *Provenance) Verify(key ed25519.PublicKey) error {
+	if this.Signature == nil {
+		return errors.New("The provenance is not signed")
+	}
+	if this.Signature.Algorithm != SignatureAlgorithmEd25519 {
+		return errors.New(fmt.Sprintf("Unsupported signature algorithm [%s]", this.Signature.Algorithm))
+	}
+	if keyID := GetKeyID(key); this.Signature.KeyID != keyID {
+		return errors.New(fmt.Sprintf("The provenance is signed by key [%s] instead of the trusted key [%s]", this.Signature.KeyID, keyID))
+	}
+	signature, err := base64.StdEncoding.DecodeString(this.Signature.Value)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Invalid signature, error: %s", err))
+	}
+	payload, err := this.payload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, payload, signature) {
+		return errors.New("Signature mismatch, the provenance is modified after signed")
+	}
+	// Done
+	return nil
+}
+
+// Get the signed payload, the canonical json without the signature
+func (this *Provenance) payload() ([]byte, error) {
+	unsigned := *this
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	// Decode and encode again, the keys of the decoded maps are sorted
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// Get the key id of the public key
+func GetKeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])[:KeyIDLength]
+}
+
+// Parse the base64 ed25519 public key
+func ParsePublicKey(text string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New(fmt.Sprintf("Invalid ed25519 public key [%s]", text))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Load the base64 ed25519 private key (or its seed) from the file
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid ed25519 private key file [%s], error: %s", path, err))
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	}
+	return nil, errors.New(fmt.Sprintf("Invalid ed25519 private key file [%s], should be the base64 of the %d bytes key or %d bytes seed", path, ed25519.PrivateKeySize, ed25519.SeedSize))
+}
+
+// Verify the content and the provenance of the artifact
+// Parameters:
+//
+//	key 	The trusted public key, the signature is not verified if nil
+func VerifyArtifact(art Artifact, key ed25519.PublicKey) error {
+	info := art.GetInfo()
+	if err := verifyContent(art); err != nil {
+		return err
+	}
+	provenance := info.Provenance
+	if provenance == nil {
+		return errors.New("No provenance")
+	}
+	if provenance.Subject.Name != art.GetName() || provenance.Subject.Fingerprint != info.Fingerprint {
+		return errors.New(fmt.Sprintf("The provenance is about [%s] (%s) instead of the artifact", provenance.Subject.Name, provenance.Subject.Fingerprint))
+	}
+	if key != nil {
+		return provenance.Verify(key)
+	}
+	// Done
+	return nil
+}
+
+// Verify the content matches the fingerprint, the docker images are not verified (the fingerprint is the digest)
+func verifyContent(art Artifact) error {
+	fingerprint := art.GetInfo().Fingerprint
+	if fingerprint == "" {
+		return errors.New("No fingerprint")
+	}
+	var actual string
+	switch t := art.(type) {
+	case *FileArtifact:
+		copied := *t
+		copied.Info = Info{}
+		if err := copied.Stat(); err != nil {
+			return err
+		}
+		actual = copied.Fingerprint
+	case *ArchiveArtifact:
+		_, sum, err := hashFile(t.Path)
+		if err != nil {
+			return err
+		}
+		actual = "sha256:" + sum
+	case *DirectoryArtifact:
+		current, err := NewDirectoryArtifact(t.Name, t.Path, t.Excludes)
+		if err != nil {
+			return err
+		}
+		if diff := t.Diff(current); !diff.Empty() {
+			return errors.New(fmt.Sprintf("The content is changed: %s", diff.String()))
+		}
+		actual = current.Fingerprint
+	default:
+		return nil
+	}
+	if actual != fingerprint {
+		return errors.New(fmt.Sprintf("Fingerprint mismatch. Expected [%s] Actual [%s]", fingerprint, actual))
+	}
+	// Done
+	return nil
+}
@@ -0,0 +1,319 @@
+// Author: lipixun
+// Created Time : 五 10/16 18:05:42 2026
+//
+// File Name: provenance_test.go
+// Description:
+//
+package artifact
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	// The fixed seeds of the test keys
+	trustedKeySeed = []byte("0123456789abcdef0123456789abcdef")
+	otherKeySeed   = []byte("fedcba9876543210fedcba9876543210")
+
+	provenanceVerifyCases = []struct {
+		Name   string
+		Sign   bool
+		Change func(provenance *Provenance)
+		Error  string
+	}{
+		{Name: "signed", Sign: true, Change: func(provenance *Provenance) {}},
+		{Name: "not signed", Change: func(provenance *Provenance) {}, Error: "not signed"},
+		{
+			Name:   "builder modified",
+			Sign:   true,
+			Change: func(provenance *Provenance) { provenance.Builder.Host = "evil" },
+			Error:  "Signature mismatch",
+		},
+		{
+			Name:   "parameter added",
+			Sign:   true,
+			Change: func(provenance *Provenance) { provenance.Parameters["debug"] = "true" },
+			Error:  "Signature mismatch",
+		},
+		{
+			Name: "dependency modified",
+			Sign: true,
+			Change: func(provenance *Provenance) {
+				provenance.Dependencies[0].Fingerprint = "sha256:0000"
+			},
+			Error: "Signature mismatch",
+		},
+		{
+			Name: "signed by other key",
+			Change: func(provenance *Provenance) {
+				provenance.Sign(ed25519.NewKeyFromSeed(otherKeySeed))
+			},
+			Error: "instead of the trusted key",
+		},
+		{
+			Name: "other key id",
+			Sign: true,
+			Change: func(provenance *Provenance) {
+				provenance.Signature.KeyID = GetKeyID(ed25519.NewKeyFromSeed(otherKeySeed).Public().(ed25519.PublicKey))
+			},
+			Error: "instead of the trusted key",
+		},
+		{
+			Name:   "algorithm",
+			Sign:   true,
+			Change: func(provenance *Provenance) { provenance.Signature.Algorithm = "rsa" },
+			Error:  "Unsupported signature algorithm",
+		},
+		{
+			Name:   "invalid signature",
+			Sign:   true,
+			Change: func(provenance *Provenance) { provenance.Signature.Value = "!" },
+			Error:  "Invalid signature",
+		},
+	}
+
+	verifyArtifactCases = []struct {
+		Name   string
+		Key    bool // Verify with the trusted key
+		Change func(art *FileArtifact) error
+		Error  string
+	}{
+		{Name: "verified", Key: true, Change: func(art *FileArtifact) error { return nil }},
+		{Name: "verified without key", Change: func(art *FileArtifact) error { art.Provenance.Signature = nil; return nil }},
+		{Name: "not signed", Key: true, Change: func(art *FileArtifact) error { art.Provenance.Signature = nil; return nil }, Error: "not signed"},
+		{Name: "no provenance", Change: func(art *FileArtifact) error { art.Provenance = nil; return nil }, Error: "No provenance"},
+		{Name: "no fingerprint", Change: func(art *FileArtifact) error { art.Fingerprint = ""; return nil }, Error: "No fingerprint"},
+		{
+			Name: "content changed",
+			Key:  true,
+			Change: func(art *FileArtifact) error {
+				return ioutil.WriteFile(filepath.Join(art.Path, "app"), []byte("tampered"), 0755)
+			},
+			Error: "Fingerprint mismatch",
+		},
+		{
+			Name: "content and fingerprint changed",
+			Key:  true,
+			Change: func(art *FileArtifact) error {
+				if err := ioutil.WriteFile(filepath.Join(art.Path, "app"), []byte("tampered"), 0755); err != nil {
+					return err
+				}
+				art.Info = Info{Provenance: art.Provenance}
+				return art.Stat()
+			},
+			Error: "The provenance is about",
+		},
+		{Name: "renamed", Key: true, Change: func(art *FileArtifact) error { art.Name = "other"; return nil }, Error: "The provenance is about"},
+	}
+)
+
+// Create the provenance of the artifact with all fields set
+func newTestProvenance(art Artifact) *Provenance {
+	provenance := NewProvenance(art)
+	provenance.Builder = ProvenanceBuilder{ID: "op/golang", Version: "1.0.0", Host: "builder", OS: "linux", Arch: "amd64"}
+	provenance.Source = ProvenanceSource{Repository: "github.com/test/repo", Branch: "master", Commit: "abcdef", Target: "//app:app", SpecHash: "sha256:1234"}
+	provenance.Dependencies = []ProvenanceDependency{{Target: "//lib:lib", Name: "default", Fingerprint: "sha256:5678"}}
+	provenance.Parameters = map[string]string{"tags": "netgo", "ldflags": "-s -w"}
+	provenance.StartedTime = time.Date(2026, 10, 16, 10, 0, 0, 123456789, time.Local)
+	provenance.FinishedTime = provenance.StartedTime.Add(time.Minute)
+	return provenance
+}
+
+func TestProvenanceVerify(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(trustedKeySeed)
+	art := NewSingleFileArtifact("default", "/tmp/app")
+	art.Fingerprint = "sha256:abcd"
+	for _, tCase := range provenanceVerifyCases {
+		provenance := newTestProvenance(art)
+		if subject := provenance.Subject; subject.Name != "default" || subject.Fingerprint != "sha256:abcd" {
+			t.Fatalf("Incorrect subject %+v", subject)
+		}
+		if tCase.Sign {
+			if err := provenance.Sign(key); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tCase.Change(provenance)
+		err := provenance.Verify(key.Public().(ed25519.PublicKey))
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+		} else if err != nil {
+			t.Errorf("Failed to verify case [%s], error: %s", tCase.Name, err)
+		}
+	}
+}
+
+func TestProvenanceManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key := ed25519.NewKeyFromSeed(trustedKeySeed)
+	// The signature survives the manifest serialization
+	var manifest Manifest
+	for _, art := range []Artifact{
+		&FileArtifact{Name: "file", Path: "/tmp/app", Info: Info{Fingerprint: "sha256:1"}},
+		&DirectoryArtifact{Name: "directory", Path: "/tmp/dir", Info: Info{Fingerprint: "sha256:2"}},
+		&ArchiveArtifact{Name: "archive", Path: "/tmp/app.zip", Format: ArchiveFormatZip, Info: Info{Fingerprint: "sha256:3"}},
+		&DockerArtifact{Name: "docker", Info: Info{Fingerprint: "sha256:4"}},
+	} {
+		provenance := newTestProvenance(art)
+		if err := provenance.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		art.GetInfo().Provenance = provenance
+		manifest.Add(art)
+	}
+	path := filepath.Join(dir, ManifestFileName)
+	if err := manifest.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Artifacts) != len(manifest.Artifacts) {
+		t.Fatalf("Incorrect artifacts. Expect %d Actual %d", len(manifest.Artifacts), len(read.Artifacts))
+	}
+	for i, art := range read.Artifacts {
+		expect := manifest.Artifacts[i]
+		if art.GetType() != expect.GetType() || art.GetName() != expect.GetName() || art.GetInfo().Fingerprint != expect.GetInfo().Fingerprint {
+			t.Errorf("Incorrect artifact. Expect %s [%s] Actual %s [%s]", expect.GetType(), expect.GetName(), art.GetType(), art.GetName())
+			continue
+		}
+		provenance := art.GetInfo().Provenance
+		if provenance == nil {
+			t.Errorf("No provenance of %s [%s]", art.GetType(), art.GetName())
+		} else if err := provenance.Verify(key.Public().(ed25519.PublicKey)); err != nil {
+			t.Errorf("Failed to verify the provenance of %s [%s] read from the manifest, error: %s", art.GetType(), art.GetName(), err)
+		}
+	}
+	// Unknown type
+	if err := ioutil.WriteFile(path, []byte(`{"artifacts": [{"type": "rpm", "name": "rpm"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(path); err == nil || !strings.Contains(err.Error(), "Unknown artifact type [rpm]") {
+		t.Errorf("Incorrect error of the unknown type. Actual [%v]", err)
+	}
+}
+
+func TestVerifyArtifact(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(trustedKeySeed)
+	for _, tCase := range verifyArtifactCases {
+		func() {
+			root, err := ioutil.TempDir("", "artifact-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			writeTestFiles(t, root, collectFiles)
+			art := NewFileArtifact("default", root, []string{"app", "lib/a.so"}, false)
+			if err := art.Stat(); err != nil {
+				t.Fatal(err)
+			}
+			art.Provenance = newTestProvenance(art)
+			if err := art.Provenance.Sign(key); err != nil {
+				t.Fatal(err)
+			}
+			if err := tCase.Change(art); err != nil {
+				t.Fatal(err)
+			}
+			var trusted ed25519.PublicKey
+			if tCase.Key {
+				trusted = key.Public().(ed25519.PublicKey)
+			}
+			err = VerifyArtifact(art, trusted)
+			if tCase.Error != "" {
+				if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+					t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+				}
+			} else if err != nil {
+				t.Errorf("Failed to verify case [%s], error: %s", tCase.Name, err)
+			}
+		}()
+	}
+}
+
+func TestVerifyDirectoryArtifact(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeTestFiles(t, root, directoryFiles)
+	art, err := NewDirectoryArtifact("default", root, []string{".git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	art.Provenance = NewProvenance(art)
+	if err := VerifyArtifact(art, nil); err != nil {
+		t.Errorf("Failed to verify the directory, error: %s", err)
+	}
+	// The excluded files are not verified
+	if err := ioutil.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifact(art, nil); err != nil {
+		t.Errorf("Failed to verify the directory of the excluded file changed, error: %s", err)
+	}
+	if err := os.Remove(filepath.Join(root, "README.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifact(art, nil); err == nil || !strings.Contains(err.Error(), "- README.md") {
+		t.Errorf("Incorrect error of the removed file. Actual [%v]", err)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifact-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key := ed25519.NewKeyFromSeed(trustedKeySeed)
+	for _, tCase := range []struct {
+		Name    string
+		Content string
+		Error   string
+	}{
+		{Name: "seed", Content: base64.StdEncoding.EncodeToString(trustedKeySeed) + "\n"},
+		{Name: "key", Content: base64.StdEncoding.EncodeToString(key)},
+		{Name: "short", Content: base64.StdEncoding.EncodeToString(trustedKeySeed[:16]), Error: "should be the base64"},
+		{Name: "not base64", Content: "!", Error: "Invalid ed25519 private key file"},
+	} {
+		path := filepath.Join(dir, tCase.Name)
+		if err := ioutil.WriteFile(path, []byte(tCase.Content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadPrivateKey(path)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+		} else if err != nil || !key.Equal(loaded) {
+			t.Errorf("Incorrect private key of case [%s], error: %v", tCase.Name, err)
+		}
+	}
+	public := key.Public().(ed25519.PublicKey)
+	if parsed, err := ParsePublicKey(" " + base64.StdEncoding.EncodeToString(public) + "\n"); err != nil || !public.Equal(parsed) {
+		t.Errorf("Incorrect public key, error: %v", err)
+	}
+	for _, text := range []string{"", "!", base64.StdEncoding.EncodeToString(trustedKeySeed[:16])} {
+		if _, err := ParsePublicKey(text); err == nil {
+			t.Errorf("Public key [%s] should be invalid", text)
+		}
+	}
+	if id := GetKeyID(public); len(id) != KeyIDLength || id == GetKeyID(ed25519.NewKeyFromSeed(otherKeySeed).Public().(ed25519.PublicKey)) {
+		t.Errorf("Incorrect key id [%s]", id)
+	}
+}
@@ -0,0 +1,129 @@
+// Author: lipixun
+// Created Time : 五 10/16 22:04:19 2026
+//
+// File Name: errors.go
+// Description:
+//	The errors with causes and stack traces
+//
+//	This package could replace the standard errors package: New creates the error with the stack trace, and Wrap /
+//	Wrapf add the operation context to an error while keeping the underlying error as the cause, instead of
+//	formatting the cause into a new error (which loses its type, e.g. os.IsNotExist no longer works):
+//
+//		if err := os.Remove(path); err != nil {
+//			return errors.Wrapf(err, "Failed to remove [%s]", path)
+//		}
+//
+//	The message of a wrapped error is "<message>: <cause message>"
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const (
+	maxStackDepth = 32
+)
+
+type Error struct {
+	Message string
+	Cause   error     // The cause, nil if this is the root error
+	stack   []uintptr // The stack where the error is created
+}
+
+// Create a new error with stack trace
+func New(message string) error {
+	return &Error{Message: message, stack: callers()}
+}
+
+// Create a new error with formatted message and stack trace
+func Errorf(format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...), stack: callers()}
+}
+
+// Wrap the error with the operation context, returns nil if err is nil
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Message: message, Cause: err, stack: callers()}
+}
+
+// Wrap the error with the formatted operation context, returns nil if err is nil
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Message: fmt.Sprintf(format, args...), Cause: err, stack: callers()}
+}
+
+func (this *Error) Error() string {
+	if this.Cause == nil {
+		return this.Message
+	}
+	return fmt.Sprintf("%s: %s", this.Message, this.Cause.Error())
+}
+
+// Support errors.Is / errors.As of the standard errors package
+func (this *Error) Unwrap() error {
+	return this.Cause
+}
+
+// Get the stack trace where the error is created, one frame per line
+func (this *Error) StackTrace() string {
+	var lines []string
+	frames := runtime.CallersFrames(this.stack)
+	for {
+		frame, more := frames.Next()
+		lines = append(lines, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Get the root cause of the error (the error itself if not wrapped)
+func Cause(err error) error {
+	for {
+		e, ok := err.(*Error)
+		if !ok || e.Cause == nil {
+			return err
+		}
+		err = e.Cause
+	}
+}
+
+// Get the error chain from the error to the root cause
+func Causes(err error) []error {
+	var errs []error
+	for err != nil {
+		errs = append(errs, err)
+		e, ok := err.(*Error)
+		if !ok {
+			break
+		}
+		err = e.Cause
+	}
+	return errs
+}
+
+// Get the messages of the error chain, each message without the message of its cause
+func Messages(err error) []string {
+	var messages []string
+	for _, e := range Causes(err) {
+		if _e, ok := e.(*Error); ok {
+			messages = append(messages, _e.Message)
+		} else {
+			messages = append(messages, e.Error())
+		}
+	}
+	return messages
+}
+
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
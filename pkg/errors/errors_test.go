@@ -0,0 +1,33 @@
+// Author: lipixun
+// Created Time : 五 10/16 22:31:50 2026
+//
+// File Name: errors_test.go
+// Description:
+//
+package errors
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	_, err := os.Open("/not/exist/file")
+	wrapped := Wrapf(Wrap(err, "Failed to load spec"), "Failed to load repository [%s]", "repo")
+	if !os.IsNotExist(Cause(wrapped)) {
+		t.Errorf("The cause should be not exist error, actual: %v", Cause(wrapped))
+	}
+	if !strings.HasPrefix(wrapped.Error(), "Failed to load repository [repo]: Failed to load spec: ") {
+		t.Errorf("Incorrect message: %s", wrapped.Error())
+	}
+	if messages := Messages(wrapped); len(messages) != 3 || messages[1] != "Failed to load spec" {
+		t.Errorf("Incorrect messages: %v", messages)
+	}
+	if !strings.Contains(wrapped.(*Error).StackTrace(), "TestWrap") {
+		t.Errorf("Stack trace should contain the test function")
+	}
+	if Wrap(nil, "Nothing") != nil {
+		t.Errorf("Wrap nil should be nil")
+	}
+}
@@ -0,0 +1,108 @@
+// Author: lipixun
+// Created Time : 六 10/17 17:05:48 2026
+//
+// File Name: archive.go
+// Description:
+//	Fetch and extract the archives (zip, tar or tar.gz, e.g. the release tarballs of the third party dependencies)
+//
+//	The archive is extracted into the content-addressed directory named by its sha256 digest:
+//		<user>/cache/archives/<sha256>/
+//	The strip prefix is the directory in the archive used as the root, e.g. foo-1.2 for foo-1.2.tar.gz. When not
+//	specified, the single top directory of the archive is stripped, use . to keep the top directory.
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ArchivesDirName = "cache/archives" // The extracted archives directory (relative to the user workdir)
+)
+
+// Fetch the archive and extract it into the cache
+// Parameters:
+//
+//	rawurl 			The url of the archive
+//	digest 			The expected sha256 digest (hex) of the archive, not verified if empty
+//	stripPrefix 	The directory in the archive used as the root
+//
+// Returns:
+//
+//	The root directory of the extracted files (should not be modified) and the sha256 digest of the archive
+func (this *Fetcher) FetchArchive(rawurl, digest, stripPrefix string) (string, string, error) {
+	archivePath, err := this.Fetch(rawurl, digest)
+	if err != nil {
+		return "", "", err
+	}
+	digest = filepath.Base(archivePath)
+	archivesPath, err := this.ws.Dir.User.GetPath(ArchivesDirName)
+	if err != nil {
+		return "", "", err
+	}
+	path := filepath.Join(archivesPath, digest)
+	if _, err := os.Stat(path); err != nil {
+		if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		this.logger.LeveledPrintf(log.LevelDebug, "Extract [%s] to [%s]\n", rawurl, path)
+		if err := extractArchive(archivePath, archivesPath, path); err != nil {
+			return "", "", errors.New(fmt.Sprintf("Failed to extract [%s], error: %s", rawurl, err))
+		}
+	}
+	root, err := getArchiveRoot(path, stripPrefix)
+	if err != nil {
+		return "", "", errors.New(fmt.Sprintf("Invalid strip prefix of [%s], error: %s", rawurl, err))
+	}
+	// Done
+	return root, digest, nil
+}
+
+// Extract the archive into the temp directory then rename, the concurrent extractions are safe
+func extractArchive(archivePath, archivesPath, path string) error {
+	if err := os.MkdirAll(archivesPath, os.ModePerm); err != nil {
+		return err
+	}
+	tempPath, err := ioutil.TempDir(archivesPath, "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempPath)
+	if err := util.ExtractArchive(archivePath, tempPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		if _, statErr := os.Stat(path); statErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get the root directory of the extracted archive by the strip prefix
+func getArchiveRoot(path, stripPrefix string) (string, error) {
+	if stripPrefix == "" {
+		infos, err := ioutil.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		if len(infos) == 1 && infos[0].IsDir() {
+			return filepath.Join(path, infos[0].Name()), nil
+		}
+		return path, nil
+	}
+	if filepath.IsAbs(stripPrefix) || strings.HasPrefix(filepath.Clean(stripPrefix), "..") {
+		return "", errors.New(fmt.Sprintf("[%s] should be a relative path in the archive", stripPrefix))
+	}
+	root := filepath.Join(path, stripPrefix)
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", errors.New(fmt.Sprintf("Directory [%s] not found in the archive", stripPrefix))
+	}
+	return root, nil
+}
@@ -0,0 +1,254 @@
+// Author: lipixun
+// Created Time : 六 10/17 10:24:41 2026
+//
+// File Name: fetcher.go
+// Description:
+//	The fetcher downloads the remote files (sources, artifacts) into the workspace cache
+//
+//	The fetched content is put in the workspace cache "fetch" keyed by the url, the content path is named by its
+//	sha256 digest (see workspace/cache.go), so the checksum declared in the spec is verified without reading the
+//	content again. The partial downloads are kept in <user>/cache/downloads to be resumed.
+//
+//	The supported urls:
+//		http(s)://... 		Authorized by the workspace credential of the host (see http.go)
+//		s3://bucket/key 	See s3.go
+//		gs://bucket/key 	See gcs.go
+//		oci://registry/repository[:tag][@digest] 	The single file artifact, see oci.go
+//
+//	The urls are rewritten by the workspace rewrite rules (see workspace/rewrite.go, only the global and user rules
+//	if UserRewrites is set) before fetching or uploading, the requests are sent through the workspace proxy (see
+//	workspace/proxy.go)
+//
+//	In offline mode (see workspace/offline.go) the content is served from the cache without revalidation, the urls
+//	not cached (or the oci urls not pinned by digest) fail with workspace.OfflineError
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FetcherLogHeader = "Fetcher"
+
+	FetchCacheNamespace = "fetch"
+	FetchCacheMaxSize   = 4 << 30
+	DownloadsDirName    = "cache/downloads" // The partial downloads directory (relative to the user workdir)
+)
+
+type Fetcher struct {
+	UserRewrites bool // Rewrite the urls by the global and user rules only, e.g. where op updates itself from
+
+	ws           *workspace.Workspace
+	logger       log.Logger
+	cache        *workspace.Cache
+	downloadPath string
+	client       *http.Client
+
+	lock           sync.Mutex
+	gcsToken       string
+	gcsTokenExpiry time.Time
+	ociChallenges  map[string]*ociChallenge
+	ociTokens      map[string]*ociToken
+}
+
+// Create a new Fetcher
+func New(ws *workspace.Workspace) (*Fetcher, error) {
+	if ws == nil {
+		return nil, errors.New("Require workspace")
+	}
+	cache, err := ws.Cache(FetchCacheNamespace, workspace.CacheOptions{MaxSize: FetchCacheMaxSize})
+	if err != nil {
+		return nil, err
+	}
+	downloadPath, err := ws.Dir.User.GetPath(DownloadsDirName)
+	if err != nil {
+		return nil, err
+	}
+	return &Fetcher{
+		ws:            ws,
+		logger:        ws.Logger.GetLoggerWithHeader(FetcherLogHeader),
+		cache:         cache,
+		downloadPath:  downloadPath,
+		client:        ws.HTTPClient(0),
+		ociChallenges: make(map[string]*ociChallenge),
+		ociTokens:     make(map[string]*ociToken),
+	}, nil
+}
+
+// Fetch the url into the cache
+// Parameters:
+//
+//	rawurl 		The url to fetch
+//	digest 		The expected sha256 digest (hex) of the content, not verified if empty
+//
+// Returns:
+//
+//	The local path of the content, should not be modified
+func (this *Fetcher) Fetch(rawurl string, digest string) (string, error) {
+	// The content is cached by the url before rewritten
+	target := this.rewrite(rawurl)
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Invalid url [%s], error: %s", target, err))
+	}
+	digest = strings.ToLower(digest)
+	var path string
+	switch strings.ToLower(u.Scheme) {
+	case uri.SchemeHTTP, uri.SchemeHTTPS:
+		path, err = this.fetchHTTP(rawurl, digest, func() (*http.Request, error) {
+			return this.newHTTPRequest(target)
+		})
+	case uri.SchemeS3, uri.SchemeGCS:
+		object, parseErr := uri.ParseObject(target)
+		if parseErr != nil {
+			return "", parseErr
+		}
+		path, err = this.fetchHTTP(rawurl, digest, func() (*http.Request, error) {
+			return this.newObjectRequest(http.MethodGet, object)
+		})
+	case uri.SchemeOCI:
+		path, err = this.fetchOCI(target)
+	default:
+		return "", errors.New(fmt.Sprintf("Unsupported scheme [%s] of url [%s]", u.Scheme, rawurl))
+	}
+	if err != nil {
+		if workspace.IsOfflineError(err) || workspace.IsCanceledError(err) {
+			return "", err
+		}
+		return "", errors.New(fmt.Sprintf("Failed to fetch [%s], error: %s", rawurl, err))
+	}
+	// Verify the checksum
+	if digest != "" && filepath.Base(path) != digest {
+		this.cache.Remove(rawurl)
+		return "", errors.New(fmt.Sprintf(
+			"Checksum mismatch of [%s]. Expected sha256 [%s] Actual [%s]. The content is discarded, update the declared sha256 if the content is expected to change",
+			rawurl, digest, filepath.Base(path),
+		))
+	}
+	// Done
+	return path, nil
+}
+
+// Rewrite the url by the workspace rules
+func (this *Fetcher) rewrite(rawurl string) string {
+	if this.UserRewrites {
+		return this.ws.UserRewrite(rawurl)
+	}
+	return this.ws.Rewrite(rawurl)
+}
+
+// Upload the local file to the url, the object is overwritten if exists
+// The http(s) url is uploaded by a PUT request authorized by the workspace credential of the host, the oci url is
+// pushed as a single file artifact
+func (this *Fetcher) Upload(path string, rawurl string) error {
+	if this.ws.Offline {
+		return errors.New(fmt.Sprintf("Cannot upload [%s] to [%s] in offline mode", path, rawurl))
+	}
+	rawurl = this.rewrite(rawurl)
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Invalid url [%s], error: %s", rawurl, err))
+	}
+	var request *http.Request
+	switch strings.ToLower(u.Scheme) {
+	case uri.SchemeHTTP, uri.SchemeHTTPS:
+		request, err = http.NewRequestWithContext(this.ws.Context(), http.MethodPut, rawurl, nil)
+		if err == nil {
+			this.authorize(request)
+		}
+	case uri.SchemeS3, uri.SchemeGCS:
+		var object *uri.ObjectURI
+		if object, err = uri.ParseObject(rawurl); err == nil {
+			if object.Key == "" || strings.HasSuffix(object.Key, "/") {
+				object = object.Join(filepath.Base(path))
+			}
+			request, err = this.newObjectRequest(http.MethodPut, object)
+		}
+	case uri.SchemeOCI:
+		digest, err := this.uploadOCI(path, rawurl)
+		if err != nil {
+			return err
+		}
+		this.logger.LeveledPrintf(log.LevelInfo, "Pushed [%s] to [%s@%s]\n", path, rawurl, digest)
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("Unsupported scheme [%s] of url [%s]", u.Scheme, rawurl))
+	}
+	if err != nil {
+		return err
+	}
+	// Send the file
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	request.Body = file
+	request.ContentLength = info.Size()
+	this.logger.LeveledPrintf(log.LevelInfo, "Upload [%s] to [%s]\n", path, request.URL)
+	response, err := this.client.Do(request)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Failed to upload [%s] to [%s], error: %s", path, rawurl, err))
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("Failed to upload [%s] to [%s]: %s", path, rawurl, response.Status))
+	}
+	// Done
+	return nil
+}
+
+// Create the request of the object storage
+func (this *Fetcher) newObjectRequest(method string, object *uri.ObjectURI) (*http.Request, error) {
+	if object.Key == "" {
+		return nil, errors.New(fmt.Sprintf("Require object key of [%s]", object))
+	}
+	if object.Scheme == uri.SchemeS3 {
+		return this.newS3Request(method, object)
+	}
+	return this.newGCSRequest(method, object)
+}
+
+// Get the cached content of the url
+// Returns:
+//
+//	The content path (empty if not cached) and whether the content matches the digest
+func (this *Fetcher) getCached(rawurl string, digest string) (string, bool, error) {
+	path, ok, err := this.cache.Get(rawurl)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return path, digest == "" || filepath.Base(path) == digest, nil
+}
+
+// Set the authorization header by the credential of the host (see workspace.GetCredential), bearer token if no username
+func (this *Fetcher) authorize(request *http.Request) {
+	credential, err := this.ws.GetCredential(request.URL.Host)
+	if err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to get credential of [%s], error: %s\n", request.URL.Host, err)
+		return
+	}
+	if credential == nil {
+		return
+	}
+	if credential.Username == "" {
+		request.Header.Set("Authorization", "Bearer "+credential.Secret)
+	} else {
+		request.SetBasicAuth(credential.Username, credential.Secret)
+	}
+}
@@ -0,0 +1,209 @@
+// Author: lipixun
+// Created Time : 六 10/17 12:14:05 2026
+//
+// File Name: gcs.go
+// Description:
+//	Fetch and upload gs:// urls
+//
+//	The requests are sent to the xml api <endpoint>/<bucket>/<key> with a bearer token, the endpoint is the gcs.endpoint
+//	config, https://storage.googleapis.com if not set. gcs.endpoint is only accepted from the user and global config
+//	since the token is sent to it
+//
+//	The access token is looked up in order:
+//		GOOGLE_OAUTH_ACCESS_TOKEN
+//		The application default credentials file (GOOGLE_APPLICATION_CREDENTIALS or
+//		~/.config/gcloud/application_default_credentials.json) of a service account or an authorized user
+//		The output of `gcloud auth print-access-token`
+//	The request is not authorized if no token is found (a public bucket)
+package fetcher
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsTokenURL        = "https://oauth2.googleapis.com/token"
+	gcsScope           = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsTokenLifetime   = time.Hour
+	gcsTokenMargin     = time.Minute // Refresh the token this duration before it expires
+
+	gcsCredentialServiceAccount = "service_account"
+	gcsCredentialAuthorizedUser = "authorized_user"
+)
+
+// The application default credentials file
+type gcsCredential struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Create the authorized gcs request of the object
+func (this *Fetcher) newGCSRequest(method string, object *uri.ObjectURI) (*http.Request, error) {
+	endpoint := this.ws.GetUserConfigString(workspace.ConfigKeyGCSEndpoint)
+	if endpoint == "" {
+		endpoint = gcsDefaultEndpoint
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid gcs endpoint [%s], error: %s", endpoint, err))
+	}
+	u.Path = fmt.Sprintf("%s/%s/%s", u.Path, object.Bucket, object.Key)
+	request, err := http.NewRequestWithContext(this.ws.Context(), method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := this.getGCSToken()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request, nil
+}
+
+// Get the gcs access token, empty if no credential is found
+func (this *Fetcher) getGCSToken() (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.gcsToken != "" && time.Now().Add(gcsTokenMargin).Before(this.gcsTokenExpiry) {
+		return this.gcsToken, nil
+	}
+	token, expiry, err := this.requestGCSToken()
+	if err != nil {
+		return "", err
+	}
+	this.gcsToken, this.gcsTokenExpiry = token, expiry
+	return token, nil
+}
+
+func (this *Fetcher) requestGCSToken() (string, time.Time, error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = filepath.Join(os.Getenv("HOME"), ".config", "gcloud", "application_default_credentials.json")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		var credential gcsCredential
+		if err := json.Unmarshal(data, &credential); err != nil {
+			return "", time.Time{}, errors.New(fmt.Sprintf("Failed to parse gcs credential file [%s], error: %s", path, err))
+		}
+		return this.exchangeGCSToken(&credential)
+	} else if !os.IsNotExist(err) {
+		return "", time.Time{}, err
+	}
+	// Try the gcloud cli
+	if _, err := exec.LookPath("gcloud"); err != nil {
+		this.logger.LeveledPrintf(log.LevelDebug, "No gcs credential found, send the requests without authorization\n")
+		return "", time.Time{}, nil
+	}
+	output, err := exec.Command("gcloud", "auth", "print-access-token").Output()
+	if err != nil {
+		return "", time.Time{}, errors.New(fmt.Sprintf("Failed to get gcs access token by gcloud, error: %s", err))
+	}
+	return strings.TrimSpace(string(output)), time.Now().Add(gcsTokenLifetime), nil
+}
+
+// Exchange the access token by the credential
+func (this *Fetcher) exchangeGCSToken(credential *gcsCredential) (string, time.Time, error) {
+	tokenURL := credential.TokenURI
+	if tokenURL == "" {
+		tokenURL = gcsTokenURL
+	}
+	form := url.Values{}
+	switch credential.Type {
+	case gcsCredentialServiceAccount:
+		assertion, err := signGCSAssertion(credential, tokenURL, time.Now())
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+		form.Set("assertion", assertion)
+	case gcsCredentialAuthorizedUser:
+		form.Set("grant_type", "refresh_token")
+		form.Set("client_id", credential.ClientID)
+		form.Set("client_secret", credential.ClientSecret)
+		form.Set("refresh_token", credential.RefreshToken)
+	default:
+		return "", time.Time{}, errors.New(fmt.Sprintf("Unsupported gcs credential type [%s]", credential.Type))
+	}
+	response, err := this.client.PostForm(tokenURL, form)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", time.Time{}, errors.New(fmt.Sprintf("Failed to get gcs access token: %s", response.Status))
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return "", time.Time{}, err
+	}
+	// Done
+	return result.AccessToken, time.Now().Add(time.Duration(result.ExpiresIn) * time.Second), nil
+}
+
+// Create the signed jwt assertion of the service account
+func signGCSAssertion(credential *gcsCredential, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(credential.PrivateKey))
+	if block == nil {
+		return "", errors.New("Invalid private key of gcs service account")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return "", err
+		}
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("Private key of gcs service account is not a rsa key")
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   credential.ClientEmail,
+		"scope": gcsScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(gcsTokenLifetime).Unix(),
+	})
+	payload := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(payload))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
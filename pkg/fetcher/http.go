@@ -0,0 +1,195 @@
+// Author: lipixun
+// Created Time : 六 10/17 10:41:03 2026
+//
+// File Name: http.go
+// Description:
+//	Fetch http(s) urls
+//
+//	The cached content is revalidated by ETag and Last-Modified (saved in the cache as meta:<url>), and skipped if
+//	the expected checksum matches. An interrupted download is resumed by a range request with If-Range.
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	httpMetaKeyPrefix = "meta:"
+)
+
+// The validators of the http content
+type httpMeta struct {
+	ETag         string `json:"etag"`
+	LastModified string `json:"lastModified"`
+}
+
+// Fetch the url by the http requests
+// Parameters:
+//
+//	rawurl 			The url, used as the cache key
+//	digest 			The expected sha256 digest
+//	newRequest 		Create the (authorized) GET request of the content
+func (this *Fetcher) fetchHTTP(rawurl string, digest string, newRequest func() (*http.Request, error)) (string, error) {
+	cachedPath, matched, err := this.getCached(rawurl, digest)
+	if err != nil {
+		return "", err
+	}
+	if matched && digest != "" {
+		// The content is pinned by checksum, no need to revalidate
+		return cachedPath, nil
+	}
+	if this.ws.Offline {
+		if cachedPath == "" || (digest != "" && !matched) {
+			return "", &workspace.OfflineError{Resource: rawurl}
+		}
+		return cachedPath, nil
+	}
+	var meta httpMeta
+	if cachedPath != "" {
+		if data, ok, _ := this.cache.GetBytes(httpMetaKeyPrefix + rawurl); ok {
+			json.Unmarshal(data, &meta)
+		}
+	}
+	for retried := false; ; retried = true {
+		path, retry, err := this.downloadHTTP(rawurl, newRequest, cachedPath, meta)
+		if err != nil {
+			// The request is aborted on interrupt, the partial download is kept to resume
+			if canceled := this.ws.Canceled(); canceled != nil {
+				return "", canceled
+			}
+		}
+		if err != nil && cachedPath != "" && !retry {
+			this.logger.LeveledPrintf(log.LevelWarn, "Failed to revalidate [%s], use the cached content, error: %s\n", rawurl, err)
+			return cachedPath, nil
+		}
+		if retry && !retried {
+			continue
+		}
+		return path, err
+	}
+}
+
+// Download the url, the cached content is revalidated if the cached path is not empty
+// Returns:
+//
+//	The content path and whether should retry (the partial download is discarded)
+func (this *Fetcher) downloadHTTP(rawurl string, newRequest func() (*http.Request, error), cachedPath string, meta httpMeta) (string, bool, error) {
+	request, err := newRequest()
+	if err != nil {
+		return "", false, err
+	}
+	if cachedPath != "" {
+		if meta.ETag != "" {
+			request.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			request.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	// Resume the partial download
+	hash := sha256.Sum256([]byte(rawurl))
+	partPath := filepath.Join(this.downloadPath, hex.EncodeToString(hash[:16])+".part")
+	var partMeta httpMeta
+	if data, err := ioutil.ReadFile(partPath + ".json"); err == nil {
+		json.Unmarshal(data, &partMeta)
+	}
+	validator := partMeta.ETag
+	if validator == "" {
+		validator = partMeta.LastModified
+	}
+	if info, err := os.Stat(partPath); err == nil && info.Size() > 0 && validator != "" {
+		this.logger.LeveledPrintf(log.LevelDebug, "Resume download [%s] from %d bytes\n", rawurl, info.Size())
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", info.Size()))
+		request.Header.Set("If-Range", validator)
+	}
+	response, err := this.client.Do(request)
+	if err != nil {
+		return "", false, err
+	}
+	defer response.Body.Close()
+	flag := os.O_WRONLY | os.O_CREATE
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		if cachedPath == "" {
+			return "", false, errors.New("Unexpected not modified response")
+		}
+		this.logger.LeveledPrintf(log.LevelDebug, "Cached content of [%s] is not modified\n", rawurl)
+		return cachedPath, false, nil
+	case http.StatusOK:
+		flag |= os.O_TRUNC
+	case http.StatusPartialContent:
+		flag |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partPath)
+		os.Remove(partPath + ".json")
+		return "", true, errors.New(response.Status)
+	default:
+		return "", false, errors.New(response.Status)
+	}
+	// Download to the partial file
+	this.logger.LeveledPrintf(log.LevelInfo, "Download [%s]\n", rawurl)
+	responseMeta := httpMeta{ETag: response.Header.Get("ETag"), LastModified: response.Header.Get("Last-Modified")}
+	if response.StatusCode == http.StatusOK {
+		data, _ := json.Marshal(responseMeta)
+		if err := ioutil.WriteFile(partPath+".json", data, 0666); err != nil {
+			return "", false, err
+		}
+	}
+	file, err := os.OpenFile(partPath, flag, 0666)
+	if err != nil {
+		return "", false, err
+	}
+	_, err = io.Copy(file, response.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", false, err
+	}
+	// Put into the cache
+	file, err = os.Open(partPath)
+	if err != nil {
+		return "", false, err
+	}
+	_, err = this.cache.Put(rawurl, file)
+	file.Close()
+	if err != nil {
+		return "", false, err
+	}
+	os.Remove(partPath)
+	os.Remove(partPath + ".json")
+	if data, err := json.Marshal(responseMeta); err == nil {
+		if _, err := this.cache.PutBytes(httpMetaKeyPrefix+rawurl, data); err != nil {
+			this.logger.LeveledPrintf(log.LevelWarn, "Failed to cache the meta of [%s], error: %s\n", rawurl, err)
+		}
+	}
+	path, ok, err := this.cache.Get(rawurl)
+	if err != nil {
+		return "", false, err
+	} else if !ok {
+		return "", false, errors.New("Content evicted from cache")
+	}
+	// Done
+	return path, false, nil
+}
+
+// Create the GET request of the http url authorized by the workspace credential
+func (this *Fetcher) newHTTPRequest(rawurl string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(this.ws.Context(), http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	this.authorize(request)
+	return request, nil
+}
@@ -0,0 +1,314 @@
+// Author: lipixun
+// Created Time : 五 10/16 16:31:45 2026
+//
+// File Name: http_test.go
+// Description:
+//
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Create the workspace of the directories under dir, the workspaces of the same dir share the caches
+func newTestWorkspace(t *testing.T, dir string) (*workspace.Workspace, *log.CaptureLogger) {
+	options := workspace.NewWorkspaceOptions()
+	options.Dir.GlobalPath = filepath.Join(dir, "global")
+	options.Dir.UserPath = filepath.Join(dir, "user")
+	options.Dir.ProjectPath = filepath.Join(dir, "project")
+	options.LogFile = false
+	options.EnableColor = false
+	for _, path := range []string{options.Dir.GlobalPath, options.Dir.UserPath, options.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := workspace.New(options, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws, logger
+}
+
+// Create the fetcher of a new workspace of the dir
+func newTestFetcher(t *testing.T, dir string) (*Fetcher, *workspace.Workspace, *log.CaptureLogger) {
+	ws, logger := newTestWorkspace(t, dir)
+	fetcher, err := New(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fetcher, ws, logger
+}
+
+// Get the sha256 digest (hex) of the content
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetchHTTPCancel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fetcher-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	content := strings.Repeat("0123456789", 1000)
+	half := len(content) / 2
+	var lock sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		lock.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", "bytes "+strings.TrimPrefix(r.Header.Get("Range"), "bytes=")+"9999/10000")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(content[half:]))
+			return
+		}
+		// Send the first half, then hang until the client is gone
+		w.Header().Set("Content-Length", "10000")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content[:half]))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+	// Canceled in the middle of the download
+	fetcher, ws, _ := newTestFetcher(t, dir)
+	downloadPath := fetcher.downloadPath
+	go func() {
+		// Cancel once the first half is written to the partial file
+		for i := 0; i < 500; i++ {
+			if paths, _ := filepath.Glob(filepath.Join(downloadPath, "*.part")); len(paths) == 1 {
+				if info, err := os.Stat(paths[0]); err == nil && info.Size() == int64(half) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		ws.Cancel()
+	}()
+	done := make(chan error, 1)
+	go func() {
+		_, err := fetcher.Fetch(server.URL+"/file", "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !workspace.IsCanceledError(err) {
+			t.Fatalf("Incorrect error of the canceled download. Expect CanceledError Actual [%v]", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The download is not aborted by cancel")
+	}
+	// Resumed by the next run
+	fetcher, _, _ = newTestFetcher(t, dir)
+	path, err := fetcher.Fetch(server.URL+"/file", sha256Hex(content))
+	if err != nil {
+		t.Fatalf("Failed to resume the download, error: %s", err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != content {
+		t.Errorf("Incorrect content of the resumed download, %d bytes", len(data))
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(ranges) != 2 || ranges[1] != "bytes=5000-" {
+		t.Errorf("Incorrect range requests. Expect [ bytes=5000-] Actual %v", ranges)
+	}
+}
+
+func TestFetchHTTPRevalidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fetcher-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var lock sync.Mutex
+	content, etag, failed := "version 1", `"v1"`, false
+	var requests []string // The validators of the requests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests = append(requests, r.Header.Get("If-None-Match")+"|"+r.Header.Get("If-Modified-Since"))
+		if failed {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Fri, 16 Oct 2026 10:00:00 GMT")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	rawurl := server.URL + "/file"
+	fetcher, ws, logger := newTestFetcher(t, dir)
+	for _, tCase := range []struct {
+		Name     string
+		Change   func()
+		Digest   string
+		Content  string
+		Requests []string // The validators of the requests, nil if not requested
+		Download bool
+		Error    string
+	}{
+		{Name: "download", Content: "version 1", Requests: []string{"|"}, Download: true},
+		{Name: "not modified", Content: "version 1", Requests: []string{`"v1"|Fri, 16 Oct 2026 10:00:00 GMT`}},
+		{Name: "pinned", Digest: sha256Hex("version 1"), Content: "version 1"},
+		{
+			Name:     "modified",
+			Change:   func() { content, etag = "version 2", `"v2"` },
+			Content:  "version 2",
+			Requests: []string{`"v1"|Fri, 16 Oct 2026 10:00:00 GMT`},
+			Download: true,
+		},
+		{Name: "revalidate failed", Change: func() { failed = true }, Content: "version 2", Requests: []string{`"v2"|Fri, 16 Oct 2026 10:00:00 GMT`}},
+		{
+			Name:     "checksum mismatch",
+			Change:   func() { failed = false },
+			Digest:   sha256Hex("version 1"),
+			Requests: []string{`"v2"|Fri, 16 Oct 2026 10:00:00 GMT`},
+			Error:    "Checksum mismatch",
+		},
+		// The mismatched content is discarded
+		{Name: "download again", Content: "version 2", Requests: []string{"|"}, Download: true},
+	} {
+		lock.Lock()
+		if tCase.Change != nil {
+			tCase.Change()
+		}
+		requests = nil
+		lock.Unlock()
+		logger.Reset()
+		path, err := fetcher.Fetch(rawurl, tCase.Digest)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+		} else if err != nil {
+			t.Errorf("Failed to fetch case [%s], error: %s", tCase.Name, err)
+		} else if data, _ := ioutil.ReadFile(path); string(data) != tCase.Content {
+			t.Errorf("Incorrect content of case [%s]. Expect [%s] Actual [%s]", tCase.Name, tCase.Content, data)
+		}
+		lock.Lock()
+		if !reflect.DeepEqual(requests, tCase.Requests) {
+			t.Errorf("Incorrect requests of case [%s]. Expect %q Actual %q", tCase.Name, tCase.Requests, requests)
+		}
+		lock.Unlock()
+		if downloaded := logger.Contains(log.LevelInfo, "Download ["); downloaded != tCase.Download {
+			t.Errorf("Incorrect download of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Download, downloaded)
+		}
+	}
+	// Offline, the cached content is used without revalidation
+	ws.Offline = true
+	lock.Lock()
+	requests = nil
+	lock.Unlock()
+	if path, err := fetcher.Fetch(rawurl, ""); err != nil {
+		t.Errorf("Failed to fetch the cached content offline, error: %s", err)
+	} else if data, _ := ioutil.ReadFile(path); string(data) != "version 2" {
+		t.Errorf("Incorrect content offline. Expect [version 2] Actual [%s]", data)
+	}
+	for _, digest := range []string{"", sha256Hex("version 1")} {
+		target := server.URL + "/missing"
+		if digest != "" {
+			target = rawurl
+		}
+		if _, err := fetcher.Fetch(target, digest); !workspace.IsOfflineError(err) {
+			t.Errorf("Incorrect error of [%s] with digest [%s] offline. Expect OfflineError Actual [%v]", target, digest, err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Errorf("Requested offline %q", requests)
+	}
+}
+
+func TestFetchHTTPResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	for _, tCase := range []struct {
+		Name     string
+		Part     string // The partial download
+		PartMeta string // The validators of the partial download
+		ETag     string // The current etag of the content
+		Ranges   []string
+	}{
+		{Name: "resumed", Part: content[:300], PartMeta: `{"etag": "\"v1\""}`, ETag: `"v1"`, Ranges: []string{`bytes=300-|"v1"`}},
+		{
+			Name:     "resumed by last modified",
+			Part:     content[:300],
+			PartMeta: `{"lastModified": "Fri, 16 Oct 2026 10:00:00 GMT"}`,
+			Ranges:   []string{"bytes=300-|Fri, 16 Oct 2026 10:00:00 GMT"},
+		},
+		// If-Range mismatched, the whole content is sent
+		{Name: "changed", Part: "abcdefghij", PartMeta: `{"etag": "\"v0\""}`, ETag: `"v1"`, Ranges: []string{`bytes=10-|"v0"`}},
+		{Name: "no validator", Part: "abcdefghij", PartMeta: `{}`, ETag: `"v1"`, Ranges: []string{"|"}},
+		// The partial download larger than the content, discarded and downloaded again
+		{Name: "not satisfiable", Part: content + "abc", PartMeta: `{"etag": "\"v1\""}`, ETag: `"v1"`, Ranges: []string{`bytes=1003-|"v1"`, "|"}},
+	} {
+		func() {
+			dir, err := ioutil.TempDir("", "fetcher-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			var lock sync.Mutex
+			var ranges []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				ranges = append(ranges, r.Header.Get("Range")+"|"+r.Header.Get("If-Range"))
+				lock.Unlock()
+				if tCase.ETag != "" {
+					w.Header().Set("ETag", tCase.ETag)
+				}
+				w.Header().Set("Last-Modified", "Fri, 16 Oct 2026 10:00:00 GMT")
+				http.ServeContent(w, r, "file", time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), strings.NewReader(content))
+			}))
+			defer server.Close()
+			rawurl := server.URL + "/file"
+			fetcher, _, _ := newTestFetcher(t, dir)
+			hash := sha256.Sum256([]byte(rawurl))
+			partPath := filepath.Join(fetcher.downloadPath, hex.EncodeToString(hash[:16])+".part")
+			if err := ioutil.WriteFile(partPath, []byte(tCase.Part), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(partPath+".json", []byte(tCase.PartMeta), 0644); err != nil {
+				t.Fatal(err)
+			}
+			path, err := fetcher.Fetch(rawurl, sha256Hex(content))
+			if err != nil {
+				t.Fatalf("Failed to fetch case [%s], error: %s", tCase.Name, err)
+			}
+			if data, _ := ioutil.ReadFile(path); string(data) != content {
+				t.Errorf("Incorrect content of case [%s], %d bytes", tCase.Name, len(data))
+			}
+			if !reflect.DeepEqual(ranges, tCase.Ranges) {
+				t.Errorf("Incorrect range requests of case [%s]. Expect %q Actual %q", tCase.Name, tCase.Ranges, ranges)
+			}
+			// The partial download is removed
+			if paths, _ := filepath.Glob(filepath.Join(fetcher.downloadPath, "*")); len(paths) != 0 {
+				t.Errorf("The partial download of case [%s] is not removed: %v", tCase.Name, paths)
+			}
+		}()
+	}
+}
@@ -0,0 +1,483 @@
+// Author: lipixun
+// Created Time : 六 10/17 13:41:38 2026
+//
+// File Name: oci.go
+// Description:
+//	Pull, push and resolve the oci:// urls by the oci distribution api
+//
+//	An artifact is an oci manifest with exactly one layer (the file), the config is the empty descriptor. The docker
+//	images (multiple layers) can be resolved to digests but are pulled and pushed by the docker daemon.
+//
+//	The registry is accessed by https, or plain http if listed in the oci.insecure config (only accepted from the user
+//	and global config, a checked out repository shouldn't downgrade the registry traffic). The registry credential is
+//	found by GetRegistryCredential (see registry.go), sent as the basic auth or exchanged to a bearer token according
+//	to the challenge of the registry.
+package fetcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	OCIManifestMediaType        = "application/vnd.oci.image.manifest.v1+json"
+	OCIIndexMediaType           = "application/vnd.oci.image.index.v1+json"
+	OCIEmptyMediaType           = "application/vnd.oci.empty.v1+json"
+	OCILayerMediaType           = "application/octet-stream"
+	DockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	DockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	OCITitleAnnotation = "org.opencontainers.image.title"
+
+	ociManifestKeyPrefix = "meta:oci-manifest:"
+	ociTokenLifetime     = time.Minute      // The token lifetime if not returned by the registry
+	ociTokenMargin       = 10 * time.Second // Refresh the token this duration before it expires
+)
+
+var (
+	ociManifestMediaTypes = []string{OCIManifestMediaType, OCIIndexMediaType, DockerManifestMediaType, DockerManifestListMediaType}
+	ociEmptyConfig        = []byte("{}")
+)
+
+// The oci content descriptor
+type OCIDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// The oci image manifest
+type OCIManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Config        OCIDescriptor   `json:"config"`
+	Layers        []OCIDescriptor `json:"layers"`
+}
+
+// The authentication challenge of the registry
+type ociChallenge struct {
+	Scheme  string // basic or bearer, empty if not required
+	Realm   string
+	Service string
+}
+
+type ociToken struct {
+	Token  string
+	Expiry time.Time
+}
+
+// Resolve the oci url to the manifest digest, the digest is returned directly if pinned
+func (this *Fetcher) Resolve(rawurl string) (string, error) {
+	u, err := uri.ParseOCI(this.rewrite(rawurl))
+	if err != nil {
+		return "", err
+	}
+	if u.Digest != "" {
+		return u.Digest, nil
+	}
+	if this.ws.Offline {
+		return "", &workspace.OfflineError{Resource: rawurl}
+	}
+	request, err := this.newOCIRequest(http.MethodHead, u, "manifests/"+u.Reference(), false)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Accept", strings.Join(ociManifestMediaTypes, ","))
+	response, err := this.client.Do(request)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Failed to resolve [%s], error: %s", rawurl, err))
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", errors.New(fmt.Sprintf("Failed to resolve [%s]: %s", rawurl, response.Status))
+	}
+	if digest := response.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	// The registry does not return the digest header, compute by the manifest
+	_, digest, err := this.getOCIManifest(u)
+	return digest, err
+}
+
+// Fetch the oci artifact (the single layer of the manifest)
+func (this *Fetcher) fetchOCI(rawurl string) (string, error) {
+	u, err := uri.ParseOCI(rawurl)
+	if err != nil {
+		return "", err
+	}
+	manifest, _, err := this.getOCIManifest(u)
+	if err != nil {
+		return "", err
+	}
+	if manifest.MediaType == OCIIndexMediaType || manifest.MediaType == DockerManifestListMediaType {
+		return "", errors.New(fmt.Sprintf("[%s] is an image index rather than an artifact", rawurl))
+	}
+	if len(manifest.Layers) != 1 {
+		return "", errors.New(fmt.Sprintf("[%s] has %d layers, an artifact should have exactly one layer (images are pulled by docker)", rawurl, len(manifest.Layers)))
+	}
+	layer := manifest.Layers[0]
+	if !strings.HasPrefix(layer.Digest, "sha256:") {
+		return "", errors.New(fmt.Sprintf("Unsupported digest [%s] of [%s]", layer.Digest, rawurl))
+	}
+	// The blob is content addressed, cached by the blob url and never revalidated
+	blobURL := fmt.Sprintf("%s://%s/%s@%s", uri.SchemeOCI, u.Registry, u.Repository, layer.Digest)
+	return this.fetchHTTP(blobURL, strings.TrimPrefix(layer.Digest, "sha256:"), func() (*http.Request, error) {
+		return this.newOCIRequest(http.MethodGet, u, "blobs/"+layer.Digest, false)
+	})
+}
+
+// Get the manifest of the oci url, the manifest of a pinned url is cached
+// Returns:
+//
+//	The manifest and its digest
+func (this *Fetcher) getOCIManifest(u *uri.OCIURI) (*OCIManifest, string, error) {
+	cacheKey := ociManifestKeyPrefix + u.String()
+	var data []byte
+	if u.Digest != "" {
+		data, _, _ = this.cache.GetBytes(cacheKey)
+	}
+	if data == nil && this.ws.Offline {
+		return nil, "", &workspace.OfflineError{Resource: u.String()}
+	}
+	if data == nil {
+		request, err := this.newOCIRequest(http.MethodGet, u, "manifests/"+u.Reference(), false)
+		if err != nil {
+			return nil, "", err
+		}
+		request.Header.Set("Accept", strings.Join(ociManifestMediaTypes, ","))
+		response, err := this.client.Do(request)
+		if err != nil {
+			return nil, "", errors.New(fmt.Sprintf("Failed to get manifest of [%s], error: %s", u, err))
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return nil, "", errors.New(fmt.Sprintf("Failed to get manifest of [%s]: %s", u, response.Status))
+		}
+		if data, err = ioutil.ReadAll(response.Body); err != nil {
+			return nil, "", err
+		}
+	}
+	hash := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(hash[:])
+	if u.Digest != "" && u.Digest != digest {
+		return nil, "", errors.New(fmt.Sprintf("Manifest digest mismatch of [%s]. Actual [%s]", u, digest))
+	}
+	var manifest OCIManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, "", errors.New(fmt.Sprintf("Failed to parse manifest of [%s], error: %s", u, err))
+	}
+	if u.Digest != "" {
+		if _, err := this.cache.PutBytes(cacheKey, data); err != nil {
+			this.logger.LeveledPrintf(log.LevelWarn, "Failed to cache the manifest of [%s], error: %s\n", u, err)
+		}
+	}
+	// Done
+	return &manifest, digest, nil
+}
+
+// Push the file as an oci artifact
+// Returns:
+//
+//	The manifest digest
+func (this *Fetcher) uploadOCI(path string, rawurl string) (string, error) {
+	u, err := uri.ParseOCI(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if u.Digest != "" {
+		return "", errors.New(fmt.Sprintf("Cannot push to the pinned url [%s]", rawurl))
+	}
+	// Push the blobs
+	layer, err := this.pushOCIBlob(u, path, nil)
+	if err != nil {
+		return "", err
+	}
+	layer.MediaType = OCILayerMediaType
+	layer.Annotations = map[string]string{OCITitleAnnotation: filepath.Base(path)}
+	config, err := this.pushOCIBlob(u, "", ociEmptyConfig)
+	if err != nil {
+		return "", err
+	}
+	config.MediaType = OCIEmptyMediaType
+	// Push the manifest
+	data, err := json.Marshal(OCIManifest{SchemaVersion: 2, MediaType: OCIManifestMediaType, Config: *config, Layers: []OCIDescriptor{*layer}})
+	if err != nil {
+		return "", err
+	}
+	request, err := this.newOCIRequest(http.MethodPut, u, "manifests/"+u.Reference(), true)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", OCIManifestMediaType)
+	if err := this.doOCIUpload(request, bytes.NewReader(data), int64(len(data)), http.StatusCreated); err != nil {
+		return "", errors.New(fmt.Sprintf("Failed to push manifest of [%s], error: %s", rawurl, err))
+	}
+	hash := sha256.Sum256(data)
+	// Done
+	return "sha256:" + hex.EncodeToString(hash[:]), nil
+}
+
+// Push the blob of the file (or the data if path is empty), skipped if the blob exists
+func (this *Fetcher) pushOCIBlob(u *uri.OCIURI, path string, data []byte) (*OCIDescriptor, error) {
+	var reader io.ReadSeeker
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		reader = bytes.NewReader(data)
+	}
+	// Compute the digest
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	descriptor := &OCIDescriptor{Digest: "sha256:" + hex.EncodeToString(hash.Sum(nil)), Size: size}
+	// Check if exists
+	request, err := this.newOCIRequest(http.MethodHead, u, "blobs/"+descriptor.Digest, true)
+	if err != nil {
+		return nil, err
+	}
+	response, err := this.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode == http.StatusOK {
+		this.logger.LeveledPrintf(log.LevelDebug, "Blob [%s] exists in [%s/%s]\n", descriptor.Digest, u.Registry, u.Repository)
+		return descriptor, nil
+	}
+	// Start the upload session
+	request, err = this.newOCIRequest(http.MethodPost, u, "blobs/uploads/", true)
+	if err != nil {
+		return nil, err
+	}
+	response, err = this.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		return nil, errors.New(fmt.Sprintf("Failed to start blob upload to [%s]: %s", u, response.Status))
+	}
+	location, err := request.URL.Parse(response.Header.Get("Location"))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid blob upload location of [%s], error: %s", u, err))
+	}
+	query := location.Query()
+	query.Set("digest", descriptor.Digest)
+	location.RawQuery = query.Encode()
+	// Upload the blob monolithically
+	request, err = this.newOCIRequest(http.MethodPut, u, "", true)
+	if err != nil {
+		return nil, err
+	}
+	request.URL = location
+	request.Host = location.Host
+	request.Header.Set("Content-Type", OCILayerMediaType)
+	if path != "" {
+		this.logger.LeveledPrintf(log.LevelInfo, "Push [%s] to [%s/%s]\n", path, u.Registry, u.Repository)
+	}
+	if err := this.doOCIUpload(request, reader, size, http.StatusCreated); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to push blob [%s] to [%s], error: %s", descriptor.Digest, u, err))
+	}
+	// Done
+	return descriptor, nil
+}
+
+func (this *Fetcher) doOCIUpload(request *http.Request, body io.Reader, size int64, expectedStatus int) error {
+	request.Body = ioutil.NopCloser(body)
+	request.ContentLength = size
+	response, err := this.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != expectedStatus {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+		return errors.New(fmt.Sprintf("%s %s", response.Status, strings.TrimSpace(string(message))))
+	}
+	return nil
+}
+
+// Create the authorized request of the registry api /v2/<repository>/<path>
+func (this *Fetcher) newOCIRequest(method string, u *uri.OCIURI, path string, push bool) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(this.ws.Context(), method, fmt.Sprintf("%s/v2/%s/%s", this.getOCIBaseURL(u.Registry), u.Repository, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := this.getOCIChallenge(u.Registry)
+	if err != nil {
+		return nil, err
+	}
+	switch challenge.Scheme {
+	case "basic":
+		credential, err := GetRegistryCredential(this.ws, u.Registry)
+		if err != nil {
+			return nil, err
+		}
+		if credential != nil {
+			request.SetBasicAuth(credential.Username, credential.Secret)
+		}
+	case "bearer":
+		token, err := this.getOCIToken(u, challenge, push)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request, nil
+}
+
+func (this *Fetcher) getOCIBaseURL(registry string) string {
+	if IsDockerHub(registry) {
+		registry = DockerHubAPIHost
+	}
+	for _, insecure := range strings.Split(this.ws.GetUserConfigString(workspace.ConfigKeyOCIInsecure), ",") {
+		if strings.TrimSpace(insecure) == registry {
+			return "http://" + registry
+		}
+	}
+	return "https://" + registry
+}
+
+// Get the authentication challenge of the registry by the api version check
+func (this *Fetcher) getOCIChallenge(registry string) (*ociChallenge, error) {
+	this.lock.Lock()
+	challenge := this.ociChallenges[registry]
+	this.lock.Unlock()
+	if challenge != nil {
+		return challenge, nil
+	}
+	response, err := this.client.Get(this.getOCIBaseURL(registry) + "/v2/")
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to connect to registry [%s], error: %s", registry, err))
+	}
+	response.Body.Close()
+	challenge = new(ociChallenge)
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		challenge = parseOCIChallenge(response.Header.Get("WWW-Authenticate"))
+	default:
+		return nil, errors.New(fmt.Sprintf("Unexpected response of registry [%s]: %s", registry, response.Status))
+	}
+	this.lock.Lock()
+	this.ociChallenges[registry] = challenge
+	this.lock.Unlock()
+	// Done
+	return challenge, nil
+}
+
+// Parse the WWW-Authenticate header, e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseOCIChallenge(header string) *ociChallenge {
+	challenge := new(ociChallenge)
+	idx := strings.Index(header, " ")
+	if idx == -1 {
+		challenge.Scheme = strings.ToLower(header)
+		return challenge
+	}
+	challenge.Scheme = strings.ToLower(header[:idx])
+	for _, param := range strings.Split(header[idx+1:], ",") {
+		parts := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.Trim(parts[1], "\"")
+		switch strings.ToLower(parts[0]) {
+		case "realm":
+			challenge.Realm = value
+		case "service":
+			challenge.Service = value
+		}
+	}
+	return challenge
+}
+
+// Get the bearer token of the repository
+func (this *Fetcher) getOCIToken(u *uri.OCIURI, challenge *ociChallenge, push bool) (string, error) {
+	scope := fmt.Sprintf("repository:%s:pull", u.Repository)
+	if push {
+		scope += ",push"
+	}
+	key := challenge.Realm + "|" + challenge.Service + "|" + scope
+	this.lock.Lock()
+	token := this.ociTokens[key]
+	this.lock.Unlock()
+	if token != nil && time.Now().Before(token.Expiry) {
+		return token.Token, nil
+	}
+	// Request a new token
+	tokenURL, err := url.Parse(challenge.Realm)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Invalid token realm [%s] of registry [%s], error: %s", challenge.Realm, u.Registry, err))
+	}
+	query := tokenURL.Query()
+	if challenge.Service != "" {
+		query.Set("service", challenge.Service)
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+	request, err := http.NewRequestWithContext(this.ws.Context(), http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	credential, err := GetRegistryCredential(this.ws, u.Registry)
+	if err != nil {
+		return "", err
+	}
+	if credential != nil {
+		request.SetBasicAuth(credential.Username, credential.Secret)
+	}
+	response, err := this.client.Do(request)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Failed to get token of registry [%s], error: %s", u.Registry, err))
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", errors.New(fmt.Sprintf("Failed to get token of registry [%s]: %s", u.Registry, response.Status))
+	}
+	var result struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	token = &ociToken{Token: result.Token, Expiry: time.Now().Add(ociTokenLifetime)}
+	if token.Token == "" {
+		token.Token = result.AccessToken
+	}
+	if result.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - ociTokenMargin)
+	}
+	this.lock.Lock()
+	this.ociTokens[key] = token
+	this.lock.Unlock()
+	// Done
+	return token.Token, nil
+}
@@ -0,0 +1,107 @@
+// Author: lipixun
+// Created Time : 六 10/17 13:26:09 2026
+//
+// File Name: registry.go
+// Description:
+//	The credentials of the container (oci) registries
+//
+//	The credential of a registry is read from the docker config file defined by the docker.registry.auth config, or
+//	the workspace credentials (op login <registry>, docker.io for docker hub). docker.registry.auth is only accepted
+//	from the user and global config, a checked out repository shouldn't choose the file the credentials are read from
+package fetcher
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	DockerHubRegistry = "https://index.docker.io/v1/" // The registry of docker hub in the docker config file
+	DockerHubHost     = "docker.io"                   // The host of docker hub in the workspace credentials
+	DockerHubAPIHost  = "registry-1.docker.io"        // The host of the registry api of docker hub
+)
+
+// Check if the registry host is docker hub
+func IsDockerHub(host string) bool {
+	switch host {
+	case DockerHubHost, "index.docker.io", DockerHubAPIHost:
+		return true
+	}
+	return false
+}
+
+// Get the server address of the registry host, which is the key in the docker config file
+func GetRegistryServerAddress(host string) string {
+	if IsDockerHub(host) {
+		return DockerHubRegistry
+	}
+	return host
+}
+
+// Get the credential of the registry host, nil if not found
+// The credential helper of the host is used first, then the docker config file, then the workspace credential store
+func GetRegistryCredential(ws *workspace.Workspace, host string) (*workspace.Credential, error) {
+	if IsDockerHub(host) {
+		host = DockerHubHost
+	}
+	// Get by the credential helper
+	if credential, err := ws.GetHelperCredential(host); err != nil || credential != nil {
+		return credential, err
+	}
+	// Read the docker config file
+	if path := ws.GetUserConfigString(workspace.ConfigKeyDockerRegistryAuth); path != "" {
+		username, password, err := readDockerConfigAuth(path, GetRegistryServerAddress(host))
+		if err != nil {
+			return nil, err
+		}
+		if username != "" {
+			return &workspace.Credential{Host: host, Username: username, Secret: password}, nil
+		}
+	}
+	// Get from workspace credentials
+	credential, err := ws.Credentials().Get(host)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to get credential of [%s], error: %s", host, err))
+	}
+	// Done
+	return credential, nil
+}
+
+// Read the username and password of the registry from the docker config file, empty if not found
+func readDockerConfigAuth(path, registry string) (string, string, error) {
+	path, err := util.GetRealPath(path)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", errors.New(fmt.Sprintf("Failed to read docker registry auth file [%s], error: %s", path, err))
+	}
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", "", errors.New(fmt.Sprintf("Failed to parse docker registry auth file [%s], error: %s", path, err))
+	}
+	entry, ok := config.Auths[registry]
+	if !ok {
+		return "", "", nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return "", "", errors.New(fmt.Sprintf("Invalid auth of registry [%s] in [%s], error: %s", registry, path, err))
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", "", errors.New(fmt.Sprintf("Invalid auth of registry [%s] in [%s]", registry, path))
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,244 @@
+// Author: lipixun
+// Created Time : 六 10/17 11:52:37 2026
+//
+// File Name: s3.go
+// Description:
+//	Fetch and upload s3:// urls
+//
+//	The requests are signed by AWS signature version 4 with the path style url <endpoint>/<bucket>/<key>
+//
+//	The credential is looked up in order:
+//		AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN
+//		The profile AWS_PROFILE (default if not set) of the shared credentials file
+//		(AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials)
+//	The request is not signed if no credential is found (a public bucket)
+//
+//	The region is looked up in order: the s3.region config, AWS_REGION, AWS_DEFAULT_REGION, the profile of the shared
+//	config file (AWS_CONFIG_FILE or ~/.aws/config), us-east-1
+//	The endpoint is the s3.endpoint config (e.g. http://localhost:9000 for a minio server), https://s3.<region>.amazonaws.com
+//	if not set
+//	s3.region and s3.endpoint are only accepted from the user and global config since they decide where the signed
+//	requests are sent
+package fetcher
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion   = "us-east-1"
+	s3DefaultProfile  = "default"
+	s3Service         = "s3"
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+)
+
+// The aws credential
+type awsCredential struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Get the aws credential from the environment variables or the shared credentials file, nil if not found
+func getAWSCredential() (*awsCredential, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return &awsCredential{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		path = filepath.Join(os.Getenv("HOME"), ".aws", "credentials")
+	}
+	values, err := readAWSProfile(path, getAWSProfile())
+	if err != nil || values == nil {
+		return nil, err
+	}
+	if values["aws_access_key_id"] == "" || values["aws_secret_access_key"] == "" {
+		return nil, errors.New(fmt.Sprintf("Incomplete credential of profile [%s] in [%s]", getAWSProfile(), path))
+	}
+	return &awsCredential{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}, nil
+}
+
+// Get the s3 region
+func getS3Region(ws *workspace.Workspace) string {
+	if region := ws.GetUserConfigString(workspace.ConfigKeyS3Region); region != "" {
+		return region
+	}
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return region
+		}
+	}
+	path := os.Getenv("AWS_CONFIG_FILE")
+	if path == "" {
+		path = filepath.Join(os.Getenv("HOME"), ".aws", "config")
+	}
+	// The profile section is [profile <name>] in the config file except the default one
+	section := getAWSProfile()
+	if section != s3DefaultProfile {
+		section = "profile " + section
+	}
+	if values, _ := readAWSProfile(path, section); values["region"] != "" {
+		return values["region"]
+	}
+	return s3DefaultRegion
+}
+
+func getAWSProfile() string {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return s3DefaultProfile
+}
+
+// Read the section of the aws ini file, nil if the file or section not found
+func readAWSProfile(path string, section string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	var values map[string]string
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if current == section && values == nil {
+				values = make(map[string]string)
+			}
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if idx := strings.Index(line, "="); idx != -1 {
+			values[strings.ToLower(strings.TrimSpace(line[:idx]))] = strings.TrimSpace(line[idx+1:])
+		}
+	}
+	// Done
+	return values, scanner.Err()
+}
+
+// Create the signed s3 request of the object
+func (this *Fetcher) newS3Request(method string, object *uri.ObjectURI) (*http.Request, error) {
+	region := getS3Region(this.ws)
+	endpoint := this.ws.GetUserConfigString(workspace.ConfigKeyS3Endpoint)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid s3 endpoint [%s], error: %s", endpoint, err))
+	}
+	u.Path = fmt.Sprintf("%s/%s/%s", u.Path, object.Bucket, object.Key)
+	u.RawPath = escapeS3Path(u.Path)
+	request, err := http.NewRequestWithContext(this.ws.Context(), method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := getAWSCredential()
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil {
+		signS3Request(request, credential, region, time.Now())
+	}
+	return request, nil
+}
+
+// Sign the request by aws signature version 4, the payload is not signed
+// NOTE: The headers set after signing (e.g. Range, If-None-Match) are not signed, which is allowed by s3
+func signS3Request(request *http.Request, credential *awsCredential, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(s3TimeFormat)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	if credential.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", credential.SessionToken)
+	}
+	// The canonical headers
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders string
+	for _, name := range names {
+		canonicalHeaders += fmt.Sprintf("%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+	// The canonical request
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		escapeS3Path(request.URL.Path),
+		strings.Replace(request.URL.Query().Encode(), "+", "%20", -1),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", now.Format("20060102"), region, s3Service)
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+	// The signing key
+	key := hmacSHA256([]byte("AWS4"+credential.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, credential.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// Escape the path as the aws canonical uri, all bytes except the unreserved characters and slashes are escaped
+func escapeS3Path(path string) string {
+	var escaped []byte
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-._~/", c) != -1 {
+			escaped = append(escaped, c)
+		} else {
+			escaped = append(escaped, []byte(fmt.Sprintf("%%%02X", c))...)
+		}
+	}
+	return string(escaped)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,42 @@
+// Author: lipixun
+// Created Time : 五 10/16 21:38:02 2026
+//
+// File Name: caller.go
+// Description:
+//	Get the caller of the log entry
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+var (
+	// The function name prefix of this package, e.g. github.com/ops-openlight/openlight/pkg/log.
+	packageFuncPrefix = getPackageFuncPrefix()
+)
+
+func getPackageFuncPrefix() string {
+	name := runtime.FuncForPC(reflect.ValueOf(New).Pointer()).Name()
+	return strings.TrimSuffix(name, "New")
+}
+
+// Get the file:line of the first caller outside this package. The wrappers (e.g. Printf --> LeveledHeadedPrintf)
+// and the line writer are in this package, so the skip depth is not fixed
+func getCaller() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packageFuncPrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Author: lipixun
+// Created Time : 五 10/16 21:02:33 2026
+//
+// File Name: capture.go
+// Description:
+//	The capture logger which records the entries in memory, used by tests to assert the logged messages
+package log
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// The logger records all entries (regardless of the level)
+type CaptureLogger struct {
+	Logger
+	*CaptureSink
+}
+
+func NewCaptureLogger() *CaptureLogger {
+	logger := New(ioutil.Discard, LevelInfo, LevelInfo, "")
+	sink := NewCaptureSink(LevelAll)
+	logger.AddSink(sink)
+	return &CaptureLogger{Logger: logger, CaptureSink: sink}
+}
+
+// The sink records the entries in memory
+type CaptureSink struct {
+	Level   int
+	entries []Entry
+	lock    sync.Mutex
+}
+
+func NewCaptureSink(level int) *CaptureSink {
+	return &CaptureSink{Level: level}
+}
+
+func (this *CaptureSink) MinLevel() int {
+	return this.Level
+}
+
+func (this *CaptureSink) Write(entry *Entry) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.entries = append(this.entries, *entry)
+	return nil
+}
+
+// Get all captured entries
+func (this *CaptureSink) Entries() []Entry {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return append([]Entry(nil), this.entries...)
+}
+
+// Get the captured entries of the level and above, with the header (any header if empty)
+func (this *CaptureSink) Filter(level int, header string) []Entry {
+	var entries []Entry
+	for _, entry := range this.Entries() {
+		if entry.Level >= level && (header == "" || entry.Header == header) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Check if any entry of the level and above contains the text
+func (this *CaptureSink) Contains(level int, text string) bool {
+	for _, entry := range this.Filter(level, "") {
+		if strings.Contains(entry.Message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear the captured entries
+func (this *CaptureSink) Reset() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.entries = nil
+}
@@ -0,0 +1,181 @@
+// Author: lipixun
+// Created Time : 五 10/16 16:41:12 2026
+//
+// File Name: entry.go
+// Description:
+//	The log entry and its formats
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	LevelNames = map[int]string{
+		LevelDebug:   "debug",
+		LevelInfo:    "info",
+		LevelWarn:    "warn",
+		LevelSuccess: "success",
+		LevelFail:    "fail",
+		LevelError:   "error",
+	}
+)
+
+// Get the name of the level, the number is returned if the level has no name
+func GetLevelName(level int) string {
+	if name, ok := LevelNames[level]; ok {
+		return name
+	}
+	return strconv.Itoa(level)
+}
+
+// A log entry, which is written by one print call
+type Entry struct {
+	Time    time.Time
+	Level   int
+	Header  string
+	Message string
+	Fields  map[string]interface{}
+	Caller  string // The file:line of the caller, empty if not recorded
+}
+
+// Get the field keys in sorted order
+func (this *Entry) FieldKeys() []string {
+	var keys []string
+	for key := range this.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// The json format of the entry
+type jsonEntry struct {
+	Time    string                 `json:"time"`
+	Level   string                 `json:"level"`
+	Header  string                 `json:"header,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Caller  string                 `json:"caller,omitempty"`
+}
+
+// Render the entry in the format of the options
+func (this *Options) Render(entry *Entry) string {
+	switch this.Format {
+	case FormatJson:
+		return this.formatJson(entry)
+	default:
+		return this.formatText(entry)
+	}
+}
+
+func (this *Options) formatText(entry *Entry) string {
+	var header string
+	if this.ShowTimestamp {
+		layout := this.TimeLayout
+		if layout == "" {
+			layout = DefaultTimeLayout
+		}
+		header += entry.Time.Format(layout) + " "
+	}
+	if this.ShowLevel {
+		header += fmt.Sprintf("[%s] ", strings.ToUpper(GetLevelName(entry.Level)))
+	}
+	if entry.Header != "" {
+		header += fmt.Sprintf(fmt.Sprintf("[%%-%ds] ", this.HeaderLength), ShortenHeader(entry.Header, this.HeaderLength))
+	}
+	if entry.Caller != "" {
+		header += fmt.Sprintf("(%s) ", entry.Caller)
+	}
+	// Append the fields as key=value before the tailing new line
+	message := strings.TrimSuffix(entry.Message, "\n")
+	newLine := entry.Message[len(message):]
+	for _, key := range entry.FieldKeys() {
+		value := fmt.Sprint(entry.Fields[key])
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		message += fmt.Sprintf(" %s=%s", key, value)
+	}
+	// Append the stack traces of the error fields (e.g. created by pkg/errors) when showing caller
+	if stacks := this.getErrorStacks(entry); len(stacks) > 0 {
+		if newLine == "" {
+			newLine = "\n"
+		}
+		for _, key := range entry.FieldKeys() {
+			if stack, ok := stacks[key]; ok {
+				newLine += fmt.Sprintf("\t%s stack trace:\n\t%s\n", key, strings.Replace(stack, "\n", "\n\t", -1))
+			}
+		}
+	}
+	if !this.EnableColor {
+		return header + message + newLine
+	}
+	// Color the header and the message (without the tailing new line)
+	headerColor, messageColor := NoColor, NoColor
+	if c, ok := this.ColorMapping[entry.Level]; ok {
+		headerColor, messageColor = c.HeaderColor, c.MessageColor
+	}
+	text := messageColor.SprintFunc()(message) + newLine
+	if header != "" {
+		text = headerColor.SprintFunc()(header) + text
+	}
+	return text
+}
+
+func (this *Options) formatJson(entry *Entry) string {
+	var fields map[string]interface{}
+	if len(entry.Fields) > 0 {
+		// Errors are marshaled as {} by json, use the message instead
+		fields = make(map[string]interface{})
+		for key, value := range entry.Fields {
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			fields[key] = value
+		}
+		for key, stack := range this.getErrorStacks(entry) {
+			fields[key+".stack"] = stack
+		}
+	}
+	data, err := json.Marshal(jsonEntry{
+		Time:    entry.Time.Format(time.RFC3339Nano),
+		Level:   GetLevelName(entry.Level),
+		Header:  entry.Header,
+		Message: strings.TrimSuffix(entry.Message, "\n"),
+		Fields:  fields,
+		Caller:  entry.Caller,
+	})
+	if err != nil {
+		// Should not happen
+		return fmt.Sprintf("{\"error\": %q}\n", err.Error())
+	}
+	return string(data) + "\n"
+}
+
+// The error with stack trace
+type stackTracer interface {
+	StackTrace() string
+}
+
+// Get the stack traces of the error fields if showing caller, key is the field key
+func (this *Options) getErrorStacks(entry *Entry) map[string]string {
+	if !this.ShowCaller {
+		return nil
+	}
+	var stacks map[string]string
+	for key, value := range entry.Fields {
+		if err, ok := value.(stackTracer); ok {
+			if stacks == nil {
+				stacks = make(map[string]string)
+			}
+			stacks[key] = err.StackTrace()
+		}
+	}
+	return stacks
+}
@@ -0,0 +1,118 @@
+// Author: lipixun
+// Created Time : 五 10/16 17:12:45 2026
+//
+// File Name: file.go
+// Description:
+//	The log file with size based rotation
+//
+//	When the size of the file exceeds the max size, the file is rotated:
+//		<filename>.<n-1> --> <filename>.<n> (the files beyond max backups are removed)
+//		<filename> --> <filename>.1
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	DefaultLogFileMaxSize    = 10 * 1024 * 1024
+	DefaultLogFileMaxBackups = 5
+)
+
+type RotatingFile struct {
+	filename   string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	lock       sync.Mutex
+}
+
+// Open the rotating file, the file is appended if exists
+func NewRotatingFile(filename string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		return nil, errors.New("Max size must be positive")
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+	f := &RotatingFile{filename: filename, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	// Done
+	return f, nil
+}
+
+func (this *RotatingFile) open() error {
+	file, err := os.OpenFile(this.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	this.file = file
+	this.size = info.Size()
+	return nil
+}
+
+func (this *RotatingFile) Write(p []byte) (int, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.file == nil {
+		return 0, errors.New("File closed")
+	}
+	if this.size > 0 && this.size+int64(len(p)) > this.maxSize {
+		if err := this.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := this.file.Write(p)
+	this.size += int64(n)
+	return n, err
+}
+
+func (this *RotatingFile) rotate() error {
+	if err := this.file.Close(); err != nil {
+		return err
+	}
+	this.file = nil
+	// Remove the oldest backup and shift the others
+	os.Remove(this.backupName(this.maxBackups))
+	for i := this.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(this.backupName(i), this.backupName(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if this.maxBackups > 0 {
+		if err := os.Rename(this.filename, this.backupName(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(this.filename); err != nil {
+		return err
+	}
+	// Done
+	return this.open()
+}
+
+func (this *RotatingFile) backupName(n int) string {
+	return fmt.Sprintf("%s.%d", this.filename, n)
+}
+
+func (this *RotatingFile) Close() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.file == nil {
+		return nil
+	}
+	err := this.file.Close()
+	this.file = nil
+	return err
+}
@@ -0,0 +1,55 @@
+// Author: lipixun
+// Created Time : 五 10/16 23:14:36 2026
+//
+// File Name: header.go
+// Description:
+//	The hierarchical headers
+//
+//	A child logger extends the header of its parent with a slash, e.g. Runner --> Runner/Instance-ab12cd. In text
+//	format, a header longer than Options.HeaderLength is shortened to keep the output aligned:
+//		1. Abbreviate the parent segments from left to right, each dotted part to its first letter, e.g.
+//		   SourceCode.Builder/Golang/Target-server --> S.B/G/Target-server
+//		2. Keep the tail of the header with a leading ~ if still too long
+package log
+
+import (
+	"strings"
+)
+
+const (
+	HeaderSeparator = "/"
+)
+
+// Get the header of the child
+func GetChildHeader(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + HeaderSeparator + name
+}
+
+// Shorten the header to the length, see the file description
+func ShortenHeader(header string, length int) string {
+	if length <= 0 || len(header) <= length {
+		return header
+	}
+	segments := strings.Split(header, HeaderSeparator)
+	for i := 0; i < len(segments)-1 && len(strings.Join(segments, HeaderSeparator)) > length; i++ {
+		segments[i] = abbreviateHeaderSegment(segments[i])
+	}
+	header = strings.Join(segments, HeaderSeparator)
+	if len(header) > length {
+		header = "~" + header[len(header)-length+1:]
+	}
+	return header
+}
+
+func abbreviateHeaderSegment(segment string) string {
+	parts := strings.Split(segment, ".")
+	for i, part := range parts {
+		if len(part) > 1 {
+			parts[i] = part[:1]
+		}
+	}
+	return strings.Join(parts, ".")
+}
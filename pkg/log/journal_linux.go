@@ -0,0 +1,81 @@
+// Author: lipixun
+// Created Time : 五 10/16 19:03:48 2026
+//
+// File Name: journal_linux.go
+// Description:
+//	The systemd journal sink, which sends the entries by the native journal protocol
+//
+//	Each entry is sent as one datagram of fields: MESSAGE, PRIORITY, SYSLOG_IDENTIFIER, OP_HEADER, OP_LEVEL and the
+//	entry fields (as OP_FIELD_<KEY>)
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	JournalSocketPath = "/run/systemd/journal/socket"
+)
+
+var (
+	journalFieldNameRegularExp = regexp.MustCompile("[^A-Z0-9_]")
+)
+
+// The sink writes the entries to the systemd journal
+type JournalSink struct {
+	Level      int
+	identifier string
+	conn       *net.UnixConn
+}
+
+// Create a new journal sink, the identifier is the SYSLOG_IDENTIFIER of entries, e.g. op
+func NewJournalSink(level int, identifier string) (*JournalSink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: JournalSocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournalSink{Level: level, identifier: identifier, conn: conn}, nil
+}
+
+func (this *JournalSink) MinLevel() int {
+	return this.Level
+}
+
+func (this *JournalSink) Write(entry *Entry) error {
+	var buffer bytes.Buffer
+	writeJournalField(&buffer, "MESSAGE", strings.TrimSuffix(entry.Message, "\n"))
+	writeJournalField(&buffer, "PRIORITY", fmt.Sprint(getSyslogPriority(entry.Level)))
+	writeJournalField(&buffer, "SYSLOG_IDENTIFIER", this.identifier)
+	writeJournalField(&buffer, "OP_LEVEL", GetLevelName(entry.Level))
+	if entry.Header != "" {
+		writeJournalField(&buffer, "OP_HEADER", entry.Header)
+	}
+	for _, key := range entry.FieldKeys() {
+		name := "OP_FIELD_" + journalFieldNameRegularExp.ReplaceAllString(strings.ToUpper(key), "_")
+		writeJournalField(&buffer, name, fmt.Sprint(entry.Fields[key]))
+	}
+	_, err := this.conn.Write(buffer.Bytes())
+	return err
+}
+
+func (this *JournalSink) Close() error {
+	return this.conn.Close()
+}
+
+// Write a field, the value with new lines is written in the binary form: name \n <64bit little endian length> value \n
+func writeJournalField(buffer *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(buffer, "%s=%s\n", name, value)
+		return
+	}
+	buffer.WriteString(name)
+	buffer.WriteByte('\n')
+	binary.Write(buffer, binary.LittleEndian, uint64(len(value)))
+	buffer.WriteString(value)
+	buffer.WriteByte('\n')
+}
@@ -0,0 +1,34 @@
+// Author: lipixun
+// Created Time : 五 10/16 19:20:14 2026
+//
+// File Name: journal_other.go
+// Description:
+//	The systemd journal is only available on linux
+
+// +build !linux
+
+package log
+
+import (
+	"errors"
+)
+
+type JournalSink struct {
+	Level int
+}
+
+func NewJournalSink(level int, identifier string) (*JournalSink, error) {
+	return nil, errors.New("Journal is only supported on linux")
+}
+
+func (this *JournalSink) MinLevel() int {
+	return this.Level
+}
+
+func (this *JournalSink) Write(entry *Entry) error {
+	return errors.New("Journal is only supported on linux")
+}
+
+func (this *JournalSink) Close() error {
+	return nil
+}
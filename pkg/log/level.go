@@ -0,0 +1,81 @@
+// Author: lipixun
+// Created Time : 五 10/16 17:48:20 2026
+//
+// File Name: level.go
+// Description:
+//	The level parsing and per header levels
+//
+//	The header levels are written as "header=level,header=level", e.g. "Runner=debug,Builder=warn"
+//	A header level applies to the header which:
+//		1. Equals to the name, e.g. Runner --> Runner
+//		2. Starts with the name and a dot or slash, e.g. Python --> Python.Nuitka, Runner --> Runner/Instance-ab12cd
+//		3. Ends with a dot and the name, e.g. Builder --> SourceCode.Builder, CLI.Builder
+//	The names are case insensitive, the longest matched name wins if more than one matches
+package log
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse the level from name (e.g. debug, warn) or number
+func ParseLevel(name string) (int, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for level, levelName := range LevelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	switch name {
+	case "all":
+		return LevelAll, nil
+	case "no", "none":
+		return LevelNo, nil
+	}
+	if level, err := strconv.Atoi(name); err == nil {
+		return level, nil
+	}
+	return 0, errors.New(fmt.Sprintf("Unknown log level [%s]", name))
+}
+
+// Parse the header levels
+func ParseHeaderLevels(value string) (map[string]int, error) {
+	levels := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		idx := strings.Index(item, "=")
+		if idx <= 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid header level [%s], should be header=level", item))
+		}
+		level, err := ParseLevel(item[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(item[:idx])] = level
+	}
+	return levels, nil
+}
+
+// Get the level of the header set in HeaderLevels
+func (this *Options) GetHeaderLevel(header string) (int, bool) {
+	if len(this.HeaderLevels) == 0 || header == "" {
+		return 0, false
+	}
+	header = strings.ToLower(header)
+	var matchedName string
+	var matchedLevel int
+	for name, level := range this.HeaderLevels {
+		lowerName := strings.ToLower(name)
+		if header == lowerName || strings.HasPrefix(header, lowerName+".") || strings.HasPrefix(header, lowerName+HeaderSeparator) || strings.HasSuffix(header, "."+lowerName) {
+			if len(name) > len(matchedName) || (len(name) == len(matchedName) && name < matchedName) {
+				matchedName, matchedLevel = name, level
+			}
+		}
+	}
+	return matchedLevel, matchedName != ""
+}
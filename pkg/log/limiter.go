@@ -0,0 +1,79 @@
+// Author: lipixun
+// Created Time : 五 10/16 22:52:07 2026
+//
+// File Name: limiter.go
+// Description:
+//	The rate limiter of identical messages
+//
+//	At most Options.RateLimit identical messages are written in one second window, the rest are suppressed. After the
+//	window, a notice "Suppressed N identical messages: <message>" is written before the next entry of the logger.
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	rateLimitWindow = time.Second
+)
+
+type limiter struct {
+	windows map[string]*limiterWindow // Key is header, level and message
+}
+
+type limiterWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	entry      Entry // The first entry of the window
+}
+
+func getLimiterKey(entry *Entry) string {
+	return fmt.Sprintf("%s\x00%d\x00%s", entry.Header, entry.Level, entry.Message)
+}
+
+// Check the expired windows, returns the notices of suppressed messages
+func (this *limiter) check(entry *Entry) []*Entry {
+	var notices []*Entry
+	for key, window := range this.windows {
+		if entry.Time.Sub(window.start) < rateLimitWindow {
+			continue
+		}
+		if window.suppressed > 0 {
+			notices = append(notices, &Entry{
+				Time:    entry.Time,
+				Level:   window.entry.Level,
+				Header:  window.entry.Header,
+				Message: fmt.Sprintf("Suppressed %d identical messages: %s\n", window.suppressed, strings.TrimSuffix(window.entry.Message, "\n")),
+				Fields:  window.entry.Fields,
+			})
+		}
+		delete(this.windows, key)
+	}
+	sort.Slice(notices, func(i, j int) bool {
+		return notices[i].Message < notices[j].Message
+	})
+	return notices
+}
+
+// Check if the entry is allowed, the entry is counted
+func (this *limiter) allow(entry *Entry, limit int) bool {
+	if this.windows == nil {
+		this.windows = make(map[string]*limiterWindow)
+	}
+	key := getLimiterKey(entry)
+	window, ok := this.windows[key]
+	if !ok {
+		window = &limiterWindow{start: entry.Time, entry: *entry}
+		this.windows[key] = window
+	}
+	window.count++
+	if window.count > limit {
+		window.suppressed++
+		return false
+	}
+	return true
+}
@@ -10,6 +10,9 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"io"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -21,6 +24,11 @@ const (
 	LevelFail    = 5
 	LevelError   = 10
 	LevelNo      = 100
+
+	FormatText = "text" // The human readable format (default)
+	FormatJson = "json" // One json object per entry
+
+	DefaultTimeLayout = "2006-01-02T15:04:05"
 )
 
 var (
@@ -29,6 +37,8 @@ var (
 	DefaultOptions = Options{
 		HeaderLength: 24,
 		EnableColor:  false,
+		Format:       FormatText,
+		TimeLayout:   DefaultTimeLayout,
 		ColorMapping: map[int]ColorSchema{
 			LevelDebug:   NewColorSchema(color.New(color.FgCyan), color.New(color.FgCyan)),
 			LevelInfo:    NewColorSchema(color.New(color.FgBlue), NoColor),
@@ -64,15 +74,38 @@ type Logger interface {
 	LeveledHeadedPrint(header string, level int, text ...interface{})
 	LeveledHeadedPrintf(header string, level int, format string, text ...interface{})
 	LeveledHeadedPrintln(header string, level int, text ...interface{})
+	// Set the file which mirrors all entries (regardless of the level) in text format with time and level, shared with all sub loggers. Set nil to disable
+	SetFile(writer io.Writer)
+	// Add a sink, shared with all sub loggers. See Sink
+	AddSink(sink Sink)
+	// Write the held text of the line buffered loggers sharing the output (this one and its sub loggers) as complete
+	// lines, should be called before exit
+	Flush()
+	// Get a writer which logs each line written to it with the level and header (the default header if empty).
+	// Close the writer to log the remaining text without tailing new line
+	WriterAt(level int, header string) io.WriteCloser
 	// Sub loggers
 	GetLogger(level int, defaultLevel int, defaultHeader string) Logger
 	GetLoggerWithHeader(defaultHeader string) Logger
+	// Get a sub logger whose default header extends the default header of this logger, e.g. Runner/Instance-ab12cd
+	GetChildLogger(name string) Logger
+	// Get a sub logger whose entries carry the field(s) in addition to the fields of this logger
+	WithField(key string, value interface{}) Logger
+	WithFields(fields map[string]interface{}) Logger
 }
 
 type Options struct {
-	HeaderLength int
-	EnableColor  bool
-	ColorMapping map[int]ColorSchema
+	HeaderLength  int
+	EnableColor   bool
+	Format        string         // The output format, FormatText or FormatJson. Color is ignored in json format
+	ShowTimestamp bool           // Prefix the time in text format
+	ShowLevel     bool           // Prefix the level tag (e.g. [WARN]) in text format
+	TimeLayout    string         // The time layout of the prefixed time, DefaultTimeLayout if empty
+	LineBuffered  bool           // Hold the text without tailing new line (e.g. by Print) until the line is completed, then write the line as one entry. The held text is written by itself when the header or level changes, or by Flush
+	HeaderLevels  map[string]int // The levels of headers, overwrite the logger level. See GetHeaderLevel
+	ShowCaller    bool           // Record the caller (file:line) of debug entries
+	RateLimit     int            // The max number of identical messages (same header, level and message) per second, unlimited if not positive
+	ColorMapping  map[int]ColorSchema
 }
 
 type ColorSchema struct {
@@ -92,9 +125,22 @@ func NewColorSchema(header, message *color.Color) ColorSchema {
 
 func (this *Options) Copy() *Options {
 	newOptions := Options{
-		HeaderLength: this.HeaderLength,
-		EnableColor:  this.EnableColor,
-		ColorMapping: make(map[int]ColorSchema),
+		HeaderLength:  this.HeaderLength,
+		EnableColor:   this.EnableColor,
+		Format:        this.Format,
+		ShowTimestamp: this.ShowTimestamp,
+		ShowLevel:     this.ShowLevel,
+		TimeLayout:    this.TimeLayout,
+		LineBuffered:  this.LineBuffered,
+		ShowCaller:    this.ShowCaller,
+		RateLimit:     this.RateLimit,
+		ColorMapping:  make(map[int]ColorSchema),
+	}
+	if this.HeaderLevels != nil {
+		newOptions.HeaderLevels = make(map[string]int)
+		for h, l := range this.HeaderLevels {
+			newOptions.HeaderLevels[h] = l
+		}
 	}
 	for l, c := range this.ColorMapping {
 		newOptions.ColorMapping[l] = c
@@ -107,12 +153,60 @@ type stdlogger struct {
 	defaultLevel  int
 	defaultHeader string
 	options       *Options
-	writer        io.Writer
+	out           *output
+	fields        map[string]interface{} // The fields of all entries, never modified after created
+	pending       string                 // The uncompleted line when line buffered
+	pendingHeader string                 // The header of the uncompleted line
+	pendingLevel  int                    // The level of the uncompleted line
+}
+
+// The output shared by the logger and all its sub loggers, all writes are serialized by the lock
+type output struct {
+	lock     sync.Mutex
+	writer   io.Writer    // The writer
+	file     Sink         // The file sink, nil if not set
+	sinks    []Sink       // The added sinks
+	minLevel int          // The min level of the file and added sinks, LevelNo if no sink
+	limiter  limiter      // The rate limiter of identical messages
+	held     []*stdlogger // The line buffered loggers holding an uncompleted line
+}
+
+// Track the logger holding an uncompleted line, the output lock must be held
+func (this *output) hold(logger *stdlogger) {
+	for _, held := range this.held {
+		if held == logger {
+			return
+		}
+	}
+	this.held = append(this.held, logger)
+}
+
+// Stop tracking the logger whose line is completed, the output lock must be held
+func (this *output) release(logger *stdlogger) {
+	for i, held := range this.held {
+		if held == logger {
+			this.held = append(this.held[:i], this.held[i+1:]...)
+			return
+		}
+	}
+}
+
+func (this *output) updateMinLevel() {
+	this.minLevel = LevelNo
+	sinks := this.sinks
+	if this.file != nil {
+		sinks = append([]Sink{this.file}, sinks...)
+	}
+	for _, sink := range sinks {
+		if sink.MinLevel() < this.minLevel {
+			this.minLevel = sink.MinLevel()
+		}
+	}
 }
 
 func New(writer io.Writer, level int, defaultLevel int, defaultHeader string) Logger {
 	return &stdlogger{
-		writer:        writer,
+		out:           &output{writer: writer, minLevel: LevelNo},
 		level:         level,
 		defaultLevel:  defaultLevel,
 		defaultHeader: defaultHeader,
@@ -185,94 +279,139 @@ func (this *stdlogger) HeadedPrintln(header string, level int, text ...interface
 }
 
 func (this *stdlogger) LeveledHeadedPrint(header string, level int, text ...interface{}) {
-	// Check level
-	if level < this.level {
+	if !this.enabled(header, level) {
 		return
 	}
-	// Check color
-	if this.options.EnableColor {
-		headerColor, messageColor := NoColor, NoColor
-		c, ok := this.options.ColorMapping[level]
-		if ok {
-			headerColor, messageColor = c.HeaderColor, c.MessageColor
-		}
-		// Check header
-		if header == "" {
-			fmt.Fprint(this.writer, messageColor.SprintFunc()(text...))
-		} else {
-			header = fmt.Sprintf(fmt.Sprintf("[%%-%ds] ", this.options.HeaderLength), header)
-			fmt.Fprint(this.writer, headerColor.SprintFunc()(header))
-			fmt.Fprint(this.writer, messageColor.SprintFunc()(text...))
-		}
-	} else {
-		// Check header
-		if header == "" {
-			fmt.Fprint(this.writer, text...)
-		} else {
-			fmt.Fprintf(this.writer, fmt.Sprintf("[%%-%ds] ", this.options.HeaderLength), header)
-			fmt.Fprint(this.writer, text...)
-		}
-	}
+	this.log(header, level, fmt.Sprint(text...))
 }
 
 func (this *stdlogger) LeveledHeadedPrintf(header string, level int, format string, text ...interface{}) {
-	// Check level
-	if level < this.level {
+	if !this.enabled(header, level) {
+		return
+	}
+	this.log(header, level, fmt.Sprintf(format, text...))
+}
+
+func (this *stdlogger) LeveledHeadedPrintln(header string, level int, text ...interface{}) {
+	if !this.enabled(header, level) {
 		return
 	}
-	// Check color
-	if this.options.EnableColor {
-		headerColor, messageColor := NoColor, NoColor
-		c, ok := this.options.ColorMapping[level]
-		if ok {
-			headerColor, messageColor = c.HeaderColor, c.MessageColor
+	this.log(header, level, fmt.Sprintln(text...))
+}
+
+func (this *stdlogger) SetFile(writer io.Writer) {
+	this.out.lock.Lock()
+	defer this.out.lock.Unlock()
+	if writer == nil {
+		this.out.file = nil
+	} else {
+		options := DefaultOptions.Copy()
+		options.ShowTimestamp, options.ShowLevel = true, true
+		sink := NewWriterSink(writer, LevelAll, options)
+		sink.StripColor = true
+		this.out.file = sink
+	}
+	this.out.updateMinLevel()
+}
+
+func (this *stdlogger) WriterAt(level int, header string) io.WriteCloser {
+	if header == "" {
+		header = this.defaultHeader
+	}
+	return &lineWriter{logger: this, level: level, header: header}
+}
+
+func (this *stdlogger) AddSink(sink Sink) {
+	this.out.lock.Lock()
+	defer this.out.lock.Unlock()
+	this.out.sinks = append(this.out.sinks, sink)
+	this.out.updateMinLevel()
+}
+
+// Get the level of the header, the level set in Options.HeaderLevels takes precedence
+func (this *stdlogger) getHeaderLevel(header string) int {
+	if level, ok := this.options.GetHeaderLevel(header); ok {
+		return level
+	}
+	return this.level
+}
+
+// Check if the entry of the header and level will be written to any output
+func (this *stdlogger) enabled(header string, level int) bool {
+	if level >= this.getHeaderLevel(header) {
+		return true
+	}
+	this.out.lock.Lock()
+	defer this.out.lock.Unlock()
+	return level >= this.out.minLevel
+}
+
+// Write a log entry
+func (this *stdlogger) log(header string, level int, message string) {
+	this.out.lock.Lock()
+	defer this.out.lock.Unlock()
+	if this.options.LineBuffered {
+		if this.pending != "" && (header != this.pendingHeader || level != this.pendingLevel) {
+			// The header or level changed, complete the pending line by itself
+			pending := this.pending
+			this.pending = ""
+			this.emit(this.pendingHeader, this.pendingLevel, pending+"\n")
 		}
-		// Check header
-		if header == "" {
-			fmt.Fprint(this.writer, messageColor.SprintfFunc()(format, text...))
-		} else {
-			header = fmt.Sprintf(fmt.Sprintf("[%%-%ds] ", this.options.HeaderLength), header)
-			fmt.Fprint(this.writer, headerColor.SprintFunc()(header))
-			fmt.Fprint(this.writer, messageColor.SprintfFunc()(format, text...))
+		this.pending += message
+		this.pendingHeader, this.pendingLevel = header, level
+		if !strings.HasSuffix(this.pending, "\n") {
+			this.out.hold(this)
+			return
 		}
-	} else {
-		// Check header
-		if header == "" {
-			fmt.Fprintf(this.writer, format, text...)
-		} else {
-			fmt.Fprintf(this.writer, fmt.Sprintf("[%%-%ds] ", this.options.HeaderLength), header)
-			fmt.Fprintf(this.writer, format, text...)
+		message, this.pending = this.pending, ""
+		this.out.release(this)
+	}
+	this.emit(header, level, message)
+}
+
+func (this *stdlogger) Flush() {
+	this.out.lock.Lock()
+	defer this.out.lock.Unlock()
+	for _, logger := range this.out.held {
+		if logger.pending != "" {
+			pending := logger.pending
+			logger.pending = ""
+			logger.emit(logger.pendingHeader, logger.pendingLevel, pending+"\n")
 		}
 	}
+	this.out.held = nil
 }
 
-func (this *stdlogger) LeveledHeadedPrintln(header string, level int, text ...interface{}) {
-	// Check level
-	if level < this.level {
-		return
+// Write the message as an entry, the output lock must be held
+func (this *stdlogger) emit(header string, level int, message string) {
+	// Each entry is rendered and written in one call to prevent interleaving
+	entry := Entry{Time: time.Now(), Level: level, Header: header, Message: message, Fields: this.fields}
+	if this.options.ShowCaller && level <= LevelDebug {
+		entry.Caller = getCaller()
 	}
-	// Check color
-	if this.options.EnableColor {
-		headerColor, messageColor := NoColor, NoColor
-		c, ok := this.options.ColorMapping[level]
-		if ok {
-			headerColor, messageColor = c.HeaderColor, c.MessageColor
+	if this.options.RateLimit > 0 {
+		// Write the notices of the suppressed messages, then check the limit of this message
+		for _, notice := range this.out.limiter.check(&entry) {
+			this.write(notice)
 		}
-		// Check header
-		if header == "" {
-			fmt.Fprintln(this.writer, messageColor.SprintFunc()(text...))
-		} else {
-			header = fmt.Sprintf(fmt.Sprintf("[%%-%ds] ", this.options.HeaderLength), header)
-			fmt.Fprint(this.writer, headerColor.SprintFunc()(header))
-			fmt.Fprint(this.writer, messageColor.SprintlnFunc()(text...))
+		if !this.out.limiter.allow(&entry, this.options.RateLimit) {
+			return
 		}
-	} else {
-		// Check header
-		if header == "" {
-			fmt.Fprintln(this.writer, text...)
-		} else {
-			fmt.Fprintf(this.writer, fmt.Sprintf("[%%-%ds] ", this.options.HeaderLength), header)
-			fmt.Fprintln(this.writer, text...)
+	}
+	this.write(&entry)
+}
+
+// Write the entry to all outputs, the output lock must be held
+func (this *stdlogger) write(entry *Entry) {
+	if entry.Level >= this.getHeaderLevel(entry.Header) {
+		fmt.Fprint(this.out.writer, this.options.Render(entry))
+	}
+	if this.out.file != nil {
+		this.out.file.Write(entry)
+	}
+	for _, sink := range this.out.sinks {
+		if entry.Level >= sink.MinLevel() {
+			sink.Write(entry)
 		}
 	}
 }
@@ -280,10 +419,11 @@ func (this *stdlogger) LeveledHeadedPrintln(header string, level int, text ...in
 func (this *stdlogger) GetLogger(level int, defaultLevel int, defaultHeader string) Logger {
 	return &stdlogger{
 		level:         level,
-		defaultLevel:  level,
+		defaultLevel:  defaultLevel,
 		defaultHeader: defaultHeader,
 		options:       this.options.Copy(),
-		writer:        this.writer,
+		out:           this.out,
+		fields:        this.fields,
 	}
 }
 
@@ -293,6 +433,33 @@ func (this *stdlogger) GetLoggerWithHeader(defaultHeader string) Logger {
 		defaultLevel:  this.defaultLevel,
 		defaultHeader: defaultHeader,
 		options:       this.options.Copy(),
-		writer:        this.writer,
+		out:           this.out,
+		fields:        this.fields,
+	}
+}
+
+func (this *stdlogger) GetChildLogger(name string) Logger {
+	return this.GetLoggerWithHeader(GetChildHeader(this.defaultHeader, name))
+}
+
+func (this *stdlogger) WithField(key string, value interface{}) Logger {
+	return this.WithFields(map[string]interface{}{key: value})
+}
+
+func (this *stdlogger) WithFields(fields map[string]interface{}) Logger {
+	newFields := make(map[string]interface{})
+	for key, value := range this.fields {
+		newFields[key] = value
+	}
+	for key, value := range fields {
+		newFields[key] = value
+	}
+	return &stdlogger{
+		level:         this.level,
+		defaultLevel:  this.defaultLevel,
+		defaultHeader: this.defaultHeader,
+		options:       this.options.Copy(),
+		out:           this.out,
+		fields:        newFields,
 	}
 }
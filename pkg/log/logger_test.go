@@ -0,0 +1,178 @@
+// Author: lipixun
+// Created Time : 五 10/16 21:15:40 2026
+//
+// File Name: logger_test.go
+// Description:
+//
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var (
+	headerLevelCases = []struct {
+		Levels string
+		Header string
+		Level  int
+		Found  bool
+	}{
+		{Levels: "Runner=debug", Header: "Runner", Level: LevelDebug, Found: true},
+		{Levels: "runner=debug", Header: "Runner", Level: LevelDebug, Found: true},
+		{Levels: "Python=warn", Header: "Python.Nuitka", Level: LevelWarn, Found: true},
+		{Levels: "Builder=error", Header: "SourceCode.Builder", Level: LevelError, Found: true},
+		{Levels: "Builder=error,SourceCode.Builder=debug", Header: "SourceCode.Builder", Level: LevelDebug, Found: true},
+		{Levels: "Build=debug", Header: "SourceCode.Builder", Found: false},
+		{Levels: "Runner=debug", Header: "", Found: false},
+	}
+)
+
+func TestHeaderLevels(t *testing.T) {
+	for _, tCase := range headerLevelCases {
+		levels, err := ParseHeaderLevels(tCase.Levels)
+		if err != nil {
+			t.Errorf("Failed to parse header levels [%s], error: %s", tCase.Levels, err)
+			continue
+		}
+		options := Options{HeaderLevels: levels}
+		level, found := options.GetHeaderLevel(tCase.Header)
+		if found != tCase.Found || level != tCase.Level {
+			t.Errorf("Incorrect level of header [%s] with [%s]. Expect [%d %v] Actual [%d %v]", tCase.Header, tCase.Levels, tCase.Level, tCase.Found, level, found)
+		}
+	}
+	if _, err := ParseHeaderLevels("Runner=verbose"); err == nil {
+		t.Errorf("Unknown level should be an error")
+	}
+}
+
+func TestCaptureLogger(t *testing.T) {
+	logger := NewCaptureLogger()
+	sub := logger.GetLoggerWithHeader("Runner").WithField("instance", "ab12")
+	sub.LeveledPrintf(LevelDebug, "Start %s\n", "app")
+	sub.LeveledPrintln(LevelError, "Failed to start")
+	logger.Println("Done")
+	if entries := logger.Entries(); len(entries) != 3 {
+		t.Fatalf("Incorrect entry count. Expect [3] Actual [%d]", len(entries))
+	}
+	errs := logger.Filter(LevelError, "Runner")
+	if len(errs) != 1 || errs[0].Fields["instance"] != "ab12" {
+		t.Errorf("Incorrect error entries: %v", errs)
+	}
+	if !logger.Contains(LevelDebug, "Start app") || logger.Contains(LevelInfo, "Start app") {
+		t.Errorf("Incorrect result of contains")
+	}
+	logger.Reset()
+	if len(logger.Entries()) != 0 {
+		t.Errorf("Entries should be cleared")
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New(&buffer, LevelInfo, LevelInfo, "Test")
+	logger.Options().HeaderLength = 6
+	logger.WithField("target", "a b").Printf("Built\n")
+	logger.LeveledPrintln(LevelDebug, "Ignored")
+	if expect := "[Test  ] Built target=\"a b\"\n"; buffer.String() != expect {
+		t.Errorf("Incorrect output. Expect [%q] Actual [%q]", expect, buffer.String())
+	}
+}
+
+func TestCaller(t *testing.T) {
+	logger := NewCaptureLogger()
+	logger.Options().ShowCaller = true
+	logger.LeveledPrintln(LevelDebug, "Debug")
+	logger.WriterAt(LevelDebug, "").Write([]byte("Output\n"))
+	logger.Println("Info")
+	entries := logger.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Incorrect entry count. Expect [3] Actual [%d]", len(entries))
+	}
+	for i, entry := range entries[:2] {
+		if !strings.HasPrefix(entry.Caller, "logger_test.go:") {
+			t.Errorf("Incorrect caller of entry %d: %s", i, entry.Caller)
+		}
+	}
+	if entries[2].Caller != "" {
+		t.Errorf("Caller should only be recorded for debug entries")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	logger := NewCaptureLogger()
+	logger.Options().RateLimit = 2
+	for i := 0; i < 5; i++ {
+		logger.Println("Polling status")
+		logger.Printf("Poll %d\n", i)
+	}
+	if count := len(logger.Filter(LevelInfo, "")); count != 7 {
+		t.Errorf("Incorrect entry count. Expect [7] Actual [%d]", count)
+	}
+}
+
+func TestLineBuffered(t *testing.T) {
+	logger := NewCaptureLogger()
+	logger.Options().LineBuffered = true
+	logger.LeveledHeadedPrintf("Build", LevelInfo, "Building [%s] ...... ", "app")
+	logger.LeveledHeadedPrintf("Build", LevelInfo, "Done\n")
+	// Interleaved partial lines of different headers and levels
+	logger.LeveledHeadedPrintf("Runner", LevelInfo, "Stopping [ab12] ...... ")
+	logger.LeveledHeadedPrintf("Build", LevelError, "Failed\n")
+	logger.LeveledHeadedPrintf("Runner", LevelWarn, "Retry ")
+	logger.LeveledHeadedPrintf("Runner", LevelError, "later\n")
+	logger.LeveledHeadedPrintf("Runner", LevelInfo, "Pending")
+	// The held lines of the sub loggers are written by the flush of any logger sharing the output
+	sub := logger.GetLoggerWithHeader("Sub")
+	sub.Print("Sub pending")
+	if count := len(logger.Entries()); count != 5 {
+		t.Errorf("Incorrect entry count before flush. Expect [5] Actual [%d]", count)
+	}
+	logger.Flush()
+	logger.Flush()
+	expects := []Entry{
+		{Header: "Build", Level: LevelInfo, Message: "Building [app] ...... Done\n"},
+		{Header: "Runner", Level: LevelInfo, Message: "Stopping [ab12] ...... \n"},
+		{Header: "Build", Level: LevelError, Message: "Failed\n"},
+		{Header: "Runner", Level: LevelWarn, Message: "Retry \n"},
+		{Header: "Runner", Level: LevelError, Message: "later\n"},
+		{Header: "Runner", Level: LevelInfo, Message: "Pending\n"},
+		{Header: "Sub", Level: LevelInfo, Message: "Sub pending\n"},
+	}
+	entries := logger.Entries()
+	if len(entries) != len(expects) {
+		t.Fatalf("Incorrect entries. Expect %v Actual %v", expects, entries)
+	}
+	for i, expect := range expects {
+		if entry := entries[i]; entry.Header != expect.Header || entry.Level != expect.Level || entry.Message != expect.Message {
+			t.Errorf("Incorrect entry %d. Expect [%s %d %q] Actual [%s %d %q]", i, expect.Header, expect.Level, expect.Message, entry.Header, entry.Level, entry.Message)
+		}
+	}
+}
+
+var (
+	shortenHeaderCases = []struct {
+		Header string
+		Length int
+		Result string
+	}{
+		{Header: "Runner", Length: 24, Result: "Runner"},
+		{Header: "Runner/Instance-ab12cd", Length: 24, Result: "Runner/Instance-ab12cd"},
+		{Header: "SourceCode.Builder/Golang/Target-server", Length: 20, Result: "S.B/G/Target-server"},
+		{Header: "SourceCode.Builder/Golang/Target-server", Length: 30, Result: "S.B/Golang/Target-server"},
+		{Header: "Runner/Instance-ab12cd-with-a-long-name", Length: 16, Result: "~ith-a-long-name"},
+	}
+)
+
+func TestShortenHeader(t *testing.T) {
+	for _, tCase := range shortenHeaderCases {
+		if result := ShortenHeader(tCase.Header, tCase.Length); result != tCase.Result {
+			t.Errorf("Incorrect result of [%s] with length %d. Expect [%s] Actual [%s]", tCase.Header, tCase.Length, tCase.Result, result)
+		}
+	}
+	logger := NewCaptureLogger().GetLoggerWithHeader("Runner").GetChildLogger("Instance-ab12cd")
+	if header := logger.GetDefaultHeader(); header != "Runner/Instance-ab12cd" {
+		t.Errorf("Incorrect child header: %s", header)
+	}
+}
@@ -0,0 +1,30 @@
+// Author: lipixun
+// Created Time : 五 10/16 18:55:10 2026
+//
+// File Name: priority.go
+// Description:
+//	The syslog priorities of the levels, used by syslog and journal sinks
+package log
+
+const (
+	syslogPriorityErr     = 3
+	syslogPriorityWarning = 4
+	syslogPriorityNotice  = 5
+	syslogPriorityInfo    = 6
+	syslogPriorityDebug   = 7
+)
+
+func getSyslogPriority(level int) int {
+	switch {
+	case level <= LevelDebug:
+		return syslogPriorityDebug
+	case level == LevelInfo:
+		return syslogPriorityInfo
+	case level == LevelWarn:
+		return syslogPriorityWarning
+	case level == LevelSuccess:
+		return syslogPriorityNotice
+	default:
+		return syslogPriorityErr
+	}
+}
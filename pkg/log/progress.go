@@ -0,0 +1,195 @@
+// Author: lipixun
+// Created Time : 五 10/16 19:41:27 2026
+//
+// File Name: progress.go
+// Description:
+//	The progress primitives: progress bar, spinner and live region
+//
+//	On a terminal, the progress is redrawn in place. Otherwise (e.g. piped to a file in CI) the progress degrades to
+//	plain lines: the bar writes a line every 10 percent, the spinner writes a line at start and stop, the region writes
+//	the changed lines.
+package log
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProgressBarWidth        = 30
+	ProgressSpinnerInterval = 100 * time.Millisecond
+	progressLineStep        = 10 // The percent step to write a line when not a terminal
+)
+
+var (
+	spinnerFrames = []string{"|", "/", "-", "\\"}
+)
+
+// The progress bar with a known total
+type ProgressBar struct {
+	writer    io.Writer
+	title     string
+	total     int64
+	current   int64
+	terminal  bool
+	lastStep  int64
+	finished  bool
+	lock      sync.Mutex
+	startTime time.Time
+}
+
+func NewProgressBar(writer io.Writer, title string, total int64) *ProgressBar {
+	bar := &ProgressBar{writer: writer, title: title, total: total, terminal: IsTerminal(writer), lastStep: -1, startTime: time.Now()}
+	bar.draw()
+	return bar
+}
+
+// Add to the current value
+func (this *ProgressBar) Add(n int64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.current += n
+	this.draw()
+}
+
+// Set the current value
+func (this *ProgressBar) Set(n int64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.current = n
+	this.draw()
+}
+
+// Finish the bar, the bar is set to the total
+func (this *ProgressBar) Finish() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.finished {
+		return
+	}
+	this.current = this.total
+	this.draw()
+	if this.terminal {
+		fmt.Fprintln(this.writer)
+	}
+	this.finished = true
+}
+
+func (this *ProgressBar) percent() int64 {
+	if this.total <= 0 {
+		return 100
+	}
+	if this.current >= this.total {
+		return 100
+	}
+	return this.current * 100 / this.total
+}
+
+func (this *ProgressBar) draw() {
+	if this.finished {
+		return
+	}
+	percent := this.percent()
+	if !this.terminal {
+		if step := percent / progressLineStep; step != this.lastStep {
+			this.lastStep = step
+			fmt.Fprintf(this.writer, "%s: %d%% (%d/%d)\n", this.title, percent, this.current, this.total)
+		}
+		return
+	}
+	done := int(percent * ProgressBarWidth / 100)
+	bar := strings.Repeat("=", done)
+	if done < ProgressBarWidth {
+		bar += ">" + strings.Repeat(" ", ProgressBarWidth-done-1)
+	}
+	fmt.Fprintf(this.writer, "\r\033[K%s [%s] %3d%% (%d/%d) %s", this.title, bar, percent, this.current, this.total, time.Now().Sub(this.startTime).Truncate(time.Second))
+}
+
+// The spinner for the progress without known total
+type Spinner struct {
+	writer   io.Writer
+	title    string
+	terminal bool
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Create and start a spinner
+func NewSpinner(writer io.Writer, title string) *Spinner {
+	spinner := &Spinner{writer: writer, title: title, terminal: IsTerminal(writer), stop: make(chan struct{}), done: make(chan struct{})}
+	if spinner.terminal {
+		go spinner.run()
+	} else {
+		fmt.Fprintf(writer, "%s...\n", title)
+		close(spinner.done)
+	}
+	return spinner
+}
+
+func (this *Spinner) run() {
+	defer close(this.done)
+	ticker := time.NewTicker(ProgressSpinnerInterval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		fmt.Fprintf(this.writer, "\r\033[K%s %s", spinnerFrames[i%len(spinnerFrames)], this.title)
+		select {
+		case <-this.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop the spinner with the final message
+func (this *Spinner) Stop(message string) {
+	this.once.Do(func() {
+		close(this.stop)
+		<-this.done
+		if this.terminal {
+			fmt.Fprint(this.writer, "\r\033[K")
+		}
+		fmt.Fprintf(this.writer, "%s: %s\n", this.title, message)
+	})
+}
+
+// The live region of multiple lines, e.g. the status of each target in a parallel build
+type Region struct {
+	writer   io.Writer
+	terminal bool
+	lines    []string
+	drawn    int // The number of lines drawn on terminal
+	lock     sync.Mutex
+}
+
+func NewRegion(writer io.Writer) *Region {
+	return &Region{writer: writer, terminal: IsTerminal(writer)}
+}
+
+// Set the text of the line at index, the region grows if necessary
+func (this *Region) Set(index int, text string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for len(this.lines) <= index {
+		this.lines = append(this.lines, "")
+	}
+	if this.lines[index] == text {
+		return
+	}
+	this.lines[index] = text
+	if !this.terminal {
+		fmt.Fprintln(this.writer, text)
+		return
+	}
+	// Move the cursor to the top of the region and redraw
+	if this.drawn > 0 {
+		fmt.Fprintf(this.writer, "\033[%dA", this.drawn)
+	}
+	for _, line := range this.lines {
+		fmt.Fprintf(this.writer, "\r\033[K%s\n", line)
+	}
+	this.drawn = len(this.lines)
+}
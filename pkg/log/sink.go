@@ -0,0 +1,81 @@
+// Author: lipixun
+// Created Time : 五 10/16 18:26:03 2026
+//
+// File Name: sink.go
+// Description:
+//	The sinks
+//
+//	Besides the writer of the logger, the entries could be sent to sinks added by Logger.AddSink. The sinks are shared
+//	by the logger and all its sub loggers, each sink has its own min level (the logger level is not applied) and format.
+//	Sinks are called with the output lock held, so a sink MUST NOT write to the logger itself.
+package log
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+var (
+	colorCodeRegularExp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// Remove the color codes (ANSI SGR escape sequences) in the text
+func StripColor(text string) string {
+	return colorCodeRegularExp.ReplaceAllString(text, "")
+}
+
+type Sink interface {
+	// The min level of the entries sent to this sink
+	MinLevel() int
+	// Write the entry
+	Write(entry *Entry) error
+}
+
+// The sink writes the entries to a writer
+type WriterSink struct {
+	Writer     io.Writer
+	Level      int
+	Options    *Options // The format options
+	StripColor bool     // Remove the color codes in the text (e.g. in the subprocess output), used by file sinks
+}
+
+// Create a new writer sink, use the text format without color if options is nil
+func NewWriterSink(writer io.Writer, level int, options *Options) *WriterSink {
+	if options == nil {
+		options = DefaultOptions.Copy()
+	}
+	return &WriterSink{Writer: writer, Level: level, Options: options}
+}
+
+func (this *WriterSink) MinLevel() int {
+	return this.Level
+}
+
+func (this *WriterSink) Write(entry *Entry) error {
+	text := this.Options.Render(entry)
+	if this.StripColor {
+		text = StripColor(text)
+	}
+	_, err := fmt.Fprint(this.Writer, text)
+	return err
+}
+
+// The sink calls a function with the entries, e.g. to subscribe the error entries
+type HookSink struct {
+	Level int
+	Hook  func(entry *Entry)
+}
+
+func NewHookSink(level int, hook func(entry *Entry)) *HookSink {
+	return &HookSink{Level: level, Hook: hook}
+}
+
+func (this *HookSink) MinLevel() int {
+	return this.Level
+}
+
+func (this *HookSink) Write(entry *Entry) error {
+	this.Hook(entry)
+	return nil
+}
@@ -0,0 +1,54 @@
+// Author: lipixun
+// Created Time : 五 10/16 18:52:36 2026
+//
+// File Name: syslog.go
+// Description:
+//	The syslog sink
+package log
+
+import (
+	"log/syslog"
+	"strings"
+)
+
+// The sink writes the entries to the local syslog daemon
+type SyslogSink struct {
+	Level  int
+	writer *syslog.Writer
+}
+
+// Create a new syslog sink, the tag is the program name in syslog, e.g. op
+func NewSyslogSink(level int, tag string) (*SyslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{Level: level, writer: writer}, nil
+}
+
+func (this *SyslogSink) MinLevel() int {
+	return this.Level
+}
+
+func (this *SyslogSink) Write(entry *Entry) error {
+	message := strings.TrimSuffix(entry.Message, "\n")
+	if entry.Header != "" {
+		message = "[" + entry.Header + "] " + message
+	}
+	switch getSyslogPriority(entry.Level) {
+	case syslogPriorityDebug:
+		return this.writer.Debug(message)
+	case syslogPriorityNotice:
+		return this.writer.Notice(message)
+	case syslogPriorityWarning:
+		return this.writer.Warning(message)
+	case syslogPriorityErr:
+		return this.writer.Err(message)
+	default:
+		return this.writer.Info(message)
+	}
+}
+
+func (this *SyslogSink) Close() error {
+	return this.writer.Close()
+}
@@ -0,0 +1,38 @@
+// Author: lipixun
+// Created Time : 五 10/16 20:08:51 2026
+//
+// File Name: terminal.go
+// Description:
+//	The terminal detection
+package log
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	NoColorEnvName = "NO_COLOR"
+)
+
+// Check if the writer is a terminal
+func IsTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Check if color should be enabled for the writer: the writer is a terminal and NO_COLOR environment variable is not set
+// See https://no-color.org
+func DetectColor(writer io.Writer) bool {
+	if _, ok := os.LookupEnv(NoColorEnvName); ok {
+		return false
+	}
+	return IsTerminal(writer)
+}
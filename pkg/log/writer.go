@@ -0,0 +1,46 @@
+// Author: lipixun
+// Created Time : 五 10/16 20:31:15 2026
+//
+// File Name: writer.go
+// Description:
+//	The writer which logs each line written to it, e.g. to pipe the subprocess output through the logger
+package log
+
+import (
+	"bytes"
+	"sync"
+)
+
+type lineWriter struct {
+	logger Logger
+	level  int
+	header string
+	buffer bytes.Buffer
+	lock   sync.Mutex
+}
+
+func (this *lineWriter) Write(p []byte) (int, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.buffer.Write(p)
+	for {
+		idx := bytes.IndexByte(this.buffer.Bytes(), '\n')
+		if idx == -1 {
+			break
+		}
+		line := string(this.buffer.Next(idx + 1))
+		this.logger.LeveledHeadedPrint(this.header, this.level, line)
+	}
+	return len(p), nil
+}
+
+// Log the remaining text without tailing new line
+func (this *lineWriter) Close() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.buffer.Len() > 0 {
+		this.logger.LeveledHeadedPrintln(this.header, this.level, this.buffer.String())
+		this.buffer.Reset()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Author: lipixun
+// Created Time : 日 10/18 11:31:14 2026
+//
+// File Name: backend.go
+// Description:
+//	The backends of the registry: the local directory and the remote urls accessed by the fetcher
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The local directory
+type localBackend struct {
+	root string
+}
+
+func (this *localBackend) Location(target, version, name string) string {
+	return filepath.Join(this.root, filepath.FromSlash(getTargetPath(target, false)), version, name)
+}
+
+func (this *localBackend) Put(path, target, version, name string) error {
+	location := this.Location(target, version, name)
+	if err := os.MkdirAll(filepath.Dir(location), os.ModePerm); err != nil {
+		return err
+	}
+	// Copy then rename, the readers never see a partial file
+	temp := location + ".tmp"
+	if err := util.CopyFile(path, temp, 0644); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return os.Rename(temp, location)
+}
+
+func (this *localBackend) Get(target, version, name, digest string) (string, error) {
+	location := this.Location(target, version, name)
+	file, err := os.Open(location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.New(fmt.Sprintf("[%s] not found", location))
+		}
+		return "", err
+	}
+	defer file.Close()
+	if digest != "" {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return "", err
+		}
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != digest {
+			return "", errors.New(fmt.Sprintf("Checksum mismatch of [%s]. Expected sha256 [%s] Actual [%s]", location, digest, actual))
+		}
+	}
+	return location, nil
+}
+
+// The remote url (http, s3, gs, oci)
+type remoteBackend struct {
+	fetcher *fetcher.Fetcher
+	base    string
+	oci     bool
+}
+
+func (this *remoteBackend) Location(target, version, name string) string {
+	if this.oci {
+		return fmt.Sprintf("%s/%s/%s:%s", this.base, getTargetPath(target, true), strings.ToLower(name), version)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", this.base, getTargetPath(target, false), version, name)
+}
+
+func (this *remoteBackend) Put(path, target, version, name string) error {
+	return this.fetcher.Upload(path, this.Location(target, version, name))
+}
+
+func (this *remoteBackend) Get(target, version, name, digest string) (string, error) {
+	return this.fetcher.Fetch(this.Location(target, version, name), digest)
+}
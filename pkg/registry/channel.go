@@ -0,0 +1,201 @@
+// Author: lipixun
+// Created Time : 日 10/18 12:37:09 2026
+//
+// File Name: channel.go
+// Description:
+//	The promotion channels of the registry, e.g. dev -> staging -> prod (the config artifact.channels)
+//
+//	A pushed version is in the first channel. Promoting a version to a channel requires the version in the previous
+//	channel (unless forced), e.g. a version is promoted to prod only after staging. The promotion is recorded:
+//		- In the manifest of the version, the metadata registry.promoted.<channel> of the artifacts is the time of the
+//		  promotion
+//		- In the channel file of the target, the current version of the channel, layout:
+//			<url>/<target>/channels/<channel>.json 		oci: <url>/<target>/<channel>.json:channels
+//	So the version of the channel could be resolved, e.g. op artifact pull server@staging pulls the latest staging
+//	version of server (a channel name takes precedence over the same version name).
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ChannelsVersion = "channels" // The reserved version of the channel files
+
+	MetadataPromotedPrefix = "registry.promoted." // The metadata prefix of the promotion time of the channels
+)
+
+var (
+	DefaultChannels = []string{"dev", "staging", "prod"}
+)
+
+// The channel of a target
+type Channel struct {
+	Name     string    `json:"name"`
+	Version  string    `json:"version"`            // The current version
+	Time     time.Time `json:"time"`               // The time of the promotion
+	Previous string    `json:"previous,omitempty"` // The version before the current one
+}
+
+// Parse the channels (comma separated), the default channels if empty
+func ParseChannels(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultChannels, nil
+	}
+	var channels []string
+	for _, channel := range strings.Split(s, ",") {
+		channel = strings.TrimSpace(channel)
+		if !versionRegexp.MatchString(channel) || channel == ChannelsVersion {
+			return nil, errors.New(fmt.Sprintf("Invalid channel [%s]", channel))
+		}
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
+
+// Whether the name is a channel
+func (this *Registry) IsChannel(name string) bool {
+	return this.channelIndex(name) != -1
+}
+
+// Get the channel of the target, nil if the target has never been promoted to the channel
+func (this *Registry) GetChannel(target, name string) (*Channel, error) {
+	if !this.IsChannel(name) {
+		return nil, errors.New(fmt.Sprintf("Unknown channel [%s], should be one of %s", name, strings.Join(this.Channels, ", ")))
+	}
+	path, err := this.backend.Get(target, ChannelsVersion, name+".json", "")
+	if err != nil {
+		// Not distinguishable from the other errors of the remote backends
+		this.logger.LeveledPrintf(log.LevelDebug, "Failed to get channel [%s] of [%s], error: %s\n", name, target, err)
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var channel Channel
+	if err := json.Unmarshal(data, &channel); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse channel [%s] of [%s], error: %s", name, target, err))
+	}
+	return &channel, nil
+}
+
+// Resolve the version or the channel to the version
+func (this *Registry) Resolve(target, version string) (string, error) {
+	if !this.IsChannel(version) {
+		return version, nil
+	}
+	channel, err := this.GetChannel(target, version)
+	if err != nil {
+		return "", err
+	}
+	if channel == nil {
+		return "", errors.New(fmt.Sprintf("No version of [%s] in channel [%s]", target, version))
+	}
+	return channel.Version, nil
+}
+
+// Promote the version of the target to the channel
+// Parameters:
+//
+//	force 	Promote even if the version is not in the previous channel
+func (this *Registry) Promote(target, version, name string, force bool) (*Channel, error) {
+	index := this.channelIndex(name)
+	if index == -1 {
+		return nil, errors.New(fmt.Sprintf("Unknown channel [%s], should be one of %s", name, strings.Join(this.Channels, ", ")))
+	}
+	manifestPath, err := this.backend.Get(target, version, ManifestFileName, "")
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to get the manifest of [%s@%s], error: %s", target, version, err))
+	}
+	manifest, err := artifact.ReadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if index > 0 && !force && !isPromoted(manifest, this.Channels[index-1]) {
+		return nil, errors.New(fmt.Sprintf("Version [%s] of [%s] is not in channel [%s], promote it to %s first", version, target, this.Channels[index-1], this.Channels[index-1]))
+	}
+	return this.promote(manifest, target, version, name)
+}
+
+// Record the promotion in the manifest and the channel
+func (this *Registry) promote(manifest *artifact.Manifest, target, version, name string) (*Channel, error) {
+	now := time.Now()
+	setPromoted(manifest, name, now)
+	if err := this.putJSON(manifest, target, version, ManifestFileName); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to push the manifest, error: %s", err))
+	}
+	return this.updateChannel(target, version, name, now)
+}
+
+// Point the channel of the target to the version
+func (this *Registry) updateChannel(target, version, name string, now time.Time) (*Channel, error) {
+	channel := &Channel{Name: name, Version: version, Time: now.UTC()}
+	if current, err := this.GetChannel(target, name); err != nil {
+		return nil, err
+	} else if current != nil {
+		channel.Previous = current.Version
+		if current.Version == version {
+			channel.Previous = current.Previous
+		}
+	}
+	if err := this.putJSON(channel, target, ChannelsVersion, name+".json"); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to push channel [%s], error: %s", name, err))
+	}
+	// Done
+	return channel, nil
+}
+
+// Put the value as the json file
+func (this *Registry) putJSON(value interface{}, target, version, name string) error {
+	tempDir, err := ioutil.TempDir("", "op-registry-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(tempDir, name)
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0666); err != nil {
+		return err
+	}
+	return this.backend.Put(path, target, version, name)
+}
+
+// Get the index of the channel, -1 if not found
+func (this *Registry) channelIndex(name string) int {
+	for i, channel := range this.Channels {
+		if channel == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Whether the version of the manifest has been promoted to the channel
+func isPromoted(manifest *artifact.Manifest, name string) bool {
+	for _, art := range manifest.Artifacts {
+		if _, ok := art.GetInfo().Metadata[MetadataPromotedPrefix+name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Set the promotion time of the channel to the artifacts of the manifest
+func setPromoted(manifest *artifact.Manifest, name string, now time.Time) {
+	for _, art := range manifest.Artifacts {
+		art.GetInfo().SetMetadata(MetadataPromotedPrefix+name, now.UTC().Format(time.RFC3339))
+	}
+}
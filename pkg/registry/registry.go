@@ -0,0 +1,364 @@
+// Author: lipixun
+// Created Time : 日 10/18 11:05:33 2026
+//
+// File Name: registry.go
+// Description:
+//	The artifact registry, stores the artifacts of the targets by versions for the other machines to pull, e.g. the
+//	binaries built on CI pulled onto a staging box
+//
+//	The registry url (--registry or the config artifact.registry, which is ignored in the project config):
+//		/path, file:///path 		A local (or mounted) directory
+//		http(s)://host/prefix 		Uploaded by PUT and downloaded by GET
+//		s3://bucket/prefix 			See fetcher/s3.go
+//		gs://bucket/prefix 			See fetcher/gcs.go
+//		oci://registry/prefix 		Each file is a single file oci artifact, see fetcher/oci.go
+//	The remote urls are accessed by the fetcher (with the credentials, the rewrite rules, the proxy and the cache of
+//	the workspace). The layout under the url (<target> is the target name as pushed, with the characters other than
+//	letters, digits, ., _, - and / replaced by _ as well as the . and .. segments, the target and the file are lower
+//	cased for oci):
+//		<url>/<target>/<version>/manifest.json 		oci: <url>/<target>/manifest.json:<version>
+//		<url>/<target>/<version>/<file> 			oci: <url>/<target>/<file>:<version>
+//	The manifest (see artifact.Manifest) records the pushed artifacts, the paths are the file names in the version.
+//	Only the archive and the single (or compressed) file artifacts have files to push, the docker artifacts are
+//	recorded in the manifest as they are (the images are in the docker registries already).
+//
+//	The pulled files are verified by the fingerprints, and written to the directory with the manifest (artifacts.json,
+//	the paths are the pulled files). With the config artifact.publickey, the pulled artifacts are verified by their
+//	signed provenances as well (see artifact/provenance.go), all of them are verified in the fetch cache before any
+//	file is written to the directory. The public key is only accepted from the user and global config, a checked out
+//	repository shouldn't supply the key its own artifacts are verified by. Pushing a version again overwrites it (and
+//	its promotions). The pushed version is in the first promotion channel, see channel.go.
+package registry
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RegistryLogHeader = "Registry"
+
+	ManifestFileName = "manifest.json"
+
+	MetadataMode = "registry.mode" // The metadata of the file mode (octal) of the pushed file
+)
+
+var (
+	versionRegexp    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	targetPathRegexp = regexp.MustCompile(`[^A-Za-z0-9._/-]`)
+)
+
+// The storage of the registry
+type Backend interface {
+	Put(path, target, version, name string) error             // Put the local file as the file name of the version
+	Get(target, version, name, digest string) (string, error) // Get the file of the version to local, verified by the sha256 digest (hex) if not empty
+	Location(target, version, name string) string             // The location of the file of the version
+}
+
+// The artifact registry
+type Registry struct {
+	Url       string
+	Channels  []string          // The promotion channels in order
+	PublicKey ed25519.PublicKey // The key to verify the provenances of the pulled artifacts, not verified if nil
+	backend   Backend
+	logger    log.Logger
+}
+
+// Create the registry of the url
+func New(ws *workspace.Workspace, rawurl string) (*Registry, error) {
+	if rawurl == "" {
+		return nil, errors.New(fmt.Sprintf("Require the registry url, specify --registry or the config %s", workspace.ConfigKeyArtifactRegistry))
+	}
+	channels, err := ParseChannels(ws.Config.GetString(workspace.ConfigKeyArtifactChannels))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid config %s, error: %s", workspace.ConfigKeyArtifactChannels, err))
+	}
+	registry := &Registry{Url: rawurl, Channels: channels, logger: ws.Logger.GetLoggerWithHeader(RegistryLogHeader)}
+	if publicKey := ws.GetUserConfigString(workspace.ConfigKeyArtifactPublicKey); publicKey != "" {
+		if registry.PublicKey, err = artifact.ParsePublicKey(publicKey); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid config %s, error: %s", workspace.ConfigKeyArtifactPublicKey, err))
+		}
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid registry url [%s], error: %s", rawurl, err))
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "file":
+		path := rawurl
+		if u.Scheme != "" {
+			path = u.Path
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, err
+		}
+		registry.backend = &localBackend{root: path}
+	case uri.SchemeHTTP, uri.SchemeHTTPS, uri.SchemeS3, uri.SchemeGCS, uri.SchemeOCI:
+		f, err := fetcher.New(ws)
+		if err != nil {
+			return nil, err
+		}
+		registry.backend = &remoteBackend{fetcher: f, base: strings.TrimRight(rawurl, "/"), oci: strings.ToLower(u.Scheme) == uri.SchemeOCI}
+	default:
+		return nil, errors.New(fmt.Sprintf("Unsupported scheme [%s] of registry url [%s]", u.Scheme, rawurl))
+	}
+	// Done
+	return registry, nil
+}
+
+// Parse the reference <target>@<version> (or <target>@<channel>)
+func ParseReference(ref string) (string, string, error) {
+	idx := strings.LastIndex(ref, "@")
+	if idx <= 0 || idx == len(ref)-1 {
+		return "", "", errors.New(fmt.Sprintf("Invalid reference [%s], should be <target>@<version>", ref))
+	}
+	target, version := ref[:idx], ref[idx+1:]
+	for _, segment := range strings.Split(strings.Trim(target, "/"), "/") {
+		if segment == "." || segment == ".." {
+			return "", "", errors.New(fmt.Sprintf("Invalid target [%s], should not have . or .. segments", target))
+		}
+	}
+	if !versionRegexp.MatchString(version) {
+		return "", "", errors.New(fmt.Sprintf("Invalid version [%s], should be letters, digits, ., _ and - (not started with . or -)", version))
+	}
+	if version == ChannelsVersion {
+		return "", "", errors.New(fmt.Sprintf("Invalid version [%s], which is reserved", version))
+	}
+	return target, version, nil
+}
+
+// Push the artifacts of the target in the manifest as the version
+// Parameters:
+//
+//	target 		The target key, or the target name if not ambiguous in the manifest
+//
+// Returns:
+//
+//	The pushed manifest
+func (this *Registry) Push(manifest *artifact.Manifest, target, version string) (*artifact.Manifest, error) {
+	key, err := findTarget(manifest, target)
+	if err != nil {
+		return nil, err
+	}
+	pushed := &artifact.Manifest{Tag: manifest.Tag, Time: manifest.Time}
+	names := make(map[string]string)
+	for _, art := range manifest.Find(key) {
+		path, err := getArtifactFile(art)
+		if err != nil {
+			return nil, err
+		}
+		if path == "" {
+			if art.GetType() != artifact.ArtifactTypeDocker {
+				this.logger.LeveledPrintf(log.LevelWarn, "Skip %s artifact [%s] which has no single file, collect it as an archive to push\n", art.GetType(), art.GetName())
+				continue
+			}
+			pushed.Add(art)
+			continue
+		}
+		name := filepath.Base(path)
+		if other, ok := names[name]; ok {
+			return nil, errors.New(fmt.Sprintf("Artifacts [%s] and [%s] have the same file name [%s]", other, art.GetName(), name))
+		}
+		names[name] = art.GetName()
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		this.logger.LeveledPrintf(log.LevelInfo, "Push [%s] to [%s]\n", path, this.backend.Location(target, version, name))
+		if err := this.backend.Put(path, target, version, name); err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to push artifact [%s], error: %s", art.GetName(), err))
+		}
+		// Record the file name instead of the local path
+		copied, err := cloneArtifact(art)
+		if err != nil {
+			return nil, err
+		}
+		setArtifactFile(copied, name)
+		copied.GetInfo().SetMetadata(MetadataMode, strconv.FormatUint(uint64(info.Mode().Perm()), 8))
+		pushed.Add(copied)
+	}
+	if len(pushed.Artifacts) == 0 {
+		return nil, errors.New(fmt.Sprintf("No artifact of target [%s] to push", key))
+	}
+	// Push the manifest at last, so the version is not visible until all files are pushed
+	now := time.Now()
+	setPromoted(pushed, this.Channels[0], now)
+	if err := this.putJSON(pushed, target, version, ManifestFileName); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to push the manifest, error: %s", err))
+	}
+	if _, err := this.updateChannel(target, version, this.Channels[0], now); err != nil {
+		return nil, err
+	}
+	// Done
+	return pushed, nil
+}
+
+// Pull the artifacts of the target of the version (or the channel) into the directory
+// Returns:
+//
+//	The pulled manifest, the paths are the pulled files, which is written to <dest>/artifacts.json as well
+func (this *Registry) Pull(target, version, dest string) (*artifact.Manifest, error) {
+	version, err := this.Resolve(target, version)
+	if err != nil {
+		return nil, err
+	}
+	manifestPath, err := this.backend.Get(target, version, ManifestFileName, "")
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to get the manifest of [%s@%s], error: %s", target, version, err))
+	}
+	manifest, err := artifact.ReadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	// Get the files (into the fetch cache) and verify them before writing anything to the directory
+	type pulledFile struct {
+		art  artifact.Artifact
+		name string // The file name in the version
+		path string // The fetched path
+	}
+	var pulled []pulledFile
+	for _, art := range manifest.Artifacts {
+		name, err := getArtifactFile(art.Artifact)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			continue
+		}
+		if name != filepath.Base(name) {
+			return nil, errors.New(fmt.Sprintf("Invalid file name [%s] of artifact [%s]", name, art.GetName()))
+		}
+		this.logger.LeveledPrintf(log.LevelInfo, "Pull [%s]\n", this.backend.Location(target, version, name))
+		path, err := this.backend.Get(target, version, name, strings.TrimPrefix(art.GetInfo().Fingerprint, "sha256:"))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to pull artifact [%s], error: %s", art.GetName(), err))
+		}
+		pulled = append(pulled, pulledFile{art: art.Artifact, name: name, path: path})
+		setArtifactFile(art.Artifact, path)
+	}
+	if this.PublicKey != nil {
+		for _, art := range manifest.Artifacts {
+			if err := artifact.VerifyArtifact(art.Artifact, this.PublicKey); err != nil {
+				return nil, errors.New(fmt.Sprintf("Failed to verify artifact [%s], error: %s", art.GetName(), err))
+			}
+		}
+	}
+	// Copy the verified files
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return nil, err
+	}
+	for _, file := range pulled {
+		mode := os.FileMode(0644)
+		if value, err := strconv.ParseUint(file.art.GetInfo().Metadata[MetadataMode], 8, 32); err == nil {
+			mode = os.FileMode(value)
+		}
+		target := filepath.Join(dest, file.name)
+		os.Remove(target)
+		if err := util.CopyFile(file.path, target, mode); err != nil {
+			return nil, err
+		}
+		setArtifactFile(file.art, target)
+	}
+	if err := manifest.Write(filepath.Join(dest, artifact.ManifestFileName)); err != nil {
+		return nil, err
+	}
+	// Done
+	return manifest, nil
+}
+
+// Find the target key of the target (key or name) in the manifest
+func findTarget(manifest *artifact.Manifest, target string) (string, error) {
+	var keys []string
+	found := make(map[string]bool)
+	for _, art := range manifest.Artifacts {
+		key := art.GetInfo().Target
+		if key == target {
+			return key, nil
+		}
+		if strings.HasSuffix(key, ":"+target) && !found[key] {
+			found[key] = true
+			keys = append(keys, key)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return "", errors.New(fmt.Sprintf("No artifact of target [%s] in the manifest", target))
+	case 1:
+		return keys[0], nil
+	default:
+		return "", errors.New(fmt.Sprintf("Ambiguous target [%s], could be %s", target, strings.Join(keys, ", ")))
+	}
+}
+
+// Get the file of the artifact to push, empty if the artifact has no single file
+func getArtifactFile(art artifact.Artifact) (string, error) {
+	switch t := art.(type) {
+	case *artifact.ArchiveArtifact:
+		return t.Path, nil
+	case *artifact.FileArtifact:
+		if len(t.Files) == 0 || t.Compressed {
+			if t.Fingerprint == "" {
+				if err := t.Stat(); err != nil {
+					return "", err
+				}
+			}
+			return t.Path, nil
+		}
+	}
+	return "", nil
+}
+
+// Set the file of the artifact (and its output)
+func setArtifactFile(art artifact.Artifact, path string) {
+	switch t := art.(type) {
+	case *artifact.ArchiveArtifact:
+		t.Path = path
+	case *artifact.FileArtifact:
+		t.Path = path
+	default:
+		return
+	}
+	art.GetInfo().Outputs = map[string]string{filepath.Base(path): path}
+}
+
+// Clone the artifact by the manifest serialization
+func cloneArtifact(art artifact.Artifact) (artifact.Artifact, error) {
+	data, err := artifact.ManifestArtifact{Artifact: art}.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var copied artifact.ManifestArtifact
+	if err := copied.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return copied.Artifact, nil
+}
+
+// Get the path of the target in the registry, the . and .. segments are replaced so the path is always under the root
+func getTargetPath(target string, lower bool) string {
+	segments := strings.Split(targetPathRegexp.ReplaceAllString(strings.Trim(target, "/"), "_"), "/")
+	for i, segment := range segments {
+		if segment == "." || segment == ".." {
+			segments[i] = strings.Repeat("_", len(segment))
+		}
+	}
+	path := strings.Join(segments, "/")
+	if lower {
+		path = strings.ToLower(path)
+	}
+	return path
+}
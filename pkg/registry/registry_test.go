@@ -0,0 +1,392 @@
+// Author: lipixun
+// Created Time : 五 10/16 13:24:52 2026
+//
+// File Name: registry_test.go
+// Description:
+//
+package registry
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testTarget = "github.com/org/repo:app"
+)
+
+var (
+	parseChannelsCases = []struct {
+		Source   string
+		Channels []string
+		Good     bool
+	}{
+		{Source: "", Channels: DefaultChannels, Good: true},
+		{Source: " dev , prod ", Channels: []string{"dev", "prod"}, Good: true},
+		{Source: "dev,,prod"},
+		{Source: "dev,channels"},
+		{Source: "dev,.hidden"},
+	}
+	parseReferenceCases = []struct {
+		Ref     string
+		Target  string
+		Version string
+		Good    bool
+	}{
+		{Ref: testTarget + "@v1.0.0", Target: testTarget, Version: "v1.0.0", Good: true},
+		{Ref: "a@b@prod", Target: "a@b", Version: "prod", Good: true},
+		{Ref: "@v1"},
+		{Ref: "app@"},
+		{Ref: "app@.v1"},
+		{Ref: "app@channels"},
+		{Ref: "../../etc@v1"},
+		{Ref: "org/./app@v1"},
+		{Ref: "org/app/..@v1"},
+	}
+	targetPathCases = []struct {
+		Target string
+		Path   string
+	}{
+		{Target: testTarget, Path: "github.com/org/repo_app"},
+		{Target: "//a b/c", Path: "a_b/c"},
+		{Target: "../../etc", Path: "__/__/etc"},
+		{Target: "a/./b/..", Path: "a/_/b/__"},
+		{Target: "a..b/...", Path: "a..b/..."},
+	}
+)
+
+// Create the workspace of temporary directories with the config
+func newTestWorkspace(t *testing.T, dir string, userConfig, projectConfig map[string]string) (*workspace.Workspace, *log.CaptureLogger) {
+	options := workspace.NewWorkspaceOptions()
+	options.Dir.GlobalPath = filepath.Join(dir, "global")
+	options.Dir.UserPath = filepath.Join(dir, "user")
+	options.Dir.ProjectPath = filepath.Join(dir, "project")
+	options.LogFile = false
+	options.EnableColor = false
+	for _, path := range []string{options.Dir.GlobalPath, options.Dir.UserPath, options.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := workspace.New(options, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range map[string]map[string]string{workspace.ConfigLayerUser: userConfig, workspace.ConfigLayerProject: projectConfig} {
+		for key, value := range values {
+			ws.Config.Layer(name).Values[key] = value
+		}
+	}
+	return ws, logger
+}
+
+// Create the single file artifact of the content with the provenance, signed by the key if not nil
+func newTestArtifact(t *testing.T, dir, name, content string, key ed25519.PrivateKey) *artifact.FileArtifact {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	art := artifact.NewSingleFileArtifact(name, path)
+	if err := art.Stat(); err != nil {
+		t.Fatal(err)
+	}
+	art.Target = testTarget
+	art.Provenance = artifact.NewProvenance(art)
+	art.Provenance.Source = artifact.ProvenanceSource{Repository: "github.com/org/repo", Commit: "abcd", Target: testTarget}
+	if key != nil {
+		if err := art.Provenance.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return art
+}
+
+// Rewrite the pushed manifest of the version in the local registry
+func rewriteManifest(t *testing.T, registry *Registry, version string, rewrite func(art *artifact.FileArtifact)) {
+	path := registry.backend.Location("app", version, ManifestFileName)
+	manifest, err := artifact.ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, art := range manifest.Artifacts {
+		rewrite(art.Artifact.(*artifact.FileArtifact))
+	}
+	if err := manifest.Write(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseChannels(t *testing.T) {
+	for _, tCase := range parseChannelsCases {
+		channels, err := ParseChannels(tCase.Source)
+		if !tCase.Good {
+			if err == nil {
+				t.Errorf("Channels [%s] should be invalid, actual %v", tCase.Source, channels)
+			}
+		} else if err != nil || !reflect.DeepEqual(channels, tCase.Channels) {
+			t.Errorf("Incorrect channels of [%s]. Expect %v Actual %v error [%v]", tCase.Source, tCase.Channels, channels, err)
+		}
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	for _, tCase := range parseReferenceCases {
+		target, version, err := ParseReference(tCase.Ref)
+		if !tCase.Good {
+			if err == nil {
+				t.Errorf("Reference [%s] should be invalid, actual [%s] [%s]", tCase.Ref, target, version)
+			}
+		} else if err != nil || target != tCase.Target || version != tCase.Version {
+			t.Errorf("Incorrect reference [%s]. Expect [%s] [%s] Actual [%s] [%s] error [%v]", tCase.Ref, tCase.Target, tCase.Version, target, version, err)
+		}
+	}
+}
+
+func TestTargetPath(t *testing.T) {
+	backend := &localBackend{root: filepath.FromSlash("/registry")}
+	for _, tCase := range targetPathCases {
+		if path := getTargetPath(tCase.Target, false); path != tCase.Path {
+			t.Errorf("Incorrect path of target [%s]. Expect [%s] Actual [%s]", tCase.Target, tCase.Path, path)
+		}
+		location := backend.Location(tCase.Target, "v1", "file")
+		if !strings.HasPrefix(location, backend.root+string(filepath.Separator)) {
+			t.Errorf("Location [%s] of target [%s] is out of the root", location, tCase.Target)
+		}
+	}
+}
+
+func TestPull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+	buildDir := filepath.Join(dir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tCase := range []struct {
+		Name          string
+		UserConfig    map[string]string
+		ProjectConfig map[string]string
+		Key           ed25519.PrivateKey                       // The signing key, not signed if nil
+		Tamper        func(registry *Registry, version string) // Modify the pushed version, nil if not tampered
+		Error         string                                   // The expected error, success if empty
+	}{
+		{Name: "signed", UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey}, Key: privateKey},
+		{Name: "unsigned without public key"},
+		{Name: "unsigned", UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey}, Error: "not signed"},
+		{
+			Name:       "signed by another key",
+			UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey},
+			Key:        otherKey,
+			Error:      "instead of the trusted key",
+		},
+		{
+			Name:       "tampered file",
+			UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey},
+			Key:        privateKey,
+			Tamper: func(registry *Registry, version string) {
+				if err := ioutil.WriteFile(registry.backend.Location("app", version, "app"), []byte("tampered"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			Error: "Checksum mismatch",
+		},
+		{
+			Name: "tampered file without public key",
+			Tamper: func(registry *Registry, version string) {
+				if err := ioutil.WriteFile(registry.backend.Location("app", version, "app"), []byte("tampered"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			Error: "Checksum mismatch",
+		},
+		{
+			Name:       "tampered file and fingerprint",
+			UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey},
+			Key:        privateKey,
+			Tamper: func(registry *Registry, version string) {
+				path := registry.backend.Location("app", version, "app")
+				if err := ioutil.WriteFile(path, []byte("tampered"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				rewriteManifest(t, registry, version, func(art *artifact.FileArtifact) {
+					copied := artifact.NewSingleFileArtifact(art.Name, path)
+					if err := copied.Stat(); err != nil {
+						t.Fatal(err)
+					}
+					art.Fingerprint = copied.Fingerprint
+				})
+			},
+			Error: "The provenance is about",
+		},
+		{
+			Name:       "tampered provenance",
+			UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey},
+			Key:        privateKey,
+			Tamper: func(registry *Registry, version string) {
+				rewriteManifest(t, registry, version, func(art *artifact.FileArtifact) {
+					art.Provenance.Source.Commit = "ffff"
+				})
+			},
+			Error: "Signature mismatch",
+		},
+		{
+			Name:       "removed provenance",
+			UserConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: encodedKey},
+			Key:        privateKey,
+			Tamper: func(registry *Registry, version string) {
+				rewriteManifest(t, registry, version, func(art *artifact.FileArtifact) {
+					art.Provenance = nil
+				})
+			},
+			Error: "No provenance",
+		},
+		{
+			Name:          "public key in project config",
+			ProjectConfig: map[string]string{workspace.ConfigKeyArtifactPublicKey: base64.StdEncoding.EncodeToString(otherKey.Public().(ed25519.PublicKey))},
+			Key:           privateKey,
+		},
+	} {
+		name := strings.Replace(tCase.Name, " ", "-", -1)
+		caseDir := filepath.Join(dir, name)
+		ws, _ := newTestWorkspace(t, caseDir, tCase.UserConfig, tCase.ProjectConfig)
+		registry, err := New(ws, filepath.Join(caseDir, "registry"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest := &artifact.Manifest{Tag: name}
+		manifest.Add(newTestArtifact(t, buildDir, "app", "binary of "+name, tCase.Key))
+		if _, err := registry.Push(manifest, "app", "v1"); err != nil {
+			t.Fatalf("Failed to push case [%s], error: %s", tCase.Name, err)
+		}
+		if tCase.Tamper != nil {
+			tCase.Tamper(registry, "v1")
+		}
+		dest := filepath.Join(caseDir, "dest")
+		pulled, err := registry.Pull("app", "v1", dest)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			// Nothing is written before verified
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Errorf("The destination of case [%s] is written, error: %v", tCase.Name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to pull case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		path := filepath.Join(dest, "app")
+		if data, err := ioutil.ReadFile(path); err != nil || string(data) != "binary of "+name {
+			t.Errorf("Incorrect pulled file of case [%s]. Actual [%s] error [%v]", tCase.Name, data, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0755 {
+			t.Errorf("Incorrect mode of the pulled file of case [%s], error: %v", tCase.Name, err)
+		}
+		if len(pulled.Artifacts) != 1 || pulled.Artifacts[0].Artifact.(*artifact.FileArtifact).Path != path {
+			t.Errorf("Incorrect pulled manifest of case [%s]", tCase.Name)
+		}
+		if _, err := artifact.ReadManifest(filepath.Join(dest, artifact.ManifestFileName)); err != nil {
+			t.Errorf("Incorrect manifest file of case [%s], error: %s", tCase.Name, err)
+		}
+	}
+}
+
+func TestChannels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	buildDir := filepath.Join(dir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ws, _ := newTestWorkspace(t, dir, map[string]string{workspace.ConfigKeyArtifactChannels: "dev,prod"}, nil)
+	registry, err := New(ws, filepath.Join(dir, "registry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Resolve("app", "dev"); err == nil || !strings.Contains(err.Error(), "No version") {
+		t.Errorf("Incorrect error of the empty channel. Actual [%v]", err)
+	}
+	if _, err := registry.GetChannel("app", "staging"); err == nil || !strings.Contains(err.Error(), "Unknown channel") {
+		t.Errorf("Incorrect error of the unknown channel. Actual [%v]", err)
+	}
+	for _, version := range []string{"v1", "v2"} {
+		manifest := &artifact.Manifest{Tag: version}
+		manifest.Add(newTestArtifact(t, buildDir, "app", "binary of "+version, nil))
+		if _, err := registry.Push(manifest, "app", version); err != nil {
+			t.Fatal(err)
+		}
+		if version == "v1" {
+			// dev is the previous channel of prod
+			if _, err := registry.Promote("app", "v1", "prod", false); err != nil {
+				t.Errorf("Failed to promote v1 which is in dev, error: %s", err)
+			}
+		}
+	}
+	for _, tCase := range []struct {
+		Channel  string
+		Version  string
+		Previous string
+	}{
+		{Channel: "dev", Version: "v2", Previous: "v1"},
+		{Channel: "prod", Version: "v1"},
+	} {
+		channel, err := registry.GetChannel("app", tCase.Channel)
+		if err != nil || channel == nil || channel.Version != tCase.Version || channel.Previous != tCase.Previous {
+			t.Errorf("Incorrect channel [%s]. Expect version [%s] previous [%s] Actual %+v error [%v]", tCase.Channel, tCase.Version, tCase.Previous, channel, err)
+		}
+		if version, err := registry.Resolve("app", tCase.Channel); err != nil || version != tCase.Version {
+			t.Errorf("Incorrect version of channel [%s]. Expect [%s] Actual [%s] error [%v]", tCase.Channel, tCase.Version, version, err)
+		}
+	}
+	// The pull of the channel
+	dest := filepath.Join(dir, "dest")
+	if _, err := registry.Pull("app", "prod", dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dest, "app")); err != nil || string(data) != "binary of v1" {
+		t.Errorf("Incorrect pulled file of channel prod. Actual [%s] error [%v]", data, err)
+	}
+	// The promotion requires the previous channel
+	registry.Channels = []string{"dev", "staging", "prod"}
+	if _, err := registry.Promote("app", "v2", "prod", false); err == nil || !strings.Contains(err.Error(), "not in channel [staging]") {
+		t.Errorf("Incorrect error of the promotion skipping staging. Actual [%v]", err)
+	}
+	if channel, err := registry.Promote("app", "v2", "prod", true); err != nil || channel.Version != "v2" || channel.Previous != "v1" {
+		t.Errorf("Incorrect forced promotion %+v error [%v]", channel, err)
+	}
+	manifest, err := artifact.ReadManifest(registry.backend.Location("app", "v2", ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isPromoted(manifest, "dev") || !isPromoted(manifest, "prod") || isPromoted(manifest, "staging") {
+		t.Errorf("Incorrect promotions of v2 %v", manifest.Artifacts[0].GetInfo().Metadata)
+	}
+}
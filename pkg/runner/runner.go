@@ -7,11 +7,10 @@
 package runner
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"github.com/ops-openlight/openlight/pkg/errors"
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"io"
@@ -34,17 +33,34 @@ const (
 	InstanceInfoFileName  = "info.json"
 	InstanceLogStderrName = "stderr.log"
 	InstanceLogStdoutName = "stdout.log"
+	InstanceCounterPrefix = "runner.instance." // The counter name prefix of the instance sequence of an app
 
 	SignalInt  = 2
 	SignalQuit = 3
 	SignalKill = 9
 )
 
+// The error of an application instance which is not found
+type InstanceNotFoundError struct {
+	ID string // The instance id
+}
+
+func (this *InstanceNotFoundError) Error() string {
+	return fmt.Sprintf("Application instance [%s] not found", this.ID)
+}
+
+// Check if the error (or its cause) is an InstanceNotFoundError
+func IsInstanceNotFoundError(err error) bool {
+	_, ok := errors.Cause(err).(*InstanceNotFoundError)
+	return ok
+}
+
 type AppRunner struct {
-	ws       *workspace.Workspace
-	logger   log.Logger
-	rootPath string
-	Apps     map[string]*RunnerAppSpec
+	ws         *workspace.Workspace
+	logger     log.Logger
+	rootPath   string
+	Apps       map[string]*RunnerAppSpec
+	AppSources workspace.Layered // The source of each app
 }
 
 func New(ws *workspace.Workspace) (*AppRunner, error) {
@@ -69,35 +85,40 @@ func New(ws *workspace.Workspace) (*AppRunner, error) {
 	return runner, nil
 }
 
-// Load the runner spec from three places:
-// 	- Current project directory: .op.runner.yaml
-// 	- User config directory: <user>/spec/runner.yaml
-// 	- Global config directory: <global>/spec/runner.yaml
+// Load the runner spec from three layers (see workspace.MergeLayers), an app defined in a higher layer replaces the
+// whole app of the same name in the lower layers:
+//   - Global config directory: <global>/spec/runner.yaml
+//   - User config directory: <user>/spec/runner.yaml
+//   - Current project directory: .op.runner.yaml
 func (this *AppRunner) loadRunnerSpec() error {
-	var filenames []string = []string{
-		filepath.Join(this.ws.Dir.Global.RootPath(), "spec", "runner.yaml"),
-		filepath.Join(this.ws.Dir.User.RootPath(), "spec", "runner.yaml"),
-		filepath.Join(this.ws.Dir.Project.RootPath(), SpecFileName),
-	}
-	apps := make(map[string]*RunnerAppSpec)
-	for _, filename := range filenames {
-		if _, err := os.Stat(filename); err == nil {
-			spec, err := LoadRunnerSpecFromFile(filename)
+	userSpecFileName := filepath.Join("spec", "runner.yaml")
+	layered, errs := workspace.MergeLayers(
+		this.ws.LayerSources(userSpecFileName, userSpecFileName, SpecFileName),
+		func(source workspace.LayerSource) (map[string]interface{}, error) {
+			spec, err := LoadRunnerSpecFromFile(source.Path)
 			if err != nil {
-				this.logger.LeveledPrintf(log.LevelWarn, "Failed to load runner spec file [%s] error: %s", filename, err)
-			} else {
-				// Loaded
-				for name, appSpec := range spec.Apps {
-					apps[name] = appSpec
-				}
+				return nil, err
 			}
-		}
+			apps := make(map[string]interface{})
+			for name, appSpec := range spec.Apps {
+				apps[name] = appSpec
+			}
+			return apps, nil
+		},
+	)
+	for _, err := range errs {
+		this.logger.LeveledPrintf(log.LevelWarn, "%s\n", err)
+	}
+	apps := make(map[string]*RunnerAppSpec)
+	for name, value := range layered {
+		apps[name] = value.Value.(*RunnerAppSpec)
 	}
 	this.Apps = apps
+	this.AppSources = layered
 	// Write debug
 	if this.ws.Verbose {
 		for name, appSpec := range apps {
-			this.logger.LeveledPrintf(log.LevelDebug, "Load application [%s] with command: %s", name, appSpec.Command)
+			this.logger.LeveledPrintf(log.LevelDebug, "Load application [%s] with command: %s\n", name, appSpec.Command)
 		}
 	}
 	// Done
@@ -149,7 +170,7 @@ func (this *AppRunner) GetInstance(id string) (*AppInstance, error) {
 }
 
 func (this *AppRunner) RemoveInstance(id string) error {
-	return os.RemoveAll(filepath.Join(this.rootPath, id))
+	return this.Clean(id)
 }
 
 func (this *AppRunner) GetInstancesByName(name string) ([]*AppInstance, error) {
@@ -205,6 +226,10 @@ func (this *AppRunner) Start(name string, command string, options AppStartOption
 	if command == "" {
 		return nil, errors.New("Require command")
 	}
+	if options.WorkDir == "" {
+		// The work dir of op (e.g. op -C <path>), the current directory if not specified
+		options.WorkDir = this.ws.Options.Dir.CurrentPath
+	}
 	// TODO: We may need a system-wide lock to ensure the singleton
 	if options.Singleton {
 		// Ensure all other apps are stopped
@@ -215,24 +240,21 @@ func (this *AppRunner) Start(name string, command string, options AppStartOption
 		// Check the status
 		for _, instance := range instances {
 			if status, err := instance.GetStatus(); err != nil {
-				return nil, errors.New(fmt.Sprintf("Failed to get the status of process [%d], error: %s", instance.Pid, err))
+				return nil, errors.Wrapf(err, "Failed to get the status of process [%d]", instance.Pid)
 			} else if status == StatusRunning {
 				// Stop it
 				if err := instance.Stop(); err != nil {
-					return nil, errors.New(fmt.Sprintf("Failed to stop process [%d], error: %s", instance.Pid, err))
+					return nil, errors.Wrapf(err, "Failed to stop process [%d]", instance.Pid)
 				}
 			}
 		}
 	}
 	// Start this app
-	id, err := this.getNextRandomID()
+	id, err := workspace.NewRandomID(this.rootPath)
 	if err != nil {
 		return nil, err
 	}
 	instancePath := filepath.Join(this.rootPath, id)
-	if err := os.MkdirAll(instancePath, os.ModePerm); err != nil {
-		return nil, err
-	}
 	var succeed bool = false
 	defer func() {
 		if !succeed {
@@ -276,6 +298,7 @@ func (this *AppRunner) Start(name string, command string, options AppStartOption
 	if this.ws.Verbose {
 		this.logger.LeveledPrintf(log.LevelDebug, "Run command: %s %s\n", cmd.Path, strings.Join(cmd.Args, " "))
 	}
+	environment := this.ws.RecordEnvironment()
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		return nil, err
@@ -288,13 +311,20 @@ func (this *AppRunner) Start(name string, command string, options AppStartOption
 		}
 	}
 	// Good the command is started, write the info
+	sequence, err := this.ws.NextCounter(InstanceCounterPrefix + name)
+	if err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to get the sequence of app [%s], error: %s\n", name, err)
+	}
 	instance := AppInstance{
-		ID:      id,
-		Time:    time.Now(),
-		Name:    name,
-		Command: command,
-		Options: options,
-		Pid:     pid,
+		ID:       id,
+		Sequence: sequence,
+		Time:     time.Now(),
+		Name:     name,
+		Command:  command,
+		Options:  options,
+		Pid:      pid,
+
+		Environment: environment,
 	}
 	data, err := json.Marshal(&instance)
 	if err != nil {
@@ -303,6 +333,7 @@ func (this *AppRunner) Start(name string, command string, options AppStartOption
 	if err := ioutil.WriteFile(filepath.Join(instancePath, InstanceInfoFileName), data, os.ModePerm); err != nil {
 		return nil, err
 	}
+	this.ws.PublishState(workspace.StateKindRunnerInstances, id, &instance)
 	// Done
 	succeed = true
 	return &instance, nil
@@ -314,7 +345,7 @@ func (this *AppRunner) Stop(id string, clean bool) error {
 		return err
 	}
 	if instance == nil {
-		return errors.New("Application instance not found")
+		return &InstanceNotFoundError{ID: id}
 	}
 	if err := instance.Stop(); err != nil {
 		return err
@@ -334,7 +365,7 @@ func (this *AppRunner) Restart(id string, clean bool) (*AppInstance, error) {
 		return nil, err
 	}
 	if instance == nil {
-		return nil, errors.New("Application instance not found")
+		return nil, &InstanceNotFoundError{ID: id}
 	}
 	if err := instance.Stop(); err != nil {
 		return nil, err
@@ -348,7 +379,16 @@ func (this *AppRunner) Restart(id string, clean bool) (*AppInstance, error) {
 }
 
 func (this *AppRunner) Clean(id string) error {
-	return os.RemoveAll(filepath.Join(this.rootPath, id))
+	if err := os.RemoveAll(filepath.Join(this.rootPath, id)); err != nil {
+		return err
+	}
+	this.ws.PublishState(workspace.StateKindRunnerInstances, id, nil)
+	return nil
+}
+
+// Get the data path of the instance
+func (this *AppRunner) GetInstancePath(id string) string {
+	return filepath.Join(this.rootPath, id)
 }
 
 func (this *AppRunner) GetLogFile(id string, stdout bool) string {
@@ -378,12 +418,12 @@ func (this *AppRunner) loadInstances(idFilterFunc func(string) bool, instanceFil
 		// Read the info file
 		data, err := ioutil.ReadFile(filepath.Join(this.rootPath, info.Name(), InstanceInfoFileName))
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Failed to read Info file [%s] not found in instance [%s], error: %s\n", InstanceInfoFileName, info.Name(), err))
+			return nil, errors.Wrapf(err, "Failed to read info file [%s] in instance [%s]", InstanceInfoFileName, info.Name())
 		}
 		var instance AppInstance
 		err = json.Unmarshal(data, &instance)
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Info file [%s] in instance [%s] is broken, error: %s\n", InstanceInfoFileName, info.Name(), err))
+			return nil, errors.Wrapf(err, "Info file [%s] in instance [%s] is broken", InstanceInfoFileName, info.Name())
 		}
 		if instanceFilterFunc == nil || instanceFilterFunc(&instance) {
 			instances = append(instances, &instance)
@@ -392,28 +432,16 @@ func (this *AppRunner) loadInstances(idFilterFunc func(string) bool, instanceFil
 	return instances, nil
 }
 
-func (this *AppRunner) getNextRandomID() (string, error) {
-	for {
-		idBytes := make([]byte, 8)
-		if _, err := rand.Read(idBytes); err != nil {
-			return "", err
-		}
-		idStr := hex.EncodeToString(idBytes)
-		if _, err := os.Stat(filepath.Join(this.rootPath, idStr)); err != nil {
-			if os.IsNotExist(err) {
-				return idStr, nil
-			}
-		}
-	}
-}
-
 type AppInstance struct {
-	ID      string          `json:"id"`
-	Time    time.Time       `json:"time"`
-	Name    string          `json:"name"`
-	Command string          `json:"command"`
-	Options AppStartOptions `json:"options"`
-	Pid     int             `json:"pid"`
+	ID       string          `json:"id"`
+	Sequence int64           `json:"sequence"` // The sequence of the instances of the app, starts from 1
+	Time     time.Time       `json:"time"`
+	Name     string          `json:"name"`
+	Command  string          `json:"command"`
+	Options  AppStartOptions `json:"options"`
+	Pid      int             `json:"pid"`
+
+	Environment *workspace.EnvironmentSnapshot `json:"environment"` // The environment when the instance started
 }
 
 // Wait t
@@ -428,6 +456,20 @@ func (this *AppInstance) Wait() {
 	}
 }
 
+// Wait the instance to exit, the instance is stopped when the context is done (e.g. op is interrupted)
+func (this *AppInstance) WaitContext(ctx context.Context) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			this.Stop()
+		case <-done:
+		}
+	}()
+	this.Wait()
+}
+
 // Get the app instance status
 // Returns: Status, error
 func (this *AppInstance) GetStatus() (int, error) {
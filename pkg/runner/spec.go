@@ -25,6 +25,7 @@ type RunnerAppSpec struct {
 	Workdir   string   `yaml:"workdir"`   // The workdir, will use the directory of the file as the "current directory"
 	Args      []string `yaml:"args"`      // The command args
 	Singleton bool     `yaml:"singleton"` // A singleton app or not
+	Target    string   `yaml:"target"`    // The target built by op up before starting the app, e.g. :server (in the repository of the file)
 }
 
 func LoadRunnerSpecFromFile(p string) (*RunnerSpec, error) {
@@ -4,6 +4,9 @@
 // File Name: artifact.go
 // Description:
 //	The generate artifact collector
+//
+//	The archive artifacts of the collectors are created in <output path>.archives/<name>.<format>, out of the output
+//	path of the target to not be collected by the other collectors
 package builder
 
 import (
@@ -13,12 +16,39 @@ import (
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
 	"path/filepath"
 	"regexp"
+	"sort"
+)
+
+const (
+	ArchiveDirSuffix = ".archives"
 )
 
 func CollectFileArtifactBySpecs(path string, specs map[string]*spec.FileArtifactCollectorSpec) ([]artifact.Artifact, error) {
+	// Collect in the order of names to get a stable result
+	var names []string
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 	var arts []artifact.Artifact
-	for name, artSpec := range specs {
-		art, err := CollectFileArtifactBySpec(name, filepath.Join(path, artSpec.Path), artSpec)
+	for _, name := range names {
+		artSpec := specs[name]
+		var art artifact.Artifact
+		var err error
+		if artSpec.Type == spec.ArtifactCollectorTypeArchive {
+			format := artSpec.Format
+			if format == "" {
+				format = artifact.ArchiveFormatTarGz
+			}
+			art, err = artifact.CreateArchiveArtifact(
+				name,
+				filepath.Join(path, artSpec.Path),
+				filepath.Join(path+ArchiveDirSuffix, fmt.Sprintf("%s.%s", name, format)),
+				artifact.ArchiveOptions{Format: format, Level: artSpec.Level, Excludes: artSpec.Ignores},
+			)
+		} else {
+			art, err = CollectFileArtifactBySpec(name, filepath.Join(path, artSpec.Path), artSpec)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -28,6 +58,9 @@ func CollectFileArtifactBySpecs(path string, specs map[string]*spec.FileArtifact
 }
 
 func CollectFileArtifactBySpec(name, path string, artSpec *spec.FileArtifactCollectorSpec) (artifact.Artifact, error) {
+	if artSpec.Type == spec.ArtifactCollectorTypeDirectory {
+		return artifact.NewDirectoryArtifact(name, path, artSpec.Ignores)
+	}
 	options := artifact.NewDefaultCollectFileArtifactOptions()
 	if artSpec.Includes != "" {
 		exp, err := regexp.Compile(artSpec.Includes)
@@ -13,14 +13,18 @@
 // 			a. Recursively build all targets with build spec defined, and collect the artifact
 // 		3. [Optional] Copy stage:
 // 			a. Copy the artifacts to output directory
+//		4. Write the artifact manifest (see artifact.Manifest) of all built targets to the build temp dir and the output
+//		   directory (if specified), and record the artifacts in the artifact index (see index.go)
 //
 // 	The environment struct
 //		buildTempDir/
-// 			environs/
-// 				buildEnvironment/
-//					...The linked packages, the structure depends on the build type...``
-//					...The environment detail of each type will be documented at the header of source code file of each build type ...
 // 			output/
+//			summary.json
+//			artifacts.json
+//		environTempDir/ (a workspace temp directory, removed after the build)
+// 			buildEnvironment/
+//				...The linked packages, the structure depends on the build type...``
+//				...The environment detail of each type will be documented at the header of source code file of each build type ...
 //
 //	The inject variables (all upper case)
 //		BUILD_ENVIRON_[type]_PATH 			The environment (root) path for a specific build type
@@ -32,6 +36,7 @@
 package builder
 
 import (
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"github.com/ops-openlight/openlight/pkg/artifact"
@@ -43,13 +48,16 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"time"
 )
 
 const (
 	BuilderLogHeader = "SourceCode.Builder"
 
-	BuilderEnvironmentDirName = "environs"
-	BuilderOutputDirName      = "output"
+	BuildDataDirName              = "sourcecode/builder" // The build data directory (relative to the user workdir)
+	BuilderEnvironmentTempPurpose = "builder-environs"
+	BuilderOutputDirName          = "output"
 
 	BuilderDefaultArtifactName = "default"
 )
@@ -69,11 +77,14 @@ type Builder struct {
 	graph           *graph.Graph
 	logger          log.Logger
 	path            string // The build temp path
+	environPath     string // The environment path
 	Options         BuilderOptions
 	Results         map[string]*spec.BuildResult // The global build results, key is target key
 	Environments    map[string]Environment       // The environments, key is build type
 	preparedTargets map[string]bool              // The prepare targets
 	builtTargets    map[string]bool              // The build targets
+	summary         BuildSummary                 // The summary of this build
+	signingKey      ed25519.PrivateKey           // The key to sign the provenances, nil if not signed
 }
 
 // Create a new Builder
@@ -85,7 +96,15 @@ func New(graph *graph.Graph, options BuilderOptions) (*Builder, error) {
 		return nil, errors.New("Require tag")
 	}
 	// Get the build path
-	path, err := graph.Workspace().Dir.User.GetPath(filepath.Join("sourcecode", "builder", options.Tag))
+	path, err := graph.Workspace().Dir.User.GetPath(filepath.Join(BuildDataDirName, options.Tag))
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := loadSigningKey(graph.Workspace())
+	if err != nil {
+		return nil, err
+	}
+	environPath, err := graph.Workspace().TempDir(BuilderEnvironmentTempPurpose)
 	if err != nil {
 		return nil, err
 	}
@@ -94,11 +113,13 @@ func New(graph *graph.Graph, options BuilderOptions) (*Builder, error) {
 		graph:           graph,
 		logger:          graph.Workspace().Logger.GetLoggerWithHeader(BuilderLogHeader),
 		path:            path,
+		environPath:     environPath,
 		Options:         options,
 		Results:         make(map[string]*spec.BuildResult),
 		Environments:    make(map[string]Environment),
 		preparedTargets: make(map[string]bool),
 		builtTargets:    make(map[string]bool),
+		signingKey:      signingKey,
 	}, nil
 }
 
@@ -112,8 +133,9 @@ func (this *Builder) Path() string {
 	return this.path
 }
 
+// The environment path of this builder, a temp directory removed when the workspace is closed
 func (this *Builder) EnvironmentPath() string {
-	return filepath.Join(this.path, BuilderEnvironmentDirName)
+	return this.environPath
 }
 
 func (this *Builder) OutputPath() string {
@@ -130,6 +152,20 @@ func (this *Builder) Build(target *spec.Target) (*spec.BuildResult, error) {
 	if result := this.Results[target.Key()]; result != nil {
 		return result, nil
 	}
+	start := time.Now()
+	result, err := this.build(target)
+	if err := this.recordSummary(target.Key(), start, err); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to write build summary, error: %s\n", err)
+	}
+	if err == nil {
+		if err := this.writeManifest(); err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to write artifact manifest, error: %s", err))
+		}
+	}
+	return result, err
+}
+
+func (this *Builder) build(target *spec.Target) (*spec.BuildResult, error) {
 	var err error
 	// Stage 1. Prepare
 	err = this.graph.Traverse(
@@ -181,6 +217,9 @@ func (this *Builder) Build(target *spec.Target) (*spec.BuildResult, error) {
 }
 
 func (this *Builder) prepareGraphTraverseVisitor(target *spec.Target, from *spec.Target, by *spec.TargetDependencySpec, context interface{}) error {
+	if err := this.graph.Workspace().Canceled(); err != nil {
+		return err
+	}
 	if !this.preparedTargets[target.Key()] {
 		ctx := context.(*BuilderContext)
 		this.logger.LeveledPrintf(log.LevelInfo, "Preparing %s\n", ctx.Tracer.String())
@@ -206,6 +245,9 @@ func (this *Builder) prepareGraphTraverseVisitor(target *spec.Target, from *spec
 }
 
 func (this *Builder) buildGraphTraverseVisitor(target *spec.Target, from *spec.Target, by *spec.TargetDependencySpec, context interface{}) error {
+	if err := this.graph.Workspace().Canceled(); err != nil {
+		return err
+	}
 	if !this.builtTargets[target.Key()] {
 		ctx := context.(*BuilderContext)
 		this.logger.LeveledPrintf(log.LevelInfo, "Building %s\n", ctx.Tracer.String())
@@ -245,16 +287,35 @@ func (this *Builder) copy2Output(target *spec.Target) error {
 	buildResult := this.Results[target.Key()]
 	if buildResult != nil {
 		for _, art := range buildResult.Artifacts {
-			if art.GetType() == artifact.ArtifactTypeFile {
+			var sourceFile, targetFile string
+			switch art.GetType() {
+			case artifact.ArtifactTypeFile:
 				fileArtifact, ok := art.(*artifact.FileArtifact)
 				if !ok {
 					return errors.New("Cannot convert artifact to file artifact")
 				}
-				targetFile := filepath.Join(this.Options.OutputPath, target.Name, art.GetName())
+				sourceFile = fileArtifact.Path
+				targetFile = filepath.Join(this.Options.OutputPath, target.Name, art.GetName())
 				if fileArtifact.Compressed || fileArtifact.Files == nil {
 					// The file artifact is a single file, add the file name
 					targetFile = filepath.Join(targetFile, filepath.Base(fileArtifact.Path))
 				}
+			case artifact.ArtifactTypeDirectory:
+				directoryArtifact, ok := art.(*artifact.DirectoryArtifact)
+				if !ok {
+					return errors.New("Cannot convert artifact to directory artifact")
+				}
+				sourceFile = directoryArtifact.Path
+				targetFile = filepath.Join(this.Options.OutputPath, target.Name, art.GetName())
+			case artifact.ArtifactTypeArchive:
+				archiveArtifact, ok := art.(*artifact.ArchiveArtifact)
+				if !ok {
+					return errors.New("Cannot convert artifact to archive artifact")
+				}
+				sourceFile = archiveArtifact.Path
+				targetFile = filepath.Join(this.Options.OutputPath, target.Name, art.GetName(), filepath.Base(archiveArtifact.Path))
+			}
+			if sourceFile != "" {
 				if _, err := os.Stat(targetFile); err == nil {
 					// Remove it
 					if err := os.Remove(targetFile); err != nil {
@@ -269,7 +330,7 @@ func (this *Builder) copy2Output(target *spec.Target) error {
 					return err
 				}
 				// Link
-				if err := os.Symlink(fileArtifact.Path, targetFile); err != nil {
+				if err := os.Symlink(sourceFile, targetFile); err != nil {
 					return err
 				}
 			}
@@ -325,10 +386,57 @@ func (this *Builder) SetBuildResultDependency(target *spec.Target, buildResult *
 	}
 }
 
+// Add the build result, set the producing target of the artifacts, stat the file artifacts and attach the provenances
 func (this *Builder) AddResult(target *spec.Target, buildResult *spec.BuildResult) {
+	for _, art := range buildResult.Artifacts {
+		art.GetInfo().Target = target.Key()
+		if fileArtifact, ok := art.(*artifact.FileArtifact); ok && fileArtifact.Fingerprint == "" {
+			if err := fileArtifact.Stat(); err != nil {
+				this.logger.LeveledPrintf(log.LevelWarn, "Failed to stat artifact [%s] of target [%s], error: %s\n", art.GetName(), target.Key(), err)
+			}
+		}
+	}
+	this.attachProvenances(target, buildResult)
 	this.Results[target.Key()] = buildResult
 }
 
+// Get the path of the artifact manifest of this builder
+func (this *Builder) ManifestPath() string {
+	return filepath.Join(this.path, artifact.ManifestFileName)
+}
+
+// Write the artifact manifest of all build results
+func (this *Builder) writeManifest() error {
+	var keys []string
+	for key := range this.Results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	manifest := artifact.Manifest{Tag: this.Options.Tag, Time: time.Now()}
+	for _, key := range keys {
+		arts := this.Results[key].Artifacts
+		var names []string
+		for name := range arts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			manifest.Add(arts[name])
+		}
+	}
+	if err := manifest.Write(this.ManifestPath()); err != nil {
+		return err
+	}
+	if err := this.updateArtifactIndex(&manifest); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to update artifact index, error: %s\n", err)
+	}
+	if this.Options.OutputPath != "" {
+		return manifest.Write(filepath.Join(this.Options.OutputPath, artifact.ManifestFileName))
+	}
+	// Done
+	return nil
+}
+
 // Get the target regular key
 func GetTargetRegularKey(target *spec.Target) string {
 	return TargetNameRegularExp.ReplaceAllString(target.Key(), "_")
@@ -360,9 +468,30 @@ type SourceCodeBuilder interface {
 	Build(target *spec.Target, env Environment, context *BuilderContext) error
 }
 
+// Get the build data path which contains a directory of each build tag
+func GetBuildDataPath(ws *workspace.Workspace) (string, error) {
+	return ws.Dir.User.GetPath(BuildDataDirName)
+}
+
+// Get the path of the artifact manifest of the latest build
+func GetLatestManifestPath(ws *workspace.Workspace) (string, error) {
+	summaries, err := LoadBuildSummaries(ws)
+	if err != nil {
+		return "", err
+	}
+	if len(summaries) == 0 {
+		return "", errors.New("No build found")
+	}
+	path, err := GetBuildDataPath(ws)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(path, summaries[0].Tag, artifact.ManifestFileName), nil
+}
+
 // Clean all build data
 func CleanBuildData(ws *workspace.Workspace) error {
-	path, err := ws.Dir.User.GetPath(filepath.Join("sourcecode", "builder"))
+	path, err := GetBuildDataPath(ws)
 	if err != nil {
 		return err
 	}
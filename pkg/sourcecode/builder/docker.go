@@ -18,8 +18,10 @@ import (
 	"github.com/docker/docker/api/types"
 	dockerClient "github.com/docker/docker/client"
 	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
 	"github.com/ops-openlight/openlight/pkg/util"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"io"
@@ -101,11 +103,36 @@ func (this *DockerSourceCodeBuilder) Build(target *spec.Target, env Environment,
 	}
 	// Get docker build files
 	var files []DockerBuildFile
+	var fetch *fetcher.Fetcher
 	for _, f := range dockerSpec.Files {
-		if f.Source.Local != nil && f.Source.Dep != nil {
-			return errors.New("Cannot define local and dep at the same time")
-		} else if f.Source.Local == nil && f.Source.Dep == nil {
-			return errors.New("Require either define local or dep")
+		if n := f.Source.Count(); n > 1 {
+			return errors.New("Cannot define more than one of local, dep and http at the same time")
+		} else if n == 0 {
+			return errors.New("Require either define local, dep or http")
+		} else if f.Source.Http != nil {
+			// A remote file
+			if fetch == nil {
+				if fetch, err = fetcher.New(context.Workspace); err != nil {
+					return err
+				}
+			}
+			url := f.Source.Http.Url
+			if uri.IsOCIURI(url) {
+				// Pin the oci artifact by the lock
+				if url, err = context.Builder.Graph().PinArtifact(url, fetch.Resolve); err != nil {
+					return err
+				}
+			}
+			var path string
+			if f.Source.Http.Extract {
+				path, _, err = fetch.FetchArchive(url, f.Source.Http.Sha256, f.Source.Http.StripPrefix)
+			} else {
+				path, err = fetch.Fetch(url, f.Source.Http.Sha256)
+			}
+			if err != nil {
+				return err
+			}
+			files = append(files, DockerBuildFile{Target: f.Target, Path: path})
 		} else if f.Source.Local != nil {
 			// A local file / dir
 			localPath := filepath.Join(target.Path(), f.Source.Local.Path)
@@ -155,16 +182,25 @@ func (this *DockerSourceCodeBuilder) Build(target *spec.Target, env Environment,
 	if context.Builder.Options.ThirdParty.Docker.Push {
 		// Push the image
 		logger.LeveledPrintf(log.LevelDebug, "Start to push the image [%s]\n", image.Uri())
-		if err := this.pushDockerImage(c, image.Uri()); err != nil {
+		if err := this.pushDockerImage(c, image.Uri(), context.Workspace); err != nil {
 			return errors.New(fmt.Sprintf("Failed to push image [%s], error: %s", image.Uri(), err))
 		}
 		// Push the latest or not
 		if dockerSpec.MarkLatest {
 			logger.LeveledPrintf(log.LevelDebug, "Start to push the image [%s]\n", image.LatestUri())
-			if err := this.pushDockerImage(c, image.LatestUri()); err != nil {
+			if err := this.pushDockerImage(c, image.LatestUri(), context.Workspace); err != nil {
 				return errors.New(fmt.Sprintf("Failed to push image [%s], error: %s", image.LatestUri(), err))
 			}
 		}
+		// Resolve the digest of the pushed image
+		if fetch == nil {
+			if fetch, err = fetcher.New(context.Workspace); err != nil {
+				return err
+			}
+		}
+		if image.Digest, err = fetch.Resolve(image.OCIUri()); err != nil {
+			logger.LeveledPrintf(log.LevelWarn, "Failed to resolve the digest of image [%s], error: %s\n", image.Uri(), err)
+		}
 	}
 	// Write out a image file to output path
 	imageSummaryFile := filepath.Join(outputPath, fmt.Sprintf("%s.json", dockerArtifactName))
@@ -175,6 +211,16 @@ func (this *DockerSourceCodeBuilder) Build(target *spec.Target, env Environment,
 	}
 	// Create artifacts
 	imageArtifact := artifact.NewDockerArtifact(dockerArtifactName, image.Uri(), image.Repository, image.ImageName, image.Tag)
+	imageArtifact.Fingerprint = image.Digest
+	if inspect, _, err := c.ImageInspectWithRaw(context.Workspace.Context(), image.Uri()); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Failed to inspect image [%s], error: %s\n", image.Uri(), err)
+	} else {
+		imageArtifact.Size = inspect.Size
+		if imageArtifact.Fingerprint == "" {
+			// Not pushed, the image id (the digest of the image config) instead
+			imageArtifact.Fingerprint = inspect.ID
+		}
+	}
 	imageSummaryArtifact := artifact.NewSingleFileArtifact(fmt.Sprintf("%s.summary", dockerArtifactName), imageSummaryFile)
 	// Create the build result
 	buildResult := spec.NewBuildResult(target, context.Builder.NewBuildMetadata(target))
@@ -204,6 +250,22 @@ func (this *DockerSourceCodeBuilder) AddFileFromArtifact(target string, art arti
 		}
 		files = append(files, DockerBuildFile{Target: target, Path: fileArtifact.Path})
 		return files, nil
+	case *artifact.DirectoryArtifact:
+		// Directory artifact
+		directoryArtifact := art.(*artifact.DirectoryArtifact)
+		if _, err := os.Stat(directoryArtifact.Path); err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to check local directory [%s], error: %s", directoryArtifact.Path, err))
+		}
+		files = append(files, DockerBuildFile{Target: target, Path: directoryArtifact.Path})
+		return files, nil
+	case *artifact.ArchiveArtifact:
+		// Archive artifact, added as the archive file
+		archiveArtifact := art.(*artifact.ArchiveArtifact)
+		if _, err := os.Stat(archiveArtifact.Path); err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to check local file [%s], error: %s", archiveArtifact.Path, err))
+		}
+		files = append(files, DockerBuildFile{Target: target, Path: archiveArtifact.Path})
+		return files, nil
 	}
 }
 
@@ -246,6 +308,7 @@ type DockerImage struct {
 	Tag        string            `json:"tag"`
 	Dockerfile string            `json:"dockerfile"` // The dockerfile content, NOT the dockerfile path!!!!
 	Files      []DockerBuildFile `json:"files"`
+	Digest     string            `json:"digest,omitempty"` // The manifest digest, only resolved when pushed
 }
 
 func (this *DockerImage) Uri() string {
@@ -256,6 +319,21 @@ func (this *DockerImage) Uri() string {
 	}
 }
 
+// Get the oci url of the image, the images without registry are in docker hub
+func (this *DockerImage) OCIUri() string {
+	repository := this.ImageName
+	if this.Repository != "" {
+		repository = fmt.Sprintf("%s/%s", this.Repository, this.ImageName)
+	}
+	registry := fetcher.DockerHubHost
+	if idx := strings.Index(repository, "/"); idx != -1 && strings.ContainsAny(repository[:idx], ".:") {
+		registry, repository = repository[:idx], repository[idx+1:]
+	} else if idx == -1 {
+		repository = "library/" + repository
+	}
+	return fmt.Sprintf("%s://%s/%s:%s", uri.SchemeOCI, registry, repository, this.Tag)
+}
+
 func (this *DockerImage) LatestUri() string {
 	if this.Repository != "" {
 		return fmt.Sprintf("%s/%s:latest", this.Repository, this.ImageName)
@@ -288,7 +366,7 @@ func (this *DockerSourceCodeBuilder) buildDockerImage(c *dockerClient.Client, im
 		contextReader.Close()
 		contextWriter.Close()
 	}()
-	runCtx, cancel := context.WithCancel(context.Background())
+	runCtx, cancel := context.WithCancel(ctx.Workspace.Context())
 	defer cancel()
 	// Run the tar builder in another goroutine
 	go func() {
@@ -382,10 +460,15 @@ func (this *DockerSourceCodeBuilder) buildDockerImage(c *dockerClient.Client, im
 	return nil
 }
 
-func (this *DockerSourceCodeBuilder) pushDockerImage(c *dockerClient.Client, uri string) error {
+func (this *DockerSourceCodeBuilder) pushDockerImage(c *dockerClient.Client, uri string, ws *workspace.Workspace) error {
+	// Get the registry credential
+	auth, err := getDockerRegistryAuth(ws, uri)
+	if err != nil {
+		return err
+	}
 	// Push docker image
-	raw, _ := json.Marshal(types.AuthConfig{})
-	if rsp, err := c.ImagePush(context.Background(), uri, types.ImagePushOptions{RegistryAuth: base64.URLEncoding.EncodeToString(raw)}); err != nil {
+	raw, _ := json.Marshal(auth)
+	if rsp, err := c.ImagePush(ws.Context(), uri, types.ImagePushOptions{RegistryAuth: base64.URLEncoding.EncodeToString(raw)}); err != nil {
 		return err
 	} else {
 		defer rsp.Close()
@@ -404,6 +487,25 @@ func (this *DockerSourceCodeBuilder) pushDockerImage(c *dockerClient.Client, uri
 	}
 }
 
+// Get the registry credential of the image (see fetcher.GetRegistryCredential)
+func getDockerRegistryAuth(ws *workspace.Workspace, uri string) (types.AuthConfig, error) {
+	var auth types.AuthConfig
+	// The registry is the first part of the image uri if it looks like a host, otherwise docker hub
+	host := fetcher.DockerHubHost
+	if idx := strings.Index(uri, "/"); idx != -1 && strings.ContainsAny(uri[:idx], ".:") {
+		host = uri[:idx]
+	}
+	credential, err := fetcher.GetRegistryCredential(ws, host)
+	if err != nil {
+		return auth, err
+	}
+	if credential != nil {
+		auth.Username, auth.Password, auth.ServerAddress = credential.Username, credential.Secret, fetcher.GetRegistryServerAddress(host)
+	}
+	// Done
+	return auth, nil
+}
+
 func (this *DockerSourceCodeBuilder) writePath2Tar(p string, targetPath string, writer *tar.Writer) error {
 	// Write a path to tar
 	// Get the real path and info
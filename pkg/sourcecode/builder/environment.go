@@ -114,7 +114,7 @@ func GeneralLink(target *spec.Target, link *spec.SourceCodeLink, dest string) er
 	if _, err := os.Stat(linkTargetName); err == nil {
 		return errors.New(fmt.Sprintf("Target [%s] already existed for target [%s] source [%s]", linkTargetName, target.Key(), link.Path))
 	} else if !os.IsNotExist(err) {
-		return errors.New(fmt.Sprintf("Failed to check link target for target [%s] source [%s] dest [%s], error: %s", target.Key(), link.Path, linkTargetName))
+		return errors.New(fmt.Sprintf("Failed to check link target for target [%s] source [%s] dest [%s], error: %s", target.Key(), link.Path, linkTargetName, err))
 	}
 	// Link it
 	return os.Symlink(filepath.Join(target.Path(), link.Path), linkTargetName)
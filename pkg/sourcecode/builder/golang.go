@@ -17,7 +17,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -122,20 +121,17 @@ func (this *GolangSourceCodeBuilder) Build(target *spec.Target, env Environment,
 		// The build package
 		buildArgs = append(buildArgs, buildPackage)
 		// Create the command
-		cmd := exec.Command("go", buildArgs...)
+		cmd := context.Workspace.Command("go", buildArgs...)
 		cmd.Dir = env.Path()
-		if context.Workspace.Verbose {
-			// Connect stdout and stderr
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-		} else {
-			// Ignore the stderr and stdout
-			cmd.Stdout = nil
-			cmd.Stderr = nil
-		}
+		// Pipe stdout and stderr through the logger (shown in verbose mode and always written to the log file)
+		output := logger.WriterAt(log.LevelDebug, "")
+		cmd.Stdout = output
+		cmd.Stderr = output
 		// Run go build
 		logger.LeveledPrintf(log.LevelDebug, "Run command: %s %s\n", cmd.Path, strings.Join(cmd.Args, " "))
-		if err := cmd.Run(); err != nil {
+		err := cmd.Run()
+		output.Close()
+		if err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,210 @@
+// Author: lipixun
+// Created Time : 日 10/18 13:42:51 2026
+//
+// File Name: index.go
+// Description:
+//	The artifact index
+//
+//	Each successful build records its artifacts in <user>/sourcecode/builder/index.json, so the locally produced
+//	artifacts could be listed across the build tags without reading every manifest (see op artifact list / inspect).
+//	An entry is identified by the build tag, the target and the artifact name, rebuilding a tag replaces its entries.
+//	The entries of the removed build tags (e.g. by op workspace clean builds) are pruned when the index is loaded, and
+//	only the newest ArtifactIndexMaxEntries entries are kept.
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	ArtifactIndexFileName   = "index.json"
+	ArtifactIndexMaxEntries = 1000
+)
+
+// The artifact index
+type ArtifactIndex struct {
+	Entries []*ArtifactIndexEntry `json:"entries"` // Sorted from the newest to the oldest
+}
+
+// An artifact of a build
+type ArtifactIndexEntry struct {
+	Target      string    `json:"target"`      // The target key
+	Name        string    `json:"name"`        // The artifact name
+	Type        string    `json:"type"`        // The artifact type
+	Tag         string    `json:"tag"`         // The build tag
+	Number      int64     `json:"number"`      // The build number of the target, 0 if built as a dependency
+	Time        time.Time `json:"time"`        // The time of the build
+	Size        int64     `json:"size"`        // See artifact.Info
+	Fingerprint string    `json:"fingerprint"` // See artifact.Info
+	Manifest    string    `json:"manifest"`    // The path of the artifact manifest of the build
+	Repository  string    `json:"repository"`  // The repository uri of the target
+	Branch      string    `json:"branch"`      // The branch of the repository when built
+	Commit      string    `json:"commit"`      // The commit of the repository when built
+}
+
+// The version of the entry, #<build number> or the build tag if built as a dependency
+func (this *ArtifactIndexEntry) Version() string {
+	if this.Number > 0 {
+		return fmt.Sprintf("#%d", this.Number)
+	}
+	return this.Tag
+}
+
+// Whether the entry matches the version, which is the build number (with or without #) or the build tag (prefix)
+func (this *ArtifactIndexEntry) MatchVersion(version string) bool {
+	if number, err := strconv.ParseInt(strings.TrimPrefix(version, "#"), 10, 64); err == nil && number == this.Number {
+		return true
+	}
+	return version != "" && strings.HasPrefix(this.Tag, version)
+}
+
+// Whether the entry matches the target, which is the target key or the target name (the key ends with :<name>)
+func (this *ArtifactIndexEntry) MatchTarget(target string) bool {
+	return this.Target == target || strings.HasSuffix(this.Target, ":"+target)
+}
+
+// Load the manifest and the artifact of the entry
+func (this *ArtifactIndexEntry) Load() (*artifact.Manifest, artifact.Artifact, error) {
+	manifest, err := artifact.ReadManifest(this.Manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, art := range manifest.Find(this.Target) {
+		if art.GetName() == this.Name {
+			return manifest, art, nil
+		}
+	}
+	return nil, nil, errors.New(fmt.Sprintf("Artifact [%s] of target [%s] not found in manifest [%s]", this.Name, this.Target, this.Manifest))
+}
+
+// Find the entries of the target, all entries if the target is empty
+func (this *ArtifactIndex) Find(target string) []*ArtifactIndexEntry {
+	var entries []*ArtifactIndexEntry
+	for _, entry := range this.Entries {
+		if target == "" || entry.MatchTarget(target) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Get the path of the artifact index
+func GetArtifactIndexPath(ws *workspace.Workspace) (string, error) {
+	path, err := GetBuildDataPath(ws)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(path, ArtifactIndexFileName), nil
+}
+
+// Load the artifact index, the entries of the removed builds are pruned
+func LoadArtifactIndex(ws *workspace.Workspace) (*ArtifactIndex, error) {
+	path, err := GetArtifactIndexPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	return loadArtifactIndex(path)
+}
+
+func loadArtifactIndex(path string) (*ArtifactIndex, error) {
+	var index ArtifactIndex
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &index, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, errors.New(fmt.Sprintf("Broken artifact index [%s], error: %s", path, err))
+	}
+	entries := index.Entries[:0]
+	for _, entry := range index.Entries {
+		if _, err := os.Stat(entry.Manifest); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	index.Entries = entries
+	// Done
+	return &index, nil
+}
+
+// Record the artifacts of all build results in the artifact index
+func (this *Builder) updateArtifactIndex(manifest *artifact.Manifest) error {
+	path, err := GetArtifactIndexPath(this.graph.Workspace())
+	if err != nil {
+		return err
+	}
+	// Lock, the builds could run concurrently
+	lockFile, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer lockFile.Close()
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+	index, err := loadArtifactIndex(path)
+	if err != nil {
+		return err
+	}
+	numbers := make(map[string]int64)
+	for _, target := range this.summary.Targets {
+		numbers[target.Target] = target.Number
+	}
+	entries := make([]*ArtifactIndexEntry, 0, len(index.Entries)+len(manifest.Artifacts))
+	for _, art := range manifest.Artifacts {
+		info := art.GetInfo()
+		entry := &ArtifactIndexEntry{
+			Target:      info.Target,
+			Name:        art.GetName(),
+			Type:        art.GetType(),
+			Tag:         this.Options.Tag,
+			Number:      numbers[info.Target],
+			Time:        manifest.Time,
+			Size:        info.Size,
+			Fingerprint: info.Fingerprint,
+			Manifest:    this.ManifestPath(),
+		}
+		if result := this.Results[info.Target]; result != nil {
+			entry.Repository = result.Repository
+			entry.Branch = result.Metadata.Repository.Branch
+			entry.Commit = result.Metadata.Repository.Commit
+		}
+		entries = append(entries, entry)
+	}
+	for _, entry := range index.Entries {
+		if entry.Tag != this.Options.Tag {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	if len(entries) > ArtifactIndexMaxEntries {
+		entries = entries[:ArtifactIndexMaxEntries]
+	}
+	index.Entries = entries
+	// Write atomically
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path+".tmp", data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
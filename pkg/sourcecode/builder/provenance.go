@@ -0,0 +1,118 @@
+// Author: lipixun
+// Created Time : 日 10/18 15:48:03 2026
+//
+// File Name: provenance.go
+// Description:
+//	Attach the provenance (see artifact/provenance.go) to the built artifacts
+//
+//	The provenance is signed when the config artifact.signingkey (the file of the base64 ed25519 private key) is set,
+//	the key is loaded when the builder is created so a broken key fails the build before building anything.
+//	The artifacts without fingerprint have no provenance.
+package builder
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/yaml.v2"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	// The version of op recorded as the builder version of the provenances, set by cli/op/main.go
+	BuilderVersion string
+)
+
+// Load the signing key of the user or global config, nil if not set
+func loadSigningKey(ws *workspace.Workspace) (ed25519.PrivateKey, error) {
+	path := ws.GetUserConfigString(workspace.ConfigKeyArtifactSigningKey)
+	if path == "" {
+		return nil, nil
+	}
+	key, err := artifact.LoadPrivateKey(path)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to load the signing key of config %s, error: %s", workspace.ConfigKeyArtifactSigningKey, err))
+	}
+	return key, nil
+}
+
+// Attach the provenance to the artifacts of the build result
+func (this *Builder) attachProvenances(target *spec.Target, buildResult *spec.BuildResult) {
+	finished := time.Now().UTC()
+	host, _ := os.Hostname()
+	// The build parameters
+	parameters := map[string]string{
+		"tag":        this.Options.Tag,
+		"build.type": target.Spec.Build.Type,
+	}
+	if target.Spec.Build.Type == BuilderTypeDocker {
+		parameters["docker.push"] = strconv.FormatBool(this.Options.ThirdParty.Docker.Push)
+	}
+	for name, value := range buildResult.Metadata.BuildParams {
+		parameters["params."+name] = fmt.Sprintf("%v", value)
+	}
+	// The source
+	source := artifact.ProvenanceSource{
+		Repository: buildResult.Repository,
+		Branch:     buildResult.Metadata.Repository.Branch,
+		Commit:     buildResult.Metadata.Repository.Commit,
+		Target:     target.Key(),
+	}
+	if data, err := yaml.Marshal(target.Spec); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to hash the spec of target [%s], error: %s\n", target.Key(), err)
+	} else {
+		sum := sha256.Sum256(data)
+		source.SpecHash = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	// The dependencies
+	var dependencies []artifact.ProvenanceDependency
+	for _, dep := range buildResult.Deps {
+		for _, art := range dep.Artifacts {
+			dependencies = append(dependencies, artifact.ProvenanceDependency{
+				Target:      art.GetInfo().Target,
+				Name:        art.GetName(),
+				Fingerprint: art.GetInfo().Fingerprint,
+			})
+		}
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Target != dependencies[j].Target {
+			return dependencies[i].Target < dependencies[j].Target
+		}
+		return dependencies[i].Name < dependencies[j].Name
+	})
+	for _, art := range buildResult.Artifacts {
+		if art.GetInfo().Fingerprint == "" {
+			continue
+		}
+		provenance := artifact.NewProvenance(art)
+		provenance.Builder = artifact.ProvenanceBuilder{
+			ID:      "op/" + buildResult.Metadata.Builder,
+			Version: BuilderVersion,
+			Host:    host,
+			OS:      runtime.GOOS,
+			Arch:    runtime.GOARCH,
+		}
+		provenance.Source = source
+		provenance.Dependencies = dependencies
+		provenance.Parameters = parameters
+		provenance.StartedTime = finished.Add(-time.Duration(buildResult.Metadata.BuildTimeUsage * float64(time.Second)))
+		provenance.FinishedTime = finished
+		if this.signingKey != nil {
+			if err := provenance.Sign(this.signingKey); err != nil {
+				this.logger.LeveledPrintf(log.LevelWarn, "Failed to sign the provenance of artifact [%s] of target [%s], error: %s\n", art.GetName(), target.Key(), err)
+			}
+		}
+		art.GetInfo().Provenance = provenance
+	}
+}
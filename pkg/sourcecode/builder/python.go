@@ -21,7 +21,6 @@ import (
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -151,21 +150,18 @@ func (this *PythonSourceCodeBuilder) runScriptBuild(target *spec.Target, env Env
 		context.Builder.Options.Time,
 	)...)
 	// Create the command
-	cmd := exec.Command("python", args...)
+	cmd := context.Workspace.Command("python", args...)
 	cmd.Dir = sourcePath
 	cmd.Env = environVars
-	if context.Workspace.Verbose {
-		// Connect stdout and stderr
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-	} else {
-		// Ignore the stderr and stdout
-		cmd.Stdout = nil
-		cmd.Stderr = nil
-	}
+	// Pipe stdout and stderr through the logger (shown in verbose mode and always written to the log file)
+	output := logger.WriterAt(log.LevelDebug, "")
+	cmd.Stdout = output
+	cmd.Stderr = output
 	// Run go build
 	logger.LeveledPrintf(log.LevelDebug, "Run command: %s %s\n", cmd.Path, strings.Join(cmd.Args, " "))
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	output.Close()
+	if err != nil {
 		return err
 	}
 	// Collect the artifacts in the output directory
@@ -300,25 +296,22 @@ func (this *PythonSourceCodeBuilder) runNuitkaBuild(target *spec.Target, env Env
 		return errors.New("Build as a python module via nuitka is not supported yet")
 	}
 	// Run the command
-	cmd := exec.Command("nuitka", args...)
+	cmd := context.Workspace.Command("nuitka", args...)
 	cmd.Env = environVars
 	cmd.Dir = sourcePath
 	// Run nuitka
-	if context.Workspace.Verbose {
-		// Connect stdout and stderr
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-	} else {
-		// Ignore the stderr and stdout
-		cmd.Stdout = nil
-		cmd.Stderr = nil
-	}
+	// Pipe stdout and stderr through the logger (shown in verbose mode and always written to the log file)
+	output := logger.WriterAt(log.LevelDebug, "")
+	cmd.Stdout = output
+	cmd.Stderr = output
 	// Run go build
 	if context.Workspace.Verbose {
-		logger.LeveledPrintf(log.LevelDebug, "Run command: %s %s\n", strings.Join(cmd.Args, " "))
+		logger.LeveledPrintf(log.LevelDebug, "Run command: %s %s\n", cmd.Path, strings.Join(cmd.Args, " "))
 		logger.LeveledPrintf(log.LevelDebug, "Environment Variables: %s\n", strings.Join(environVars, ";"))
 	}
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	output.Close()
+	if err != nil {
 		return err
 	}
 	// Rename the output file
@@ -15,7 +15,6 @@ import (
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -93,21 +92,18 @@ func (this *ShellSourceCodeBuilder) Build(target *spec.Target, env Environment,
 	// Create the command
 	var args []string
 	args = append(args, shellSpec.Args...)
-	cmd := exec.Command(shellSpec.Command, args...)
+	cmd := context.Workspace.Command(shellSpec.Command, args...)
 	cmd.Dir = workDir
 	cmd.Env = append(os.Environ(), environVars...)
-	if context.Workspace.Verbose {
-		// Connect stdout and stderr
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-	} else {
-		// Ignore the stderr and stdout
-		cmd.Stdout = nil
-		cmd.Stderr = nil
-	}
+	// Pipe stdout and stderr through the logger (shown in verbose mode and always written to the log file)
+	output := logger.WriterAt(log.LevelDebug, "")
+	cmd.Stdout = output
+	cmd.Stderr = output
 	// Run shell command
 	logger.LeveledPrintf(log.LevelDebug, "Run command: %s %s\n", cmd.Path, strings.Join(cmd.Args, " "))
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	output.Close()
+	if err != nil {
 		return err
 	}
 	// Collect the artifacts
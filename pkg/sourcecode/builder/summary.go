@@ -0,0 +1,121 @@
+// Author: lipixun
+// Created Time : 六 10/17 01:30:14 2026
+//
+// File Name: summary.go
+// Description:
+//	The build summary
+//
+//	Each build tag records the outcome of the built targets in <user>/sourcecode/builder/<tag>/summary.json
+package builder
+
+import (
+	"encoding/json"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	BuildSummaryFileName = "summary.json"
+
+	BuildStatusSucceeded = "succeeded"
+	BuildStatusFailed    = "failed"
+
+	BuildCounterPrefix = "build." // The counter name prefix of the build number of a target
+)
+
+// The summary of a build tag
+type BuildSummary struct {
+	Tag     string                `json:"tag"`     // The build tag
+	Time    time.Time             `json:"time"`    // The time of the last build
+	Targets []*BuildSummaryTarget `json:"targets"` // The built targets in order
+
+	Environment *workspace.EnvironmentSnapshot `json:"environment"` // The environment when the build started
+}
+
+// The outcome of a target
+type BuildSummaryTarget struct {
+	Target   string  `json:"target"`   // The target key
+	Number   int64   `json:"number"`   // The build number of the target, starts from 1
+	Status   string  `json:"status"`   // The status, one of BuildStatus*
+	Duration float64 `json:"duration"` // The build time in seconds
+	Error    string  `json:"error"`    // The error message if failed
+}
+
+// Get the status of the build, failed if any target is failed
+func (this *BuildSummary) Status() string {
+	for _, target := range this.Targets {
+		if target.Status == BuildStatusFailed {
+			return BuildStatusFailed
+		}
+	}
+	return BuildStatusSucceeded
+}
+
+// Record the outcome of the target to the summary file
+func (this *Builder) recordSummary(target string, start time.Time, err error) error {
+	this.summary.Tag = this.Options.Tag
+	this.summary.Time = time.Now()
+	if this.summary.Environment == nil {
+		this.summary.Environment = this.graph.Workspace().RecordEnvironment()
+	}
+	summaryTarget := &BuildSummaryTarget{Target: target, Status: BuildStatusSucceeded, Duration: time.Now().Sub(start).Seconds()}
+	if number, err := this.graph.Workspace().NextCounter(BuildCounterPrefix + target); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to get the build number of target [%s], error: %s\n", target, err)
+	} else {
+		summaryTarget.Number = number
+	}
+	if err != nil {
+		summaryTarget.Status = BuildStatusFailed
+		summaryTarget.Error = err.Error()
+	}
+	this.summary.Targets = append(this.summary.Targets, summaryTarget)
+	data, err := json.Marshal(this.summary)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(this.path, BuildSummaryFileName), data, 0666); err != nil {
+		return err
+	}
+	this.graph.Workspace().PublishState(workspace.StateKindBuilds, this.summary.Tag, &this.summary)
+	return nil
+}
+
+// Load the summaries of all build tags
+// Returns:
+//
+//	The summaries sorted from the newest to the oldest, tags without summary are ignored
+func LoadBuildSummaries(ws *workspace.Workspace) ([]*BuildSummary, error) {
+	path, err := GetBuildDataPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var summaries []*BuildSummary
+	for _, info := range infos {
+		data, err := ioutil.ReadFile(filepath.Join(path, info.Name(), BuildSummaryFileName))
+		if err != nil {
+			continue
+		}
+		var summary BuildSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			continue
+		}
+		summaries = append(summaries, &summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Time.After(summaries[j].Time)
+	})
+	// Done
+	return summaries, nil
+}
@@ -0,0 +1,28 @@
+// Author: lipixun
+// Created Time : 六 10/17 22:48:30 2026
+//
+// File Name: errors.go
+// Description:
+//	The errors of the graph
+package graph
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/errors"
+)
+
+// The error of a target which is not defined in the repository spec
+type TargetNotFoundError struct {
+	Target     string // The target name
+	Repository string // The repository uri
+}
+
+func (this *TargetNotFoundError) Error() string {
+	return fmt.Sprintf("Target [%s] not found in repository [%s]", this.Target, this.Repository)
+}
+
+// Check if the error (or its cause) is a TargetNotFoundError
+func IsTargetNotFoundError(err error) bool {
+	_, ok := errors.Cause(err).(*TargetNotFoundError)
+	return ok
+}
@@ -11,11 +11,14 @@ import (
 	"fmt"
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/sourcecode"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repofetcher"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/repofinder"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/repoloader"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"strings"
+	"time"
 )
 
 const (
@@ -33,11 +36,15 @@ type Graph struct {
 	Repositories     map[string]*spec.Repository
 	Targets          map[string]*spec.Target
 	RemoteOverwrites map[string]string // Key is uri, value is remote
+	Lock             *Lock             // Load the remote repositories at the locked commits if not nil
+	Resolved         *Lock             // The resolved remote repositories
+	missing          []string          // The dependencies not available offline
 }
 
 type GraphOptions struct {
 	UseLocalDependency bool // Whether to use local repository to resolve the dependency
 	DisableFinder      bool
+	Trace              bool // Log the time of loading each repository and target
 }
 
 func New(ws *workspace.Workspace, options GraphOptions) (*Graph, error) {
@@ -52,6 +59,7 @@ func New(ws *workspace.Workspace, options GraphOptions) (*Graph, error) {
 		Repositories:     make(map[string]*spec.Repository),
 		Targets:          make(map[string]*spec.Target),
 		RemoteOverwrites: make(map[string]string),
+		Resolved:         &Lock{Version: LockFileVersion},
 	}, nil
 }
 
@@ -60,16 +68,36 @@ func (this *Graph) Workspace() *workspace.Workspace {
 }
 
 type LoadOptions struct {
-	Uri     string // The expected uri of the loading repository
-	Type    string
-	Branch  string
-	Commit  string
-	Targets []string
+	Uri         string // The expected uri of the loading repository
+	Type        string
+	Branch      string
+	Commit      string
+	Tag         string
+	Ref         string // A branch, tag or commit
+	Path        string // The sub path of the repository
+	Sha256      string // The expected sha256 digest of the archive repository
+	StripPrefix string // The root directory in the archive repository
+	Submodules  *bool  // Initialize the submodules, the workspace config if nil
+	LFS         *bool  // Pull the git lfs objects, the workspace config if nil
+	Targets     []string
 }
 
 // Load a repository
+// In offline mode, the dependencies not available locally are collected and reported at once
 func (this *Graph) Load(remote string, options LoadOptions) (*spec.Repository, error) {
-	return this.load(remote, options, sourcecode.NewTracer())
+	repo, err := this.load(remote, options, sourcecode.NewTracer())
+	if offlineErr, ok := err.(*workspace.OfflineError); ok {
+		this.missing = append(this.missing, offlineErr.Resource)
+	} else if err != nil {
+		return nil, err
+	}
+	if len(this.missing) > 0 {
+		return nil, errors.New(fmt.Sprintf(
+			"%d remote dependencies are not available offline, fetch them once with network access:\n\t%s",
+			len(this.missing), strings.Join(this.missing, "\n\t"),
+		))
+	}
+	return repo, nil
 }
 
 // Load a repository
@@ -90,6 +118,31 @@ func (this *Graph) load(remote string, options LoadOptions, tracer *sourcecode.T
 			remote = _remote
 		}
 	}
+	// Apply the rewrite rules, the ref in the remote and the lock
+	// The lock records the remote before rewritten to be portable between the mirrored and the public environments
+	lockRemote := remote
+	remote, err := this.rewriteRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	remote, err = applyRemoteRef(remote, &options)
+	if err != nil {
+		return nil, err
+	}
+	t := options.Type
+	if t == "" {
+		if repoloader.IsArchive(remote) {
+			t = repoloader.RepositoryTypeArchive
+		} else {
+			t = spec.DefaultRepositoryType
+		}
+	}
+	isRemote := repofetcher.IsRemote(remote) || t == repoloader.RepositoryTypeArchive
+	requestedRef := options.requestedRef()
+	var locked *LockedRepository
+	if isRemote {
+		locked = this.applyLock(lockRemote, &options)
+	}
 	// Check the loaded repositories
 	if options.Uri != "" {
 		loadedRepo, ok := this.Repositories[options.Uri]
@@ -108,22 +161,37 @@ func (this *Graph) load(remote string, options LoadOptions, tracer *sourcecode.T
 		}
 	}
 	// Load this repository
-	t := options.Type
-	if t == "" {
-		t = spec.DefaultRepositoryType
-	}
 	loader := repoloader.GetLoader(t)
 	if loader == nil {
 		return nil, errors.New(fmt.Sprintf("Repository loader for type [%s] not found", t))
 	}
-	loadingRepo, err := loader.Load(remote, repoloader.LoadOptions{Branch: options.Branch, Commit: options.Commit}, this.ws)
+	startTime := time.Now()
+	loadingRepo, err := loader.Load(remote, repoloader.LoadOptions{
+		Branch:      options.Branch,
+		Commit:      options.Commit,
+		Tag:         options.Tag,
+		Ref:         options.Ref,
+		Path:        options.Path,
+		Sha256:      options.Sha256,
+		StripPrefix: options.StripPrefix,
+		Submodules:  options.Submodules,
+		LFS:         options.LFS,
+	}, this.ws)
 	if err != nil {
 		return nil, err
 	}
+	if this.Options.Trace {
+		this.logger.LeveledPrintf(log.LevelInfo, "[Trace] Repository [%s] loaded from spec [%s] in %s\n", loadingRepo.Uri, loadingRepo.SpecFile, time.Now().Sub(startTime))
+	}
 	if options.Uri != "" && loadingRepo.Uri != options.Uri {
 		this.logger.LeveledPrintf(log.LevelError, "Mismatch repository uri. Expected [%s] Actually [%s]\n", options.Uri, loadingRepo.Uri)
 		return nil, errors.New("Mismatch repository uri")
 	}
+	if isRemote {
+		if err := this.recordLock(loadingRepo, lockRemote, requestedRef, locked); err != nil {
+			return nil, err
+		}
+	}
 	loadedRepo, ok := this.Repositories[loadingRepo.Uri]
 	if ok {
 		// Compare the two repository
@@ -153,8 +221,8 @@ func (this *Graph) load(remote string, options LoadOptions, tracer *sourcecode.T
 func (this *Graph) resolve(r *spec.Repository, targets []string, tracer *sourcecode.Tracer) error {
 	if len(targets) == 0 {
 		// Load all targets in this repository
-		for targetName, targetSpec := range r.Spec.Targets {
-			_, err := this.loadTarget(targetName, targetSpec, r, tracer)
+		for _, targetName := range r.Spec.GetTargetNames() {
+			_, err := this.loadTarget(targetName, r.Spec.Targets[targetName], r, tracer)
 			if err != nil {
 				return err
 			}
@@ -163,8 +231,8 @@ func (this *Graph) resolve(r *spec.Repository, targets []string, tracer *sourcec
 		for _, targetName := range targets {
 			targetSpec, ok := r.Spec.Targets[targetName]
 			if !ok {
-				this.logger.LeveledPrintf(log.LevelError, "Target [%s] not found in repository [%s]\n", targetName, r.Uri)
-				return errors.New(fmt.Sprintf("Target [%s] not found in repository [%s]", targetName, r.Uri))
+				this.logger.LeveledPrintf(log.LevelError, "Target [%s] not found in repository [%s] spec [%s]\n", targetName, r.Uri, r.SpecFile)
+				return &TargetNotFoundError{Target: targetName, Repository: r.Uri}
 			}
 			_, err := this.loadTarget(targetName, targetSpec, r, tracer)
 			if err != nil {
@@ -192,6 +260,12 @@ func (this *Graph) loadTarget(targetName string, targetSpec *spec.TargetSpec, r
 	// Push into tracer
 	tracer.Push(sourcecode.TraceTypeTarget, targetKey, targetName)
 	defer tracer.Pop()
+	if this.Options.Trace {
+		startTime := time.Now()
+		defer func() {
+			this.logger.LeveledPrintf(log.LevelInfo, "[Trace] Target [%s] declared in spec [%s] loaded with dependencies in %s\n", targetKey, r.SpecFile, time.Now().Sub(startTime))
+		}()
+	}
 	this.logger.LeveledPrintf(log.LevelInfo, "Loading %s\n", tracer.String())
 	target = &spec.Target{
 		Name:       targetName,
@@ -199,7 +273,8 @@ func (this *Graph) loadTarget(targetName string, targetSpec *spec.TargetSpec, r
 		Spec:       targetSpec,
 	}
 	// Resolve the dependency
-	for depName, depSpec := range target.Spec.Deps {
+	for _, depName := range target.Spec.Deps.Names() {
+		depSpec := target.Spec.Deps[depName]
 		if depSpec.Repository == "" {
 			// Set the repository to the repository of current target
 			depSpec.Repository = r.Uri
@@ -229,8 +304,8 @@ func (this *Graph) resolveTargetDependency(name, targetName, repository string,
 		// Load the target from repository itself
 		targetSpec, ok := target.Repository.Spec.Targets[targetName]
 		if !ok {
-			this.logger.LeveledPrintf(log.LevelError, "Target [%s] not found in repository [%s]\n", targetName, target.Repository.Uri)
-			return errors.New("Dependent target not found")
+			this.logger.LeveledPrintf(log.LevelError, "Target [%s] (dependency [%s] of target [%s]) not found in repository [%s] spec [%s]\n", targetName, name, target.Name, target.Repository.Uri, target.Repository.SpecFile)
+			return &TargetNotFoundError{Target: targetName, Repository: target.Repository.Uri}
 		}
 		_, err := this.loadTarget(targetName, targetSpec, target.Repository, tracer)
 		return err
@@ -238,7 +313,7 @@ func (this *Graph) resolveTargetDependency(name, targetName, repository string,
 	// Get the repository reference info
 	refer, ok := target.Repository.Spec.References[repository]
 	if !ok {
-		this.logger.LeveledPrintf(log.LevelError, "Repository reference of [%s] not found\n", repository)
+		this.logger.LeveledPrintf(log.LevelError, "Repository reference of [%s] (dependency [%s] of target [%s]) not found in spec [%s]\n", repository, name, target.Name, target.Repository.SpecFile)
 		return errors.New("Repository reference not found")
 	}
 	remote := refer.Remote
@@ -247,11 +322,14 @@ func (this *Graph) resolveTargetDependency(name, targetName, repository string,
 		// Find the repository by finder
 		finder := repofinder.GetFinder(refer.Finder.Type)
 		if finder == nil {
-			this.logger.LeveledPrintf(log.LevelWarn, "Repository finder for [%s] with type [%s] not found\n", repository, refer.Finder.Type)
+			this.logger.LeveledPrintf(log.LevelWarn, "Repository finder for [%s] with type [%s] not found, referenced in spec [%s]\n", repository, refer.Finder.Type, target.Repository.SpecFile)
 		} else {
+			if unknowns := finder.Params().Unknown(refer.Finder.Params); len(unknowns) > 0 {
+				this.logger.LeveledPrintf(log.LevelWarn, "Unknown parameters [%s] of finder [%s] for repository [%s] are ignored, referenced in spec [%s]\n", strings.Join(unknowns, ", "), refer.Finder.Type, repository, target.Repository.SpecFile)
+			}
 			paths, err := finder.Find(this.ws, refer.Finder.Params)
 			if err != nil {
-				this.logger.LeveledPrintf(log.LevelWarn, "Failed to find repository for [%s] with type [%s] error: %s\n", repository, refer.Finder.Type, err)
+				this.logger.LeveledPrintf(log.LevelWarn, "Failed to find repository for [%s] with type [%s] referenced in spec [%s], error: %s\n", repository, refer.Finder.Type, target.Repository.SpecFile, err)
 			} else if len(paths) > 2 {
 				this.logger.LeveledPrintf(log.LevelWarn, "Too many repository found by finder [%s] for repository [%s], found: %s\n", refer.Finder.Type, repository, strings.Join(paths, ", "))
 			} else if len(paths) == 1 {
@@ -263,7 +341,13 @@ func (this *Graph) resolveTargetDependency(name, targetName, repository string,
 		}
 	}
 	// Load it
-	_, err := this.load(remote, LoadOptions{Uri: repository, Branch: refer.Branch, Commit: refer.Commit}, tracer)
+	_, err := this.load(remote, LoadOptions{Uri: repository, Type: refer.Type, Branch: refer.Branch, Commit: refer.Commit, Sha256: refer.Sha256, StripPrefix: refer.StripPrefix, Submodules: refer.Submodules, LFS: refer.LFS}, tracer)
+	if offlineErr, ok := err.(*workspace.OfflineError); ok {
+		// Continue to find all the missing dependencies
+		this.logger.LeveledPrintf(log.LevelDebug, "Repository [%s] is not available offline, referenced in spec [%s]\n", repository, target.Repository.SpecFile)
+		this.missing = append(this.missing, fmt.Sprintf("%s (%s)", offlineErr.Resource, repository))
+		return nil
+	}
 	return err
 }
 
@@ -302,7 +386,8 @@ func (this *Graph) _traverse(
 		}
 	}
 	// Visit all dependency
-	for _, dep := range target.Spec.Deps {
+	for _, name := range target.Spec.Deps.Names() {
+		dep := target.Spec.Deps[name]
 		depTarget := this.Targets[dep.Key()]
 		if depTarget == nil {
 			return errors.New(fmt.Sprintf("Dependency target [%s] not found", dep.Key()))
@@ -325,3 +410,47 @@ func (this *Graph) _traverse(
 	// Done
 	return nil
 }
+
+// Apply the rewrite rules, then resolve the go import path (an address without scheme, e.g. golang.org/x/tools) to
+// the git url by the well known hosts or the go-import meta tag. The resolved url is rewritten again to use the mirrors
+func (this *Graph) rewriteRemote(remote string) (string, error) {
+	remote = this.ws.Rewrite(remote)
+	if !repofetcher.IsGoImportPath(remote) {
+		return remote, nil
+	}
+	resolved, err := repofetcher.ResolveGoImportPath(this.ws, remote)
+	if err != nil {
+		return "", err
+	}
+	return this.ws.Rewrite(resolved), nil
+}
+
+// Move the sub path and ref in the remote (e.g. <url>//sub/path#tag=v1.0.0) into the load options
+// Returns:
+//
+//	The remote without sub path and ref
+func applyRemoteRef(remote string, options *LoadOptions) (string, error) {
+	u, err := uri.Parse(remote)
+	if err != nil || (u.Ref == "" && u.Path == "") {
+		// Not a git url with sub path or ref, let the loader handle it
+		return remote, nil
+	}
+	options.Path = u.Path
+	if u.Ref == "" {
+		return u.Remote(), nil
+	}
+	if options.Branch != "" || options.Commit != "" || options.Tag != "" || options.Ref != "" {
+		return "", errors.New(fmt.Sprintf("Cannot specify both the ref in remote [%s] and branch or commit", remote))
+	}
+	switch u.RefType {
+	case uri.RefTypeBranch:
+		options.Branch = u.Ref
+	case uri.RefTypeTag:
+		options.Tag = u.Ref
+	case uri.RefTypeCommit:
+		options.Commit = u.Ref
+	default:
+		options.Ref = u.Ref
+	}
+	return u.Remote(), nil
+}
@@ -0,0 +1,217 @@
+// Author: lipixun
+// Created Time : 六 10/17 09:31:52 2026
+//
+// File Name: lock.go
+// Description:
+//	The lock file of the remote repositories
+//
+//	The lock file (op.lock in the root of the repository) records the resolved commit and the content hash of each
+//	remote repository loaded by the graph. When the graph has a lock, the remote repositories are loaded at the
+//	locked commits and verified by the content hash. A locked repository is resolved again if its remote or ref is
+//	changed in the referencing spec. The commit of an archive repository is the sha256 digest of the archive, so the
+//	unverified archives are pinned by the lock as well.
+//
+//	The lock file also records the digests of the oci artifacts (oci://registry/repository:tag) referenced by the
+//	specs, the locked artifacts are fetched by the digest instead of the tag.
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+const (
+	LockFileName    = "op.lock"
+	LockFileVersion = 1
+)
+
+// The lock file
+type Lock struct {
+	Version      int                 `json:"version"`
+	Repositories []*LockedRepository `json:"repositories"`        // Sorted by uri
+	Artifacts    []*LockedArtifact   `json:"artifacts,omitempty"` // Sorted by uri
+}
+
+// A locked remote repository
+type LockedRepository struct {
+	Uri    string `json:"uri"`    // The repository uri
+	Remote string `json:"remote"` // The remote url
+	Ref    string `json:"ref"`    // The requested branch, tag or commit, empty means the default branch
+	Commit string `json:"commit"` // The resolved commit
+	Hash   string `json:"hash"`   // The sha256 digest of the files of the commit
+}
+
+// A locked oci artifact
+type LockedArtifact struct {
+	Uri    string `json:"uri"`    // The oci url with tag
+	Digest string `json:"digest"` // The resolved manifest digest
+}
+
+// Load the lock file, a not existed file is an empty lock
+func LoadLock(path string) (*Lock, error) {
+	lock := &Lock{Version: LockFileVersion}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse lock file [%s], error: %s", path, err))
+	}
+	if lock.Version != LockFileVersion {
+		return nil, errors.New(fmt.Sprintf("Unsupported version [%d] of lock file [%s]", lock.Version, path))
+	}
+	// Done
+	return lock, nil
+}
+
+// Get the locked repository by uri, nil if not found
+func (this *Lock) Get(uri string) *LockedRepository {
+	for _, repo := range this.Repositories {
+		if repo.Uri == uri {
+			return repo
+		}
+	}
+	return nil
+}
+
+// Add or replace the locked repository
+func (this *Lock) Set(repo *LockedRepository) {
+	this.Remove(repo.Uri)
+	this.Repositories = append(this.Repositories, repo)
+	sort.Slice(this.Repositories, func(i, j int) bool {
+		return this.Repositories[i].Uri < this.Repositories[j].Uri
+	})
+}
+
+// Remove the locked repository
+func (this *Lock) Remove(uri string) {
+	var repos []*LockedRepository
+	for _, repo := range this.Repositories {
+		if repo.Uri != uri {
+			repos = append(repos, repo)
+		}
+	}
+	this.Repositories = repos
+}
+
+// Get the locked artifact by uri, nil if not found
+func (this *Lock) GetArtifact(uri string) *LockedArtifact {
+	for _, artifact := range this.Artifacts {
+		if artifact.Uri == uri {
+			return artifact
+		}
+	}
+	return nil
+}
+
+// Add or replace the locked artifact
+func (this *Lock) SetArtifact(artifact *LockedArtifact) {
+	this.RemoveArtifact(artifact.Uri)
+	this.Artifacts = append(this.Artifacts, artifact)
+	sort.Slice(this.Artifacts, func(i, j int) bool {
+		return this.Artifacts[i].Uri < this.Artifacts[j].Uri
+	})
+}
+
+// Remove the locked artifact
+func (this *Lock) RemoveArtifact(uri string) {
+	var artifacts []*LockedArtifact
+	for _, artifact := range this.Artifacts {
+		if artifact.Uri != uri {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	this.Artifacts = artifacts
+}
+
+// Save the lock file
+func (this *Lock) Save(path string) error {
+	this.Version = LockFileVersion
+	data, err := json.MarshalIndent(this, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0666)
+}
+
+// Get the requested ref of the load options
+func (this *LoadOptions) requestedRef() string {
+	for _, ref := range []string{this.Sha256, this.Commit, this.Tag, this.Branch, this.Ref} {
+		if ref != "" {
+			return ref
+		}
+	}
+	return ""
+}
+
+// Apply the lock to the load options of the remote repository
+// Returns:
+//
+//	The locked repository, nil if not locked
+func (this *Graph) applyLock(remote string, options *LoadOptions) *LockedRepository {
+	if this.Lock == nil || options.Uri == "" {
+		return nil
+	}
+	locked := this.Lock.Get(options.Uri)
+	if locked == nil {
+		return nil
+	}
+	if locked.Remote != remote || locked.Ref != options.requestedRef() {
+		this.logger.LeveledPrintf(log.LevelWarn, "Lock of repository [%s] is outdated (remote or ref changed), resolve it again\n", options.Uri)
+		return nil
+	}
+	this.logger.LeveledPrintf(log.LevelDebug, "Use locked commit [%s] of repository [%s]\n", locked.Commit, options.Uri)
+	options.Branch, options.Tag, options.Ref, options.Commit = "", "", "", locked.Commit
+	return locked
+}
+
+// Record the resolved remote repository, verify the content hash if locked
+func (this *Graph) recordLock(repo *spec.Repository, remote, ref string, locked *LockedRepository) error {
+	hash, err := util.HashPathDigest(repo.Local.Path)
+	if err != nil {
+		return err
+	}
+	if locked != nil && locked.Hash != hash {
+		return errors.New(fmt.Sprintf("Content hash mismatch of repository [%s] at commit [%s]. Locked [%s] Actual [%s]", repo.Uri, locked.Commit, locked.Hash, hash))
+	}
+	this.Resolved.Set(&LockedRepository{Uri: repo.Uri, Remote: remote, Ref: ref, Commit: repo.Metadata.Commit, Hash: hash})
+	return nil
+}
+
+// Pin the oci artifact url by the locked digest, or the digest resolved by the resolver if not locked
+// The url pinned by the digest itself is returned as is
+func (this *Graph) PinArtifact(rawurl string, resolve func(string) (string, error)) (string, error) {
+	u, err := uri.ParseOCI(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if u.Digest != "" {
+		return rawurl, nil
+	}
+	var digest string
+	if this.Lock != nil {
+		if locked := this.Lock.GetArtifact(rawurl); locked != nil {
+			this.logger.LeveledPrintf(log.LevelDebug, "Use locked digest [%s] of artifact [%s]\n", locked.Digest, rawurl)
+			digest = locked.Digest
+		}
+	}
+	if digest == "" {
+		if digest, err = resolve(rawurl); err != nil {
+			return "", err
+		}
+	}
+	this.Resolved.SetArtifact(&LockedArtifact{Uri: rawurl, Digest: digest})
+	// Done
+	return u.WithDigest(digest).String(), nil
+}
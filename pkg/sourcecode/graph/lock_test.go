@@ -0,0 +1,268 @@
+// Author: lipixun
+// Created Time : 五 10/16 19:46:03 2026
+//
+// File Name: lock_test.go
+// Description:
+//
+package graph
+
+import (
+	"errors"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testLockRemote = "https://github.com/org/lib.git"
+	testLockCommit = "0123456789abcdef0123456789abcdef01234567"
+	testLockDigest = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+)
+
+var (
+	applyLockCases = []struct {
+		Name    string
+		Remote  string
+		Options LoadOptions
+		Locked  bool
+		Expect  LoadOptions // The load options after applied
+		Warning bool
+	}{
+		{
+			Name:    "locked branch",
+			Remote:  testLockRemote,
+			Options: LoadOptions{Uri: testLibRepository, Branch: "master"},
+			Locked:  true,
+			Expect:  LoadOptions{Uri: testLibRepository, Commit: testLockCommit},
+		},
+		{
+			Name:    "locked default branch",
+			Remote:  testLockRemote,
+			Options: LoadOptions{Uri: testAppRepository, Path: "sub"},
+			Locked:  true,
+			Expect:  LoadOptions{Uri: testAppRepository, Path: "sub", Commit: testLockCommit},
+		},
+		{
+			Name:    "not locked",
+			Remote:  testLockRemote,
+			Options: LoadOptions{Uri: "github.com/org/other", Tag: "v1.0.0"},
+			Expect:  LoadOptions{Uri: "github.com/org/other", Tag: "v1.0.0"},
+		},
+		{
+			Name:    "no uri",
+			Remote:  testLockRemote,
+			Options: LoadOptions{Branch: "master"},
+			Expect:  LoadOptions{Branch: "master"},
+		},
+		{
+			Name:    "ref changed",
+			Remote:  testLockRemote,
+			Options: LoadOptions{Uri: testLibRepository, Tag: "v2.0.0"},
+			Expect:  LoadOptions{Uri: testLibRepository, Tag: "v2.0.0"},
+			Warning: true,
+		},
+		{
+			Name:    "remote changed",
+			Remote:  "https://gitlab.com/org/lib.git",
+			Options: LoadOptions{Uri: testLibRepository, Branch: "master"},
+			Expect:  LoadOptions{Uri: testLibRepository, Branch: "master"},
+			Warning: true,
+		},
+	}
+
+	pinArtifactCases = []struct {
+		Name     string
+		Url      string
+		Resolved string // The digest returned by the resolver
+		Expect   string
+		Error    string
+	}{
+		{Name: "locked", Url: "oci://ghcr.io/org/rules:v1", Expect: "oci://ghcr.io/org/rules:v1@" + testLockDigest},
+		{Name: "resolved", Url: "oci://ghcr.io/org/rules:v2", Resolved: testLockDigest, Expect: "oci://ghcr.io/org/rules:v2@" + testLockDigest},
+		{Name: "pinned", Url: "oci://ghcr.io/org/rules@" + testLockDigest, Expect: "oci://ghcr.io/org/rules@" + testLockDigest},
+		{Name: "resolve failed", Url: "oci://ghcr.io/org/rules:v3", Error: "manifest unknown"},
+		{Name: "invalid", Url: "ghcr.io/org/rules:v1", Error: "oci"},
+	}
+)
+
+func TestLoadLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graph-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, LockFileName)
+	// Not existed
+	lock, err := LoadLock(path)
+	if err != nil || lock.Version != LockFileVersion || len(lock.Repositories) != 0 {
+		t.Fatalf("Incorrect lock of the not existed file %+v error [%v]", lock, err)
+	}
+	// Sorted by uri, replaced by uri
+	lock.Set(&LockedRepository{Uri: "github.com/org/b", Commit: "1"})
+	lock.Set(&LockedRepository{Uri: "github.com/org/a", Commit: "2"})
+	lock.Set(&LockedRepository{Uri: "github.com/org/c", Commit: "3"})
+	lock.Set(&LockedRepository{Uri: "github.com/org/b", Commit: "4"})
+	lock.Remove("github.com/org/c")
+	lock.SetArtifact(&LockedArtifact{Uri: "oci://ghcr.io/org/b:v1", Digest: "5"})
+	lock.SetArtifact(&LockedArtifact{Uri: "oci://ghcr.io/org/a:v1", Digest: "6"})
+	if err := lock.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &Lock{
+		Version:      LockFileVersion,
+		Repositories: []*LockedRepository{{Uri: "github.com/org/a", Commit: "2"}, {Uri: "github.com/org/b", Commit: "4"}},
+		Artifacts:    []*LockedArtifact{{Uri: "oci://ghcr.io/org/a:v1", Digest: "6"}, {Uri: "oci://ghcr.io/org/b:v1", Digest: "5"}},
+	}
+	if !reflect.DeepEqual(loaded, expect) {
+		t.Errorf("Incorrect loaded lock. Expect %+v Actual %+v", expect, loaded)
+	}
+	if repo := loaded.Get("github.com/org/b"); repo == nil || repo.Commit != "4" || loaded.Get("github.com/org/c") != nil {
+		t.Errorf("Incorrect locked repository %+v", repo)
+	}
+	loaded.RemoveArtifact("oci://ghcr.io/org/a:v1")
+	if loaded.GetArtifact("oci://ghcr.io/org/a:v1") != nil || loaded.GetArtifact("oci://ghcr.io/org/b:v1") == nil {
+		t.Errorf("Incorrect locked artifacts %+v", loaded.Artifacts)
+	}
+	// The invalid files
+	for content, message := range map[string]string{
+		`{"version": 2, "repositories": []}`: "Unsupported version [2]",
+		`{"version": 1, "repositories": {}}`: "Failed to parse lock file",
+	} {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLock(path); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Incorrect error of the lock file [%s]. Expect [%s] Actual [%v]", content, message, err)
+		}
+	}
+}
+
+func TestApplyLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graph-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	graph, logger := newTestGraph(t, dir)
+	// Not locked without the lock
+	options := LoadOptions{Uri: testLibRepository, Branch: "master"}
+	if locked := graph.applyLock(testLockRemote, &options); locked != nil || options.Branch != "master" {
+		t.Errorf("Incorrect apply of no lock. Actual locked %+v options %+v", locked, options)
+	}
+	graph.Lock = &Lock{Version: LockFileVersion}
+	graph.Lock.Set(&LockedRepository{Uri: testLibRepository, Remote: testLockRemote, Ref: "master", Commit: testLockCommit, Hash: "hash"})
+	graph.Lock.Set(&LockedRepository{Uri: testAppRepository, Remote: testLockRemote, Commit: testLockCommit, Hash: "hash"})
+	for _, tCase := range applyLockCases {
+		logger.Reset()
+		options := tCase.Options
+		locked := graph.applyLock(tCase.Remote, &options)
+		if (locked != nil) != tCase.Locked {
+			t.Errorf("Incorrect lock of case [%s]. Expect locked %v Actual %+v", tCase.Name, tCase.Locked, locked)
+		}
+		if !reflect.DeepEqual(options, tCase.Expect) {
+			t.Errorf("Incorrect options of case [%s]. Expect %+v Actual %+v", tCase.Name, tCase.Expect, options)
+		}
+		if warned := logger.Contains(log.LevelWarn, "is outdated"); warned != tCase.Warning {
+			t.Errorf("Incorrect warning of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Warning, warned)
+		}
+	}
+}
+
+func TestRecordLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graph-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	graph, _ := newTestGraph(t, dir)
+	repoPath := filepath.Join(dir, "lib")
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repoPath, "op.yaml"), []byte("uri: "+testLibRepository+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := util.HashPathDigest(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &spec.Repository{Uri: testLibRepository, Local: spec.RepositoryLocalInfo{Path: repoPath}, Metadata: spec.RepositoryMetadata{Commit: testLockCommit}}
+	// Not locked, recorded as resolved
+	if err := graph.recordLock(repo, testLockRemote, "master", nil); err != nil {
+		t.Fatal(err)
+	}
+	expect := &LockedRepository{Uri: testLibRepository, Remote: testLockRemote, Ref: "master", Commit: testLockCommit, Hash: hash}
+	if resolved := graph.Resolved.Get(testLibRepository); !reflect.DeepEqual(resolved, expect) {
+		t.Errorf("Incorrect resolved repository. Expect %+v Actual %+v", expect, resolved)
+	}
+	// Verified by the locked hash
+	if err := graph.recordLock(repo, testLockRemote, "master", expect); err != nil {
+		t.Errorf("Failed to verify the locked repository, error: %s", err)
+	}
+	// The content changed at the locked commit
+	if err := ioutil.WriteFile(filepath.Join(repoPath, "op.yaml"), []byte("uri: github.com/evil/lib\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	graph.Resolved = &Lock{Version: LockFileVersion}
+	if err := graph.recordLock(repo, testLockRemote, "master", expect); err == nil || !strings.Contains(err.Error(), "Content hash mismatch") {
+		t.Errorf("Incorrect error of the changed content. Actual [%v]", err)
+	}
+	if graph.Resolved.Get(testLibRepository) != nil {
+		t.Errorf("The mismatched repository should not be recorded")
+	}
+}
+
+func TestPinArtifact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graph-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	graph, _ := newTestGraph(t, dir)
+	graph.Lock = &Lock{Version: LockFileVersion}
+	graph.Lock.SetArtifact(&LockedArtifact{Uri: "oci://ghcr.io/org/rules:v1", Digest: testLockDigest})
+	for _, tCase := range pinArtifactCases {
+		var resolved []string
+		pinned, err := graph.PinArtifact(tCase.Url, func(url string) (string, error) {
+			resolved = append(resolved, url)
+			if tCase.Resolved == "" {
+				return "", errors.New("manifest unknown")
+			}
+			return tCase.Resolved, nil
+		})
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to pin case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		if pinned != tCase.Expect {
+			t.Errorf("Incorrect url of case [%s]. Expect [%s] Actual [%s]", tCase.Name, tCase.Expect, pinned)
+		}
+		if expect := tCase.Resolved != ""; (len(resolved) == 1) != expect {
+			t.Errorf("Incorrect resolve of case [%s]. Expect resolved %v Actual %v", tCase.Name, expect, resolved)
+		}
+	}
+	// The locked and resolved artifacts are recorded, the pinned url is not
+	var uris []string
+	for _, artifact := range graph.Resolved.Artifacts {
+		uris = append(uris, artifact.Uri)
+	}
+	if expect := []string{"oci://ghcr.io/org/rules:v1", "oci://ghcr.io/org/rules:v2"}; !reflect.DeepEqual(uris, expect) {
+		t.Errorf("Incorrect resolved artifacts. Expect %v Actual %v", expect, uris)
+	}
+}
@@ -0,0 +1,240 @@
+// Author: lipixun
+// Created Time : 五 10/16 13:05:44 2026
+//
+// File Name: query.go
+// Description:
+//	Query the targets in the graph
+//
+//	The query expression:
+//		deps(<expr>) 			The targets in <expr> and all their (transitive) dependencies
+//		rdeps(<expr>) 			The targets in <expr> and all loaded targets (transitively) depend on them
+//		kind(<type>, <expr>) 	The targets in <expr> with build type <type>
+//		...						All loaded targets
+//		<repository>::...		All loaded targets of the repository
+//		<target uri> 			The target, the repository of the root loaded repository is used if not specified
+//		<address> 				The target address, e.g. github.com/org/repo:target (the sub path and ref are ignored)
+//
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"sort"
+	"strings"
+)
+
+const (
+	QueryFuncDeps  = "deps"
+	QueryFuncRDeps = "rdeps"
+	QueryFuncKind  = "kind"
+
+	QueryAllTargets = "..."
+)
+
+// Query the targets by expression
+// Parameters:
+// 	expr 		The query expression
+// 	root 		The repository used to resolve the target uri without repository
+// Returns:
+// 	The matched targets sorted by target key
+func (this *Graph) Query(expr string, root *spec.Repository) ([]*spec.Target, error) {
+	node, rest, err := parseQueryNode(expr)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, errors.New(fmt.Sprintf("Unexpected [%s] in query expression", strings.TrimSpace(rest)))
+	}
+	targets, err := this.evalQueryNode(node, root)
+	if err != nil {
+		return nil, err
+	}
+	return sortTargets(targets), nil
+}
+
+// Get all dependencies (including the target itself) of the target
+func (this *Graph) Deps(target *spec.Target) ([]*spec.Target, error) {
+	targets := make(map[string]*spec.Target)
+	if err := this.Traverse(target, func(target *spec.Target, from *spec.Target, by *spec.TargetDependencySpec, context interface{}) error {
+		targets[target.Key()] = target
+		return nil
+	}, nil, nil, true, nil); err != nil {
+		return nil, err
+	}
+	return sortTargets(targets), nil
+}
+
+// Get all loaded targets (including the target itself) which depend on the target
+func (this *Graph) ReverseDeps(target *spec.Target) []*spec.Target {
+	// Build the reverse edges
+	reverseEdges := make(map[string][]*spec.Target)
+	for _, t := range this.Targets {
+		for _, dep := range t.Spec.Deps {
+			reverseEdges[dep.Key()] = append(reverseEdges[dep.Key()], t)
+		}
+	}
+	// Visit
+	targets := map[string]*spec.Target{target.Key(): target}
+	queue := []*spec.Target{target}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, r := range reverseEdges[t.Key()] {
+			if _, ok := targets[r.Key()]; !ok {
+				targets[r.Key()] = r
+				queue = append(queue, r)
+			}
+		}
+	}
+	return sortTargets(targets)
+}
+
+type queryNode struct {
+	Func  string       // The function name, empty means a literal
+	Value string       // The literal value
+	Args  []*queryNode // The function arguments
+}
+
+// Parse a query node from the expression, returns the node and the rest of the expression
+func parseQueryNode(expr string) (*queryNode, string, error) {
+	expr = strings.TrimSpace(expr)
+	// Find the end of the token
+	end := strings.IndexAny(expr, "(),")
+	if end == -1 {
+		end = len(expr)
+	}
+	token := strings.TrimSpace(expr[:end])
+	if end == len(expr) || expr[end] != '(' {
+		// A literal
+		if token == "" {
+			return nil, "", errors.New("Empty query expression")
+		}
+		return &queryNode{Value: token}, expr[end:], nil
+	}
+	// A function call
+	node := &queryNode{Func: token}
+	rest := expr[end+1:]
+	for {
+		arg, _rest, err := parseQueryNode(rest)
+		if err != nil {
+			return nil, "", err
+		}
+		node.Args = append(node.Args, arg)
+		rest = strings.TrimSpace(_rest)
+		if strings.HasPrefix(rest, ",") {
+			rest = rest[1:]
+		} else if strings.HasPrefix(rest, ")") {
+			return node, rest[1:], nil
+		} else {
+			return nil, "", errors.New(fmt.Sprintf("Missing ) of function [%s] in query expression", token))
+		}
+	}
+}
+
+func (this *Graph) evalQueryNode(node *queryNode, root *spec.Repository) (map[string]*spec.Target, error) {
+	switch node.Func {
+	case "":
+		return this.resolveQueryPattern(node.Value, root)
+	case QueryFuncDeps, QueryFuncRDeps:
+		if len(node.Args) != 1 {
+			return nil, errors.New(fmt.Sprintf("Function [%s] requires 1 argument", node.Func))
+		}
+		targets, err := this.evalQueryNode(node.Args[0], root)
+		if err != nil {
+			return nil, err
+		}
+		results := make(map[string]*spec.Target)
+		for _, target := range targets {
+			var deps []*spec.Target
+			if node.Func == QueryFuncDeps {
+				deps, err = this.Deps(target)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				deps = this.ReverseDeps(target)
+			}
+			for _, dep := range deps {
+				results[dep.Key()] = dep
+			}
+		}
+		return results, nil
+	case QueryFuncKind:
+		if len(node.Args) != 2 || node.Args[0].Func != "" {
+			return nil, errors.New(fmt.Sprintf("Function [%s] requires 2 arguments: type and expression", node.Func))
+		}
+		targets, err := this.evalQueryNode(node.Args[1], root)
+		if err != nil {
+			return nil, err
+		}
+		results := make(map[string]*spec.Target)
+		for key, target := range targets {
+			if target.Spec.Build.Type == node.Args[0].Value {
+				results[key] = target
+			}
+		}
+		return results, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown query function [%s]", node.Func))
+	}
+}
+
+func (this *Graph) resolveQueryPattern(pattern string, root *spec.Repository) (map[string]*spec.Target, error) {
+	results := make(map[string]*spec.Target)
+	// All targets
+	if pattern == QueryAllTargets {
+		for key, target := range this.Targets {
+			results[key] = target
+		}
+		return results, nil
+	}
+	// All targets of a repository
+	if strings.HasSuffix(pattern, "::"+QueryAllTargets) {
+		repository := strings.TrimSuffix(pattern, "::"+QueryAllTargets)
+		for key, target := range this.Targets {
+			if target.Repository.Uri == repository {
+				results[key] = target
+			}
+		}
+		return results, nil
+	}
+	// A single target
+	targetUri := uri.ParseTargetUri(pattern)
+	if targetUri == nil || targetUri.Name == "" {
+		address, err := uri.ParseAddress(pattern)
+		if err != nil || address.Target == "" {
+			return nil, errors.New(fmt.Sprintf("Invalid target [%s] in query expression", pattern))
+		}
+		targetUri = &uri.TargetUri{Repository: &uri.RepositoryUri{Uri: address.Repository.Remote()}, Name: address.Target}
+	}
+	var key string
+	if targetUri.Repository != nil {
+		key = fmt.Sprintf("%s:%s", targetUri.Repository.Uri, targetUri.Name)
+	} else if root != nil {
+		key = root.GetTargetKey(targetUri.Name)
+	} else {
+		return nil, errors.New(fmt.Sprintf("Cannot resolve target [%s] without repository", pattern))
+	}
+	target := this.Targets[key]
+	if target == nil {
+		return nil, errors.New(fmt.Sprintf("Target [%s] not found", key))
+	}
+	results[key] = target
+	// Done
+	return results, nil
+}
+
+func sortTargets(targets map[string]*spec.Target) []*spec.Target {
+	var keys []string
+	for key := range targets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var results []*spec.Target
+	for _, key := range keys {
+		results = append(results, targets[key])
+	}
+	return results
+}
@@ -0,0 +1,210 @@
+// Author: lipixun
+// Created Time : 五 10/16 19:20:36 2026
+//
+// File Name: query_test.go
+// Description:
+//
+package graph
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testAppRepository = "github.com/org/app"
+	testLibRepository = "github.com/org/lib"
+)
+
+var (
+	// The targets of the test graph: the build type and the dependencies (the target keys)
+	testGraphTargets = []struct {
+		Key  string
+		Type string
+		Deps []string
+	}{
+		{Key: testAppRepository + ":app", Type: "golang", Deps: []string{testAppRepository + ":util", testLibRepository + ":lib"}},
+		{Key: testAppRepository + ":cli", Type: "golang", Deps: []string{testAppRepository + ":app"}},
+		{Key: testAppRepository + ":image", Type: "docker", Deps: []string{testAppRepository + ":app"}},
+		{Key: testAppRepository + ":util", Type: "golang"},
+		{Key: testAppRepository + ":docs", Type: "shell"},
+		{Key: testLibRepository + ":lib", Type: "golang", Deps: []string{testLibRepository + ":base"}},
+		{Key: testLibRepository + ":base", Type: "shell"},
+	}
+
+	parseQueryNodeCases = []struct {
+		Expr  string
+		Node  *queryNode
+		Rest  string
+		Error string
+	}{
+		{Expr: "app", Node: &queryNode{Value: "app"}},
+		{Expr: "  ... ", Node: &queryNode{Value: "..."}},
+		{Expr: "deps(app)", Node: &queryNode{Func: "deps", Args: []*queryNode{{Value: "app"}}}},
+		{
+			Expr: " rdeps( github.com/org/lib::lib ) ",
+			Node: &queryNode{Func: "rdeps", Args: []*queryNode{{Value: "github.com/org/lib::lib"}}},
+		},
+		{
+			Expr: "kind(golang, deps(app))",
+			Node: &queryNode{Func: "kind", Args: []*queryNode{{Value: "golang"}, {Func: "deps", Args: []*queryNode{{Value: "app"}}}}},
+		},
+		{Expr: "app) rest", Node: &queryNode{Value: "app"}, Rest: ") rest"},
+		{Expr: "", Error: "Empty query expression"},
+		{Expr: "deps()", Error: "Empty query expression"},
+		{Expr: "deps(app", Error: "Missing ) of function [deps]"},
+		{Expr: "deps(app cli", Error: "Missing ) of function [deps]"},
+		{Expr: "deps(app,)", Error: "Empty query expression"},
+	}
+
+	queryCases = []struct {
+		Expr    string
+		Targets []string // The expected target keys
+		Error   string
+	}{
+		{Expr: "app", Targets: []string{testAppRepository + ":app"}},
+		{Expr: testLibRepository + "::lib", Targets: []string{testLibRepository + ":lib"}},
+		{Expr: testLibRepository + ":base", Targets: []string{testLibRepository + ":base"}},
+		{
+			Expr:    "...",
+			Targets: []string{testAppRepository + ":app", testAppRepository + ":cli", testAppRepository + ":docs", testAppRepository + ":image", testAppRepository + ":util", testLibRepository + ":base", testLibRepository + ":lib"},
+		},
+		{Expr: testLibRepository + "::...", Targets: []string{testLibRepository + ":base", testLibRepository + ":lib"}},
+		{Expr: "deps(docs)", Targets: []string{testAppRepository + ":docs"}},
+		{
+			Expr:    "deps(cli)",
+			Targets: []string{testAppRepository + ":app", testAppRepository + ":cli", testAppRepository + ":util", testLibRepository + ":base", testLibRepository + ":lib"},
+		},
+		{
+			Expr:    "rdeps(" + testLibRepository + "::base)",
+			Targets: []string{testAppRepository + ":app", testAppRepository + ":cli", testAppRepository + ":image", testLibRepository + ":base", testLibRepository + ":lib"},
+		},
+		{Expr: "rdeps(cli)", Targets: []string{testAppRepository + ":cli"}},
+		{Expr: "kind(docker, ...)", Targets: []string{testAppRepository + ":image"}},
+		{Expr: "kind(shell, deps(image))", Targets: []string{testLibRepository + ":base"}},
+		{Expr: "kind(golang, rdeps(util))", Targets: []string{testAppRepository + ":app", testAppRepository + ":cli", testAppRepository + ":util"}},
+		{Expr: "deps(kind(docker, ...))", Targets: []string{testAppRepository + ":app", testAppRepository + ":image", testAppRepository + ":util", testLibRepository + ":base", testLibRepository + ":lib"}},
+		{Expr: "kind(python, ...)"},
+		{Expr: "unknown", Error: "Target [" + testAppRepository + ":unknown] not found"},
+		{Expr: "deps(app, cli)", Error: "Function [deps] requires 1 argument"},
+		{Expr: "kind(golang)", Error: "Function [kind] requires 2 arguments"},
+		{Expr: "kind(golang app)", Error: "Function [kind] requires 2 arguments"},
+		{Expr: "kind(deps(app), app)", Error: "Function [kind] requires 2 arguments"},
+		{Expr: "all(app)", Error: "Unknown query function [all]"},
+		{Expr: "app cli", Error: "Invalid target [app cli]"},
+		{Expr: "deps(app) cli", Error: "Unexpected [cli] in query expression"},
+	}
+)
+
+// Create the graph of a new workspace under dir
+func newTestGraph(t *testing.T, dir string) (*Graph, *log.CaptureLogger) {
+	options := workspace.NewWorkspaceOptions()
+	options.Dir.GlobalPath = filepath.Join(dir, "global")
+	options.Dir.UserPath = filepath.Join(dir, "user")
+	options.Dir.ProjectPath = filepath.Join(dir, "project")
+	options.LogFile = false
+	options.EnableColor = false
+	for _, path := range []string{options.Dir.GlobalPath, options.Dir.UserPath, options.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := workspace.New(options, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := New(ws, GraphOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graph, logger
+}
+
+// Add the test targets to the graph
+func addTestTargets(graph *Graph) {
+	for _, uri := range []string{testAppRepository, testLibRepository} {
+		graph.Repositories[uri] = &spec.Repository{Uri: uri, Spec: &spec.RepositorySpec{Uri: uri, Targets: make(map[string]*spec.TargetSpec)}}
+	}
+	for _, t := range testGraphTargets {
+		idx := strings.LastIndex(t.Key, ":")
+		repo, name := graph.Repositories[t.Key[:idx]], t.Key[idx+1:]
+		targetSpec := &spec.TargetSpec{Deps: make(spec.TargetDependencies)}
+		targetSpec.Build.Type = t.Type
+		for _, dep := range t.Deps {
+			idx := strings.LastIndex(dep, ":")
+			targetSpec.Deps[dep[idx+1:]] = &spec.TargetDependencySpec{Repository: dep[:idx], Target: dep[idx+1:]}
+		}
+		repo.Spec.Targets[name] = targetSpec
+		graph.Targets[t.Key] = &spec.Target{Name: name, Repository: repo, Spec: targetSpec}
+	}
+}
+
+// Get the keys of the targets
+func getTargetKeys(targets []*spec.Target) []string {
+	var keys []string
+	for _, target := range targets {
+		keys = append(keys, target.Key())
+	}
+	return keys
+}
+
+func TestParseQueryNode(t *testing.T) {
+	for _, tCase := range parseQueryNodeCases {
+		node, rest, err := parseQueryNode(tCase.Expr)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of expression [%s]. Expect [%s] Actual [%v]", tCase.Expr, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to parse expression [%s], error: %s", tCase.Expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(node, tCase.Node) || rest != tCase.Rest {
+			t.Errorf("Incorrect node of expression [%s]. Expect %+v [%s] Actual %+v [%s]", tCase.Expr, tCase.Node, tCase.Rest, node, rest)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graph-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	graph, _ := newTestGraph(t, dir)
+	addTestTargets(graph)
+	root := graph.Repositories[testAppRepository]
+	for _, tCase := range queryCases {
+		targets, err := graph.Query(tCase.Expr, root)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of query [%s]. Expect [%s] Actual [%v]", tCase.Expr, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to query [%s], error: %s", tCase.Expr, err)
+			continue
+		}
+		if keys := getTargetKeys(targets); !reflect.DeepEqual(keys, tCase.Targets) {
+			t.Errorf("Incorrect targets of query [%s]. Expect %v Actual %v", tCase.Expr, tCase.Targets, keys)
+		}
+	}
+	// The target without repository requires the root
+	if _, err := graph.Query("app", nil); err == nil || !strings.Contains(err.Error(), "without repository") {
+		t.Errorf("Incorrect error of the target without repository. Actual [%v]", err)
+	}
+	if targets, err := graph.Query(testAppRepository+"::app", nil); err != nil || len(targets) != 1 {
+		t.Errorf("Incorrect targets of the target uri without root. Actual %v error [%v]", getTargetKeys(targets), err)
+	}
+}
@@ -0,0 +1,341 @@
+// Author: lipixun
+// Created Time : 六 10/17 08:41:09 2026
+//
+// File Name: fetcher.go
+// Description:
+//	The repository fetcher clones the remote git repositories into the workspace cache
+//
+//	The fetcher directory
+//		<user>/cache/git/
+//			repos/<key>.git 			The bare clone of the remote, updated by fetch
+//			repos/<key>.lock 			The lock of the bare clone
+//			repos/<key>.fetched 		The time (modification time) of the last successful clone or fetch
+//			worktrees/<key>/<commit>/ 	The worktree of a commit, shared by all refs point to the commit
+//			worktrees/<key>/<commit>.* 	The stamps of the submodules and lfs objects in the worktree (see submodule.go)
+//
+//	The key of a remote is its host and path with a short hash of the url, e.g. github.com_org_repo-1a2b3c4d
+//	The clone is shallow if the depth is set (config key git.depth)
+//	The branches and tags fetched within the ttl (config key git.ttl) are resolved without fetching again, unless
+//	refreshed by the --refresh flag. The commits are never fetched if found.
+//	In offline mode the ref is resolved in the existing clone without fetching (see workspace/offline.go)
+//	The http(s) remotes are accessed with the configured credential helper then the credentials stored by op login,
+//	the ssh remotes with the ssh options of the host (see ssh.go)
+package repofetcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	FetcherLogHeader = "SourceCode.Fetcher"
+
+	FetcherDirName   = "cache/git" // The fetcher directory (relative to the user workdir)
+	ReposDirName     = "repos"
+	WorktreesDirName = "worktrees"
+)
+
+var (
+	keyRegularExp    = regexp.MustCompile("[^a-zA-Z\\d\\.\\-]+")
+	fullCommitRegExp = regexp.MustCompile("^[0-9a-fA-F]{40}$")
+	commitRegExp     = regexp.MustCompile("^[0-9a-fA-F]{4,40}$")
+)
+
+type Fetcher struct {
+	ws      *workspace.Workspace
+	logger  log.Logger
+	path    string
+	Options FetcherOptions
+}
+
+type FetcherOptions struct {
+	Depth           int           // The depth of shallow clone, full clone if not positive
+	TTL             time.Duration // The duration to reuse the fetched refs, always fetch if not positive
+	Refresh         bool          // Always fetch regardless of the ttl
+	StoreCredential string        // The git credential helper of the credential store (see ssh.go), not passed if empty
+}
+
+// Create a new Fetcher, the options are read from the workspace config
+func New(ws *workspace.Workspace) (*Fetcher, error) {
+	if ws == nil {
+		return nil, errors.New("Require workspace")
+	}
+	path, err := GetFetcherPath(ws)
+	if err != nil {
+		return nil, err
+	}
+	return &Fetcher{
+		ws:     ws,
+		logger: ws.Logger.GetLoggerWithHeader(FetcherLogHeader),
+		path:   path,
+		Options: FetcherOptions{
+			Depth:           ws.Config.GetInt(workspace.ConfigKeyGitDepth),
+			TTL:             time.Duration(ws.Config.GetInt(workspace.ConfigKeyGitTTL)) * time.Second,
+			Refresh:         ws.Refresh,
+			StoreCredential: GetStoreCredentialHelper(ws),
+		},
+	}, nil
+}
+
+// Get the fetcher directory
+func GetFetcherPath(ws *workspace.Workspace) (string, error) {
+	return ws.Dir.User.GetPath(FetcherDirName)
+}
+
+// Clean all fetched repositories and their worktrees
+func CleanFetcherData(ws *workspace.Workspace) error {
+	path, err := GetFetcherPath(ws)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// Check if the remote should be fetched (a git url with http, https, ssh or git scheme)
+func IsRemote(remote string) bool {
+	u, err := uri.Parse(remote)
+	return err == nil && u.Scheme != "" && u.Scheme != uri.SchemeFile
+}
+
+// Checkout the ref of the remote repository
+// Parameters:
+//
+//	remote 		The git url, the sub path and ref in the url are honored
+//	ref 		The branch, tag or commit, overwrites the ref in the url. The default branch is used if empty
+//	options 	Whether to initialize the submodules and pull the lfs objects
+//
+// Returns:
+//
+//	The path of the checked out worktree (joined with the sub path)
+func (this *Fetcher) Checkout(remote string, ref string, options CheckoutOptions) (string, error) {
+	u, err := uri.Parse(remote)
+	if err != nil {
+		return "", err
+	}
+	if ref == "" {
+		ref = u.Ref
+	}
+	url := u.Remote()
+	key := getRemoteKey(u)
+	repoPath := filepath.Join(this.path, ReposDirName, key+".git")
+	if err := os.MkdirAll(filepath.Dir(repoPath), os.ModePerm); err != nil {
+		return "", err
+	}
+	// Lock the repository
+	lockFile, err := os.OpenFile(filepath.Join(this.path, ReposDirName, key+".lock"), os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return "", err
+	}
+	defer lockFile.Close()
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		return "", err
+	}
+	defer syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+	// Clone or fetch
+	if this.ws.Offline {
+		commit, err := this.resolveRef(repoPath, ref)
+		if err != nil {
+			resource := url
+			if ref != "" {
+				resource += "@" + ref
+			}
+			return "", &workspace.OfflineError{Resource: resource}
+		}
+		return this.checkoutCommit(u, url, key, repoPath, commit, nil, options)
+	}
+	environ, err := this.getGitEnviron(u)
+	if err != nil {
+		return "", err
+	}
+	commit, err := this.update(url, repoPath, ref, environ)
+	if err != nil {
+		return "", explainGitError(u, err)
+	}
+	return this.checkoutCommit(u, url, key, repoPath, commit, environ, options)
+}
+
+// Create the worktree of the commit, then initialize the submodules and pull the lfs objects by the options
+// Returns:
+//
+//	The path of the checked out worktree (joined with the sub path)
+func (this *Fetcher) checkoutCommit(u *uri.URI, url, key, repoPath, commit string, environ []string, options CheckoutOptions) (string, error) {
+	worktreePath := filepath.Join(this.path, WorktreesDirName, key, commit)
+	if err := this.addWorktree(repoPath, worktreePath, commit); err != nil {
+		return "", err
+	}
+	if options.Submodules {
+		if err := this.updateSubmodules(url+"@"+commit, worktreePath, environ); err != nil {
+			return "", explainGitError(u, err)
+		}
+	}
+	if options.LFS {
+		if err := this.pullLFS(url+"@"+commit, worktreePath, environ, options.Submodules); err != nil {
+			return "", explainGitError(u, err)
+		}
+	}
+	this.logger.LeveledPrintf(log.LevelDebug, "Checked out [%s] at [%s] to [%s]\n", url, commit, worktreePath)
+	// Done
+	return filepath.Join(worktreePath, u.Path), nil
+}
+
+// Clone or fetch the repository and resolve the ref
+// Parameters:
+//
+//	environ 	The environment variables of the git commands accessing the remote
+//
+// Returns:
+//
+//	The commit of the ref
+func (this *Fetcher) update(url, repoPath, ref string, environ []string) (string, error) {
+	if _, err := os.Stat(repoPath); err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		// Clone
+		this.logger.LeveledPrintf(log.LevelInfo, "Clone repository [%s]\n", url)
+		tempPath := fmt.Sprintf("%s.%d.tmp", repoPath, os.Getpid())
+		os.RemoveAll(tempPath)
+		args := []string{"clone", "--bare"}
+		if this.Options.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(this.Options.Depth), "--no-single-branch")
+		}
+		if _, err := this.runGitWithEnv("", environ, append(args, url, tempPath)...); err != nil {
+			os.RemoveAll(tempPath)
+			return "", err
+		}
+		if err := os.Rename(tempPath, repoPath); err != nil {
+			os.RemoveAll(tempPath)
+			return "", err
+		}
+		this.markFetched(repoPath)
+	} else if commit, err := this.resolveRef(repoPath, ref); err == nil && fullCommitRegExp.MatchString(ref) {
+		// The commit is immutable, no need to fetch
+		return commit, nil
+	} else if err == nil && this.isFresh(repoPath) {
+		this.logger.LeveledPrintf(log.LevelDebug, "Use the recently fetched ref [%s] of repository [%s], --refresh to fetch again\n", ref, url)
+		return commit, nil
+	} else {
+		// Fetch
+		this.logger.LeveledPrintf(log.LevelInfo, "Fetch repository [%s]\n", url)
+		args := []string{"fetch", "--prune", "--tags"}
+		if this.Options.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(this.Options.Depth))
+		}
+		if _, err := this.runGitWithEnv(repoPath, environ, append(args, url, "+refs/heads/*:refs/heads/*")...); err != nil {
+			return "", err
+		}
+		this.markFetched(repoPath)
+	}
+	// Resolve the ref
+	commit, err := this.resolveRef(repoPath, ref)
+	if err != nil && commitRegExp.MatchString(ref) {
+		// The commit may not be reachable from the fetched refs (e.g. a shallow clone), fetch it directly
+		args := []string{"fetch"}
+		if this.Options.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(this.Options.Depth))
+		}
+		if _, err := this.runGitWithEnv(repoPath, environ, append(args, url, ref)...); err == nil {
+			commit, err = this.resolveRef(repoPath, ref)
+		}
+	}
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Ref [%s] not found in repository [%s]", ref, url))
+	}
+	// Done
+	return commit, nil
+}
+
+// Check if the repository was fetched within the ttl
+func (this *Fetcher) isFresh(repoPath string) bool {
+	if this.Options.Refresh || this.Options.TTL <= 0 {
+		return false
+	}
+	info, err := os.Stat(strings.TrimSuffix(repoPath, ".git") + ".fetched")
+	return err == nil && time.Now().Sub(info.ModTime()) < this.Options.TTL
+}
+
+// Record the time of the successful clone or fetch
+func (this *Fetcher) markFetched(repoPath string) {
+	path := strings.TrimSuffix(repoPath, ".git") + ".fetched"
+	if err := ioutil.WriteFile(path, nil, 0666); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to record the fetch time of [%s], error: %s\n", repoPath, err)
+		return
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+}
+
+// Add the worktree of the commit if not added
+func (this *Fetcher) addWorktree(repoPath, worktreePath, commit string) error {
+	if head, err := this.runGit(worktreePath, "rev-parse", "HEAD"); err == nil && head == commit {
+		return nil
+	}
+	// Remove the broken worktree and its stamps
+	if err := os.RemoveAll(worktreePath); err != nil {
+		return err
+	}
+	removeStamps(worktreePath)
+	if _, err := this.runGit(repoPath, "worktree", "prune"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), os.ModePerm); err != nil {
+		return err
+	}
+	// The lfs objects are pulled later if required
+	_, err := this.runGitWithEnv(repoPath, []string{lfsSkipSmudgeEnv}, "worktree", "add", "--detach", worktreePath, commit)
+	return err
+}
+
+// Resolve the ref to commit, HEAD if the ref is empty
+func (this *Fetcher) resolveRef(repoPath, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return this.runGit(repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+}
+
+// Get the key of the remote, e.g. github.com_org_repo-1a2b3c4d
+func getRemoteKey(u *uri.URI) string {
+	hash := sha256.Sum256([]byte(u.Remote()))
+	name := keyRegularExp.ReplaceAllString(strings.Trim(fmt.Sprintf("%s/%s/%s", u.Host, u.Owner, u.Repo), "/"), "_")
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(hash[:4]))
+}
+
+// Run the git command in the directory, returns the trimmed stdout
+func (this *Fetcher) runGit(dir string, args ...string) (string, error) {
+	return this.runGitWithEnv(dir, nil, args...)
+}
+
+// Run the git command in the directory with the extra environment variables, returns the trimmed stdout
+func (this *Fetcher) runGitWithEnv(dir string, environ []string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := this.ws.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), environ...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if err := this.ws.Canceled(); err != nil {
+			return "", err
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.New(fmt.Sprintf("git %s: %s", args[0], message))
+		}
+		return "", errors.New(fmt.Sprintf("git %s: %s", args[0], err))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,277 @@
+// Author: lipixun
+// Created Time : 五 10/16 18:42:27 2026
+//
+// File Name: fetcher_test.go
+// Description:
+//
+package repofetcher
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The test remote, a bare repository pushed from a work repository
+type testRemote struct {
+	t    *testing.T
+	dir  string
+	url  string // The file url of the bare repository
+	work string // The work repository
+}
+
+// Run git in the directory, returns the trimmed stdout
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to run git %v, error: %s", args, err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func newTestRemote(t *testing.T, dir string) *testRemote {
+	remote := &testRemote{t: t, dir: dir, url: "file://" + filepath.Join(dir, "remote.git"), work: filepath.Join(dir, "work")}
+	runGit(t, dir, "init", "-q", "--bare", filepath.Join(dir, "remote.git"))
+	runGit(t, dir, "init", "-q", remote.work)
+	runGit(t, remote.work, "checkout", "-q", "-b", "master")
+	return remote
+}
+
+// Commit the file to the branch then push, returns the commit
+func (this *testRemote) commit(branch, name, content string) string {
+	runGit(this.t, this.work, "checkout", "-q", "-B", branch)
+	path := filepath.Join(this.work, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		this.t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		this.t.Fatal(err)
+	}
+	runGit(this.t, this.work, "add", "-A")
+	runGit(this.t, this.work, "commit", "-q", "-m", name)
+	runGit(this.t, this.work, "push", "-q", "-f", this.url, branch)
+	return runGit(this.t, this.work, "rev-parse", "HEAD")
+}
+
+// Tag the commit then push
+func (this *testRemote) tag(name, commit string) {
+	runGit(this.t, this.work, "tag", "-f", name, commit)
+	runGit(this.t, this.work, "push", "-q", "-f", this.url, "refs/tags/"+name)
+}
+
+// Create the fetcher of a new workspace under dir, the fetchers of the same dir share the clones
+func newTestFetcher(t *testing.T, dir string, ttl time.Duration) (*Fetcher, *workspace.Workspace, *log.CaptureLogger) {
+	options := workspace.NewWorkspaceOptions()
+	options.Dir.GlobalPath = filepath.Join(dir, "global")
+	options.Dir.UserPath = filepath.Join(dir, "user")
+	options.Dir.ProjectPath = filepath.Join(dir, "project")
+	options.LogFile = false
+	options.EnableColor = false
+	for _, path := range []string{options.Dir.GlobalPath, options.Dir.UserPath, options.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := workspace.New(options, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher, err := New(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The executable of the test is not op
+	fetcher.Options.StoreCredential = ""
+	fetcher.Options.TTL = ttl
+	return fetcher, ws, logger
+}
+
+// Get the commit of the checked out worktree
+func getWorktreeCommit(t *testing.T, path string) string {
+	return runGit(t, path, "rev-parse", "HEAD")
+}
+
+func TestCheckoutRefs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "repofetcher-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	remote := newTestRemote(t, dir)
+	first := remote.commit("master", "src/main.go", "package main\n")
+	second := remote.commit("master", "README.md", "readme\n")
+	feature := remote.commit("feature/a", "src/feature.go", "package main\n")
+	remote.tag("v1.0.0", first)
+	fetcher, _, _ := newTestFetcher(t, dir, 0)
+	for _, tCase := range []struct {
+		Name   string
+		Remote string
+		Ref    string
+		Commit string
+		Path   string // The sub path
+		Error  string
+	}{
+		{Name: "default branch", Remote: remote.url, Commit: second},
+		{Name: "branch", Remote: remote.url, Ref: "master", Commit: second},
+		{Name: "branch with slash", Remote: remote.url, Ref: "feature/a", Commit: feature},
+		{Name: "tag", Remote: remote.url, Ref: "v1.0.0", Commit: first},
+		{Name: "commit", Remote: remote.url, Ref: first, Commit: first},
+		{Name: "short commit", Remote: remote.url, Ref: first[:8], Commit: first},
+		{Name: "ref in url", Remote: remote.url + "#tag=v1.0.0", Commit: first},
+		{Name: "ref overwrites url", Remote: remote.url + "#tag=v1.0.0", Ref: "master", Commit: second},
+		{Name: "sub path", Remote: remote.url + "//src", Ref: "master", Commit: second, Path: "src"},
+		{Name: "unknown branch", Remote: remote.url, Ref: "unknown", Error: "Ref [unknown] not found"},
+		{Name: "unknown commit", Remote: remote.url, Ref: "0123456789abcdef0123456789abcdef01234567", Error: "not found"},
+	} {
+		path, err := fetcher.Checkout(tCase.Remote, tCase.Ref, CheckoutOptions{})
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to checkout case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		if commit := getWorktreeCommit(t, path); commit != tCase.Commit {
+			t.Errorf("Incorrect commit of case [%s]. Expect [%s] Actual [%s]", tCase.Name, tCase.Commit, commit)
+		}
+		if filepath.Base(path) != filepath.Base(filepath.Join(tCase.Commit, tCase.Path)) {
+			t.Errorf("Incorrect path of case [%s]. Expect the sub path [%s] of the worktree of [%s] Actual [%s]", tCase.Name, tCase.Path, tCase.Commit, path)
+		}
+	}
+	// The refs of the same commit share the worktree
+	byTag, err := fetcher.Checkout(remote.url, "v1.0.0", CheckoutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byCommit, err := fetcher.Checkout(remote.url, first, CheckoutOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byTag != byCommit {
+		t.Errorf("Incorrect worktree of the same commit. Expect [%s] Actual [%s]", byTag, byCommit)
+	}
+	// The broken worktree is checked out again
+	if err := os.RemoveAll(filepath.Join(byTag, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := fetcher.Checkout(remote.url, "v1.0.0", CheckoutOptions{}); err != nil || getWorktreeCommit(t, path) != first {
+		t.Errorf("Failed to checkout the broken worktree again, error: %v", err)
+	}
+}
+
+func TestCheckoutTTL(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "repofetcher-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	remote := newTestRemote(t, dir)
+	first := remote.commit("master", "README.md", "first\n")
+	fetcher, _, logger := newTestFetcher(t, dir, time.Hour)
+	checkout := func(name, ref, expect string, fetched bool) {
+		logger.Reset()
+		path, err := fetcher.Checkout(remote.url, ref, CheckoutOptions{})
+		if err != nil {
+			t.Fatalf("Failed to checkout [%s] of case [%s], error: %s", ref, name, err)
+		}
+		if commit := getWorktreeCommit(t, path); commit != expect {
+			t.Errorf("Incorrect commit of case [%s]. Expect [%s] Actual [%s]", name, expect, commit)
+		}
+		if actual := logger.Contains(log.LevelInfo, "Fetch repository") || logger.Contains(log.LevelInfo, "Clone repository"); actual != fetched {
+			t.Errorf("Incorrect fetch of case [%s]. Expect fetched %v Actual %v", name, fetched, actual)
+		}
+	}
+	checkout("clone", "master", first, true)
+	second := remote.commit("master", "README.md", "second\n")
+	checkout("within ttl", "master", first, false)
+	// The ref not found in the clone is fetched regardless of the ttl
+	remote.tag("v2.0.0", second)
+	checkout("new tag", "v2.0.0", second, true)
+	third := remote.commit("master", "README.md", "third\n")
+	checkout("fetched commit", second, second, false)
+	checkout("within ttl after fetched", "master", second, false)
+	// Refreshed
+	fetcher.Options.Refresh = true
+	checkout("refresh", "master", third, true)
+	fetcher.Options.Refresh = false
+	// Expired
+	fourth := remote.commit("master", "README.md", "fourth\n")
+	expired := time.Now().Add(-2 * time.Hour)
+	fetchedPath := filepath.Join(fetcher.path, ReposDirName, getRemoteKeyOf(t, remote.url)+".fetched")
+	if err := os.Chtimes(fetchedPath, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	checkout("expired", "master", fourth, true)
+	checkout("within ttl after expired", "master", fourth, false)
+	// No ttl
+	fifth := remote.commit("master", "README.md", "fifth\n")
+	fetcher.Options.TTL = 0
+	checkout("no ttl", "master", fifth, true)
+	// The full commit is never fetched again if found
+	checkout("full commit of no ttl", first, first, false)
+}
+
+func TestCheckoutOffline(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "repofetcher-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	remote := newTestRemote(t, dir)
+	first := remote.commit("master", "README.md", "first\n")
+	fetcher, ws, _ := newTestFetcher(t, dir, 0)
+	ws.Offline = true
+	// Not cloned
+	if _, err := fetcher.Checkout(remote.url, "master", CheckoutOptions{}); !workspace.IsOfflineError(err) {
+		t.Errorf("Incorrect error of the offline checkout before cloned. Expect OfflineError Actual [%v]", err)
+	}
+	ws.Offline = false
+	if _, err := fetcher.Checkout(remote.url, "master", CheckoutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// The ref is resolved in the clone without fetching
+	remote.commit("master", "README.md", "second\n")
+	ws.Offline = true
+	if path, err := fetcher.Checkout(remote.url, "master", CheckoutOptions{}); err != nil || getWorktreeCommit(t, path) != first {
+		t.Errorf("Incorrect offline checkout. Expect [%s] error [%v]", first, err)
+	}
+	if _, err := fetcher.Checkout(remote.url, "unknown", CheckoutOptions{}); !workspace.IsOfflineError(err) || !strings.Contains(err.Error(), "@unknown") {
+		t.Errorf("Incorrect error of the unknown ref offline. Expect OfflineError Actual [%v]", err)
+	}
+}
+
+// Get the key of the remote url
+func getRemoteKeyOf(t *testing.T, remote string) string {
+	u, err := uri.Parse(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return getRemoteKey(u)
+}
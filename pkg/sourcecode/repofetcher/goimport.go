@@ -0,0 +1,176 @@
+// Author: lipixun
+// Created Time : 六 10/17 18:02:26 2026
+//
+// File Name: goimport.go
+// Description:
+//	Resolve the go import paths (the remote address without scheme) to the git urls like go get
+//
+//	The repository root of the well known hosts (github.com, bitbucket.org) is the first two path segments, e.g.
+//		github.com/org/repo/sub/pkg 	=> https://github.com/org/repo.git//sub/pkg
+//	The other hosts (vanity import paths) are resolved by the go-import meta tag of https://<import path>?go-get=1, e.g.
+//		<meta name="go-import" content="golang.org/x/tools git https://go.googlesource.com/tools">
+//	resolves
+//		golang.org/x/tools/cmd/stringer@v0.1.0 	=> https://go.googlesource.com/tools//cmd/stringer@v0.1.0
+//
+//	The resolved repository roots are cached in the workspace cache "go-import" for a day. Only the git repositories
+//	are supported.
+package repofetcher
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	GoImportCacheNamespace = "go-import"
+	GoImportCacheTTL       = 24 * time.Hour
+	GoImportTimeout        = 30 * time.Second
+	GoImportMaxSize        = 1 << 20 // The max size of the page to read the meta tags
+)
+
+var (
+	goImportStaticHosts = map[string]bool{"github.com": true, "bitbucket.org": true}
+	goImportMetaRegExp  = regexp.MustCompile("(?is)<meta\\s[^>]*>")
+	goImportAttrRegExp  = regexp.MustCompile("(?is)\\b(name|content)\\s*=\\s*(?:\"([^\"]*)\"|'([^']*)')")
+)
+
+// Check if the remote is a go import path (an address without scheme, e.g. golang.org/x/tools)
+func IsGoImportPath(remote string) bool {
+	u, err := uri.Parse(remote)
+	return err == nil && u.Scheme == "" && u.Host != ""
+}
+
+// Resolve the go import path to the git url, the ref and sub path are kept
+// Returns:
+//
+//	The git url with the sub path (the rest of the import path after the repository root) and ref
+func ResolveGoImportPath(ws *workspace.Workspace, remote string) (string, error) {
+	u, err := uri.Parse(remote)
+	if err != nil {
+		return "", err
+	}
+	importPath := path.Join(u.Host, u.Owner, u.Repo)
+	root, repoURL, err := getGoImportRoot(ws, importPath)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := uri.Parse(repoURL)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Invalid repository [%s] of go import path [%s], error: %s", repoURL, importPath, err))
+	}
+	resolved.Path = strings.Trim(path.Join(strings.TrimPrefix(importPath, root), u.Path), "/")
+	resolved.Ref, resolved.RefType = u.Ref, u.RefType
+	ws.Logger.LeveledPrintf(log.LevelDebug, "Resolved go import path [%s] to [%s]\n", remote, resolved)
+	// Done
+	return resolved.String(), nil
+}
+
+// Get the repository root and url of the import path
+func getGoImportRoot(ws *workspace.Workspace, importPath string) (string, string, error) {
+	segments := strings.Split(importPath, "/")
+	if goImportStaticHosts[segments[0]] {
+		if len(segments) < 3 {
+			return "", "", errors.New(fmt.Sprintf("Invalid go import path [%s], require owner and repository", importPath))
+		}
+		root := strings.Join(segments[:3], "/")
+		return root, "https://" + root + uri.GitSuffix, nil
+	}
+	cache, err := ws.Cache(GoImportCacheNamespace, workspace.CacheOptions{TTL: GoImportCacheTTL})
+	if err != nil {
+		return "", "", err
+	}
+	// The import path may be cached by itself or any parent path which is a repository root
+	for i := len(segments); i > 0; i-- {
+		if data, ok, _ := cache.GetBytes(strings.Join(segments[:i], "/")); ok {
+			if fields := strings.Fields(string(data)); len(fields) == 2 {
+				return fields[0], fields[1], nil
+			}
+		}
+	}
+	if ws.Offline {
+		return "", "", &workspace.OfflineError{Resource: importPath}
+	}
+	root, repoURL, err := fetchGoImportMeta(ws, importPath)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := cache.PutBytes(root, []byte(root+" "+repoURL)); err != nil {
+		ws.Logger.LeveledPrintf(log.LevelWarn, "Failed to cache go import path [%s], error: %s\n", root, err)
+	}
+	// Done
+	return root, repoURL, nil
+}
+
+// Get the repository root and url by the go-import meta tag
+func fetchGoImportMeta(ws *workspace.Workspace, importPath string) (string, string, error) {
+	request, err := http.NewRequestWithContext(ws.Context(), http.MethodGet, "https://"+importPath+"?go-get=1", nil)
+	if err != nil {
+		return "", "", err
+	}
+	host := strings.SplitN(importPath, "/", 2)[0]
+	if credential, err := ws.GetCredential(host); err != nil {
+		ws.Logger.LeveledPrintf(log.LevelWarn, "Failed to get credential of [%s], error: %s\n", host, err)
+	} else if credential != nil {
+		if credential.Username == "" {
+			request.Header.Set("Authorization", "Bearer "+credential.Secret)
+		} else {
+			request.SetBasicAuth(credential.Username, credential.Secret)
+		}
+	}
+	response, err := ws.HTTPClient(GoImportTimeout).Do(request)
+	if err != nil {
+		return "", "", errors.New(fmt.Sprintf("Failed to resolve go import path [%s], error: %s", importPath, err))
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", "", errors.New(fmt.Sprintf("Failed to resolve go import path [%s]: %s", importPath, response.Status))
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, GoImportMaxSize))
+	if err != nil {
+		return "", "", err
+	}
+	return parseGoImportMeta(importPath, string(data))
+}
+
+// Parse the go-import meta tags, the longest prefix of the import path wins
+func parseGoImportMeta(importPath, page string) (string, string, error) {
+	var root, repoURL, vcs string
+	for _, tag := range goImportMetaRegExp.FindAllString(page, -1) {
+		var name, content string
+		for _, attr := range goImportAttrRegExp.FindAllStringSubmatch(tag, -1) {
+			value := attr[2] + attr[3]
+			if strings.ToLower(attr[1]) == "name" {
+				name = value
+			} else {
+				content = value
+			}
+		}
+		fields := strings.Fields(content)
+		if name != "go-import" || len(fields) != 3 || fields[1] == "mod" {
+			continue
+		}
+		if fields[0] != importPath && !strings.HasPrefix(importPath, fields[0]+"/") {
+			continue
+		}
+		if len(fields[0]) > len(root) {
+			root, vcs, repoURL = fields[0], fields[1], fields[2]
+		}
+	}
+	if root == "" {
+		return "", "", errors.New(fmt.Sprintf("No go-import meta tag of go import path [%s]", importPath))
+	}
+	if vcs != "git" {
+		return "", "", errors.New(fmt.Sprintf("Unsupported vcs [%s] of go import path [%s], only git is supported", vcs, importPath))
+	}
+	return root, repoURL, nil
+}
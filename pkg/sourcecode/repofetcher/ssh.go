@@ -0,0 +1,284 @@
+// Author: lipixun
+// Created Time : 六 10/17 15:20:44 2026
+//
+// File Name: ssh.go
+// Description:
+//	The ssh options of the remote hosts
+//
+//	The options are loaded from the layered files (see workspace/layer.go), a host defined in a higher layer
+//	replaces the whole options of the host in the lower layers:
+//		global 		<global>/ssh.yaml
+//		user 		<user>/ssh.yaml
+//	There's no project layer, a checked out repository shouldn't turn off the host key checking or choose the
+//	identity, agent or proxy of the hosts.
+//
+//	Each file is a yaml map of the host name (without port) to the options, * matches the hosts not defined, e.g.
+//		git.internal:
+//			identityFile: ~/.ssh/id_internal
+//			user: git
+//			port: 2222
+//			knownHosts: accept-new
+//			agent: none
+//			proxy: socks5://proxy.corp:1080
+//
+//	The user and port in the url take precedence over the options. The ssh command always runs in batch mode, so
+//	an authentication failure is reported instead of prompting for a password.
+//	The proxy is the config proxy.ssh if not set (see workspace/proxy.go), none to connect directly.
+package repofetcher
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	SSHFileName    = "ssh.yaml"
+	SSHDefaultHost = "*"
+
+	KnownHostsStrict    = "strict"     // Only the hosts in known_hosts are allowed
+	KnownHostsAcceptNew = "accept-new" // Add the new hosts to known_hosts, the changed host keys are rejected
+	KnownHostsOff       = "off"        // Do not check the host keys
+
+	SSHAgentNone = "none" // Disable the ssh agent
+)
+
+var (
+	shellSafeRegularExp = regexp.MustCompile("^[a-zA-Z0-9_./:=@%+,-]+$")
+)
+
+// The ssh options of a host
+type SSHHostOptions struct {
+	IdentityFile   string `yaml:"identityFile"`   // The private key file, only this key is offered if set
+	User           string `yaml:"user"`           // The login user
+	Port           int    `yaml:"port"`           // The port
+	KnownHosts     string `yaml:"knownHosts"`     // The host key policy, one of KnownHosts*, the ssh default if empty
+	KnownHostsFile string `yaml:"knownHostsFile"` // The known_hosts file, the ssh default if empty
+	Agent          string `yaml:"agent"`          // The ssh agent socket, none to disable, SSH_AUTH_SOCK if empty
+	Proxy          string `yaml:"proxy"`          // The socks5 or http proxy, none to disable
+}
+
+// Load the ssh options of the host, nil if not defined
+func LoadSSHHostOptions(ws *workspace.Workspace, host string) *SSHHostOptions {
+	layered, errs := workspace.MergeLayers(
+		ws.UserLayerSources(SSHFileName, SSHFileName),
+		func(source workspace.LayerSource) (map[string]interface{}, error) {
+			data, err := ioutil.ReadFile(source.Path)
+			if err != nil {
+				return nil, err
+			}
+			var hosts map[string]*SSHHostOptions
+			if err := yaml.Unmarshal(data, &hosts); err != nil {
+				return nil, err
+			}
+			values := make(map[string]interface{})
+			for name, options := range hosts {
+				values[name] = options
+			}
+			return values, nil
+		},
+	)
+	for _, err := range errs {
+		ws.Logger.LeveledPrintf(log.LevelWarn, "%s\n", err)
+	}
+	for _, name := range []string{host, SSHDefaultHost} {
+		if value, ok := layered[name]; ok && value.Value.(*SSHHostOptions) != nil {
+			return value.Value.(*SSHHostOptions)
+		}
+	}
+	return nil
+}
+
+// Get the environment variables of git to apply the ssh options
+func (this *SSHHostOptions) Environ() ([]string, error) {
+	command := []string{"ssh", "-o", "BatchMode=yes"}
+	var environ []string
+	if this.IdentityFile != "" {
+		path, err := util.GetRealPath(this.IdentityFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, errors.New(fmt.Sprintf("Identity file [%s] not found, check identityFile in %s", this.IdentityFile, SSHFileName))
+		}
+		command = append(command, "-i", path, "-o", "IdentitiesOnly=yes")
+	}
+	if this.User != "" {
+		command = append(command, "-l", this.User)
+	}
+	if this.Port != 0 {
+		command = append(command, "-p", strconv.Itoa(this.Port))
+	}
+	switch this.KnownHosts {
+	case "":
+	case KnownHostsStrict:
+		command = append(command, "-o", "StrictHostKeyChecking=yes")
+	case KnownHostsAcceptNew:
+		command = append(command, "-o", "StrictHostKeyChecking=accept-new")
+	case KnownHostsOff:
+		command = append(command, "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null")
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown knownHosts policy [%s], should be one of %s, %s, %s", this.KnownHosts, KnownHostsStrict, KnownHostsAcceptNew, KnownHostsOff))
+	}
+	if this.KnownHostsFile != "" && this.KnownHosts != KnownHostsOff {
+		path, err := util.GetRealPath(this.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		command = append(command, "-o", "UserKnownHostsFile="+path)
+	}
+	switch this.Agent {
+	case "":
+	case SSHAgentNone:
+		command = append(command, "-o", "IdentityAgent=none")
+	default:
+		path, err := util.GetRealPath(this.Agent)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, errors.New(fmt.Sprintf("Ssh agent socket [%s] not found, start the agent or check agent in %s", this.Agent, SSHFileName))
+		}
+		environ = append(environ, "SSH_AUTH_SOCK="+path)
+	}
+	if this.Proxy != "" && this.Proxy != workspace.ProxyNone {
+		proxy, err := workspace.ParseProxy(this.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		// The openbsd netcat connects by the proxy, 5 is socks5
+		protocol := "connect"
+		if proxy.Scheme == workspace.ProxySchemeSOCKS5 {
+			protocol = "5"
+		}
+		command = append(command, "-o", fmt.Sprintf("ProxyCommand=nc -X %s -x %s %%h %%p", protocol, proxy.Host))
+	}
+	// Git runs GIT_SSH_COMMAND by the shell
+	environ = append(environ, "GIT_SSH_COMMAND="+quoteShellCommand(command))
+	// Done
+	return environ, nil
+}
+
+// Get the git credential helper serving the credential store of the workspace (op credential, the credentials
+// stored by op login), empty if the running executable is unknown. The workdirs are passed so that the helper opens
+// the same store as the workspace
+func GetStoreCredentialHelper(ws *workspace.Workspace) string {
+	path, err := os.Executable()
+	if err != nil {
+		ws.Logger.LeveledPrintf(log.LevelDebug, "Cannot pass the credential store to git, error: %s\n", err)
+		return ""
+	}
+	return quoteShellCommand([]string{
+		path,
+		"--workspace", workspace.DefaultWorkspaceName,
+		"--workdir-user-path", ws.Dir.User.RootPath(),
+		"--workdir-global-path", ws.Dir.Global.RootPath(),
+		"--no-log-file",
+		"credential",
+	})
+}
+
+// Get the environment variables of git to access the remote: the credential helpers and proxy of the http(s) remotes
+// and the ssh options of the ssh remotes
+func (this *Fetcher) getGitEnviron(u *uri.URI) ([]string, error) {
+	if u.Scheme == uri.SchemeHTTP || u.Scheme == uri.SchemeHTTPS {
+		environ := this.ws.ProxyEnviron()
+		// The configured helper (see workspace/credentialhelper.go) then the credential store, git asks the helpers
+		// in order until one has the credential
+		var helpers []string
+		if command := this.ws.GetCredentialHelper(u.Host); command != "" {
+			helpers = append(helpers, "!"+command)
+		}
+		if this.Options.StoreCredential != "" {
+			helpers = append(helpers, "!"+this.Options.StoreCredential)
+		}
+		if len(helpers) > 0 {
+			environ = append(environ, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(helpers)))
+			for i, helper := range helpers {
+				environ = append(environ, fmt.Sprintf("GIT_CONFIG_KEY_%d=credential.helper", i), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, helper))
+			}
+		}
+		return environ, nil
+	}
+	if u.Scheme != uri.SchemeSSH {
+		return nil, nil
+	}
+	host := u.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	var hostOptions SSHHostOptions
+	if options := LoadSSHHostOptions(this.ws, host); options != nil {
+		hostOptions = *options
+	}
+	if hostOptions.Proxy == "" {
+		proxy, err := this.ws.GetSSHProxy(host)
+		if err != nil {
+			return nil, err
+		}
+		if proxy != nil {
+			hostOptions.Proxy = proxy.String()
+		}
+	}
+	// The user and port in the url take precedence (ssh prefers -l over user@host)
+	if u.User != "" {
+		hostOptions.User = ""
+	}
+	if host != u.Host {
+		hostOptions.Port = 0
+	}
+	environ, err := hostOptions.Environ()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid ssh options of host [%s], error: %s", host, err))
+	}
+	return environ, nil
+}
+
+// Quote the arguments for the shell and join them
+func quoteShellCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if shellSafeRegularExp.MatchString(arg) {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.Replace(arg, "'", "'\\''", -1) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Explain the error of the git command accessing the remote with the actions to fix
+func explainGitError(u *uri.URI, err error) error {
+	message := err.Error()
+	host := u.Host
+	var explain string
+	switch {
+	case strings.Contains(message, "Permission denied (publickey"):
+		explain = fmt.Sprintf("Ssh authentication to [%s] failed: no key is accepted. Load the key into the agent (ssh-add), "+
+			"set identityFile of the host in %s, and make sure the public key is registered on the server", host, SSHFileName)
+	case strings.Contains(message, "REMOTE HOST IDENTIFICATION HAS CHANGED"):
+		explain = fmt.Sprintf("Host key of [%s] has changed. Verify the new key with the server owner, then remove the old one by ssh-keygen -R %s", host, host)
+	case strings.Contains(message, "Host key verification failed"):
+		explain = fmt.Sprintf("Host key of [%s] is not trusted. Verify and add it by ssh-keyscan %s >> ~/.ssh/known_hosts, "+
+			"or set knownHosts: %s of the host in %s", host, host, KnownHostsAcceptNew, SSHFileName)
+	case strings.Contains(message, "Could not resolve hostname"):
+		explain = fmt.Sprintf("Cannot resolve host [%s], check the url or the rewrite rules (op config rewrites)", host)
+	case strings.Contains(message, "Connection refused"), strings.Contains(message, "Connection timed out"), strings.Contains(message, "Operation timed out"):
+		explain = fmt.Sprintf("Cannot connect to [%s], check the network and the port (port of the host in %s)", host, SSHFileName)
+	case strings.Contains(message, "Could not open a connection to your authentication agent"), strings.Contains(message, "Error connecting to agent"):
+		explain = fmt.Sprintf("Cannot connect to the ssh agent, start it (eval $(ssh-agent)) or set agent: %s of the host in %s", SSHAgentNone, SSHFileName)
+	default:
+		return err
+	}
+	return errors.New(fmt.Sprintf("%s\n%s", explain, message))
+}
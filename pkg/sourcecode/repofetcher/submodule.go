@@ -0,0 +1,124 @@
+// Author: lipixun
+// Created Time : 六 10/17 18:31:47 2026
+//
+// File Name: submodule.go
+// Description:
+//	The submodules and git lfs objects of the fetched repositories
+//
+//	The submodules are initialized recursively if the worktree has .gitmodules, the lfs objects are pulled if the
+//	.gitattributes of the worktree uses the lfs filter. Both are enabled by default (config key git.submodules and
+//	git.lfs) and can be disabled per reference in the spec, e.g.
+//		references:
+//			github.com/org/assets:
+//				remote: https://github.com/org/assets.git
+//				submodules: false
+//				lfs: true
+//
+//	The worktrees are shared by the refs point to the same commit, so the stamps next to the worktree record what
+//	have been done, the later checkouts (or the checkouts in offline mode) skip them:
+//		worktrees/<key>/<commit>.submodules
+//		worktrees/<key>/<commit>.lfs
+//	The lfs objects are stored in the bare clone and shared by all the worktrees of the repository
+package repofetcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	SubmodulesStampSuffix = ".submodules"
+	LFSStampSuffix        = ".lfs"
+
+	lfsSkipSmudgeEnv = "GIT_LFS_SKIP_SMUDGE=1" // Do not download the lfs objects when checking out
+	lfsFilter        = "filter=lfs"
+)
+
+// The checkout options
+type CheckoutOptions struct {
+	Submodules bool // Initialize the submodules recursively
+	LFS        bool // Pull the git lfs objects
+}
+
+// Get the default checkout options by the workspace config
+func (this *Fetcher) DefaultCheckoutOptions() CheckoutOptions {
+	return CheckoutOptions{
+		Submodules: this.ws.Config.GetBool(workspace.ConfigKeyGitSubmodules),
+		LFS:        this.ws.Config.GetBool(workspace.ConfigKeyGitLFS),
+	}
+}
+
+// Initialize the submodules of the worktree recursively
+func (this *Fetcher) updateSubmodules(resource, worktreePath string, environ []string) error {
+	if _, err := os.Stat(filepath.Join(worktreePath, ".gitmodules")); err != nil || hasStamp(worktreePath, SubmodulesStampSuffix) {
+		return nil
+	}
+	if this.ws.Offline {
+		return &workspace.OfflineError{Resource: resource + " (submodules)"}
+	}
+	this.logger.LeveledPrintf(log.LevelInfo, "Update submodules of [%s]\n", resource)
+	if _, err := this.runGitWithEnv(worktreePath, append(environ, lfsSkipSmudgeEnv), "submodule", "update", "--init", "--recursive"); err != nil {
+		return err
+	}
+	this.stamp(worktreePath, SubmodulesStampSuffix)
+	// Done
+	return nil
+}
+
+// Pull the lfs objects of the worktree (and its submodules if initialized)
+func (this *Fetcher) pullLFS(resource, worktreePath string, environ []string, submodules bool) error {
+	if !usesLFS(worktreePath) || hasStamp(worktreePath, LFSStampSuffix) {
+		return nil
+	}
+	if this.ws.Offline {
+		return &workspace.OfflineError{Resource: resource + " (lfs)"}
+	}
+	if _, err := this.runGit("", "lfs", "version"); err != nil {
+		return errors.New(fmt.Sprintf("git-lfs is required to pull the lfs objects of [%s], install it or set lfs: false in the reference", resource))
+	}
+	this.logger.LeveledPrintf(log.LevelInfo, "Pull lfs objects of [%s]\n", resource)
+	if _, err := this.runGitWithEnv(worktreePath, environ, "lfs", "pull"); err != nil {
+		return err
+	}
+	if submodules {
+		script := fmt.Sprintf("if grep -qs %s .gitattributes; then git lfs pull; fi", lfsFilter)
+		if _, err := this.runGitWithEnv(worktreePath, environ, "submodule", "foreach", "--recursive", script); err != nil {
+			return err
+		}
+	}
+	this.stamp(worktreePath, LFSStampSuffix)
+	// Done
+	return nil
+}
+
+// Record the stamp of the worktree
+func (this *Fetcher) stamp(worktreePath, suffix string) {
+	if err := ioutil.WriteFile(worktreePath+suffix, nil, 0666); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to record the stamp [%s], error: %s\n", worktreePath+suffix, err)
+	}
+}
+
+// Check if the worktree has the stamp
+func hasStamp(worktreePath, suffix string) bool {
+	_, err := os.Stat(worktreePath + suffix)
+	return err == nil
+}
+
+// Remove the stamps of the worktree
+func removeStamps(worktreePath string) {
+	for _, suffix := range []string{SubmodulesStampSuffix, LFSStampSuffix} {
+		os.Remove(worktreePath + suffix)
+	}
+}
+
+// Check if the worktree uses the lfs filter
+func usesLFS(worktreePath string) bool {
+	data, err := ioutil.ReadFile(filepath.Join(worktreePath, ".gitattributes"))
+	return err == nil && bytes.Contains(data, []byte(lfsFilter))
+}
@@ -8,19 +8,34 @@ package repofinder
 
 import (
 	"github.com/ops-openlight/openlight/pkg/workspace"
+	"sort"
 )
 
 var (
 	finders map[string]Finder = map[string]Finder{
 		FinderTypeGolang: NewGolangFinder(),
 		FinderTypePython: NewPythonFinder(),
+		FinderTypeNode:   NewNodeFinder(),
 	}
 )
 
 type Finder interface {
+	// The schema of the finder parameters
+	Params() ParamsSchema
+	// Find the repository paths
 	Find(ws *workspace.Workspace, params map[string]interface{}) ([]string, error)
 }
 
 func GetFinder(t string) Finder {
 	return finders[t]
 }
+
+// Get all registered finder types, sorted by name
+func GetFinderTypes() []string {
+	var types []string
+	for t := range finders {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -3,7 +3,7 @@
 //
 // File Name: golang.go
 // Description:
-//	Find repository by go path
+//	Find repository by go path, all entries of GOPATH are searched
 //	Required parameters:
 // 		package 		string 	The package to find
 // 		parent  		int 	The parent level count
@@ -12,11 +12,10 @@
 package repofinder
 
 import (
-	"errors"
+	"github.com/ops-openlight/openlight/pkg/util"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 const (
@@ -32,43 +31,56 @@ func NewGolangFinder() GolangFinder {
 	return GolangFinder{}
 }
 
-func (this GolangFinder) Find(ws *workspace.Workspace, params map[string]interface{}) ([]string, error) {
-	_package, ok := params[GolangFinderParamPackage]
-	if !ok {
-		return nil, errors.New("Require package parameter")
+func (this GolangFinder) Params() ParamsSchema {
+	return ParamsSchema{
+		{Name: GolangFinderParamPackage, Type: ParamTypeString, Required: true, Usage: "The package to find"},
+		{Name: GolangFinderParamParent, Type: ParamTypeInt, Default: 0, Usage: "The parent level count"},
 	}
-	pkg, ok := _package.(string)
-	if !ok {
-		return nil, errors.New("Invalid value of package parameter")
+}
+
+func (this GolangFinder) Find(ws *workspace.Workspace, params map[string]interface{}) ([]string, error) {
+	values, err := this.Params().Parse(params)
+	if err != nil {
+		return nil, err
 	}
-	parent := 0
-	_parent, ok := params[GolangFinderParamParent]
-	if ok {
-		parent, ok = _parent.(int)
-		if !ok {
-			return nil, errors.New("Invalid value of parent parameter")
+	pkg := values[GolangFinderParamPackage].(string)
+	parent := values[GolangFinderParamParent].(int)
+	// Find the package in each go path entry
+	for _, goPath := range getGoPaths() {
+		// The package is expected to be in the src directory, the go path itself is also checked for backward compatibility
+		for _, path := range []string{filepath.Join(goPath, "src", pkg), filepath.Join(goPath, pkg)} {
+			if _, err := os.Stat(path); err != nil {
+				// Not found
+				continue
+			}
+			// Found it
+			// Get with parent
+			for i := 0; i < parent; i++ {
+				path = filepath.Dir(path)
+			}
+			// Done
+			return []string{path}, nil
 		}
 	}
-	// Get go path
-	var goPath string
-	for _, environ := range os.Environ() {
-		if strings.HasPrefix(environ, "GOPATH=") {
-			// Found the go path
-			goPath = environ[7:]
-			break
+	// Not found
+	return nil, nil
+}
+
+// Get the go path entries, the default go path (~/go) is used if GOPATH is not set
+func getGoPaths() []string {
+	goPath := os.Getenv("GOPATH")
+	if goPath == "" {
+		path, err := util.GetRealPath("~/go")
+		if err != nil {
+			return nil
 		}
+		return []string{path}
 	}
-	// Find the package
-	path := filepath.Join(goPath, pkg)
-	if _, err := os.Stat(path); err != nil {
-		// Not found
-		return nil, nil
-	}
-	// Found it
-	// Get with parent
-	for i := 0; i < parent; i++ {
-		path = filepath.Dir(path)
+	var paths []string
+	for _, path := range filepath.SplitList(goPath) {
+		if path != "" {
+			paths = append(paths, path)
+		}
 	}
-	// Done
-	return []string{path}, nil
+	return paths
 }
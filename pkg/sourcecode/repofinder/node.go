@@ -0,0 +1,90 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:52:26 2026
+//
+// File Name: node.go
+// Description:
+//	Find repository by node modules
+//	The node_modules directories are searched from the project path up to the root, then the NODE_PATH entries
+//	Required parameters:
+// 		package 		string 	The package name to find, required
+// 		parent  		int 	The parent level count
+//
+
+package repofinder
+
+import (
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"os"
+	"path/filepath"
+)
+
+const (
+	FinderTypeNode = "node"
+
+	NodeFinderParamPackage = "package"
+	NodeFinderParamParent  = "parent"
+
+	NodeModulesDirName     = "node_modules"
+	NodePackageFileName    = "package.json"
+	NodePathEnvironVarName = "NODE_PATH"
+)
+
+type NodeFinder struct{}
+
+func NewNodeFinder() NodeFinder {
+	return NodeFinder{}
+}
+
+func (this NodeFinder) Params() ParamsSchema {
+	return ParamsSchema{
+		{Name: NodeFinderParamPackage, Type: ParamTypeString, Required: true, Usage: "The package name to find"},
+		{Name: NodeFinderParamParent, Type: ParamTypeInt, Default: 0, Usage: "The parent level count"},
+	}
+}
+
+func (this NodeFinder) Find(ws *workspace.Workspace, params map[string]interface{}) ([]string, error) {
+	values, err := this.Params().Parse(params)
+	if err != nil {
+		return nil, err
+	}
+	pkg := values[NodeFinderParamPackage].(string)
+	parent := values[NodeFinderParamParent].(int)
+	// Get the node_modules directories to search
+	var dirs []string
+	if ws != nil && ws.Dir.Project != nil {
+		for p := ws.Dir.Project.RootPath(); ; p = filepath.Dir(p) {
+			if filepath.Base(p) != NodeModulesDirName {
+				dirs = append(dirs, filepath.Join(p, NodeModulesDirName))
+			}
+			if filepath.Dir(p) == p {
+				break
+			}
+		}
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv(NodePathEnvironVarName))...)
+	// Find the package
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, pkg)
+		if _, err := os.Stat(filepath.Join(path, NodePackageFileName)); err != nil {
+			// Not found
+			continue
+		}
+		// Found it, resolve the link (npm link)
+		path, err := util.GetRealPath(path)
+		if err != nil {
+			return nil, err
+		}
+		// Get with parent
+		for i := 0; i < parent; i++ {
+			path = filepath.Dir(path)
+		}
+		// Done
+		return []string{path}, nil
+	}
+	// Not found
+	return nil, nil
+}
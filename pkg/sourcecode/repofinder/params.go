@@ -0,0 +1,93 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:15:52 2026
+//
+// File Name: params.go
+// Description:
+//	The declarative finder parameter schema
+package repofinder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ParamTypeString = "string"
+	ParamTypeInt    = "int"
+	ParamTypeBool   = "bool"
+)
+
+// The spec of a finder parameter
+type ParamSpec struct {
+	Name     string      // The parameter name
+	Type     string      // The parameter type, one of ParamType*
+	Required bool        // Whether the parameter is required
+	Default  interface{} // The default value when the parameter is not required and not specified
+	Usage    string      // The usage of the parameter
+}
+
+type ParamsSchema []ParamSpec
+
+// Parse the parameters by the schema
+// Returns:
+// 	The parsed parameters with defaults applied. Missing required parameters and values with unexpected type are
+// 	treated as errors, the unknown parameters are ignored (see Unknown) so the specs with them still work
+func (this ParamsSchema) Parse(params map[string]interface{}) (map[string]interface{}, error) {
+	var errs []string
+	values := make(map[string]interface{})
+	for _, paramSpec := range this {
+		value, ok := params[paramSpec.Name]
+		if !ok || value == nil {
+			if paramSpec.Required {
+				errs = append(errs, fmt.Sprintf("Require %s parameter", paramSpec.Name))
+			} else {
+				values[paramSpec.Name] = paramSpec.Default
+			}
+			continue
+		}
+		if !isParamType(value, paramSpec.Type) {
+			errs = append(errs, fmt.Sprintf("Invalid value of %s parameter, expect %s but got %T (%v)", paramSpec.Name, paramSpec.Type, value, value))
+			continue
+		}
+		values[paramSpec.Name] = value
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	// Done
+	return values, nil
+}
+
+// Get the sorted names of the parameters not defined in the schema, they are warned (e.g. a typo) but not rejected
+func (this ParamsSchema) Unknown(params map[string]interface{}) []string {
+	known := make(map[string]bool)
+	for _, paramSpec := range this {
+		known[paramSpec.Name] = true
+	}
+	var unknowns []string
+	for name := range params {
+		if !known[name] {
+			unknowns = append(unknowns, name)
+		}
+	}
+	sort.Strings(unknowns)
+	return unknowns
+}
+
+func isParamType(value interface{}, t string) bool {
+	switch t {
+	case ParamTypeString:
+		_, ok := value.(string)
+		return ok
+	case ParamTypeInt:
+		_, ok := value.(int)
+		return ok
+	case ParamTypeBool:
+		_, ok := value.(bool)
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:20:14 2026
+//
+// File Name: params_test.go
+// Description:
+//
+package repofinder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	testParamsSchema = ParamsSchema{
+		{Name: "package", Type: ParamTypeString, Required: true},
+		{Name: "parent", Type: ParamTypeInt, Default: 0},
+		{Name: "dev", Type: ParamTypeBool, Default: false},
+	}
+
+	paramsCases = []struct {
+		Params   map[string]interface{}
+		Values   map[string]interface{}
+		Unknowns []string
+		Error    string
+	}{
+		{
+			Params: map[string]interface{}{"package": "github.com/org/repo"},
+			Values: map[string]interface{}{"package": "github.com/org/repo", "parent": 0, "dev": false},
+		},
+		{
+			Params: map[string]interface{}{"package": "github.com/org/repo", "parent": 2, "dev": true},
+			Values: map[string]interface{}{"package": "github.com/org/repo", "parent": 2, "dev": true},
+		},
+		{
+			Params:   map[string]interface{}{"package": "github.com/org/repo", "parnet": 2, "extra": "x"},
+			Values:   map[string]interface{}{"package": "github.com/org/repo", "parent": 0, "dev": false},
+			Unknowns: []string{"extra", "parnet"},
+		},
+		{Params: map[string]interface{}{"parent": 1}, Error: "Require package parameter"},
+		{Params: map[string]interface{}{"package": nil}, Error: "Require package parameter"},
+		{Params: map[string]interface{}{"package": "x", "parent": "1"}, Error: "Invalid value of parent parameter, expect int"},
+		{Params: map[string]interface{}{"package": 1, "dev": "yes", "other": 1}, Error: "Invalid value of package parameter", Unknowns: []string{"other"}},
+	}
+)
+
+func TestParamsSchema(t *testing.T) {
+	for _, tCase := range paramsCases {
+		values, err := testParamsSchema.Parse(tCase.Params)
+		if unknowns := testParamsSchema.Unknown(tCase.Params); !reflect.DeepEqual(unknowns, tCase.Unknowns) {
+			t.Errorf("Incorrect unknown parameters of %v. Expect %v Actual %v", tCase.Params, tCase.Unknowns, unknowns)
+		}
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of %v. Expect [%s] Actual [%v]", tCase.Params, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to parse %v, error: %s", tCase.Params, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, tCase.Values) {
+			t.Errorf("Incorrect values of %v. Expect %v Actual %v", tCase.Params, tCase.Values, values)
+		}
+	}
+}
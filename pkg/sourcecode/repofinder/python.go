@@ -13,7 +13,6 @@ package repofinder
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"os/exec"
@@ -46,23 +45,20 @@ func NewPythonFinder() PythonFinder {
 	return PythonFinder{}
 }
 
-func (this PythonFinder) Find(ws *workspace.Workspace, params map[string]interface{}) ([]string, error) {
-	_module, ok := params[PythonFinderParamModule]
-	if !ok {
-		return nil, errors.New("Require module parameter")
-	}
-	module, ok := _module.(string)
-	if !ok {
-		return nil, errors.New("Invalid value of module parameter")
+func (this PythonFinder) Params() ParamsSchema {
+	return ParamsSchema{
+		{Name: PythonFinderParamModule, Type: ParamTypeString, Required: true, Usage: "The module name to find"},
+		{Name: PythonFinderParamParent, Type: ParamTypeInt, Default: 0, Usage: "The parent level count"},
 	}
-	parent := 0
-	_parent, ok := params[PythonFinderParamParent]
-	if ok {
-		parent, ok = _parent.(int)
-		if !ok {
-			return nil, errors.New("Invalid value of parent parameter")
-		}
+}
+
+func (this PythonFinder) Find(ws *workspace.Workspace, params map[string]interface{}) ([]string, error) {
+	values, err := this.Params().Parse(params)
+	if err != nil {
+		return nil, err
 	}
+	module := values[PythonFinderParamModule].(string)
+	parent := values[PythonFinderParamParent].(int)
 	// Create and run the python command
 	ctx, cancel := context.WithTimeout(context.Background(), PythonTryImportTimeoutSeconds*time.Second)
 	defer cancel()
@@ -0,0 +1,106 @@
+// Author: lipixun
+// Created Time : 六 10/17 16:12:37 2026
+//
+// File Name: archive.go
+// Description:
+//	The archive repository loader
+//
+//	The archive (zip, tar or tar.gz) is fetched by the fetcher (http(s), s3, gs or oci url, see pkg/fetcher) and
+//	verified by the sha256 digest declared in the reference (or locked in op.lock). The archive is extracted into
+//	the cache and the root is selected by the strip prefix of the reference (see pkg/fetcher/archive.go).
+//
+//	The commit of the loaded repository is the sha256 digest of the archive.
+package repoloader
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	RepositoryTypeArchive = spec.ArchiveRepositoryType
+)
+
+var (
+	archiveSuffixes = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+)
+
+type ArchiveLoader struct {
+}
+
+func NewArchiveLoader() Loader {
+	return ArchiveLoader{}
+}
+
+// Check if the remote is an archive url (by the suffix of the path) which should be loaded by the archive loader
+func IsArchive(remote string) bool {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case uri.SchemeHTTP, uri.SchemeHTTPS, uri.SchemeS3, uri.SchemeGCS:
+		p := strings.ToLower(u.Path)
+		for _, suffix := range archiveSuffixes {
+			if strings.HasSuffix(p, suffix) {
+				return true
+			}
+		}
+	case uri.SchemeOCI:
+		return true
+	}
+	return false
+}
+
+func (this ArchiveLoader) Load(remote string, options LoadOptions, ws *workspace.Workspace) (*spec.Repository, error) {
+	if options.Branch != "" || options.Tag != "" || options.Ref != "" {
+		return nil, errors.New(fmt.Sprintf("Branch, tag or ref is not supported by archive repository [%s], use sha256 instead", remote))
+	}
+	// The declared digest wins, the commit is the locked digest
+	digest := strings.ToLower(options.Sha256)
+	if digest == "" {
+		digest = strings.ToLower(options.Commit)
+	} else if options.Commit != "" && !strings.EqualFold(options.Commit, digest) {
+		return nil, errors.New(fmt.Sprintf("Mismatch sha256 [%s] and commit [%s] of archive repository [%s]", options.Sha256, options.Commit, remote))
+	}
+	// Fetch the archive
+	f, err := fetcher.New(ws)
+	if err != nil {
+		return nil, err
+	}
+	root, archiveDigest, err := f.FetchArchive(remote, digest, options.StripPrefix)
+	if err != nil {
+		if workspace.IsOfflineError(err) {
+			return nil, err
+		}
+		return nil, errors.New(fmt.Sprintf("Failed to fetch archive repository [%s], error: %s", remote, err))
+	}
+	if digest == "" {
+		digest = archiveDigest
+		ws.Logger.LeveledPrintf(log.LevelWarn, "Archive repository [%s] is not verified, declare sha256: %s in the reference to pin it\n", remote, digest)
+	}
+	// Load spec
+	path := filepath.Join(root, options.Path)
+	specFile := filepath.Join(path, spec.SpecFileName)
+	repoSpec, err := loadRepositorySpec(specFile, ws)
+	if err != nil {
+		return nil, err
+	}
+	// Done
+	return &spec.Repository{
+		Uri:      repoSpec.Uri,
+		Source:   remote,
+		SpecFile: specFile,
+		Metadata: spec.RepositoryMetadata{Commit: digest, Message: remote},
+		Spec:     repoSpec,
+		Local:    spec.RepositoryLocalInfo{Path: path},
+	}, nil
+}
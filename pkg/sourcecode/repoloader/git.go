@@ -11,6 +11,7 @@ import (
 	"fmt"
 	git "github.com/libgit2/git2go"
 	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/repofetcher"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
 	"github.com/ops-openlight/openlight/pkg/workspace"
 	"path/filepath"
@@ -29,8 +30,42 @@ func NewGitLoader() Loader {
 }
 
 func (this GitLoader) Load(remote string, options LoadOptions, ws *workspace.Workspace) (*spec.Repository, error) {
-	if strings.HasPrefix(remote, "http://") || strings.HasPrefix(remote, "https://") {
-		return nil, errors.New("Not implemented")
+	if repofetcher.IsRemote(remote) {
+		// Fetch the remote repository
+		fetcher, err := repofetcher.New(ws)
+		if err != nil {
+			return nil, err
+		}
+		ref := options.Ref
+		for _, r := range []string{options.Commit, options.Tag, options.Branch} {
+			if r != "" {
+				ref = r
+				break
+			}
+		}
+		checkoutOptions := fetcher.DefaultCheckoutOptions()
+		if options.Submodules != nil {
+			checkoutOptions.Submodules = *options.Submodules
+		}
+		if options.LFS != nil {
+			checkoutOptions.LFS = *options.LFS
+		}
+		path, err := fetcher.Checkout(remote, ref, checkoutOptions)
+		if err != nil {
+			if workspace.IsOfflineError(err) {
+				return nil, err
+			}
+			return nil, errors.New(fmt.Sprintf("Failed to fetch repository [%s], error: %s", remote, err))
+		}
+		repo, err := this.loadFromLocal(filepath.Join(path, options.Path), ws)
+		if err != nil {
+			return nil, err
+		}
+		repo.Source = remote
+		if repo.Metadata.Branch == "" {
+			repo.Metadata.Branch = options.Branch
+		}
+		return repo, nil
 	} else {
 		// Load from local
 		if options.Branch != "" {
@@ -39,12 +74,15 @@ func (this GitLoader) Load(remote string, options LoadOptions, ws *workspace.Wor
 		if options.Commit != "" {
 			ws.Logger.LeveledPrintf(log.LevelWarn, "Commit will be ignored when load from local path for repository [%s]\n", remote)
 		}
-		return this.loadFromLocal(remote)
+		if options.Tag != "" || options.Ref != "" {
+			ws.Logger.LeveledPrintf(log.LevelWarn, "Ref will be ignored when load from local path for repository [%s]\n", remote)
+		}
+		return this.loadFromLocal(filepath.Join(remote, options.Path), ws)
 	}
 }
 
 // Create repository from a local path (either a local repository or a cloned remote repository)
-func (this GitLoader) loadFromLocal(p string) (*spec.Repository, error) {
+func (this GitLoader) loadFromLocal(p string, ws *workspace.Workspace) (*spec.Repository, error) {
 	// Open git repository
 	gitRepo, err := git.OpenRepositoryExtended(p, 0, "")
 	if err != nil {
@@ -58,13 +96,16 @@ func (this GitLoader) loadFromLocal(p string) (*spec.Repository, error) {
 		return nil, err
 	}
 	metadata.Commit = headReference.Target().String()
-	headBranch := headReference.Branch()
+	detached, err := gitRepo.IsHeadDetached()
 	if err != nil {
 		return nil, err
 	}
-	metadata.Branch, err = headBranch.Name()
-	if err != nil {
-		return nil, err
+	if !detached {
+		// The branch is unknown when detached (e.g. a fetched remote repository)
+		metadata.Branch, err = headReference.Branch().Name()
+		if err != nil {
+			return nil, err
+		}
 	}
 	commit, err := gitRepo.LookupCommit(headReference.Target())
 	if err != nil {
@@ -72,18 +113,16 @@ func (this GitLoader) loadFromLocal(p string) (*spec.Repository, error) {
 	}
 	metadata.Message = strings.Trim(commit.Message(), "\n\r")
 	// Load spec
-	repoSpec, err := LoadRepositorySpecFromFile(filepath.Join(p, spec.SpecFileName))
+	specFile := filepath.Join(p, spec.SpecFileName)
+	repoSpec, err := loadRepositorySpec(specFile, ws)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("Failed to load repository spec file, error: %s", err))
-	}
-	// Verify the spec
-	if repoSpec.Uri == "" {
-		return nil, errors.New("Invalid repository spec, uri is required")
+		return nil, err
 	}
 	// Create the repository
 	repo := &spec.Repository{
 		Uri:      repoSpec.Uri,
 		Source:   p,
+		SpecFile: specFile,
 		Metadata: metadata,
 		Spec:     repoSpec,
 		Local: spec.RepositoryLocalInfo{
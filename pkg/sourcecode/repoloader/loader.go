@@ -13,7 +13,8 @@ import (
 
 var (
 	loaders map[string]Loader = map[string]Loader{
-		RepositoryTypeGit: NewGitLoader(),
+		RepositoryTypeGit:     NewGitLoader(),
+		RepositoryTypeArchive: NewArchiveLoader(),
 	}
 )
 
@@ -22,8 +23,15 @@ type Loader interface {
 }
 
 type LoadOptions struct {
-	Branch string
-	Commit string
+	Branch      string
+	Commit      string
+	Tag         string
+	Ref         string // A branch, tag or commit
+	Path        string // The sub path of the repository which has the spec file
+	Sha256      string // The expected sha256 digest (hex) of the archive, only used by the archive loader
+	StripPrefix string // The directory in the archive used as the root, only used by the archive loader
+	Submodules  *bool  // Initialize the submodules of the remote repository, the workspace config if nil
+	LFS         *bool  // Pull the git lfs objects of the remote repository, the workspace config if nil
 }
 
 func GetLoader(t string) Loader {
@@ -7,21 +7,59 @@
 package repoloader
 
 import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/workspace"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 )
 
 func LoadRepositorySpecFromFile(filename string) (*spec.RepositorySpec, error) {
+	return loadRepositorySpecFromFile(filename, yaml.Unmarshal)
+}
+
+// Load repository spec from file in strict mode, unknown fields and duplicated keys (e.g. duplicated target names) are treated as errors
+func LoadRepositorySpecFromFileStrict(filename string) (*spec.RepositorySpec, error) {
+	return loadRepositorySpecFromFile(filename, yaml.UnmarshalStrict)
+}
+
+func loadRepositorySpecFromFile(filename string, unmarshal func([]byte, interface{}) error) (*spec.RepositorySpec, error) {
 	// Load repository spec from file
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
 	var repoSpec spec.RepositorySpec
-	if err := yaml.Unmarshal(data, &repoSpec); err != nil {
+	if err := unmarshal(data, &repoSpec); err != nil {
 		return nil, err
 	} else {
 		return &repoSpec, nil
 	}
 }
+
+// Load and verify the repository spec of a loading repository, the unknown or duplicated keys are warned
+func loadRepositorySpec(specFile string, ws *workspace.Workspace) (*spec.RepositorySpec, error) {
+	data, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to load repository spec file [%s], error: %s", specFile, err))
+	}
+	repoSpec := new(spec.RepositorySpec)
+	if strictErr := yaml.UnmarshalStrict(data, repoSpec); strictErr != nil {
+		// Typos in the spec are ignored by the non-strict decoding, warn them
+		repoSpec = new(spec.RepositorySpec)
+		if err := yaml.Unmarshal(data, repoSpec); err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to load repository spec file [%s], error: %s", specFile, err))
+		}
+		ws.Logger.LeveledPrintf(log.LevelWarn, "Unknown or duplicated keys in repository spec file [%s], error: %s\n", specFile, strictErr)
+	}
+	// Verify the spec
+	if err := repoSpec.CheckVersion(); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid repository spec [%s], error: %s", specFile, err))
+	}
+	if repoSpec.Uri == "" {
+		return nil, errors.New(fmt.Sprintf("Invalid repository spec [%s], uri is required", specFile))
+	}
+	return repoSpec, nil
+}
@@ -0,0 +1,79 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:32:08 2026
+//
+// File Name: util_test.go
+// Description:
+//
+package repoloader
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	loadSpecCases = []struct {
+		Name   string
+		Source string
+		Target string // The target expected in the loaded spec
+		Warn   string // The expected warning, none if empty
+		Error  string
+	}{
+		{Name: "valid", Source: "uri: github.com/org/repo\ntargets:\n  app:\n    path: app\n", Target: "app"},
+		{
+			Name:   "unknown key",
+			Source: "uri: github.com/org/repo\ntargets:\n  app:\n    pth: app\n",
+			Target: "app",
+			Warn:   "field pth not found",
+		},
+		{
+			Name:   "unknown dependency key",
+			Source: "uri: github.com/org/repo\ntargets:\n  app:\n    deps:\n      lib:\n        target: lib\n        opts: {}\n",
+			Target: "app",
+			Warn:   "field opts not found",
+		},
+		{Name: "bad yaml", Source: "uri: [\n", Error: "Failed to load repository spec file"},
+		{Name: "no uri", Source: "targets:\n  app:\n    path: app\n", Error: "uri is required"},
+		{Name: "newer version", Source: "version: 99\nuri: github.com/org/repo\n", Error: "please upgrade op"},
+	}
+)
+
+func TestLoadRepositorySpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repoloader-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tCase := range loadSpecCases {
+		specFile := filepath.Join(dir, strings.Replace(tCase.Name, " ", "-", -1)+".yaml")
+		if err := ioutil.WriteFile(specFile, []byte(tCase.Source), 0644); err != nil {
+			t.Fatal(err)
+		}
+		logger := log.NewCaptureLogger()
+		repoSpec, err := loadRepositorySpec(specFile, &workspace.Workspace{Logger: logger})
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to load case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		if _, ok := repoSpec.Targets[tCase.Target]; !ok {
+			t.Errorf("Target [%s] not found in case [%s]", tCase.Target, tCase.Name)
+		}
+		warnings := logger.Filter(log.LevelWarn, "")
+		if tCase.Warn == "" && len(warnings) != 0 {
+			t.Errorf("Unexpected warnings of case [%s]: %v", tCase.Name, warnings)
+		} else if tCase.Warn != "" && (len(warnings) != 1 || !strings.Contains(warnings[0].Message, tCase.Warn)) {
+			t.Errorf("Incorrect warnings of case [%s]. Expect [%s] Actual %v", tCase.Name, tCase.Warn, warnings)
+		}
+	}
+}
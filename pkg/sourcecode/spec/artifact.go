@@ -6,10 +6,20 @@
 //	The artifact
 package spec
 
+const (
+	ArtifactCollectorTypeFile      = "file"      // Collect the files as a file artifact, the default
+	ArtifactCollectorTypeDirectory = "directory" // Collect the directory as a directory artifact with every file recorded
+	ArtifactCollectorTypeArchive   = "archive"   // Pack the directory into an archive artifact
+)
+
 type FileArtifactCollectorSpec struct {
-	Path       string `yaml:"path"`
-	Recursive  bool   `yaml:"recursive"`
-	FollowLink bool   `yaml:"followLink"`
-	Includes   string `yaml:"includes"`
-	Excludes   string `yaml:"excludes"`
+	Type       string   `yaml:"type"` // One of ArtifactCollectorType*
+	Path       string   `yaml:"path"`
+	Recursive  bool     `yaml:"recursive"`
+	FollowLink bool     `yaml:"followLink"`
+	Includes   string   `yaml:"includes"`
+	Excludes   string   `yaml:"excludes"`
+	Ignores    []string `yaml:"ignores"` // The glob patterns of the files to exclude from the directory or archive artifact
+	Format     string   `yaml:"format"`  // The format of the archive artifact, tar.gz (default), tar.zst or zip
+	Level      int      `yaml:"level"`   // The compression level of the archive artifact, 0 for the default level
 }
@@ -0,0 +1,80 @@
+// Author: lipixun
+// Created Time : 五 10/16 10:21:07 2026
+//
+// File Name: doc.go
+// Description:
+//	Describe the spec structures by reflecting the yaml tags, used to generate the spec documentation
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A key in the spec file
+type KeyDoc struct {
+	Path string // The key path, map keys are written as <key>, list items as []
+	Type string // The value type
+}
+
+// Describe all keys of a spec value (or a pointer to it)
+func DescribeKeys(v interface{}) []KeyDoc {
+	var docs []KeyDoc
+	describeKeys(reflect.TypeOf(v), "", &docs)
+	return docs
+}
+
+func describeKeys(t reflect.Type, path string, docs *[]KeyDoc) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				// Unexported
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "-" {
+				continue
+			} else if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fieldPath := joinKeyPath(path, name)
+			*docs = append(*docs, KeyDoc{Path: fieldPath, Type: describeType(field.Type)})
+			describeKeys(field.Type, fieldPath, docs)
+		}
+	case reflect.Map:
+		describeKeys(t.Elem(), joinKeyPath(path, "<key>"), docs)
+	case reflect.Slice:
+		describeKeys(t.Elem(), path+"[]", docs)
+	}
+}
+
+func describeType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return "object"
+	case reflect.Map:
+		return fmt.Sprintf("map of %s", describeType(t.Elem()))
+	case reflect.Slice:
+		return fmt.Sprintf("list of %s", describeType(t.Elem()))
+	case reflect.Interface:
+		return "any"
+	default:
+		return t.Kind().String()
+	}
+}
+
+func joinKeyPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return fmt.Sprintf("%s.%s", path, name)
+}
@@ -19,14 +19,38 @@ type DockerBuildSpec struct {
 }
 
 type DockerBuildFileSpec struct {
-	Target string `yaml:"target"` // The target file / dir name
-	Source struct {
-		Dep *struct {
-			Name     string `yaml:"name"`     // The dependency name
-			Artifact string `yaml:"artifact"` // The artifact name
-		} `yaml:"dep"` // Get file from dependency
-		Local *struct {
-			Path string `yaml:"path"` // The local filename
-		} `yaml:"local"` // Get file from local
+	Target string                `yaml:"target"` // The target file / dir name
+	Source DockerBuildFileSource `yaml:"source"`
+}
+
+// The source of a docker build file, exactly one should be defined
+type DockerBuildFileSource struct {
+	Dep *struct {
+		Name     string `yaml:"name"`     // The dependency name
+		Artifact string `yaml:"artifact"` // The artifact name
+	} `yaml:"dep"` // Get file from dependency
+	Local *struct {
+		Path string `yaml:"path"` // The local filename
+	} `yaml:"local"` // Get file from local
+	Http *struct {
+		Url         string `yaml:"url"`         // The http(s), s3 or gs url
+		Sha256      string `yaml:"sha256"`      // The expected sha256 digest (hex) of the file, not verified if empty
+		Extract     bool   `yaml:"extract"`     // Extract the archive (zip, tar or tar.gz) and add the directory
+		StripPrefix string `yaml:"stripPrefix"` // The directory in the extracted archive to add, see fetcher.FetchArchive
+	} `yaml:"http"` // Download file by http(s) or from the object storage (s3, gs)
+}
+
+// Get the number of the defined sources
+func (this *DockerBuildFileSource) Count() int {
+	var n int
+	if this.Dep != nil {
+		n++
+	}
+	if this.Local != nil {
+		n++
+	}
+	if this.Http != nil {
+		n++
 	}
+	return n
 }
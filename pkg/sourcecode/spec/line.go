@@ -0,0 +1,96 @@
+// Author: lipixun
+// Created Time : 日 10/18 08:14:37 2026
+//
+// File Name: line.go
+// Description:
+//	Locate the key path (e.g. targets.server.build.type, see ValidationError) in the spec file, for the messages and
+//	the annotations pointing to the line
+//
+//	The yaml decoder doesn't keep the node positions, the key path is located in the source instead: the keys of
+//	the block mappings by their indents and the items of the block sequences (e.g. docker.files.0) by their indexes.
+//	The keys in the flow style ({...} or [...]) are located at the line of the outer key.
+package spec
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Get the line number (from 1) of the key path in the spec file, the line of the deepest key found (0 if none)
+// The keys could contain the dots, e.g. references.github.com/org/repo.branch
+func GetKeyPathLine(filename, keyPath string) int {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0
+	}
+	return getKeyPathLine(strings.Split(string(data), "\n"), keyPath)
+}
+
+// Set the lines of the validation errors in the spec file, see GetKeyPathLine
+func LocateValidationErrors(filename string, errs []ValidationError) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(string(data), "\n")
+	for i := range errs {
+		errs[i].Line = getKeyPathLine(lines, errs[i].Path)
+	}
+}
+
+func getKeyPathLine(lines []string, keyPath string) int {
+	if keyPath == "" {
+		return 0
+	}
+	lines = append([]string(nil), lines...)
+	// The indents of the matched key (or sequence item) and its children, the index of the next sequence item
+	rest, indent, childIndent, line, item, inItem := keyPath, -1, -1, 0, 0, false
+	for i := 0; i < len(lines) && rest != ""; i++ {
+		text := strings.TrimRight(lines[i], " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lineIndent := len(text) - len(trimmed)
+		isItem := trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+		if lineIndent < indent || (lineIndent == indent && (inItem || !isItem)) {
+			// The end of the block of the matched key or item (the items of a sequence could be at the indent of its key)
+			break
+		}
+		if childIndent == -1 {
+			childIndent = lineIndent
+		}
+		if lineIndent != childIndent {
+			continue
+		}
+		if isItem {
+			key := strconv.Itoa(item)
+			item++
+			if rest == key {
+				return i + 1
+			}
+			if strings.HasPrefix(rest, key+".") {
+				rest, indent, childIndent, line, item, inItem = rest[len(key)+1:], lineIndent, -1, i+1, 0, true
+				if trimmed != "-" {
+					// The first key of the item is on the same line, check it again as the child of the item
+					lines[i] = strings.Repeat(" ", lineIndent+2) + strings.TrimLeft(trimmed[1:], " ")
+					i--
+				}
+			}
+			continue
+		}
+		idx := strings.Index(trimmed, ":")
+		if idx == -1 {
+			continue
+		}
+		key := strings.Trim(trimmed[:idx], "\"'")
+		if rest == key {
+			return i + 1
+		}
+		if strings.HasPrefix(rest, key+".") {
+			rest, indent, childIndent, line, item, inItem = rest[len(key)+1:], lineIndent, -1, i+1, 0, false
+		}
+	}
+	return line
+}
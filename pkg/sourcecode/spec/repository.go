@@ -8,15 +8,18 @@ package spec
 
 import (
 	"fmt"
+	"sort"
 )
 
 const (
 	DefaultRepositoryType = "git"
+	ArchiveRepositoryType = "archive"
 )
 
 type Repository struct {
 	Uri      string              // The repository uri
 	Source   string              // The source uri
+	SpecFile string              // The path of the spec file this repository is loaded from
 	Local    RepositoryLocalInfo // The local info
 	Metadata RepositoryMetadata  // The metadata
 	Spec     *RepositorySpec     // The repository spec
@@ -50,6 +53,7 @@ func (this *RepositoryMetadata) String() string {
 
 // The repository spec
 type RepositorySpec struct {
+	Version int    `yaml:"version"` // The spec version, see SpecVersion
 	Uri     string `yaml:"uri"`
 	Options struct {
 		Default struct {
@@ -62,11 +66,29 @@ type RepositorySpec struct {
 	Targets    map[string]*TargetSpec              `yaml:"targets"`    // Key is target name
 }
 
+// Get the target names in sorted order
+func (this *RepositorySpec) GetTargetNames() []string {
+	var names []string
+	for name := range this.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type RepositoryReferenceSpec struct {
 	Remote string `yaml:"remote"` // The repository remote path, either a local path or url
-	Branch string `yaml:"branch"`
-	Commit string `yaml:"commit"`
-	Finder struct {
+	Type   string `yaml:"type"`   // The repository type, git or archive. Inferred from the remote if empty
+	Sha256 string `yaml:"sha256"` // The expected sha256 digest (hex) of the archive, not verified if empty
+	// The directory in the archive used as the root, the single top directory is stripped if empty
+	StripPrefix string `yaml:"stripPrefix"`
+	Branch      string `yaml:"branch"`
+	Commit      string `yaml:"commit"`
+	// Initialize the git submodules recursively and pull the git lfs objects, the config git.submodules and git.lfs
+	// are used if not specified
+	Submodules *bool `yaml:"submodules"`
+	LFS        *bool `yaml:"lfs"`
+	Finder     struct {
 		Type   string                 `yaml:"type"`
 		Params map[string]interface{} `yaml:"params"`
 	} `yaml:"finder"` // The repository finder
@@ -7,8 +7,11 @@
 package spec
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 type Target struct {
@@ -40,7 +43,91 @@ type TargetSpec struct {
 		Golang *GolangBuildSpec `yaml:"golang"`
 		Python *PythonBuildSpec `yaml:"python"`
 	} `yaml:"build"`
-	Deps map[string]*TargetDependencySpec `yaml:"deps"` // The key is target dependency name
+	Test *TestSpec          `yaml:"test"` // The test spec, a target with only test spec is a test target
+	Deps TargetDependencies `yaml:"deps"` // The key is target dependency name
+}
+
+// The target dependencies, the key is the dependency name
+// In the spec file, the dependencies could be declared either as a map of TargetDependencySpec or a list of target references in short form:
+// 	- ":target" or "target" 	The target in the same repository
+// 	- "repository:target" 		The target in another repository, the repository is the part before the last colon
+// The dependency name of the short form is the target name (the reference if the target name is shared by the
+// targets of different repositories) and the dependency will be built
+type TargetDependencies map[string]*TargetDependencySpec
+
+func (this *TargetDependencies) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	if _, ok := raw.([]interface{}); !ok {
+		// The map form, the error (e.g. an unknown key of a dependency) is returned as it is
+		var deps map[string]*TargetDependencySpec
+		if err := unmarshal(&deps); err != nil {
+			return err
+		}
+		*this = TargetDependencies(deps)
+		return nil
+	}
+	// The short form
+	var refs []string
+	if err := unmarshal(&refs); err != nil {
+		return errors.New(fmt.Sprintf("Target dependencies should be a list of target references, error: %s", err))
+	}
+	var parsed []*TargetDependencySpec
+	keys := make(map[string]bool)
+	targets := make(map[string]int)
+	for _, ref := range refs {
+		dep, err := ParseTargetDependencyReference(ref)
+		if err != nil {
+			return err
+		}
+		if keys[dep.Key()] {
+			return errors.New(fmt.Sprintf("Duplicated target reference [%s]", ref))
+		}
+		keys[dep.Key()] = true
+		targets[dep.Target]++
+		parsed = append(parsed, dep)
+	}
+	deps := make(map[string]*TargetDependencySpec)
+	for _, dep := range parsed {
+		name := dep.Target
+		if targets[dep.Target] > 1 {
+			name = dep.Key()
+		}
+		deps[name] = dep
+	}
+	*this = TargetDependencies(deps)
+	// Done
+	return nil
+}
+
+// Get the dependency names in sorted order, iterate the dependencies by these names to get a stable order
+func (this TargetDependencies) Names() []string {
+	var names []string
+	for name := range this {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse the target dependency from a short form target reference
+func ParseTargetDependencyReference(ref string) (*TargetDependencySpec, error) {
+	dep := new(TargetDependencySpec)
+	idx := strings.LastIndex(ref, ":")
+	if idx == -1 {
+		dep.Target = ref
+	} else {
+		dep.Repository = ref[:idx]
+		dep.Target = ref[idx+1:]
+	}
+	if dep.Target == "" {
+		return nil, errors.New(fmt.Sprintf("Invalid target reference [%s], require target name", ref))
+	}
+	dep.Options.Build = true
+	// Done
+	return dep, nil
 }
 
 type TargetDependencySpec struct {
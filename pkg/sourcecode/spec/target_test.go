@@ -0,0 +1,66 @@
+// Author: lipixun
+// Created Time : 五 10/16 10:05:31 2026
+//
+// File Name: target_test.go
+// Description:
+//
+package spec
+
+import (
+	"gopkg.in/yaml.v2"
+	"strings"
+	"testing"
+)
+
+var (
+	targetDependenciesCases = []struct {
+		Source string
+		Keys   map[string]string // The dependency name to the key
+		Error  string            // The substring of the error
+	}{
+		{
+			Source: "{a: {target: a}, b: {target: b, repository: github.com/org/repo}}",
+			Keys:   map[string]string{"a": ":a", "b": "github.com/org/repo:b"},
+		},
+		{
+			Source: "[a, ':b', 'github.com/org/repo:c']",
+			Keys:   map[string]string{"a": ":a", "b": ":b", "c": "github.com/org/repo:c"},
+		},
+		{
+			Source: "[a, 'github.com/org/repo:a', 'github.com/org/other:b']",
+			Keys:   map[string]string{":a": ":a", "github.com/org/repo:a": "github.com/org/repo:a", "b": "github.com/org/other:b"},
+		},
+		{Source: "[a, ':a']", Error: "Duplicated target reference [:a]"},
+		{Source: "['github.com/org/repo:']", Error: "require target name"},
+		{Source: "{a: {target: a, unknown: true}}", Error: "line 1: field unknown not found"},
+		{Source: "{a: {target: a, options: {build: maybe}}}", Error: "line 1: cannot unmarshal"},
+		{Source: "[{target: a}]", Error: "should be a list of target references"},
+		{Source: "a", Error: "cannot unmarshal"},
+	}
+)
+
+func TestTargetDependenciesUnmarshal(t *testing.T) {
+	for _, tCase := range targetDependenciesCases {
+		var deps TargetDependencies
+		err := yaml.UnmarshalStrict([]byte(tCase.Source), &deps)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of [%s]. Expect [%s] Actual [%v]", tCase.Source, tCase.Error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to unmarshal [%s], error: %s", tCase.Source, err)
+			continue
+		}
+		if len(deps) != len(tCase.Keys) {
+			t.Errorf("Incorrect dependencies of [%s]. Expect %v Actual %v", tCase.Source, tCase.Keys, deps.Names())
+			continue
+		}
+		for name, key := range tCase.Keys {
+			if dep, ok := deps[name]; !ok || dep.Key() != key {
+				t.Errorf("Incorrect dependency [%s] of [%s]. Expect [%s] Actual [%v]", name, tCase.Source, key, dep)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Author: lipixun
+// Created Time : 五 10/16 14:10:37 2026
+//
+// File Name: test.go
+// Description:
+//	The test spec
+package spec
+
+// The test spec of a target
+type TestSpec struct {
+	Command string   `yaml:"command"` // The test command
+	Args    []string `yaml:"args"`    // The arguments of the test command
+	WorkDir string   `yaml:"workDir"` // The work directory relative to the target path, will use the target path if not specified
+	Envs    []string `yaml:"envs"`    // The extra environment variables, in KEY=VALUE form
+	Timeout int      `yaml:"timeout"` // The timeout in seconds, 0 means no timeout
+}
@@ -0,0 +1,234 @@
+// Author: lipixun
+// Created Time : 五 10/16 09:12:40 2026
+//
+// File Name: validate.go
+// Description:
+//	The repository spec validation
+package spec
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/artifact"
+	gopath "path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	BuildTypeShell  = "shell"
+	BuildTypeDocker = "docker"
+	BuildTypeGolang = "golang"
+	BuildTypePython = "python"
+)
+
+var (
+	sha256RegularExp = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+)
+
+// A problem found when validating the spec
+type ValidationError struct {
+	Path    string // The key path of the spec item, e.g. targets.server.build.type
+	Message string // The error message
+	Line    int    // The line of the spec item in the spec file, 0 if unknown (see LocateValidationErrors)
+}
+
+func (this ValidationError) Error() string {
+	if this.Path == "" {
+		return this.Message
+	}
+	return fmt.Sprintf("%s: %s", this.Path, this.Message)
+}
+
+// Validate the repository spec
+// Returns:
+// 	All errors found in the spec, sorted by the key path. Empty means the spec is valid
+func (this *RepositorySpec) Validate() []ValidationError {
+	var errs []ValidationError
+	addError := func(path, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	// Check the repository
+	if err := this.CheckVersion(); err != nil {
+		addError("version", "%s", err)
+	}
+	if this.Uri == "" {
+		addError("uri", "Require uri")
+	}
+	if name := this.Options.Default.Build.Target; name != "" {
+		if _, ok := this.Targets[name]; !ok {
+			addError("options.default.build.target", "Target [%s] not found", name)
+		}
+	}
+	// Check the references
+	for uri, refer := range this.References {
+		path := fmt.Sprintf("references.%s", uri)
+		if refer == nil {
+			addError(path, "Empty reference")
+			continue
+		}
+		if refer.Remote == "" && refer.Finder.Type == "" {
+			addError(path, "Require either remote or finder")
+		}
+		if refer.Branch != "" && refer.Commit != "" {
+			addError(path, "Cannot specify both branch and commit")
+		}
+		switch refer.Type {
+		case "", DefaultRepositoryType:
+		case ArchiveRepositoryType:
+			if refer.Branch != "" || refer.Commit != "" {
+				addError(path, "Cannot specify branch or commit of archive repository, use sha256 instead")
+			}
+			if refer.Submodules != nil || refer.LFS != nil {
+				addError(path, "Cannot specify submodules or lfs of archive repository")
+			}
+		default:
+			addError(path+".type", "Unknown repository type [%s]", refer.Type)
+		}
+		if refer.Sha256 != "" && !sha256RegularExp.MatchString(refer.Sha256) {
+			addError(path+".sha256", "Invalid sha256 digest [%s]", refer.Sha256)
+		}
+	}
+	// Check the targets
+	for name, target := range this.Targets {
+		path := fmt.Sprintf("targets.%s", name)
+		if target == nil {
+			addError(path, "Empty target")
+			continue
+		}
+		for depName, dep := range target.Deps {
+			depPath := fmt.Sprintf("%s.deps.%s", path, depName)
+			if dep == nil {
+				addError(depPath, "Empty dependency")
+				continue
+			}
+			if dep.Target == "" {
+				addError(depPath, "Require target")
+			} else if dep.Repository == "" || dep.Repository == this.Uri {
+				if _, ok := this.Targets[dep.Target]; !ok {
+					addError(depPath, "Dependent target [%s] not found", dep.Target)
+				}
+			} else if _, ok := this.References[dep.Repository]; !ok {
+				addError(depPath, "Repository reference of [%s] not found", dep.Repository)
+			}
+		}
+		if target.Test != nil {
+			if target.Test.Command == "" {
+				addError(path+".test.command", "Require command")
+			}
+			if target.Test.Timeout < 0 {
+				addError(path+".test.timeout", "Timeout cannot be negative")
+			}
+		}
+		if target.Test == nil || target.Build.Type != "" {
+			errs = append(errs, target.validateBuild(path)...)
+		}
+	}
+	// Sort the errors to make the output stable
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path < errs[j].Path
+	})
+	// Done
+	return errs
+}
+
+// Validate the build spec of the target
+func (this *TargetSpec) validateBuild(path string) []ValidationError {
+	var errs []ValidationError
+	path = fmt.Sprintf("%s.build", path)
+	addError := func(path, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	switch this.Build.Type {
+	case "":
+		addError(path+".type", "Require build type")
+	case BuildTypeShell:
+		if this.Build.Shell == nil {
+			addError(path+".shell", "Shell build spec not defined")
+		} else {
+			if this.Build.Shell.Command == "" {
+				addError(path+".shell.command", "Require command")
+			}
+			if len(this.Build.Shell.Collectors) == 0 {
+				addError(path+".shell.collectors", "No artifact collector defined")
+			}
+			for name, collector := range this.Build.Shell.Collectors {
+				collectorPath := fmt.Sprintf("%s.shell.collectors.%s", path, name)
+				if collector == nil {
+					addError(collectorPath, "Empty collector")
+					continue
+				}
+				switch collector.Type {
+				case "", ArtifactCollectorTypeFile:
+					if len(collector.Ignores) > 0 {
+						addError(collectorPath+".ignores", "Require type %s or %s, use excludes instead", ArtifactCollectorTypeDirectory, ArtifactCollectorTypeArchive)
+					}
+				case ArtifactCollectorTypeDirectory, ArtifactCollectorTypeArchive:
+					if collector.Includes != "" || collector.Excludes != "" {
+						addError(collectorPath, "Cannot specify includes or excludes of %s collector, use ignores instead", collector.Type)
+					}
+					for _, pattern := range collector.Ignores {
+						if _, err := gopath.Match(pattern, ""); err != nil {
+							addError(collectorPath+".ignores", "Invalid glob pattern [%s]", pattern)
+						}
+					}
+				default:
+					addError(collectorPath+".type", "Unknown collector type [%s]", collector.Type)
+				}
+				if collector.Type == ArtifactCollectorTypeArchive {
+					if collector.Format != "" && !artifact.IsArchiveFormat(collector.Format) {
+						addError(collectorPath+".format", "Unknown archive format [%s], should be one of %s", collector.Format, strings.Join(artifact.ArchiveFormats, ", "))
+					}
+				} else if collector.Format != "" || collector.Level != 0 {
+					addError(collectorPath, "Require type %s to specify format or level", ArtifactCollectorTypeArchive)
+				}
+			}
+		}
+	case BuildTypeDocker:
+		if this.Build.Docker == nil {
+			addError(path+".docker", "Docker build spec not defined")
+		} else {
+			if this.Build.Docker.Image == "" {
+				addError(path+".docker.image", "Require image name")
+			}
+			for i, f := range this.Build.Docker.Files {
+				filePath := fmt.Sprintf("%s.docker.files.%d", path, i)
+				if n := f.Source.Count(); n > 1 {
+					addError(filePath, "Cannot define more than one of local, dep and http at the same time")
+				} else if n == 0 {
+					addError(filePath, "Require either define local, dep or http")
+				} else if f.Source.Http != nil {
+					if f.Source.Http.Url == "" {
+						addError(filePath+".source.http.url", "Require url")
+					}
+					if f.Source.Http.Sha256 != "" && !sha256RegularExp.MatchString(f.Source.Http.Sha256) {
+						addError(filePath+".source.http.sha256", "Invalid sha256 digest [%s]", f.Source.Http.Sha256)
+					}
+					if f.Source.Http.StripPrefix != "" && !f.Source.Http.Extract {
+						addError(filePath+".source.http.stripPrefix", "Require extract")
+					}
+				} else if f.Source.Dep != nil {
+					if _, ok := this.Deps[f.Source.Dep.Name]; !ok {
+						addError(filePath, "Dependency [%s] not found", f.Source.Dep.Name)
+					}
+				}
+			}
+		}
+	case BuildTypeGolang:
+		if this.Build.Golang == nil {
+			addError(path+".golang", "Golang build spec not defined")
+		} else if this.Build.Golang.Package == "" {
+			addError(path+".golang.package", "Require package")
+		}
+	case BuildTypePython:
+		if this.Build.Python == nil {
+			addError(path+".python", "Python build spec not defined")
+		} else if this.Build.Python.Type != "script" && this.Build.Python.Type != "nuitka" {
+			addError(path+".python.type", "Unknown python build type [%s]", this.Build.Python.Type)
+		}
+	default:
+		addError(path+".type", "Unknown build type [%s]", this.Build.Type)
+	}
+	// Done
+	return errs
+}
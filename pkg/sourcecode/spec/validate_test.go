@@ -0,0 +1,238 @@
+// Author: lipixun
+// Created Time : 五 10/16 10:41:02 2026
+//
+// File Name: validate_test.go
+// Description:
+//
+package spec
+
+import (
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testSpecHeader = "uri: github.com/org/repo\n"
+	testSpecTarget = "targets:\n  app:\n    build:\n      type: golang\n      golang:\n        package: github.com/org/repo/app\n"
+)
+
+var (
+	validateCases = []struct {
+		Name   string
+		Source string
+		Paths  []string // The key paths of the errors in order
+	}{
+		{Name: "valid", Source: testSpecHeader + testSpecTarget},
+		{Name: "newer version", Source: "version: 99\n" + testSpecHeader, Paths: []string{"version"}},
+		{Name: "older version", Source: "version: -1\n" + testSpecHeader, Paths: []string{"version"}},
+		{Name: "no uri", Source: testSpecTarget, Paths: []string{"uri"}},
+		{
+			Name:   "unknown default target",
+			Source: testSpecHeader + "options:\n  default:\n    build:\n      target: none\n",
+			Paths:  []string{"options.default.build.target"},
+		},
+		{Name: "empty reference", Source: testSpecHeader + "references:\n  github.com/org/lib:\n", Paths: []string{"references.github.com/org/lib"}},
+		{
+			Name:   "reference without remote or finder",
+			Source: testSpecHeader + "references:\n  github.com/org/lib:\n    branch: master\n",
+			Paths:  []string{"references.github.com/org/lib"},
+		},
+		{
+			Name:   "reference with branch and commit",
+			Source: testSpecHeader + "references:\n  github.com/org/lib:\n    remote: /tmp/lib\n    branch: master\n    commit: abcd\n",
+			Paths:  []string{"references.github.com/org/lib"},
+		},
+		{
+			Name:   "archive reference with branch and submodules",
+			Source: testSpecHeader + "references:\n  github.com/org/lib:\n    remote: https://host/lib.tar.gz\n    type: archive\n    branch: master\n    submodules: true\n",
+			Paths:  []string{"references.github.com/org/lib", "references.github.com/org/lib"},
+		},
+		{
+			Name:   "unknown reference type",
+			Source: testSpecHeader + "references:\n  github.com/org/lib:\n    remote: /tmp/lib\n    type: svn\n",
+			Paths:  []string{"references.github.com/org/lib.type"},
+		},
+		{
+			Name:   "invalid reference sha256",
+			Source: testSpecHeader + "references:\n  github.com/org/lib:\n    remote: https://host/lib.tar.gz\n    sha256: abc\n",
+			Paths:  []string{"references.github.com/org/lib.sha256"},
+		},
+		{Name: "empty target", Source: testSpecHeader + "targets:\n  app:\n", Paths: []string{"targets.app"}},
+		{
+			Name:   "dependencies",
+			Source: testSpecHeader + testSpecTarget + "    deps:\n      a:\n      b:\n        repository: github.com/org/repo\n      c:\n        target: none\n      d:\n        target: lib\n        repository: github.com/org/lib\n      e:\n        target: app\n",
+			Paths:  []string{"targets.app.deps.a", "targets.app.deps.b", "targets.app.deps.c", "targets.app.deps.d"},
+		},
+		{
+			Name:   "test",
+			Source: testSpecHeader + "targets:\n  unit:\n    test:\n      timeout: -1\n",
+			Paths:  []string{"targets.unit.test.command", "targets.unit.test.timeout"},
+		},
+		{Name: "test target", Source: testSpecHeader + "targets:\n  unit:\n    test:\n      command: go test\n"},
+		{Name: "no build type", Source: testSpecHeader + "targets:\n  app:\n    path: app\n", Paths: []string{"targets.app.build.type"}},
+		{Name: "unknown build type", Source: testSpecHeader + "targets:\n  app:\n    build:\n      type: make\n", Paths: []string{"targets.app.build.type"}},
+		{
+			Name:   "no build specs",
+			Source: testSpecHeader + "targets:\n  a:\n    build:\n      type: shell\n  b:\n    build:\n      type: docker\n  c:\n    build:\n      type: golang\n  d:\n    build:\n      type: python\n",
+			Paths:  []string{"targets.a.build.shell", "targets.b.build.docker", "targets.c.build.golang", "targets.d.build.python"},
+		},
+		{
+			Name:   "shell",
+			Source: testSpecHeader + "targets:\n  app:\n    build:\n      type: shell\n      shell:\n        args: [a]\n",
+			Paths:  []string{"targets.app.build.shell.collectors", "targets.app.build.shell.command"},
+		},
+		{
+			Name: "shell collectors",
+			Source: testSpecHeader + "targets:\n  app:\n    build:\n      type: shell\n      shell:\n        command: make\n        collectors:\n" +
+				"          a:\n" +
+				"          b:\n            ignores: ['*.o']\n" +
+				"          c:\n            type: directory\n            includes: '*.go'\n            ignores: ['[']\n" +
+				"          d:\n            type: tree\n" +
+				"          e:\n            type: archive\n            format: rar\n" +
+				"          f:\n            format: zip\n" +
+				"          g:\n            type: archive\n            format: tar.zst\n            level: 3\n",
+			Paths: []string{
+				"targets.app.build.shell.collectors.a",
+				"targets.app.build.shell.collectors.b.ignores",
+				"targets.app.build.shell.collectors.c",
+				"targets.app.build.shell.collectors.c.ignores",
+				"targets.app.build.shell.collectors.d.type",
+				"targets.app.build.shell.collectors.e.format",
+				"targets.app.build.shell.collectors.f",
+			},
+		},
+		{
+			Name: "docker",
+			Source: testSpecHeader + "targets:\n  app:\n    build:\n      type: docker\n      docker:\n        files:\n" +
+				"          - target: a\n" +
+				"          - target: b\n            source:\n              local: {path: a}\n              dep: {name: x}\n" +
+				"          - target: c\n            source:\n              http: {sha256: abc, stripPrefix: x}\n" +
+				"          - target: d\n            source:\n              dep: {name: none}\n" +
+				"          - target: e\n            source:\n              local: {path: e}\n",
+			Paths: []string{
+				"targets.app.build.docker.files.0",
+				"targets.app.build.docker.files.1",
+				"targets.app.build.docker.files.2.source.http.sha256",
+				"targets.app.build.docker.files.2.source.http.stripPrefix",
+				"targets.app.build.docker.files.2.source.http.url",
+				"targets.app.build.docker.files.3",
+				"targets.app.build.docker.image",
+			},
+		},
+		{
+			Name:   "golang and python",
+			Source: testSpecHeader + "targets:\n  a:\n    build:\n      type: golang\n      golang:\n        output: a\n  b:\n    build:\n      type: python\n      python:\n        type: wheel\n",
+			Paths:  []string{"targets.a.build.golang.package", "targets.b.build.python.type"},
+		},
+	}
+)
+
+func TestValidate(t *testing.T) {
+	for _, tCase := range validateCases {
+		var repoSpec RepositorySpec
+		if err := yaml.UnmarshalStrict([]byte(tCase.Source), &repoSpec); err != nil {
+			t.Errorf("Failed to unmarshal case [%s], error: %s", tCase.Name, err)
+			continue
+		}
+		var paths []string
+		for _, err := range repoSpec.Validate() {
+			paths = append(paths, err.Path)
+		}
+		if !reflect.DeepEqual(paths, tCase.Paths) {
+			t.Errorf("Incorrect errors of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Paths, repoSpec.Validate())
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	for _, tCase := range []struct {
+		Version int
+		Error   string
+	}{
+		{Version: 0},
+		{Version: SpecVersion},
+		{Version: SpecVersion + 1, Error: "please upgrade op"},
+		{Version: -1, Error: "no longer supported"},
+	} {
+		repoSpec := RepositorySpec{Version: tCase.Version}
+		err := repoSpec.CheckVersion()
+		if tCase.Error == "" && err != nil {
+			t.Errorf("Version %d should be supported, error: %s", tCase.Version, err)
+		} else if tCase.Error != "" && (err == nil || !strings.Contains(err.Error(), tCase.Error)) {
+			t.Errorf("Incorrect error of version %d. Expect [%s] Actual [%v]", tCase.Version, tCase.Error, err)
+		}
+	}
+	if version := (&RepositorySpec{}).GetVersion(); version != 1 {
+		t.Errorf("Incorrect default version %d", version)
+	}
+}
+
+func TestLocateValidationErrors(t *testing.T) {
+	source := strings.Join([]string{
+		"uri: github.com/org/repo", // 1
+		"options:",                 // 2
+		"  default:",               // 3
+		"    build:",               // 4
+		"      target: none",       // 5
+		"references:",              // 6
+		"  github.com/org/lib:",    // 7
+		"    remote: /tmp/lib",     // 8
+		"    type: svn",            // 9
+		"targets:",                 // 10
+		"  app:",                   // 11
+		"    deps: [none]",         // 12
+		"    build:",               // 13
+		"      type: docker",       // 14
+		"      docker:",            // 15
+		"        image: app",       // 16
+		"        files:",           // 17
+		"        - target: a",      // 18
+		"          source:",        // 19
+		"            local:",       // 20
+		"              path: a",    // 21
+		"        - target: b",      // 22
+		"          source:",        // 23
+		"            http:",        // 24
+		"              sha256: x",  // 25
+		"  lib:",                   // 26
+		"    test:",                // 27
+		"      timeout: -1",        // 28
+	}, "\n")
+	dir, err := ioutil.TempDir("", "spec-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, SpecFileName)
+	if err := ioutil.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var repoSpec RepositorySpec
+	if err := yaml.UnmarshalStrict([]byte(source), &repoSpec); err != nil {
+		t.Fatal(err)
+	}
+	errs := repoSpec.Validate()
+	LocateValidationErrors(filename, errs)
+	expects := map[string]int{
+		"options.default.build.target":                        5,
+		"references.github.com/org/lib.type":                  9,
+		"targets.app.deps.none":                               12,
+		"targets.app.build.docker.files.1.source.http.sha256": 25,
+		"targets.app.build.docker.files.1.source.http.url":    24,
+		"targets.lib.test.command":                            27,
+		"targets.lib.test.timeout":                            28,
+	}
+	if len(errs) != len(expects) {
+		t.Fatalf("Incorrect errors. Expect %v Actual %v", expects, errs)
+	}
+	for _, err := range errs {
+		if line, ok := expects[err.Path]; !ok || err.Line != line {
+			t.Errorf("Incorrect line of [%s]. Expect %d Actual %d", err.Path, line, err.Line)
+		}
+	}
+}
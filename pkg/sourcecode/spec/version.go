@@ -0,0 +1,42 @@
+// Author: lipixun
+// Created Time : 五 10/16 15:31:07 2026
+//
+// File Name: version.go
+// Description:
+//	The repository spec version
+//
+//	The spec file declares the version it's written for by the "version" key, version 1 is assumed if not declared.
+//	A spec file with a version newer than SpecVersion requires a newer op binary, a spec file with a version older
+//	than MinSpecVersion uses keys which are no longer supported.
+package spec
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	SpecVersion    = 1 // The latest spec version supported
+	MinSpecVersion = 1 // The oldest spec version supported
+)
+
+// Get the declared spec version, version 1 is returned if not declared
+func (this *RepositorySpec) GetVersion() int {
+	if this.Version == 0 {
+		return 1
+	}
+	return this.Version
+}
+
+// Check if the spec version is supported
+func (this *RepositorySpec) CheckVersion() error {
+	version := this.GetVersion()
+	if version > SpecVersion {
+		return errors.New(fmt.Sprintf("Spec version %d is newer than the supported version %d, please upgrade op", version, SpecVersion))
+	}
+	if version < MinSpecVersion {
+		return errors.New(fmt.Sprintf("Spec version %d is no longer supported, the oldest supported version is %d", version, MinSpecVersion))
+	}
+	// Done
+	return nil
+}
@@ -0,0 +1,145 @@
+// Author: lipixun
+// Created Time : 日 10/18 07:52:06 2026
+//
+// File Name: junit.go
+// Description:
+//	The junit xml report of the test results for the CI systems
+//
+//	Each test target is a test case, the class name is the repository uri and the name is the target name. A failed
+//	test has the error as the failure message and the tail of its log as the failure content. A cached test is a
+//	passed test case of no time.
+package tester
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	JUnitSuiteName  = "op"
+	JUnitLogMaxSize = 64 << 10 // The max bytes of the log tail in the failure
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// Write the junit xml report of the results to the file
+func WriteJUnitReport(path string, results []*TestResult) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteJUnit(file, results)
+}
+
+// Write the junit xml of the results
+func WriteJUnit(writer io.Writer, results []*TestResult) error {
+	suite := junitTestSuite{Name: JUnitSuiteName, Tests: len(results)}
+	var duration float64
+	var start time.Time
+	for _, result := range results {
+		testCase := junitTestCase{Name: result.Target}
+		if idx := strings.LastIndex(result.Target, ":"); idx != -1 {
+			testCase.ClassName, testCase.Name = result.Target[:idx], result.Target[idx+1:]
+		}
+		switch result.Status {
+		case StatusCached:
+			testCase.Time = formatJUnitTime(0)
+			testCase.SystemOut = fmt.Sprintf("Cached, passed at %s", result.Time.Format(time.RFC3339))
+		case StatusPassed:
+			testCase.Time = formatJUnitTime(result.Duration)
+		default:
+			suite.Failures++
+			testCase.Time = formatJUnitTime(result.Duration)
+			testCase.Failure = &junitFailure{Message: result.Error, Content: readLogTail(result.LogFile)}
+		}
+		if result.Status != StatusCached {
+			duration += result.Duration
+			if start.IsZero() || result.Time.Before(start) {
+				start = result.Time
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = formatJUnitTime(duration)
+	if !start.IsZero() {
+		suite.Timestamp = start.UTC().Format("2006-01-02T15:04:05")
+	}
+	suites := junitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Time: suite.Time, Suites: []junitTestSuite{suite}}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// Format the seconds in the junit time
+func formatJUnitTime(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+// Read the tail of the log file, empty if failed to read
+func readLogTail(path string) string {
+	if path == "" {
+		return ""
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() > JUnitLogMaxSize {
+		file.Seek(info.Size()-JUnitLogMaxSize, io.SeekStart)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ""
+	}
+	// Remove the control characters invalid in xml, e.g. the escape of the colors
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, string(data))
+}
@@ -0,0 +1,125 @@
+// Author: lipixun
+// Created Time : 五 10/16 20:05:12 2026
+//
+// File Name: junit_test.go
+// Description:
+//
+package tester
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	readLogTailCases = []struct {
+		Name    string
+		Content string
+		Expect  string
+	}{
+		{Name: "empty"},
+		{Name: "plain", Content: "line1\nline2\n", Expect: "line1\nline2\n"},
+		{Name: "whitespaces", Content: "a\tb\r\nc\n", Expect: "a\tb\r\nc\n"},
+		{Name: "colors", Content: "\x1b[31mFAIL\x1b[0m: test\x00\x07\n", Expect: "[31mFAIL[0m: test\n"},
+		{
+			Name:    "tail",
+			Content: strings.Repeat("h", 100) + strings.Repeat("t", JUnitLogMaxSize),
+			Expect:  strings.Repeat("t", JUnitLogMaxSize),
+		},
+	}
+)
+
+func TestReadLogTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tCase := range readLogTailCases {
+		path := filepath.Join(dir, tCase.Name+".log")
+		if err := ioutil.WriteFile(path, []byte(tCase.Content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if tail := readLogTail(path); tail != tCase.Expect {
+			t.Errorf("Incorrect log tail of case [%s]. Expect %d bytes [%.64q] Actual %d bytes [%.64q]", tCase.Name, len(tCase.Expect), tCase.Expect, len(tail), tail)
+		}
+	}
+	// Not existed or no log file
+	if tail := readLogTail(filepath.Join(dir, "none.log")); tail != "" {
+		t.Errorf("Incorrect log tail of the not existed file. Actual [%s]", tail)
+	}
+	if tail := readLogTail(""); tail != "" {
+		t.Errorf("Incorrect log tail of no log file. Actual [%s]", tail)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logFile := filepath.Join(dir, "fail.log")
+	if err := ioutil.WriteFile(logFile, []byte("\x1b[31m--- FAIL\x1b[0m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 16, 20, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	results := []*TestResult{
+		{Target: "github.com/org/app:app", Status: StatusPassed, Time: start.Add(time.Minute), Duration: 1.5},
+		{Target: "github.com/org/app:cached", Status: StatusCached, Time: start.Add(-time.Hour), Duration: 10},
+		{Target: "github.com/org/app:fail", Status: StatusFailed, Time: start, Duration: 0.25, LogFile: logFile, Error: "exit status 1"},
+		{Target: "github.com/org/lib:lib", Status: StatusFailed, Time: start.Add(time.Second), Error: "Canceled by interrupt"},
+	}
+	path := filepath.Join(dir, "reports", "junit.xml")
+	if err := WriteJUnitReport(path, results); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(xml.Header)) {
+		t.Errorf("Incorrect junit report. Expect the xml header Actual [%.64s]", data)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("Failed to parse the junit report, error: %s", err)
+	}
+	expect := junitTestSuites{
+		XMLName:  xml.Name{Local: "testsuites"},
+		Tests:    4,
+		Failures: 2,
+		Time:     "1.750",
+		Suites: []junitTestSuite{{
+			Name:      JUnitSuiteName,
+			Tests:     4,
+			Failures:  2,
+			Time:      "1.750",
+			Timestamp: "2026-10-16T12:00:00",
+			Cases: []junitTestCase{
+				{ClassName: "github.com/org/app", Name: "app", Time: "1.500"},
+				{ClassName: "github.com/org/app", Name: "cached", Time: "0.000", SystemOut: "Cached, passed at " + start.Add(-time.Hour).Format(time.RFC3339)},
+				{ClassName: "github.com/org/app", Name: "fail", Time: "0.250", Failure: &junitFailure{Message: "exit status 1", Content: "[31m--- FAIL[0m\n"}},
+				{ClassName: "github.com/org/lib", Name: "lib", Time: "0.000", Failure: &junitFailure{Message: "Canceled by interrupt"}},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(suites, expect) {
+		t.Errorf("Incorrect junit report. Expect %+v Actual %+v", expect, suites)
+	}
+	// No results
+	var buffer bytes.Buffer
+	if err := WriteJUnit(&buffer, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), `<testsuite name="op" tests="0" failures="0" time="0.000">`) {
+		t.Errorf("Incorrect junit report of no results. Actual [%s]", buffer.String())
+	}
+}
@@ -0,0 +1,282 @@
+// Author: lipixun
+// Created Time : 五 10/16 14:32:18 2026
+//
+// File Name: tester.go
+// Description:
+//	The tester runs the test targets
+//
+//	The tester directory
+//		<user>/sourcecode/tester/
+//			logs/
+//				<target regular key>.log 	The output of the last run of the test
+//
+//	The result of a passed test is put in the workspace cache "tester" by its fingerprint, the test is skipped if the
+//	fingerprint is not changed
+//	The fingerprint of a test target is computed from its test spec and the files of the target and all its dependencies
+//
+package tester
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	TesterLogHeader = "SourceCode.Tester"
+
+	TesterDirName        = "sourcecode/tester" // The tester directory (relative to the user workdir)
+	TesterCacheNamespace = "tester"
+	TesterCacheTTL       = 30 * 24 * time.Hour // Rerun the passed tests after this duration even if not changed
+	TesterCacheMaxSize   = 16 << 20
+	TesterLogsDirName    = "logs"
+
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+	StatusCached = "cached"
+)
+
+var (
+	targetNameRegularExp = regexp.MustCompile("[^a-zA-Z\\d\\.]")
+)
+
+type Tester struct {
+	graph   *graph.Graph
+	logger  log.Logger
+	path    string
+	cache   *workspace.Cache
+	Options TesterOptions
+}
+
+type TesterOptions struct {
+	Jobs    int            // The max number of tests running in parallel, 1 if not positive
+	Filter  *regexp.Regexp // Only run the test targets whose key matches the filter
+	NoCache bool           // Run the tests even the fingerprint is not changed
+}
+
+// The result of a test target
+type TestResult struct {
+	Target      string    `json:"target"`      // The target key
+	Status      string    `json:"status"`      // The status, one of Status*
+	Fingerprint string    `json:"fingerprint"` // The input fingerprint
+	Time        time.Time `json:"time"`        // The time when start the test
+	Duration    float64   `json:"duration"`    // The test time in seconds
+	LogFile     string    `json:"logFile"`     // The log file of the test output
+	Error       string    `json:"error"`       // The error message if failed
+}
+
+// Create a new Tester
+func New(g *graph.Graph, options TesterOptions) (*Tester, error) {
+	if g == nil {
+		return nil, errors.New("Require graph")
+	}
+	path, err := GetTesterPath(g.Workspace())
+	if err != nil {
+		return nil, err
+	}
+	cache, err := g.Workspace().Cache(TesterCacheNamespace, workspace.CacheOptions{TTL: TesterCacheTTL, MaxSize: TesterCacheMaxSize})
+	if err != nil {
+		return nil, err
+	}
+	if options.Jobs <= 0 {
+		options.Jobs = 1
+	}
+	return &Tester{
+		graph:   g,
+		logger:  g.Workspace().Logger.GetLoggerWithHeader(TesterLogHeader),
+		path:    path,
+		cache:   cache,
+		Options: options,
+	}, nil
+}
+
+// Get the tester directory
+func GetTesterPath(ws *workspace.Workspace) (string, error) {
+	return ws.Dir.User.GetPath(TesterDirName)
+}
+
+// Get the test targets in the targets (targets without test spec or not matched by filter are ignored)
+func (this *Tester) GetTestTargets(targets []*spec.Target) []*spec.Target {
+	var testTargets []*spec.Target
+	for _, target := range targets {
+		if target.Spec.Test == nil {
+			continue
+		}
+		if this.Options.Filter != nil && !this.Options.Filter.MatchString(target.Key()) {
+			continue
+		}
+		testTargets = append(testTargets, target)
+	}
+	return testTargets
+}
+
+// Run the test targets
+// Returns:
+//
+//	The results in the same order of the targets
+func (this *Tester) Run(targets []*spec.Target) []*TestResult {
+	results := make([]*TestResult, len(targets))
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for i := 0; i < this.Options.Jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				results[idx] = this.runTarget(targets[idx])
+			}
+		}()
+	}
+	for i := range targets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	// Done
+	return results
+}
+
+func (this *Tester) runTarget(target *spec.Target) *TestResult {
+	result := &TestResult{Target: target.Key(), Time: time.Now()}
+	// The rest tests are not run after canceled
+	if err := this.graph.Workspace().Canceled(); err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	// Check the cache
+	fingerprint, err := this.Fingerprint(target)
+	if err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to get fingerprint of target [%s], error: %s\n", target.Key(), err)
+	}
+	result.Fingerprint = fingerprint
+	if fingerprint != "" && !this.Options.NoCache {
+		if data, ok, err := this.cache.GetBytes(fingerprint); err != nil {
+			this.logger.LeveledPrintf(log.LevelWarn, "Failed to get test cache of target [%s], error: %s\n", target.Key(), err)
+		} else if ok {
+			var cachedResult TestResult
+			if err := json.Unmarshal(data, &cachedResult); err == nil && cachedResult.Status == StatusPassed {
+				cachedResult.Status = StatusCached
+				return &cachedResult
+			}
+		}
+	}
+	// Run the test
+	err = this.runTest(target, result)
+	result.Duration = time.Now().Sub(result.Time).Seconds()
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = StatusPassed
+	// Write the cache
+	if fingerprint != "" {
+		if data, err := json.Marshal(result); err == nil {
+			if _, err := this.cache.PutBytes(fingerprint, data); err != nil {
+				this.logger.LeveledPrintf(log.LevelWarn, "Failed to write test cache of target [%s], error: %s\n", target.Key(), err)
+			}
+		}
+	}
+	// Done
+	return result
+}
+
+func (this *Tester) runTest(target *spec.Target, result *TestResult) error {
+	testSpec := target.Spec.Test
+	if testSpec == nil {
+		return errors.New("Test spec not defined")
+	}
+	// Create the log file
+	logFile := filepath.Join(this.path, TesterLogsDirName, fmt.Sprintf("%s.log", targetNameRegularExp.ReplaceAllString(target.Key(), "_")))
+	if err := os.MkdirAll(filepath.Dir(logFile), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.Create(logFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	result.LogFile = logFile
+	// Create the command
+	ctx := this.graph.Workspace().Context()
+	if testSpec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(testSpec.Timeout)*time.Second)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, testSpec.Command, testSpec.Args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = workspace.CommandWaitDelay
+	cmd.Dir = filepath.Join(target.Path(), testSpec.WorkDir)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("CI_BRANCH=%s", target.Repository.Metadata.Branch),
+		fmt.Sprintf("CI_COMMIT=%s", target.Repository.Metadata.Commit),
+	)
+	cmd.Env = append(cmd.Env, testSpec.Envs...)
+	if this.graph.Workspace().Verbose && this.Options.Jobs == 1 {
+		cmd.Stdout = io.MultiWriter(os.Stdout, file)
+		cmd.Stderr = io.MultiWriter(os.Stderr, file)
+	} else {
+		cmd.Stdout = file
+		cmd.Stderr = file
+	}
+	this.logger.LeveledPrintf(log.LevelDebug, "Run test of target [%s]: %s %v\n", target.Key(), cmd.Path, cmd.Args)
+	if err := cmd.Run(); err != nil {
+		if err := this.graph.Workspace().Canceled(); err != nil {
+			return err
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.New(fmt.Sprintf("Timeout after %d seconds", testSpec.Timeout))
+		}
+		return err
+	}
+	// Done
+	return nil
+}
+
+// Get the input fingerprint of the test target
+func (this *Tester) Fingerprint(target *spec.Target) (string, error) {
+	hash := sha256.New()
+	specData, err := json.Marshal(target.Spec.Test)
+	if err != nil {
+		return "", err
+	}
+	hash.Write(specData)
+	// Hash the files of the target and all dependencies
+	deps, err := this.graph.Deps(target)
+	if err != nil {
+		return "", err
+	}
+	var paths []string
+	for _, dep := range deps {
+		paths = append(paths, dep.Path())
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(hash, "path:%s\n", path)
+		if err := util.HashPath(path, hash); err != nil {
+			return "", err
+		}
+	}
+	// Done
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,270 @@
+// Author: lipixun
+// Created Time : 五 10/16 20:31:47 2026
+//
+// File Name: tester_test.go
+// Description:
+//
+package tester
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/graph"
+	"github.com/ops-openlight/openlight/pkg/sourcecode/spec"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const (
+	testRepository = "github.com/org/app"
+)
+
+var (
+	// The targets of the test repository: the test spec and the dependencies (the target names)
+	testTargets = []struct {
+		Name string
+		Test *spec.TestSpec
+		Deps []string
+	}{
+		{Name: "lib"},
+		{Name: "app", Test: &spec.TestSpec{Command: "sh", Args: []string{"-c", "cat ../lib/lib.txt"}}, Deps: []string{"lib"}},
+		{Name: "env", Test: &spec.TestSpec{Command: "sh", Args: []string{"-c", `test "$CI_BRANCH:$TEST_ENV" = "master:1"`}, Envs: []string{"TEST_ENV=1"}}},
+		{Name: "workdir", Test: &spec.TestSpec{Command: "sh", Args: []string{"-c", "test -f lib.txt"}, WorkDir: "../lib"}},
+		{Name: "fail", Test: &spec.TestSpec{Command: "sh", Args: []string{"-c", "echo failed output; exit 1"}}},
+		{Name: "timeout", Test: &spec.TestSpec{Command: "sleep", Args: []string{"10"}, Timeout: 1}},
+	}
+
+	runCases = []struct {
+		Target string
+		Status string
+		Error  string
+		Log    string // The expected content in the log file
+	}{
+		{Target: "app", Status: StatusPassed, Log: "lib content"},
+		{Target: "env", Status: StatusPassed},
+		{Target: "workdir", Status: StatusPassed},
+		{Target: "fail", Status: StatusFailed, Error: "exit status 1", Log: "failed output"},
+		{Target: "timeout", Status: StatusFailed, Error: "Timeout after 1 seconds"},
+	}
+)
+
+// Create the tester of a new workspace under dir with the test repository
+func newTestTester(t *testing.T, dir string, options TesterOptions) (*Tester, *log.CaptureLogger) {
+	wsOptions := workspace.NewWorkspaceOptions()
+	wsOptions.Dir.GlobalPath = filepath.Join(dir, "global")
+	wsOptions.Dir.UserPath = filepath.Join(dir, "user")
+	wsOptions.Dir.ProjectPath = filepath.Join(dir, "project")
+	wsOptions.LogFile = false
+	wsOptions.EnableColor = false
+	for _, path := range []string{wsOptions.Dir.GlobalPath, wsOptions.Dir.UserPath, wsOptions.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := workspace.New(wsOptions, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := graph.New(ws, graph.GraphOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The repository
+	repo := &spec.Repository{
+		Uri:      testRepository,
+		Local:    spec.RepositoryLocalInfo{Path: filepath.Join(dir, "repo")},
+		Metadata: spec.RepositoryMetadata{Branch: "master", Commit: "0123456789abcdef"},
+		Spec:     &spec.RepositorySpec{Uri: testRepository, Targets: make(map[string]*spec.TargetSpec)},
+	}
+	g.Repositories[testRepository] = repo
+	for _, target := range testTargets {
+		targetSpec := &spec.TargetSpec{Path: target.Name, Test: target.Test, Deps: make(spec.TargetDependencies)}
+		for _, dep := range target.Deps {
+			targetSpec.Deps[dep] = &spec.TargetDependencySpec{Repository: testRepository, Target: dep}
+		}
+		repo.Spec.Targets[target.Name] = targetSpec
+		g.Targets[repo.GetTargetKey(target.Name)] = &spec.Target{Name: target.Name, Repository: repo, Spec: targetSpec}
+		if err := os.MkdirAll(filepath.Join(repo.Local.Path, target.Name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(repo.Local.Path, "lib", "lib.txt"), []byte("lib content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tester, err := New(g, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tester, logger
+}
+
+// Get the targets of the names
+func getTestTargets(tester *Tester, names ...string) []*spec.Target {
+	var targets []*spec.Target
+	for _, name := range names {
+		targets = append(targets, tester.graph.Targets[spec.GetTargetKey(name, tester.graph.Repositories[testRepository])])
+	}
+	return targets
+}
+
+func TestGetTestTargets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tester, _ := newTestTester(t, dir, TesterOptions{})
+	all := getTestTargets(tester, "lib", "app", "env", "fail")
+	for _, tCase := range []struct {
+		Filter  string
+		Targets []string
+	}{
+		{Targets: []string{"app", "env", "fail"}},
+		{Filter: ":[af]", Targets: []string{"app", "fail"}},
+		{Filter: ":(lib|env)$", Targets: []string{"env"}},
+		{Filter: "none"},
+	} {
+		tester.Options.Filter = nil
+		if tCase.Filter != "" {
+			tester.Options.Filter = regexp.MustCompile(tCase.Filter)
+		}
+		var names []string
+		for _, target := range tester.GetTestTargets(all) {
+			names = append(names, target.Name)
+		}
+		if !reflect.DeepEqual(names, tCase.Targets) {
+			t.Errorf("Incorrect test targets of filter [%s]. Expect %v Actual %v", tCase.Filter, tCase.Targets, names)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tester, _ := newTestTester(t, dir, TesterOptions{Jobs: 3})
+	var names []string
+	for _, tCase := range runCases {
+		names = append(names, tCase.Target)
+	}
+	results := tester.Run(getTestTargets(tester, names...))
+	if len(results) != len(runCases) {
+		t.Fatalf("Incorrect number of results. Expect %d Actual %d", len(runCases), len(results))
+	}
+	for i, tCase := range runCases {
+		result := results[i]
+		if result.Target != testRepository+":"+tCase.Target || result.Status != tCase.Status || !strings.Contains(result.Error, tCase.Error) {
+			t.Errorf("Incorrect result of target [%s]. Expect [%s] [%s] Actual %+v", tCase.Target, tCase.Status, tCase.Error, result)
+			continue
+		}
+		if result.Fingerprint == "" || result.Time.IsZero() || result.Duration <= 0 {
+			t.Errorf("Incorrect result of target [%s]. Actual %+v", tCase.Target, result)
+		}
+		data, err := ioutil.ReadFile(result.LogFile)
+		if err != nil {
+			t.Errorf("Failed to read the log file of target [%s], error: %s", tCase.Target, err)
+		} else if !strings.Contains(string(data), tCase.Log) {
+			t.Errorf("Incorrect log of target [%s]. Expect [%s] Actual [%s]", tCase.Target, tCase.Log, data)
+		}
+	}
+}
+
+func TestRunCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tester, _ := newTestTester(t, dir, TesterOptions{})
+	targets := getTestTargets(tester, "app", "fail")
+	run := func(name string, expect ...string) []*TestResult {
+		results := tester.Run(targets)
+		for i, result := range results {
+			if result.Status != expect[i] {
+				t.Errorf("Incorrect status of target [%s] of case [%s]. Expect [%s] Actual [%s]", result.Target, name, expect[i], result.Status)
+			}
+		}
+		return results
+	}
+	first := run("first", StatusPassed, StatusFailed)
+	// The passed test is cached, the failed test is run again
+	cached := run("unchanged", StatusCached, StatusFailed)
+	if cached[0].Fingerprint != first[0].Fingerprint || !cached[0].Time.Equal(first[0].Time) {
+		t.Errorf("Incorrect cached result. Expect %+v Actual %+v", first[0], cached[0])
+	}
+	tester.Options.NoCache = true
+	run("no cache", StatusPassed, StatusFailed)
+	tester.Options.NoCache = false
+	// The dependency is changed
+	if err := ioutil.WriteFile(filepath.Join(dir, "repo", "lib", "lib.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed := run("dependency changed", StatusPassed, StatusFailed)
+	if changed[0].Fingerprint == first[0].Fingerprint {
+		t.Errorf("Incorrect fingerprint of the changed dependency. Actual [%s]", changed[0].Fingerprint)
+	}
+	run("cached after changed", StatusCached, StatusFailed)
+	// The test spec is changed
+	targets[0].Spec.Test.Envs = []string{"CHANGED=1"}
+	run("spec changed", StatusPassed, StatusFailed)
+}
+
+func TestRunCanceled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tester, _ := newTestTester(t, dir, TesterOptions{})
+	tester.graph.Workspace().Cancel()
+	for _, result := range tester.Run(getTestTargets(tester, "app", "env")) {
+		if result.Status != StatusFailed || result.Error != (&workspace.CanceledError{}).Error() || result.LogFile != "" {
+			t.Errorf("Incorrect result of the canceled target [%s]. Actual %+v", result.Target, result)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tester-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tester, _ := newTestTester(t, dir, TesterOptions{})
+	targets := getTestTargets(tester, "lib", "app", "env")
+	fingerprints := make(map[string]string)
+	for _, target := range targets {
+		fingerprint, err := tester.Fingerprint(target)
+		if err != nil {
+			t.Fatalf("Failed to get fingerprint of target [%s], error: %s", target.Name, err)
+		}
+		if again, _ := tester.Fingerprint(target); len(fingerprint) != 64 || again != fingerprint {
+			t.Errorf("Incorrect fingerprint of target [%s]. Expect [%s] Actual [%s]", target.Name, fingerprint, again)
+		}
+		fingerprints[target.Name] = fingerprint
+	}
+	// The file not in the target or its dependencies
+	if err := ioutil.WriteFile(filepath.Join(dir, "repo", "env", "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if fingerprint, _ := tester.Fingerprint(targets[1]); fingerprint != fingerprints["app"] {
+		t.Errorf("Incorrect fingerprint of target [app] after the other target changed. Expect [%s] Actual [%s]", fingerprints["app"], fingerprint)
+	}
+	// The file of the dependency
+	if err := ioutil.WriteFile(filepath.Join(dir, "repo", "lib", "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range targets[:2] {
+		if fingerprint, _ := tester.Fingerprint(target); fingerprint == fingerprints[target.Name] {
+			t.Errorf("Incorrect fingerprint of target [%s] after the dependency changed. Actual [%s]", target.Name, fingerprint)
+		}
+	}
+}
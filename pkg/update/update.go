@@ -0,0 +1,463 @@
+// Author: lipixun
+// Created Time : 六 10/17 21:12:40 2026
+//
+// File Name: update.go
+// Description:
+//	The self update of the op executable
+//
+//	The releases are resolved from the endpoint (config update.endpoint) of the channel (stable or edge):
+//		https://github.com/<owner>/<repo> 	The github releases, the latest release for stable and the latest release
+//											including the pre-releases for edge. The binary is the asset op-<os>-<arch>,
+//											its sha256 is listed in the asset SHA256SUMS and its signature is the asset
+//											op-<os>-<arch>.sig
+//		Any other url 						The release manifest <endpoint>/<channel>.json:
+//			{
+//				"version": "v1.2.0",
+//				"binaries": {
+//					"linux-amd64": {"url": "op-linux-amd64", "sha256": "<hex>", "signature": "<base64>"}
+//				}
+//			}
+//											The binary url is relative to the manifest url
+//
+//	The binary is downloaded by the fetcher (so the credentials and proxy apply) and verified by the sha256 and the
+//	ed25519 signature of the public key (config update.publickey or PublicKey). Without a public key the download
+//	fails unless the updater is insecure (op self-update --insecure). Then it replaces the running executable by a
+//	rename in the same directory, which is atomic.
+//
+//	The config update.endpoint and update.publickey are ignored in the project config, and the urls are rewritten
+//	by the global and user rewrite rules only (see workspace/rewrite.go), a checked out repository shouldn't decide
+//	where op comes from.
+package update
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/fetcher"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LogHeader = "Update"
+
+	ChannelStable = "stable"
+	ChannelEdge   = "edge"
+
+	BinaryPrefix       = "op-"
+	ChecksumsAssetName = "SHA256SUMS"
+	SignatureSuffix    = ".sig"
+
+	RequestTimeout = 30 * time.Second
+	MaxMetaSize    = 4 << 20 // The max size of the release list, manifest and signature
+)
+
+var (
+	// The base64 ed25519 public key to verify the binaries, set at build time by the makefile (UpdatePublicKey)
+	// The config update.publickey overwrites it
+	PublicKey string
+)
+
+// A release of the current platform
+type Release struct {
+	Version   string // The version (tag), e.g. v1.2.0
+	Channel   string // The channel, one of Channel*
+	URL       string // The binary url
+	Checksum  string // The sha256 (hex) of the binary
+	Signature []byte // The ed25519 signature of the binary, nil if not provided
+}
+
+// The release manifest
+type manifest struct {
+	Version  string                     `json:"version"`
+	Binaries map[string]*manifestBinary `json:"binaries"` // Keyed by <os>-<arch>
+}
+
+type manifestBinary struct {
+	URL       string `json:"url"`
+	Sha256    string `json:"sha256"`
+	Signature string `json:"signature"` // The base64 signature
+}
+
+// The github release
+type githubRelease struct {
+	TagName    string         `json:"tag_name"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []*githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// The updater
+type Updater struct {
+	// Download the binary without verifying the signature if there is no public key, the sha256 is still verified
+	Insecure bool
+
+	ws       *workspace.Workspace
+	logger   log.Logger
+	endpoint string
+}
+
+// Create a new Updater of the endpoint in config
+func New(ws *workspace.Workspace) (*Updater, error) {
+	if ws == nil {
+		return nil, errors.New("Require workspace")
+	}
+	endpoint := strings.TrimRight(ws.GetUserConfigString(workspace.ConfigKeyUpdateEndpoint), "/")
+	if endpoint == "" {
+		return nil, errors.New(fmt.Sprintf("Require the release endpoint, see config %s", workspace.ConfigKeyUpdateEndpoint))
+	}
+	return &Updater{ws: ws, logger: ws.Logger.GetLoggerWithHeader(LogHeader), endpoint: endpoint}, nil
+}
+
+// Get the platform name of the binaries, e.g. linux-amd64
+func Platform() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// Get the latest release of the channel
+func (this *Updater) GetLatestRelease(channel string) (*Release, error) {
+	if channel != ChannelStable && channel != ChannelEdge {
+		return nil, errors.New(fmt.Sprintf("Unknown channel [%s], should be one of %s, %s", channel, ChannelStable, ChannelEdge))
+	}
+	if this.ws.Offline {
+		return nil, &workspace.OfflineError{Resource: this.endpoint}
+	}
+	if owner, repo, ok := parseGithubEndpoint(this.endpoint); ok {
+		return this.getGithubRelease(owner, repo, channel)
+	}
+	return this.getManifestRelease(channel)
+}
+
+// Download and verify the binary of the release
+// Returns:
+//
+//	The local path of the binary (in the fetch cache), should not be modified
+func (this *Updater) Download(release *Release) (string, error) {
+	f, err := fetcher.New(this.ws)
+	if err != nil {
+		return "", err
+	}
+	f.UserRewrites = true
+	// The fetcher verifies the sha256
+	path, err := f.Fetch(release.URL, release.Checksum)
+	if err != nil {
+		return "", err
+	}
+	// Verify the signature
+	publicKey := this.ws.GetUserConfigString(workspace.ConfigKeyUpdatePublicKey)
+	if publicKey == "" {
+		publicKey = PublicKey
+	}
+	if publicKey == "" {
+		if !this.Insecure {
+			return "", errors.New(fmt.Sprintf("No public key to verify the signature of [%s], set config %s or skip the verification by --insecure", release.URL, workspace.ConfigKeyUpdatePublicKey))
+		}
+		this.logger.LeveledPrintf(log.LevelWarn, "The signature of [%s] is not verified since no public key (insecure)\n", release.URL)
+		return path, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return "", errors.New(fmt.Sprintf("Invalid ed25519 public key [%s]", publicKey))
+	}
+	if release.Signature == nil {
+		return "", errors.New(fmt.Sprintf("Release [%s] has no signature of [%s]", release.Version, release.URL))
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, release.Signature) {
+		return "", errors.New(fmt.Sprintf("Signature mismatch of [%s], the binary is not signed by the public key", release.URL))
+	}
+	// Done
+	return path, nil
+}
+
+// Get the real path of the executable (the running executable if empty) replaced by the update
+func GetExecutable(executable string) (string, error) {
+	if executable == "" {
+		path, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+		executable = path
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+// Replace the executable by the binary atomically
+// Parameters:
+//
+//	executable 	The executable path, the running executable if empty
+func Replace(executable, binary string) error {
+	executable, err := GetExecutable(executable)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	// Copy to the same directory then rename, so the executable is either the old one or the new one
+	source, err := os.Open(binary)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	target, err := ioutil.TempFile(filepath.Dir(executable), "."+filepath.Base(executable)+".update.")
+	if err != nil {
+		if os.IsPermission(err) {
+			return errors.New(fmt.Sprintf("No permission to write the directory of [%s], run as the owner of the executable", executable))
+		}
+		return err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		os.Remove(target.Name())
+		return err
+	}
+	if err := target.Sync(); err != nil {
+		target.Close()
+		os.Remove(target.Name())
+		return err
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(target.Name())
+		return err
+	}
+	if err := os.Chmod(target.Name(), info.Mode().Perm()|0111); err != nil {
+		os.Remove(target.Name())
+		return err
+	}
+	if err := os.Rename(target.Name(), executable); err != nil {
+		os.Remove(target.Name())
+		return err
+	}
+	// Done
+	return nil
+}
+
+// Compare the versions (e.g. v1.2.0, 1.3.0-rc.1), the numeric segments are compared by number and a version with the
+// pre-release suffix is older than the one without
+// Returns:
+//
+//	-1 if a < b, 0 if a == b, 1 if a > b
+func CompareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+	if c := compareVersionParts(aCore, bCore); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareVersionParts(aPre, bPre)
+}
+
+// Split the version into the core and the pre-release suffix
+func splitVersion(version string) (string, string) {
+	if idx := strings.IndexAny(version, "-+"); idx >= 0 {
+		return version[:idx], version[idx+1:]
+	}
+	return version, ""
+}
+
+// Compare the dot separated parts, by number if both are numbers, by string otherwise. The missing parts are 0
+func compareVersionParts(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		x, y := "0", "0"
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		m, errX := strconv.Atoi(x)
+		n, errY := strconv.Atoi(y)
+		var c int
+		if errX == nil && errY == nil {
+			if m < n {
+				c = -1
+			} else if m > n {
+				c = 1
+			}
+		} else {
+			c = strings.Compare(x, y)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Get the owner and repository of the github endpoint
+func parseGithubEndpoint(endpoint string) (string, string, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case u.Host == "github.com" && len(parts) == 2:
+		return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+	case u.Host == "api.github.com" && len(parts) == 3 && parts[0] == "repos":
+		return parts[1], parts[2], true
+	}
+	return "", "", false
+}
+
+// Get the latest github release of the channel
+func (this *Updater) getGithubRelease(owner, repo, channel string) (*Release, error) {
+	var releases []*githubRelease
+	if err := this.getJSON(fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=30", owner, repo), &releases); err != nil {
+		return nil, err
+	}
+	// The releases are in the order of the creation time, the newest first
+	for _, r := range releases {
+		if r.Draft || r.Prerelease && channel != ChannelEdge {
+			continue
+		}
+		assets := make(map[string]string)
+		for _, asset := range r.Assets {
+			assets[asset.Name] = asset.URL
+		}
+		name := BinaryPrefix + Platform()
+		if assets[name] == "" {
+			return nil, errors.New(fmt.Sprintf("Release [%s] has no binary [%s] of the platform", r.TagName, name))
+		}
+		if assets[ChecksumsAssetName] == "" {
+			return nil, errors.New(fmt.Sprintf("Release [%s] has no %s to verify the binary", r.TagName, ChecksumsAssetName))
+		}
+		checksums, err := this.getBytes(assets[ChecksumsAssetName])
+		if err != nil {
+			return nil, err
+		}
+		release := &Release{Version: r.TagName, Channel: channel, URL: assets[name]}
+		for _, line := range strings.Split(string(checksums), "\n") {
+			// The sha256sum format: <hex> [*]<name>
+			if fields := strings.Fields(line); len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+				release.Checksum = fields[0]
+			}
+		}
+		if release.Checksum == "" {
+			return nil, errors.New(fmt.Sprintf("Release [%s] has no checksum of [%s] in %s", r.TagName, name, ChecksumsAssetName))
+		}
+		if signatureURL := assets[name+SignatureSuffix]; signatureURL != "" {
+			if release.Signature, err = this.getSignature(signatureURL); err != nil {
+				return nil, err
+			}
+		}
+		return release, nil
+	}
+	return nil, errors.New(fmt.Sprintf("No %s release of [%s/%s]", channel, owner, repo))
+}
+
+// Get the release by the manifest of the channel
+func (this *Updater) getManifestRelease(channel string) (*Release, error) {
+	manifestURL := fmt.Sprintf("%s/%s.json", this.endpoint, channel)
+	var m manifest
+	if err := this.getJSON(manifestURL, &m); err != nil {
+		return nil, err
+	}
+	if m.Version == "" {
+		return nil, errors.New(fmt.Sprintf("Release manifest [%s] requires version", manifestURL))
+	}
+	binary := m.Binaries[Platform()]
+	if binary == nil || binary.URL == "" {
+		return nil, errors.New(fmt.Sprintf("Release [%s] has no binary of the platform [%s]", m.Version, Platform()))
+	}
+	if binary.Sha256 == "" {
+		return nil, errors.New(fmt.Sprintf("Release [%s] requires the sha256 of the binary", m.Version))
+	}
+	base, _ := url.Parse(manifestURL)
+	u, err := base.Parse(binary.URL)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid binary url [%s], error: %s", binary.URL, err))
+	}
+	release := &Release{Version: m.Version, Channel: channel, URL: u.String(), Checksum: binary.Sha256}
+	if binary.Signature != "" {
+		if release.Signature, err = base64.StdEncoding.DecodeString(binary.Signature); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid signature of release [%s], error: %s", m.Version, err))
+		}
+	}
+	// Done
+	return release, nil
+}
+
+// Get the signature, either raw (64 bytes) or base64
+func (this *Updater) getSignature(rawurl string) ([]byte, error) {
+	data, err := this.getBytes(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == ed25519.SignatureSize {
+		return data, nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid signature [%s], error: %s", rawurl, err))
+	}
+	return signature, nil
+}
+
+func (this *Updater) getJSON(rawurl string, value interface{}) error {
+	data, err := this.getBytes(rawurl)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return errors.New(fmt.Sprintf("Failed to parse [%s], error: %s", rawurl, err))
+	}
+	return nil
+}
+
+// Get the content of the url, authorized by the workspace credential of the host
+func (this *Updater) getBytes(rawurl string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(this.ws.Context(), http.MethodGet, this.ws.UserRewrite(rawurl), nil)
+	if err != nil {
+		return nil, err
+	}
+	if credential, err := this.ws.GetCredential(request.URL.Host); err != nil {
+		this.logger.LeveledPrintf(log.LevelWarn, "Failed to get credential of [%s], error: %s\n", request.URL.Host, err)
+	} else if credential != nil {
+		if credential.Username == "" {
+			request.Header.Set("Authorization", "Bearer "+credential.Secret)
+		} else {
+			request.SetBasicAuth(credential.Username, credential.Secret)
+		}
+	}
+	response, err := this.ws.HTTPClient(RequestTimeout).Do(request)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to get [%s], error: %s", rawurl, err))
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("Failed to get [%s]: %s", rawurl, response.Status))
+	}
+	return ioutil.ReadAll(io.LimitReader(response.Body, MaxMetaSize))
+}
@@ -0,0 +1,324 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:20:14 2026
+//
+// File Name: update_test.go
+// Description:
+//
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/workspace"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	compareVersionsCases = []struct {
+		A      string
+		B      string
+		Expect int
+	}{
+		{A: "v1.2.0", B: "1.2.0", Expect: 0},
+		{A: "v1.2.0", B: "v1.10.0", Expect: -1},
+		{A: "v2.0.0", B: "v1.99.99", Expect: 1},
+		{A: "v1.2", B: "v1.2.0", Expect: 0},
+		{A: "v1.2.1", B: "v1.2", Expect: 1},
+		{A: "v1.3.0-rc.1", B: "v1.3.0", Expect: -1},
+		{A: "v1.3.0", B: "v1.3.0-rc.1", Expect: 1},
+		{A: "v1.3.0-rc.2", B: "v1.3.0-rc.10", Expect: -1},
+		{A: "v1.3.0-beta", B: "v1.3.0-alpha", Expect: 1},
+		{A: "v1.3.0-rc.1", B: "v1.2.9", Expect: 1},
+	}
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, tCase := range compareVersionsCases {
+		if c := CompareVersions(tCase.A, tCase.B); c != tCase.Expect {
+			t.Errorf("Incorrect comparison of [%s] and [%s]. Expect %d Actual %d", tCase.A, tCase.B, tCase.Expect, c)
+		}
+	}
+}
+
+// The test release server, serves the manifest of each channel and the binaries by path
+type testServer struct {
+	*httptest.Server
+	manifests map[string]*manifest
+	binaries  map[string][]byte
+}
+
+func newTestServer() *testServer {
+	server := &testServer{manifests: make(map[string]*manifest), binaries: make(map[string][]byte)}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if m := server.manifests[strings.TrimSuffix(name, ".json")]; m != nil && strings.HasSuffix(name, ".json") {
+			json.NewEncoder(w).Encode(m)
+		} else if data, ok := server.binaries[name]; ok {
+			w.Write(data)
+		} else {
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+// Create the workspace of temporary directories with the user config
+func newTestWorkspace(t *testing.T, userConfig, projectConfig map[string]string) (*workspace.Workspace, *log.CaptureLogger, func()) {
+	dir, err := ioutil.TempDir("", "update-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := workspace.NewWorkspaceOptions()
+	options.Dir.GlobalPath = filepath.Join(dir, "global")
+	options.Dir.UserPath = filepath.Join(dir, "user")
+	options.Dir.ProjectPath = filepath.Join(dir, "project")
+	options.LogFile = false
+	options.EnableColor = false
+	for _, path := range []string{options.Dir.GlobalPath, options.Dir.UserPath, options.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := workspace.New(options, logger)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	for name, values := range map[string]map[string]string{workspace.ConfigLayerUser: userConfig, workspace.ConfigLayerProject: projectConfig} {
+		layer := ws.Config.Layer(name)
+		if layer == nil {
+			os.RemoveAll(dir)
+			t.Fatalf("No config layer [%s]", name)
+		}
+		for key, value := range values {
+			layer.Values[key] = value
+		}
+	}
+	return ws, logger, func() { os.RemoveAll(dir) }
+}
+
+func TestDownload(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+	binary := []byte("#!/bin/sh\necho op\n")
+	checksum := sha256.Sum256(binary)
+	server := newTestServer()
+	defer server.Close()
+
+	for _, tCase := range []struct {
+		Name          string
+		UserConfig    map[string]string
+		ProjectConfig map[string]string
+		Insecure      bool
+		Binary        []byte // The served binary, the signed binary if nil
+		Checksum      string // The declared sha256, the sha256 of the signed binary if empty, of the served binary if "-"
+		Signature     string // The base64 signature, signed by the private key if empty, none if "-"
+		Error         string // The expected error, success if empty
+		Warning       string // The expected warning
+	}{
+		{Name: "valid", UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey}},
+		{
+			Name:       "tampered binary",
+			UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey},
+			Binary:     append([]byte("#!/bin/sh\nrm -rf ~\n"), binary...),
+			Checksum:   "-",
+			Error:      "Signature mismatch",
+		},
+		{
+			Name:       "tampered signature",
+			UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey},
+			Signature:  base64.StdEncoding.EncodeToString(ed25519.Sign(otherKey, binary)),
+			Error:      "Signature mismatch",
+		},
+		{
+			Name:       "checksum mismatch",
+			UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey},
+			Binary:     []byte("tampered"),
+			Error:      "Checksum mismatch",
+		},
+		{
+			Name:       "missing signature",
+			UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey},
+			Signature:  "-",
+			Error:      "has no signature",
+		},
+		{
+			Name:       "missing signature insecure",
+			UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey},
+			Insecure:   true,
+			Signature:  "-",
+			Error:      "has no signature",
+		},
+		{
+			Name:       "invalid public key",
+			UserConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: "abcd"},
+			Error:      "Invalid ed25519 public key",
+		},
+		{Name: "no public key", Error: "No public key"},
+		{Name: "no public key insecure", Insecure: true, Warning: "is not verified"},
+		{
+			Name:          "project public key",
+			ProjectConfig: map[string]string{workspace.ConfigKeyUpdatePublicKey: encodedKey},
+			Error:         "No public key",
+			Warning:       "Ignore " + workspace.ConfigKeyUpdatePublicKey + " in the project config",
+		},
+	} {
+		// Each case has its own binary url since the fetcher caches by url
+		name := strings.Replace(tCase.Name, " ", "-", -1)
+		served := tCase.Binary
+		if served == nil {
+			served = binary
+		}
+		server.binaries[name] = served
+		release := &Release{Version: "v1.2.0", Channel: ChannelStable, URL: server.URL + "/" + name, Checksum: hex.EncodeToString(checksum[:])}
+		if tCase.Checksum == "-" {
+			sum := sha256.Sum256(served)
+			release.Checksum = hex.EncodeToString(sum[:])
+		}
+		switch tCase.Signature {
+		case "":
+			release.Signature = ed25519.Sign(privateKey, binary)
+		case "-":
+		default:
+			release.Signature, _ = base64.StdEncoding.DecodeString(tCase.Signature)
+		}
+		func() {
+			userConfig := map[string]string{workspace.ConfigKeyUpdateEndpoint: server.URL}
+			for key, value := range tCase.UserConfig {
+				userConfig[key] = value
+			}
+			ws, logger, cleanup := newTestWorkspace(t, userConfig, tCase.ProjectConfig)
+			defer cleanup()
+			updater, err := New(ws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			updater.Insecure = tCase.Insecure
+			path, err := updater.Download(release)
+			if tCase.Error == "" {
+				if err != nil {
+					t.Errorf("Failed to download case [%s], error: %s", tCase.Name, err)
+				} else if data, _ := ioutil.ReadFile(path); string(data) != string(served) {
+					t.Errorf("Incorrect binary of case [%s]. Expect [%s] Actual [%s]", tCase.Name, served, data)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+			if tCase.Warning != "" && !logger.Contains(log.LevelWarn, tCase.Warning) {
+				t.Errorf("No warning [%s] of case [%s]. Actual %v", tCase.Warning, tCase.Name, logger.Entries())
+			}
+		}()
+	}
+}
+
+func TestGetManifestRelease(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	server.manifests[ChannelStable] = &manifest{
+		Version: "v1.2.0",
+		Binaries: map[string]*manifestBinary{
+			Platform(): {URL: "bin/op", Sha256: "abcd", Signature: base64.StdEncoding.EncodeToString([]byte("signature"))},
+		},
+	}
+	server.manifests[ChannelEdge] = &manifest{
+		Version:  "v1.3.0-rc.1",
+		Binaries: map[string]*manifestBinary{Platform(): {URL: "bin/op-edge", Sha256: "abcd", Signature: "!"}},
+	}
+	ws, _, cleanup := newTestWorkspace(t, map[string]string{workspace.ConfigKeyUpdateEndpoint: server.URL + "/"}, nil)
+	defer cleanup()
+	updater, err := New(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := updater.GetLatestRelease(ChannelStable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version != "v1.2.0" || release.URL != server.URL+"/bin/op" || release.Checksum != "abcd" || string(release.Signature) != "signature" {
+		t.Errorf("Incorrect release %+v", release)
+	}
+	if _, err := updater.GetLatestRelease(ChannelEdge); err == nil || !strings.Contains(err.Error(), "Invalid signature") {
+		t.Errorf("Incorrect error of the invalid signature. Actual [%v]", err)
+	}
+	if _, err := updater.GetLatestRelease("nightly"); err == nil || !strings.Contains(err.Error(), "Unknown channel") {
+		t.Errorf("Incorrect error of the unknown channel. Actual [%v]", err)
+	}
+}
+
+func TestProjectRewrites(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := []byte("#!/bin/sh\necho op\n")
+	checksum := sha256.Sum256(binary)
+	newServer := func(version string) *testServer {
+		server := newTestServer()
+		server.manifests[ChannelStable] = &manifest{
+			Version: version,
+			Binaries: map[string]*manifestBinary{Platform(): {
+				URL:       "bin/op",
+				Sha256:    hex.EncodeToString(checksum[:]),
+				Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, binary)),
+			}},
+		}
+		server.binaries["bin/op"] = binary
+		return server
+	}
+	server, evil := newServer("v1.2.0"), newServer("v6.6.6")
+	defer server.Close()
+	defer evil.Close()
+	evil.binaries["bin/op"] = []byte("#!/bin/sh\nrm -rf ~\n")
+	ws, _, cleanup := newTestWorkspace(t, map[string]string{
+		workspace.ConfigKeyUpdateEndpoint:  "http://releases.example",
+		workspace.ConfigKeyUpdatePublicKey: base64.StdEncoding.EncodeToString(publicKey),
+	}, nil)
+	defer cleanup()
+	// The user rules apply, the project rules are ignored
+	rewrites := map[string]string{
+		filepath.Join(ws.Dir.User.RootPath(), workspace.RewriteFileName):           "releases.example: " + server.URL + "\n",
+		filepath.Join(ws.Dir.Project.RootPath(), workspace.ProjectRewriteFileName): "releases.example: " + evil.URL + "\n" + strings.TrimPrefix(server.URL, "http://") + ": " + evil.URL + "\n",
+	}
+	for path, content := range rewrites {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updater, err := New(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := updater.GetLatestRelease(ChannelStable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version != "v1.2.0" {
+		t.Errorf("Incorrect version of the release. Expect [v1.2.0] Actual [%s]", release.Version)
+	}
+	path, err := updater.Download(release)
+	if err != nil {
+		t.Fatalf("Failed to download, error: %s", err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != string(binary) {
+		t.Errorf("Incorrect binary. Expect [%s] Actual [%s]", binary, data)
+	}
+}
@@ -0,0 +1,133 @@
+// Author: lipixun
+// Created Time : 六 10/17 07:52:26 2026
+//
+// File Name: address.go
+// Description:
+//	The target address
+//
+//	The address of a target (or a sub path) in a repository:
+//		<url>[//<sub path>][:<target>][@<ref>|#<ref fragment>]
+//	e.g.
+//		github.com/org/repo//sub/path:target@v1.0.0
+//		git@github.com:org/repo.git//:target#commit=5ca58c2
+//		https://github.com/org/repo:target
+//
+//	The url, ref and ref fragment are the same as git url (see git.go). The target is in the last segment of the
+//	address, so a url without slash (e.g. host:repo) requires an empty sub path to specify the target (host:repo//:target)
+package uri
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	targetNameRegex = regexp.MustCompile("^" + TargetRegex + "$")
+)
+
+// The parsed target address
+type Address struct {
+	Repository *URI   // The repository with sub path and ref
+	Target     string // The target name, empty means the (sub path of) repository itself
+}
+
+// Parse the target address
+func ParseAddress(s string) (*Address, error) {
+	if s == "" {
+		return nil, errors.New("Empty address")
+	}
+	source := s
+	// Split the ref fragment, it's always the last part
+	var fragment string
+	if idx := strings.Index(s, "#"); idx != -1 {
+		s, fragment = s[:idx], s[idx:]
+	}
+	// Split the sub path
+	start := 0
+	if idx := strings.Index(s, "://"); idx != -1 {
+		start = idx + 3
+	}
+	var subPath string
+	hasSubPath := false
+	if idx := strings.Index(s[start:], "//"); idx > 0 {
+		s, subPath = s[:start+idx], s[start+idx+2:]
+		hasSubPath = true
+	}
+	// Split the target and the ref suffix from the last segment
+	var address Address
+	last, lastStart := &s, strings.LastIndex(s, "/")+1
+	if hasSubPath {
+		last, lastStart = &subPath, strings.LastIndex(subPath, "/")+1
+	}
+	if hasSubPath || lastStart > start {
+		segment := (*last)[lastStart:]
+		var ref string
+		if idx := strings.LastIndex(segment, "@"); idx != -1 && !isLocalAddress(s, start) {
+			segment, ref = segment[:idx], segment[idx:]
+		}
+		if idx := strings.Index(segment, ":"); idx != -1 {
+			segment, address.Target = segment[:idx], segment[idx+1:]
+			if !targetNameRegex.MatchString(address.Target) {
+				return nil, errors.New(fmt.Sprintf("Invalid target name [%s] of address [%s]", address.Target, source))
+			}
+		}
+		*last = (*last)[:lastStart] + segment
+		// Put the ref back to the url
+		s += ref
+	}
+	// Parse the url
+	u, err := parseURL(s + fragment)
+	if err != nil {
+		return nil, err
+	}
+	if hasSubPath {
+		if u.Path, err = cleanSubPath(subPath); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid sub path of address [%s], error: %s", source, err))
+		}
+	}
+	address.Repository = u
+	// Done
+	return &address, nil
+}
+
+// Check if the address (without fragment and sub path) is a local path
+func isLocalAddress(s string, start int) bool {
+	if start > 0 {
+		return strings.ToLower(s[:start]) == SchemeFile+"://"
+	}
+	return !scpLikeRegex.MatchString(s) && isLocalPath(s)
+}
+
+// Clean the sub path, returns the path without leading and tailing slashes
+func cleanSubPath(path string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			return "", errors.New("Parent directory is not allowed")
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// Format the address
+func (this *Address) String() string {
+	if this.Target == "" {
+		return this.Repository.String()
+	}
+	url, path := this.Repository.url(), this.Repository.formatPath()
+	rest := url
+	if idx := strings.Index(url, "://"); idx != -1 {
+		rest = url[idx+3:]
+	}
+	if path == "" && !strings.Contains(rest, "/") {
+		// No slash in the url (except the scheme), require an empty sub path
+		path = "//"
+	}
+	return fmt.Sprintf("%s%s:%s%s", url, path, this.Target, this.Repository.formatRef())
+}
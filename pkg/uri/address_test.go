@@ -0,0 +1,105 @@
+// Author: lipixun
+// Created Time : 六 10/17 08:10:37 2026
+//
+// File Name: address_test.go
+// Description:
+//
+package uri
+
+import (
+	"testing"
+)
+
+var (
+	addressCases = []struct {
+		Source    string
+		Stringify string
+		Good      bool
+		Uri       URI
+		Target    string
+	}{
+		{
+			Source:    "github.com/ops-openlight/openlight//pkg/uri:test@v1.2.3",
+			Stringify: "github.com/ops-openlight/openlight//pkg/uri:test@v1.2.3",
+			Good:      true,
+			Uri:       URI{Host: "github.com", Owner: "ops-openlight", Repo: "openlight", Path: "pkg/uri", Ref: "v1.2.3"},
+			Target:    "test",
+		},
+		{
+			Source:    "github.com/ops-openlight/openlight:server",
+			Stringify: "github.com/ops-openlight/openlight:server",
+			Good:      true,
+			Uri:       URI{Host: "github.com", Owner: "ops-openlight", Repo: "openlight"},
+			Target:    "server",
+		},
+		{
+			Source:    "https://github.com/ops-openlight/openlight.git//./pkg//uri/#tag=v1.0.0",
+			Stringify: "https://github.com/ops-openlight/openlight.git//pkg/uri#tag=v1.0.0",
+			Good:      true,
+			Uri:       URI{Scheme: SchemeHTTPS, Host: "github.com", Owner: "ops-openlight", Repo: "openlight", Path: "pkg/uri", Ref: "v1.0.0", RefType: RefTypeTag, DotGit: true},
+		},
+		{
+			Source:    "git@github.com:ops-openlight/openlight.git:server@develop",
+			Stringify: "git@github.com:ops-openlight/openlight.git:server@develop",
+			Good:      true,
+			Uri:       URI{Scheme: SchemeSSH, User: "git", Host: "github.com", Owner: "ops-openlight", Repo: "openlight", Ref: "develop", Shorthand: true, DotGit: true},
+			Target:    "server",
+		},
+		{
+			Source:    "server:repo//:target",
+			Stringify: "server:repo//:target",
+			Good:      true,
+			Uri:       URI{Scheme: SchemeSSH, Host: "server", Repo: "repo", Shorthand: true},
+			Target:    "target",
+		},
+		{
+			Source:    "/home/user/my@repo//sub:target#develop",
+			Stringify: "/home/user/my@repo//sub:target#develop",
+			Good:      true,
+			Uri:       URI{Owner: "/home/user", Repo: "my@repo", Path: "sub", Ref: "develop", RefType: RefTypeBranch},
+			Target:    "target",
+		},
+		{
+			Source: "github.com/ops-openlight/openlight//../etc",
+		},
+		{
+			Source: "github.com/ops-openlight/openlight:bad.name",
+		},
+		{
+			Source: "",
+		},
+	}
+)
+
+func TestAddress(t *testing.T) {
+	for _, tCase := range addressCases {
+		r, err := ParseAddress(tCase.Source)
+		if !tCase.Good {
+			if err == nil {
+				t.Errorf("Address [%s] should be a bad address", tCase.Source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to parse [%s], error: %s", tCase.Source, err)
+			continue
+		}
+		if *r.Repository != tCase.Uri || r.Target != tCase.Target {
+			t.Errorf("Incorrect result of [%s]. Expect [%#v] [%s] Actual [%#v] [%s]", tCase.Source, tCase.Uri, tCase.Target, *r.Repository, r.Target)
+			continue
+		}
+		// Test stringify
+		if r.String() != tCase.Stringify {
+			t.Errorf("Incorrect stringify result. Expect [%s] Actual [%s]", tCase.Stringify, r.String())
+			continue
+		}
+		// Test round trip
+		if rr, err := ParseAddress(r.String()); err != nil || *rr.Repository != *r.Repository || rr.Target != r.Target {
+			t.Errorf("Round trip of [%s] failed, error: %v", r.String(), err)
+		}
+	}
+	// The repository url does not allow target
+	if _, err := Parse("github.com/ops-openlight/openlight:server"); err == nil {
+		t.Errorf("Target should not be allowed in repository url")
+	}
+}
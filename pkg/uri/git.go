@@ -0,0 +1,273 @@
+// Author: lipixun
+// Created Time : 六 10/17 06:48:31 2026
+//
+// File Name: git.go
+// Description:
+//	The git url parser
+//
+//	The supported formats:
+//		https://[user[:password]@]host[:port]/owner/repo[.git]
+//		http://...
+//		ssh://[user@]host[:port]/owner/repo[.git]
+//		git://host[:port]/owner/repo[.git]
+//		[user@]host:owner/repo[.git] 		The scp-like ssh shorthand, no slash is allowed before the colon
+//		file:///path/to/repo
+//		/path/to/repo, ./repo, ~/repo 		The local path
+//		host/owner/repo 					The address without scheme, the first segment must contain a dot
+//
+//	The owner may contain slashes, e.g. the subgroups of gitlab: https://gitlab.com/group/subgroup/repo.git
+//
+//	The ref of the repository is specified by a fragment or a suffix of the repository name:
+//		<url>#<branch>
+//		<url>#tag=<tag>
+//		<url>#commit=<sha>
+//		<url>@<ref> 						A branch, tag or commit, resolved by the fetcher. Cannot contain slashes, not supported by local paths
+//
+//	The sub path in the repository is separated by a double slash, e.g. github.com/org/repo//sub/path@v1.0.0 (see address.go)
+package uri
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+	SchemeSSH   = "ssh"
+	SchemeGit   = "git"
+	SchemeFile  = "file"
+
+	GitSuffix = ".git"
+
+	RefTypeBranch = "branch"
+	RefTypeTag    = "tag"
+	RefTypeCommit = "commit"
+)
+
+var (
+	scpLikeRegex = regexp.MustCompile("^(?:([^@/]+)@)?([^@/:#]+):(.*)$")
+	refRegex     = regexp.MustCompile("^[a-zA-Z0-9._/+-]+$")
+	commitRegex  = regexp.MustCompile("^[0-9a-fA-F]{4,40}$")
+)
+
+// The parsed git url
+type URI struct {
+	Scheme    string // One of Scheme*, empty if not specified (a local path or an address like host/owner/repo)
+	User      string // The user info, e.g. git or user:password
+	Host      string // The host with port, empty for local paths
+	Owner     string // The path before the repository name, e.g. org or group/subgroup. Absolute for local paths
+	Repo      string // The repository name without the .git suffix
+	Path      string // The sub path in the repository
+	Ref       string // The branch, tag or commit
+	RefType   string // The type of the ref, one of RefType*, empty if resolved by the fetcher
+	Shorthand bool   // Whether in the scp-like ssh shorthand
+	DotGit    bool   // Whether the repository name has the .git suffix
+}
+
+// Parse the git url, the sub path and ref are allowed
+func Parse(s string) (*URI, error) {
+	address, err := ParseAddress(s)
+	if err != nil {
+		return nil, err
+	}
+	if address.Target != "" {
+		return nil, errors.New(fmt.Sprintf("Target is not allowed in repository url [%s]", s))
+	}
+	return address.Repository, nil
+}
+
+// Parse the git url without sub path
+func parseURL(s string) (*URI, error) {
+	if s == "" {
+		return nil, errors.New("Empty url")
+	}
+	var u URI
+	var path string
+	// Parse the fragment
+	if idx := strings.Index(s, "#"); idx != -1 {
+		if err := u.parseFragment(s[idx+1:]); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid ref of url [%s], error: %s", s, err))
+		}
+		s = s[:idx]
+	}
+	if idx := strings.Index(s, "://"); idx != -1 {
+		// A url with scheme
+		u.Scheme = strings.ToLower(s[:idx])
+		switch u.Scheme {
+		case SchemeHTTP, SchemeHTTPS, SchemeSSH, SchemeGit, SchemeFile:
+		default:
+			return nil, errors.New(fmt.Sprintf("Unsupported scheme [%s] of url [%s]", u.Scheme, s))
+		}
+		parsed, err := url.Parse(s)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid url [%s], error: %s", s, err))
+		}
+		if parsed.RawQuery != "" || parsed.Fragment != "" {
+			return nil, errors.New(fmt.Sprintf("Query or fragment is not supported in url [%s]", s))
+		}
+		if parsed.User != nil {
+			u.User = parsed.User.String()
+		}
+		u.Host = parsed.Host
+		path = parsed.Path
+		if u.Scheme == SchemeFile {
+			if u.Host != "" {
+				return nil, errors.New(fmt.Sprintf("Host is not supported in file url [%s]", s))
+			}
+		} else {
+			if u.Host == "" {
+				return nil, errors.New(fmt.Sprintf("Require host in url [%s]", s))
+			}
+			path = strings.TrimPrefix(path, "/")
+		}
+	} else if matches := scpLikeRegex.FindStringSubmatch(s); matches != nil {
+		// The scp-like ssh shorthand
+		u.Scheme = SchemeSSH
+		u.Shorthand = true
+		u.User, u.Host, path = matches[1], matches[2], matches[3]
+	} else if isLocalPath(s) {
+		path = s
+	} else {
+		// The address without scheme
+		idx := strings.Index(s, "/")
+		if idx == -1 {
+			return nil, errors.New(fmt.Sprintf("Require repository path in url [%s]", s))
+		}
+		u.Host, path = s[:idx], s[idx+1:]
+	}
+	// Split the path into owner and repository name
+	path = strings.TrimRight(path, "/")
+	if idx := strings.LastIndex(path, "/"); idx != -1 {
+		u.Owner, u.Repo = path[:idx], path[idx+1:]
+		if u.Owner == "" {
+			// The repository in root directory, e.g. /repo
+			u.Owner = "/"
+		}
+	} else {
+		u.Repo = path
+	}
+	if idx := strings.LastIndex(u.Repo, "@"); idx != -1 && !u.IsLocal() {
+		if u.Ref != "" {
+			return nil, errors.New(fmt.Sprintf("Cannot specify both fragment and @ref in url [%s]", s))
+		}
+		u.Ref = u.Repo[idx+1:]
+		u.Repo = u.Repo[:idx]
+		if !refRegex.MatchString(u.Ref) {
+			return nil, errors.New(fmt.Sprintf("Invalid ref [%s] of url [%s]", u.Ref, s))
+		}
+	}
+	if strings.HasSuffix(u.Repo, GitSuffix) {
+		u.Repo = strings.TrimSuffix(u.Repo, GitSuffix)
+		u.DotGit = true
+	}
+	if u.Repo == "" {
+		return nil, errors.New(fmt.Sprintf("Require repository name in url [%s]", s))
+	}
+	// Done
+	return &u, nil
+}
+
+// Parse the ref fragment
+func (this *URI) parseFragment(fragment string) error {
+	this.Ref, this.RefType = fragment, RefTypeBranch
+	if idx := strings.Index(fragment, "="); idx != -1 {
+		this.Ref, this.RefType = fragment[idx+1:], fragment[:idx]
+		switch this.RefType {
+		case RefTypeTag:
+		case RefTypeCommit:
+			if !commitRegex.MatchString(this.Ref) {
+				return errors.New(fmt.Sprintf("Invalid commit [%s]", this.Ref))
+			}
+		default:
+			return errors.New(fmt.Sprintf("Unknown ref type [%s]", this.RefType))
+		}
+	}
+	if !refRegex.MatchString(this.Ref) {
+		return errors.New(fmt.Sprintf("Invalid ref [%s]", this.Ref))
+	}
+	return nil
+}
+
+// Check if the url without scheme is a local path
+func isLocalPath(s string) bool {
+	if strings.HasPrefix(s, "/") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "~") {
+		return true
+	}
+	// An address must have a host name with dot
+	idx := strings.Index(s, "/")
+	return idx == -1 || !strings.Contains(s[:idx], ".")
+}
+
+// Whether the url is a local path
+func (this *URI) IsLocal() bool {
+	return this.Scheme == SchemeFile || (this.Scheme == "" && this.Host == "")
+}
+
+// Get the repository path, e.g. owner/repo.git
+func (this *URI) RepoPath() string {
+	name := this.Repo
+	if this.DotGit {
+		name += GitSuffix
+	}
+	if this.Owner == "" {
+		return name
+	} else if strings.HasSuffix(this.Owner, "/") {
+		return this.Owner + name
+	}
+	return fmt.Sprintf("%s/%s", this.Owner, name)
+}
+
+// Get the url of the repository to clone, without sub path and ref
+func (this *URI) Remote() string {
+	return this.url()
+}
+
+// Format the ref, e.g. #tag=v1.0.0
+func (this *URI) formatRef() string {
+	switch {
+	case this.Ref == "":
+		return ""
+	case this.RefType == "":
+		return "@" + this.Ref
+	case this.RefType == RefTypeBranch:
+		return "#" + this.Ref
+	default:
+		return fmt.Sprintf("#%s=%s", this.RefType, this.Ref)
+	}
+}
+
+// Format the git url
+func (this *URI) String() string {
+	return this.url() + this.formatPath() + this.formatRef()
+}
+
+// Format the sub path, e.g. //sub/path
+func (this *URI) formatPath() string {
+	if this.Path == "" {
+		return ""
+	}
+	return "//" + this.Path
+}
+
+func (this *URI) url() string {
+	var user string
+	if this.User != "" {
+		user = this.User + "@"
+	}
+	switch {
+	case this.Shorthand:
+		return fmt.Sprintf("%s%s:%s", user, this.Host, this.RepoPath())
+	case this.Scheme == SchemeFile:
+		return fmt.Sprintf("file://%s", this.RepoPath())
+	case this.Scheme != "":
+		return fmt.Sprintf("%s://%s%s/%s", this.Scheme, user, this.Host, strings.TrimPrefix(this.RepoPath(), "/"))
+	case this.Host != "":
+		return fmt.Sprintf("%s/%s", this.Host, this.RepoPath())
+	default:
+		return this.RepoPath()
+	}
+}
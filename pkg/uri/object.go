@@ -0,0 +1,88 @@
+// Author: lipixun
+// Created Time : 六 10/17 11:36:12 2026
+//
+// File Name: object.go
+// Description:
+//	The object storage url parser
+//
+//	The supported formats:
+//		s3://bucket/path/to/object 		Amazon S3 (or a S3 compatible storage, see the s3.endpoint config)
+//		gs://bucket/path/to/object 		Google Cloud Storage
+//
+//	The key of the object is not escaped, the trailing slash is kept (a prefix rather than an object)
+package uri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	SchemeS3  = "s3"
+	SchemeGCS = "gs"
+)
+
+// The parsed object storage url
+type ObjectURI struct {
+	Scheme string // One of SchemeS3, SchemeGCS
+	Bucket string // The bucket name
+	Key    string // The object key without the leading slash, empty means the bucket itself
+}
+
+// Check if the url is an object storage url
+func IsObjectURI(s string) bool {
+	idx := strings.Index(s, "://")
+	if idx == -1 {
+		return false
+	}
+	switch strings.ToLower(s[:idx]) {
+	case SchemeS3, SchemeGCS:
+		return true
+	}
+	return false
+}
+
+// Parse the object storage url
+func ParseObject(s string) (*ObjectURI, error) {
+	idx := strings.Index(s, "://")
+	if idx == -1 {
+		return nil, errors.New(fmt.Sprintf("Invalid object url [%s], require scheme", s))
+	}
+	u := &ObjectURI{Scheme: strings.ToLower(s[:idx])}
+	switch u.Scheme {
+	case SchemeS3, SchemeGCS:
+	default:
+		return nil, errors.New(fmt.Sprintf("Unsupported scheme [%s] of object url [%s]", u.Scheme, s))
+	}
+	rest := s[idx+3:]
+	if idx := strings.Index(rest, "/"); idx != -1 {
+		u.Bucket, u.Key = rest[:idx], rest[idx+1:]
+	} else {
+		u.Bucket = rest
+	}
+	if u.Bucket == "" {
+		return nil, errors.New(fmt.Sprintf("Invalid object url [%s], require bucket", s))
+	}
+	if strings.ContainsAny(u.Bucket, "@:?#") {
+		return nil, errors.New(fmt.Sprintf("Invalid bucket name [%s] of object url [%s]", u.Bucket, s))
+	}
+	// Done
+	return u, nil
+}
+
+// Join the object key with the path, e.g. s3://bucket/prefix/ + a/b => s3://bucket/prefix/a/b
+func (this *ObjectURI) Join(path string) *ObjectURI {
+	key := strings.TrimPrefix(path, "/")
+	if this.Key != "" {
+		key = strings.TrimSuffix(this.Key, "/") + "/" + key
+	}
+	return &ObjectURI{Scheme: this.Scheme, Bucket: this.Bucket, Key: key}
+}
+
+func (this *ObjectURI) String() string {
+	if this.Key == "" {
+		return fmt.Sprintf("%s://%s", this.Scheme, this.Bucket)
+	}
+	return fmt.Sprintf("%s://%s/%s", this.Scheme, this.Bucket, this.Key)
+}
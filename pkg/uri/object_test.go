@@ -0,0 +1,70 @@
+// Author: lipixun
+// Created Time : 六 10/17 11:48:20 2026
+//
+// File Name: object_test.go
+// Description:
+//
+package uri
+
+import (
+	"testing"
+)
+
+var (
+	objectUriCases = []struct {
+		Source string
+		Good   bool
+		Uri    ObjectURI
+	}{
+		{Source: "s3://my-bucket/toolchains/go1.9.tar.gz", Good: true, Uri: ObjectURI{Scheme: SchemeS3, Bucket: "my-bucket", Key: "toolchains/go1.9.tar.gz"}},
+		{Source: "GS://my-bucket/data/", Good: true, Uri: ObjectURI{Scheme: SchemeGCS, Bucket: "my-bucket", Key: "data/"}},
+		{Source: "s3://my-bucket", Good: true, Uri: ObjectURI{Scheme: SchemeS3, Bucket: "my-bucket"}},
+		{Source: "s3:///object"},
+		{Source: "s3://user@bucket/object"},
+		{Source: "https://bucket/object"},
+		{Source: "bucket/object"},
+	}
+)
+
+func TestParseObject(t *testing.T) {
+	for _, tCase := range objectUriCases {
+		u, err := ParseObject(tCase.Source)
+		if !tCase.Good {
+			if err == nil {
+				t.Errorf("Url [%s] should be a bad object url", tCase.Source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to parse [%s], error: %s", tCase.Source, err)
+			continue
+		}
+		if *u != tCase.Uri {
+			t.Errorf("Incorrect result of [%s]. Expect [%#v] Actual [%#v]", tCase.Source, tCase.Uri, *u)
+			continue
+		}
+		if uu, err := ParseObject(u.String()); err != nil || *uu != *u {
+			t.Errorf("Round trip of [%s] failed, error: %v", u.String(), err)
+		}
+	}
+}
+
+func TestIsObjectURI(t *testing.T) {
+	if !IsObjectURI("s3://bucket/object") || !IsObjectURI("gs://bucket") {
+		t.Errorf("Object url not recognized")
+	}
+	if IsObjectURI("https://bucket/object") || IsObjectURI("bucket/object") {
+		t.Errorf("Non object url recognized as object url")
+	}
+}
+
+func TestObjectJoin(t *testing.T) {
+	u := &ObjectURI{Scheme: SchemeS3, Bucket: "bucket", Key: "prefix/"}
+	if s := u.Join("/a/b").String(); s != "s3://bucket/prefix/a/b" {
+		t.Errorf("Incorrect join result [%s]", s)
+	}
+	u = &ObjectURI{Scheme: SchemeGCS, Bucket: "bucket"}
+	if s := u.Join("a").String(); s != "gs://bucket/a" {
+		t.Errorf("Incorrect join result [%s]", s)
+	}
+}
@@ -0,0 +1,105 @@
+// Author: lipixun
+// Created Time : 六 10/17 13:05:26 2026
+//
+// File Name: oci.go
+// Description:
+//	The oci registry url parser
+//
+//	The supported formats:
+//		oci://registry[:port]/repository[:tag] 				The tag is latest if neither tag nor digest is specified
+//		oci://registry[:port]/repository[:tag]@sha256:<hex> 	Pinned by the manifest digest, the tag is informational
+//
+//	The registry is required, e.g. oci://docker.io/library/alpine:3.6 for the official image of docker hub
+package uri
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	SchemeOCI = "oci"
+
+	OCIDefaultTag = "latest"
+)
+
+var (
+	ociRepositoryRegex = regexp.MustCompile("^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*)*$")
+	ociTagRegex        = regexp.MustCompile("^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$")
+	ociDigestRegex     = regexp.MustCompile("^sha256:[0-9a-f]{64}$")
+)
+
+// The parsed oci registry url
+type OCIURI struct {
+	Registry   string // The registry host (with port)
+	Repository string // The repository, e.g. library/alpine
+	Tag        string // The tag, empty if not specified
+	Digest     string // The manifest digest, e.g. sha256:<hex>, empty if not pinned
+}
+
+// Check if the url is an oci registry url
+func IsOCIURI(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), SchemeOCI+"://")
+}
+
+// Parse the oci registry url
+func ParseOCI(s string) (*OCIURI, error) {
+	if !IsOCIURI(s) {
+		return nil, errors.New(fmt.Sprintf("Invalid oci url [%s], require scheme %s://", s, SchemeOCI))
+	}
+	rest := s[len(SchemeOCI)+3:]
+	u := new(OCIURI)
+	if idx := strings.Index(rest, "@"); idx != -1 {
+		rest, u.Digest = rest[:idx], rest[idx+1:]
+		if !ociDigestRegex.MatchString(u.Digest) {
+			return nil, errors.New(fmt.Sprintf("Invalid digest [%s] of oci url [%s]", u.Digest, s))
+		}
+	}
+	idx := strings.Index(rest, "/")
+	if idx <= 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid oci url [%s], require registry and repository", s))
+	}
+	u.Registry, rest = strings.ToLower(rest[:idx]), rest[idx+1:]
+	// The tag is after the last colon which is not followed by a slash
+	if idx := strings.LastIndex(rest, ":"); idx != -1 && !strings.Contains(rest[idx:], "/") {
+		rest, u.Tag = rest[:idx], rest[idx+1:]
+		if !ociTagRegex.MatchString(u.Tag) {
+			return nil, errors.New(fmt.Sprintf("Invalid tag [%s] of oci url [%s]", u.Tag, s))
+		}
+	}
+	if !ociRepositoryRegex.MatchString(rest) {
+		return nil, errors.New(fmt.Sprintf("Invalid repository [%s] of oci url [%s]", rest, s))
+	}
+	u.Repository = rest
+	// Done
+	return u, nil
+}
+
+// Get the reference of the manifest, the digest if pinned, otherwise the tag
+func (this *OCIURI) Reference() string {
+	if this.Digest != "" {
+		return this.Digest
+	}
+	if this.Tag != "" {
+		return this.Tag
+	}
+	return OCIDefaultTag
+}
+
+// Get the url pinned by the digest
+func (this *OCIURI) WithDigest(digest string) *OCIURI {
+	return &OCIURI{Registry: this.Registry, Repository: this.Repository, Tag: this.Tag, Digest: digest}
+}
+
+func (this *OCIURI) String() string {
+	s := fmt.Sprintf("%s://%s/%s", SchemeOCI, this.Registry, this.Repository)
+	if this.Tag != "" {
+		s += ":" + this.Tag
+	}
+	if this.Digest != "" {
+		s += "@" + this.Digest
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+// Author: lipixun
+// Created Time : 六 10/17 13:18:47 2026
+//
+// File Name: oci_test.go
+// Description:
+//
+package uri
+
+import (
+	"testing"
+)
+
+const (
+	testDigest = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+)
+
+var (
+	ociUriCases = []struct {
+		Source    string
+		Good      bool
+		Uri       OCIURI
+		Reference string
+	}{
+		{
+			Source:    "oci://docker.io/library/alpine:3.6",
+			Good:      true,
+			Uri:       OCIURI{Registry: "docker.io", Repository: "library/alpine", Tag: "3.6"},
+			Reference: "3.6",
+		},
+		{
+			Source:    "oci://localhost:5000/team/rules",
+			Good:      true,
+			Uri:       OCIURI{Registry: "localhost:5000", Repository: "team/rules"},
+			Reference: OCIDefaultTag,
+		},
+		{
+			Source:    "OCI://ghcr.io/org/tool:v1@" + testDigest,
+			Good:      true,
+			Uri:       OCIURI{Registry: "ghcr.io", Repository: "org/tool", Tag: "v1", Digest: testDigest},
+			Reference: testDigest,
+		},
+		{
+			Source:    "oci://ghcr.io/org/tool@" + testDigest,
+			Good:      true,
+			Uri:       OCIURI{Registry: "ghcr.io", Repository: "org/tool", Digest: testDigest},
+			Reference: testDigest,
+		},
+		{Source: "oci://ghcr.io/org/tool@sha256:1234"},
+		{Source: "oci://ghcr.io/Org/tool"},
+		{Source: "oci://ghcr.io/org/tool:bad/tag"},
+		{Source: "oci://ghcr.io"},
+		{Source: "docker.io/library/alpine"},
+	}
+)
+
+func TestParseOCI(t *testing.T) {
+	for _, tCase := range ociUriCases {
+		u, err := ParseOCI(tCase.Source)
+		if !tCase.Good {
+			if err == nil {
+				t.Errorf("Url [%s] should be a bad oci url", tCase.Source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to parse [%s], error: %s", tCase.Source, err)
+			continue
+		}
+		if *u != tCase.Uri || u.Reference() != tCase.Reference {
+			t.Errorf("Incorrect result of [%s]. Expect [%#v] [%s] Actual [%#v] [%s]", tCase.Source, tCase.Uri, tCase.Reference, *u, u.Reference())
+			continue
+		}
+		if uu, err := ParseOCI(u.String()); err != nil || *uu != *u {
+			t.Errorf("Round trip of [%s] failed, error: %v", u.String(), err)
+		}
+	}
+}
+
+func TestOCIWithDigest(t *testing.T) {
+	u := &OCIURI{Registry: "ghcr.io", Repository: "org/tool", Tag: "v1"}
+	if s := u.WithDigest(testDigest).String(); s != "oci://ghcr.io/org/tool:v1@"+testDigest {
+		t.Errorf("Incorrect pinned url [%s]", s)
+	}
+}
@@ -0,0 +1,118 @@
+// Author: lipixun
+// Created Time : 六 10/17 14:22:51 2026
+//
+// File Name: rewrite.go
+// Description:
+//	The url rewrite (mirror) rules
+//
+//	A rule replaces the address prefix (host[/path], without scheme and user) of a url, e.g.
+//		github.com/org -> git.internal/mirror/org
+//	rewrites
+//		https://github.com/org/repo.git//sub#tag=v1 	=> https://git.internal/mirror/org/repo.git//sub#tag=v1
+//		git@github.com:org/repo.git 					=> git@git.internal:mirror/org/repo.git
+//		github.com/org/repo@v1 							=> git.internal/mirror/org/repo@v1
+//		oci://github.com/org/image:v1 					=> oci://git.internal/mirror/org/image:v1
+//
+//	The prefix only matches the whole path segments (github.com/org does not match github.com/organization, but
+//	github.com/org/repo matches github.com/org/repo.git). The scheme and user of the url are kept unless the
+//	replacement has a scheme (e.g. https://git.internal/mirror/org). The longest matched prefix wins. Local paths
+//	and file urls are never rewritten.
+package uri
+
+import (
+	"sort"
+	"strings"
+)
+
+// A rewrite rule
+type RewriteRule struct {
+	From string // The address prefix to match
+	To   string // The replacement, may have a scheme
+}
+
+// The rewrite rules, sorted by the length of the prefix from long to short
+type RewriteRules []RewriteRule
+
+// Create the rewrite rules, the key is the prefix and the value is the replacement
+func NewRewriteRules(rules map[string]string) RewriteRules {
+	var results RewriteRules
+	for from, to := range rules {
+		if idx := strings.Index(from, "://"); idx != -1 {
+			from = from[idx+3:]
+		}
+		from, to = strings.TrimRight(from, "/"), strings.TrimRight(to, "/")
+		if from == "" || to == "" {
+			continue
+		}
+		results = append(results, RewriteRule{From: from, To: to})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if len(results[i].From) != len(results[j].From) {
+			return len(results[i].From) > len(results[j].From)
+		}
+		return results[i].From < results[j].From
+	})
+	return results
+}
+
+// Rewrite the url
+// Returns:
+//
+//	The rewritten url and the matched rule, nil if not matched (the url is returned as is)
+func (this RewriteRules) Rewrite(s string) (string, *RewriteRule) {
+	if len(this) == 0 {
+		return s, nil
+	}
+	// Split the url into the prefix (scheme and user) and the address
+	var prefix, address string
+	var shorthand bool
+	if idx := strings.Index(s, "://"); idx != -1 {
+		if strings.ToLower(s[:idx]) == SchemeFile {
+			return s, nil
+		}
+		prefix, address = s[:idx+3], s[idx+3:]
+		if at := strings.Index(address, "@"); at != -1 && at < strings.IndexAny(address+"/", "/") {
+			prefix, address = prefix+address[:at+1], address[at+1:]
+		}
+	} else if matches := scpLikeRegex.FindStringSubmatch(s); matches != nil {
+		if matches[1] != "" {
+			prefix = matches[1] + "@"
+		}
+		address, shorthand = matches[2]+"/"+matches[3], true
+	} else if isLocalPath(s) {
+		return s, nil
+	} else {
+		address = s
+	}
+	// Find the rule
+	for i := range this {
+		rule := &this[i]
+		if !strings.HasPrefix(address, rule.From) {
+			continue
+		}
+		rest := address[len(rule.From):]
+		if !isRewriteBoundary(rest) && !(strings.HasPrefix(rest, GitSuffix) && isRewriteBoundary(rest[len(GitSuffix):])) {
+			// Not at the boundary of the path segment
+			continue
+		}
+		switch {
+		case strings.Contains(rule.To, "://"):
+			return rule.To + rest, rule
+		case shorthand:
+			// Back to the shorthand, the colon is after the host
+			address = rule.To + rest
+			if idx := strings.Index(address, "/"); idx != -1 {
+				return prefix + address[:idx] + ":" + address[idx+1:], rule
+			}
+			return prefix + address, rule
+		default:
+			return prefix + rule.To + rest, rule
+		}
+	}
+	return s, nil
+}
+
+// Check if the rest of the address after the prefix starts at a boundary of the path segment
+func isRewriteBoundary(rest string) bool {
+	return rest == "" || strings.IndexByte("/:@#?", rest[0]) != -1
+}
@@ -6,10 +6,6 @@
 //	Source code spec structure
 package uri
 
-import (
-	"strings"
-)
-
 const (
 	UriTypeKnown = "unknown"
 	UriTypePath  = "localPath"
@@ -18,20 +14,23 @@ const (
 	UriTypeSSH   = "ssh"
 )
 
+// Get the type of the git url, unknown if it's invalid
 func GetUriType(uri string) string {
-	uri = strings.ToLower(uri)
-	// Check the uri type
-	if strings.HasPrefix(uri, "http://") {
+	u, err := Parse(uri)
+	if err != nil {
+		return UriTypeKnown
+	}
+	switch u.Scheme {
+	case SchemeHTTP:
 		return UriTypeHttp
-	} else if strings.HasPrefix(uri, "https://") {
+	case SchemeHTTPS:
 		return UriTypeHttps
-	} else if strings.HasPrefix(uri, "ssh://") {
-		if strings.Index(uri, "@") != -1 {
-			return UriTypeSSH
-		} else {
-			return UriTypeKnown
+	case SchemeSSH:
+		return UriTypeSSH
+	case SchemeFile, "":
+		if u.IsLocal() {
+			return UriTypePath
 		}
-	} else {
-		return UriTypePath
 	}
+	return UriTypeKnown
 }
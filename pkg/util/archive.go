@@ -0,0 +1,210 @@
+// Author: lipixun
+// Created Time : 六 10/17 15:58:12 2026
+//
+// File Name: archive.go
+// Description:
+//	The archive extracting utility
+//
+//	The format is detected by the content (not the file name): zip, gzip compressed tar, zstd compressed tar (requires
+//	the zstd command, see zstd.go) or tar
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// Extract the archive file into the directory
+// The entries out of the directory are rejected: the absolute paths, .., the links point outside, and the entries
+// written through the extracted links resolved outside (e.g. m -> ., l -> m/.. then l/evil). An entry replaces the
+// extracted link of the same path instead of writing through it.
+func ExtractArchive(path string, dest string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return err
+	}
+	realDest, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(file)
+	magic, _ := reader.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		return extractZip(path, dest, realDest)
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		return extractTar(gzipReader, dest, realDest)
+	case bytes.HasPrefix(magic, zstdMagic):
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			pipeWriter.CloseWithError(ZstdDecompress(reader, pipeWriter))
+		}()
+		defer pipeReader.Close()
+		return extractTar(pipeReader, dest, realDest)
+	default:
+		return extractTar(reader, dest, realDest)
+	}
+}
+
+// Extract the tar stream into the directory (realDest is the directory with the links resolved)
+func extractTar(reader io.Reader, dest, realDest string) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		target, err := getArchiveEntryPath(dest, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkArchiveEntryPath(dest, realDest, target, hdr.Name); err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, os.ModePerm)
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeArchiveFile(target, tarReader, os.FileMode(hdr.Mode))
+		case tar.TypeSymlink:
+			err = writeArchiveLink(dest, target, hdr.Linkname)
+		default:
+			// Skip the other types (hard links, devices, pax headers)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Extract the zip file into the directory (realDest is the directory with the links resolved)
+func extractZip(path string, dest, realDest string) error {
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+	for _, f := range zipReader.File {
+		target, err := getArchiveEntryPath(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkArchiveEntryPath(dest, realDest, target, f.Name); err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		reader, err := f.Open()
+		if err != nil {
+			return err
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			// The content of a link is the link target
+			var link []byte
+			if link, err = io.ReadAll(reader); err == nil {
+				err = writeArchiveLink(dest, target, string(link))
+			}
+			reader.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = writeArchiveFile(target, reader, f.Mode())
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get the path of the archive entry in the directory
+func getArchiveEntryPath(dest, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	if filepath.IsAbs(name) || (target != dest && !strings.HasPrefix(target, dest+string(filepath.Separator))) {
+		return "", errors.New(fmt.Sprintf("Invalid archive entry [%s], out of the directory", name))
+	}
+	return target, nil
+}
+
+// Check the entry is written in the directory, the parent of the entry is resolved by the links already extracted
+// The extracted link of the entry path is removed, so the entry replaces the link instead of writing through it
+func checkArchiveEntryPath(dest, realDest, target, name string) error {
+	if target == dest {
+		return nil
+	}
+	// The deepest existing parent, the missing ones are created as directories
+	parent := filepath.Dir(target)
+	for {
+		if _, err := os.Lstat(parent); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		parent = filepath.Dir(parent)
+	}
+	realParent, err := filepath.EvalSymlinks(parent)
+	if err != nil || (realParent != realDest && !strings.HasPrefix(realParent, realDest+string(filepath.Separator))) {
+		return errors.New(fmt.Sprintf("Invalid archive entry [%s], out of the directory through the links", name))
+	}
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return os.Remove(target)
+	}
+	return nil
+}
+
+func writeArchiveFile(target string, reader io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeArchiveLink(dest, target, link string) error {
+	rel := strings.TrimPrefix(target[len(dest):], string(filepath.Separator))
+	if _, err := getArchiveEntryPath(dest, filepath.Join(filepath.Dir(rel), link)); err != nil || filepath.IsAbs(link) {
+		return errors.New(fmt.Sprintf("Invalid archive link [%s] -> [%s], out of the directory", target, link))
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
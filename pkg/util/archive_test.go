@@ -0,0 +1,111 @@
+// Author: lipixun
+// Created Time : 一 10/19 10:12:44 2026
+//
+// File Name: archive_test.go
+// Description:
+//
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// An archive entry, a link if Link is not empty, a directory if the name ends with /
+type archiveEntry struct {
+	Name string
+	Link string
+}
+
+var (
+	archiveCases = []struct {
+		Name    string
+		Zip     bool
+		Entries []archiveEntry
+		Good    bool
+	}{
+		{Name: "regular", Entries: []archiveEntry{{Name: "./"}, {Name: "a/"}, {Name: "a/b"}, {Name: "c", Link: "a/b"}, {Name: "d", Link: "a"}, {Name: "d/x"}}, Good: true},
+		{Name: "parent", Entries: []archiveEntry{{Name: "../evil"}}},
+		{Name: "nested parent", Entries: []archiveEntry{{Name: "a/../../evil"}}},
+		{Name: "absolute", Entries: []archiveEntry{{Name: "/evil"}}},
+		{Name: "link to parent", Entries: []archiveEntry{{Name: "l", Link: "../"}}},
+		{Name: "absolute link", Entries: []archiveEntry{{Name: "l", Link: "/tmp"}}},
+		{Name: "chained links", Entries: []archiveEntry{{Name: "m", Link: "."}, {Name: "l", Link: "m/.."}, {Name: "l/evil"}}},
+		{Name: "chained nested links", Entries: []archiveEntry{{Name: "a/"}, {Name: "a/m", Link: ".."}, {Name: "a/l", Link: "m/.."}, {Name: "a/l/evil"}}},
+		{Name: "replace link", Entries: []archiveEntry{{Name: "m", Link: "."}, {Name: "f", Link: "m/../evil"}, {Name: "f"}}, Good: true},
+		{Name: "zip parent", Zip: true, Entries: []archiveEntry{{Name: "../evil"}}},
+		{Name: "zip chained links", Zip: true, Entries: []archiveEntry{{Name: "m", Link: "."}, {Name: "l", Link: "m/.."}, {Name: "l/evil"}}},
+	}
+)
+
+func TestExtractArchive(t *testing.T) {
+	for _, tCase := range archiveCases {
+		root, err := ioutil.TempDir("", "op-archive-test-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(root)
+		path := filepath.Join(root, "archive")
+		if err := ioutil.WriteFile(path, newTestArchive(t, tCase.Zip, tCase.Entries), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// The dest is nested, so the escaped entries are still in the temp root
+		dest := filepath.Join(root, "out", "dest")
+		err = ExtractArchive(path, dest)
+		if tCase.Good && err != nil {
+			t.Errorf("Failed to extract [%s], error: %s", tCase.Name, err)
+		} else if !tCase.Good && err == nil {
+			t.Errorf("Archive [%s] should be rejected", tCase.Name)
+		}
+		for _, evil := range []string{filepath.Join(root, "evil"), filepath.Join(root, "out", "evil")} {
+			if _, err := os.Lstat(evil); err == nil {
+				t.Errorf("Archive [%s] wrote [%s] out of the directory", tCase.Name, evil)
+			}
+		}
+	}
+}
+
+func newTestArchive(t *testing.T, isZip bool, entries []archiveEntry) []byte {
+	var buffer bytes.Buffer
+	if isZip {
+		writer := zip.NewWriter(&buffer)
+		for _, entry := range entries {
+			header := &zip.FileHeader{Name: entry.Name}
+			header.SetMode(0644)
+			if entry.Link != "" {
+				header.SetMode(os.ModeSymlink | 0777)
+			}
+			w, err := writer.CreateHeader(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(entry.Link))
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buffer.Bytes()
+	}
+	writer := tar.NewWriter(&buffer)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.Name, Mode: 0644, Typeflag: tar.TypeReg}
+		switch {
+		case entry.Link != "":
+			header.Typeflag, header.Linkname, header.Mode = tar.TypeSymlink, entry.Link, 0777
+		case entry.Name[len(entry.Name)-1] == '/':
+			header.Typeflag, header.Mode = tar.TypeDir, 0755
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
@@ -0,0 +1,59 @@
+// Author: lipixun
+// Created Time : 六 10/17 03:14:45 2026
+//
+// File Name: copy.go
+// Description:
+//	The copy helper
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Copy the directory recursively, the file modes and symbol links are kept
+func CopyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return CopyFile(path, target, info.Mode().Perm())
+		}
+		// Ignore the other files, e.g. sockets
+		return nil
+	})
+}
+
+// Copy the file
+func CopyFile(src, dst string, mode os.FileMode) error {
+	reader, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	writer, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, reader); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
@@ -0,0 +1,82 @@
+// Author: lipixun
+// Created Time : 六 10/17 09:20:36 2026
+//
+// File Name: hash.go
+// Description:
+//	The hash helper
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Hash the files in the path (.git is ignored whatever its type, symbol links are not followed)
+// Only the content, the type and the executable bit of the files are hashed, so the same tree hashes the same on
+// any machine, e.g. the permissions depending on the umask and the .git file of a worktree are not hashed
+func HashPath(root string, writer io.Writer) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && info.Name() == ".git" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "%s:%s:%d\n", filepath.ToSlash(rel), getHashFileType(info.Mode()), info.Size())
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(writer, link)
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(writer, file)
+		return err
+	})
+}
+
+// Get the hashed file type: l for symbol links, x for executable files, f for the other regular files, o otherwise
+func getHashFileType(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return "l"
+	case !mode.IsRegular():
+		return "o"
+	case mode&0111 != 0:
+		return "x"
+	default:
+		return "f"
+	}
+}
+
+// Get the sha256 digest (hex) of the files in the path
+func HashPathDigest(root string) (string, error) {
+	hash := sha256.New()
+	if err := HashPath(root, hash); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
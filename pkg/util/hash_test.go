@@ -0,0 +1,107 @@
+// Author: lipixun
+// Created Time : 五 10/16 15:42:08 2026
+//
+// File Name: hash_test.go
+// Description:
+//
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	hashFiles = []struct {
+		Name    string
+		Content string
+		Mode    os.FileMode
+	}{
+		{Name: "README.md", Content: "readme\n", Mode: 0644},
+		{Name: "bin/run.sh", Content: "#!/bin/sh\necho run\n", Mode: 0755},
+		{Name: "src/main.go", Content: "package main\n", Mode: 0644},
+	}
+)
+
+// Run git in the directory
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to run git %v, error: %s, output: %s", args, err, output)
+	}
+}
+
+// Hash the path, fail the test on error
+func mustHashPath(t *testing.T, path string) string {
+	digest, err := HashPathDigest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
+
+func TestHashPathWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "hash-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	repo := filepath.Join(dir, "repo")
+	for _, file := range hashFiles {
+		path := filepath.Join(repo, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file.Content), file.Mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, repo, "init", "-q")
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-q", "-m", "init")
+	// The same commit checked out into two worktrees, the .git of which are files of different gitdir
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	runGit(t, repo, "worktree", "add", "-q", "--detach", first, "HEAD")
+	runGit(t, repo, "worktree", "add", "-q", "--detach", second, "HEAD")
+	if info, err := os.Lstat(filepath.Join(first, ".git")); err != nil || info.IsDir() {
+		t.Fatalf("The .git of the worktree should be a file, error: %v", err)
+	}
+	// The permissions of another umask
+	for _, file := range hashFiles {
+		if err := os.Chmod(filepath.Join(second, filepath.FromSlash(file.Name)), file.Mode|0020); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := mustHashPath(t, repo)
+	if actual := mustHashPath(t, first); actual != expect {
+		t.Errorf("Incorrect hash of the first worktree. Expect [%s] Actual [%s]", expect, actual)
+	}
+	if actual := mustHashPath(t, second); actual != expect {
+		t.Errorf("Incorrect hash of the second worktree. Expect [%s] Actual [%s]", expect, actual)
+	}
+	// The executable bit and the content are hashed
+	if err := os.Chmod(filepath.Join(second, "bin", "run.sh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if actual := mustHashPath(t, second); actual == expect {
+		t.Errorf("The hash should be changed by the executable bit")
+	}
+	if err := ioutil.WriteFile(filepath.Join(first, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if actual := mustHashPath(t, first); actual == expect {
+		t.Errorf("The hash should be changed by the content")
+	}
+}
@@ -0,0 +1,84 @@
+// Author: lipixun
+// Created Time : 六 10/17 00:12:48 2026
+//
+// File Name: size.go
+// Description:
+//	The size and age helper
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	sizeUnits = []string{"B", "K", "M", "G", "T"}
+)
+
+// Format the size in bytes to human readable text, e.g. 1.5G
+func FormatSize(size int64) string {
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(sizeUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%dB", size)
+	}
+	return fmt.Sprintf("%.1f%s", value, sizeUnits[unit])
+}
+
+// Parse the size text, e.g. 512, 100K, 1.5G (the unit is case insensitive and a tailing B is optional)
+func ParseSize(text string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(text))
+	if len(value) > 1 && strings.HasSuffix(value, "B") {
+		value = value[:len(value)-1]
+	}
+	multiplier := int64(1)
+	for i := len(sizeUnits) - 1; i > 0; i-- {
+		if strings.HasSuffix(value, sizeUnits[i]) {
+			value = value[:len(value)-1]
+			multiplier = int64(1) << uint(10*i)
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, errors.New(fmt.Sprintf("Invalid size [%s]", text))
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// Parse the age text, either a go duration (e.g. 36h) or days (e.g. 7d)
+func ParseAge(text string) (time.Duration, error) {
+	value := strings.TrimSpace(text)
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || days < 0 {
+			return 0, errors.New(fmt.Sprintf("Invalid age [%s]", text))
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, errors.New(fmt.Sprintf("Invalid age [%s]", text))
+	}
+	return duration, nil
+}
+
+// Format the age to the text of its largest unit, e.g. 45s, 12m, 3h, 7d
+func FormatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int64(age/time.Second))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int64(age/time.Minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int64(age/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int64(age/(24*time.Hour)))
+}
@@ -0,0 +1,46 @@
+// Author: lipixun
+// Created Time : 六 10/17 23:52:14 2026
+//
+// File Name: stat.go
+// Description:
+//	The stat helper
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Get the sha256 digest (hex) of the stats (path, mode, size and modification time) of the files in the path, which
+// is much cheaper than HashPathDigest to detect the changes. The directories skipped by the function are ignored
+func StatPathDigest(root string, skip func(path string, info os.FileInfo) bool) (string, error) {
+	hash := sha256.New()
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Removed while walking
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			if path != root && skip != nil && skip(path, info) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s:%s:%d:%d\n", rel, info.Mode(), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,72 @@
+// Author: lipixun
+// Created Time : 日 10/18 10:21:05 2026
+//
+// File Name: zstd.go
+// Description:
+//	The zstd compression by the zstd command (https://github.com/facebook/zstd), which is required in the PATH
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+const (
+	ZstdCommand = "zstd"
+)
+
+var (
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// The writer compressing the data to the underlying writer, must be closed to flush the data
+type ZstdWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// Create a zstd writer of the compression level (1-19, 0 for the default level of zstd)
+func NewZstdWriter(writer io.Writer, level int) (*ZstdWriter, error) {
+	args := []string{"-q", "-c"}
+	if level != 0 {
+		args = append(args, fmt.Sprintf("-%d", level))
+	}
+	cmd := exec.Command(ZstdCommand, args...)
+	cmd.Stdout = writer
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to start %s, error: %s", ZstdCommand, err))
+	}
+	return &ZstdWriter{cmd: cmd, stdin: stdin}, nil
+}
+
+func (this *ZstdWriter) Write(data []byte) (int, error) {
+	return this.stdin.Write(data)
+}
+
+// Close and wait the compression done
+func (this *ZstdWriter) Close() error {
+	if err := this.stdin.Close(); err != nil {
+		this.cmd.Wait()
+		return err
+	}
+	if err := this.cmd.Wait(); err != nil {
+		return errors.New(fmt.Sprintf("Failed to run %s, error: %s", ZstdCommand, err))
+	}
+	return nil
+}
+
+// Decompress the zstd data of the reader to the writer
+func ZstdDecompress(reader io.Reader, writer io.Writer) error {
+	cmd := exec.Command(ZstdCommand, "-q", "-d", "-c")
+	cmd.Stdin, cmd.Stdout = reader, writer
+	if err := cmd.Run(); err != nil {
+		return errors.New(fmt.Sprintf("Failed to run %s, error: %s", ZstdCommand, err))
+	}
+	return nil
+}
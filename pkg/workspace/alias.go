@@ -0,0 +1,181 @@
+// Author: lipixun
+// Created Time : 六 10/17 23:18:40 2026
+//
+// File Name: alias.go
+// Description:
+//	The command aliases
+//
+//	An alias is defined by the config key alias.<name>, the value is the arguments of op, e.g.
+//		op config set alias.up "build :all && start dev"
+//	Then op up runs op build :all, and op start dev if the build succeeded.
+//
+//	The value is split into words like the shell: the words are separated by the spaces, quoted by ' or " and
+//	escaped by \. The commands are chained by &&, each runs only if the former one succeeded.
+//	The arguments after the alias replace the $@ words (a quoted or escaped $@ is kept as it is), or are appended to
+//	the last command if no $@, e.g.
+//		alias.b = "build --trace $@ && status"
+//		op b :app 	runs op build --trace :app && op status
+//
+//	The user defined aliases overwrite the builtin ones (see BuiltinAliases), the builtin commands are never aliased.
+//	Only the global and user config can define the aliases, the aliases in the project config are ignored since an
+//	alias runs any op command (e.g. config set or clean --all --yes) and shadows the external commands.
+//	An alias may run another alias, up to MaxAliasDepth nested aliases, a recursive alias is an error (see AliasStack).
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ConfigKeyAliasPrefix = "alias."
+
+	AliasChainSeparator  = "&&"
+	AliasArgsPlaceholder = "$@"
+
+	MaxAliasDepth = 8 // The max number of the nested aliases
+)
+
+// The builtin aliases
+var BuiltinAliases = map[string]string{
+	"ls":   "status",
+	"ps":   "status --all",
+	"tail": "logs --follow",
+}
+
+// Get the alias of the name, the user defined one (the project config is ignored) first
+// Returns:
+//
+//	The alias value and whether the alias is found
+func (this *Workspace) GetAlias(name string) (string, bool) {
+	if value, _, ok := this.LookupUserConfig(ConfigKeyAliasPrefix + name); ok {
+		return value, true
+	}
+	value, ok := BuiltinAliases[name]
+	return value, ok
+}
+
+// The aliases being expanded, from the outermost one
+type AliasStack struct {
+	names []string
+}
+
+// Push the alias to expand, fails if the alias is being expanded or nested too deep
+func (this *AliasStack) Push(name string) error {
+	for i, expanding := range this.names {
+		if expanding == name {
+			return errors.New(fmt.Sprintf("Recursive alias [%s]: %s -> %s", name, strings.Join(this.names[i:], " -> "), name))
+		}
+	}
+	if len(this.names) >= MaxAliasDepth {
+		return errors.New(fmt.Sprintf("Too many nested aliases (max %d): %s -> %s", MaxAliasDepth, strings.Join(this.names, " -> "), name))
+	}
+	this.names = append(this.names, name)
+	return nil
+}
+
+// Pop the innermost alias after expanded
+func (this *AliasStack) Pop() {
+	if len(this.names) > 0 {
+		this.names = this.names[:len(this.names)-1]
+	}
+}
+
+// Expand the alias value with the arguments
+// Returns:
+//
+//	The commands to run in order, each is the arguments of op
+func ExpandAlias(value string, args []string) ([][]string, error) {
+	words, err := splitAlias(value)
+	if err != nil {
+		return nil, err
+	}
+	commands := make([][]string, len(words))
+	placeholder := false
+	for i, command := range words {
+		for _, word := range command {
+			if word.placeholder {
+				placeholder = true
+				commands[i] = append(commands[i], args...)
+			} else {
+				commands[i] = append(commands[i], word.text)
+			}
+		}
+	}
+	if !placeholder {
+		last := len(commands) - 1
+		commands[last] = append(commands[last], args...)
+	}
+	// Done
+	return commands, nil
+}
+
+// A word of the alias value
+type aliasWord struct {
+	text        string
+	placeholder bool // Whether the word is the unquoted $@
+}
+
+// Split the alias value into the commands of words
+func splitAlias(value string) ([][]aliasWord, error) {
+	var commands [][]aliasWord
+	var command []aliasWord
+	var word strings.Builder
+	var quote rune
+	inWord, escaped, literal := false, false, false
+	endWord := func() {
+		if inWord {
+			text := word.String()
+			command = append(command, aliasWord{text: text, placeholder: !literal && text == AliasArgsPlaceholder})
+			word.Reset()
+			inWord, literal = false, false
+		}
+	}
+	endCommand := func() error {
+		endWord()
+		if len(command) == 0 {
+			return errors.New(fmt.Sprintf("Empty command in alias [%s]", value))
+		}
+		commands = append(commands, command)
+		command = nil
+		return nil
+	}
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			inWord, escaped, literal = true, true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			inWord, quote, literal = true, r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			endWord()
+		case strings.HasPrefix(string(runes[i:]), AliasChainSeparator):
+			if err := endCommand(); err != nil {
+				return nil, err
+			}
+			i += len(AliasChainSeparator) - 1
+		default:
+			inWord = true
+			word.WriteRune(r)
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, errors.New(fmt.Sprintf("Unterminated quote or escape in alias [%s]", value))
+	}
+	if err := endCommand(); err != nil {
+		return nil, err
+	}
+	// Done
+	return commands, nil
+}
@@ -0,0 +1,127 @@
+// Author: lipixun
+// Created Time : 五 10/16 13:52:18 2026
+//
+// File Name: alias_test.go
+// Description:
+//
+package workspace
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	expandAliasCases = []struct {
+		Value    string
+		Args     []string
+		Commands [][]string
+		Error    string
+	}{
+		{Value: "status", Commands: [][]string{{"status"}}},
+		{Value: "  build \t:all\n", Args: []string{"--trace"}, Commands: [][]string{{"build", ":all", "--trace"}}},
+		{Value: "build :all && start dev", Args: []string{"-v"}, Commands: [][]string{{"build", ":all"}, {"start", "dev", "-v"}}},
+		{Value: "build&&status", Commands: [][]string{{"build"}, {"status"}}},
+		{Value: "build --trace $@ && status", Args: []string{":app", ":lib"}, Commands: [][]string{{"build", "--trace", ":app", ":lib"}, {"status"}}},
+		{Value: "build $@ && test $@", Args: []string{":app"}, Commands: [][]string{{"build", ":app"}, {"test", ":app"}}},
+		{Value: "build $@ --trace", Commands: [][]string{{"build", "--trace"}}},
+		{Value: "build '$@'", Args: []string{":app"}, Commands: [][]string{{"build", "$@", ":app"}}},
+		{Value: `build "$@" \$@ $@x`, Args: []string{":app"}, Commands: [][]string{{"build", "$@", "$@", "$@x", ":app"}}},
+		// Quoting and escaping
+		{Value: `run "hello world" 'a  b'`, Commands: [][]string{{"run", "hello world", "a  b"}}},
+		{Value: `run a"b c"d`, Commands: [][]string{{"run", "ab cd"}}},
+		{Value: `run '' ""`, Commands: [][]string{{"run", "", ""}}},
+		{Value: `run "a\"b" 'a\b' a\ b`, Commands: [][]string{{"run", `a"b`, `a\b`, "a b"}}},
+		{Value: `run "x && y" '&&' \&& && status`, Commands: [][]string{{"run", "x && y", "&&", "&&"}, {"status"}}},
+		{Value: `run a\\`, Commands: [][]string{{"run", `a\`}}},
+		{Value: "run 你好", Commands: [][]string{{"run", "你好"}}},
+		// Errors
+		{Value: "", Error: "Empty command"},
+		{Value: "  ", Error: "Empty command"},
+		{Value: "&& status", Error: "Empty command"},
+		{Value: "build &&", Error: "Empty command"},
+		{Value: "build && && status", Error: "Empty command"},
+		{Value: `run "a`, Error: "Unterminated"},
+		{Value: `run 'a`, Error: "Unterminated"},
+		{Value: `run a\`, Error: "Unterminated"},
+	}
+)
+
+func TestExpandAlias(t *testing.T) {
+	for _, tCase := range expandAliasCases {
+		commands, err := ExpandAlias(tCase.Value, tCase.Args)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of alias [%s]. Expect [%s] Actual [%v] commands %q", tCase.Value, tCase.Error, err, commands)
+			}
+		} else if err != nil || !reflect.DeepEqual(commands, tCase.Commands) {
+			t.Errorf("Incorrect commands of alias [%s]. Expect %q Actual %q error [%v]", tCase.Value, tCase.Commands, commands, err)
+		}
+	}
+}
+
+func TestAliasStack(t *testing.T) {
+	var stack AliasStack
+	for _, name := range []string{"a", "b"} {
+		if err := stack.Push(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stack.Push("a"); err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("Incorrect error of the recursive alias. Actual [%v]", err)
+	}
+	if err := stack.Push("b"); err == nil || !strings.Contains(err.Error(), "Recursive alias [b]: b -> b") {
+		t.Errorf("Incorrect error of the self recursive alias. Actual [%v]", err)
+	}
+	// The expanded alias could be expanded again
+	stack.Pop()
+	if err := stack.Push("b"); err != nil {
+		t.Errorf("Failed to push the popped alias, error: %s", err)
+	}
+	stack.Pop()
+	stack.Pop()
+	stack.Pop()
+	// The depth limit
+	for i := 0; i < MaxAliasDepth; i++ {
+		if err := stack.Push(fmt.Sprintf("a%d", i)); err != nil {
+			t.Fatalf("Failed to push the nested alias %d, error: %s", i, err)
+		}
+	}
+	if err := stack.Push("deep"); err == nil || !strings.Contains(err.Error(), "Too many nested aliases") {
+		t.Errorf("Incorrect error of the deep alias. Actual [%v]", err)
+	}
+}
+
+func TestGetAlias(t *testing.T) {
+	ws, logger, cleanup := newTestWorkspace(t)
+	defer cleanup()
+	ws.Config.Layer(ConfigLayerUser).Values[ConfigKeyAliasPrefix+"ls"] = "status --all"
+	ws.Config.Layer(ConfigLayerUser).Values[ConfigKeyAliasPrefix+"up"] = "build :all && start dev"
+	// The project aliases are ignored
+	ws.Config.Layer(ConfigLayerProject).Values[ConfigKeyAliasPrefix+"up"] = "clean --all --yes"
+	ws.Config.Layer(ConfigLayerProject).Values[ConfigKeyAliasPrefix+"ps"] = "self-update --insecure"
+	ws.Config.Layer(ConfigLayerProject).Values[ConfigKeyAliasPrefix+"deploy"] = "config set update.endpoint http://evil"
+	for _, tCase := range []struct {
+		Name    string
+		Value   string
+		Found   bool
+		Warning bool
+	}{
+		{Name: "ls", Value: "status --all", Found: true},
+		{Name: "ps", Value: BuiltinAliases["ps"], Found: true, Warning: true},
+		{Name: "up", Value: "build :all && start dev", Found: true, Warning: true},
+		{Name: "deploy", Warning: true},
+		{Name: "down"},
+	} {
+		logger.Reset()
+		if value, found := ws.GetAlias(tCase.Name); value != tCase.Value || found != tCase.Found {
+			t.Errorf("Incorrect alias [%s]. Expect [%s] %v Actual [%s] %v", tCase.Name, tCase.Value, tCase.Found, value, found)
+		}
+		if warned := logger.Contains(log.LevelWarn, "Ignore "+ConfigKeyAliasPrefix+tCase.Name); warned != tCase.Warning {
+			t.Errorf("Incorrect warning of alias [%s]. Expect %v Actual %v", tCase.Name, tCase.Warning, warned)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Author: lipixun
+// Created Time : 六 10/17 04:02:33 2026
+//
+// File Name: cache.go
+// Description:
+//	The content addressed cache
+//
+//	The cache directory:
+//		<user>/cache/<namespace>/
+//			objects/<sha256> 		The content, named by its sha256 digest (shared by the entries with the same content)
+//			entries/<key hash>.json 	The entry of the key: the digest, size and expire time
+//
+//	The modification time of the entry file is the last access time, the least recently used entries are evicted
+//	when the total size exceeds the max size. The content is verified against its digest when got, a corrupted
+//	entry is removed and treated as missed.
+//
+//	The cache keeps single file contents: the fetched files (fetcher), the test results (tester) and the go import
+//	metadata (repofetcher). The fetched repositories and the build outputs are directory trees updated in place
+//	(git fetch, incremental builds), so they keep their own layouts (see repofetcher/fetcher.go and builder) and are
+//	pruned by op workspace clean instead.
+package workspace
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	CacheDirName        = "cache"
+	CacheObjectsDirName = "objects"
+	CacheEntriesDirName = "entries"
+)
+
+var (
+	cacheNamespaceRegularExp = regexp.MustCompile("^[a-zA-Z\\d][a-zA-Z\\d_\\-\\.]*$")
+)
+
+type CacheOptions struct {
+	TTL     time.Duration // The time to live of the entries, never expire if not positive
+	MaxSize int64         // The max total size of the contents in bytes, unlimited if not positive
+}
+
+// The cache of a namespace
+type Cache struct {
+	lock    sync.Mutex
+	path    string
+	Options CacheOptions
+}
+
+// The cache entry
+type CacheEntry struct {
+	Key     string    `json:"key"`     // The key
+	Digest  string    `json:"digest"`  // The sha256 digest of the content
+	Size    int64     `json:"size"`    // The content size
+	Time    time.Time `json:"time"`    // The time when put
+	Expires time.Time `json:"expires"` // The expire time, zero if never expire
+	access  time.Time
+}
+
+// Get the cache of the namespace
+func (this *Workspace) Cache(namespace string, options CacheOptions) (*Cache, error) {
+	if !cacheNamespaceRegularExp.MatchString(namespace) {
+		return nil, errors.New(fmt.Sprintf("Invalid cache namespace [%s]", namespace))
+	}
+	path, err := this.Dir.User.GetPath(filepath.Join(CacheDirName, namespace))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{CacheObjectsDirName, CacheEntriesDirName} {
+		if err := os.MkdirAll(filepath.Join(path, name), os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	return &Cache{path: path, Options: options}, nil
+}
+
+func (this *Cache) entryPath(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(this.path, CacheEntriesDirName, hex.EncodeToString(hash[:])+".json")
+}
+
+func (this *Cache) objectPath(digest string) string {
+	return filepath.Join(this.path, CacheObjectsDirName, digest)
+}
+
+// Get the content path of the key
+// Returns:
+// 	The content path (should not be modified) and whether the key is found
+func (this *Cache) Get(key string) (string, bool, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	entryPath := this.entryPath(key)
+	data, err := ioutil.ReadFile(entryPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		// Broken entry
+		os.Remove(entryPath)
+		return "", false, nil
+	}
+	if !entry.Expires.IsZero() && time.Now().After(entry.Expires) {
+		os.Remove(entryPath)
+		return "", false, nil
+	}
+	// Verify the content
+	objectPath := this.objectPath(entry.Digest)
+	if digest, _, err := hashFile(objectPath); err != nil || digest != entry.Digest {
+		os.Remove(entryPath)
+		os.Remove(objectPath)
+		return "", false, nil
+	}
+	// Update the access time
+	now := time.Now()
+	os.Chtimes(entryPath, now, now)
+	// Done
+	return objectPath, true, nil
+}
+
+// Get the content of the key
+func (this *Cache) GetBytes(key string) ([]byte, bool, error) {
+	path, ok, err := this.Get(key)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put the content of the key
+func (this *Cache) Put(key string, reader io.Reader) (*CacheEntry, error) {
+	// Write the content to a temp file and get the digest
+	tempFile, err := ioutil.TempFile(filepath.Join(this.path, CacheObjectsDirName), ".put-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile.Name())
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tempFile, hash), reader)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if this.Options.MaxSize > 0 && size > this.Options.MaxSize {
+		return nil, errors.New(fmt.Sprintf("Content of [%s] (%d bytes) is larger than the max cache size (%d bytes)", key, size, this.Options.MaxSize))
+	}
+	entry := &CacheEntry{Key: key, Digest: hex.EncodeToString(hash.Sum(nil)), Size: size, Time: time.Now()}
+	if this.Options.TTL > 0 {
+		entry.Expires = entry.Time.Add(this.Options.TTL)
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if err := os.Rename(tempFile.Name(), this.objectPath(entry.Digest)); err != nil {
+		return nil, err
+	}
+	// Write the entry
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	entryPath := this.entryPath(key)
+	if err := ioutil.WriteFile(entryPath+".tmp", data, 0666); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(entryPath+".tmp", entryPath); err != nil {
+		return nil, err
+	}
+	// Evict, the entry just put is the most recently used one which is evicted last
+	if err := this.prune(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(entryPath); err != nil {
+		return nil, errors.New(fmt.Sprintf("Content of [%s] is evicted right after put, error: %s", key, err))
+	}
+	// Done
+	return entry, nil
+}
+
+// Put the content of the key
+func (this *Cache) PutBytes(key string, data []byte) (*CacheEntry, error) {
+	return this.Put(key, bytes.NewReader(data))
+}
+
+// Remove the key
+func (this *Cache) Remove(key string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if err := os.Remove(this.entryPath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return this.prune()
+}
+
+// Remove the expired entries, evict the least recently used entries if the total size exceeds the max size, then
+// remove the contents not referenced by any entry
+func (this *Cache) Prune() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.prune()
+}
+
+func (this *Cache) prune() error {
+	entriesPath := filepath.Join(this.path, CacheEntriesDirName)
+	infos, err := ioutil.ReadDir(entriesPath)
+	if err != nil {
+		return err
+	}
+	// Load the entries and remove the expired ones
+	var entries []*CacheEntry
+	now := time.Now()
+	for _, info := range infos {
+		path := filepath.Join(entriesPath, info.Name())
+		if filepath.Ext(info.Name()) != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry CacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil || (!entry.Expires.IsZero() && now.After(entry.Expires)) {
+			os.Remove(path)
+			continue
+		}
+		entry.access = info.ModTime()
+		entries = append(entries, &entry)
+	}
+	// Evict the least recently used entries
+	if this.Options.MaxSize > 0 {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].access.Before(entries[j].access)
+		})
+		sizes := make(map[string]int64)
+		var total int64
+		for _, entry := range entries {
+			if _, ok := sizes[entry.Digest]; !ok {
+				total += entry.Size
+			}
+			sizes[entry.Digest]++
+		}
+		for len(entries) > 0 && total > this.Options.MaxSize {
+			entry := entries[0]
+			entries = entries[1:]
+			if err := os.Remove(this.entryPath(entry.Key)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if sizes[entry.Digest]--; sizes[entry.Digest] == 0 {
+				total -= entry.Size
+			}
+		}
+	}
+	// Remove the unreferenced contents
+	referenced := make(map[string]bool)
+	for _, entry := range entries {
+		referenced[entry.Digest] = true
+	}
+	objectsPath := filepath.Join(this.path, CacheObjectsDirName)
+	infos, err = ioutil.ReadDir(objectsPath)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		// Keep the temp files of the putting contents
+		if !referenced[info.Name()] && info.Name()[0] != '.' {
+			if err := os.Remove(filepath.Join(objectsPath, info.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	// Done
+	return nil
+}
+
+// Get the sha256 digest and size of the file
+func hashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
@@ -0,0 +1,233 @@
+// Author: lipixun
+// Created Time : 五 10/16 12:31:47 2026
+//
+// File Name: cache_test.go
+// Description:
+//
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cacheDigest(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
+
+// Check the keys found or not in the cache
+func checkCacheKeys(t *testing.T, cache *Cache, name string, keys map[string]bool) {
+	for key, expect := range keys {
+		if _, ok, err := cache.Get(key); err != nil || ok != expect {
+			t.Errorf("Incorrect key [%s] %s. Expect found %v Actual %v error [%v]", key, name, expect, ok, err)
+		}
+	}
+}
+
+// Check the content objects exist or not in the cache
+func checkCacheObjects(t *testing.T, cache *Cache, name string, contents map[string]bool) {
+	for content, expect := range contents {
+		_, err := os.Stat(cache.objectPath(cacheDigest(content)))
+		if exists := err == nil; exists != expect {
+			t.Errorf("Incorrect object of [%s] %s. Expect exists %v Actual %v", content, name, expect, exists)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	ws, _, cleanup := newTestWorkspace(t)
+	defer cleanup()
+	for _, namespace := range []string{"", "../test", ".test", "a/b"} {
+		if _, err := ws.Cache(namespace, CacheOptions{}); err == nil {
+			t.Errorf("Namespace [%s] should be invalid", namespace)
+		}
+	}
+	cache, err := ws.Cache("test", CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Round trip
+	if _, ok, err := cache.GetBytes("a"); err != nil || ok {
+		t.Errorf("Incorrect missed key. Actual found %v error [%v]", ok, err)
+	}
+	entry, err := cache.PutBytes("a", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Key != "a" || entry.Digest != cacheDigest("hello") || entry.Size != 5 || !entry.Expires.IsZero() {
+		t.Errorf("Incorrect entry %+v", entry)
+	}
+	if data, ok, err := cache.GetBytes("a"); err != nil || !ok || string(data) != "hello" {
+		t.Errorf("Incorrect content. Expect [hello] Actual [%s] found %v error [%v]", data, ok, err)
+	}
+	// The same content is shared, the object is removed when not referenced
+	if _, err := cache.PutBytes("b", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	checkCacheKeys(t, cache, "after removing a", map[string]bool{"a": false, "b": true})
+	checkCacheObjects(t, cache, "after removing a", map[string]bool{"hello": true})
+	if _, err := cache.PutBytes("b", []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	checkCacheObjects(t, cache, "after replacing b", map[string]bool{"hello": false, "world": true})
+	if err := cache.Remove("none"); err != nil {
+		t.Errorf("Failed to remove the missed key, error: %s", err)
+	}
+	// The temp files of the putting contents are kept
+	tempPath := filepath.Join(cache.path, CacheObjectsDirName, ".put-test")
+	if err := ioutil.WriteFile(tempPath, []byte("putting"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tempPath); err != nil {
+		t.Errorf("The temp file is removed by prune, error: %s", err)
+	}
+}
+
+func TestCacheExpire(t *testing.T) {
+	ws, _, cleanup := newTestWorkspace(t)
+	defer cleanup()
+	cache, err := ws.Cache("test", CacheOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := cache.PutBytes("a", []byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Expires.Sub(entry.Time) != time.Hour {
+		t.Errorf("Incorrect expire time %v of put time %v", entry.Expires, entry.Time)
+	}
+	cache.Options.TTL = time.Millisecond
+	if _, err := cache.PutBytes("b", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.PutBytes("c", []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	checkCacheKeys(t, cache, "after expired", map[string]bool{"a": true, "b": false})
+	// The expired entries and their contents are removed by prune
+	if err := cache.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cache.entryPath("c")); !os.IsNotExist(err) {
+		t.Errorf("The expired entry is not pruned, error: %v", err)
+	}
+	checkCacheObjects(t, cache, "after pruned", map[string]bool{"a": true, "b": false, "c": false})
+}
+
+func TestCacheEvict(t *testing.T) {
+	ws, _, cleanup := newTestWorkspace(t)
+	defer cleanup()
+	// The shared content is counted once: 5 (a, b) + 5 (c) + 6 (d) = 16
+	cache, err := ws.Cache("test", CacheOptions{MaxSize: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range [][2]string{{"a", "12345"}, {"b", "12345"}, {"c", "67890"}, {"d", "abcdef"}} {
+		if _, err := cache.PutBytes(item[0], []byte(item[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkCacheKeys(t, cache, "within max size", map[string]bool{"a": true, "b": true, "c": true, "d": true})
+	// Set the access time: a, c, b, d from the least recently used
+	now := time.Now()
+	for i, key := range []string{"a", "c", "b", "d"} {
+		accessTime := now.Add(time.Duration(i-4) * time.Hour)
+		if err := os.Chtimes(cache.entryPath(key), accessTime, accessTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 16 + 3 (e) = 19, evicting a doesn't free the content shared by b, evicting c does: 14
+	if _, err := cache.PutBytes("e", []byte("xyz")); err != nil {
+		t.Fatal(err)
+	}
+	checkCacheKeys(t, cache, "after evicted", map[string]bool{"a": false, "b": true, "c": false, "d": true, "e": true})
+	checkCacheObjects(t, cache, "after evicted", map[string]bool{"12345": true, "67890": false, "abcdef": true, "xyz": true})
+	// Get updates the access time, the unlimited cache doesn't evict
+	for i, key := range []string{"b", "d", "e"} {
+		accessTime := now.Add(time.Duration(i-3) * time.Hour)
+		if err := os.Chtimes(cache.entryPath(key), accessTime, accessTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache.Options.MaxSize = 9
+	if _, ok, err := cache.Get("b"); err != nil || !ok {
+		t.Fatalf("Failed to get b. Actual found %v error [%v]", ok, err)
+	}
+	if err := cache.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	checkCacheKeys(t, cache, "after b accessed", map[string]bool{"b": true, "d": false, "e": true})
+	// The content larger than the max size is not put, nothing is evicted for it
+	if entry, err := cache.PutBytes("large", []byte("0123456789")); err == nil || !strings.Contains(err.Error(), "larger than the max cache size") {
+		t.Errorf("Incorrect error of the content larger than the max size. Actual entry %v error [%v]", entry, err)
+	}
+	checkCacheKeys(t, cache, "after the large content", map[string]bool{"b": true, "e": true, "large": false})
+	cache.Options.MaxSize = 0
+	if _, err := cache.PutBytes("f", make([]byte, 1024)); err != nil {
+		t.Fatal(err)
+	}
+	checkCacheKeys(t, cache, "of unlimited size", map[string]bool{"b": true, "e": true, "f": true})
+}
+
+func TestCacheIntegrity(t *testing.T) {
+	ws, _, cleanup := newTestWorkspace(t)
+	defer cleanup()
+	cache, err := ws.Cache("test", CacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"corrupted", "missing", "broken", "collided"} {
+		if _, err := cache.PutBytes(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The corrupted content
+	if err := ioutil.WriteFile(cache.objectPath(cacheDigest("corrupted")), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The missing content
+	if err := os.Remove(cache.objectPath(cacheDigest("missing"))); err != nil {
+		t.Fatal(err)
+	}
+	// The broken entry
+	if err := ioutil.WriteFile(cache.entryPath("broken"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The entry of another key
+	data, err := ioutil.ReadFile(cache.entryPath("collided"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(cache.entryPath("other"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkCacheKeys(t, cache, "of the integrity check", map[string]bool{"corrupted": false, "missing": false, "broken": false, "other": false, "collided": true})
+	for _, key := range []string{"corrupted", "missing", "broken", "other"} {
+		if _, err := os.Stat(cache.entryPath(key)); !os.IsNotExist(err) {
+			t.Errorf("The invalid entry [%s] is not removed, error: %v", key, err)
+		}
+	}
+	checkCacheObjects(t, cache, "of the integrity check", map[string]bool{"corrupted": false, "collided": true})
+	// The key is put again after missed
+	if _, err := cache.PutBytes("corrupted", []byte("corrupted")); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok, err := cache.GetBytes("corrupted"); err != nil || !ok || string(data) != "corrupted" {
+		t.Errorf("Incorrect content after put again. Actual [%s] found %v error [%v]", data, ok, err)
+	}
+}
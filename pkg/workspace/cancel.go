@@ -0,0 +1,67 @@
+// Author: lipixun
+// Created Time : 六 10/17 22:05:37 2026
+//
+// File Name: cancel.go
+// Description:
+//	The cancellation of the workspace
+//
+//	The workspace is canceled when op is interrupted (SIGINT or SIGTERM, see cli/signal.go). The long running
+//	operations check Canceled between their steps (e.g. the targets of a build or a test run) and the external
+//	commands created by Command are interrupted (SIGINT, then killed after CommandWaitDelay). So the commands return
+//	through their deferred cleanups (file locks, temp directories, partial downloads) instead of leaving the half
+//	finished state behind.
+package workspace
+
+import (
+	"context"
+	"github.com/ops-openlight/openlight/pkg/errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const (
+	CommandWaitDelay = 10 * time.Second // The time to wait the interrupted command before killing it
+)
+
+// The error of the canceled operation
+type CanceledError struct{}
+
+func (this *CanceledError) Error() string {
+	return "Canceled by interrupt"
+}
+
+// Check if the error (or its cause) is a CanceledError
+func IsCanceledError(err error) bool {
+	_, ok := errors.Cause(err).(*CanceledError)
+	return ok
+}
+
+// Get the context which is done when the workspace is canceled
+func (this *Workspace) Context() context.Context {
+	return this.ctx
+}
+
+// Cancel the running operations of the workspace
+func (this *Workspace) Cancel() {
+	this.cancel()
+}
+
+// Get the CanceledError if the workspace is canceled, nil otherwise
+func (this *Workspace) Canceled() error {
+	if this.ctx.Err() != nil {
+		return &CanceledError{}
+	}
+	return nil
+}
+
+// Create the external command which is interrupted when the workspace is canceled. The command should be waited
+// (e.g. by Run), use exec.Command for the commands released to run in background
+func (this *Workspace) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(this.ctx, name, args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = CommandWaitDelay
+	return cmd
+}
@@ -0,0 +1,344 @@
+// Author: lipixun
+// Created Time : 五 10/16 23:32:05 2026
+//
+// File Name: config.go
+// Description:
+//	The workspace configuration
+//
+//	The configuration is layered (see layer.go), a key defined in a latter layer overwrites the former one:
+//		global 		<global>/config.yaml
+//		user 		<user>/config.yaml
+//		project 	<project>/.op.config.yaml
+//
+//	Each file is a flat yaml map of the dotted key to the value, e.g.
+//		log.verbose: true
+//		test.cache: false
+//
+//	Only the keys defined in ConfigKeys are allowed to be set, a key ends with .* allows any key of the prefix, e.g.
+//		alias.up: build :all && start dev
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ConfigLayerGlobal  = "global"
+	ConfigLayerUser    = "user"
+	ConfigLayerProject = "project"
+
+	ConfigFileName        = "config.yaml"
+	ProjectConfigFileName = ".op.config.yaml"
+
+	DefaultUpdateEndpoint = "https://github.com/ops-openlight/openlight"
+
+	ConfigTypeString = "string"
+	ConfigTypeBool   = "bool"
+	ConfigTypeInt    = "int"
+
+	ConfigKeyLogVerbose         = "log.verbose"
+	ConfigKeyLogLevel           = "log.level"
+	ConfigKeyLogColor           = "log.color"
+	ConfigKeyTestCache          = "test.cache"
+	ConfigKeyDockerRegistryAuth = "docker.registry.auth"
+	ConfigKeyStateBackend       = "state.backend"
+	ConfigKeyGitDepth           = "git.depth"
+	ConfigKeyGitTTL             = "git.ttl"
+	ConfigKeyGitSubmodules      = "git.submodules"
+	ConfigKeyGitLFS             = "git.lfs"
+	ConfigKeyS3Region           = "s3.region"
+	ConfigKeyS3Endpoint         = "s3.endpoint"
+	ConfigKeyGCSEndpoint        = "gcs.endpoint"
+	ConfigKeyOCIInsecure        = "oci.insecure"
+	ConfigKeyOffline            = "offline"
+	ConfigKeyCredentialHelpers  = "credential.helpers"
+	ConfigKeyProxyHTTP          = "proxy.http"
+	ConfigKeyProxySSH           = "proxy.ssh"
+	ConfigKeyProxyNo            = "proxy.no"
+	ConfigKeyUpdateEndpoint     = "update.endpoint"
+	ConfigKeyUpdateChannel      = "update.channel"
+	ConfigKeyUpdatePublicKey    = "update.publickey"
+	ConfigKeyAlias              = ConfigKeyAliasPrefix + "*"
+	ConfigKeyTelemetryEnabled   = "telemetry.enabled"
+	ConfigKeyTelemetryEndpoint  = "telemetry.endpoint"
+	ConfigKeyArtifactRegistry   = "artifact.registry"
+	ConfigKeyArtifactChannels   = "artifact.channels"
+	ConfigKeyArtifactSigningKey = "artifact.signingkey"
+	ConfigKeyArtifactPublicKey  = "artifact.publickey"
+)
+
+// A configuration key
+type ConfigKey struct {
+	Name        string // The dotted name
+	Type        string // The value type, one of ConfigType*
+	Default     string // The default value
+	Description string // The description
+}
+
+// The known configuration keys
+var ConfigKeys = []ConfigKey{
+	{Name: ConfigKeyLogVerbose, Type: ConfigTypeBool, Default: "false", Description: "Show verbose log (debug log) when neither --verbose nor a log level is specified"},
+	{Name: ConfigKeyLogLevel, Type: ConfigTypeString, Description: "The default log level, e.g. debug, warn"},
+	{Name: ConfigKeyLogColor, Type: ConfigTypeBool, Default: "true", Description: "Enable the color of the log (still disabled when not a terminal)"},
+	{Name: ConfigKeyTestCache, Type: ConfigTypeBool, Default: "true", Description: "Skip the tests whose inputs are not changed since the last pass"},
+	{Name: ConfigKeyDockerRegistryAuth, Type: ConfigTypeString, Description: "The docker config file (e.g. ~/.docker/config.json) to read the registry credentials from when pushing images. Ignored in the project config"},
+	{Name: ConfigKeyGitDepth, Type: ConfigTypeInt, Default: "0", Description: "The depth of the shallow clones of the remote repositories, full clone if 0"},
+	{Name: ConfigKeyGitTTL, Type: ConfigTypeInt, Default: "600", Description: "The seconds to reuse the fetched branches and tags of the remote repositories without fetching again (--refresh to fetch anyway), always fetch if 0"},
+	{Name: ConfigKeyGitSubmodules, Type: ConfigTypeBool, Default: "true", Description: "Initialize the submodules of the remote repositories recursively unless the reference specifies submodules"},
+	{Name: ConfigKeyGitLFS, Type: ConfigTypeBool, Default: "true", Description: "Pull the git lfs objects of the remote repositories (requires git-lfs) unless the reference specifies lfs"},
+	{Name: ConfigKeyS3Region, Type: ConfigTypeString, Description: "The region of the s3 buckets, AWS_REGION or the aws config if not set. Ignored in the project config"},
+	{Name: ConfigKeyS3Endpoint, Type: ConfigTypeString, Description: "The endpoint of the s3 compatible storage, e.g. http://localhost:9000, the aws endpoint of the region if not set. Ignored in the project config"},
+	{Name: ConfigKeyGCSEndpoint, Type: ConfigTypeString, Description: "The endpoint of the google cloud storage, https://storage.googleapis.com if not set. Ignored in the project config"},
+	{Name: ConfigKeyOCIInsecure, Type: ConfigTypeString, Description: "The comma separated oci registries (host:port) accessed by plain http, e.g. localhost:5000. Ignored in the project config"},
+	{Name: ConfigKeyOffline, Type: ConfigTypeBool, Default: "false", Description: "Never access the network, serve the remote repositories and files from the caches and op.lock only"},
+	{Name: ConfigKeyCredentialHelpers, Type: ConfigTypeString, Description: "The comma separated credential helpers of the hosts (host=command, * for any host), see workspace/credentialhelper.go"},
+	{Name: ConfigKeyProxyHTTP, Type: ConfigTypeString, Description: "The proxy of the http(s), s3, gcs, oci urls and git http(s) remotes, e.g. http://proxy:3128 or socks5://proxy:1080, HTTPS_PROXY / HTTP_PROXY if not set. Ignored in the project config"},
+	{Name: ConfigKeyProxySSH, Type: ConfigTypeString, Description: "The proxy of the git ssh remotes, socks5://host:port or http://host:port (requires nc -X), see workspace/proxy.go. Ignored in the project config"},
+	{Name: ConfigKeyProxyNo, Type: ConfigTypeString, Description: "The comma separated hosts (with their subdomains), ips or cidrs not proxied, NO_PROXY if not set. Ignored in the project config"},
+	{Name: ConfigKeyUpdateEndpoint, Type: ConfigTypeString, Default: DefaultUpdateEndpoint, Description: "The release endpoint of op self-update, a github repository url or the url of the release manifests, see update/update.go. Ignored in the project config"},
+	{Name: ConfigKeyUpdateChannel, Type: ConfigTypeString, Default: "stable", Description: "The release channel of op self-update, stable or edge"},
+	{Name: ConfigKeyUpdatePublicKey, Type: ConfigTypeString, Description: "The base64 ed25519 public key to verify the signature of the binaries of op self-update, required unless op self-update --insecure, the key built in op (see makefile) if not set. Ignored in the project config"},
+	{Name: ConfigKeyAlias, Type: ConfigTypeString, Description: "The command alias, e.g. alias.up = build :all && start dev, see workspace/alias.go. Ignored in the project config"},
+	{Name: ConfigKeyTelemetryEnabled, Type: ConfigTypeBool, Default: "false", Description: "Record the anonymous usage metrics (commands, durations and error kinds), see op telemetry. Ignored in the project config"},
+	{Name: ConfigKeyTelemetryEndpoint, Type: ConfigTypeString, Description: "The url to post the usage metrics to, the metrics are kept locally if not set, see workspace/telemetry.go. Ignored in the project config"},
+	{Name: ConfigKeyArtifactRegistry, Type: ConfigTypeString, Description: "The url of the artifact registry of op artifact push / pull, a local directory, http(s)://, s3://, gs:// or oci://, see registry/registry.go. Ignored in the project config"},
+	{Name: ConfigKeyArtifactChannels, Type: ConfigTypeString, Default: "dev,staging,prod", Description: "The promotion channels of the artifact registry in order (comma separated), a pushed version is in the first one, see registry/channel.go"},
+	{Name: ConfigKeyArtifactSigningKey, Type: ConfigTypeString, Description: "The file of the base64 ed25519 private key (or seed) to sign the artifact provenances of the builds, not signed if not set. Ignored in the project config"},
+	{Name: ConfigKeyArtifactPublicKey, Type: ConfigTypeString, Description: "The base64 ed25519 public key to verify the artifact provenances by op artifact verify and op artifact pull. Ignored in the project config"},
+	{Name: ConfigKeyStateBackend, Type: ConfigTypeString, Default: StateBackendFile, Description: "The backend to publish the runner instances and build summaries to, file or the url of a http server. Ignored in the project config"},
+}
+
+// Get the configuration key by name (or the prefix key ends with .* matches the name), nil if not found
+func GetConfigKey(name string) *ConfigKey {
+	for i := range ConfigKeys {
+		if ConfigKeys[i].Name == name {
+			return &ConfigKeys[i]
+		}
+	}
+	for i := range ConfigKeys {
+		if prefix, ok := ConfigKeys[i].Prefix(); ok && strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return &ConfigKeys[i]
+		}
+	}
+	return nil
+}
+
+// Get the prefix of the key ends with .*, returns false if the key is not a prefix key
+func (this *ConfigKey) Prefix() (string, bool) {
+	if !strings.HasSuffix(this.Name, ".*") {
+		return "", false
+	}
+	return strings.TrimSuffix(this.Name, "*"), true
+}
+
+// Check the value is valid for the key
+func (this *ConfigKey) Check(value string) error {
+	switch this.Type {
+	case ConfigTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New(fmt.Sprintf("Invalid bool value [%s] of [%s]", value, this.Name))
+		}
+	case ConfigTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return errors.New(fmt.Sprintf("Invalid int value [%s] of [%s]", value, this.Name))
+		}
+	}
+	return nil
+}
+
+// A configuration layer (file)
+type ConfigLayer struct {
+	Name   string            // The layer name, one of ConfigLayer*
+	Path   string            // The file path
+	Values map[string]string // The values
+}
+
+// Load the configuration layer, a not existed file is an empty layer
+func LoadConfigLayer(name, path string) (*ConfigLayer, error) {
+	layer := &ConfigLayer{Name: name, Path: path, Values: make(map[string]string)}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return layer, nil
+		}
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse config file [%s], error: %s", path, err))
+	}
+	for key, value := range values {
+		layer.Values[key] = fmt.Sprint(value)
+	}
+	// Done
+	return layer, nil
+}
+
+// Set the value of the key
+func (this *ConfigLayer) Set(key, value string) error {
+	configKey := GetConfigKey(key)
+	if configKey == nil {
+		return errors.New(fmt.Sprintf("Unknown config key [%s]", key))
+	}
+	if err := configKey.Check(value); err != nil {
+		return err
+	}
+	this.Values[key] = value
+	return nil
+}
+
+// Remove the key
+func (this *ConfigLayer) Unset(key string) {
+	delete(this.Values, key)
+}
+
+// Save the layer to its file
+func (this *ConfigLayer) Save() error {
+	var keys []string
+	for key := range this.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var values yaml.MapSlice
+	for _, key := range keys {
+		values = append(values, yaml.MapItem{Key: key, Value: this.Values[key]})
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(this.Path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(this.Path, data, 0666)
+}
+
+// The layered configuration
+type Config struct {
+	Layers []*ConfigLayer // The layers from the lowest to the highest precedence
+}
+
+// Get the layer by name, nil if not found
+func (this *Config) Layer(name string) *ConfigLayer {
+	for _, layer := range this.Layers {
+		if layer.Name == name {
+			return layer
+		}
+	}
+	return nil
+}
+
+// Get the value of the key
+// Returns:
+//
+//	The value, the layer defines it (empty if it's the default value) and whether the value is found
+func (this *Config) Lookup(key string) (string, string, bool) {
+	for i := len(this.Layers) - 1; i >= 0; i-- {
+		if value, ok := this.Layers[i].Values[key]; ok {
+			return value, this.Layers[i].Name, true
+		}
+	}
+	if configKey := GetConfigKey(key); configKey != nil && configKey.Default != "" {
+		return configKey.Default, "", true
+	}
+	return "", "", false
+}
+
+// Get the keys defined in any layer with the prefix in sorted order
+func (this *Config) KeysWithPrefix(prefix string) []string {
+	found := make(map[string]bool)
+	var keys []string
+	for _, layer := range this.Layers {
+		for key := range layer.Values {
+			if strings.HasPrefix(key, prefix) && !found[key] {
+				found[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get the string value of the key, empty if not defined
+func (this *Config) GetString(key string) string {
+	value, _, _ := this.Lookup(key)
+	return value
+}
+
+// Get the bool value of the key, false if not defined or invalid
+func (this *Config) GetBool(key string) bool {
+	value, _ := strconv.ParseBool(this.GetString(key))
+	return value
+}
+
+// Get the int value of the key, 0 if not defined or invalid
+func (this *Config) GetInt(key string) int {
+	value, _ := strconv.Atoi(this.GetString(key))
+	return value
+}
+
+// Get the merged values with their sources
+func (this *Config) Layered() Layered {
+	var sources []LayerSource
+	for _, layer := range this.Layers {
+		sources = append(sources, LayerSource{Layer: layer.Name, Path: layer.Path})
+	}
+	layered, _ := MergeLayers(sources, func(source LayerSource) (map[string]interface{}, error) {
+		values := make(map[string]interface{})
+		for key, value := range this.Layer(source.Layer).Values {
+			values[key] = value
+		}
+		return values, nil
+	})
+	return layered
+}
+
+// Load the configuration of the workspace
+func (this *Workspace) loadConfig() (*Config, error) {
+	config := new(Config)
+	for _, source := range this.LayerSources(ConfigFileName, ConfigFileName, ProjectConfigFileName) {
+		layer, err := LoadConfigLayer(source.Layer, source.Path)
+		if err != nil {
+			return nil, err
+		}
+		config.Layers = append(config.Layers, layer)
+	}
+	// Done
+	return config, nil
+}
+
+// Get the value of the key ignoring the project layer, for the keys decide what op trusts or sends (e.g. the update
+// public key) which a checked out repository shouldn't set. Warns if the project layer sets the key
+// Returns:
+//
+//	The value, the layer defines it (empty if it's the default value) and whether the value is found
+func (this *Workspace) LookupUserConfig(key string) (string, string, bool) {
+	value, layer, found := this.Config.Lookup(key)
+	if layer != ConfigLayerProject {
+		return value, layer, found
+	}
+	this.Logger.LeveledPrintf(log.LevelWarn, "Ignore %s in the project config, define it in the user config instead\n", key)
+	config := new(Config)
+	for _, layer := range this.Config.Layers {
+		if layer.Name != ConfigLayerProject {
+			config.Layers = append(config.Layers, layer)
+		}
+	}
+	return config.Lookup(key)
+}
+
+// Get the string value of the key ignoring the project layer, see LookupUserConfig
+func (this *Workspace) GetUserConfigString(key string) string {
+	value, _, _ := this.LookupUserConfig(key)
+	return value
+}
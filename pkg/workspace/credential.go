@@ -0,0 +1,339 @@
+// Author: lipixun
+// Created Time : 六 10/17 02:03:37 2026
+//
+// File Name: credential.go
+// Description:
+//	The credential storage
+//
+//	The credentials (username and secret of a host) are stored in the OS keychain if available:
+//		linux 		The secret service via secret-tool (libsecret)
+//		darwin 		The login keychain via security
+//	The keychain is probed once per workspace, when it's not usable (e.g. no D-Bus session over ssh, the keychain is
+//	locked) the credentials are stored in <user>/credentials.enc which is encrypted (AES-GCM) by the key in
+//	<user>/credentials.key.
+//	Both files are only readable by the user. The key file is kept apart so that the credential file alone (e.g. in a
+//	backup) doesn't disclose the secrets.
+package workspace
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	CredentialService = "openlight" // The service name of the credentials in the OS keychain
+
+	CredentialFileName    = "credentials.enc"
+	CredentialKeyFileName = "credentials.key"
+
+	CredentialStoreSecretTool = "secret-tool"
+	CredentialStoreKeychain   = "keychain"
+	CredentialStoreFile       = "file"
+
+	credentialProbeHost = "openlight.probe" // The host looked up to probe the keychain, never stored
+
+	secretToolNotFoundCode = 1  // secret-tool lookup exits with 1 and prints nothing if not found
+	keychainNotFoundCode   = 44 // security exits with errSecItemNotFound if not found
+)
+
+// The credential of a host
+type Credential struct {
+	Host     string `json:"host"`
+	Username string `json:"username"`
+	Secret   string `json:"secret"` // The password or token
+}
+
+// The credential store
+type CredentialStore interface {
+	// The store name, one of CredentialStore*
+	Name() string
+	// Get the credential of the host, nil if not found
+	Get(host string) (*Credential, error)
+	// Add or replace the credential
+	Set(credential *Credential) error
+	// Delete the credential of the host, no error if not found
+	Delete(host string) error
+}
+
+// Get the credential store, the OS keychain if usable, otherwise the encrypted file in user workdir
+func (this *Workspace) Credentials() CredentialStore {
+	this.credentialLock.Lock()
+	defer this.credentialLock.Unlock()
+	if this.credentialStore != nil {
+		return this.credentialStore
+	}
+	var store CredentialStore
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			store = secretToolCredentialStore{}
+		}
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			store = keychainCredentialStore{}
+		}
+	}
+	if store != nil {
+		if _, err := store.Get(credentialProbeHost); err != nil {
+			this.Logger.LeveledPrintf(log.LevelWarn, "The keychain [%s] is not usable, use the encrypted credential file instead. Error: %s\n", store.Name(), err)
+			store = nil
+		}
+	}
+	if store == nil {
+		store = &fileCredentialStore{
+			path:    filepath.Join(this.Dir.User.RootPath(), CredentialFileName),
+			keyPath: filepath.Join(this.Dir.User.RootPath(), CredentialKeyFileName),
+		}
+	}
+	this.credentialStore = store
+	// Done
+	return store
+}
+
+// Run the command and get the stdout
+func runCredentialCommand(stdin string, command string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", &credentialCommandError{code: exitErr.ExitCode(), message: strings.TrimSpace(stderr.String())}
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+type credentialCommandError struct {
+	code    int
+	message string // The stderr
+}
+
+func (this *credentialCommandError) Error() string {
+	if this.message == "" {
+		return fmt.Sprintf("Exit with code %d", this.code)
+	}
+	return this.message
+}
+
+// Check the error is the exit code of not found
+func isCredentialNotFound(err error, code int, silent bool) bool {
+	cmdErr, ok := err.(*credentialCommandError)
+	return ok && cmdErr.code == code && (!silent || cmdErr.message == "")
+}
+
+func decodeCredential(host, text string) (*Credential, error) {
+	var credential Credential
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &credential); err != nil {
+		return nil, errors.New(fmt.Sprintf("Broken credential of host [%s] in keychain", host))
+	}
+	return &credential, nil
+}
+
+// The secret service store, the credential is stored as a json secret with attributes service and host
+type secretToolCredentialStore struct{}
+
+func (this secretToolCredentialStore) Name() string {
+	return CredentialStoreSecretTool
+}
+
+func (this secretToolCredentialStore) Get(host string) (*Credential, error) {
+	text, err := runCredentialCommand("", "secret-tool", "lookup", "service", CredentialService, "host", host)
+	if err != nil {
+		if isCredentialNotFound(err, secretToolNotFoundCode, true) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	return decodeCredential(host, text)
+}
+
+func (this secretToolCredentialStore) Set(credential *Credential) error {
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return err
+	}
+	_, err = runCredentialCommand(string(data), "secret-tool", "store", "--label", fmt.Sprintf("%s %s", CredentialService, credential.Host), "service", CredentialService, "host", credential.Host)
+	return err
+}
+
+func (this secretToolCredentialStore) Delete(host string) error {
+	_, err := runCredentialCommand("", "secret-tool", "clear", "service", CredentialService, "host", host)
+	return err
+}
+
+// The macOS keychain store, the credential is stored as a json generic password with service and account (host)
+type keychainCredentialStore struct{}
+
+func (this keychainCredentialStore) Name() string {
+	return CredentialStoreKeychain
+}
+
+func (this keychainCredentialStore) Get(host string) (*Credential, error) {
+	text, err := runCredentialCommand("", "security", "find-generic-password", "-s", CredentialService, "-a", host, "-w")
+	if err != nil {
+		if isCredentialNotFound(err, keychainNotFoundCode, false) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeCredential(host, text)
+}
+
+func (this keychainCredentialStore) Set(credential *Credential) error {
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return err
+	}
+	// The command is read from stdin by the interactive mode so the secret isn't in the arguments (visible by ps),
+	// the password is in hex (-X) so it needs no quoting
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", CredentialService, quoteKeychainArg(credential.Host), hex.EncodeToString(data))
+	if _, err := runCredentialCommand(command, "security", "-i"); err != nil {
+		return err
+	}
+	// The interactive mode may exit with 0 even if the command failed, read it back
+	stored, err := this.Get(credential.Host)
+	if err != nil {
+		return err
+	}
+	if stored == nil || *stored != *credential {
+		return errors.New(fmt.Sprintf("Failed to add the credential of host [%s] to the keychain", credential.Host))
+	}
+	return nil
+}
+
+func (this keychainCredentialStore) Delete(host string) error {
+	credential, err := this.Get(host)
+	if err != nil || credential == nil {
+		return err
+	}
+	_, err = runCredentialCommand("", "security", "delete-generic-password", "-s", CredentialService, "-a", host)
+	return err
+}
+
+// Quote the argument of the interactive mode of security
+func quoteKeychainArg(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// The encrypted file store
+type fileCredentialStore struct {
+	path    string
+	keyPath string
+}
+
+func (this *fileCredentialStore) Name() string {
+	return CredentialStoreFile
+}
+
+func (this *fileCredentialStore) Get(host string) (*Credential, error) {
+	credentials, err := this.load()
+	if err != nil {
+		return nil, err
+	}
+	return credentials[host], nil
+}
+
+func (this *fileCredentialStore) Set(credential *Credential) error {
+	credentials, err := this.load()
+	if err != nil {
+		return err
+	}
+	credentials[credential.Host] = credential
+	return this.save(credentials)
+}
+
+func (this *fileCredentialStore) Delete(host string) error {
+	credentials, err := this.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := credentials[host]; !ok {
+		return nil
+	}
+	delete(credentials, host)
+	return this.save(credentials)
+}
+
+// Get the cipher, the key is created if not exists
+func (this *fileCredentialStore) getCipher() (cipher.AEAD, error) {
+	key, err := ioutil.ReadFile(this.keyPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(this.keyPath, key, 0600); err != nil {
+			return nil, err
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid credential key file [%s], error: %s", this.keyPath, err))
+	}
+	return cipher.NewGCM(block)
+}
+
+func (this *fileCredentialStore) load() (map[string]*Credential, error) {
+	credentials := make(map[string]*Credential)
+	data, err := ioutil.ReadFile(this.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return credentials, nil
+		}
+		return nil, err
+	}
+	aead, err := this.getCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New(fmt.Sprintf("Broken credential file [%s]", this.path))
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Cannot decrypt credential file [%s], error: %s", this.path, err))
+	}
+	if err := json.Unmarshal(plain, &credentials); err != nil {
+		return nil, errors.New(fmt.Sprintf("Broken credential file [%s], error: %s", this.path, err))
+	}
+	return credentials, nil
+}
+
+func (this *fileCredentialStore) save(credentials map[string]*Credential) error {
+	plain, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	aead, err := this.getCipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(this.path, aead.Seal(nonce, nonce, plain, nil), 0600)
+}
@@ -0,0 +1,161 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:58:31 2026
+//
+// File Name: credential_test.go
+// Description:
+//
+package workspace
+
+import (
+	"bytes"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFileCredentialStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &fileCredentialStore{path: filepath.Join(dir, CredentialFileName), keyPath: filepath.Join(dir, CredentialKeyFileName)}
+	// Not exists
+	if credential, err := store.Get("git.corp"); err != nil || credential != nil {
+		t.Fatalf("Incorrect credential of the empty store. Actual [%v] error [%v]", credential, err)
+	}
+	if err := store.Delete("git.corp"); err != nil {
+		t.Fatalf("Failed to delete from the empty store, error: %s", err)
+	}
+	// Round trip
+	credentials := []*Credential{
+		{Host: "git.corp", Username: "ci", Secret: "p@ss\"word\n"},
+		{Host: "registry.corp", Secret: "token-0123456789"},
+	}
+	for _, credential := range credentials {
+		if err := store.Set(credential); err != nil {
+			t.Fatalf("Failed to set credential of [%s], error: %s", credential.Host, err)
+		}
+	}
+	reopened := &fileCredentialStore{path: store.path, keyPath: store.keyPath}
+	for _, credential := range credentials {
+		actual, err := reopened.Get(credential.Host)
+		if err != nil || actual == nil || *actual != *credential {
+			t.Errorf("Incorrect credential of [%s]. Expect %+v Actual %+v error [%v]", credential.Host, credential, actual, err)
+		}
+	}
+	// Encrypted and only readable by the user
+	data, err := ioutil.ReadFile(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, credential := range credentials {
+		if bytes.Contains(data, []byte(credential.Secret)) || bytes.Contains(data, []byte(credential.Host)) {
+			t.Errorf("The credential of [%s] is not encrypted", credential.Host)
+		}
+	}
+	for _, path := range []string{store.path, store.keyPath} {
+		if info, err := os.Stat(path); err != nil {
+			t.Errorf("Failed to stat [%s], error: %s", path, err)
+		} else if info.Mode().Perm() != 0600 {
+			t.Errorf("Incorrect mode of [%s]. Expect 0600 Actual %v", path, info.Mode().Perm())
+		}
+	}
+	// Replace and delete
+	if err := store.Set(&Credential{Host: "git.corp", Username: "ci", Secret: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("registry.corp"); err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := store.Get("git.corp"); err != nil || actual == nil || actual.Secret != "new" {
+		t.Errorf("Incorrect replaced credential %+v error [%v]", actual, err)
+	}
+	if actual, err := store.Get("registry.corp"); err != nil || actual != nil {
+		t.Errorf("Incorrect deleted credential %+v error [%v]", actual, err)
+	}
+	// The file is not decrypted by another key
+	if err := ioutil.WriteFile(store.keyPath, bytes.Repeat([]byte{1}, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("git.corp"); err == nil || !strings.Contains(err.Error(), "Cannot decrypt") {
+		t.Errorf("Incorrect error of the wrong key. Actual [%v]", err)
+	}
+	// The broken key and file
+	if err := ioutil.WriteFile(store.keyPath, []byte("short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("git.corp"); err == nil || !strings.Contains(err.Error(), "Invalid credential key file") {
+		t.Errorf("Incorrect error of the broken key. Actual [%v]", err)
+	}
+	if err := os.Remove(store.keyPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(store.path, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("git.corp"); err == nil || !strings.Contains(err.Error(), "Broken credential file") {
+		t.Errorf("Incorrect error of the broken file. Actual [%v]", err)
+	}
+}
+
+func TestCredentialsFallback(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("The fake secret-tool is a shell script")
+	}
+	binDir, err := ioutil.TempDir("", "credential-bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(binDir)
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", binDir)
+
+	for _, tCase := range []struct {
+		Name   string
+		Script string // The fake secret-tool, not installed if empty
+		Store  string
+	}{
+		{Name: "no secret-tool", Store: CredentialStoreFile},
+		{Name: "usable", Script: "#!/bin/sh\nexit 1\n", Store: CredentialStoreSecretTool},
+		{Name: "no session", Script: "#!/bin/sh\necho 'Cannot autolaunch D-Bus without X11 $DISPLAY' >&2\nexit 1\n", Store: CredentialStoreFile},
+		{Name: "locked", Script: "#!/bin/sh\necho 'Cannot get secret of a locked object' >&2\nexit 2\n", Store: CredentialStoreFile},
+	} {
+		os.Remove(filepath.Join(binDir, "secret-tool"))
+		if tCase.Script != "" {
+			if err := ioutil.WriteFile(filepath.Join(binDir, "secret-tool"), []byte(tCase.Script), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		func() {
+			ws, logger, cleanup := newTestWorkspace(t)
+			defer cleanup()
+			store := ws.Credentials()
+			if store.Name() != tCase.Store {
+				t.Errorf("Incorrect store of case [%s]. Expect [%s] Actual [%s]", tCase.Name, tCase.Store, store.Name())
+			}
+			if ws.Credentials() != store {
+				t.Errorf("The store of case [%s] is probed again", tCase.Name)
+			}
+			warned := logger.Contains(log.LevelWarn, "is not usable")
+			if warned != (tCase.Script != "" && tCase.Store == CredentialStoreFile) {
+				t.Errorf("Incorrect warning of case [%s]. Actual %v", tCase.Name, logger.Entries())
+			}
+			if store.Name() != CredentialStoreFile {
+				return
+			}
+			// The fallback store works in the user workdir
+			if err := store.Set(&Credential{Host: "git.corp", Secret: "secret"}); err != nil {
+				t.Errorf("Failed to set credential of case [%s], error: %s", tCase.Name, err)
+			}
+			if _, err := os.Stat(filepath.Join(ws.Dir.User.RootPath(), CredentialFileName)); err != nil {
+				t.Errorf("No credential file of case [%s], error: %s", tCase.Name, err)
+			}
+		}()
+	}
+}
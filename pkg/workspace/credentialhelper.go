@@ -0,0 +1,182 @@
+// Author: lipixun
+// Created Time : 六 10/17 17:32:19 2026
+//
+// File Name: credentialhelper.go
+// Description:
+//	The credential helpers
+//
+//	A credential helper is an external command which gets the credential of a host on demand (e.g. from vault or an
+//	sso login), configured per host by the config key credential.helpers, e.g.
+//		credential.helpers: git.internal=vault-credential,*=sso-token --quiet
+//	The host * matches the hosts not listed. Only the global and user config can define the helpers, the helpers in
+//	the project config are ignored since the project may come from anywhere.
+//
+//	The helper follows the git credential helper protocol, so the same command works as the credential helper of git
+//	(the repository fetcher passes it to git for the http(s) remotes):
+//		<command> get
+//	with the stdin
+//		protocol=https
+//		host=<host>
+//	and prints
+//		username=<username>
+//		password=<password or token>
+//	The credential without username is sent as a bearer token by the http fetchers. The helper prints nothing if it
+//	has no credential of the host, then the credential store is used.
+//
+//	The other way round, the credential store (see credential.go) is served to git by op credential get, which the
+//	repository fetcher passes to git after the configured helper, so the credentials stored by op login are used by
+//	git as well. Only get is answered, store and erase are ignored since the credentials are managed by op login.
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	CredentialHelperTimeout = 2 * time.Minute // The helper may wait for a login
+	CredentialHelperAnyHost = "*"
+
+	CredentialHelperActionGet = "get"
+)
+
+// Get the credential helper command of the host, empty if not configured
+func (this *Workspace) GetCredentialHelper(host string) string {
+	value := this.GetUserConfigString(ConfigKeyCredentialHelpers)
+	if value == "" {
+		return ""
+	}
+	helpers := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		if idx := strings.Index(item, "="); idx != -1 {
+			helpers[strings.TrimSpace(item[:idx])] = strings.TrimSpace(item[idx+1:])
+		}
+	}
+	names := []string{host}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		names = append(names, h)
+	}
+	for _, name := range append(names, CredentialHelperAnyHost) {
+		if command, ok := helpers[name]; ok {
+			return command
+		}
+	}
+	return ""
+}
+
+// Get the credential of the host by the credential helper, nil if no helper or the helper has no credential
+// The credentials are cached in the workspace, the helper is called at most once for each host
+func (this *Workspace) GetHelperCredential(host string) (*Credential, error) {
+	command := this.GetCredentialHelper(host)
+	if command == "" {
+		return nil, nil
+	}
+	this.credentialLock.Lock()
+	defer this.credentialLock.Unlock()
+	if credential, ok := this.helperCredentials[host]; ok {
+		return credential, nil
+	}
+	this.Logger.LeveledPrintf(log.LevelDebug, "Get credential of [%s] by helper [%s]\n", host, command)
+	credential, err := runCredentialHelper(command, host)
+	if err != nil {
+		return nil, err
+	}
+	if this.helperCredentials == nil {
+		this.helperCredentials = make(map[string]*Credential)
+	}
+	this.helperCredentials[host] = credential
+	// Done
+	return credential, nil
+}
+
+// Get the credential of the host by the credential helper, then the credential store. Nil if not found
+func (this *Workspace) GetCredential(host string) (*Credential, error) {
+	credential, err := this.GetHelperCredential(host)
+	if err != nil || credential != nil {
+		return credential, err
+	}
+	return this.Credentials().Get(host)
+}
+
+// Run the credential helper, the stderr is passed through to show the prompts of the helper
+func runCredentialHelper(command, host string) (*Credential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), CredentialHelperTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command+" get")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, errors.New(fmt.Sprintf("Credential helper [%s] of host [%s] failed, error: %s", command, host, err))
+	}
+	credential := &Credential{Host: host}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			continue
+		}
+		switch line[:idx] {
+		case "username":
+			credential.Username = line[idx+1:]
+		case "password":
+			credential.Secret = line[idx+1:]
+		}
+	}
+	if credential.Secret == "" {
+		return nil, nil
+	}
+	return credential, nil
+}
+
+// Serve the credential store as a git credential helper: read the request of the action from the reader and write
+// the stored credential of the host to the writer. Nothing is written if not found or the action is not get
+func (this *Workspace) ServeCredentialHelper(action string, reader io.Reader, writer io.Writer) error {
+	if action != CredentialHelperActionGet {
+		return nil
+	}
+	var host string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "host=") {
+			host = strings.TrimPrefix(line, "host=")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if host == "" {
+		return errors.New("Require the host in the credential request")
+	}
+	credential, err := this.Credentials().Get(host)
+	if err != nil || credential == nil {
+		return err
+	}
+	if strings.ContainsAny(credential.Username+credential.Secret, "\n\x00") {
+		return errors.New(fmt.Sprintf("Invalid credential of host [%s], has a new line", host))
+	}
+	if credential.Username != "" {
+		fmt.Fprintf(writer, "username=%s\n", credential.Username)
+	}
+	_, err = fmt.Fprintf(writer, "password=%s\n", credential.Secret)
+	return err
+}
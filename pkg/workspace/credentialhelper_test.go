@@ -0,0 +1,109 @@
+// Author: lipixun
+// Created Time : 五 10/16 15:58:21 2026
+//
+// File Name: credentialhelper_test.go
+// Description:
+//
+package workspace
+
+import (
+	"bytes"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	credentialHelperCases = []struct {
+		Name    string
+		User    string // The credential.helpers of the user config
+		Project string // The credential.helpers of the project config
+		Host    string
+		Helper  string
+		Warning bool
+	}{
+		{Name: "none", Host: "git.internal"},
+		{Name: "host", User: "git.internal=vault-credential,*=sso-token --quiet", Host: "git.internal", Helper: "vault-credential"},
+		{Name: "host without port", User: "git.internal=vault-credential", Host: "git.internal:8443", Helper: "vault-credential"},
+		{Name: "any host", User: "git.internal=vault-credential,*=sso-token --quiet", Host: "github.com", Helper: "sso-token --quiet"},
+		{Name: "no match", User: "git.internal=vault-credential", Host: "github.com"},
+		{Name: "project only", Project: "*=steal-token", Host: "github.com", Warning: true},
+		{
+			Name:    "project overriding user",
+			User:    "*=sso-token",
+			Project: "*=steal-token",
+			Host:    "github.com",
+			Helper:  "sso-token",
+			Warning: true,
+		},
+	}
+	serveCredentialHelperCases = []struct {
+		Name    string
+		Action  string
+		Request string
+		Output  string
+		Error   string
+	}{
+		{Name: "get", Action: "get", Request: "protocol=https\nhost=git.corp\n\n", Output: "username=ci\npassword=secret\n"},
+		{Name: "get token", Action: "get", Request: "protocol=https\nhost=registry.corp:8443\npath=org/repo\n\n", Output: "password=token\n"},
+		{Name: "not found", Action: "get", Request: "protocol=https\nhost=github.com\n\n"},
+		{Name: "store", Action: "store", Request: "protocol=https\nhost=git.corp\nusername=a\npassword=b\n\n"},
+		{Name: "erase", Action: "erase", Request: "protocol=https\nhost=git.corp\n\n"},
+		{Name: "no host", Action: "get", Request: "protocol=https\n\nhost=git.corp\n", Error: "Require the host"},
+		{Name: "new line", Action: "get", Request: "host=broken.corp\n", Error: "has a new line"},
+	}
+)
+
+func TestGetCredentialHelper(t *testing.T) {
+	for _, tCase := range credentialHelperCases {
+		func() {
+			ws, logger, cleanup := newTestWorkspace(t)
+			defer cleanup()
+			if tCase.User != "" {
+				ws.Config.Layer(ConfigLayerUser).Values[ConfigKeyCredentialHelpers] = tCase.User
+			}
+			if tCase.Project != "" {
+				ws.Config.Layer(ConfigLayerProject).Values[ConfigKeyCredentialHelpers] = tCase.Project
+			}
+			if helper := ws.GetCredentialHelper(tCase.Host); helper != tCase.Helper {
+				t.Errorf("Incorrect helper of case [%s]. Expect [%s] Actual [%s]", tCase.Name, tCase.Helper, helper)
+			}
+			if warned := logger.Contains(log.LevelWarn, "Ignore "+ConfigKeyCredentialHelpers); warned != tCase.Warning {
+				t.Errorf("Incorrect warning of case [%s]. Expect %v Actual %v", tCase.Name, tCase.Warning, warned)
+			}
+		}()
+	}
+}
+
+func TestServeCredentialHelper(t *testing.T) {
+	ws, _, cleanup := newTestWorkspace(t)
+	defer cleanup()
+	ws.credentialStore = &fileCredentialStore{
+		path:    filepath.Join(ws.Dir.User.RootPath(), CredentialFileName),
+		keyPath: filepath.Join(ws.Dir.User.RootPath(), CredentialKeyFileName),
+	}
+	for _, credential := range []*Credential{
+		{Host: "git.corp", Username: "ci", Secret: "secret"},
+		{Host: "registry.corp:8443", Secret: "token"},
+		{Host: "broken.corp", Username: "ci", Secret: "secret\nhost=github.com"},
+	} {
+		if err := ws.credentialStore.Set(credential); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tCase := range serveCredentialHelperCases {
+		var output bytes.Buffer
+		err := ws.ServeCredentialHelper(tCase.Action, strings.NewReader(tCase.Request), &output)
+		if tCase.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tCase.Error) {
+				t.Errorf("Incorrect error of case [%s]. Expect [%s] Actual [%v]", tCase.Name, tCase.Error, err)
+			}
+		} else if err != nil {
+			t.Errorf("Failed to serve case [%s], error: %s", tCase.Name, err)
+		}
+		if output.String() != tCase.Output {
+			t.Errorf("Incorrect output of case [%s]. Expect [%q] Actual [%q]", tCase.Name, tCase.Output, output.String())
+		}
+	}
+}
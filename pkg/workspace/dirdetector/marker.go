@@ -0,0 +1,59 @@
+// Author: lipixun
+// Created Time : 六 10/17 00:58:26 2026
+//
+// File Name: marker.go
+// Description:
+//	Detect the project directory by walking up from the path until a directory containing a marker is found
+//
+//	The markers:
+//		.op.yaml 				The project marker
+//		.op.sourcecode.yaml 	The sourcecode spec file
+//		.op.runner.yaml 		The runner spec file
+//		.op.config.yaml 		The project config file
+//		.git 					The git repository (either a directory or a file of a worktree / submodule)
+//
+//	The nearest directory wins, so a subdirectory with its own spec file is a project of its own
+package dirdetector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ProjectMarkers = []string{
+		".op.yaml",
+		".op.sourcecode.yaml",
+		".op.runner.yaml",
+		".op.config.yaml",
+		".git",
+	}
+)
+
+type MarkerDirDetector struct {
+	Markers []string
+}
+
+func newMarkerDirDetector() MarkerDirDetector {
+	return MarkerDirDetector{Markers: ProjectMarkers}
+}
+
+func (this MarkerDirDetector) Detect(p string) (string, error) {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	for dir := p; ; dir = filepath.Dir(dir) {
+		for _, marker := range this.Markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, nil
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return "", errors.New(fmt.Sprintf("No project marker found in [%s] or its parents", p))
+}
@@ -8,7 +8,7 @@ package dirdetector
 
 var (
 	Detectors map[string]DirDetector = map[string]DirDetector{
-		"git": newGitDirDetector(),
+		"marker": newMarkerDirDetector(),
 	}
 )
 
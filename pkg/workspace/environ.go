@@ -0,0 +1,172 @@
+// Author: lipixun
+// Created Time : 六 10/17 05:40:19 2026
+//
+// File Name: environ.go
+// Description:
+//	The environment snapshot
+//
+//	A snapshot of the environment facts is captured for each build and run, the latest one is kept in
+//	<user>/environment.json and each change is appended to <user>/environment.log (one json object per line), so
+//	it's possible to tell what changed on this machine between two builds.
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	EnvironmentFileName    = "environment.json"
+	EnvironmentLogFileName = "environment.log"
+
+	ToolchainTimeout  = 5 * time.Second
+	ToolchainNotFound = "not found"
+)
+
+// The toolchains to detect, the first line of the output is the version
+var Toolchains = []struct {
+	Name    string
+	Command string
+	Args    []string
+}{
+	{"go", "go", []string{"version"}},
+	{"python", "python", []string{"--version"}},
+	{"docker", "docker", []string{"version", "--format", "{{.Client.Version}}"}},
+	{"git", "git", []string{"--version"}},
+}
+
+// The environment snapshot
+type EnvironmentSnapshot struct {
+	Time       time.Time         `json:"time"`
+	OS         string            `json:"os"`
+	Arch       string            `json:"arch"`
+	Hostname   string            `json:"hostname"`
+	Toolchains map[string]string `json:"toolchains"` // The version of each toolchain, key is the toolchain name
+	PathHash   string            `json:"pathHash"`   // The sha256 of the PATH environment variable
+}
+
+// Capture the snapshot of current environment
+func CaptureEnvironment() *EnvironmentSnapshot {
+	snapshot := &EnvironmentSnapshot{
+		Time:       time.Now(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Toolchains: make(map[string]string),
+	}
+	snapshot.Hostname, _ = os.Hostname()
+	for _, toolchain := range Toolchains {
+		snapshot.Toolchains[toolchain.Name] = GetToolchainVersion(toolchain.Command, toolchain.Args...)
+	}
+	hash := sha256.Sum256([]byte(os.Getenv("PATH")))
+	snapshot.PathHash = hex.EncodeToString(hash[:])
+	return snapshot
+}
+
+// Get the differences from the other snapshot, e.g. "go: go1.8 --> go1.9"
+func (this *EnvironmentSnapshot) Diff(other *EnvironmentSnapshot) []string {
+	var diffs []string
+	add := func(name, from, to string) {
+		if from != to {
+			diffs = append(diffs, fmt.Sprintf("%s: %s --> %s", name, from, to))
+		}
+	}
+	add("os", other.OS, this.OS)
+	add("arch", other.Arch, this.Arch)
+	add("hostname", other.Hostname, this.Hostname)
+	var names []string
+	for name := range this.Toolchains {
+		names = append(names, name)
+	}
+	for name := range other.Toolchains {
+		if _, ok := this.Toolchains[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, other.Toolchains[name], this.Toolchains[name])
+	}
+	add("PATH", other.PathHash, this.PathHash)
+	return diffs
+}
+
+// Get the first line of the version output of the toolchain
+func GetToolchainVersion(command string, args ...string) string {
+	if _, err := exec.LookPath(command); err != nil {
+		return ToolchainNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ToolchainTimeout)
+	defer cancel()
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Sprintf("unknown (%s)", err)
+	}
+	return strings.SplitN(strings.TrimSpace(output.String()), "\n", 2)[0]
+}
+
+// Capture the environment snapshot and record it as the latest one of the workspace, the changes since the last
+// snapshot are logged
+func (this *Workspace) RecordEnvironment() *EnvironmentSnapshot {
+	snapshot := CaptureEnvironment()
+	diffs, err := this.saveEnvironment(snapshot)
+	if err != nil {
+		this.Logger.LeveledPrintf(log.LevelWarn, "Failed to record environment snapshot, error: %s\n", err)
+	}
+	for _, diff := range diffs {
+		this.Logger.LeveledPrintf(log.LevelInfo, "Environment changed since last snapshot: %s\n", diff)
+	}
+	return snapshot
+}
+
+// Save the snapshot as the latest one and append it to the history if changed
+// Returns:
+//
+//	The differences from the last snapshot
+func (this *Workspace) saveEnvironment(snapshot *EnvironmentSnapshot) ([]string, error) {
+	path := filepath.Join(this.Dir.User.RootPath(), EnvironmentFileName)
+	var diffs []string
+	changed := true
+	if data, err := ioutil.ReadFile(path); err == nil {
+		var last EnvironmentSnapshot
+		if err := json.Unmarshal(data, &last); err == nil {
+			diffs = snapshot.Diff(&last)
+			changed = len(diffs) > 0
+		}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return diffs, err
+	}
+	if err := ioutil.WriteFile(path, data, 0666); err != nil {
+		return diffs, err
+	}
+	// Append the history if changed
+	if changed {
+		file, err := os.OpenFile(filepath.Join(this.Dir.User.RootPath(), EnvironmentLogFileName), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+		if err != nil {
+			return diffs, err
+		}
+		defer file.Close()
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			return diffs, err
+		}
+	}
+	// Done
+	return diffs, nil
+}
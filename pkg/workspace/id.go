@@ -0,0 +1,101 @@
+// Author: lipixun
+// Created Time : 六 10/17 05:12:27 2026
+//
+// File Name: id.go
+// Description:
+//	The id and counter service
+//
+//	The counters are persisted in the user workdir and are safe to be used by concurrent processes:
+//		<user>/counters/
+//			<name> 			The last value of the counter
+//			<name>.lock 	The lock file, locked exclusively when increasing the counter
+package workspace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	CountersDirName = "counters"
+
+	RandomIDLength = 8 // The random id length in bytes
+)
+
+var (
+	counterNameRegularExp = regexp.MustCompile("[^a-zA-Z\\d\\.\\-_]")
+)
+
+// Create a random id which is not used in the directory, the directory of the id is created to reserve it
+// Returns:
+// 	The id, the path of the id is <dir>/<id>
+func NewRandomID(dir string) (string, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	for {
+		idBytes := make([]byte, RandomIDLength)
+		if _, err := rand.Read(idBytes); err != nil {
+			return "", err
+		}
+		id := hex.EncodeToString(idBytes)
+		// Mkdir fails if exists, so the id is never reserved twice
+		if err := os.Mkdir(filepath.Join(dir, id), os.ModePerm); err == nil {
+			return id, nil
+		} else if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
+// Increase the counter and get the new value, the first value is 1
+// Parameters:
+// 	name 	The counter name, e.g. build.<target key>. Chars except letters, digits, dot, dash and underscore are replaced by underscore
+func (this *Workspace) NextCounter(name string) (int64, error) {
+	if name == "" {
+		return 0, errors.New("Require counter name")
+	}
+	dir, err := this.Dir.User.GetPath(CountersDirName)
+	if err != nil {
+		return 0, err
+	}
+	path := filepath.Join(dir, counterNameRegularExp.ReplaceAllString(name, "_"))
+	// Lock
+	lockFile, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return 0, err
+	}
+	defer lockFile.Close()
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		return 0, err
+	}
+	defer syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+	// Read and increase
+	var value int64
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if value, err = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err != nil {
+			return 0, errors.New(fmt.Sprintf("Broken counter file [%s]", path))
+		}
+	} else if !os.IsNotExist(err) {
+		return 0, err
+	}
+	value++
+	// Write atomically
+	if err := ioutil.WriteFile(path+".tmp", []byte(strconv.FormatInt(value, 10)), 0666); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return 0, err
+	}
+	// Done
+	return value, nil
+}
@@ -0,0 +1,122 @@
+// Author: lipixun
+// Created Time : 六 10/17 04:40:12 2026
+//
+// File Name: layer.go
+// Description:
+//	The layered config files
+//
+//	A config (e.g. the workspace config, the runner apps) may be defined in the files of three layers, in the order
+//	of precedence from low to high:
+//		global 		A file in the global workdir
+//		user 		A file in the user workdir
+//		project 	A file in the project directory
+//
+//	The merge semantics: each file is a map, a key defined in a higher layer replaces the whole value of the key in
+//	the lower layers (values are never merged deeply), keys only defined in lower layers are kept. A missing file is
+//	an empty layer. The layer and file supplying each effective value are recorded to explain the result.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// A config file of a layer
+type LayerSource struct {
+	Layer string // The layer name, one of ConfigLayer*
+	Path  string // The file path
+}
+
+func (this LayerSource) String() string {
+	return fmt.Sprintf("%s:%s", this.Layer, this.Path)
+}
+
+// An effective value
+type LayeredValue struct {
+	Key        string
+	Value      interface{}
+	Source     LayerSource   // The source supplying the value
+	Overridden []LayerSource // The lower sources also defining the key, from high to low
+}
+
+// The merged values, key is the config key
+type Layered map[string]*LayeredValue
+
+// Get the sources of the three layers
+// Parameters:
+// 	global 		The file path relative to the global workdir
+// 	user 		The file path relative to the user workdir
+// 	project 	The file path relative to the project directory
+func (this *Workspace) LayerSources(global, user, project string) []LayerSource {
+	return []LayerSource{
+		{ConfigLayerGlobal, filepath.Join(this.Dir.Global.RootPath(), global)},
+		{ConfigLayerUser, filepath.Join(this.Dir.User.RootPath(), user)},
+		{ConfigLayerProject, filepath.Join(this.Dir.Project.RootPath(), project)},
+	}
+}
+
+// Get the sources of the global and user layers, for the files decide what op trusts which a checked out
+// repository shouldn't define (see LookupUserConfig)
+func (this *Workspace) UserLayerSources(global, user string) []LayerSource {
+	return []LayerSource{
+		{ConfigLayerGlobal, filepath.Join(this.Dir.Global.RootPath(), global)},
+		{ConfigLayerUser, filepath.Join(this.Dir.User.RootPath(), user)},
+	}
+}
+
+// Merge the layers, see the file description
+// Parameters:
+// 	sources 	The sources in the order of precedence from low to high
+// 	load 		Load the values of the source, only called if the file exists
+// Returns:
+// 	The merged values and the errors of the sources failed to load (these sources are ignored)
+func MergeLayers(sources []LayerSource, load func(source LayerSource) (map[string]interface{}, error)) (Layered, []error) {
+	layered := make(Layered)
+	var errs []error
+	for _, source := range sources {
+		if _, err := os.Stat(source.Path); err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		values, err := load(source)
+		if err != nil {
+			errs = append(errs, errors.New(fmt.Sprintf("Failed to load [%s], error: %s", source.Path, err)))
+			continue
+		}
+		for key, value := range values {
+			layeredValue := &LayeredValue{Key: key, Value: value, Source: source}
+			if lower := layered[key]; lower != nil {
+				layeredValue.Overridden = append([]LayerSource{lower.Source}, lower.Overridden...)
+			}
+			layered[key] = layeredValue
+		}
+	}
+	return layered, errs
+}
+
+// Get the sorted keys
+func (this Layered) Keys() []string {
+	var keys []string
+	for key := range this {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Write the source of each effective value
+func (this Layered) Explain(writer io.Writer) {
+	for _, key := range this.Keys() {
+		value := this[key]
+		fmt.Fprintf(writer, "%s\tfrom %s\n", key, value.Source)
+		for _, source := range value.Overridden {
+			fmt.Fprintf(writer, "\toverrides %s\n", source)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Author: lipixun
+// Created Time : 六 10/17 01:12:40 2026
+//
+// File Name: migration.go
+// Description:
+//	The user workdir layout versioning and migration
+//
+//	The layout version is stored in <user>/version. A workdir without the version file is version 0 if it has any data
+//	(created by an old op), or is initialized as the current version if it's empty.
+//
+//	When an older workdir is opened, the migrations after its version are applied in order and the version file is
+//	updated after each one, so an interrupted migration continues from where it stopped. A workdir of a newer version
+//	is refused since this op doesn't know its layout.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The current layout version of the user workdir
+	WorkDirVersion = 2
+
+	WorkDirVersionFileName = "version"
+)
+
+// A migration upgrades the user workdir from Version-1 to Version
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func(ws *Workspace) error
+}
+
+// The migrations sorted by version, the last one should be WorkDirVersion
+var Migrations = []Migration{
+	{Version: 1, Description: "Add the version file", Migrate: func(ws *Workspace) error { return nil }},
+	{Version: 2, Description: "Remove the test cache replaced by the workspace cache", Migrate: func(ws *Workspace) error {
+		return os.RemoveAll(filepath.Join(ws.Dir.User.RootPath(), "sourcecode", "tester", "cache"))
+	}},
+}
+
+// Get the layout version of the user workdir
+func (this *Workspace) getWorkDirVersion() (int, error) {
+	path := filepath.Join(this.Dir.User.RootPath(), WorkDirVersionFileName)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return 0, err
+		}
+		// No version file, check if the workdir is empty
+		infos, err := ioutil.ReadDir(this.Dir.User.RootPath())
+		if err != nil {
+			return 0, err
+		}
+		if len(infos) == 0 {
+			return WorkDirVersion, this.setWorkDirVersion(WorkDirVersion)
+		}
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("Invalid version file [%s]", path))
+	}
+	return version, nil
+}
+
+func (this *Workspace) setWorkDirVersion(version int) error {
+	path := filepath.Join(this.Dir.User.RootPath(), WorkDirVersionFileName)
+	return ioutil.WriteFile(path, []byte(fmt.Sprintf("%d\n", version)), 0666)
+}
+
+// Migrate the user workdir to the current version
+func (this *Workspace) migrateWorkDir() error {
+	version, err := this.getWorkDirVersion()
+	if err != nil {
+		return err
+	}
+	if version > WorkDirVersion {
+		return errors.New(fmt.Sprintf(
+			"User workdir [%s] is version %d which is newer than the supported version %d. Please upgrade op, or use another workdir by --workspace or --workdir-user-path",
+			this.Dir.User.RootPath(), version, WorkDirVersion,
+		))
+	}
+	for _, migration := range Migrations {
+		if migration.Version <= version {
+			continue
+		}
+		this.Logger.LeveledPrintf(log.LevelInfo, "Migrate user workdir to version %d: %s\n", migration.Version, migration.Description)
+		if err := migration.Migrate(this); err != nil {
+			return errors.New(fmt.Sprintf("Failed to migrate user workdir [%s] to version %d, error: %s", this.Dir.User.RootPath(), migration.Version, err))
+		}
+		if err := this.setWorkDirVersion(migration.Version); err != nil {
+			return err
+		}
+	}
+	// Done
+	return nil
+}
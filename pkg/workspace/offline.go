@@ -0,0 +1,33 @@
+// Author: lipixun
+// Created Time : 六 10/17 16:41:05 2026
+//
+// File Name: offline.go
+// Description:
+//	The offline mode
+//
+//	The offline mode is enabled by the --offline flag, the OP_OFFLINE environment variable or the offline config.
+//	In offline mode the fetchers never access the network: the remote repositories are checked out from the local
+//	clones (at the commits locked in op.lock if any) and the remote files are served from the fetch cache. The
+//	resources not available locally are reported by OfflineError, the graph collects them to report all the missing
+//	dependencies at once.
+package workspace
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/errors"
+)
+
+// The error of a remote resource which is not available offline
+type OfflineError struct {
+	Resource string // The url of the remote repository or file, with the ref if any
+}
+
+func (this *OfflineError) Error() string {
+	return fmt.Sprintf("[%s] is not available offline, fetch it once with network access", this.Resource)
+}
+
+// Check if the error (or its cause) is an OfflineError
+func IsOfflineError(err error) bool {
+	_, ok := errors.Cause(err).(*OfflineError)
+	return ok
+}
@@ -6,17 +6,45 @@
 //  The workspace options
 package workspace
 
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"os"
+)
+
 const (
 	DefaultGlobalDirPath = "/var/run/openlight"
 	DefaultUserDirPath   = "~/.openlight"
 
 	DefaultDockerServiceUri = "unix:///var/run/docker.sock"
+
+	DefaultWorkspaceName = "default"
+	WorkspaceEnvName     = "OP_WORKSPACE" // The environment variable of the workspace name
+	WorkspacesDirName    = "workspaces"   // The user workdir of a named workspace is <user>/workspaces/<name>
+	OfflineEnvName       = "OP_OFFLINE"   // The environment variable of the offline mode, e.g. 1
+
+	LogLevelEnvName  = "OP_LOG_LEVEL" // The environment variable of the log level, e.g. debug
+	LogLevelsEnvName = "OP_LOG"       // The environment variable of the log levels of headers, e.g. Runner=debug,Builder=warn
+
+	LogSystemSyslog  = "syslog"
+	LogSystemJournal = "journal"
+	LogSystemTag     = "op" // The program name in system log
 )
 
 type WorkspaceOptions struct {
+	Name         string              // The workspace name, each named workspace has its own user workdir (runner state, caches and config). The default workspace if empty
 	Dir          WorkDirOptions      // The directory of workspace options
 	Verbose      bool                // Show the verbose
 	EnableColor  bool                // Enable the color of the log
+	LogFormat    string              // The log format, text or json
+	LogTimestamp bool                // Prefix the time and level of each log line in text format
+	LogFile      bool                // Mirror all log to <user>/logs/op.log
+	LogLevel     string              // The log level name (e.g. debug, warn), overwrites the OP_LOG_LEVEL environment variable and verbose
+	LogLevels    map[string]int      // The log levels of headers, see log.ParseHeaderLevels. Merged with the OP_LOG environment variable
+	LogCaller    bool                // Show the caller (file:line) of the debug log
+	LogRateLimit int                 // The max number of identical log messages per second, unlimited if not positive
+	LogSystem    string              // Send the log (info level and above) to the system log, either syslog or journal. Disabled if empty
+	Offline      bool                // Serve the remote repositories and files from the caches only, see offline.go
+	Refresh      bool                // Refresh the cached metadata of the remote repositories regardless of the ttl
 	ThirdService ThirdServiceOptions // The third party options
 }
 
@@ -24,6 +52,9 @@ func NewWorkspaceOptions() *WorkspaceOptions {
 	options := new(WorkspaceOptions)
 	options.Dir.GlobalPath = DefaultGlobalDirPath
 	options.Dir.UserPath = DefaultUserDirPath
+	options.LogFormat = log.FormatText
+	options.LogFile = true
+	options.EnableColor = log.DetectColor(os.Stderr)
 	options.ThirdService.Docker.Uri = DefaultDockerServiceUri
 	// Done
 	return options
@@ -34,6 +65,7 @@ type WorkDirOptions struct {
 	UserPath                 string
 	ProjectPath              string
 	CurrentPathAsProjectPath bool
+	CurrentPath              string // The path to use as the current path (to detect the project path), os.Getwd if empty
 }
 
 type ThirdServiceOptions struct {
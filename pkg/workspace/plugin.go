@@ -0,0 +1,282 @@
+// Author: lipixun
+// Created Time : 六 10/17 03:20:16 2026
+//
+// File Name: plugin.go
+// Description:
+//	The plugins
+//
+//	The plugins are installed in the user workdir:
+//		<user>/plugins/
+//			<name>/
+//				plugin.yaml 	The plugin spec (name, version, type, description and command)
+//				install.json 	The install metadata (source, version and time)
+//				...				The plugin files
+//
+//	The plugin types:
+//		command 	A CLI extension, run by op plugin run <name> or op <name>
+//	There's no builder or rule library plugin, the builders are built in (see sourcecode/builder).
+//
+//	An unknown subcommand op <command> is dispatched to the external command (see FindExternalCommand), searched in order:
+//		The command plugin named <command>
+//		The executable <user>/plugins/op-<command>
+//		The executable op-<command> on PATH
+//	The workspace context is passed by the environment variables (see PluginEnviron).
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/util"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	PluginsDirName        = "plugins"
+	PluginSpecFileName    = "plugin.yaml"
+	PluginInstallFileName = "install.json"
+
+	PluginCommandPrefix = "op-" // The name prefix of the external command executables
+
+	PluginWorkDirGlobalEnvName  = "OP_WORKDIR_GLOBAL"
+	PluginWorkDirUserEnvName    = "OP_WORKDIR_USER"
+	PluginWorkDirProjectEnvName = "OP_WORKDIR_PROJECT"
+	PluginPathEnvName           = "OP_PLUGIN_PATH" // The plugin directory, only set for the installed plugins
+
+	PluginTypeCommand = "command"
+)
+
+var (
+	pluginNameRegularExp = regexp.MustCompile("^[a-zA-Z\\d][a-zA-Z\\d_\\-\\.]*$")
+)
+
+// The plugin
+type Plugin struct {
+	Name        string         `yaml:"name"`        // The plugin name
+	Version     string         `yaml:"version"`     // The plugin version
+	Type        string         `yaml:"type"`        // The plugin type, one of PluginType*
+	Description string         `yaml:"description"` // The description
+	Command     string         `yaml:"command"`     // The executable (relative to the plugin directory) of command plugin
+	Install     *PluginInstall `yaml:"-"`           // The install metadata, nil if not installed
+	path        string
+}
+
+// The install metadata of a plugin
+type PluginInstall struct {
+	Source  string    `json:"source"`  // The install source
+	Version string    `json:"version"` // The installed version
+	Time    time.Time `json:"time"`    // The install time
+}
+
+// Load the plugin from the directory
+func LoadPlugin(path string) (*Plugin, error) {
+	data, err := ioutil.ReadFile(filepath.Join(path, PluginSpecFileName))
+	if err != nil {
+		return nil, err
+	}
+	var plugin Plugin
+	if err := yaml.Unmarshal(data, &plugin); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse plugin spec in [%s], error: %s", path, err))
+	}
+	plugin.path = path
+	if err := plugin.Check(); err != nil {
+		return nil, err
+	}
+	// Load the install metadata
+	if data, err := ioutil.ReadFile(filepath.Join(path, PluginInstallFileName)); err == nil {
+		var install PluginInstall
+		if err := json.Unmarshal(data, &install); err == nil {
+			plugin.Install = &install
+		}
+	}
+	// Done
+	return &plugin, nil
+}
+
+// Check the plugin spec
+func (this *Plugin) Check() error {
+	if !pluginNameRegularExp.MatchString(this.Name) {
+		return errors.New(fmt.Sprintf("Invalid plugin name [%s]", this.Name))
+	}
+	switch this.Type {
+	case PluginTypeCommand:
+		if this.Command == "" {
+			return errors.New(fmt.Sprintf("Plugin [%s] requires command", this.Name))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Unknown type [%s] of plugin [%s]", this.Type, this.Name))
+	}
+	return nil
+}
+
+// The plugin directory
+func (this *Plugin) Path() string {
+	return this.path
+}
+
+// The path of the plugin command
+func (this *Plugin) CommandPath() string {
+	if this.Command == "" {
+		return ""
+	}
+	return filepath.Join(this.path, this.Command)
+}
+
+// Get the installed plugins sorted by name, the broken plugins are ignored
+// Parameters:
+// 	t 	The plugin type, all types if empty
+func (this *Workspace) Plugins(t string) ([]*Plugin, error) {
+	root, err := this.Dir.User.GetPath(PluginsDirName)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var plugins []*Plugin
+	for _, info := range infos {
+		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		plugin, err := LoadPlugin(filepath.Join(root, info.Name()))
+		if err != nil {
+			this.Logger.LeveledPrintf(log.LevelWarn, "Ignore plugin [%s], error: %s\n", info.Name(), err)
+			continue
+		}
+		if t == "" || plugin.Type == t {
+			plugins = append(plugins, plugin)
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	// Done
+	return plugins, nil
+}
+
+// Get the installed plugin by name
+func (this *Workspace) GetPlugin(name string) (*Plugin, error) {
+	if !pluginNameRegularExp.MatchString(name) {
+		return nil, errors.New(fmt.Sprintf("Invalid plugin name [%s]", name))
+	}
+	path := filepath.Join(this.Dir.User.RootPath(), PluginsDirName, name)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(fmt.Sprintf("Plugin [%s] not installed", name))
+		}
+		return nil, err
+	}
+	return LoadPlugin(path)
+}
+
+// Install (or upgrade) the plugin from the local directory
+func (this *Workspace) InstallPlugin(source string) (*Plugin, error) {
+	source, err := util.GetRealPath(source)
+	if err != nil {
+		return nil, err
+	}
+	plugin, err := LoadPlugin(source)
+	if err != nil {
+		return nil, err
+	}
+	root, err := this.Dir.User.GetPath(PluginsDirName)
+	if err != nil {
+		return nil, err
+	}
+	// Copy to a temp directory, then replace the installed one
+	path := filepath.Join(root, plugin.Name)
+	tempPath := filepath.Join(root, fmt.Sprintf(".%s.installing", plugin.Name))
+	if err := os.RemoveAll(tempPath); err != nil {
+		return nil, err
+	}
+	if err := util.CopyDir(source, tempPath); err != nil {
+		os.RemoveAll(tempPath)
+		return nil, err
+	}
+	install := PluginInstall{Source: source, Version: plugin.Version, Time: time.Now()}
+	data, err := json.Marshal(install)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(tempPath, PluginInstallFileName), data, 0666); err != nil {
+		os.RemoveAll(tempPath)
+		return nil, err
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return nil, err
+	}
+	// Done
+	return LoadPlugin(path)
+}
+
+// Remove the installed plugin
+func (this *Workspace) RemovePlugin(name string) error {
+	plugin, err := this.GetPlugin(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(plugin.Path())
+}
+
+// Find the external command of the subcommand
+// Returns: The executable path, the plugin (nil if not an installed plugin), error (nil with an empty path if not found)
+func (this *Workspace) FindExternalCommand(name string) (string, *Plugin, error) {
+	if !pluginNameRegularExp.MatchString(name) {
+		return "", nil, nil
+	}
+	// The command plugin
+	if plugin, err := this.GetPlugin(name); err == nil {
+		if plugin.Type == PluginTypeCommand {
+			return plugin.CommandPath(), plugin, nil
+		}
+	} else if _, statErr := os.Stat(filepath.Join(this.Dir.User.RootPath(), PluginsDirName, name)); statErr == nil {
+		// Installed but broken
+		return "", nil, err
+	}
+	// The executable in the plugins directory
+	path := filepath.Join(this.Dir.User.RootPath(), PluginsDirName, PluginCommandPrefix+name)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		if info.Mode()&0111 == 0 {
+			return "", nil, errors.New(fmt.Sprintf("External command [%s] is not executable", path))
+		}
+		return path, nil, nil
+	}
+	// The executable on PATH
+	if path, err := exec.LookPath(PluginCommandPrefix + name); err == nil {
+		return path, nil, nil
+	}
+	return "", nil, nil
+}
+
+// Get the environment variables of the plugin commands (appended to the current environment), which pass the
+// workspace context
+// Parameters:
+// 	plugin 	The installed plugin, nil for the external commands on PATH
+func (this *Workspace) PluginEnviron(plugin *Plugin) []string {
+	environ := append(os.Environ(),
+		fmt.Sprintf("%s=%s", WorkspaceEnvName, this.Name),
+		fmt.Sprintf("%s=%s", PluginWorkDirGlobalEnvName, this.Dir.Global.RootPath()),
+		fmt.Sprintf("%s=%s", PluginWorkDirUserEnvName, this.Dir.User.RootPath()),
+		fmt.Sprintf("%s=%s", PluginWorkDirProjectEnvName, this.Dir.Project.RootPath()),
+	)
+	if this.Offline {
+		environ = append(environ, OfflineEnvName+"=1")
+	}
+	if plugin != nil {
+		environ = append(environ, fmt.Sprintf("%s=%s", PluginPathEnvName, plugin.Path()))
+	}
+	return append(environ, this.ProxyEnviron()...)
+}
@@ -0,0 +1,181 @@
+// Author: lipixun
+// Created Time : 六 10/17 18:58:13 2026
+//
+// File Name: proxy.go
+// Description:
+//	The proxy of the network fetchers
+//
+//	The proxy is configured by the config keys, the environment variables are used if not configured:
+//		proxy.http 		The proxy of the http(s), s3, gcs and oci urls and the git http(s) remotes, e.g.
+//						http://proxy.corp:3128 or socks5://proxy.corp:1080. HTTPS_PROXY / HTTP_PROXY if not set
+//		proxy.ssh 		The proxy of the git ssh remotes, socks5://host:port or http://host:port (by CONNECT).
+//						Requires the openbsd netcat (nc -X). Can be overwritten per host by proxy in ssh.yaml
+//		proxy.no 		The comma separated hosts not proxied, NO_PROXY if not set. An entry matches the host and
+//						its subdomains (the leading dot is optional), an ip or cidr matches the ip hosts, * matches all
+//
+//	The proxy config is only accepted from the user and global config (see LookupUserConfig), a checked out
+//	repository shouldn't redirect the traffic. The proxy host must be a plain host[:port] since proxy.ssh ends up in
+//	the ProxyCommand of ssh which runs by the shell.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ProxyNone = "none" // Disable the proxy of a host in ssh.yaml
+
+	ProxySchemeHTTP   = "http"
+	ProxySchemeHTTPS  = "https"
+	ProxySchemeSOCKS5 = "socks5"
+)
+
+var (
+	proxyHostRegularExp = regexp.MustCompile("^[a-zA-Z0-9_]([a-zA-Z0-9_-]*[a-zA-Z0-9_])?(\\.[a-zA-Z0-9_]([a-zA-Z0-9_-]*[a-zA-Z0-9_])?)*\\.?$")
+)
+
+// Get the proxy url of the http requests, nil if not proxied
+func (this *Workspace) GetHTTPProxy(u *url.URL) (*url.URL, error) {
+	proxy := this.GetUserConfigString(ConfigKeyProxyHTTP)
+	if proxy == "" {
+		// Use the environment variables
+		return http.ProxyFromEnvironment(&http.Request{URL: u})
+	}
+	if this.IsNoProxy(u.Host) {
+		return nil, nil
+	}
+	return ParseProxy(proxy)
+}
+
+// Get the proxy url of the ssh host, nil if not proxied
+func (this *Workspace) GetSSHProxy(host string) (*url.URL, error) {
+	proxy := this.GetUserConfigString(ConfigKeyProxySSH)
+	if proxy == "" || this.IsNoProxy(host) {
+		return nil, nil
+	}
+	return ParseProxy(proxy)
+}
+
+// Check if the host (with or without port) should not be proxied by proxy.no (or NO_PROXY)
+func (this *Workspace) IsNoProxy(host string) bool {
+	noProxy := this.GetUserConfigString(ConfigKeyProxyNo)
+	if noProxy == "" {
+		noProxy = getEnvAny("NO_PROXY", "no_proxy")
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case ip != nil && strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+				return true
+			}
+		default:
+			entry = strings.TrimPrefix(entry, ".")
+			if host == entry || strings.HasSuffix(host, "."+entry) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Get the http client of the workspace which honors the proxy config
+func (this *Workspace) HTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(request *http.Request) (*url.URL, error) {
+		return this.GetHTTPProxy(request.URL)
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// Get the proxy environment variables of the external commands (e.g. git, curl), nil if proxy.http is not set
+// since the commands read the same environment variables
+func (this *Workspace) ProxyEnviron() []string {
+	proxy := this.GetUserConfigString(ConfigKeyProxyHTTP)
+	if proxy == "" {
+		return nil
+	}
+	if _, err := ParseProxy(proxy); err != nil {
+		this.Logger.LeveledPrintf(log.LevelWarn, "Ignore %s, error: %s\n", ConfigKeyProxyHTTP, err)
+		return nil
+	}
+	environ := []string{"http_proxy=" + proxy, "https_proxy=" + proxy, "HTTP_PROXY=" + proxy, "HTTPS_PROXY=" + proxy}
+	if noProxy := this.GetUserConfigString(ConfigKeyProxyNo); noProxy != "" {
+		environ = append(environ, "no_proxy="+noProxy, "NO_PROXY="+noProxy)
+	}
+	return environ
+}
+
+// Parse the proxy url, the scheme is http if not specified
+func ParseProxy(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = ProxySchemeHTTP + "://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid proxy [%s], error: %s", proxy, err))
+	}
+	switch u.Scheme {
+	case ProxySchemeHTTP, ProxySchemeHTTPS, ProxySchemeSOCKS5:
+	default:
+		return nil, errors.New(fmt.Sprintf("Unsupported scheme [%s] of proxy [%s], should be one of %s, %s, %s", u.Scheme, proxy, ProxySchemeHTTP, ProxySchemeHTTPS, ProxySchemeSOCKS5))
+	}
+	if u.Host == "" {
+		return nil, errors.New(fmt.Sprintf("Invalid proxy [%s], require host", proxy))
+	}
+	if !isPlainHost(u.Host) {
+		return nil, errors.New(fmt.Sprintf("Invalid proxy [%s], the host should be a plain host[:port]", proxy))
+	}
+	return u, nil
+}
+
+// Check if the host is a plain host name or ip with an optional port
+func isPlainHost(host string) bool {
+	name, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		name, port = h, p
+	} else if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		name = host[1 : len(host)-1]
+	}
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 || strconv.Itoa(n) != port {
+			return false
+		}
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		// The ipv6 address must be bracketed
+		return ip.To4() != nil || strings.HasPrefix(host, "[")
+	}
+	return proxyHostRegularExp.MatchString(name)
+}
+
+// Get the first non-empty environment variable
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
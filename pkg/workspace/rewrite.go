@@ -0,0 +1,102 @@
+// Author: lipixun
+// Created Time : 六 10/17 14:55:30 2026
+//
+// File Name: rewrite.go
+// Description:
+//	The url rewrite (mirror) rules of the workspace
+//
+//	The rules are loaded from the layered files (see layer.go), a prefix defined in a higher layer replaces the one
+//	in the lower layers:
+//		global 		<global>/rewrites.yaml
+//		user 		<user>/rewrites.yaml
+//		project 	<project>/.op.rewrites.yaml
+//
+//	Each file is a flat yaml map of the address prefix to the replacement (see uri/rewrite.go), e.g.
+//		github.com/org: git.internal/mirror/org
+//		docker.io: https://registry.internal/dockerhub
+//
+//	The urls a checked out repository shouldn't redirect (e.g. where op updates itself from) are rewritten by the
+//	global and user rules only, see UserRewrite.
+package workspace
+
+import (
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"github.com/ops-openlight/openlight/pkg/uri"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+)
+
+const (
+	RewriteFileName        = "rewrites.yaml"
+	ProjectRewriteFileName = ".op.rewrites.yaml"
+)
+
+// Load the rewrite rules with their sources, the files failed to load are ignored with warnings
+func (this *Workspace) LoadRewrites() Layered {
+	return this.loadRewrites(this.LayerSources(RewriteFileName, RewriteFileName, ProjectRewriteFileName))
+}
+
+// Load the rewrite rules of the global and user layers, see LoadRewrites
+func (this *Workspace) LoadUserRewrites() Layered {
+	return this.loadRewrites(this.UserLayerSources(RewriteFileName, RewriteFileName))
+}
+
+// Load the rewrite rules of the sources
+func (this *Workspace) loadRewrites(sources []LayerSource) Layered {
+	layered, errs := MergeLayers(
+		sources,
+		func(source LayerSource) (map[string]interface{}, error) {
+			data, err := ioutil.ReadFile(source.Path)
+			if err != nil {
+				return nil, err
+			}
+			var rules map[string]string
+			if err := yaml.Unmarshal(data, &rules); err != nil {
+				return nil, err
+			}
+			values := make(map[string]interface{})
+			for from, to := range rules {
+				values[from] = to
+			}
+			return values, nil
+		},
+	)
+	for _, err := range errs {
+		this.Logger.LeveledPrintf(log.LevelWarn, "%s\n", err)
+	}
+	return layered
+}
+
+// Get the rewrite rules
+func (this *Workspace) RewriteRules() uri.RewriteRules {
+	return newRewriteRules(this.LoadRewrites())
+}
+
+// Rewrite the url by the rules, the url is returned as is if not matched
+func (this *Workspace) Rewrite(s string) string {
+	return this.rewrite(this.RewriteRules(), s)
+}
+
+// Rewrite the url by the global and user rules only, the project rules are ignored
+func (this *Workspace) UserRewrite(s string) string {
+	return this.rewrite(newRewriteRules(this.LoadUserRewrites()), s)
+}
+
+// Create the rewrite rules of the loaded layers
+func newRewriteRules(layered Layered) uri.RewriteRules {
+	rules := make(map[string]string)
+	for from, value := range layered {
+		rules[from] = fmt.Sprint(value.Value)
+	}
+	return uri.NewRewriteRules(rules)
+}
+
+// Rewrite the url by the rules
+func (this *Workspace) rewrite(rules uri.RewriteRules, s string) string {
+	rewritten, rule := rules.Rewrite(s)
+	if rule != nil {
+		this.Logger.LeveledPrintf(log.LevelDebug, "Rewrite [%s] to [%s] by rule [%s -> %s]\n", s, rewritten, rule.From, rule.To)
+	}
+	return rewritten
+}
@@ -0,0 +1,259 @@
+// Author: lipixun
+// Created Time : 六 10/17 06:12:47 2026
+//
+// File Name: state.go
+// Description:
+//	The workspace state backend
+//
+//	The state records (runner instances, build summaries) are published to the state backend so they can be
+//	collected by others, e.g. a central dashboard of a shared staging box. The backend is selected by the config key
+//	state.backend:
+//		file 			(Default) The files in <user>/state/<kind>/<key>.json
+//		http(s)://... 	The http server, see httpStateBackend
+//
+//	The backend is only a mirror, the local files of the runner and builder are still used by themselves. The
+//	state.backend is only accepted from the user and global config, a checked out repository shouldn't receive the
+//	records (and the credential of the backend) at a url it chooses
+package workspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	StateBackendFile = "file"
+
+	StateDirName     = "state"
+	StateHTTPTimeout = 10 * time.Second
+
+	StateKindRunnerInstances = "runner.instances"
+	StateKindBuilds          = "builds"
+)
+
+// The state backend
+type StateBackend interface {
+	// The backend name, e.g. file or the url of the http server
+	Name() string
+	// Get the record, nil if not found
+	Get(kind, key string) ([]byte, error)
+	// Add or replace the record
+	Put(kind, key string, data []byte) error
+	// Delete the record, no error if not found
+	Delete(kind, key string) error
+	// List the keys of the kind, sorted
+	List(kind string) ([]string, error)
+}
+
+// Get the state backend of the workspace
+func (this *Workspace) State() (StateBackend, error) {
+	backend := this.GetUserConfigString(ConfigKeyStateBackend)
+	if backend == "" || backend == StateBackendFile {
+		path, err := this.Dir.User.GetPath(StateDirName)
+		if err != nil {
+			return nil, err
+		}
+		return fileStateBackend{path}, nil
+	}
+	if strings.HasPrefix(backend, "http://") || strings.HasPrefix(backend, "https://") {
+		u, err := url.Parse(backend)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid state backend [%s], error: %s", backend, err))
+		}
+		backend := &httpStateBackend{url: strings.TrimSuffix(backend, "/"), client: this.HTTPClient(StateHTTPTimeout)}
+		if credential, err := this.GetCredential(u.Host); err != nil {
+			return nil, err
+		} else if credential != nil {
+			backend.username, backend.secret = credential.Username, credential.Secret
+		}
+		return backend, nil
+	}
+	return nil, errors.New(fmt.Sprintf("Unknown state backend [%s]", backend))
+}
+
+// Publish the record to the state backend, the failure is logged as a warning
+func (this *Workspace) PublishState(kind, key string, value interface{}) {
+	if err := this.publishState(kind, key, value); err != nil {
+		this.Logger.LeveledPrintf(log.LevelWarn, "Failed to publish state [%s] of [%s], error: %s\n", key, kind, err)
+	}
+}
+
+func (this *Workspace) publishState(kind, key string, value interface{}) error {
+	backend, err := this.State()
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return backend.Delete(kind, key)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return backend.Put(kind, key, data)
+}
+
+// The state backend on the local file system
+type fileStateBackend struct {
+	path string
+}
+
+func (this fileStateBackend) Name() string {
+	return StateBackendFile
+}
+
+func (this fileStateBackend) recordPath(kind, key string) (string, error) {
+	if kind == "" || key == "" || strings.ContainsAny(kind+key, "/\\") || strings.HasPrefix(kind, ".") || strings.HasPrefix(key, ".") {
+		return "", errors.New(fmt.Sprintf("Invalid state record [%s] of [%s]", key, kind))
+	}
+	return filepath.Join(this.path, kind, key+".json"), nil
+}
+
+func (this fileStateBackend) Get(kind, key string) ([]byte, error) {
+	path, err := this.recordPath(kind, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (this fileStateBackend) Put(kind, key string, data []byte) error {
+	path, err := this.recordPath(kind, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	// Write to a temp file and rename, the readers never see a partial record
+	tempPath := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := ioutil.WriteFile(tempPath, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
+
+func (this fileStateBackend) Delete(kind, key string) error {
+	path, err := this.recordPath(kind, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (this fileStateBackend) List(kind string) ([]string, error) {
+	infos, err := ioutil.ReadDir(filepath.Join(this.path, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var keys []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
+			keys = append(keys, strings.TrimSuffix(info.Name(), ".json"))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// The state backend on a http server
+//
+// The api:
+//
+//	GET 	<url>/<kind>/<key> 		Get the record, 404 if not found
+//	PUT 	<url>/<kind>/<key> 		Put the record (application/json)
+//	DELETE 	<url>/<kind>/<key> 		Delete the record
+//	GET 	<url>/<kind>/ 			List the keys as a json string array
+//
+// The basic auth is used if the credential of the host is found (see op login)
+type httpStateBackend struct {
+	url      string
+	client   *http.Client
+	username string
+	secret   string
+}
+
+func (this *httpStateBackend) Name() string {
+	return this.url
+}
+
+func (this *httpStateBackend) do(method, path string, data []byte) ([]byte, int, error) {
+	request, err := http.NewRequest(method, this.url+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	if data != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if this.username != "" || this.secret != "" {
+		request.SetBasicAuth(this.username, this.secret)
+	}
+	response, err := this.client.Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+	respData, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, response.StatusCode, err
+	}
+	if response.StatusCode != http.StatusNotFound && (response.StatusCode < 200 || response.StatusCode >= 300) {
+		return nil, response.StatusCode, errors.New(fmt.Sprintf("%s %s: %s", method, this.url+path, response.Status))
+	}
+	return respData, response.StatusCode, nil
+}
+
+func (this *httpStateBackend) Get(kind, key string) ([]byte, error) {
+	data, code, err := this.do(http.MethodGet, fmt.Sprintf("/%s/%s", url.PathEscape(kind), url.PathEscape(key)), nil)
+	if err != nil || code == http.StatusNotFound {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (this *httpStateBackend) Put(kind, key string, data []byte) error {
+	_, code, err := this.do(http.MethodPut, fmt.Sprintf("/%s/%s", url.PathEscape(kind), url.PathEscape(key)), data)
+	if err == nil && code == http.StatusNotFound {
+		return errors.New(fmt.Sprintf("State kind [%s] not found on [%s]", kind, this.url))
+	}
+	return err
+}
+
+func (this *httpStateBackend) Delete(kind, key string) error {
+	_, _, err := this.do(http.MethodDelete, fmt.Sprintf("/%s/%s", url.PathEscape(kind), url.PathEscape(key)), nil)
+	return err
+}
+
+func (this *httpStateBackend) List(kind string) ([]string, error) {
+	data, code, err := this.do(http.MethodGet, fmt.Sprintf("/%s/", url.PathEscape(kind)), nil)
+	if err != nil || code == http.StatusNotFound {
+		return nil, err
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid key list of [%s] from [%s], error: %s", kind, this.url, err))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,238 @@
+// Author: lipixun
+// Created Time : 日 10/18 05:02:37 2026
+//
+// File Name: telemetry.go
+// Description:
+//	The anonymous usage metrics (telemetry), disabled unless explicitly enabled by op telemetry enable
+//
+//	Each command run is an event of the command path, the names of the flags set, the duration, the exit code and
+//	its kind, the op version and the platform. No arguments, flag values, paths, hosts or identifiers (neither of the
+//	user nor of the installation) are recorded, the time is the date only.
+//
+//	The events are queued in <user>/telemetry/events.jsonl and posted to telemetry.endpoint as a json array at most
+//	once per TelemetryFlushInterval. The queue is kept (the latest TelemetryMaxEvents events) if the post failed, in
+//	offline mode or if no endpoint is configured.
+//
+//	The config telemetry.enabled and telemetry.endpoint are only accepted from the user and global config, a checked
+//	out repository shouldn't turn on the telemetry or redirect it.
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TelemetryDirName        = "telemetry"
+	TelemetryEventsFileName = "events.jsonl"
+	TelemetryNoticeFileName = "notice"  // Exists if the first run notice was shown
+	TelemetryFlushFileName  = "flushed" // The modification time is the last flush
+	TelemetryMaxEvents      = 1000
+	TelemetryMaxQueueSize   = 1 << 20 // The queue is trimmed to the latest TelemetryMaxEvents events beyond this size
+	TelemetryFlushInterval  = time.Hour
+	TelemetryHTTPTimeout    = 3 * time.Second
+)
+
+// A telemetry event
+type TelemetryEvent struct {
+	Command    string   `json:"command"`         // The command path, e.g. workspace clean
+	Flags      []string `json:"flags,omitempty"` // The names of the flags set, without the values
+	DurationMs int64    `json:"durationMs"`
+	ExitCode   int      `json:"exitCode"`
+	ErrorKind  string   `json:"errorKind,omitempty"` // The kind of the exit code, e.g. build_failure
+	Version    string   `json:"version"`
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+	Date       string   `json:"date"` // e.g. 2026-10-18
+}
+
+// Whether the telemetry is enabled
+func (this *Workspace) TelemetryEnabled() bool {
+	enabled, _ := strconv.ParseBool(this.GetUserConfigString(ConfigKeyTelemetryEnabled))
+	return enabled
+}
+
+// Add the event to the queue, nothing if the telemetry is disabled
+func (this *Workspace) RecordTelemetry(event TelemetryEvent) error {
+	if !this.TelemetryEnabled() {
+		return nil
+	}
+	path, err := this.Dir.User.GetPath(TelemetryDirName)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// A single write of a line is atomic in append mode, so the events of the concurrent commands are not mixed
+	eventsPath := filepath.Join(path, TelemetryEventsFileName)
+	file, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	file.Close()
+	if err != nil {
+		return err
+	}
+	// Trim the queue, e.g. no endpoint is configured
+	if info, err := os.Stat(eventsPath); err == nil && info.Size() > TelemetryMaxQueueSize {
+		events, err := readTelemetryEvents(eventsPath)
+		if err != nil {
+			return err
+		}
+		var buffer bytes.Buffer
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			buffer.Write(append(data, '\n'))
+		}
+		return ioutil.WriteFile(eventsPath, buffer.Bytes(), 0644)
+	}
+	// Done
+	return nil
+}
+
+// Get the queued events, the latest TelemetryMaxEvents ones
+func (this *Workspace) GetTelemetryEvents() ([]TelemetryEvent, error) {
+	path, err := this.Dir.User.GetPath(TelemetryDirName)
+	if err != nil {
+		return nil, err
+	}
+	return readTelemetryEvents(filepath.Join(path, TelemetryEventsFileName))
+}
+
+// Remove the queued events
+func (this *Workspace) ClearTelemetry() error {
+	if err := os.Remove(filepath.Join(this.Dir.User.RootPath(), TelemetryDirName, TelemetryEventsFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Get the time of the last flush, zero if never flushed
+func (this *Workspace) GetTelemetryFlushTime() time.Time {
+	info, err := os.Stat(filepath.Join(this.Dir.User.RootPath(), TelemetryDirName, TelemetryFlushFileName))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Post the queued events to the endpoint if the flush interval passed (or force), the posted events are removed
+func (this *Workspace) FlushTelemetry(force bool) error {
+	endpoint := this.GetUserConfigString(ConfigKeyTelemetryEndpoint)
+	if endpoint == "" || this.Offline {
+		return nil
+	}
+	if !force && time.Since(this.GetTelemetryFlushTime()) < TelemetryFlushInterval {
+		return nil
+	}
+	path, err := this.Dir.User.GetPath(TelemetryDirName)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(path, TelemetryFlushFileName), nil, 0644); err != nil {
+		return err
+	}
+	// Move the queue aside, so the events recorded while posting are kept
+	eventsPath := filepath.Join(path, TelemetryEventsFileName)
+	sendingPath := fmt.Sprintf("%s.%d", eventsPath, os.Getpid())
+	if err := os.Rename(eventsPath, sendingPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	events, err := readTelemetryEvents(sendingPath)
+	if err == nil && len(events) > 0 {
+		err = this.postTelemetry(endpoint, events)
+	}
+	if err != nil {
+		// Put the events back to the queue
+		if data, readErr := ioutil.ReadFile(sendingPath); readErr == nil {
+			if file, openErr := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); openErr == nil {
+				file.Write(data)
+				file.Close()
+			}
+		}
+	}
+	os.Remove(sendingPath)
+	// Done
+	return err
+}
+
+// Post the events as a json array
+func (this *Workspace) postTelemetry(endpoint string, events []TelemetryEvent) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	response, err := this.HTTPClient(TelemetryHTTPTimeout).Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("POST %s: %s", endpoint, response.Status))
+	}
+	return nil
+}
+
+// Check whether the first run notice should be shown: the telemetry is neither enabled nor disabled explicitly and
+// the notice is not shown yet. The notice is marked as shown.
+func (this *Workspace) CheckTelemetryNotice() bool {
+	if _, layer, _ := this.LookupUserConfig(ConfigKeyTelemetryEnabled); layer != "" || this.Dir.User.ReadOnly() {
+		return false
+	}
+	path, err := this.Dir.User.GetPath(TelemetryDirName)
+	if err != nil {
+		return false
+	}
+	noticePath := filepath.Join(path, TelemetryNoticeFileName)
+	if _, err := os.Stat(noticePath); err == nil {
+		return false
+	}
+	return ioutil.WriteFile(noticePath, nil, 0644) == nil
+}
+
+// Read the events in the file, the malformed lines are skipped
+func readTelemetryEvents(path string) ([]TelemetryEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	var events []TelemetryEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var event TelemetryEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) > TelemetryMaxEvents {
+		events = events[len(events)-TelemetryMaxEvents:]
+	}
+	return events, nil
+}
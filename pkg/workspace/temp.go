@@ -0,0 +1,149 @@
+// Author: lipixun
+// Created Time : 六 10/17 02:52:08 2026
+//
+// File Name: temp.go
+// Description:
+//	The managed temp directories
+//
+//	The temp directories are allocated in the user workdir and tracked in the manifest of the process:
+//		<user>/tmp/
+//			<pid>.json 					The manifest, the temp directories allocated by the process
+//			<pid>-<purpose>-<random>/ 	The temp directory
+//
+//	The temp directories are removed by Workspace.Close when the process exits, or by the next invocation if the
+//	process of the manifest is not running anymore (e.g. crashed or killed)
+package workspace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	TempDirName = "tmp"
+)
+
+var (
+	tempPurposeRegularExp = regexp.MustCompile("[^a-zA-Z\\d\\.\\-]")
+)
+
+// The temp directory manifest of a process
+type tempManifest struct {
+	Pid  int      `json:"pid"`
+	Dirs []string `json:"dirs"`
+}
+
+type tempDirs struct {
+	lock     sync.Mutex
+	manifest tempManifest
+}
+
+// Allocate a new temp directory which is removed when the workspace is closed
+// Parameters:
+// 	purpose 	The purpose used in the directory name, e.g. builder-environs
+func (this *Workspace) TempDir(purpose string) (string, error) {
+	root, err := this.Dir.User.GetPath(TempDirName)
+	if err != nil {
+		return "", err
+	}
+	rands := make([]byte, 4)
+	if _, err := rand.Read(rands); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%d-%s-%s", os.Getpid(), tempPurposeRegularExp.ReplaceAllString(purpose, "_"), hex.EncodeToString(rands))
+	path := filepath.Join(root, name)
+	this.temp.lock.Lock()
+	defer this.temp.lock.Unlock()
+	// Record in the manifest before creating, so it's always cleaned
+	this.temp.manifest.Pid = os.Getpid()
+	this.temp.manifest.Dirs = append(this.temp.manifest.Dirs, name)
+	if err := writeTempManifest(root, &this.temp.manifest); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return "", err
+	}
+	// Done
+	return path, nil
+}
+
+// Close the workspace, the temp directories are removed
+func (this *Workspace) Close() error {
+	this.temp.lock.Lock()
+	defer this.temp.lock.Unlock()
+	if len(this.temp.manifest.Dirs) == 0 {
+		return nil
+	}
+	root := filepath.Join(this.Dir.User.RootPath(), TempDirName)
+	err := removeTempDirs(root, &this.temp.manifest)
+	this.temp.manifest.Dirs = nil
+	return err
+}
+
+// Remove the temp directories of the processes which are not running anymore
+func (this *Workspace) cleanStaleTempDirs() {
+	root := filepath.Join(this.Dir.User.RootPath(), TempDirName)
+	infos, err := ioutil.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSuffix(info.Name(), ".json"))
+		if err != nil || pid == os.Getpid() || isProcessRunning(pid) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(root, info.Name()))
+		if err != nil {
+			continue
+		}
+		var manifest tempManifest
+		if err := json.Unmarshal(data, &manifest); err != nil || manifest.Pid != pid {
+			this.Logger.LeveledPrintf(log.LevelWarn, "Ignore broken temp manifest [%s]\n", info.Name())
+			continue
+		}
+		this.Logger.LeveledPrintf(log.LevelDebug, "Remove the temp directories of exited process [%d]\n", pid)
+		if err := removeTempDirs(root, &manifest); err != nil {
+			this.Logger.LeveledPrintf(log.LevelWarn, "Failed to remove the temp directories of process [%d], error: %s\n", pid, err)
+		}
+	}
+}
+
+func writeTempManifest(root string, manifest *tempManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(root, fmt.Sprintf("%d.json", manifest.Pid)), data, 0666)
+}
+
+// Remove the temp directories and the manifest
+func removeTempDirs(root string, manifest *tempManifest) error {
+	for _, name := range manifest.Dirs {
+		if err := os.RemoveAll(filepath.Join(root, filepath.Base(name))); err != nil {
+			return err
+		}
+	}
+	return os.Remove(filepath.Join(root, fmt.Sprintf("%d.json", manifest.Pid)))
+}
+
+func isProcessRunning(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,97 @@
+// Author: lipixun
+// Created Time : 六 10/17 00:20:31 2026
+//
+// File Name: usage.go
+// Description:
+//	The disk usage of the workdir
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// A removable item (file or directory) in the workdir
+type UsageItem struct {
+	Path    string    // The path
+	Size    int64     // The total size of the files in bytes
+	ModTime time.Time // The latest modification time of the files
+}
+
+// Get the usage of the path, symbol links are not followed
+func GetUsageItem(path string) (*UsageItem, error) {
+	item := &UsageItem{Path: path}
+	if err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			item.Size += info.Size()
+		}
+		if info.ModTime().After(item.ModTime) {
+			item.ModTime = info.ModTime()
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Get the usage of each entry in the directory, sorted from the oldest to the newest. Empty if the directory not exists
+func ListUsageItems(dir string) ([]*UsageItem, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var items []*UsageItem
+	for _, info := range infos {
+		item, err := GetUsageItem(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	// Done
+	return SortUsageItems(items), nil
+}
+
+// Sort the items from the oldest to the newest
+func SortUsageItems(items []*UsageItem) []*UsageItem {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ModTime.Before(items[j].ModTime)
+	})
+	return items
+}
+
+// Select the items to prune, the items should be sorted from the oldest to the newest
+// Parameters:
+// 	items 		The items
+// 	olderThan 	Select the items not modified in this duration, ignored if not positive
+// 	maxSize 	Select the oldest items until the total size of the rest is not greater than it, ignored if negative
+// Returns:
+// 	The selected items. All items are selected if neither olderThan nor maxSize is specified
+func SelectUsageItems(items []*UsageItem, olderThan time.Duration, maxSize int64) []*UsageItem {
+	if olderThan <= 0 && maxSize < 0 {
+		return items
+	}
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+	var selected []*UsageItem
+	now := time.Now()
+	for _, item := range items {
+		if (olderThan > 0 && now.Sub(item.ModTime) > olderThan) || (maxSize >= 0 && total > maxSize) {
+			selected = append(selected, item)
+			total -= item.Size
+		}
+	}
+	return selected
+}
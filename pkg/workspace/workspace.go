@@ -7,25 +7,49 @@
 package workspace
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/ops-openlight/openlight/pkg/log"
 	"github.com/ops-openlight/openlight/pkg/workspace/dirdetector"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 )
 
 const (
 	WorkspaceLogHeader = "Workspace"
+
+	WorkspaceLogDirName  = "logs"
+	WorkspaceLogFileName = "op.log"
+)
+
+var (
+	workspaceNameRegularExp = regexp.MustCompile("^[a-zA-Z\\d_\\-\\.]+$")
 )
 
 type Workspace struct {
+	Name    string
 	Verbose bool
+	Offline bool // Never access the network, see offline.go
+	Refresh bool // Refresh the cached metadata of the remote repositories regardless of the ttl
 	Logger  log.Logger
 	Dir     struct {
 		Global  *WorkDir
 		User    *WorkDir
 		Project *WorkDir
 	}
+	Config  *Config
 	Options WorkspaceOptions
+	temp    tempDirs
+	ctx     context.Context // Done when canceled, see cancel.go
+	cancel  context.CancelFunc
+
+	credentialLock    sync.Mutex
+	credentialStore   CredentialStore        // The probed store, see Credentials
+	helperCredentials map[string]*Credential // The credentials got by the credential helpers, key is host
 }
 
 // Create new default worksapce
@@ -39,28 +63,173 @@ func New(options *WorkspaceOptions, logger log.Logger) (*Workspace, error) {
 	if options == nil {
 		options = NewWorkspaceOptions()
 	}
-	if logger == nil {
-		if options.Verbose {
-			logger = log.New(os.Stderr, log.LevelDebug, log.LevelInfo, WorkspaceLogHeader)
-		} else {
-			logger = log.New(os.Stderr, log.LevelInfo, log.LevelInfo, WorkspaceLogHeader)
+	// Get the log level: the log level option, the OP_LOG_LEVEL environment variable, then the verbose option
+	level := log.LevelInfo
+	if options.Verbose {
+		level = log.LevelDebug
+	}
+	levelName := options.LogLevel
+	if levelName == "" {
+		levelName = os.Getenv(LogLevelEnvName)
+	}
+	var levelErr error
+	if levelName != "" {
+		var l int
+		if l, levelErr = log.ParseLevel(levelName); levelErr == nil {
+			level = l
 		}
+	}
+	if logger == nil {
+		logger = log.New(os.Stderr, level, log.LevelInfo, WorkspaceLogHeader)
 	} else {
 		logger = logger.GetLoggerWithHeader(WorkspaceLogHeader)
 	}
+	if levelErr != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Ignore log level, error: %s\n", levelErr)
+	}
 	logger.Options().EnableColor = options.EnableColor
+	if options.LogFormat != "" {
+		logger.Options().Format = options.LogFormat
+	}
+	if options.LogTimestamp {
+		logger.Options().ShowTimestamp = true
+		logger.Options().ShowLevel = true
+	}
+	logger.Options().ShowCaller = options.LogCaller
+	logger.Options().RateLimit = options.LogRateLimit
+	if err := setLogLevels(logger, options.LogLevels); err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Invalid %s environment variable, error: %s\n", LogLevelsEnvName, err)
+	}
 	// Set the workspace
-	ws.Verbose = options.Verbose
+	ws.Verbose = options.Verbose || logger.GetLevel() <= log.LevelDebug
 	ws.Logger = logger
 	ws.Options = *options
+	ws.Refresh = options.Refresh
+	ws.ctx, ws.cancel = context.WithCancel(context.Background())
 	// Initialize work dir
-	if err := ws.initWorkDir(&options.Dir); err != nil {
+	dirOptions := options.Dir
+	ws.Name = options.Name
+	if ws.Name == "" {
+		ws.Name = DefaultWorkspaceName
+	}
+	if ws.Name != DefaultWorkspaceName {
+		if !workspaceNameRegularExp.MatchString(ws.Name) || ws.Name == "." || ws.Name == ".." {
+			return nil, errors.New(fmt.Sprintf("Invalid workspace name [%s]", ws.Name))
+		}
+		dirOptions.UserPath = filepath.Join(dirOptions.UserPath, WorkspacesDirName, ws.Name)
+		logger.LeveledPrintf(log.LevelDebug, "Use workspace [%s]\n", ws.Name)
+	}
+	if err := ws.initWorkDir(&dirOptions); err != nil {
 		return nil, err
 	}
+	if err := ws.migrateWorkDir(); err != nil {
+		return nil, err
+	}
+	ws.cleanStaleTempDirs()
+	// Load the config
+	config, err := ws.loadConfig()
+	if err != nil {
+		logger.LeveledPrintf(log.LevelWarn, "Ignore workspace config, error: %s\n", err)
+		config = new(Config)
+	}
+	ws.Config = config
+	ws.applyConfig(options.Verbose || levelName != "")
+	// Initialize the system log
+	if options.LogSystem != "" {
+		if err := ws.initLogSystem(options.LogSystem); err != nil {
+			logger.LeveledPrintf(log.LevelWarn, "Failed to connect to system log [%s], error: %s\n", options.LogSystem, err)
+		}
+	}
+	// Initialize the log file
+	if options.LogFile {
+		if err := ws.initLogFile(); err != nil {
+			logger.LeveledPrintf(log.LevelWarn, "Failed to open log file, error: %s\n", err)
+		}
+	}
 	// Done
 	return ws, nil
 }
 
+// Apply the log config, the log level in config is ignored if specified by options or environment variable
+func (this *Workspace) applyConfig(levelSpecified bool) {
+	if !levelSpecified {
+		if levelName := this.Config.GetString(ConfigKeyLogLevel); levelName != "" {
+			if level, err := log.ParseLevel(levelName); err != nil {
+				this.Logger.LeveledPrintf(log.LevelWarn, "Ignore log level in config, error: %s\n", err)
+			} else {
+				this.Logger.SetLevel(level)
+			}
+		} else if this.Config.GetBool(ConfigKeyLogVerbose) {
+			this.Logger.SetLevel(log.LevelDebug)
+		}
+		this.Verbose = this.Verbose || this.Logger.GetLevel() <= log.LevelDebug
+	}
+	if !this.Config.GetBool(ConfigKeyLogColor) {
+		this.Logger.Options().EnableColor = false
+	}
+	this.Offline = this.Options.Offline || this.Config.GetBool(ConfigKeyOffline)
+	if this.Offline {
+		this.Logger.LeveledPrintf(log.LevelDebug, "Offline mode, the remote resources are served from the caches only\n")
+	}
+}
+
+// Set the log levels of headers from options and environment variable (the environment variable takes precedence)
+func setLogLevels(logger log.Logger, levels map[string]int) error {
+	headerLevels := make(map[string]int)
+	for header, level := range levels {
+		headerLevels[header] = level
+	}
+	var err error
+	if value := os.Getenv(LogLevelsEnvName); value != "" {
+		var envLevels map[string]int
+		if envLevels, err = log.ParseHeaderLevels(value); err == nil {
+			for header, level := range envLevels {
+				headerLevels[header] = level
+			}
+		}
+	}
+	if len(headerLevels) > 0 {
+		logger.Options().HeaderLevels = headerLevels
+	}
+	return err
+}
+
+// Init the system log sink
+func (this *Workspace) initLogSystem(name string) error {
+	var sink log.Sink
+	var err error
+	switch name {
+	case LogSystemSyslog:
+		sink, err = log.NewSyslogSink(log.LevelInfo, LogSystemTag)
+	case LogSystemJournal:
+		sink, err = log.NewJournalSink(log.LevelInfo, LogSystemTag)
+	default:
+		return errors.New(fmt.Sprintf("Unknown system log [%s]", name))
+	}
+	if err != nil {
+		return err
+	}
+	this.Logger.AddSink(sink)
+	// Done
+	return nil
+}
+
+// Init the log file which mirrors all log of this invocation
+func (this *Workspace) initLogFile() error {
+	path, err := this.Dir.User.GetPath(WorkspaceLogDirName)
+	if err != nil {
+		return err
+	}
+	file, err := log.NewRotatingFile(filepath.Join(path, WorkspaceLogFileName), log.DefaultLogFileMaxSize, log.DefaultLogFileMaxBackups)
+	if err != nil {
+		return err
+	}
+	this.Logger.SetFile(file)
+	this.Logger.LeveledPrintf(log.LevelDebug, "Run: %s\n", strings.Join(os.Args, " "))
+	// Done
+	return nil
+}
+
 // Init the work dir
 func (this *Workspace) initWorkDir(options *WorkDirOptions) error {
 	var err error
@@ -84,21 +253,20 @@ func (this *Workspace) initWorkDir(options *WorkDirOptions) error {
 	}
 	// Create the project workdir
 	var projectPath string
+	currentPath := options.CurrentPath
+	if currentPath == "" {
+		if currentPath, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
 	if options.ProjectPath != "" {
 		projectPath = options.ProjectPath
 	} else if options.CurrentPathAsProjectPath {
-		projectPath, err = os.Getwd()
-		if err != nil {
-			return err
-		}
+		projectPath = currentPath
 	} else {
-		p, err := os.Getwd()
-		if err != nil {
-			return err
-		}
 		// Detect project path
 		for name, detector := range dirdetector.Detectors {
-			projectPath, err = detector.Detect(p)
+			projectPath, err = detector.Detect(currentPath)
 			if err != nil {
 				this.Logger.LeveledPrintf(log.LevelDebug, "Project path workdir detector [%s] returns error: %s\n", name, err)
 			} else {
@@ -107,7 +275,10 @@ func (this *Workspace) initWorkDir(options *WorkDirOptions) error {
 			}
 		}
 		if projectPath == "" {
-			return errors.New("No project path detected")
+			// Not in a project (e.g. op config or op self-update in the home directory), the current path is the
+			// project workdir as the cli always did before the detection
+			this.Logger.LeveledPrintf(log.LevelDebug, "No project path detected, use current path\n")
+			projectPath = currentPath
 		}
 	}
 	this.Logger.LeveledPrintf(log.LevelDebug, "Set project workdir to: %s\n", projectPath)
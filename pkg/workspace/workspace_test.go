@@ -0,0 +1,42 @@
+// Author: lipixun
+// Created Time : 五 10/16 11:52:06 2026
+//
+// File Name: workspace_test.go
+// Description:
+//
+package workspace
+
+import (
+	"github.com/ops-openlight/openlight/pkg/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Create the workspace of temporary directories, the directories are removed by the returned function
+func newTestWorkspace(t *testing.T) (*Workspace, *log.CaptureLogger, func()) {
+	dir, err := ioutil.TempDir("", "workspace-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := NewWorkspaceOptions()
+	options.Dir.GlobalPath = filepath.Join(dir, "global")
+	options.Dir.UserPath = filepath.Join(dir, "user")
+	options.Dir.ProjectPath = filepath.Join(dir, "project")
+	options.LogFile = false
+	options.EnableColor = false
+	for _, path := range []string{options.Dir.GlobalPath, options.Dir.UserPath, options.Dir.ProjectPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	logger := log.NewCaptureLogger()
+	ws, err := New(options, logger)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return ws, logger, func() { os.RemoveAll(dir) }
+}